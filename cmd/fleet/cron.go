@@ -21,13 +21,17 @@ import (
 	"github.com/fleetdm/fleet/v4/server/contexts/license"
 	"github.com/fleetdm/fleet/v4/server/datastore/mysql"
 	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/hostlifecycle"
+	"github.com/fleetdm/fleet/v4/server/labelsync"
 	"github.com/fleetdm/fleet/v4/server/policies"
 	"github.com/fleetdm/fleet/v4/server/ptr"
 	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
 	"github.com/fleetdm/fleet/v4/server/service/schedule"
+	"github.com/fleetdm/fleet/v4/server/updaterollouts"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/macoffice"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/msrc"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/nvd"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/osv"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/oval"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/utils"
 	"github.com/fleetdm/fleet/v4/server/webhooks"
@@ -158,23 +162,69 @@ func scanVulnerabilities(
 		}
 	}
 
+	// check for Slack integrations
+	for _, sl := range appConfig.Integrations.Slack {
+		if sl.EnableSoftwareVulnerabilities {
+			if vulnAutomationEnabled != "" {
+				err := ctxerr.New(ctx, "slack check")
+				errHandler(ctx, logger, "more than one automation enabled", err)
+			}
+			vulnAutomationEnabled = "slack"
+			break
+		}
+	}
+
+	// check for Microsoft Teams integrations
+	for _, mt := range appConfig.Integrations.MicrosoftTeams {
+		if mt.EnableSoftwareVulnerabilities {
+			if vulnAutomationEnabled != "" {
+				err := ctxerr.New(ctx, "microsoft teams check")
+				errHandler(ctx, logger, "more than one automation enabled", err)
+			}
+			vulnAutomationEnabled = "microsoft_teams"
+			break
+		}
+	}
+
 	level.Debug(logger).Log("vulnAutomationEnabled", vulnAutomationEnabled)
 
 	nvdVulns := checkNVDVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 	ovalVulns := checkOvalVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 	macOfficeVulns := checkMacOfficeVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
+	osvVulns := checkOSVVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 
 	checkWinVulnerabilities(ctx, ds, logger, vulnPath, config, vulnAutomationEnabled != "")
 
+	// record when the CVEs were first detected on the hosts and when they were
+	// resolved, now that the vulnerabilities are up to date.
+	if err := ds.SyncHostVulnerabilityRemediations(ctx, time.Now()); err != nil {
+		errHandler(ctx, logger, "syncing host vulnerability remediations", err)
+	}
+
+	if vulnAutomationEnabled == "webhook" {
+		// send the remediation SLA breaches, regardless of the recent
+		// vulnerabilities.
+		if err := webhooks.TriggerVulnerabilitySLABreachesWebhook(
+			ctx,
+			ds,
+			kitlog.With(logger, "webhook", "vulnerability_sla_breaches"),
+			appConfig,
+			time.Now(),
+		); err != nil {
+			errHandler(ctx, logger, "triggering vulnerability sla breaches webhook", err)
+		}
+	}
+
 	// If no automations enabled, then there is nothing else to do...
 	if vulnAutomationEnabled == "" {
 		return nil
 	}
 
-	vulns := make([]fleet.SoftwareVulnerability, 0, len(nvdVulns)+len(ovalVulns)+len(macOfficeVulns))
+	vulns := make([]fleet.SoftwareVulnerability, 0, len(nvdVulns)+len(ovalVulns)+len(macOfficeVulns)+len(osvVulns))
 	vulns = append(vulns, nvdVulns...)
 	vulns = append(vulns, ovalVulns...)
 	vulns = append(vulns, macOfficeVulns...)
+	vulns = append(vulns, osvVulns...)
 
 	meta, err := ds.ListCVEs(ctx, config.RecentVulnerabilityMaxAge)
 	if err != nil {
@@ -232,6 +282,30 @@ func scanVulnerabilities(
 				errHandler(ctx, logger, "queueing vulnerabilities to Zendesk", err)
 			}
 
+		case "slack":
+			// queue job to post slack message
+			if err := worker.QueueSlackVulnJobs(
+				ctx,
+				ds,
+				kitlog.With(logger, "slack", "vulnerabilities"),
+				recentV,
+				matchingMeta,
+			); err != nil {
+				errHandler(ctx, logger, "queueing vulnerabilities to Slack", err)
+			}
+
+		case "microsoft_teams":
+			// queue job to post microsoft teams message
+			if err := worker.QueueMicrosoftTeamsVulnJobs(
+				ctx,
+				ds,
+				kitlog.With(logger, "microsoft_teams", "vulnerabilities"),
+				recentV,
+				matchingMeta,
+			); err != nil {
+				errHandler(ctx, logger, "queueing vulnerabilities to Microsoft Teams", err)
+			}
+
 		default:
 			err = ctxerr.New(ctx, "no vuln automations enabled")
 			errHandler(ctx, logger, "attempting to process vuln automations", err)
@@ -307,7 +381,7 @@ func checkOvalVulnerabilities(
 
 	if !config.DisableDataSync {
 		// Sync on disk OVAL definitions with current OS Versions.
-		downloaded, err := oval.Refresh(ctx, versions, vulnPath)
+		downloaded, err := oval.Refresh(ctx, versions, vulnPath, logger)
 		if err != nil {
 			errHandler(ctx, logger, "updating oval definitions", err)
 		}
@@ -410,6 +484,40 @@ func checkMacOfficeVulnerabilities(
 	return r
 }
 
+func checkOSVVulnerabilities(
+	ctx context.Context,
+	ds fleet.Datastore,
+	logger kitlog.Logger,
+	vulnPath string,
+	config *config.VulnerabilitiesConfig,
+	collectVulns bool,
+) []fleet.SoftwareVulnerability {
+	if !config.DisableDataSync {
+		downloaded, err := osv.Refresh(ctx, ds, vulnPath)
+		if err != nil {
+			errHandler(ctx, logger, "updating osv dumps", err)
+		}
+		for _, d := range downloaded {
+			level.Debug(logger).Log("osv-sync-downloaded", d)
+		}
+	}
+
+	start := time.Now()
+	r, err := osv.Analyze(ctx, ds, vulnPath, collectVulns)
+	elapsed := time.Since(start)
+
+	level.Debug(logger).Log(
+		"msg", "osv-analysis-done",
+		"elapsed", elapsed,
+		"found new", len(r))
+
+	if err != nil {
+		errHandler(ctx, logger, "analyzing software packages using osv", err)
+	}
+
+	return r
+}
+
 func newAutomationsSchedule(
 	ctx context.Context,
 	instanceID string,
@@ -531,6 +639,42 @@ func triggerFailingPoliciesAutomation(
 			if err := failingPoliciesSet.RemoveHosts(policy.ID, hosts); err != nil {
 				return ctxerr.Wrapf(ctx, err, "removing %d hosts from failing policies set %d", len(hosts), policy.ID)
 			}
+
+		case policies.FailingPolicySlack:
+			hosts, err := failingPoliciesSet.ListHosts(policy.ID)
+			if err != nil {
+				return ctxerr.Wrapf(ctx, err, "listing hosts for failing policies set %d", policy.ID)
+			}
+			if err := worker.QueueSlackFailingPolicyJob(ctx, ds, logger, policy, hosts); err != nil {
+				return err
+			}
+			if err := failingPoliciesSet.RemoveHosts(policy.ID, hosts); err != nil {
+				return ctxerr.Wrapf(ctx, err, "removing %d hosts from failing policies set %d", len(hosts), policy.ID)
+			}
+
+		case policies.FailingPolicyMicrosoftTeams:
+			hosts, err := failingPoliciesSet.ListHosts(policy.ID)
+			if err != nil {
+				return ctxerr.Wrapf(ctx, err, "listing hosts for failing policies set %d", policy.ID)
+			}
+			if err := worker.QueueMicrosoftTeamsFailingPolicyJob(ctx, ds, logger, policy, hosts); err != nil {
+				return err
+			}
+			if err := failingPoliciesSet.RemoveHosts(policy.ID, hosts); err != nil {
+				return ctxerr.Wrapf(ctx, err, "removing %d hosts from failing policies set %d", len(hosts), policy.ID)
+			}
+
+		case policies.FailingPolicyCustomHTTP:
+			hosts, err := failingPoliciesSet.ListHosts(policy.ID)
+			if err != nil {
+				return ctxerr.Wrapf(ctx, err, "listing hosts for failing policies set %d", policy.ID)
+			}
+			if err := worker.QueueCustomHTTPFailingPolicyJob(ctx, ds, logger, policy, hosts); err != nil {
+				return err
+			}
+			if err := failingPoliciesSet.RemoveHosts(policy.ID, hosts); err != nil {
+				return ctxerr.Wrapf(ctx, err, "removing %d hosts from failing policies set %d", len(hosts), policy.ID)
+			}
 		}
 		return nil
 	})
@@ -554,9 +698,11 @@ func newIntegrationsSchedule(
 
 	logger = kitlog.With(logger, "cron", name)
 
-	// create the worker and register the Jira and Zendesk jobs even if no
-	// integration is enabled, as that config can change live (and if it's not
-	// there won't be any records to process so it will mostly just sleep).
+	// create the worker and register the Jira, Zendesk, Slack, Microsoft Teams,
+	// custom HTTP and activity webhook jobs
+	// even if no integration is enabled, as that config can change live (and if
+	// it's not there won't be any records to process so it will mostly just
+	// sleep).
 	w := worker.NewWorker(ds, logger)
 	jira := &worker.Jira{
 		Datastore:     ds,
@@ -568,10 +714,33 @@ func newIntegrationsSchedule(
 		Log:           logger,
 		NewClientFunc: newZendeskClient,
 	}
+	slack := &worker.Slack{
+		Datastore:     ds,
+		Log:           logger,
+		NewClientFunc: newSlackClient,
+	}
+	msTeams := &worker.MicrosoftTeams{
+		Datastore:     ds,
+		Log:           logger,
+		NewClientFunc: newMicrosoftTeamsClient,
+	}
+	customHTTP := &worker.CustomHTTP{
+		Datastore:     ds,
+		Log:           logger,
+		NewClientFunc: newCustomHTTPClient,
+	}
 	// leave the url empty for now, will be filled when the lock is acquired with
 	// the up-to-date config.
+	activityWebhook := &worker.ActivityWebhook{
+		Datastore: ds,
+		Log:       logger,
+	}
 	w.Register(jira)
 	w.Register(zendesk)
+	w.Register(slack)
+	w.Register(msTeams)
+	w.Register(customHTTP)
+	w.Register(activityWebhook)
 
 	// Read app config a first time before starting, to clear up any failer client
 	// configuration if we're not on a fleet-owned server. Technically, the ServerURL
@@ -593,6 +762,12 @@ func newIntegrationsSchedule(
 		ctx, name, instanceID, defaultInterval, ds, ds,
 		schedule.WithAltLockID("worker"),
 		schedule.WithLogger(logger),
+		schedule.WithJob("queue_activities_webhooks", func(ctx context.Context) error {
+			return worker.QueueActivityWebhookJobs(ctx, ds, logger)
+		}),
+		schedule.WithJob("queue_resolved_policy_tickets", func(ctx context.Context) error {
+			return worker.QueueResolvedPolicyTicketJobs(ctx, ds, logger)
+		}),
 		schedule.WithJob("integrations_worker", func(ctx context.Context) error {
 			// Read app config to be able to use the latest configuration for integrations.
 			appConfig, err := ds.AppConfig(ctx)
@@ -602,6 +777,9 @@ func newIntegrationsSchedule(
 
 			jira.FleetURL = appConfig.ServerSettings.ServerURL
 			zendesk.FleetURL = appConfig.ServerSettings.ServerURL
+			slack.FleetURL = appConfig.ServerSettings.ServerURL
+			msTeams.FleetURL = appConfig.ServerSettings.ServerURL
+			customHTTP.FleetURL = appConfig.ServerSettings.ServerURL
 
 			workCtx, cancel := context.WithTimeout(ctx, defaultInterval)
 			if err := w.ProcessJobs(workCtx); err != nil {
@@ -651,6 +829,18 @@ func newZendeskClient(opts *externalsvc.ZendeskOptions) (worker.ZendeskClient, e
 	return client, nil
 }
 
+func newSlackClient(opts *externalsvc.SlackOptions) (worker.SlackClient, error) {
+	return externalsvc.NewSlackClient(opts)
+}
+
+func newMicrosoftTeamsClient(opts *externalsvc.MicrosoftTeamsOptions) (worker.MicrosoftTeamsClient, error) {
+	return externalsvc.NewMicrosoftTeamsClient(opts)
+}
+
+func newCustomHTTPClient(opts *externalsvc.CustomHTTPOptions) (worker.CustomHTTPClient, error) {
+	return externalsvc.NewCustomHTTPClient(opts)
+}
+
 func newFailerClient(forcedFailures string) *worker.TestAutomationFailer {
 	var failerClient *worker.TestAutomationFailer
 	if forcedFailures != "" {
@@ -674,6 +864,7 @@ func newCleanupsAndAggregationSchedule(
 	ctx context.Context,
 	instanceID string,
 	ds fleet.Datastore,
+	carveStore fleet.CarveStore,
 	logger kitlog.Logger,
 	enrollHostLimiter fleet.EnrollHostLimiter,
 	config *config.FleetConfig,
@@ -695,6 +886,18 @@ func newCleanupsAndAggregationSchedule(
 				return err
 			},
 		),
+		schedule.WithJob(
+			"distributed_query_campaign_results",
+			func(ctx context.Context) error {
+				// when the retention is disabled, purge the results that were
+				// persisted while it was enabled.
+				before := time.Now()
+				if config.App.LiveQueryResultsRetention > 0 {
+					before = before.Add(-config.App.LiveQueryResultsRetention)
+				}
+				return ds.CleanupDistributedQueryCampaignResults(ctx, before)
+			},
+		),
 		schedule.WithJob(
 			"incoming_hosts",
 			func(ctx context.Context) error {
@@ -705,7 +908,7 @@ func newCleanupsAndAggregationSchedule(
 		schedule.WithJob(
 			"carves",
 			func(ctx context.Context) error {
-				_, err := ds.CleanupCarves(ctx, time.Now())
+				_, err := carveStore.CleanupCarves(ctx, time.Now())
 				return err
 			},
 		),
@@ -716,6 +919,25 @@ func newCleanupsAndAggregationSchedule(
 				return err
 			},
 		),
+		schedule.WithJob(
+			"host_lifecycle",
+			func(ctx context.Context) error {
+				return hostlifecycle.Apply(ctx, ds, kitlog.With(logger, "job", "host_lifecycle"), time.Now())
+			},
+		),
+		schedule.WithJob(
+			"sync_label_membership",
+			func(ctx context.Context) error {
+				client := labelsync.NewClient(30 * time.Second)
+				return labelsync.Sync(ctx, ds, kitlog.With(logger, "job", "sync_label_membership"), client, time.Now())
+			},
+		),
+		schedule.WithJob(
+			"halt_update_rollouts",
+			func(ctx context.Context) error {
+				return updaterollouts.HaltOffline(ctx, ds, kitlog.With(logger, "job", "halt_update_rollouts"), time.Now())
+			},
+		),
 		schedule.WithJob(
 			"policy_membership",
 			func(ctx context.Context) error {
@@ -770,6 +992,12 @@ func newCleanupsAndAggregationSchedule(
 				return ds.IncrementPolicyViolationDays(ctx)
 			},
 		),
+		schedule.WithJob(
+			"snapshot_policy_stats",
+			func(ctx context.Context) error {
+				return snapshotPolicyStats(ctx, ds, config.App.PolicyHistoryRetention, time.Now())
+			},
+		),
 		schedule.WithJob(
 			"update_os_versions",
 			func(ctx context.Context) error {
@@ -787,6 +1015,20 @@ func newCleanupsAndAggregationSchedule(
 	return s, nil
 }
 
+// snapshotPolicyStats records the daily snapshot of the policies' passing and
+// failing host counts, and deletes the snapshots older than the retention
+// period. As the job runs multiple times a day, the snapshot of the current day
+// is updated on each run.
+func snapshotPolicyStats(ctx context.Context, ds fleet.Datastore, retention time.Duration, now time.Time) error {
+	if err := ds.SnapshotPolicyStats(ctx, now); err != nil {
+		return err
+	}
+	if retention <= 0 {
+		return nil
+	}
+	return ds.CleanupPolicyStatsHistory(ctx, now.Add(-retention))
+}
+
 func verifyDiskEncryptionKeys(
 	ctx context.Context,
 	logger kitlog.Logger,
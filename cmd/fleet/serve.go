@@ -33,6 +33,7 @@ import (
 	"github.com/fleetdm/fleet/v4/server/datastore/cached_mysql"
 	"github.com/fleetdm/fleet/v4/server/datastore/mysql"
 	"github.com/fleetdm/fleet/v4/server/datastore/mysqlredis"
+	"github.com/fleetdm/fleet/v4/server/datastore/objectstore"
 	"github.com/fleetdm/fleet/v4/server/datastore/redis"
 	"github.com/fleetdm/fleet/v4/server/datastore/s3"
 	"github.com/fleetdm/fleet/v4/server/errorstore"
@@ -184,13 +185,9 @@ the way that the Fleet server works.
 			}
 			ds = mds
 
-			if config.S3.Bucket != "" {
-				carveStore, err = s3.NewCarveStore(config.S3, ds)
-				if err != nil {
-					initFatal(err, "initializing S3 carvestore")
-				}
-			} else {
-				carveStore = ds
+			carveStore, err = newCarveStore(config, ds)
+			if err != nil {
+				initFatal(err, "initializing carvestore")
 			}
 
 			if config.Packaging.S3.Bucket != "" {
@@ -369,6 +366,24 @@ the way that the Fleet server works.
 					ContentTypeValue: config.KafkaREST.ContentTypeValue,
 					Timeout:          config.KafkaREST.Timeout,
 				},
+				HTTP: logging.HTTPConfig{
+					Authorization: config.HTTPLog.Authorization,
+					Batch:         httpLogBatchConfig(config.HTTPLog.HTTPLogBatchConfig),
+				},
+				Splunk: logging.SplunkConfig{
+					URL:        config.Splunk.URL,
+					Token:      config.Splunk.Token,
+					SourceType: config.Splunk.SourceType,
+					Source:     config.Splunk.Source,
+					Batch:      httpLogBatchConfig(config.Splunk.HTTPLogBatchConfig),
+				},
+				Elasticsearch: logging.ElasticsearchConfig{
+					URL:      config.Elasticsearch.URL,
+					Username: config.Elasticsearch.Username,
+					Password: config.Elasticsearch.Password,
+					APIKey:   config.Elasticsearch.APIKey,
+					Batch:    httpLogBatchConfig(config.Elasticsearch.HTTPLogBatchConfig),
+				},
 			}
 
 			// Set specific configuration to osqueryd status logs.
@@ -380,6 +395,10 @@ the way that the Fleet server works.
 			loggingConfig.PubSub.Topic = config.PubSub.StatusTopic
 			loggingConfig.PubSub.AddAttributes = false // only used by result logs
 			loggingConfig.KafkaREST.Topic = config.KafkaREST.StatusTopic
+			loggingConfig.HTTP.URL = config.HTTPLog.StatusURL
+			loggingConfig.Splunk.Index = config.Splunk.StatusIndex
+			loggingConfig.Elasticsearch.Index = config.Elasticsearch.StatusIndex
+			loggingConfig.Routes = logRoutes(config.Osquery.StatusLogRoutes)
 
 			osquerydStatusLogger, err := logging.NewJSONLogger("status", loggingConfig, logger)
 			if err != nil {
@@ -395,6 +414,10 @@ the way that the Fleet server works.
 			loggingConfig.PubSub.Topic = config.PubSub.ResultTopic
 			loggingConfig.PubSub.AddAttributes = config.PubSub.AddAttributes
 			loggingConfig.KafkaREST.Topic = config.KafkaREST.ResultTopic
+			loggingConfig.HTTP.URL = config.HTTPLog.ResultURL
+			loggingConfig.Splunk.Index = config.Splunk.ResultIndex
+			loggingConfig.Elasticsearch.Index = config.Elasticsearch.ResultIndex
+			loggingConfig.Routes = logRoutes(config.Osquery.ResultLogRoutes)
 
 			osquerydResultLogger, err := logging.NewJSONLogger("result", loggingConfig, logger)
 			if err != nil {
@@ -412,6 +435,10 @@ the way that the Fleet server works.
 				loggingConfig.PubSub.Topic = config.PubSub.AuditTopic
 				loggingConfig.PubSub.AddAttributes = false // only used by result logs
 				loggingConfig.KafkaREST.Topic = config.KafkaREST.AuditTopic
+				loggingConfig.HTTP.URL = config.HTTPLog.AuditURL
+				loggingConfig.Splunk.Index = config.Splunk.AuditIndex
+				loggingConfig.Elasticsearch.Index = config.Elasticsearch.AuditIndex
+				loggingConfig.Routes = nil // only used by osquery logs
 
 				auditLogger, err = logging.NewJSONLogger("audit", loggingConfig, logger)
 				if err != nil {
@@ -614,8 +641,14 @@ the way that the Fleet server works.
 				initFatal(errors.New("Error generating random instance identifier"), "")
 			}
 
+			// The S3 carve store relies on the bucket lifecycle configuration to
+			// delete the carves data, so only the metadata is cleaned up for it.
+			carveCleaner := carveStore
+			if _, ok := carveStore.(*s3.CarveStore); ok {
+				carveCleaner = ds
+			}
 			if err := cronSchedules.StartCronSchedule(func() (fleet.CronSchedule, error) {
-				return newCleanupsAndAggregationSchedule(ctx, instanceID, ds, logger, redisWrapperDS, &config)
+				return newCleanupsAndAggregationSchedule(ctx, instanceID, ds, carveCleaner, logger, redisWrapperDS, &config)
 			}); err != nil {
 				initFatal(err, "failed to register cleanups_then_aggregations schedule")
 			}
@@ -1067,3 +1100,91 @@ func (m *debugMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	m.fleetAuthenticatedHandler.ServeHTTP(w, r)
 }
+
+// newCarveStore returns the store of the file carves, as configured in the
+// carves section. The carves metadata is always stored in the datastore.
+func newCarveStore(config configpkg.FleetConfig, ds fleet.Datastore) (fleet.CarveStore, error) {
+	storage := config.Carves.Storage
+	if storage == "" {
+		storage = "mysql"
+		if config.S3.Bucket != "" {
+			storage = "s3"
+		}
+	}
+
+	var carveStore fleet.CarveStore
+	switch storage {
+	case "mysql":
+		carveStore = ds
+	case "s3":
+		if config.Carves.EncryptionKey != "" {
+			return nil, errors.New("carves encryption is not supported with the s3 storage, use the object_store storage or the bucket encryption instead")
+		}
+		store, err := s3.NewCarveStore(config.S3, ds)
+		if err != nil {
+			return nil, fmt.Errorf("initializing S3 carvestore: %w", err)
+		}
+		carveStore = store
+	case "filesystem":
+		store, err := objectstore.NewFilesystemStore(config.Carves.FilesystemDir)
+		if err != nil {
+			return nil, fmt.Errorf("initializing filesystem carvestore: %w", err)
+		}
+		carveStore = objectstore.NewCarveStore(store, "", ds)
+	case "object_store":
+		if config.S3.Bucket == "" {
+			return nil, errors.New("the object_store carves storage requires the s3 bucket to be set")
+		}
+		store, err := s3.NewObjectStore(config.S3)
+		if err != nil {
+			return nil, fmt.Errorf("initializing object store carvestore: %w", err)
+		}
+		carveStore = objectstore.NewCarveStore(store, config.S3.Prefix, ds)
+	default:
+		return nil, fmt.Errorf("unknown carves storage: %s", storage)
+	}
+
+	if config.Carves.EncryptionKey != "" {
+		key, err := objectstore.ParseEncryptionKey(config.Carves.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("carves encryption key: %w", err)
+		}
+		return objectstore.NewEncryptedCarveStore(carveStore, key)
+	}
+	return carveStore, nil
+}
+
+// httpLogBatchConfig converts the batching options of an HTTP-based logging
+// plugin from the Fleet config to the logging package config.
+func httpLogBatchConfig(conf configpkg.HTTPLogBatchConfig) logging.HTTPBatchConfig {
+	return logging.HTTPBatchConfig{
+		MaxRecordsInBatch: conf.MaxBatchRecords,
+		MaxSizeOfRecord:   conf.MaxRecordSize,
+		MaxSizeOfBatch:    conf.MaxBatchSize,
+		MaxRetries:        conf.MaxRetries,
+		Timeout:           conf.Timeout,
+		TLSCA:             conf.TLSCA,
+		TLSCert:           conf.TLSCert,
+		TLSKey:            conf.TLSKey,
+		TLSServerName:     conf.TLSServerName,
+	}
+}
+
+// logRoutes converts the logging routes from the Fleet config to the logging
+// package config.
+func logRoutes(routes []configpkg.LogRoute) []logging.Route {
+	if len(routes) == 0 {
+		return nil
+	}
+	res := make([]logging.Route, 0, len(routes))
+	for _, r := range routes {
+		res = append(res, logging.Route{
+			Plugins: r.Plugins,
+			Names:   r.Names,
+			Packs:   r.Packs,
+			Queries: r.Queries,
+			TeamIDs: r.TeamIDs,
+		})
+	}
+	return res
+}
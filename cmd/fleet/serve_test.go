@@ -19,6 +19,7 @@ import (
 	"github.com/fleetdm/fleet/v4/pkg/nettest"
 	"github.com/fleetdm/fleet/v4/server/config"
 	"github.com/fleetdm/fleet/v4/server/contexts/license"
+	"github.com/fleetdm/fleet/v4/server/datastore/objectstore"
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/fleetdm/fleet/v4/server/mock"
 	"github.com/fleetdm/fleet/v4/server/ptr"
@@ -470,7 +471,7 @@ func TestScanVulnerabilities(t *testing.T) {
 			},
 		}, nil
 	}
-	ds.HostsBySoftwareIDsFunc = func(ctx context.Context, softwareIDs []uint) ([]*fleet.HostShort, error) {
+	ds.VulnerableHostsBySoftwareIDsFunc = func(ctx context.Context, softwareIDs []uint, cve string) ([]*fleet.HostShort, error) {
 		return []*fleet.HostShort{
 			{
 				ID:          1,
@@ -494,6 +495,16 @@ func TestScanVulnerabilities(t *testing.T) {
 		return iterator, nil
 	}
 
+	ds.ListSoftwareVulnerabilitiesBySourceFunc = func(ctx context.Context, source fleet.VulnerabilitySource) ([]fleet.SoftwareVulnerability, error) {
+		require.Equal(t, fleet.OSVSource, source)
+		return nil, nil
+	}
+	ds.SyncHostVulnerabilityRemediationsFunc = func(ctx context.Context, now time.Time) error {
+		return nil
+	}
+	ds.ListUnnotifiedVulnerabilitySLABreachesFunc = func(ctx context.Context, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, error) {
+		return nil, nil
+	}
 	vulnPath := t.TempDir()
 
 	config := config.VulnerabilitiesConfig{
@@ -509,6 +520,10 @@ func TestScanVulnerabilities(t *testing.T) {
 	// ensure that nvd vulnerabilities are not deleted
 	require.False(t, ds.DeleteSoftwareVulnerabilitiesFuncInvoked)
 
+	// ensure that the remediations were synced and the sla breaches checked
+	require.True(t, ds.SyncHostVulnerabilityRemediationsFuncInvoked)
+	require.True(t, ds.ListUnnotifiedVulnerabilitySLABreachesFuncInvoked)
+
 	// ensure that webhook was called
 	require.Equal(t, 1, webhookCount)
 }
@@ -1075,3 +1090,80 @@ func TestVerifyDiskEncryptionKeysJob(t *testing.T) {
 		require.Equal(t, 2, calls)
 	})
 }
+
+func TestSnapshotPolicyStats(t *testing.T) {
+	ds := new(mock.Store)
+	ctx := context.Background()
+	now := time.Date(2023, 3, 14, 10, 0, 0, 0, time.UTC)
+
+	var snapshotAt time.Time
+	ds.SnapshotPolicyStatsFunc = func(ctx context.Context, now time.Time) error {
+		snapshotAt = now
+		return nil
+	}
+	var cleanupBefore time.Time
+	ds.CleanupPolicyStatsHistoryFunc = func(ctx context.Context, before time.Time) error {
+		cleanupBefore = before
+		return nil
+	}
+
+	require.NoError(t, snapshotPolicyStats(ctx, ds, 30*24*time.Hour, now))
+	require.Equal(t, now, snapshotAt)
+	require.True(t, ds.CleanupPolicyStatsHistoryFuncInvoked)
+	require.Equal(t, time.Date(2023, 2, 12, 10, 0, 0, 0, time.UTC), cleanupBefore)
+
+	// a zero retention keeps the history forever
+	ds.CleanupPolicyStatsHistoryFuncInvoked = false
+	require.NoError(t, snapshotPolicyStats(ctx, ds, 0, now))
+	require.True(t, ds.SnapshotPolicyStatsFuncInvoked)
+	require.False(t, ds.CleanupPolicyStatsHistoryFuncInvoked)
+
+	// the history is not cleaned up if the snapshot fails
+	ds.SnapshotPolicyStatsFunc = func(ctx context.Context, now time.Time) error {
+		return errors.New("snapshot failed")
+	}
+	require.Error(t, snapshotPolicyStats(ctx, ds, 30*24*time.Hour, now))
+	require.False(t, ds.CleanupPolicyStatsHistoryFuncInvoked)
+}
+
+func TestNewCarveStore(t *testing.T) {
+	ds := new(mock.Store)
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	store, err := newCarveStore(config.FleetConfig{}, ds)
+	require.NoError(t, err)
+	require.Equal(t, ds, store)
+
+	store, err = newCarveStore(config.FleetConfig{Carves: config.CarvesConfig{EncryptionKey: key}}, ds)
+	require.NoError(t, err)
+	require.IsType(t, &objectstore.EncryptedCarveStore{}, store)
+
+	store, err = newCarveStore(config.FleetConfig{Carves: config.CarvesConfig{
+		Storage:       "filesystem",
+		FilesystemDir: t.TempDir(),
+	}}, ds)
+	require.NoError(t, err)
+	require.IsType(t, &objectstore.CarveStore{}, store)
+
+	_, err = newCarveStore(config.FleetConfig{Carves: config.CarvesConfig{Storage: "filesystem"}}, ds)
+	require.ErrorContains(t, err, "missing directory")
+
+	_, err = newCarveStore(config.FleetConfig{Carves: config.CarvesConfig{Storage: "object_store"}}, ds)
+	require.ErrorContains(t, err, "requires the s3 bucket")
+
+	_, err = newCarveStore(config.FleetConfig{
+		S3:     config.S3Config{Bucket: "carves", Region: "us-east-1"},
+		Carves: config.CarvesConfig{EncryptionKey: key},
+	}, ds)
+	require.ErrorContains(t, err, "not supported with the s3 storage")
+
+	_, err = newCarveStore(config.FleetConfig{Carves: config.CarvesConfig{
+		Storage:       "filesystem",
+		FilesystemDir: t.TempDir(),
+		EncryptionKey: "invalid",
+	}}, ds)
+	require.ErrorContains(t, err, "carves encryption key")
+
+	_, err = newCarveStore(config.FleetConfig{Carves: config.CarvesConfig{Storage: "foo"}}, ds)
+	require.ErrorContains(t, err, "unknown carves storage: foo")
+}
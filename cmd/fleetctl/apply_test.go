@@ -247,6 +247,97 @@ spec:
 	require.Equal(t, "[+] applied 1 teams\n", runAppForTest(t, []string{"apply", "-f", filename}))
 	// agent options provided but empty, clears the value
 	assert.Nil(t, teamsByName["team1"].Config.AgentOptions)
+	assert.False(t, ds.ReplaceTeamScheduledQueryOverridesFuncInvoked)
+
+	ds.EnsureGlobalPackFunc = func(ctx context.Context) (*fleet.Pack, error) {
+		return &fleet.Pack{ID: 1}, nil
+	}
+	ds.ListScheduledQueriesInPackFunc = func(ctx context.Context, packID uint) (fleet.ScheduledQueryList, error) {
+		return fleet.ScheduledQueryList{{ID: 11, Name: "time"}, {ID: 12, Name: "uptime"}}, nil
+	}
+	var scheduleOverrides []*fleet.TeamScheduledQueryOverride
+	ds.ReplaceTeamScheduledQueryOverridesFunc = func(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error {
+		assert.Equal(t, uint(42), teamID)
+		scheduleOverrides = overrides
+		return nil
+	}
+
+	filename = writeTmpYml(t, `
+apiVersion: v1
+kind: team
+spec:
+  team:
+    name: team1
+    schedule_overrides:
+      - name: time
+        disabled: true
+      - name: uptime
+        interval: 3600
+        shard: 10
+        platform: linux
+`)
+
+	require.Equal(t, "[+] applied 1 teams\n", runAppForTest(t, []string{"apply", "-f", filename}))
+	assert.Equal(t, []*fleet.TeamScheduledQueryOverride{
+		{ScheduledQueryID: 11, Name: "time", Disabled: true},
+		{ScheduledQueryID: 12, Name: "uptime", Interval: ptr.Uint(3600), Shard: ptr.Uint(10), Platform: ptr.String("linux")},
+	}, scheduleOverrides)
+
+	filename = writeTmpYml(t, `
+apiVersion: v1
+kind: team
+spec:
+  team:
+    name: team1
+    schedule_overrides:
+      - name: no-such-query
+        disabled: true
+`)
+	runAppCheckErr(t, []string{"apply", "-f", filename}, `applying teams: POST /api/latest/fleet/spec/teams received status 422 Validation Failed: "no-such-query" is not a query of the global schedule`)
+
+	ds.TeamHierarchyFunc = func(ctx context.Context) (fleet.TeamHierarchy, error) {
+		h := make(fleet.TeamHierarchy)
+		for _, tm := range teamsByName {
+			h[tm.ID] = 0
+			if tm.ParentTeamID != nil {
+				h[tm.ID] = *tm.ParentTeamID
+			}
+		}
+		return h, nil
+	}
+
+	filename = writeTmpYml(t, `
+apiVersion: v1
+kind: team
+spec:
+  team:
+    name: child
+    parent_team: team1
+`)
+	require.Equal(t, "[+] applied 1 teams\n", runAppForTest(t, []string{"apply", "-f", filename}))
+	require.Equal(t, ptr.Uint(42), teamsByName["child"].ParentTeamID)
+	// agent options are inherited from the parent team
+	assert.Nil(t, teamsByName["child"].Config.AgentOptions)
+
+	filename = writeTmpYml(t, `
+apiVersion: v1
+kind: team
+spec:
+  team:
+    name: team1
+    parent_team: child
+`)
+	runAppCheckErr(t, []string{"apply", "-f", filename}, `applying teams: POST /api/latest/fleet/spec/teams received status 422 Validation Failed: a team cannot be the child of one of its descendants`)
+
+	filename = writeTmpYml(t, `
+apiVersion: v1
+kind: team
+spec:
+  team:
+    name: child
+    parent_team: no-such-team
+`)
+	runAppCheckErr(t, []string{"apply", "-f", filename}, `applying teams: POST /api/latest/fleet/spec/teams received status 422 Validation Failed: team "no-such-team" does not exist`)
 }
 
 func writeTmpYml(t *testing.T, contents string) string {
@@ -647,6 +738,51 @@ spec:
 	assert.Equal(t, "select 1;", appliedLabels[0].Query)
 }
 
+func TestApplyCPETranslations(t *testing.T) {
+	_, ds := runServerWithMockedDS(t)
+
+	var appliedRules []*fleet.CPETranslationRuleSpec
+	ds.ApplyCPETranslationRuleSpecsFunc = func(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error {
+		appliedRules = specs
+		return nil
+	}
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+		return nil
+	}
+
+	name := writeTmpYml(t, `---
+apiVersion: v1
+kind: cpe_translation
+spec:
+  name: acme-vpn
+  software:
+    name: ["/^Acme VPN/"]
+    source: ["apps"]
+  filter:
+    product: ["vpn_client"]
+    vendor: ["acme"]
+`)
+
+	assert.Equal(t, "[+] applied 1 cpe translations\n", runAppForTest(t, []string{"apply", "-f", name}))
+	assert.True(t, ds.ApplyCPETranslationRuleSpecsFuncInvoked)
+	require.Len(t, appliedRules, 1)
+	assert.Equal(t, "acme-vpn", appliedRules[0].Name)
+	assert.Equal(t, []string{"/^Acme VPN/"}, appliedRules[0].Software.Name)
+	assert.Equal(t, []string{"acme"}, appliedRules[0].Filter.Vendor)
+
+	// invalid rules are rejected by the server
+	name = writeTmpYml(t, `---
+apiVersion: v1
+kind: cpe_translation
+spec:
+  name: acme-vpn
+  software:
+    name: ["Acme VPN"]
+`)
+	_, err := runAppNoChecks([]string{"apply", "-f", name})
+	require.ErrorContains(t, err, "filter requires a product or a vendor")
+}
+
 func TestApplyPacks(t *testing.T) {
 	_, ds := runServerWithMockedDS(t)
 
@@ -88,6 +88,19 @@ func deleteCommand() *cli.Command {
 				}
 			}
 
+			for _, rule := range specs.CPETranslations {
+				fmt.Printf("[+] deleting cpe translation %q\n", rule.Name)
+				if err := fleet.DeleteCPETranslationRule(rule.Name); err != nil {
+					root := ctxerr.Cause(err)
+					switch root.(type) {
+					case service.NotFoundErr:
+						fmt.Printf("[!] cpe translation %q doesn't exist\n", rule.Name)
+						continue
+					}
+					return err
+				}
+			}
+
 			return nil
 		},
 	}
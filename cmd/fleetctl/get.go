@@ -1,17 +1,26 @@
 package main
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/fleetdm/fleet/v4/pkg/secure"
+	"github.com/klauspost/compress/zstd"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/fleetdm/fleet/v4/server/fleet"
@@ -27,6 +36,12 @@ const (
 	withQueriesFlagName         = "with-queries"
 	expiredFlagName             = "expired"
 	includeServerConfigFlagName = "include-server-config"
+	activityTypeFlagName        = "activity-type"
+	policyIDFlagName            = "policy-id"
+	fromFlagName                = "from"
+	toFlagName                  = "to"
+	unpackFlagName              = "unpack"
+	verifyFlagName              = "verify"
 )
 
 type specGeneric struct {
@@ -119,6 +134,16 @@ func printPack(c *cli.Context, pack *fleet.PackSpec) error {
 	return printSpec(c, spec)
 }
 
+func printCPETranslation(c *cli.Context, rule *fleet.CPETranslationRuleSpec) error {
+	spec := specGeneric{
+		Kind:    fleet.CPETranslationKind,
+		Version: fleet.ApiVersion,
+		Spec:    rule,
+	}
+
+	return printSpec(c, spec)
+}
+
 func printSecret(c *cli.Context, secret *fleet.EnrollSecretSpec) error {
 	spec := specGeneric{
 		Kind:    fleet.EnrollSecretKind,
@@ -289,6 +314,9 @@ func getCommand() *cli.Command {
 			getSoftwareCommand(),
 			getMDMAppleCommand(),
 			getMDMAppleBMCommand(),
+			getWebhookDeliveriesCommand(),
+			getPolicyHistoryCommand(),
+			getCPETranslationsCommand(),
 		},
 	}
 }
@@ -778,6 +806,14 @@ func getCarveCommand() *cli.Command {
 			configFlag(),
 			contextFlag(),
 			outfileFlag(),
+			&cli.StringFlag{
+				Name:  unpackFlagName,
+				Usage: "Extract the contents of the carve into this directory",
+			},
+			&cli.BoolFlag{
+				Name:  verifyFlagName,
+				Usage: "Verify the SHA-256 hash of the downloaded carve contents",
+			},
 			debugFlag(),
 		},
 		Action: func(c *cli.Context) error {
@@ -799,10 +835,15 @@ func getCarveCommand() *cli.Command {
 
 			outFile := getOutfile(c)
 			stdout := c.Bool(stdoutFlagName)
+			unpackDir := c.String(unpackFlagName)
+			verify := c.Bool(verifyFlagName)
 
 			if stdout && outFile != "" {
 				return errors.New("-stdout and -outfile must not be specified together")
 			}
+			if stdout && unpackDir != "" {
+				return errors.New("-stdout and -unpack must not be specified together")
+			}
 
 			carve, err := client.GetCarve(id)
 			if err != nil {
@@ -813,26 +854,51 @@ func getCarveCommand() *cli.Command {
 				return errors.New(*carve.Error)
 			}
 
-			if stdout || outFile != "" {
-				out := os.Stdout
-				if outFile != "" {
-					f, err := secure.OpenFile(outFile, os.O_CREATE|os.O_WRONLY, defaultFileMode)
-					if err != nil {
-						return fmt.Errorf("open out file: %w", err)
-					}
-					defer f.Close()
-					out = f
-				}
+			if verify && carve.SHA256 == nil {
+				return errors.New("the carve has no SHA-256 hash to verify, it is not complete or was received by an older Fleet version")
+			}
 
+			if stdout || outFile != "" || unpackDir != "" || verify {
 				reader, err := client.DownloadCarve(id)
 				if err != nil {
 					return err
 				}
+				defer reader.Close()
+
+				var src io.Reader = reader
+				hash := sha256.New()
+				if verify {
+					src = io.TeeReader(src, hash)
+				}
+				if stdout || outFile != "" {
+					out := os.Stdout
+					if outFile != "" {
+						f, err := secure.OpenFile(outFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, defaultFileMode)
+						if err != nil {
+							return fmt.Errorf("open out file: %w", err)
+						}
+						defer f.Close()
+						out = f
+					}
+					src = io.TeeReader(src, out)
+				}
 
-				if _, err := io.Copy(out, reader); err != nil {
+				if unpackDir != "" {
+					if err := unpackCarve(src, unpackDir); err != nil {
+						return fmt.Errorf("unpack carve contents: %w", err)
+					}
+				}
+				// read what's left, the tar archive may have trailing padding
+				if _, err := io.Copy(io.Discard, src); err != nil {
 					return fmt.Errorf("download carve contents: %w", err)
 				}
 
+				if verify {
+					if sum := hex.EncodeToString(hash.Sum(nil)); sum != *carve.SHA256 {
+						return fmt.Errorf("SHA-256 mismatch: expected %s, got %s", *carve.SHA256, sum)
+					}
+					fmt.Fprintf(c.App.ErrWriter, "SHA-256 verified: %s\n", *carve.SHA256)
+				}
 				return nil
 			}
 
@@ -845,6 +911,66 @@ func getCarveCommand() *cli.Command {
 	}
 }
 
+// zstdMagic starts the carves compressed with osquery's carver_compression.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// unpackCarve extracts the regular files and directories of the tar archive
+// of a carve into dir. The paths of the archive (usually absolute paths of
+// the host) are made relative to dir.
+func unpackCarve(r io.Reader, dir string) error {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, _ := br.Peek(len(zstdMagic)); bytes.Equal(magic, zstdMagic) {
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("open zstd stream: %w", err)
+		}
+		defer zr.Close()
+		src = zr
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read tar: %w", err)
+		}
+
+		// cleaning the rooted path removes any ".." element.
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+hdr.Name)))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("create directory: %w", err)
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return fmt.Errorf("create file: %w", err)
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("write file %s: %w", hdr.Name, err)
+			}
+		default:
+			// links and special files are not extracted
+		}
+	}
+}
+
 func log(c *cli.Context, msg ...interface{}) {
 	fmt.Fprint(c.App.Writer, msg...)
 }
@@ -1069,6 +1195,187 @@ func getSoftwareCommand() *cli.Command {
 	}
 }
 
+func getWebhookDeliveriesCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "webhook_deliveries",
+		Aliases: []string{"webhook-deliveries", "wd"},
+		Usage:   "List the deliveries of activities to the activities webhooks",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  statusFlagName,
+				Usage: "Only list deliveries with the specified status (pending, success or failed)",
+			},
+			&cli.StringFlag{
+				Name:  activityTypeFlagName,
+				Usage: "Only list deliveries of activities of the specified type",
+			},
+			jsonFlag(),
+			yamlFlag(),
+			configFlag(),
+			contextFlag(),
+			debugFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool(yamlFlagName) && c.Bool(jsonFlagName) {
+				return errors.New("Can't specify both yaml and json flags.")
+			}
+
+			query := url.Values{}
+			if status := c.String(statusFlagName); status != "" {
+				query.Set("status", status)
+			}
+			if activityType := c.String(activityTypeFlagName); activityType != "" {
+				query.Set("activity_type", activityType)
+			}
+
+			deliveries, err := client.ListActivityWebhookDeliveries(query.Encode())
+			if err != nil {
+				return fmt.Errorf("could not list webhook deliveries: %w", err)
+			}
+
+			if len(deliveries) == 0 {
+				log(c, "No webhook deliveries found")
+				return nil
+			}
+
+			if c.Bool(jsonFlagName) || c.Bool(yamlFlagName) {
+				spec := specGeneric{
+					Kind:    "webhook_deliveries",
+					Version: "1",
+					Spec:    deliveries,
+				}
+				return printSpec(c, spec)
+			}
+
+			data := [][]string{}
+			for _, d := range deliveries {
+				response := ""
+				if d.ResponseStatus != nil {
+					response = fmt.Sprint(*d.ResponseStatus)
+				}
+				data = append(data, []string{
+					fmt.Sprint(d.ID),
+					fmt.Sprint(d.ActivityID),
+					d.ActivityType,
+					d.DestinationURL,
+					string(d.Status),
+					fmt.Sprint(d.Attempts),
+					response,
+					d.UpdatedAt.Format(time.RFC3339),
+				})
+			}
+			columns := []string{"ID", "Activity ID", "Activity type", "URL", "Status", "Attempts", "Response", "Updated at"}
+			printTable(c, columns, data)
+
+			return nil
+		},
+	}
+}
+
+func getPolicyHistoryCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "policy_history",
+		Aliases: []string{"policy-history"},
+		Usage:   "List the daily snapshots of the policies' passing and failing host counts per team",
+		Flags: []cli.Flag{
+			&cli.UintFlag{
+				Name:  policyIDFlagName,
+				Usage: "Only list the history of the specified policy",
+			},
+			&cli.UintFlag{
+				Name:  teamFlagName,
+				Usage: "Only list the history of the hosts of the specified team ID (0 for hosts without a team)",
+			},
+			&cli.StringFlag{
+				Name:  fromFlagName,
+				Usage: "Only list the snapshots taken on or after the specified day (YYYY-MM-DD)",
+			},
+			&cli.StringFlag{
+				Name:  toFlagName,
+				Usage: "Only list the snapshots taken on or before the specified day (YYYY-MM-DD)",
+			},
+			jsonFlag(),
+			yamlFlag(),
+			configFlag(),
+			contextFlag(),
+			debugFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool(yamlFlagName) && c.Bool(jsonFlagName) {
+				return errors.New("Can't specify both yaml and json flags.")
+			}
+
+			query := url.Values{}
+			if c.IsSet(policyIDFlagName) {
+				query.Set("policy_id", fmt.Sprint(c.Uint(policyIDFlagName)))
+			}
+			if c.IsSet(teamFlagName) {
+				query.Set("team_id", fmt.Sprint(c.Uint(teamFlagName)))
+			}
+			if from := c.String(fromFlagName); from != "" {
+				query.Set("from", from)
+			}
+			if to := c.String(toFlagName); to != "" {
+				query.Set("to", to)
+			}
+
+			history, err := client.ListPolicyHistory(query.Encode())
+			if err != nil {
+				return fmt.Errorf("could not list policy history: %w", err)
+			}
+
+			if len(history) == 0 {
+				log(c, "No policy history found")
+				return nil
+			}
+
+			if c.Bool(jsonFlagName) || c.Bool(yamlFlagName) {
+				spec := specGeneric{
+					Kind:    "policy_history",
+					Version: "1",
+					Spec:    history,
+				}
+				return printSpec(c, spec)
+			}
+
+			data := [][]string{}
+			for _, h := range history {
+				team := h.TeamName
+				if h.TeamID == nil {
+					team = "No team"
+				}
+				compliance := ""
+				if total := h.PassingHostCount + h.FailingHostCount; total > 0 {
+					compliance = fmt.Sprintf("%.1f%%", float64(h.PassingHostCount)*100/float64(total))
+				}
+				data = append(data, []string{
+					h.Date,
+					fmt.Sprint(h.PolicyID),
+					h.PolicyName,
+					team,
+					fmt.Sprint(h.PassingHostCount),
+					fmt.Sprint(h.FailingHostCount),
+					compliance,
+				})
+			}
+			columns := []string{"Date", "Policy ID", "Policy", "Team", "Passing", "Failing", "Compliance"}
+			printTable(c, columns, data)
+
+			return nil
+		},
+	}
+}
+
 func getMDMAppleCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "mdm_apple",
@@ -1173,3 +1480,78 @@ func getMDMAppleBMCommand() *cli.Command {
 		},
 	}
 }
+
+func getCPETranslationsCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "cpe_translations",
+		Aliases: []string{"cpe_translation", "cpe-translations", "cpe-translation"},
+		Usage:   "List the custom CPE translation rules",
+		Flags: []cli.Flag{
+			jsonFlag(),
+			yamlFlag(),
+			configFlag(),
+			contextFlag(),
+			debugFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			client, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			rules, err := client.GetCPETranslationRules()
+			if err != nil {
+				return fmt.Errorf("could not list cpe translations: %w", err)
+			}
+
+			if c.Bool(yamlFlagName) || c.Bool(jsonFlagName) {
+				for _, rule := range rules {
+					printCPETranslation(c, rule) //nolint:errcheck
+				}
+				return nil
+			}
+
+			if len(rules) == 0 {
+				fmt.Println("No cpe translations found")
+				return nil
+			}
+
+			data := [][]string{}
+			for _, rule := range rules {
+				filter := "skip"
+				if !rule.Filter.Skip {
+					filter = formatCPETranslationCriteria(
+						[]string{"product", "vendor", "target_sw"},
+						rule.Filter.Product, rule.Filter.Vendor, rule.Filter.TargetSW,
+					)
+				}
+				data = append(data, []string{
+					rule.Name,
+					rule.Description,
+					formatCPETranslationCriteria(
+						[]string{"name", "bundle_identifier", "source"},
+						rule.Software.Name, rule.Software.BundleIdentifier, rule.Software.Source,
+					),
+					filter,
+				})
+			}
+
+			columns := []string{"name", "description", "software", "filter"}
+			printTable(c, columns, data)
+
+			return nil
+		},
+	}
+}
+
+// formatCPETranslationCriteria formats the non-empty values of the criteria
+// of a CPE translation rule, e.g. "name: Foo, /^Bar/; source: apps".
+func formatCPETranslationCriteria(names []string, values ...[]string) string {
+	var parts []string
+	for i, name := range names {
+		if len(values[i]) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", name, strings.Join(values[i], ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
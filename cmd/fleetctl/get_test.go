@@ -1,8 +1,11 @@
 package main
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -162,6 +165,10 @@ func TestGetTeams(t *testing.T) {
 								},
 							},
 						},
+						ScheduleOverrides: []*fleet.TeamScheduledQueryOverride{
+							{TeamID: 43, ScheduledQueryID: 1, Name: "time", Disabled: true},
+							{TeamID: 43, ScheduledQueryID: 2, Name: "uptime", Interval: ptr.Uint(3600), Shard: ptr.Uint(10)},
+						},
 					},
 				}, nil
 			}
@@ -313,6 +320,12 @@ func TestGetHosts(t *testing.T) {
 	ds.ListHostBatteriesFunc = func(ctx context.Context, hid uint) (batteries []*fleet.HostBattery, err error) {
 		return nil, nil
 	}
+	ds.HostOrbitChannelsFunc = func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+		return nil, nil
+	}
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return nil, nil
+	}
 	defaultPolicyQuery := "select 1 from osquery_info where start_time > 1;"
 	ds.ListPoliciesForHostFunc = func(ctx context.Context, host *fleet.Host) ([]*fleet.HostPolicy, error) {
 		return []*fleet.HostPolicy{
@@ -599,6 +612,80 @@ spec:
 	assert.Equal(t, uint(999), *gotTeamID)
 }
 
+func TestGetWebhookDeliveries(t *testing.T) {
+	_, ds := runServerWithMockedDS(t)
+
+	updatedAt := time.Date(2023, 3, 7, 10, 0, 0, 0, time.UTC)
+	var gotOpts fleet.ListActivityWebhookDeliveriesOptions
+	ds.ListActivityWebhookDeliveriesFunc = func(ctx context.Context, opt fleet.ListActivityWebhookDeliveriesOptions) ([]*fleet.ActivityWebhookDelivery, *fleet.PaginationMetadata, error) {
+		gotOpts = opt
+		return []*fleet.ActivityWebhookDelivery{
+			{
+				ID: 2, ActivityID: 5, ActivityType: "created_pack", DestinationURL: "https://example.com/hook",
+				Status: fleet.ActivityWebhookDeliveryFailed, Attempts: 6, ResponseStatus: ptr.Int(500),
+				Error: ptr.String("server error"), UpdatedAt: updatedAt,
+			},
+			{
+				ID: 1, ActivityID: 4, ActivityType: "deleted_pack", DestinationURL: "https://example.com/hook",
+				Status: fleet.ActivityWebhookDeliveryPending, UpdatedAt: updatedAt,
+			},
+		}, nil, nil
+	}
+
+	expected := `+----+-------------+---------------+--------------------------+---------+----------+----------+----------------------+
+| ID | ACTIVITY ID | ACTIVITY TYPE |           URL            | STATUS  | ATTEMPTS | RESPONSE |      UPDATED AT      |
++----+-------------+---------------+--------------------------+---------+----------+----------+----------------------+
+|  2 |           5 | created_pack  | https://example.com/hook | failed  |        6 |      500 | 2023-03-07T10:00:00Z |
++----+-------------+---------------+--------------------------+---------+----------+----------+----------------------+
+|  1 |           4 | deleted_pack  | https://example.com/hook | pending |        0 |          | 2023-03-07T10:00:00Z |
++----+-------------+---------------+--------------------------+---------+----------+----------+----------------------+
+`
+	assert.Equal(t, expected, runAppForTest(t, []string{"get", "webhook_deliveries"}))
+	assert.Empty(t, gotOpts.Status)
+	assert.Empty(t, gotOpts.ActivityType)
+
+	runAppForTest(t, []string{"get", "webhook-deliveries", "--status", "failed", "--activity-type", "created_pack", "--json"})
+	assert.Equal(t, fleet.ActivityWebhookDeliveryFailed, gotOpts.Status)
+	assert.Equal(t, "created_pack", gotOpts.ActivityType)
+}
+
+func TestGetPolicyHistory(t *testing.T) {
+	_, ds := runServerWithMockedDS(t)
+
+	var gotOpts fleet.PolicyStatsHistoryListOptions
+	ds.ListPolicyStatsHistoryFunc = func(ctx context.Context, opts fleet.PolicyStatsHistoryListOptions) ([]*fleet.PolicyStatsSnapshot, error) {
+		gotOpts = opts
+		return []*fleet.PolicyStatsSnapshot{
+			{Date: "2023-03-13", PolicyID: 1, PolicyName: "Gatekeeper enabled", PassingHostCount: 3, FailingHostCount: 1},
+			{Date: "2023-03-13", PolicyID: 1, PolicyName: "Gatekeeper enabled", TeamID: ptr.Uint(2), TeamName: "Workstations"},
+		}, nil
+	}
+
+	expected := `+------------+-----------+--------------------+--------------+---------+---------+------------+
+|    DATE    | POLICY ID |       POLICY       |     TEAM     | PASSING | FAILING | COMPLIANCE |
++------------+-----------+--------------------+--------------+---------+---------+------------+
+| 2023-03-13 |         1 | Gatekeeper enabled | No team      |       3 |       1 | 75.0%      |
++------------+-----------+--------------------+--------------+---------+---------+------------+
+| 2023-03-13 |         1 | Gatekeeper enabled | Workstations |       0 |       0 |            |
++------------+-----------+--------------------+--------------+---------+---------+------------+
+`
+	assert.Equal(t, expected, runAppForTest(t, []string{"get", "policy-history"}))
+	assert.Nil(t, gotOpts.PolicyID)
+	assert.Nil(t, gotOpts.TeamID)
+	assert.Empty(t, gotOpts.From)
+
+	runAppForTest(t, []string{"get", "policy_history", "--policy-id", "1", "--team", "0", "--from", "2023-03-01", "--to", "2023-03-13", "--json"})
+	require.NotNil(t, gotOpts.PolicyID)
+	assert.Equal(t, uint(1), *gotOpts.PolicyID)
+	require.NotNil(t, gotOpts.TeamID)
+	assert.Equal(t, uint(0), *gotOpts.TeamID)
+	assert.Equal(t, "2023-03-01", gotOpts.From)
+	assert.Equal(t, "2023-03-13", gotOpts.To)
+
+	_, err := runAppNoChecks([]string{"get", "policy-history", "--from", "03/01/2023"})
+	require.ErrorContains(t, err, "invalid from date")
+}
+
 func TestGetLabels(t *testing.T) {
 	_, ds := runServerWithMockedDS(t)
 
@@ -1031,11 +1118,15 @@ func TestEnrichedAppConfig(t *testing.T) {
             "destination_url": "",
             "host_batch_size": 0
           },
+          "activities_webhooks": null,
           "interval": "24h0m0s"
         },
         "integrations": {
           "jira": null,
-          "zendesk": null
+          "zendesk": null,
+          "slack": null,
+          "microsoft_teams": null,
+          "custom_http": null
         },
         "update_interval": {
           "osquery_detail": 3600000000000,
@@ -1194,13 +1285,83 @@ host_id: 1
 id: 0
 max_block: 0
 name: foobar
+progress: 9.75
+received_bytes: 12
 request_id: request_id_1
 session_id: session_id_1
+sha256: null
 `
 
 	assert.Equal(t, expectedOut, runAppForTest(t, []string{"get", "carve", "1"}))
 }
 
+func TestGetCarveDownload(t *testing.T) {
+	_, ds := runServerWithMockedDS(t)
+
+	// a tar archive of the /etc/hosts file, as carved by osquery
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	hosts := []byte("127.0.0.1 localhost\n")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "/etc/hosts", Mode: 0o644, Size: int64(len(hosts)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(hosts)
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../../escape", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	contents := buf.Bytes()
+	sum := sha256.Sum256(contents)
+
+	const blockSize = 1000
+	blockCount := (int64(len(contents)) + blockSize - 1) / blockSize
+	carve := &fleet.CarveMetadata{
+		ID:         1,
+		Name:       "foobar",
+		BlockCount: blockCount,
+		BlockSize:  blockSize,
+		CarveSize:  int64(len(contents)),
+		MaxBlock:   blockCount - 1,
+		SHA256:     ptr.String(hex.EncodeToString(sum[:])),
+	}
+	ds.CarveFunc = func(ctx context.Context, carveID int64) (*fleet.CarveMetadata, error) {
+		c := *carve
+		return &c, nil
+	}
+	ds.GetBlockFunc = func(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64) ([]byte, error) {
+		end := (blockID + 1) * blockSize
+		if end > int64(len(contents)) {
+			end = int64(len(contents))
+		}
+		return contents[blockID*blockSize : end], nil
+	}
+
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "carve.tar")
+	unpackDir := filepath.Join(dir, "unpacked")
+	runAppForTest(t, []string{"get", "carve", "--outfile", outFile, "--unpack", unpackDir, "--verify", "1"})
+
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	require.Equal(t, contents, data)
+	data, err = os.ReadFile(filepath.Join(unpackDir, "etc", "hosts"))
+	require.NoError(t, err)
+	require.Equal(t, hosts, data)
+	// paths can't escape the unpack directory
+	data, err = os.ReadFile(filepath.Join(unpackDir, "escape"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("x"), data)
+
+	// hash mismatch
+	carve.SHA256 = ptr.String(strings.Repeat("0", 64))
+	runAppCheckErr(t, []string{"get", "carve", "--verify", "1"}, "SHA-256 mismatch: expected "+*carve.SHA256+", got "+hex.EncodeToString(sum[:]))
+
+	// no hash recorded
+	carve.SHA256 = nil
+	runAppCheckErr(t, []string{"get", "carve", "--verify", "1"}, "the carve has no SHA-256 hash to verify, it is not complete or was received by an older Fleet version")
+
+	runAppCheckErr(t, []string{"get", "carve", "--stdout", "--unpack", unpackDir, "1"}, "-stdout and -unpack must not be specified together")
+}
+
 func TestGetCarveWithError(t *testing.T) {
 	_, ds := runServerWithMockedDS(t)
 
@@ -1318,3 +1479,68 @@ func TestGetTeamsYAMLAndApply(t *testing.T) {
 
 	require.Equal(t, "[+] applied 2 teams\n", runAppForTest(t, []string{"apply", "-f", yamlFilePath}))
 }
+
+func TestGetCPETranslations(t *testing.T) {
+	_, ds := runServerWithMockedDS(t)
+
+	ds.GetCPETranslationRuleSpecsFunc = func(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) {
+		return []*fleet.CPETranslationRuleSpec{
+			{
+				Name:        "acme-vpn",
+				Description: "Acme VPN client",
+				Software:    fleet.CPETranslationRuleSoftware{Name: []string{"/^Acme VPN/"}, Source: []string{"apps"}},
+				Filter:      fleet.CPETranslationRuleFilter{Product: []string{"vpn"}, Vendor: []string{"acme"}},
+			},
+			{
+				Name:     "acme-tools",
+				Software: fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"com.acme"}},
+				Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+			},
+		}, nil
+	}
+
+	expected := `+------------+-----------------+--------------------------------+----------------------------+
+|    NAME    |   DESCRIPTION   |            SOFTWARE            |           FILTER           |
++------------+-----------------+--------------------------------+----------------------------+
+| acme-vpn   | Acme VPN client | name: /^Acme VPN/; source:     | product: vpn; vendor: acme |
+|            |                 | apps                           |                            |
++------------+-----------------+--------------------------------+----------------------------+
+| acme-tools |                 | bundle_identifier: com.acme    | skip                       |
++------------+-----------------+--------------------------------+----------------------------+
+`
+	expectedYaml := `---
+apiVersion: v1
+kind: cpe_translation
+spec:
+  description: Acme VPN client
+  filter:
+    product:
+    - vpn
+    vendor:
+    - acme
+  name: acme-vpn
+  software:
+    name:
+    - /^Acme VPN/
+    source:
+    - apps
+---
+apiVersion: v1
+kind: cpe_translation
+spec:
+  description: ""
+  filter:
+    skip: true
+  name: acme-tools
+  software:
+    bundle_identifier:
+    - com.acme
+`
+	expectedJson := `{"kind":"cpe_translation","apiVersion":"v1","spec":{"name":"acme-vpn","description":"Acme VPN client","software":{"name":["/^Acme VPN/"],"source":["apps"]},"filter":{"product":["vpn"],"vendor":["acme"]}}}
+{"kind":"cpe_translation","apiVersion":"v1","spec":{"name":"acme-tools","description":"","software":{"bundle_identifier":["com.acme"]},"filter":{"skip":true}}}
+`
+
+	assert.Equal(t, expected, runAppForTest(t, []string{"get", "cpe_translations"}))
+	assert.Equal(t, expectedYaml, runAppForTest(t, []string{"get", "cpe_translations", "--yaml"}))
+	assert.Equal(t, expectedJson, runAppForTest(t, []string{"get", "cpe_translations", "--json"}))
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/service"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,6 +19,7 @@ func queryCommand() *cli.Command {
 		flHosts, flLabels, flQuery, flQueryName string
 		flQuiet, flExit, flPretty               bool
 		flTimeout                               time.Duration
+		flCampaignID                            uint
 	)
 	return &cli.Command{
 		Name:      "query",
@@ -69,6 +72,12 @@ func queryCommand() *cli.Command {
 				Destination: &flPretty,
 				Usage:       "Enable pretty-printing",
 			},
+			&cli.UintFlag{
+				Name:        "campaign-id",
+				EnvVars:     []string{"CAMPAIGN_ID"},
+				Destination: &flCampaignID,
+				Usage:       "ID of a previous live query to get the persisted results of, instead of running a new query",
+			},
 			&cli.DurationFlag{
 				Name:        "timeout",
 				EnvVars:     []string{"TIMEOUT"},
@@ -85,6 +94,20 @@ func queryCommand() *cli.Command {
 				return err
 			}
 
+			var output outputWriter
+			if flPretty {
+				output = newPrettyWriter()
+			} else {
+				output = newJsonWriter(c.App.Writer)
+			}
+
+			if flCampaignID != 0 {
+				if flHosts != "" || flLabels != "" || flQuery != "" || flQueryName != "" {
+					return errors.New("--campaign-id must not be provided with --hosts, --labels, --query or --query-name")
+				}
+				return printCampaignResults(fleet, flCampaignID, output, flQuiet)
+			}
+
 			if flHosts == "" && flLabels == "" {
 				return errors.New("No hosts or labels targeted. Please provide either --hosts or --labels.")
 			}
@@ -105,13 +128,6 @@ func queryCommand() *cli.Command {
 				return errors.New("Query must be specified with --query or --query-name")
 			}
 
-			var output outputWriter
-			if flPretty {
-				output = newPrettyWriter()
-			} else {
-				output = newJsonWriter(c.App.Writer)
-			}
-
 			hosts := strings.Split(flHosts, ",")
 			labels := strings.Split(flLabels, ",")
 
@@ -206,3 +222,33 @@ func queryCommand() *cli.Command {
 		},
 	}
 }
+
+// campaignResultsPageSize is the number of persisted results retrieved per
+// request by printCampaignResults.
+const campaignResultsPageSize = 500
+
+// printCampaignResults writes all the persisted results of a live query
+// campaign to the output.
+func printCampaignResults(client *service.Client, campaignID uint, output outputWriter, quiet bool) error {
+	var campaign *fleet.DistributedQueryCampaign
+	for page := uint(0); ; page++ {
+		camp, results, meta, err := client.ListCampaignResults(campaignID, page, campaignResultsPageSize)
+		if err != nil {
+			return err
+		}
+		campaign = camp
+		for _, res := range results {
+			if err := output.WriteResult(*res); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing result: %s\n", err)
+			}
+		}
+		if meta == nil || !meta.HasNextResults {
+			break
+		}
+	}
+
+	if !quiet && campaign != nil && campaign.Status != fleet.QueryComplete {
+		fmt.Fprintf(os.Stderr, "Live query %d is still running, run this command again to get the new results.\n", campaignID)
+	}
+	return nil
+}
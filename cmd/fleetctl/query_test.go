@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fleetdm/fleet/v4/server/config"
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/fleetdm/fleet/v4/server/live_query/live_query_mock"
 	"github.com/fleetdm/fleet/v4/server/pubsub"
@@ -117,3 +118,56 @@ func TestLiveQuery(t *testing.T) {
 `
 	assert.Equal(t, expected, runAppForTest(t, []string{"query", "--hosts", "1234", "--query", "select 42, * from time"}))
 }
+
+func TestLiveQueryCampaignResults(t *testing.T) {
+	cfg := config.TestConfig()
+	cfg.App.LiveQueryResultsRetention = 24 * time.Hour
+	_, ds := runServerWithMockedDS(t, &service.TestServerOpts{FleetConfig: &cfg})
+
+	users, err := ds.ListUsersFunc(context.Background(), fleet.UserListOptions{})
+	require.NoError(t, err)
+	var admin *fleet.User
+	for _, user := range users {
+		if user.GlobalRole != nil && *user.GlobalRole == fleet.RoleAdmin {
+			admin = user
+		}
+	}
+
+	status := fleet.QueryComplete
+	ds.DistributedQueryCampaignFunc = func(ctx context.Context, id uint) (*fleet.DistributedQueryCampaign, error) {
+		if id != 321 {
+			return nil, &notFoundError{}
+		}
+		return &fleet.DistributedQueryCampaign{ID: 321, UserID: admin.ID, Status: status}, nil
+	}
+	hostResult := func(id uint, hostname string, rows []map[string]string) *fleet.DistributedQueryResult {
+		return &fleet.DistributedQueryResult{
+			DistributedQueryCampaignID: 321,
+			Host:                       &fleet.HostResponse{Host: &fleet.Host{ID: id, Hostname: hostname}},
+			Rows:                       rows,
+		}
+	}
+	ds.ListDistributedQueryCampaignResultsFunc = func(ctx context.Context, campaignID uint, opt fleet.ListOptions) ([]*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error) {
+		switch opt.Page {
+		case 0:
+			return []*fleet.DistributedQueryResult{hostResult(1, "h1", []map[string]string{{"a": "1"}})},
+				&fleet.PaginationMetadata{HasNextResults: true}, nil
+		default:
+			return []*fleet.DistributedQueryResult{hostResult(2, "h2", []map[string]string{{"a": "2"}, {"a": "3"}})},
+				&fleet.PaginationMetadata{HasPreviousResults: true}, nil
+		}
+	}
+
+	expected := `{"host":"h1","rows":[{"a":"1"}]}
+{"host":"h2","rows":[{"a":"2"},{"a":"3"}]}
+`
+	assert.Equal(t, expected, runAppForTest(t, []string{"query", "--campaign-id", "321"}))
+
+	// a still running campaign prints the results received so far
+	status = fleet.QueryRunning
+	assert.Equal(t, expected, runAppForTest(t, []string{"query", "--campaign-id", "321", "--quiet"}))
+
+	runAppCheckErr(t, []string{"query", "--campaign-id", "321", "--hosts", "h1"}, "--campaign-id must not be provided with --hosts, --labels, --query or --query-name")
+	_, err = runAppNoChecks([]string{"query", "--campaign-id", "1"})
+	require.Error(t, err)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/msrc"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/nvd"
 	"github.com/fleetdm/fleet/v4/server/vulnerabilities/oval"
+	kitlog "github.com/go-kit/kit/log"
 	"github.com/urfave/cli/v2"
 )
 
@@ -79,7 +80,7 @@ Downloads (if needed) the data streams that can be used by the Fleet server to p
 			log(c, " Done\n")
 
 			log(c, "[-] Downloading Oval definitions...")
-			err = oval.Sync(dir, nil)
+			_, err = oval.Sync(dir, nil, kitlog.NewLogfmtLogger(c.App.ErrWriter))
 			if err != nil {
 				return err
 			}
@@ -15,6 +15,7 @@ import (
 	"github.com/fleetdm/fleet/v4/server/contexts/logging"
 	"github.com/fleetdm/fleet/v4/server/contexts/viewer"
 	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/hostlifecycle"
 	"github.com/fleetdm/fleet/v4/server/ptr"
 	"github.com/go-kit/kit/log/level"
 )
@@ -36,6 +37,16 @@ func (svc *Service) NewTeam(ctx context.Context, p fleet.TeamPayload) (*fleet.Te
 		},
 	}
 
+	if p.ParentTeamID != nil && *p.ParentTeamID != 0 {
+		if err := svc.validateParentTeam(ctx, 0, *p.ParentTeamID); err != nil {
+			return nil, err
+		}
+		team.ParentTeamID = p.ParentTeamID
+		// a child team inherits the agent options of its parent until it sets
+		// its own.
+		team.Config.AgentOptions = nil
+	}
+
 	if p.Name == nil {
 		return nil, fleet.NewInvalidArgumentError("name", "missing required argument")
 	}
@@ -99,11 +110,35 @@ func (svc *Service) ModifyTeam(ctx context.Context, teamID uint, payload fleet.T
 	if payload.Description != nil {
 		team.Description = *payload.Description
 	}
+	if payload.ParentTeamID != nil {
+		if *payload.ParentTeamID == 0 {
+			team.ParentTeamID = nil
+		} else {
+			if err := svc.validateParentTeam(ctx, team.ID, *payload.ParentTeamID); err != nil {
+				return nil, err
+			}
+			team.ParentTeamID = payload.ParentTeamID
+		}
+	}
 
 	if payload.WebhookSettings != nil {
 		team.Config.WebhookSettings = *payload.WebhookSettings
 	}
 
+	if payload.HostLifecycle != nil {
+		if err := svc.validateHostLifecycle(ctx, team.Name, payload.HostLifecycle, nil); err != nil {
+			return nil, err
+		}
+		team.Config.HostLifecycle = payload.HostLifecycle
+	}
+
+	if payload.UpdateChannels != nil {
+		if err := payload.UpdateChannels.Validate(); err != nil {
+			return nil, fleet.NewInvalidArgumentError("update_channels", err.Error())
+		}
+		team.Config.UpdateChannels = updateChannelsFromSpec(payload.UpdateChannels)
+	}
+
 	var macOSMinVersionUpdated, macOSDiskEncryptionUpdated bool
 	if payload.MDM != nil {
 		if payload.MDM.MacOSUpdates != nil {
@@ -139,8 +174,21 @@ func (svc *Service) ModifyTeam(ctx context.Context, teamID uint, payload fleet.T
 			return nil, fleet.NewInvalidArgumentError("integrations", err.Error())
 		}
 
+		// the team only stores the masked URL of the Slack and Microsoft Teams
+		// webhooks, which identifies the global integration, as the full URL is
+		// a secret.
+		for _, intg := range payload.Integrations.Slack {
+			intg.URL = intg.UniqueKey()
+		}
+		for _, intg := range payload.Integrations.MicrosoftTeams {
+			intg.URL = intg.UniqueKey()
+		}
+
 		team.Config.Integrations.Jira = payload.Integrations.Jira
 		team.Config.Integrations.Zendesk = payload.Integrations.Zendesk
+		team.Config.Integrations.Slack = payload.Integrations.Slack
+		team.Config.Integrations.MicrosoftTeams = payload.Integrations.MicrosoftTeams
+		team.Config.Integrations.CustomHTTP = payload.Integrations.CustomHTTP
 	}
 
 	if payload.WebhookSettings != nil || payload.Integrations != nil {
@@ -419,6 +467,18 @@ func (svc *Service) TeamEnrollSecrets(ctx context.Context, teamID uint) ([]*flee
 	return svc.ds.TeamEnrollSecrets(ctx, teamID)
 }
 
+func (svc *Service) PreviewTeamHostLifecycle(ctx context.Context, teamID uint) (*fleet.HostLifecycleReport, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.Team{ID: teamID}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	team, err := svc.ds.Team(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return hostlifecycle.Plan(ctx, svc.ds, team, svc.clock.Now())
+}
+
 func (svc *Service) ModifyTeamEnrollSecrets(ctx context.Context, teamID uint, secrets []fleet.EnrollSecret) ([]*fleet.EnrollSecret, error) {
 	if err := svc.authz.Authorize(ctx, &fleet.EnrollSecret{TeamID: ptr.Uint(teamID)}, fleet.ActionWrite); err != nil {
 		return nil, err
@@ -443,6 +503,68 @@ func (svc *Service) ModifyTeamEnrollSecrets(ctx context.Context, teamID uint, se
 	return newSecrets, nil
 }
 
+// validateParentTeam checks that the team identified by parentID can be the
+// parent of the team identified by teamID (0 for a new team): it must exist,
+// be writable by the user and not be the team itself or one of its
+// descendants.
+func (svc *Service) validateParentTeam(ctx context.Context, teamID, parentID uint) error {
+	if parentID == teamID {
+		return fleet.NewInvalidArgumentError("parent_team_id", "a team cannot be its own parent")
+	}
+	if err := svc.authz.Authorize(ctx, &fleet.Team{ID: parentID}, fleet.ActionWrite); err != nil {
+		return err
+	}
+
+	hierarchy, err := svc.ds.TeamHierarchy(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := hierarchy[parentID]; !ok {
+		return fleet.NewInvalidArgumentError("parent_team_id", fmt.Sprintf("team %d does not exist", parentID))
+	}
+	if teamID != 0 && hierarchy.IsDescendant(parentID, teamID) {
+		return fleet.NewInvalidArgumentError("parent_team_id", "a team cannot be the child of one of its descendants")
+	}
+	return nil
+}
+
+// updateChannelsFromSpec returns the update channels to store for the
+// channels of a team spec. No channel set means that the team inherits the
+// channels of its parent team.
+func updateChannelsFromSpec(channels *fleet.UpdateChannels) *fleet.UpdateChannels {
+	if channels == nil || channels.IsEmpty() {
+		return nil
+	}
+	return channels
+}
+
+// validateHostLifecycle checks the host lifecycle settings of the team with
+// the provided name. The teams that the hosts are transferred to must exist,
+// or be in pendingTeams if they are created in the same request.
+func (svc *Service) validateHostLifecycle(ctx context.Context, teamName string, settings *fleet.HostLifecycleSettings, pendingTeams map[string]bool) error {
+	if err := settings.Validate(); err != nil {
+		return fleet.NewInvalidArgumentError("host_lifecycle", err.Error())
+	}
+	for _, r := range settings.Rules {
+		if r.TransferToTeam == "" {
+			continue
+		}
+		if r.TransferToTeam == teamName {
+			return fleet.NewInvalidArgumentError("host_lifecycle", "hosts cannot be transferred to their own team")
+		}
+		if pendingTeams[r.TransferToTeam] {
+			continue
+		}
+		if _, err := svc.ds.TeamByName(ctx, r.TransferToTeam); err != nil {
+			if ctxerr.Cause(err) == sql.ErrNoRows {
+				return fleet.NewInvalidArgumentError("host_lifecycle", fmt.Sprintf("team %q does not exist", r.TransferToTeam))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 func (svc *Service) teamByIDOrName(ctx context.Context, id *uint, name *string) (*fleet.Team, error) {
 	if err := svc.authz.Authorize(ctx, &fleet.Team{}, fleet.ActionRead); err != nil {
 		return nil, err
@@ -509,6 +631,15 @@ func (svc *Service) ApplyTeamSpecs(ctx context.Context, specs []*fleet.TeamSpec,
 
 	var details []fleet.TeamActivityDetail
 
+	// the hosts can be transferred to a team created by the same specs
+	specNames := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		specNames[spec.Name] = true
+	}
+
+	// the global schedule is loaded only if a spec has schedule overrides
+	var globalSchedule map[string]*fleet.ScheduledQuery
+
 	for _, spec := range specs {
 		var secrets []*fleet.EnrollSecret
 		for _, secret := range spec.Secrets {
@@ -548,9 +679,54 @@ func (svc *Service) ApplyTeamSpecs(ctx context.Context, specs []*fleet.TeamSpec,
 		if err := spec.MDM.MacOSUpdates.Validate(); err != nil {
 			return ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("macos_updates", err.Error()))
 		}
+		if spec.HostLifecycle != nil {
+			if err := svc.validateHostLifecycle(ctx, spec.Name, spec.HostLifecycle, specNames); err != nil {
+				return ctxerr.Wrap(ctx, err, "validate host lifecycle")
+			}
+		}
+		if spec.UpdateChannels != nil {
+			if err := spec.UpdateChannels.Validate(); err != nil {
+				return ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("update_channels", err.Error()))
+			}
+		}
+
+		var overrides []*fleet.TeamScheduledQueryOverride
+		if spec.ScheduleOverrides != nil {
+			if globalSchedule == nil {
+				globalSchedule, err = svc.globalScheduledQueriesByName(ctx)
+				if err != nil {
+					return err
+				}
+			}
+			overrides, err = teamScheduleOverridesFromSpec(*spec.ScheduleOverrides, globalSchedule)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "validate schedule overrides")
+			}
+		}
+
+		// the parent team must exist, so it must be created by a previous spec
+		// if it is new.
+		var parentTeamID *uint
+		if spec.ParentTeam != nil && *spec.ParentTeam != "" {
+			parent, err := svc.ds.TeamByName(ctx, *spec.ParentTeam)
+			if err != nil {
+				if ctxerr.Cause(err) == sql.ErrNoRows {
+					return ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("parent_team", fmt.Sprintf("team %q does not exist", *spec.ParentTeam)))
+				}
+				return err
+			}
+			var teamID uint
+			if !create {
+				teamID = team.ID
+			}
+			if err := svc.validateParentTeam(ctx, teamID, parent.ID); err != nil {
+				return ctxerr.Wrap(ctx, err, "validate parent team")
+			}
+			parentTeamID = &parent.ID
+		}
 
 		if create {
-			team, err := svc.createTeamFromSpec(ctx, spec, appConfig, secrets, applyOpts.DryRun)
+			team, err := svc.createTeamFromSpec(ctx, spec, appConfig, secrets, overrides, parentTeamID, applyOpts.DryRun)
 			if err != nil {
 				return ctxerr.Wrap(ctx, err, "creating team from spec")
 			}
@@ -561,7 +737,7 @@ func (svc *Service) ApplyTeamSpecs(ctx context.Context, specs []*fleet.TeamSpec,
 			continue
 		}
 
-		if err := svc.editTeamFromSpec(ctx, team, spec, secrets, applyOpts.DryRun); err != nil {
+		if err := svc.editTeamFromSpec(ctx, team, spec, secrets, overrides, parentTeamID, applyOpts.DryRun); err != nil {
 			return ctxerr.Wrap(ctx, err, "editing team from spec")
 		}
 
@@ -594,11 +770,17 @@ func (svc *Service) createTeamFromSpec(
 	spec *fleet.TeamSpec,
 	defaults *fleet.AppConfig,
 	secrets []*fleet.EnrollSecret,
+	scheduleOverrides []*fleet.TeamScheduledQueryOverride,
+	parentTeamID *uint,
 	dryRun bool,
 ) (*fleet.Team, error) {
 	agentOptions := &spec.AgentOptions
 	if len(spec.AgentOptions) == 0 {
 		agentOptions = defaults.AgentOptions
+		if parentTeamID != nil {
+			// inherited from the parent team
+			agentOptions = nil
+		}
 	}
 
 	// if a team spec is not provided, use the global features, otherwise
@@ -622,7 +804,8 @@ func (svc *Service) createTeamFromSpec(
 	}
 
 	tm, err := svc.ds.NewTeam(ctx, &fleet.Team{
-		Name: spec.Name,
+		Name:         spec.Name,
+		ParentTeamID: parentTeamID,
 		Config: fleet.TeamConfig{
 			AgentOptions: agentOptions,
 			Features:     features,
@@ -630,6 +813,8 @@ func (svc *Service) createTeamFromSpec(
 				MacOSUpdates:  spec.MDM.MacOSUpdates,
 				MacOSSettings: macOSSettings,
 			},
+			HostLifecycle:  spec.HostLifecycle,
+			UpdateChannels: updateChannelsFromSpec(spec.UpdateChannels),
 		},
 		Secrets: secrets,
 	})
@@ -637,6 +822,12 @@ func (svc *Service) createTeamFromSpec(
 		return nil, err
 	}
 
+	if len(scheduleOverrides) > 0 {
+		if err := svc.ds.ReplaceTeamScheduledQueryOverrides(ctx, tm.ID, scheduleOverrides); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "set team schedule overrides")
+		}
+	}
+
 	if macOSSettings.EnableDiskEncryption {
 		if err := svc.MDMAppleEnableFileVaultAndEscrow(ctx, &tm.ID); err != nil {
 			return nil, ctxerr.Wrap(ctx, err, "enable team filevault and escrow")
@@ -658,10 +849,17 @@ func (svc *Service) editTeamFromSpec(
 	team *fleet.Team,
 	spec *fleet.TeamSpec,
 	secrets []*fleet.EnrollSecret,
+	scheduleOverrides []*fleet.TeamScheduledQueryOverride,
+	parentTeamID *uint,
 	dryRun bool,
 ) error {
 	team.Name = spec.Name
 
+	// if the parent team is not provided, do not change it
+	if spec.ParentTeam != nil {
+		team.ParentTeamID = parentTeamID
+	}
+
 	// if agent options are not provided, do not change them
 	if len(spec.AgentOptions) > 0 {
 		if bytes.Equal(spec.AgentOptions, jsonNull) {
@@ -681,6 +879,16 @@ func (svc *Service) editTeamFromSpec(
 	team.Config.Features = features
 	team.Config.MDM.MacOSUpdates = spec.MDM.MacOSUpdates
 
+	// if the host lifecycle rules are not provided, do not change them
+	if spec.HostLifecycle != nil {
+		team.Config.HostLifecycle = spec.HostLifecycle
+	}
+
+	// if the update channels are not provided, do not change them
+	if spec.UpdateChannels != nil {
+		team.Config.UpdateChannels = updateChannelsFromSpec(spec.UpdateChannels)
+	}
+
 	oldMacOSDiskEncryption := team.Config.MDM.MacOSSettings.EnableDiskEncryption
 	if err := svc.applyTeamMacOSSettings(ctx, spec, &team.Config.MDM.MacOSSettings); err != nil {
 		return err
@@ -705,6 +913,12 @@ func (svc *Service) editTeamFromSpec(
 			return err
 		}
 	}
+	// only replace the schedule overrides if the key is provided
+	if spec.ScheduleOverrides != nil {
+		if err := svc.ds.ReplaceTeamScheduledQueryOverrides(ctx, team.ID, scheduleOverrides); err != nil {
+			return ctxerr.Wrap(ctx, err, "replace team schedule overrides")
+		}
+	}
 	if oldMacOSDiskEncryption != newMacOSDiskEncryption {
 		var act fleet.ActivityDetails
 		if team.Config.MDM.MacOSSettings.EnableDiskEncryption {
@@ -725,6 +939,49 @@ func (svc *Service) editTeamFromSpec(
 	return nil
 }
 
+// globalScheduledQueriesByName returns the queries of the global schedule
+// indexed by name.
+func (svc *Service) globalScheduledQueriesByName(ctx context.Context) (map[string]*fleet.ScheduledQuery, error) {
+	gp, err := svc.ds.EnsureGlobalPack(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get global schedule pack")
+	}
+	queries, err := svc.ds.ListScheduledQueriesInPack(ctx, gp.ID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list global scheduled queries")
+	}
+	byName := make(map[string]*fleet.ScheduledQuery, len(queries))
+	for _, q := range queries {
+		byName[q.Name] = q
+	}
+	return byName, nil
+}
+
+// teamScheduleOverridesFromSpec validates the schedule overrides of a team
+// spec and resolves the global scheduled queries they refer to.
+func teamScheduleOverridesFromSpec(specOverrides []fleet.TeamScheduledQueryOverride, globalSchedule map[string]*fleet.ScheduledQuery) ([]*fleet.TeamScheduledQueryOverride, error) {
+	overrides := make([]*fleet.TeamScheduledQueryOverride, 0, len(specOverrides))
+	seen := make(map[string]bool, len(specOverrides))
+	for _, o := range specOverrides {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[o.Name] {
+			return nil, fleet.NewInvalidArgumentError("schedule_overrides.name", fmt.Sprintf("duplicate override for %q", o.Name))
+		}
+		seen[o.Name] = true
+
+		sq := globalSchedule[o.Name]
+		if sq == nil {
+			return nil, fleet.NewInvalidArgumentError("schedule_overrides.name", fmt.Sprintf("%q is not a query of the global schedule", o.Name))
+		}
+		o := o
+		o.ScheduledQueryID = sq.ID
+		overrides = append(overrides, &o)
+	}
+	return overrides, nil
+}
+
 func (svc *Service) applyTeamMacOSSettings(ctx context.Context, spec *fleet.TeamSpec, applyUpon *fleet.MacOSSettings) error {
 	setFields, err := applyUpon.FromMap(spec.MDM.MacOSSettings)
 	if err != nil {
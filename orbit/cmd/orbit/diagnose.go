@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/build"
+	"github.com/fleetdm/fleet/v4/orbit/pkg/constant"
+	"github.com/fleetdm/fleet/v4/orbit/pkg/diagnostics"
+	"github.com/fleetdm/fleet/v4/orbit/pkg/status"
+	"github.com/urfave/cli/v2"
+)
+
+var diagnoseCommand = &cli.Command{
+	Name:  "diagnose",
+	Usage: "Report the status of the running Orbit and write a diagnostics bundle for support",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "status-addr",
+			Usage:   "Localhost address (host:port) of the Orbit status server, read from the root dir if empty",
+			EnvVars: []string{"ORBIT_STATUS_ADDR"},
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "Path of the diagnostics bundle (.tar.gz) to write",
+		},
+		&cli.BoolFlag{
+			Name:  "no-bundle",
+			Usage: "Only report the status, without writing a diagnostics bundle",
+		},
+		&cli.StringSliceFlag{
+			Name:  "log-path",
+			Usage: "Additional log file or directory of log files to include in the bundle",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		rootDir := c.String("root-dir")
+		now := time.Now().UTC()
+
+		info := diagnostics.Info{
+			Version:     build.Version,
+			Commit:      build.Commit,
+			OS:          runtime.GOOS,
+			Arch:        runtime.GOARCH,
+			RootDir:     rootDir,
+			Enrolled:    status.IsEnrolled(rootDir),
+			CollectedAt: now,
+		}
+
+		var statusErr string
+		addr := c.String("status-addr")
+		if addr == "" {
+			var err error
+			if addr, err = status.ReadAddr(rootDir); err != nil {
+				return err
+			}
+		}
+		var st *status.Status
+		if addr == "" {
+			statusErr = "the status server of Orbit is disabled, set --status-addr (ORBIT_STATUS_ADDR) on the Orbit service to enable it"
+		} else {
+			ctx, cancel := context.WithTimeout(c.Context, 5*time.Second)
+			defer cancel()
+			s, err := status.Fetch(ctx, addr)
+			if err != nil {
+				statusErr = fmt.Sprintf("fetch status from %s: %v", addr, err)
+			} else {
+				st = s
+			}
+		}
+
+		printDiagnosis(c.App.Writer, info, st, statusErr)
+
+		if c.Bool("no-bundle") {
+			return nil
+		}
+
+		output := c.String("output")
+		if output == "" {
+			output = fmt.Sprintf("orbit-diagnostics-%s.tar.gz", now.Format("20060102-150405"))
+		}
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, constant.DefaultFileMode)
+		if err != nil {
+			return fmt.Errorf("create diagnostics bundle: %w", err)
+		}
+		defer f.Close()
+
+		if err := diagnostics.WriteBundle(f, diagnostics.Options{
+			RootDir:     rootDir,
+			Info:        info,
+			Status:      st,
+			StatusError: statusErr,
+			ConfigFiles: diagnostics.DefaultConfigFiles(rootDir),
+			LogPaths:    append(diagnostics.DefaultLogPaths(rootDir), c.StringSlice("log-path")...),
+		}); err != nil {
+			return fmt.Errorf("write diagnostics bundle: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("close diagnostics bundle: %w", err)
+		}
+		fmt.Fprintf(c.App.Writer, "\nDiagnostics bundle written to %s\n", output)
+		return nil
+	},
+}
+
+func printDiagnosis(w io.Writer, info diagnostics.Info, st *status.Status, statusErr string) {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	formatCheck := func(c status.Check) string {
+		if c.Time == nil {
+			return "never"
+		}
+		s := c.Time.Format(time.RFC3339)
+		if c.Error != "" {
+			s += " (error: " + c.Error + ")"
+		}
+		return s
+	}
+
+	fmt.Fprintf(w, "Orbit version: %s\n", info.Version)
+	fmt.Fprintf(w, "Root directory: %s\n", info.RootDir)
+	fmt.Fprintf(w, "Enrolled: %s\n", yesNo(info.Enrolled))
+
+	if st == nil {
+		fmt.Fprintf(w, "Status: not available, %s\n", statusErr)
+		return
+	}
+	fmt.Fprintf(w, "Running version: %s (started at %s)\n", st.Version, st.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Last update check: %s\n", formatCheck(st.LastUpdateCheck))
+	fmt.Fprintf(w, "Last Fleet config fetch: %s\n", formatCheck(st.LastConfigFetch))
+	fmt.Fprintf(w, "osqueryd restarts (last 24h): %d\n", st.OsquerydRestarts)
+	fmt.Fprintln(w, "Runners:")
+	for _, r := range st.Runners {
+		line := fmt.Sprintf("  %-22s %-8s since %s", r.Name, r.State, r.StartedAt.Format(time.RFC3339))
+		if r.StoppedAt != nil {
+			line = fmt.Sprintf("  %-22s %-8s at %s", r.Name, r.State, r.StoppedAt.Format(time.RFC3339))
+		}
+		if r.Error != "" {
+			line += " (error: " + r.Error + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
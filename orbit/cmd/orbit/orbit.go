@@ -25,6 +25,7 @@ import (
 	"github.com/fleetdm/fleet/v4/orbit/pkg/osservice"
 	"github.com/fleetdm/fleet/v4/orbit/pkg/platform"
 	"github.com/fleetdm/fleet/v4/orbit/pkg/profiles"
+	"github.com/fleetdm/fleet/v4/orbit/pkg/status"
 	"github.com/fleetdm/fleet/v4/orbit/pkg/table"
 	"github.com/fleetdm/fleet/v4/orbit/pkg/table/orbit_info"
 	"github.com/fleetdm/fleet/v4/orbit/pkg/token"
@@ -49,6 +50,7 @@ func main() {
 	app.Commands = []*cli.Command{
 		versionCommand,
 		shellCommand,
+		diagnoseCommand,
 	}
 	app.Flags = []cli.Flag{
 		&cli.StringFlag{
@@ -140,6 +142,11 @@ func main() {
 			Usage:   "Launch Fleet Desktop application (flag currently only used on darwin)",
 			EnvVars: []string{"ORBIT_FLEET_DESKTOP"},
 		},
+		&cli.StringFlag{
+			Name:    "status-addr",
+			Usage:   "Localhost address (host:port) to serve the Orbit status on, disabled if empty",
+			EnvVars: []string{"ORBIT_STATUS_ADDR"},
+		},
 		&cli.BoolFlag{
 			Name:    "disable-kickstart-softwareupdated",
 			Usage:   "Disable periodic execution of 'launchctl kickstart -k softwareupdated' on macOS",
@@ -258,6 +265,23 @@ func main() {
 			log.Fatal().Err(err).Msg("create local metadata store")
 		}
 
+		// statusTracker records the state of the runners and of the checks of
+		// Orbit, for the status server and `orbit diagnose`.
+		statusTracker := status.NewTracker(c.String("root-dir"), build.Version)
+
+		// The channels pinned by Fleet override the channels Orbit was
+		// packaged with.
+		pinnedChannels, err := update.ReadPinnedChannels(c.String("root-dir"))
+		if err != nil {
+			log.Error().Err(err).Msg("read pinned channels, using the configured channels")
+		}
+		targetChannel := func(target string) string {
+			if channel, ok := pinnedChannels[target]; ok {
+				return channel
+			}
+			return c.String(target + "-channel")
+		}
+
 		opt := update.DefaultOptions
 
 		if c.Bool("fleet-desktop") {
@@ -272,12 +296,12 @@ func main() {
 				log.Fatal().Str("GOOS", runtime.GOOS).Msg("unsupported GOOS for desktop target")
 			}
 			// Override default channel with the provided value.
-			opt.Targets.SetTargetChannel("desktop", c.String("desktop-channel"))
+			opt.Targets.SetTargetChannel("desktop", targetChannel("desktop"))
 		}
 
 		// Override default channels with the provided values.
-		opt.Targets.SetTargetChannel("orbit", c.String("orbit-channel"))
-		opt.Targets.SetTargetChannel("osqueryd", c.String("osqueryd-channel"))
+		opt.Targets.SetTargetChannel("orbit", targetChannel("orbit"))
+		opt.Targets.SetTargetChannel("osqueryd", targetChannel("osqueryd"))
 
 		opt.RootDirectory = c.String("root-dir")
 		opt.ServerURL = c.String("update-url")
@@ -296,7 +320,7 @@ func main() {
 
 		// Initializing service runner and system service manager
 		systemChecker := newSystemChecker()
-		g.Add(systemChecker.Execute, systemChecker.Interrupt)
+		g.Add(statusTracker.Actor("service_checker", systemChecker.Execute, systemChecker.Interrupt))
 		go osservice.SetupServiceManagement(constant.SystemServiceName, systemChecker.svcInterruptCh, appDoneCh)
 
 		// periodically run launchctl kickstart -k softwareupdated on macOS
@@ -305,7 +329,7 @@ func main() {
 			updatedRunner := update.NewSoftwareUpdatedRunner(update.SoftwareUpdatedOptions{
 				Interval: softwareUpdatedKickstartInterval,
 			})
-			g.Add(updatedRunner.Execute, updatedRunner.Interrupt)
+			g.Add(statusTracker.Actor("softwareupdated", updatedRunner.Execute, updatedRunner.Interrupt))
 		}
 
 		// NOTE: When running in dev-mode, even if `disable-updates` is set,
@@ -320,6 +344,20 @@ func main() {
 			if err := updater.UpdateMetadata(); err != nil {
 				log.Info().Err(err).Msg("update metadata. using saved metadata")
 			}
+			// Fall back to the configured channel of the targets whose pinned
+			// channel does not exist, for Orbit to be able to start.
+			for target, channel := range pinnedChannels {
+				info, ok := opt.Targets[target]
+				if !ok {
+					continue
+				}
+				if err := updater.LookupChannel(target, channel); err != nil {
+					log.Error().Err(err).Str("target", target).Msg("pinned channel not found, using the configured channel")
+					info.Channel = c.String(target + "-channel")
+					updater.SetTargetInfo(target, info)
+					delete(pinnedChannels, target)
+				}
+			}
 
 			targets := []string{"orbit", "osqueryd"}
 			if c.Bool("fleet-desktop") {
@@ -331,6 +369,7 @@ func main() {
 			updateRunner, err = update.NewRunner(updater, update.RunnerOptions{
 				CheckInterval: c.Duration("update-interval"),
 				Targets:       targets,
+				OnUpdateCheck: statusTracker.SetUpdateCheck,
 			})
 			if err != nil {
 				return err
@@ -348,7 +387,7 @@ func main() {
 				return nil
 			}
 
-			g.Add(updateRunner.Execute, updateRunner.Interrupt)
+			g.Add(statusTracker.Actor("updater", updateRunner.Execute, updateRunner.Interrupt))
 
 			osquerydLocalTarget, err := updater.Get("osqueryd")
 			if err != nil {
@@ -443,7 +482,8 @@ func main() {
 				return fmt.Errorf("create TLS proxy: %w", err)
 			}
 
-			g.Add(
+			g.Add(statusTracker.Actor(
+				"tls_proxy",
 				func() error {
 					log.Info().
 						Str("addr", fmt.Sprintf("localhost:%d", proxy.Port)).
@@ -457,7 +497,7 @@ func main() {
 						log.Error().Err(err).Msg("close proxy")
 					}
 				},
-			)
+			))
 
 			// Directory to store proxy related assets
 			proxyDirectory := filepath.Join(c.String("root-dir"), "proxy")
@@ -551,7 +591,10 @@ func main() {
 		// create the notifications middleware that wraps the orbit client
 		// (must be shared by all runners that use a ConfigFetcher).
 		const renewEnrollmentProfileCommandFrequency = time.Hour
-		configFetcher := update.ApplyRenewEnrollmentProfileConfigFetcherMiddleware(orbitClient, renewEnrollmentProfileCommandFrequency)
+		configFetcher := update.ApplyRenewEnrollmentProfileConfigFetcherMiddleware(
+			status.ApplyConfigFetcherMiddleware(orbitClient, statusTracker),
+			renewEnrollmentProfileCommandFrequency,
+		)
 
 		if runtime.GOOS == "darwin" {
 			// add middleware to handle nudge installation and updates
@@ -572,7 +615,7 @@ func main() {
 			// in flagRunner.Execute.
 			log.Info().Err(err).Msg("initial flags update failed")
 		}
-		g.Add(flagRunner.Execute, flagRunner.Interrupt)
+		g.Add(statusTracker.Actor("flags_updater", flagRunner.Execute, flagRunner.Interrupt))
 
 		// only setup extensions autoupdate if we have enabled updates
 		// for extensions autoupdate, we can only proceed after orbit is enrolled in fleet
@@ -613,7 +656,19 @@ func main() {
 			default:
 				log.Error().Err(err).Msg("error with extensions.load file at " + extensionAutoLoadFile)
 			}
-			g.Add(extRunner.Execute, extRunner.Interrupt)
+			g.Add(statusTracker.Actor("extensions_updater", extRunner.Execute, extRunner.Interrupt))
+
+			const orbitPinnedChannelsUpdateInterval = 60 * time.Second
+			channelRunner := update.NewChannelRunner(configFetcher, update.ChannelUpdateOptions{
+				CheckInterval: orbitPinnedChannelsUpdateInterval,
+				RootDir:       c.String("root-dir"),
+				DefaultChannels: map[string]string{
+					"orbit":    c.String("orbit-channel"),
+					"osqueryd": c.String("osqueryd-channel"),
+					"desktop":  c.String("desktop-channel"),
+				},
+			}, updateRunner)
+			g.Add(statusTracker.Actor("channels_updater", channelRunner.Execute, channelRunner.Interrupt))
 		}
 
 		trw := token.NewReadWriter(filepath.Join(c.String("root-dir"), "identifier"))
@@ -739,7 +794,16 @@ func main() {
 		if err != nil {
 			return fmt.Errorf("create osquery runner: %w", err)
 		}
-		g.Add(r.Execute, r.Interrupt)
+		g.Add(statusTracker.Actor(
+			"osqueryd",
+			func() error {
+				if err := statusTracker.RecordOsquerydStart(time.Now()); err != nil {
+					log.Error().Err(err).Msg("record osqueryd start")
+				}
+				return r.Execute()
+			},
+			r.Interrupt,
+		))
 
 		// rootDir string, addr string, rootCA string, insecureSkipVerify bool, enrollSecret, uuid string
 		checkerClient, err := service.NewOrbitClient(
@@ -755,23 +819,42 @@ func main() {
 			return fmt.Errorf("new client for capabilities checker: %w", err)
 		}
 		capabilitiesChecker := newCapabilitiesChecker(checkerClient)
-		g.Add(capabilitiesChecker.actor())
+		capabilitiesExecute, capabilitiesInterrupt := capabilitiesChecker.actor()
+		g.Add(statusTracker.Actor("capabilities_checker", capabilitiesExecute, capabilitiesInterrupt))
 
-		registerExtensionRunner(
-			&g,
+		ext := table.NewRunner(
 			r.ExtensionSocketPath(),
 			table.WithExtension(orbit_info.New(
 				orbitClient,
-				c.String("orbit-channel"),
-				c.String("osqueryd-channel"),
-				c.String("desktop-channel"),
+				func(target string) string {
+					// the channels set at runtime by Fleet take precedence
+					if channel := updater.TargetChannel(target); channel != "" {
+						return channel
+					}
+					return targetChannel(target)
+				},
 				trw,
 			)),
 		)
+		g.Add(statusTracker.Actor("extension", ext.Execute, ext.Interrupt))
 
 		if c.Bool("fleet-desktop") {
 			desktopRunner := newDesktopRunner(desktopPath, fleetURL, c.String("fleet-certificate"), c.Bool("insecure"), trw)
-			g.Add(desktopRunner.actor())
+			desktopExecute, desktopInterrupt := desktopRunner.actor()
+			g.Add(statusTracker.Actor("desktop", desktopExecute, desktopInterrupt))
+		}
+
+		statusAddr := c.String("status-addr")
+		if statusAddr != "" {
+			statusServer, err := status.NewServer(statusAddr, statusTracker)
+			if err != nil {
+				return fmt.Errorf("create status server: %w", err)
+			}
+			g.Add(statusServer.Execute, statusServer.Interrupt)
+			statusAddr = statusServer.Addr()
+		}
+		if err := status.WriteAddr(c.String("root-dir"), statusAddr); err != nil {
+			log.Error().Err(err).Msg("record status server address")
 		}
 
 		// Install a signal handler
@@ -0,0 +1,317 @@
+// Package diagnostics builds the diagnostics bundle of Orbit, a gzipped
+// tarball of its status, logs and redacted configuration to attach to
+// support tickets.
+package diagnostics
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/status"
+	"github.com/fleetdm/fleet/v4/orbit/pkg/update"
+)
+
+// DefaultMaxLogSize is the default maximum size of each log file in the
+// bundle, only the end of larger files is included.
+const DefaultMaxLogSize = 5 << 20
+
+// Options are the contents of the diagnostics bundle.
+type Options struct {
+	// RootDir is the Orbit root directory. The list of its files is included,
+	// but not their contents.
+	RootDir string
+	// Info is included as info.json.
+	Info Info
+	// Status is the status of the running Orbit, nil if it could not be
+	// fetched.
+	Status *status.Status
+	// StatusError is the reason the status could not be fetched.
+	StatusError string
+	// ConfigFiles are the paths of the configuration files to include,
+	// redacted. Missing files are skipped.
+	ConfigFiles []string
+	// LogPaths are the paths of the log files, or directories of log files,
+	// to include. Missing paths are skipped.
+	LogPaths []string
+	// MaxLogSize is the maximum size of each log file, DefaultMaxLogSize if 0.
+	MaxLogSize int64
+}
+
+// Info is the static information about the host and the Orbit installation.
+type Info struct {
+	Version     string    `json:"version"`
+	Commit      string    `json:"commit"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	RootDir     string    `json:"root_dir"`
+	Enrolled    bool      `json:"enrolled"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// WriteBundle writes the diagnostics bundle as a gzipped tarball.
+func WriteBundle(w io.Writer, opts Options) error {
+	if opts.MaxLogSize <= 0 {
+		opts.MaxLogSize = DefaultMaxLogSize
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	b := &bundle{tw: tw, modTime: opts.Info.CollectedAt}
+
+	if err := b.addJSON("info.json", opts.Info); err != nil {
+		return err
+	}
+	if opts.Status != nil {
+		if err := b.addJSON("status.json", opts.Status); err != nil {
+			return err
+		}
+	} else if err := b.add("status_error.txt", []byte(opts.StatusError+"\n")); err != nil {
+		return err
+	}
+	if opts.RootDir != "" {
+		listing, err := listFiles(opts.RootDir)
+		if err != nil {
+			return err
+		}
+		if err := b.add("root_dir_files.txt", listing); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range opts.ConfigFiles {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read config file: %w", err)
+		}
+		if err := b.add("config/"+filepath.Base(path), Redact(content)); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range opts.LogPaths {
+		if err := b.addLogs(path, opts.MaxLogSize); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close tar: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("close gzip: %w", err)
+	}
+	return nil
+}
+
+type bundle struct {
+	tw      *tar.Writer
+	modTime time.Time
+	names   map[string]bool
+}
+
+func (b *bundle) add(name string, content []byte) error {
+	// different paths with the same base name are stored under distinct names
+	if b.names == nil {
+		b.names = make(map[string]bool)
+	}
+	unique := name
+	for i := 1; b.names[unique]; i++ {
+		unique = fmt.Sprintf("%s.%d", name, i)
+	}
+	b.names[unique] = true
+
+	if err := b.tw.WriteHeader(&tar.Header{
+		Name:    "orbit-diagnostics/" + unique,
+		Mode:    0o600,
+		Size:    int64(len(content)),
+		ModTime: b.modTime,
+	}); err != nil {
+		return fmt.Errorf("write tar header for %s: %w", name, err)
+	}
+	if _, err := b.tw.Write(content); err != nil {
+		return fmt.Errorf("write %s to tar: %w", name, err)
+	}
+	return nil
+}
+
+func (b *bundle) addJSON(name string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", name, err)
+	}
+	return b.add(name, append(content, '\n'))
+}
+
+func (b *bundle) addLogs(path string, maxSize int64) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat log path: %w", err)
+	}
+	if !info.IsDir() {
+		return b.addLogFile(path, maxSize)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("read log directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isLogFile(e.Name()) {
+			continue
+		}
+		if err := b.addLogFile(filepath.Join(path, e.Name()), maxSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLogFile returns false for the osquery result logs, which contain the
+// data collected from the host and not diagnostics.
+func isLogFile(name string) bool {
+	return !strings.Contains(name, "results") && !strings.Contains(name, "snapshots")
+}
+
+func (b *bundle) addLogFile(path string, maxSize int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	if info.Size() > maxSize {
+		if _, err := f.Seek(info.Size()-maxSize, io.SeekStart); err != nil {
+			return fmt.Errorf("seek log file: %w", err)
+		}
+	}
+	content, err := io.ReadAll(io.LimitReader(f, maxSize))
+	if err != nil {
+		return fmt.Errorf("read log file: %w", err)
+	}
+	return b.add("logs/"+filepath.Base(path), Redact(content))
+}
+
+// listFiles returns the list of the files in the directory, with their size,
+// mode and modification time.
+func listFiles(root string) ([]byte, error) {
+	var lines []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the file may have been removed while walking the directory
+			lines = append(lines, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%d\t%s\t%s", info.Mode(), info.Size(), info.ModTime().UTC().Format(time.RFC3339), filepath.ToSlash(rel)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list root dir files: %w", err)
+	}
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// redacted replaces the redacted values.
+const redacted = "REDACTED"
+
+var (
+	sensitiveKeyRegexp = regexp.MustCompile(`(?i)(secret|password|passphrase|token|node_key|private_key)`)
+	// assignmentRegexp matches "key=value" and "key: value" lines.
+	assignmentRegexp  = regexp.MustCompile(`^(\s*(?:export\s+)?([^=:\s]+)\s*[=:]\s*)(\S.*)$`)
+	plistKeyRegexp    = regexp.MustCompile(`<key>([^<]*)</key>`)
+	plistStringRegexp = regexp.MustCompile(`<string>[^<]*</string>`)
+)
+
+// Redact replaces the values of the sensitive settings (enroll secrets, node
+// keys, tokens, etc.) in environment files, flag files and property lists.
+func Redact(content []byte) []byte {
+	var out bytes.Buffer
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	sc.Buffer(make([]byte, 64*1024), len(content)+1)
+
+	var redactNextString bool
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case plistKeyRegexp.MatchString(line):
+			key := plistKeyRegexp.FindStringSubmatch(line)[1]
+			redactNextString = sensitiveKeyRegexp.MatchString(key)
+			if redactNextString && plistStringRegexp.MatchString(line) {
+				line = plistStringRegexp.ReplaceAllString(line, "<string>"+redacted+"</string>")
+				redactNextString = false
+			}
+		case redactNextString && plistStringRegexp.MatchString(line):
+			line = plistStringRegexp.ReplaceAllString(line, "<string>"+redacted+"</string>")
+			redactNextString = false
+		default:
+			if m := assignmentRegexp.FindStringSubmatch(line); m != nil && sensitiveKeyRegexp.MatchString(m[2]) {
+				line = m[1] + redacted
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// DefaultConfigFiles returns the paths of the configuration files of Orbit
+// on this platform.
+func DefaultConfigFiles(rootDir string) []string {
+	files := []string{
+		filepath.Join(rootDir, "osquery.flags"),
+		filepath.Join(rootDir, "extensions.load"),
+		filepath.Join(rootDir, update.PinnedChannelsFileName),
+	}
+	switch runtime.GOOS {
+	case "linux":
+		files = append(files, "/etc/default/orbit")
+	case "darwin":
+		files = append(files, "/Library/LaunchDaemons/com.fleetdm.orbit.plist")
+	}
+	return files
+}
+
+// DefaultLogPaths returns the paths of the logs of Orbit and osqueryd on
+// this platform.
+func DefaultLogPaths(rootDir string) []string {
+	paths := []string{filepath.Join(rootDir, "osquery_log")}
+	switch runtime.GOOS {
+	case "linux", "darwin":
+		paths = append(paths, "/var/log/orbit")
+	case "windows":
+		paths = append(paths, filepath.Join(os.Getenv("SystemRoot"), `System32\config\systemprofile\AppData\Local\FleetDM\Orbit\Logs`))
+	}
+	return paths
+}
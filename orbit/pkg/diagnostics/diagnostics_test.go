@@ -0,0 +1,95 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	in := `ORBIT_URL=https://fleet.example.com
+ORBIT_ENROLL_SECRET=s3cr3t
+export ORBIT_FLEET_DESKTOP_TOKEN="abc"
+--enroll_secret_env=ENROLL_SECRET
+node_key: xyz
+--tls_hostname=fleet.example.com
+<dict>
+	<key>ORBIT_ENROLL_SECRET</key>
+	<string>s3cr3t</string>
+	<key>ORBIT_FLEET_URL</key>
+	<string>https://fleet.example.com</string>
+	<key>NODE_KEY</key><string>xyz</string>
+</dict>
+`
+	want := `ORBIT_URL=https://fleet.example.com
+ORBIT_ENROLL_SECRET=REDACTED
+export ORBIT_FLEET_DESKTOP_TOKEN=REDACTED
+--enroll_secret_env=REDACTED
+node_key: REDACTED
+--tls_hostname=fleet.example.com
+<dict>
+	<key>ORBIT_ENROLL_SECRET</key>
+	<string>REDACTED</string>
+	<key>ORBIT_FLEET_URL</key>
+	<string>https://fleet.example.com</string>
+	<key>NODE_KEY</key><string>REDACTED</string>
+</dict>
+`
+	require.Equal(t, want, string(Redact([]byte(in))))
+}
+
+func TestWriteBundle(t *testing.T) {
+	rootDir := t.TempDir()
+	logDir := filepath.Join(rootDir, "osquery_log")
+	require.NoError(t, os.MkdirAll(logDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(logDir, "osqueryd.INFO"), []byte(strings.Repeat("a", 100)+"last line\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(logDir, "osqueryd.results.log"), []byte("results\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "osquery.flags"), []byte("--enroll_secret=abc\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "secret-orbit-node-key.txt"), []byte("abc"), 0o600))
+
+	var buf bytes.Buffer
+	err := WriteBundle(&buf, Options{
+		RootDir:     rootDir,
+		Info:        Info{Version: "1.2.3", RootDir: rootDir, CollectedAt: time.Now()},
+		StatusError: "status server disabled",
+		ConfigFiles: []string{filepath.Join(rootDir, "osquery.flags"), filepath.Join(rootDir, "missing")},
+		LogPaths:    []string{logDir, filepath.Join(rootDir, "missing_log")},
+		MaxLogSize:  20,
+	})
+	require.NoError(t, err)
+
+	gr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[strings.TrimPrefix(hdr.Name, "orbit-diagnostics/")] = string(b)
+	}
+
+	require.Len(t, files, 5)
+	require.Contains(t, files["info.json"], `"version": "1.2.3"`)
+	require.Equal(t, "status server disabled\n", files["status_error.txt"])
+	require.Contains(t, files["root_dir_files.txt"], "secret-orbit-node-key.txt")
+	require.Equal(t, "--enroll_secret=REDACTED\n", files["config/osquery.flags"])
+	// only the end of the log is included, and the results log is skipped
+	require.Equal(t, "aaaaaaaaaalast line\n", files["logs/osqueryd.INFO"])
+	for name, content := range files {
+		require.NotContains(t, content, "results\n", name)
+	}
+}
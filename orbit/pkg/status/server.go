@@ -0,0 +1,156 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/constant"
+	"github.com/rs/zerolog/log"
+)
+
+// ValidateAddr checks that the address of the status server is a localhost
+// address, as the status must not be exposed to the network.
+func ValidateAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid status address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("status address %q must be a localhost address", addr)
+}
+
+// Server serves the status of Orbit as JSON on GET /status. It is designed
+// with Execute and Interrupt functions to be compatible with oklog/run.
+type Server struct {
+	tracker *Tracker
+	srv     *http.Server
+	ln      net.Listener
+}
+
+// NewServer returns a server of the status of the tracker that listens on the
+// provided localhost address.
+func NewServer(addr string, tracker *Tracker) (*Server, error) {
+	if err := ValidateAddr(addr); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on status address: %w", err)
+	}
+
+	s := &Server{tracker: tracker, ln: ln}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	s.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.tracker.Status()); err != nil {
+		log.Debug().Err(err).Msg("write status response")
+	}
+}
+
+// Execute serves the status until Interrupt is called.
+func (s *Server) Execute() error {
+	log.Info().Str("addr", s.Addr()).Msg("start status server")
+	if err := s.srv.Serve(s.ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve status: %w", err)
+	}
+	return nil
+}
+
+// Interrupt stops the server.
+func (s *Server) Interrupt(err error) {
+	log.Debug().Err(err).Msg("interrupt status server")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.srv.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("shutdown status server")
+	}
+}
+
+// Fetch returns the status served by the Orbit running on this host at the
+// provided localhost address.
+func Fetch(ctx context.Context, addr string) (*Status, error) {
+	if err := ValidateAddr(addr); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+"/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create status request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get status: unexpected status %s", resp.Status)
+	}
+	var s Status
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decode status: %w", err)
+	}
+	return &s, nil
+}
+
+// addrFileName is the name of the file in the root directory that holds the
+// address of the status server, for `orbit diagnose` to find it.
+const addrFileName = "status-addr"
+
+// WriteAddr records the address of the status server in the root directory.
+// An empty address removes the record, when the server is disabled.
+func WriteAddr(rootDir, addr string) error {
+	path := filepath.Join(rootDir, addrFileName)
+	if addr == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove status address file: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(addr), constant.DefaultWorldReadableFileMode); err != nil {
+		return fmt.Errorf("write status address file: %w", err)
+	}
+	return nil
+}
+
+// ReadAddr returns the address of the status server recorded in the root
+// directory, or an empty string if the server is disabled.
+func ReadAddr(rootDir string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(rootDir, addrFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read status address file: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,237 @@
+// Package status tracks the state of the Orbit runners and of the last
+// interactions of Orbit with the update and Fleet servers, and serves it on a
+// localhost socket for local introspection (e.g. by `orbit diagnose`).
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/constant"
+	"github.com/fleetdm/fleet/v4/orbit/pkg/update"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+// RunnerState is the state of an Orbit runner.
+type RunnerState string
+
+const (
+	// RunnerStateRunning is the state of a runner whose execute function has
+	// not returned.
+	RunnerStateRunning = RunnerState("running")
+	// RunnerStateStopped is the state of a runner whose execute function has
+	// returned.
+	RunnerStateStopped = RunnerState("stopped")
+)
+
+// Runner is the status of an Orbit runner (osqueryd, the updater, Fleet
+// Desktop, etc.).
+type Runner struct {
+	Name      string      `json:"name"`
+	State     RunnerState `json:"state"`
+	StartedAt time.Time   `json:"started_at"`
+	StoppedAt *time.Time  `json:"stopped_at,omitempty"`
+	// Error is the error the runner returned when it stopped, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Check is the result of the last periodic check of a kind (e.g. the check
+// for updates).
+type Check struct {
+	// Time is the time of the last check, nil if there was none.
+	Time *time.Time `json:"time"`
+	// Error is the error of the last check, empty if it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// Status is the status of Orbit.
+type Status struct {
+	Version   string    `json:"version"`
+	StartedAt time.Time `json:"started_at"`
+	// Enrolled is true if Orbit is enrolled to Fleet.
+	Enrolled        bool     `json:"enrolled"`
+	Runners         []Runner `json:"runners"`
+	LastUpdateCheck Check    `json:"last_update_check"`
+	LastConfigFetch Check    `json:"last_config_fetch"`
+	// OsquerydRestarts is the number of times osqueryd was restarted in the
+	// last 24 hours, by Orbit or by the system service manager.
+	OsquerydRestarts int `json:"osqueryd_restarts"`
+}
+
+// Tracker records the status of Orbit. It is safe for concurrent use.
+type Tracker struct {
+	rootDir string
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewTracker returns a tracker of the status of the Orbit process using the
+// provided root directory.
+func NewTracker(rootDir, version string) *Tracker {
+	return &Tracker{
+		rootDir: rootDir,
+		status: Status{
+			Version:   version,
+			StartedAt: time.Now().UTC(),
+			Runners:   []Runner{},
+		},
+	}
+}
+
+// Status returns a snapshot of the status. Enrollment is checked on each
+// call, as Orbit may enroll at any time.
+func (t *Tracker) Status() Status {
+	enrolled := IsEnrolled(t.rootDir)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.status
+	s.Enrolled = enrolled
+	s.Runners = make([]Runner, len(t.status.Runners))
+	copy(s.Runners, t.status.Runners)
+	sort.Slice(s.Runners, func(i, j int) bool { return s.Runners[i].Name < s.Runners[j].Name })
+	return s
+}
+
+// Actor wraps the execute and interrupt functions of a runner of an
+// oklog/run group to track its state under the provided name.
+func (t *Tracker) Actor(name string, execute func() error, interrupt func(error)) (func() error, func(error)) {
+	return func() error {
+		t.setRunner(name, RunnerStateRunning, nil)
+		err := execute()
+		t.setRunner(name, RunnerStateStopped, err)
+		return err
+	}, interrupt
+}
+
+func (t *Tracker) setRunner(name string, state RunnerState, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	r := Runner{Name: name, State: state, StartedAt: now}
+	i := -1
+	for j := range t.status.Runners {
+		if t.status.Runners[j].Name == name {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		t.status.Runners = append(t.status.Runners, r)
+		i = len(t.status.Runners) - 1
+	}
+
+	switch state {
+	case RunnerStateRunning:
+		t.status.Runners[i] = r
+	case RunnerStateStopped:
+		t.status.Runners[i].State = state
+		t.status.Runners[i].StoppedAt = &now
+		if err != nil {
+			t.status.Runners[i].Error = err.Error()
+		}
+	}
+}
+
+// SetUpdateCheck records the result of a check for updates.
+func (t *Tracker) SetUpdateCheck(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastUpdateCheck = newCheck(err)
+}
+
+// SetConfigFetch records the result of a fetch of the Orbit configuration
+// from Fleet.
+func (t *Tracker) SetConfigFetch(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastConfigFetch = newCheck(err)
+}
+
+func newCheck(err error) Check {
+	now := time.Now().UTC()
+	c := Check{Time: &now}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	return c
+}
+
+// ApplyConfigFetcherMiddleware wraps the fetcher to record the result of each
+// fetch of the Orbit configuration in the tracker.
+func ApplyConfigFetcherMiddleware(fetcher update.OrbitConfigFetcher, tracker *Tracker) update.OrbitConfigFetcher {
+	return &configFetcher{Fetcher: fetcher, tracker: tracker}
+}
+
+type configFetcher struct {
+	Fetcher update.OrbitConfigFetcher
+	tracker *Tracker
+}
+
+func (c *configFetcher) GetConfig() (*fleet.OrbitConfig, error) {
+	cfg, err := c.Fetcher.GetConfig()
+	c.tracker.SetConfigFetch(err)
+	return cfg, err
+}
+
+// IsEnrolled returns true if Orbit is enrolled to Fleet, that is if it has
+// an Orbit node key in the root directory.
+func IsEnrolled(rootDir string) bool {
+	info, err := os.Stat(filepath.Join(rootDir, constant.OrbitNodeKeyFileName))
+	return err == nil && info.Size() > 0
+}
+
+// osquerydStartsFileName is the name of the file in the root directory that
+// records the start times of osqueryd.
+const osquerydStartsFileName = "osqueryd-starts.json"
+
+// osquerydRestartsWindow is the period over which the osqueryd restarts are
+// counted.
+const osquerydRestartsWindow = 24 * time.Hour
+
+// RecordOsquerydStart records a start of osqueryd at the provided time. The
+// start times are persisted in the root directory, as Orbit restarts osqueryd
+// by exiting and being restarted by the system service manager.
+func (t *Tracker) RecordOsquerydStart(now time.Time) error {
+	path := filepath.Join(t.rootDir, osquerydStartsFileName)
+
+	var starts []time.Time
+	switch b, err := os.ReadFile(path); {
+	case err == nil:
+		if err := json.Unmarshal(b, &starts); err != nil {
+			// a corrupted file is overwritten
+			starts = nil
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return fmt.Errorf("read osqueryd starts: %w", err)
+	}
+
+	kept := []time.Time{now.UTC()}
+	for _, s := range starts {
+		if s.After(now.Add(-osquerydRestartsWindow)) && !s.After(now) {
+			kept = append(kept, s)
+		}
+	}
+	b, err := json.Marshal(kept)
+	if err != nil {
+		return fmt.Errorf("marshal osqueryd starts: %w", err)
+	}
+	if err := os.WriteFile(path, b, constant.DefaultFileMode); err != nil {
+		return fmt.Errorf("write osqueryd starts: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.OsquerydRestarts = len(kept) - 1
+	return nil
+}
@@ -0,0 +1,124 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/constant"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/stretchr/testify/require"
+)
+
+type fetcherFunc func() (*fleet.OrbitConfig, error)
+
+func (f fetcherFunc) GetConfig() (*fleet.OrbitConfig, error) { return f() }
+
+func TestTracker(t *testing.T) {
+	rootDir := t.TempDir()
+	tracker := NewTracker(rootDir, "1.2.3")
+
+	s := tracker.Status()
+	require.Equal(t, "1.2.3", s.Version)
+	require.False(t, s.Enrolled)
+	require.Empty(t, s.Runners)
+	require.Nil(t, s.LastUpdateCheck.Time)
+	require.Nil(t, s.LastConfigFetch.Time)
+
+	// runners
+	block := make(chan struct{})
+	execute, _ := tracker.Actor("osqueryd", func() error {
+		<-block
+		return errors.New("osqueryd exited")
+	}, func(error) {})
+	done := make(chan error)
+	go func() { done <- execute() }()
+	require.Eventually(t, func() bool {
+		s := tracker.Status()
+		return len(s.Runners) == 1 && s.Runners[0].State == RunnerStateRunning
+	}, time.Second, 10*time.Millisecond)
+	close(block)
+	require.Error(t, <-done)
+	s = tracker.Status()
+	require.Len(t, s.Runners, 1)
+	require.Equal(t, "osqueryd", s.Runners[0].Name)
+	require.Equal(t, RunnerStateStopped, s.Runners[0].State)
+	require.NotNil(t, s.Runners[0].StoppedAt)
+	require.Equal(t, "osqueryd exited", s.Runners[0].Error)
+
+	// checks
+	tracker.SetUpdateCheck(errors.New("offline"))
+	fetcher := ApplyConfigFetcherMiddleware(fetcherFunc(func() (*fleet.OrbitConfig, error) {
+		return &fleet.OrbitConfig{}, nil
+	}), tracker)
+	_, err := fetcher.GetConfig()
+	require.NoError(t, err)
+	s = tracker.Status()
+	require.NotNil(t, s.LastUpdateCheck.Time)
+	require.Equal(t, "offline", s.LastUpdateCheck.Error)
+	require.NotNil(t, s.LastConfigFetch.Time)
+	require.Empty(t, s.LastConfigFetch.Error)
+
+	// enrollment
+	err = os.WriteFile(filepath.Join(rootDir, constant.OrbitNodeKeyFileName), []byte("key"), constant.DefaultFileMode)
+	require.NoError(t, err)
+	require.True(t, tracker.Status().Enrolled)
+}
+
+func TestRecordOsquerydStart(t *testing.T) {
+	tracker := NewTracker(t.TempDir(), "1.2.3")
+	now := time.Now()
+
+	require.NoError(t, tracker.RecordOsquerydStart(now.Add(-25*time.Hour)))
+	require.Equal(t, 0, tracker.Status().OsquerydRestarts)
+
+	// the start older than 24 hours is not counted
+	require.NoError(t, tracker.RecordOsquerydStart(now.Add(-time.Hour)))
+	require.Equal(t, 0, tracker.Status().OsquerydRestarts)
+	require.NoError(t, tracker.RecordOsquerydStart(now))
+	require.Equal(t, 1, tracker.Status().OsquerydRestarts)
+
+	// the starts are persisted across Orbit processes
+	tracker = NewTracker(tracker.rootDir, "1.2.3")
+	require.NoError(t, tracker.RecordOsquerydStart(now.Add(time.Minute)))
+	require.Equal(t, 2, tracker.Status().OsquerydRestarts)
+}
+
+func TestServer(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:8080", "example.com:8080", ":8080", "localhost"} {
+		require.Error(t, ValidateAddr(addr), addr)
+	}
+	for _, addr := range []string{"localhost:8080", "127.0.0.1:0", "[::1]:8080"} {
+		require.NoError(t, ValidateAddr(addr), addr)
+	}
+
+	rootDir := t.TempDir()
+	tracker := NewTracker(rootDir, "1.2.3")
+	tracker.SetUpdateCheck(nil)
+
+	srv, err := NewServer("127.0.0.1:0", tracker)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() { done <- srv.Execute() }()
+
+	require.NoError(t, WriteAddr(rootDir, srv.Addr()))
+	addr, err := ReadAddr(rootDir)
+	require.NoError(t, err)
+	require.Equal(t, srv.Addr(), addr)
+
+	s, err := Fetch(context.Background(), addr)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3", s.Version)
+	require.NotNil(t, s.LastUpdateCheck.Time)
+
+	srv.Interrupt(nil)
+	require.NoError(t, <-done)
+
+	require.NoError(t, WriteAddr(rootDir, ""))
+	addr, err = ReadAddr(rootDir)
+	require.NoError(t, err)
+	require.Empty(t, addr)
+}
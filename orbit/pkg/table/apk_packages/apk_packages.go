@@ -0,0 +1,87 @@
+// Package apk_packages implements the apk_packages table, which lists the packages installed with
+// the Alpine Linux package manager (apk).
+package apk_packages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/osquery/osquery-go/plugin/table"
+)
+
+// InstalledDBPath is the path of the database of the installed apk packages.
+const InstalledDBPath = "/lib/apk/db/installed"
+
+// Columns is the schema of the table.
+func Columns() []table.ColumnDefinition {
+	return []table.ColumnDefinition{
+		table.TextColumn("name"),
+		table.TextColumn("version"),
+		table.TextColumn("arch"),
+		table.TextColumn("origin"),
+	}
+}
+
+// Generate is called to return the results for the table at query time.
+//
+// Constraints for generating can be retrieved from the queryContext.
+func Generate(ctx context.Context, queryContext table.QueryContext) ([]map[string]string, error) {
+	f, err := os.Open(InstalledDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("open apk database: %w", err)
+	}
+	defer f.Close()
+
+	return parseInstalledDB(f)
+}
+
+// parseInstalledDB parses the apk database of the installed packages, made of one block of
+// "<field>:<value>" lines per package, separated by empty lines. The version of a package
+// includes its package release, e.g. "1.2.3-r0".
+func parseInstalledDB(r io.Reader) ([]map[string]string, error) {
+	var rows []map[string]string
+	var row map[string]string
+
+	flush := func() {
+		if row["name"] != "" {
+			rows = append(rows, row)
+		}
+		row = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		if row == nil {
+			row = map[string]string{"name": "", "version": "", "arch": "", "origin": ""}
+		}
+		value := strings.TrimSpace(line[2:])
+		switch line[0] {
+		case 'P':
+			row["name"] = value
+		case 'V':
+			row["version"] = value
+		case 'A':
+			row["arch"] = value
+		case 'o':
+			row["origin"] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read apk database: %w", err)
+	}
+	flush()
+
+	return rows, nil
+}
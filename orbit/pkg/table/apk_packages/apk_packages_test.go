@@ -0,0 +1,47 @@
+package apk_packages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInstalledDB(t *testing.T) {
+	const db = `C:Q1Ef0OQoJ1qXm1JQzKo1MEsXGqR/s=
+P:musl
+V:1.2.3-r4
+A:x86_64
+S:383152
+I:622592
+T:the musl c library (libc) implementation
+U:https://musl.libc.org/
+L:MIT
+o:musl
+m:Timo Teräs <timo.teras@iki.fi>
+t:1661859226
+F:lib
+R:ld-musl-x86_64.so.1
+R:libc.musl-x86_64.so.1
+
+C:Q1z6ytOnQmm2ItmHtkc+bSvzG+R/o=
+P:libcrypto3
+V:3.0.8_p1-r0
+A:x86_64
+o:openssl
+
+P:broken
+`
+
+	rows, err := parseInstalledDB(strings.NewReader(db))
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"name": "musl", "version": "1.2.3-r4", "arch": "x86_64", "origin": "musl"},
+		{"name": "libcrypto3", "version": "3.0.8_p1-r0", "arch": "x86_64", "origin": "openssl"},
+		{"name": "broken", "version": "", "arch": "", "origin": ""},
+	}, rows)
+
+	rows, err = parseInstalledDB(strings.NewReader(""))
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
@@ -0,0 +1,21 @@
+//go:build linux
+
+package table
+
+import (
+	"os"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/table/apk_packages"
+	"github.com/osquery/osquery-go"
+	"github.com/osquery/osquery-go/plugin/table"
+)
+
+func platformTables() []osquery.OsqueryPlugin {
+	var plugins []osquery.OsqueryPlugin
+	// The apk_packages table is only registered on hosts using apk (e.g. Alpine Linux), Fleet
+	// uses its presence to pick the software query that includes the apk packages.
+	if _, err := os.Stat(apk_packages.InstalledDBPath); err == nil {
+		plugins = append(plugins, table.NewPlugin("apk_packages", apk_packages.Columns(), apk_packages.Generate))
+	}
+	return plugins
+}
@@ -13,22 +13,20 @@ import (
 
 // Extension implements an extension table that provides info about Orbit.
 type Extension struct {
-	orbitClient     *service.OrbitClient
-	orbitChannel    string
-	osquerydChannel string
-	desktopChannel  string
-	trw             *token.ReadWriter
+	orbitClient *service.OrbitClient
+	// targetChannel returns the current update channel of a target, which
+	// may change at runtime when Fleet pins the target to a channel.
+	targetChannel func(target string) string
+	trw           *token.ReadWriter
 }
 
 var _ orbit_table.Extension = (*Extension)(nil)
 
-func New(orbitClient *service.OrbitClient, orbitChannel, osquerydChannel, desktopChannel string, trw *token.ReadWriter) *Extension {
+func New(orbitClient *service.OrbitClient, targetChannel func(target string) string, trw *token.ReadWriter) *Extension {
 	return &Extension{
-		orbitClient:     orbitClient,
-		orbitChannel:    orbitChannel,
-		osquerydChannel: osquerydChannel,
-		desktopChannel:  desktopChannel,
-		trw:             trw,
+		orbitClient:   orbitClient,
+		targetChannel: targetChannel,
+		trw:           trw,
 	}
 }
 
@@ -74,8 +72,8 @@ func (o Extension) GenerateFunc(_ context.Context, _ table.QueryContext) ([]map[
 		"device_auth_token":   token,
 		"enrolled":            strconv.FormatBool(o.orbitClient.Enrolled()),
 		"last_recorded_error": lastRecordedError,
-		"orbit_channel":       o.orbitChannel,
-		"osqueryd_channel":    o.osquerydChannel,
-		"desktop_channel":     o.desktopChannel,
+		"orbit_channel":       o.targetChannel("orbit"),
+		"osqueryd_channel":    o.targetChannel("osqueryd"),
+		"desktop_channel":     o.targetChannel("desktop"),
 	}}, nil
 }
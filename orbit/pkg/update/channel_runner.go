@@ -0,0 +1,199 @@
+package update
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/orbit/pkg/constant"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/rs/zerolog/log"
+)
+
+// PinnedChannelsFileName is the name of the file in the root directory that
+// holds the update channels that Fleet pinned the targets to. Orbit reads it
+// on startup to override the channels it was packaged with.
+const PinnedChannelsFileName = "pinned-channels.json"
+
+// ReadPinnedChannels returns the update channels pinned by Fleet, by target
+// name, that are recorded in the root directory.
+func ReadPinnedChannels(rootDir string) (map[string]string, error) {
+	b, err := os.ReadFile(filepath.Join(rootDir, PinnedChannelsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read pinned channels: %w", err)
+	}
+	var channels map[string]string
+	if err := json.Unmarshal(b, &channels); err != nil {
+		return nil, fmt.Errorf("unmarshal pinned channels: %w", err)
+	}
+	for target, channel := range channels {
+		if err := fleet.ValidateUpdateChannel(channel); err != nil {
+			return nil, fmt.Errorf("pinned channel of %s: %w", target, err)
+		}
+	}
+	return channels, nil
+}
+
+func writePinnedChannels(rootDir string, channels map[string]string) error {
+	path := filepath.Join(rootDir, PinnedChannelsFileName)
+	if len(channels) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove pinned channels: %w", err)
+		}
+		return nil
+	}
+	b, err := json.Marshal(channels)
+	if err != nil {
+		return fmt.Errorf("marshal pinned channels: %w", err)
+	}
+	if err := os.WriteFile(path, b, constant.DefaultFileMode); err != nil {
+		return fmt.Errorf("write pinned channels: %w", err)
+	}
+	return nil
+}
+
+// ChannelRunner is a specialized runner to periodically check the update
+// channels that Fleet pins the targets to. It is designed with Execute and
+// Interrupt functions to be compatible with oklog/run.
+//
+// When the pinned channels change, they are recorded in the root directory,
+// so that they are used when Orbit starts, and they are set in the update
+// runner. The channel runner doesn't update the targets itself, the update
+// runner updates them from their new channels on its next check (and exits
+// so that Orbit restarts and runs the new versions), so that the updates are
+// never run concurrently.
+type ChannelRunner struct {
+	configFetcher OrbitConfigFetcher
+	opt           ChannelUpdateOptions
+	cancel        chan struct{}
+	// targets are the targets that can be pinned, the ones kept up-to-date
+	// by the update runner.
+	targets []string
+	// lookupChannel checks that a target exists in a channel.
+	lookupChannel func(target, channel string) error
+	// setChannel sets the channel of a target in the update runner.
+	setChannel func(target, channel string)
+}
+
+// ChannelUpdateOptions is options provided for the pinned channels runner.
+type ChannelUpdateOptions struct {
+	// CheckInterval is the interval to check for pinned channels.
+	CheckInterval time.Duration
+	// RootDir is the root directory for orbit state.
+	RootDir string
+	// DefaultChannels are the channels of the targets that are not pinned,
+	// the ones Orbit was configured with.
+	DefaultChannels map[string]string
+}
+
+// NewChannelRunner creates a new runner with provided options. The runner
+// must be started with Execute.
+func NewChannelRunner(configFetcher OrbitConfigFetcher, opt ChannelUpdateOptions, updateRunner *Runner) *ChannelRunner {
+	updateRunner.mu.Lock()
+	targets := append([]string(nil), updateRunner.opt.Targets...)
+	updateRunner.mu.Unlock()
+
+	return &ChannelRunner{
+		configFetcher: configFetcher,
+		opt:           opt,
+		cancel:        make(chan struct{}),
+		targets:       targets,
+		lookupChannel: updateRunner.updater.LookupChannel,
+		setChannel:    updateRunner.SetTargetChannel,
+	}
+}
+
+// Execute starts the loop checking for pinned channels.
+func (r *ChannelRunner) Execute() error {
+	log.Debug().Msg("starting pinned channels updater")
+
+	ticker := time.NewTicker(r.opt.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.cancel:
+			return nil
+		case <-ticker.C:
+			if err := r.DoChannelUpdate(); err != nil {
+				log.Info().Err(err).Msg("pinned channels update failed")
+			}
+		}
+	}
+}
+
+// Interrupt is the oklog/run interrupt method that stops the runner.
+func (r *ChannelRunner) Interrupt(err error) {
+	close(r.cancel)
+	log.Debug().Err(err).Msg("interrupt for pinned channels updater")
+}
+
+// DoChannelUpdate gets the pinned channels from Fleet and compares them to
+// the ones recorded in the root directory. If they differ, it records the
+// pinned channels and sets the channels of the targets in the update runner,
+// reverting the targets that are no longer pinned to their default channel.
+//
+// The pinned channels of unknown targets, and of targets that do not exist in
+// the pinned channel of the update repository, are ignored, so that a
+// mistake in Fleet cannot leave Orbit unable to start.
+func (r *ChannelRunner) DoChannelUpdate() error {
+	current, err := ReadPinnedChannels(r.opt.RootDir)
+	corrupted := err != nil
+	if corrupted {
+		// a corrupted file is overwritten
+		log.Info().Err(err).Msg("invalid pinned channels file")
+	}
+
+	config, err := r.configFetcher.GetConfig()
+	if err != nil {
+		// keep the current pins, Fleet may be temporarily unreachable
+		return fmt.Errorf("error getting pinned channels from fleet: %w", err)
+	}
+
+	pinned := make(map[string]string)
+	for _, target := range r.targets {
+		channel, ok := config.UpdateChannels[target]
+		if !ok {
+			continue
+		}
+		if err := fleet.ValidateUpdateChannel(channel); err != nil {
+			log.Info().Err(err).Str("target", target).Msg("ignoring pinned channel")
+			continue
+		}
+		if current[target] != channel {
+			// only check the channels that are not pinned yet, the update
+			// runner reports the errors of the pinned ones
+			if err := r.lookupChannel(target, channel); err != nil {
+				log.Info().Err(err).Str("target", target).Msg("ignoring pinned channel")
+				continue
+			}
+		}
+		pinned[target] = channel
+	}
+
+	if !corrupted && (len(pinned) == 0 && len(current) == 0 || reflect.DeepEqual(pinned, current)) {
+		return nil
+	}
+	if err := writePinnedChannels(r.opt.RootDir, pinned); err != nil {
+		return err
+	}
+	log.Info().Interface("channels", pinned).Msg("pinned channels changed")
+
+	for _, target := range r.targets {
+		channel, ok := pinned[target]
+		if !ok {
+			channel, ok = r.opt.DefaultChannels[target]
+		}
+		if ok {
+			r.setChannel(target, channel)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package update
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoChannelUpdate(t *testing.T) {
+	rootDir := t.TempDir()
+	fetcher := &dummyConfigFetcher{cfg: &fleet.OrbitConfig{}}
+	var lookups []string
+	channels := map[string]string{"orbit": "stable", "osqueryd": "stable"}
+	var sets int
+	r := &ChannelRunner{
+		configFetcher: fetcher,
+		opt: ChannelUpdateOptions{
+			CheckInterval:   time.Minute,
+			RootDir:         rootDir,
+			DefaultChannels: map[string]string{"orbit": "stable", "osqueryd": "stable"},
+		},
+		cancel:  make(chan struct{}),
+		targets: []string{"orbit", "osqueryd"},
+		lookupChannel: func(target, channel string) error {
+			lookups = append(lookups, target+"/"+channel)
+			if channel == "missing" {
+				return errors.New("not found")
+			}
+			return nil
+		},
+		setChannel: func(target, channel string) {
+			sets++
+			channels[target] = channel
+		},
+	}
+
+	// nothing is pinned
+	err := r.DoChannelUpdate()
+	require.NoError(t, err)
+	require.Zero(t, sets)
+	_, err = os.Stat(filepath.Join(rootDir, PinnedChannelsFileName))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// the unknown targets and the invalid or missing channels are ignored
+	fetcher.cfg.UpdateChannels = map[string]string{
+		"osqueryd": "5.8.2",
+		"orbit":    "missing",
+		"desktop":  "1.8.0",
+		"nudge":    "stable",
+	}
+	err = r.DoChannelUpdate()
+	require.NoError(t, err)
+	require.Equal(t, []string{"orbit/missing", "osqueryd/5.8.2"}, lookups)
+	pinned, err := ReadPinnedChannels(rootDir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"osqueryd": "5.8.2"}, pinned)
+	require.Equal(t, map[string]string{"orbit": "stable", "osqueryd": "5.8.2"}, channels)
+	require.Equal(t, 2, sets)
+
+	// the same channels are not set again, nor looked up again
+	lookups = nil
+	err = r.DoChannelUpdate()
+	require.NoError(t, err)
+	require.Equal(t, []string{"orbit/missing"}, lookups)
+	require.Equal(t, 2, sets)
+
+	fetcher.cfg.UpdateChannels["orbit"] = "../stable"
+	err = r.DoChannelUpdate()
+	require.NoError(t, err)
+	require.Equal(t, 2, sets)
+
+	fetcher.cfg.UpdateChannels = map[string]string{"osqueryd": "5.8.2", "orbit": "edge"}
+	err = r.DoChannelUpdate()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"orbit": "edge", "osqueryd": "5.8.2"}, channels)
+	require.Equal(t, 4, sets)
+
+	// unpinning removes the file and reverts to the default channels
+	fetcher.cfg.UpdateChannels = nil
+	err = r.DoChannelUpdate()
+	require.NoError(t, err)
+	pinned, err = ReadPinnedChannels(rootDir)
+	require.NoError(t, err)
+	require.Nil(t, pinned)
+	require.Equal(t, map[string]string{"orbit": "stable", "osqueryd": "stable"}, channels)
+
+	// a corrupted file is overwritten
+	err = os.WriteFile(filepath.Join(rootDir, PinnedChannelsFileName), []byte(`{"osqueryd":"../x"}`), 0o600)
+	require.NoError(t, err)
+	_, err = ReadPinnedChannels(rootDir)
+	require.Error(t, err)
+	err = r.DoChannelUpdate()
+	require.NoError(t, err)
+	pinned, err = ReadPinnedChannels(rootDir)
+	require.NoError(t, err)
+	require.Nil(t, pinned)
+}
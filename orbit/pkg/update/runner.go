@@ -20,6 +20,9 @@ type RunnerOptions struct {
 	CheckInterval time.Duration
 	// Targets is the names of the artifacts to watch for updates.
 	Targets []string
+	// OnUpdateCheck, if set, is called with the result of each check for
+	// updates.
+	OnUpdateCheck func(err error)
 }
 
 // Runner is a specialized runner for an Updater. It is designed with Execute and
@@ -140,6 +143,12 @@ func (r *Runner) HasLocalHash(target string) bool {
 	return ok
 }
 
+// SetTargetChannel changes the update channel of the target. The target is
+// updated from the new channel on the next update check.
+func (r *Runner) SetTargetChannel(target, channel string) {
+	r.updater.SetTargetChannel(target, channel)
+}
+
 // Execute begins a loop checking for updates.
 func (r *Runner) Execute() error {
 	log.Debug().Msg("start updater")
@@ -171,6 +180,14 @@ func (r *Runner) Execute() error {
 // NOTE: If it returns (true, non-nil error) then it means some target/s
 // were successfully upgraded and some failed to upgrade.
 func (r *Runner) UpdateAction() (bool, error) {
+	didUpdate, err := r.updateAction()
+	if r.opt.OnUpdateCheck != nil {
+		r.opt.OnUpdateCheck(err)
+	}
+	return didUpdate, err
+}
+
+func (r *Runner) updateAction() (bool, error) {
 	if err := r.updater.UpdateMetadata(); err != nil {
 		// Consider this a non-fatal error since it will be common to be offline
 		// or otherwise unable to retrieve the metadata.
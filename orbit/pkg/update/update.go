@@ -79,6 +79,24 @@ func (u *Updater) SetTargetInfo(name string, info TargetInfo) {
 	u.opt.Targets[name] = info
 }
 
+// SetTargetChannel sets the update channel of the given target, if it is
+// known. The target is looked up in the new channel on the next update.
+func (u *Updater) SetTargetChannel(target, channel string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.opt.Targets[target]; ok {
+		u.opt.Targets.SetTargetChannel(target, channel)
+	}
+}
+
+// TargetChannel returns the update channel of the given target, or an empty
+// string if the target is unknown.
+func (u *Updater) TargetChannel(target string) string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.opt.Targets[target].Channel
+}
+
 // RemoveTargetInfo removes the TargetInfo for the given target.
 func (u *Updater) RemoveTargetInfo(name string) {
 	u.mu.Lock()
@@ -268,6 +286,21 @@ func (u *Updater) Lookup(target string) (*data.TargetFileMeta, error) {
 	return &t, nil
 }
 
+// LookupChannel checks that the target exists in the provided channel of the
+// remote repository, using the platform and file of the configured target.
+func (u *Updater) LookupChannel(target, channel string) error {
+	u.mu.Lock()
+	t, ok := u.opt.Targets[target]
+	u.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown target: %s", target)
+	}
+	if _, err := u.client.Target(path.Join(target, t.Platform, channel, t.TargetFile)); err != nil {
+		return fmt.Errorf("lookup %s in channel %s: %w", target, channel, err)
+	}
+	return nil
+}
+
 // Targets gets all of the known targets
 func (u *Updater) Targets() (data.TargetFiles, error) {
 	targets, err := u.client.Targets()
@@ -63,3 +63,21 @@ func TestMakeRepoPath(t *testing.T) {
 		})
 	}
 }
+
+func TestSetTargetChannel(t *testing.T) {
+	u := NewDisabled(Options{Targets: Targets{
+		"osqueryd": {Platform: "linux", Channel: "stable", TargetFile: "osqueryd"},
+	}})
+
+	u.SetTargetChannel("osqueryd", "5.8.2")
+	require.Equal(t, "5.8.2", u.TargetChannel("osqueryd"))
+	repoPath, err := u.repoPath("osqueryd")
+	require.NoError(t, err)
+	require.Equal(t, "osqueryd/linux/5.8.2/osqueryd", repoPath)
+
+	// unknown targets are not added
+	u.SetTargetChannel("desktop", "edge")
+	require.Empty(t, u.TargetChannel("desktop"))
+	_, err = u.repoPath("desktop")
+	require.Error(t, err)
+}
@@ -17,11 +17,12 @@ var yamlSeparator = regexp.MustCompile(`(?m:^---[\t ]*)`)
 
 // Group holds a set of "specs" that can be applied to a Fleet server.
 type Group struct {
-	Queries  []*fleet.QuerySpec
-	Teams    []json.RawMessage
-	Packs    []*fleet.PackSpec
-	Labels   []*fleet.LabelSpec
-	Policies []*fleet.PolicySpec
+	Queries         []*fleet.QuerySpec
+	Teams           []json.RawMessage
+	Packs           []*fleet.PackSpec
+	Labels          []*fleet.LabelSpec
+	Policies        []*fleet.PolicySpec
+	CPETranslations []*fleet.CPETranslationRuleSpec
 	// This needs to be interface{} to allow for the patch logic. Otherwise we send a request that looks to the
 	// server like the user explicitly set the zero values.
 	AppConfig    interface{}
@@ -80,6 +81,13 @@ func GroupFromBytes(b []byte) (*Group, error) {
 			}
 			specs.Policies = append(specs.Policies, policySpec)
 
+		case fleet.CPETranslationKind:
+			var cpeTranslationSpec *fleet.CPETranslationRuleSpec
+			if err := yaml.Unmarshal(s.Spec, &cpeTranslationSpec); err != nil {
+				return nil, fmt.Errorf("unmarshaling %s spec: %w", kind, err)
+			}
+			specs.CPETranslations = append(specs.CPETranslations, cpeTranslationSpec)
+
 		case fleet.AppConfigKind:
 			if specs.AppConfig != nil {
 				return nil, errors.New("config defined twice in the same file")
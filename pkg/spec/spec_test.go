@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -69,3 +70,42 @@ func TestGroupFromBytesWithWin10CISQueries(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, g.Policies)
 }
+
+func TestGroupFromBytesWithCPETranslations(t *testing.T) {
+	g, err := GroupFromBytes([]byte(`
+apiVersion: v1
+kind: cpe_translation
+spec:
+  name: acme-vpn
+  description: Acme VPN client
+  software:
+    name: ["/^Acme VPN/"]
+    source: ["apps"]
+  filter:
+    product: ["vpn_client"]
+    vendor: ["acme"]
+---
+apiVersion: v1
+kind: cpe_translation
+spec:
+  name: internal-tools
+  software:
+    bundle_identifier: ["com.acme.tools"]
+  filter:
+    skip: true
+`))
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{
+		{
+			Name:        "acme-vpn",
+			Description: "Acme VPN client",
+			Software:    fleet.CPETranslationRuleSoftware{Name: []string{"/^Acme VPN/"}, Source: []string{"apps"}},
+			Filter:      fleet.CPETranslationRuleFilter{Product: []string{"vpn_client"}, Vendor: []string{"acme"}},
+		},
+		{
+			Name:     "internal-tools",
+			Software: fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"com.acme.tools"}},
+			Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+		},
+	}, g.CPETranslations)
+}
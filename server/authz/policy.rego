@@ -34,13 +34,29 @@ observer := "observer"
 default allow = false
 
 # team_role gets the role that the subject has for the team, returning undefined
-# if the user has no explicit role for that team.
-team_role(subject, team_id) = role {
+# if the user has no explicit role for that team. An admin of a team is also
+# admin of the descendants of that team, which takes precedence over an
+# explicit role on the descendant.
+team_role(subject, team_id) = admin {
+	subject_team := subject.teams[_]
+	subject_team.role == admin
+	team_admin_scope(subject_team, team_id)
+} else = role {
 	subject_team := subject.teams[_]
 	subject_team.id == team_id
 	role := subject_team.role
 }
 
+# team_admin_scope is true if the team is the subject's team or one of its
+# descendants.
+team_admin_scope(subject_team, team_id) {
+	subject_team.id == team_id
+}
+
+team_admin_scope(subject_team, team_id) {
+	subject_team.descendant_ids[_] == team_id
+}
+
 ##
 # Global config
 ##
@@ -151,6 +167,13 @@ allow {
   action == read
 }
 
+# Only global admins can read the activity webhook deliveries
+allow {
+  object.type == "activity_webhook_delivery"
+  subject.global_role == admin
+  action == read
+}
+
 ##
 # Sessions
 ##
@@ -514,6 +537,59 @@ allow {
   action == read
 }
 
+##
+# Vulnerability exceptions
+##
+
+# Global admins and maintainers can read and write all vulnerability exceptions.
+allow {
+  object.type == "vulnerability_exception"
+  subject.global_role == [admin, maintainer][_]
+  action == [read, write][_]
+}
+
+# Global observers can read all vulnerability exceptions.
+allow {
+  object.type == "vulnerability_exception"
+  subject.global_role == observer
+  action == read
+}
+
+# Team admins and maintainers can read and write the vulnerability exceptions
+# of their teams.
+allow {
+  not is_null(object.team_id)
+  object.type == "vulnerability_exception"
+  team_role(subject, object.team_id) == [admin, maintainer][_]
+  action == [read, write][_]
+}
+
+# Team observers can read the vulnerability exceptions of their teams.
+allow {
+  not is_null(object.team_id)
+  object.type == "vulnerability_exception"
+  team_role(subject, object.team_id) == observer
+  action == read
+}
+
+##
+# CPE translation rules
+##
+
+# Global admins and maintainers can read and write CPE translation rules.
+allow {
+  object.type == "cpe_translation_rule"
+  subject.global_role == [admin, maintainer][_]
+  action == [read, write][_]
+}
+
+# Global observers can read CPE translation rules.
+allow {
+  object.type == "cpe_translation_rule"
+  subject.global_role == observer
+  action == read
+}
+
 ##
 # Apple MDM
 ##
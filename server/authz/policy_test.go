@@ -763,6 +763,82 @@ func TestAuthorizeCarves(t *testing.T) {
 	})
 }
 
+func TestAuthorizeActivityWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	delivery := &fleet.ActivityWebhookDelivery{}
+	runTestCases(t, []authTestCase{
+		{user: nil, object: delivery, action: read, allow: false},
+		{user: nil, object: delivery, action: write, allow: false},
+		{user: test.UserNoRoles, object: delivery, action: read, allow: false},
+		{user: test.UserMaintainer, object: delivery, action: read, allow: false},
+		{user: test.UserObserver, object: delivery, action: read, allow: false},
+		{user: test.UserTeamAdminTeam1, object: delivery, action: read, allow: false},
+
+		// Only global admins can read, and nobody can write
+		{user: test.UserAdmin, object: delivery, action: read, allow: true},
+		{user: test.UserAdmin, object: delivery, action: write, allow: false},
+	})
+}
+
+func TestAuthorizeVulnerabilityExceptions(t *testing.T) {
+	t.Parallel()
+
+	globalException := &fleet.VulnerabilityException{}
+	teamException := &fleet.VulnerabilityException{TeamID: ptr.Uint(1)}
+	runTestCases(t, []authTestCase{
+		{user: nil, object: globalException, action: read, allow: false},
+		{user: test.UserNoRoles, object: globalException, action: read, allow: false},
+		{user: test.UserNoRoles, object: teamException, action: read, allow: false},
+
+		{user: test.UserAdmin, object: globalException, action: write, allow: true},
+		{user: test.UserAdmin, object: teamException, action: write, allow: true},
+		{user: test.UserMaintainer, object: globalException, action: write, allow: true},
+		{user: test.UserMaintainer, object: teamException, action: write, allow: true},
+		{user: test.UserObserver, object: globalException, action: read, allow: true},
+		{user: test.UserObserver, object: teamException, action: read, allow: true},
+		{user: test.UserObserver, object: globalException, action: write, allow: false},
+		{user: test.UserObserver, object: teamException, action: write, allow: false},
+
+		// team users read the global exceptions that apply to their team by
+		// listing the exceptions of their team.
+		{user: test.UserTeamAdminTeam1, object: globalException, action: read, allow: false},
+		{user: test.UserTeamAdminTeam1, object: globalException, action: write, allow: false},
+		{user: test.UserTeamObserverTeam1, object: globalException, action: read, allow: false},
+
+		{user: test.UserTeamAdminTeam1, object: teamException, action: write, allow: true},
+		{user: test.UserTeamAdminTeam2, object: teamException, action: read, allow: false},
+		{user: test.UserTeamAdminTeam2, object: teamException, action: write, allow: false},
+		{user: test.UserTeamMaintainerTeam1, object: teamException, action: write, allow: true},
+		{user: test.UserTeamMaintainerTeam2, object: teamException, action: write, allow: false},
+		{user: test.UserTeamObserverTeam1, object: teamException, action: read, allow: true},
+		{user: test.UserTeamObserverTeam1, object: teamException, action: write, allow: false},
+		{user: test.UserTeamObserverTeam2, object: teamException, action: read, allow: false},
+	})
+}
+
+func TestAuthorizeCPETranslationRules(t *testing.T) {
+	t.Parallel()
+
+	rule := &fleet.CPETranslationRuleSpec{}
+	runTestCases(t, []authTestCase{
+		{user: nil, object: rule, action: read, allow: false},
+		{user: test.UserNoRoles, object: rule, action: read, allow: false},
+
+		{user: test.UserAdmin, object: rule, action: read, allow: true},
+		{user: test.UserAdmin, object: rule, action: write, allow: true},
+		{user: test.UserMaintainer, object: rule, action: read, allow: true},
+		{user: test.UserMaintainer, object: rule, action: write, allow: true},
+		{user: test.UserObserver, object: rule, action: read, allow: true},
+		{user: test.UserObserver, object: rule, action: write, allow: false},
+
+		{user: test.UserTeamAdminTeam1, object: rule, action: read, allow: false},
+		{user: test.UserTeamAdminTeam1, object: rule, action: write, allow: false},
+		{user: test.UserTeamMaintainerTeam1, object: rule, action: write, allow: false},
+		{user: test.UserTeamObserverTeam1, object: rule, action: read, allow: false},
+	})
+}
+
 func TestAuthorizePolicies(t *testing.T) {
 	t.Parallel()
 
@@ -811,6 +887,51 @@ func TestAuthorizePolicies(t *testing.T) {
 	})
 }
 
+func TestAuthorizeNestedTeams(t *testing.T) {
+	t.Parallel()
+
+	// team 1 is the parent of teams 2 and 3, team 4 is a top-level team.
+	parentAdmin := &fleet.User{
+		Teams: []fleet.UserTeam{
+			{Team: fleet.Team{ID: 1}, Role: fleet.RoleAdmin, DescendantIDs: []uint{2, 3}},
+		},
+	}
+	parentMaintainer := &fleet.User{
+		Teams: []fleet.UserTeam{
+			{Team: fleet.Team{ID: 1}, Role: fleet.RoleMaintainer},
+		},
+	}
+	parentAdminChildObserver := &fleet.User{
+		Teams: []fleet.UserTeam{
+			{Team: fleet.Team{ID: 1}, Role: fleet.RoleAdmin, DescendantIDs: []uint{2, 3}},
+			{Team: fleet.Team{ID: 2}, Role: fleet.RoleObserver},
+		},
+	}
+	childTeam := &fleet.Team{ID: 2}
+	otherTeam := &fleet.Team{ID: 4}
+	hostChild := &fleet.Host{TeamID: ptr.Uint(2)}
+	hostOther := &fleet.Host{TeamID: ptr.Uint(4)}
+	childPolicy := &fleet.Policy{PolicyData: fleet.PolicyData{TeamID: ptr.Uint(3)}}
+
+	runTestCases(t, []authTestCase{
+		{user: parentAdmin, object: childTeam, action: write, allow: true},
+		{user: parentAdmin, object: otherTeam, action: write, allow: false},
+		{user: parentAdmin, object: hostChild, action: read, allow: true},
+		{user: parentAdmin, object: hostChild, action: write, allow: true},
+		{user: parentAdmin, object: hostOther, action: read, allow: false},
+		{user: parentAdmin, object: childPolicy, action: write, allow: true},
+
+		// only admins get access to the descendants
+		{user: parentMaintainer, object: childTeam, action: write, allow: false},
+		{user: parentMaintainer, object: hostChild, action: read, allow: false},
+		{user: parentMaintainer, object: childPolicy, action: read, allow: false},
+
+		// the inherited admin role takes precedence over the explicit one
+		{user: parentAdminChildObserver, object: childTeam, action: write, allow: true},
+		{user: parentAdminChildObserver, object: hostChild, action: write, allow: true},
+	})
+}
+
 func TestAuthorizeMDMAppleConfigProfile(t *testing.T) {
 	t.Parallel()
 
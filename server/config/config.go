@@ -121,6 +121,12 @@ type AppConfig struct {
 	TokenKeySize              int           `yaml:"token_key_size"`
 	InviteTokenValidityPeriod time.Duration `yaml:"invite_token_validity_period"`
 	EnableScheduledQueryStats bool          `yaml:"enable_scheduled_query_stats"`
+	// PolicyHistoryRetention is how long the daily snapshots of the policies'
+	// passing and failing host counts are kept. Zero keeps them forever.
+	PolicyHistoryRetention time.Duration `yaml:"policy_history_retention"`
+	// LiveQueryResultsRetention is how long the results of live query campaigns
+	// are persisted. Zero disables the persistence of the results.
+	LiveQueryResultsRetention time.Duration `yaml:"live_query_results_retention"`
 }
 
 // SessionConfig defines configs related to user sessions
@@ -136,6 +142,8 @@ type OsqueryConfig struct {
 	EnrollCooldown       time.Duration `yaml:"enroll_cooldown"`
 	StatusLogPlugin      string        `yaml:"status_log_plugin"`
 	ResultLogPlugin      string        `yaml:"result_log_plugin"`
+	StatusLogRoutes      []LogRoute    `yaml:"status_log_routes"`
+	ResultLogRoutes      []LogRoute    `yaml:"result_log_routes"`
 	LabelUpdateInterval  time.Duration `yaml:"label_update_interval"`
 	PolicyUpdateInterval time.Duration `yaml:"policy_update_interval"`
 	DetailUpdateInterval time.Duration `yaml:"detail_update_interval"`
@@ -162,6 +170,7 @@ type OsqueryConfig struct {
 	AsyncHostRedisPopCount           int           `yaml:"async_host_redis_pop_count"`
 	AsyncHostRedisScanKeysCount      int           `yaml:"async_host_redis_scan_keys_count"`
 	MinSoftwareLastOpenedAtDiff      time.Duration `yaml:"min_software_last_opened_at_diff"`
+	MaxQueryReportRows               int           `yaml:"max_query_report_rows"`
 }
 
 // AsyncTaskName is the type of names that identify tasks supporting
@@ -226,6 +235,18 @@ type LoggingConfig struct {
 	TracingType string `yaml:"tracing_type"`
 }
 
+// LogRoute defines a routing rule of the logs when multiple logging plugins
+// are configured. The logs matching all the non-empty criteria of the first
+// matching route are sent to its plugins, the logs matching no route are sent
+// to all the plugins.
+type LogRoute struct {
+	Plugins []string `json:"plugins" yaml:"plugins"`
+	Names   []string `json:"names,omitempty" yaml:"names"`
+	Packs   []string `json:"packs,omitempty" yaml:"packs"`
+	Queries []string `json:"queries,omitempty" yaml:"queries"`
+	TeamIDs []uint   `json:"team_ids,omitempty" yaml:"team_ids"`
+}
+
 // ActivityConfig defines configs related to activities.
 type ActivityConfig struct {
 	// EnableAuditLog enables logging for audit activities.
@@ -282,6 +303,15 @@ type S3Config struct {
 	ForceS3PathStyle bool   `yaml:"force_s3_path_style"`
 }
 
+// CarvesConfig defines the storage of the file carves data blocks
+type CarvesConfig struct {
+	// Storage is one of "mysql", "s3", "filesystem" or "object_store". When
+	// empty, "s3" is used if the S3 bucket is set, "mysql" otherwise.
+	Storage       string `yaml:"storage"`
+	FilesystemDir string `yaml:"filesystem_dir"`
+	EncryptionKey string `yaml:"encryption_key"`
+}
+
 // PubSubConfig defines configs the for Google PubSub logging plugin
 type PubSubConfig struct {
 	Project       string `json:"project"`
@@ -313,6 +343,54 @@ type KafkaRESTConfig struct {
 	Timeout          int    `json:"timeout" yaml:"timeout"`
 }
 
+// HTTPLogBatchConfig defines the batching, retry, size limit and TLS configs
+// shared by the HTTP-based logging plugins.
+type HTTPLogBatchConfig struct {
+	MaxBatchRecords int           `json:"max_batch_records" yaml:"max_batch_records"`
+	MaxBatchSize    int           `json:"max_batch_size" yaml:"max_batch_size"`
+	MaxRecordSize   int           `json:"max_record_size" yaml:"max_record_size"`
+	MaxRetries      int           `json:"max_retries" yaml:"max_retries"`
+	Timeout         time.Duration `json:"timeout" yaml:"timeout"`
+	TLSCA           string        `json:"tls_ca" yaml:"tls_ca"`
+	TLSCert         string        `json:"tls_cert" yaml:"tls_cert"`
+	TLSKey          string        `json:"tls_key" yaml:"tls_key"`
+	TLSServerName   string        `json:"tls_server_name" yaml:"tls_server_name"`
+}
+
+// HTTPLogConfig defines configs for the generic HTTP logging plugin.
+type HTTPLogConfig struct {
+	StatusURL          string `json:"status_url" yaml:"status_url"`
+	ResultURL          string `json:"result_url" yaml:"result_url"`
+	AuditURL           string `json:"audit_url" yaml:"audit_url"`
+	Authorization      string `json:"authorization" yaml:"authorization"`
+	HTTPLogBatchConfig `yaml:",inline"`
+}
+
+// SplunkConfig defines configs for the Splunk HTTP Event Collector logging
+// plugin.
+type SplunkConfig struct {
+	URL                string `json:"url" yaml:"url"`
+	Token              string `json:"token" yaml:"token"`
+	StatusIndex        string `json:"status_index" yaml:"status_index"`
+	ResultIndex        string `json:"result_index" yaml:"result_index"`
+	AuditIndex         string `json:"audit_index" yaml:"audit_index"`
+	SourceType         string `json:"source_type" yaml:"source_type"`
+	Source             string `json:"source" yaml:"source"`
+	HTTPLogBatchConfig `yaml:",inline"`
+}
+
+// ElasticsearchConfig defines configs for the Elasticsearch logging plugin.
+type ElasticsearchConfig struct {
+	URL                string `json:"url" yaml:"url"`
+	StatusIndex        string `json:"status_index" yaml:"status_index"`
+	ResultIndex        string `json:"result_index" yaml:"result_index"`
+	AuditIndex         string `json:"audit_index" yaml:"audit_index"`
+	Username           string `json:"username" yaml:"username"`
+	Password           string `json:"password" yaml:"password"`
+	APIKey             string `json:"api_key" yaml:"api_key"`
+	HTTPLogBatchConfig `yaml:",inline"`
+}
+
 // LicenseConfig defines configs related to licensing Fleet.
 type LicenseConfig struct {
 	Key              string `yaml:"key"`
@@ -422,9 +500,13 @@ type FleetConfig struct {
 	Kinesis          KinesisConfig
 	Lambda           LambdaConfig
 	S3               S3Config
+	Carves           CarvesConfig
 	PubSub           PubSubConfig
 	Filesystem       FilesystemConfig
 	KafkaREST        KafkaRESTConfig
+	HTTPLog          HTTPLogConfig `yaml:"http_log"`
+	Splunk           SplunkConfig
+	Elasticsearch    ElasticsearchConfig
 	License          LicenseConfig
 	Vulnerabilities  VulnerabilitiesConfig
 	Upgrades         UpgradesConfig
@@ -804,6 +886,10 @@ func (man Manager) addConfigs() {
 		"Size of generated tokens")
 	man.addConfigBool("app.enable_scheduled_query_stats", true,
 		"If true (default) it gets scheduled query stats from hosts")
+	man.addConfigDuration("app.policy_history_retention", 365*24*time.Hour,
+		"Duration the daily snapshots of policy results are kept (0 keeps them forever)")
+	man.addConfigDuration("app.live_query_results_retention", 0,
+		"Duration the results of live queries are persisted (0 disables persistence)")
 
 	// Session
 	man.addConfigInt("session.key_size", 64,
@@ -822,6 +908,10 @@ func (man Manager) addConfigs() {
 		"Log plugin to use for status logs")
 	man.addConfigString("osquery.result_log_plugin", "filesystem",
 		"Log plugin to use for result logs")
+	man.addConfigString("osquery.status_log_routes", "",
+		"Routing rules of status logs when multiple plugins are used (JSON list)")
+	man.addConfigString("osquery.result_log_routes", "",
+		"Routing rules of result logs when multiple plugins are used (JSON list)")
 	man.addConfigDuration("osquery.label_update_interval", 1*time.Hour,
 		"Interval to update host label membership (i.e. 1h)")
 	man.addConfigDuration("osquery.policy_update_interval", 1*time.Hour,
@@ -858,6 +948,8 @@ func (man Manager) addConfigs() {
 		"Batch size to scan redis keys in async collection")
 	man.addConfigDuration("osquery.min_software_last_opened_at_diff", 1*time.Hour,
 		"Minimum time difference of the software's last opened timestamp (compared to the last one saved) to trigger an update to the database")
+	man.addConfigInt("osquery.max_query_report_rows", 1000,
+		"Maximum number of result rows stored per query for the queries that store their results")
 
 	// Activities
 	man.addConfigBool("activity.enable_audit_log", false,
@@ -933,6 +1025,11 @@ func (man Manager) addConfigs() {
 	man.addConfigBool("s3.disable_ssl", false, "Disable SSL (typically for local testing)")
 	man.addConfigBool("s3.force_s3_path_style", false, "Set this to true to force path-style addressing, i.e., `http://s3.amazonaws.com/BUCKET/KEY`")
 
+	// File carves storage
+	man.addConfigString("carves.storage", "", "Storage of the file carves (mysql, s3, filesystem or object_store, defaults to s3 if the S3 bucket is set, mysql otherwise)")
+	man.addConfigString("carves.filesystem_dir", "", "Directory where to store file carves with the filesystem storage")
+	man.addConfigString("carves.encryption_key", "", "Base64-encoded 32 bytes key to encrypt the file carves at rest")
+
 	// PubSub
 	man.addConfigString("pubsub.project", "", "Google Cloud Project to use")
 	man.addConfigString("pubsub.status_topic", "", "PubSub topic for status logs")
@@ -964,6 +1061,46 @@ func (man Manager) addConfigs() {
 		"Kafka REST proxy content type header (defaults to \"application/vnd.kafka.json.v1+json\"")
 	man.addConfigInt("kafkarest.timeout", 5, "Kafka REST proxy json post timeout")
 
+	// HTTP-based logging plugins
+	addHTTPLogBatchConfig := func(prefix, name string) {
+		man.addConfigInt(prefix+".max_batch_records", 500, "Maximum number of logs sent in a single request to "+name)
+		man.addConfigInt(prefix+".max_batch_size", 5*1000*1000, "Maximum size in bytes of a single request to "+name)
+		man.addConfigInt(prefix+".max_record_size", 1000*1000, "Maximum size in bytes of a log sent to "+name+", bigger logs are dropped")
+		man.addConfigInt(prefix+".max_retries", 8, "Maximum number of retries of a failed request to "+name)
+		man.addConfigDuration(prefix+".timeout", 10*time.Second, "Timeout of the requests to "+name)
+		man.addConfigString(prefix+".tls_ca", "", name+" TLS server CA")
+		man.addConfigString(prefix+".tls_cert", "", name+" TLS client certificate path")
+		man.addConfigString(prefix+".tls_key", "", name+" TLS client key path")
+		man.addConfigString(prefix+".tls_server_name", "", name+" TLS server name")
+	}
+
+	// HTTP
+	man.addConfigString("http_log.status_url", "", "URL where the status logs are POSTed")
+	man.addConfigString("http_log.result_url", "", "URL where the result logs are POSTed")
+	man.addConfigString("http_log.audit_url", "", "URL where the audit logs are POSTed")
+	man.addConfigString("http_log.authorization", "", "Value of the Authorization header of the HTTP logging requests")
+	addHTTPLogBatchConfig("http_log", "the HTTP logging endpoint")
+
+	// Splunk
+	man.addConfigString("splunk.url", "", "Splunk HTTP Event Collector URL (e.g. https://splunk.example.com:8088)")
+	man.addConfigString("splunk.token", "", "Splunk HTTP Event Collector token")
+	man.addConfigString("splunk.status_index", "", "Splunk index for status logs (blank for the default index of the token)")
+	man.addConfigString("splunk.result_index", "", "Splunk index for result logs (blank for the default index of the token)")
+	man.addConfigString("splunk.audit_index", "", "Splunk index for audit logs (blank for the default index of the token)")
+	man.addConfigString("splunk.source_type", "_json", "Splunk source type of the logs")
+	man.addConfigString("splunk.source", "fleet", "Splunk source of the logs")
+	addHTTPLogBatchConfig("splunk", "Splunk")
+
+	// Elasticsearch
+	man.addConfigString("elasticsearch.url", "", "Elasticsearch URL (e.g. https://elasticsearch.example.com:9200)")
+	man.addConfigString("elasticsearch.status_index", "", "Elasticsearch index or data stream for status logs")
+	man.addConfigString("elasticsearch.result_index", "", "Elasticsearch index or data stream for result logs")
+	man.addConfigString("elasticsearch.audit_index", "", "Elasticsearch index or data stream for audit logs")
+	man.addConfigString("elasticsearch.username", "", "Elasticsearch username for basic authentication")
+	man.addConfigString("elasticsearch.password", "", "Elasticsearch password for basic authentication")
+	man.addConfigString("elasticsearch.api_key", "", "Elasticsearch API key (takes precedence over basic authentication)")
+	addHTTPLogBatchConfig("elasticsearch", "Elasticsearch")
+
 	// License
 	man.addConfigString("license.key", "", "Fleet license key (to enable Fleet Premium features)")
 	man.addConfigBool("license.enforce_host_limit", false, "Enforce license limit of enrolled hosts")
@@ -1099,6 +1236,20 @@ func (man Manager) LoadConfig() FleetConfig {
 		}
 	}
 
+	loadHTTPLogBatchConfig := func(prefix string) HTTPLogBatchConfig {
+		return HTTPLogBatchConfig{
+			MaxBatchRecords: man.getConfigInt(prefix + ".max_batch_records"),
+			MaxBatchSize:    man.getConfigInt(prefix + ".max_batch_size"),
+			MaxRecordSize:   man.getConfigInt(prefix + ".max_record_size"),
+			MaxRetries:      man.getConfigInt(prefix + ".max_retries"),
+			Timeout:         man.getConfigDuration(prefix + ".timeout"),
+			TLSCA:           man.getConfigString(prefix + ".tls_ca"),
+			TLSCert:         man.getConfigString(prefix + ".tls_cert"),
+			TLSKey:          man.getConfigString(prefix + ".tls_key"),
+			TLSServerName:   man.getConfigString(prefix + ".tls_server_name"),
+		}
+	}
+
 	cfg := FleetConfig{
 		Mysql:            loadMysqlConfig("mysql"),
 		MysqlReadReplica: loadMysqlConfig("mysql_read_replica"),
@@ -1145,6 +1296,8 @@ func (man Manager) LoadConfig() FleetConfig {
 			TokenKeySize:              man.getConfigInt("app.token_key_size"),
 			InviteTokenValidityPeriod: man.getConfigDuration("app.invite_token_validity_period"),
 			EnableScheduledQueryStats: man.getConfigBool("app.enable_scheduled_query_stats"),
+			PolicyHistoryRetention:    man.getConfigDuration("app.policy_history_retention"),
+			LiveQueryResultsRetention: man.getConfigDuration("app.live_query_results_retention"),
 		},
 		Session: SessionConfig{
 			KeySize:  man.getConfigInt("session.key_size"),
@@ -1156,6 +1309,8 @@ func (man Manager) LoadConfig() FleetConfig {
 			EnrollCooldown:  man.getConfigDuration("osquery.enroll_cooldown"),
 			StatusLogPlugin: man.getConfigString("osquery.status_log_plugin"),
 			ResultLogPlugin: man.getConfigString("osquery.result_log_plugin"),
+			StatusLogRoutes: man.getConfigLogRoutes("osquery.status_log_routes"),
+			ResultLogRoutes: man.getConfigLogRoutes("osquery.result_log_routes"),
 			// StatusLogFile is deprecated. FilesystemConfig.StatusLogFile is used instead.
 			StatusLogFile: man.getConfigString("osquery.status_log_file"),
 			// ResultLogFile is deprecated. FilesystemConfig.ResultLogFile is used instead.
@@ -1176,6 +1331,7 @@ func (man Manager) LoadConfig() FleetConfig {
 			AsyncHostRedisPopCount:           man.getConfigInt("osquery.async_host_redis_pop_count"),
 			AsyncHostRedisScanKeysCount:      man.getConfigInt("osquery.async_host_redis_scan_keys_count"),
 			MinSoftwareLastOpenedAtDiff:      man.getConfigDuration("osquery.min_software_last_opened_at_diff"),
+			MaxQueryReportRows:               man.getConfigInt("osquery.max_query_report_rows"),
 		},
 		Activity: ActivityConfig{
 			EnableAuditLog: man.getConfigBool("activity.enable_audit_log"),
@@ -1229,6 +1385,11 @@ func (man Manager) LoadConfig() FleetConfig {
 			DisableSSL:       man.getConfigBool("s3.disable_ssl"),
 			ForceS3PathStyle: man.getConfigBool("s3.force_s3_path_style"),
 		},
+		Carves: CarvesConfig{
+			Storage:       man.getConfigString("carves.storage"),
+			FilesystemDir: man.getConfigString("carves.filesystem_dir"),
+			EncryptionKey: man.getConfigString("carves.encryption_key"),
+		},
 		PubSub: PubSubConfig{
 			Project:       man.getConfigString("pubsub.project"),
 			StatusTopic:   man.getConfigString("pubsub.status_topic"),
@@ -1254,6 +1415,33 @@ func (man Manager) LoadConfig() FleetConfig {
 			ContentTypeValue: man.getConfigString("kafkarest.content_type_value"),
 			Timeout:          man.getConfigInt("kafkarest.timeout"),
 		},
+		HTTPLog: HTTPLogConfig{
+			StatusURL:          man.getConfigString("http_log.status_url"),
+			ResultURL:          man.getConfigString("http_log.result_url"),
+			AuditURL:           man.getConfigString("http_log.audit_url"),
+			Authorization:      man.getConfigString("http_log.authorization"),
+			HTTPLogBatchConfig: loadHTTPLogBatchConfig("http_log"),
+		},
+		Splunk: SplunkConfig{
+			URL:                man.getConfigString("splunk.url"),
+			Token:              man.getConfigString("splunk.token"),
+			StatusIndex:        man.getConfigString("splunk.status_index"),
+			ResultIndex:        man.getConfigString("splunk.result_index"),
+			AuditIndex:         man.getConfigString("splunk.audit_index"),
+			SourceType:         man.getConfigString("splunk.source_type"),
+			Source:             man.getConfigString("splunk.source"),
+			HTTPLogBatchConfig: loadHTTPLogBatchConfig("splunk"),
+		},
+		Elasticsearch: ElasticsearchConfig{
+			URL:                man.getConfigString("elasticsearch.url"),
+			StatusIndex:        man.getConfigString("elasticsearch.status_index"),
+			ResultIndex:        man.getConfigString("elasticsearch.result_index"),
+			AuditIndex:         man.getConfigString("elasticsearch.audit_index"),
+			Username:           man.getConfigString("elasticsearch.username"),
+			Password:           man.getConfigString("elasticsearch.password"),
+			APIKey:             man.getConfigString("elasticsearch.api_key"),
+			HTTPLogBatchConfig: loadHTTPLogBatchConfig("elasticsearch"),
+		},
 		License: LicenseConfig{
 			Key:              man.getConfigString("license.key"),
 			EnforceHostLimit: man.getConfigBool("license.enforce_host_limit"),
@@ -1432,6 +1620,33 @@ func (man Manager) getConfigString(key string) string {
 	return stringVal
 }
 
+// getConfigLogRoutes retrieves the logging routes, set either as a list in the
+// config file or as a JSON encoded list in the environment or flags.
+func (man Manager) getConfigLogRoutes(key string) []LogRoute {
+	var b []byte
+	switch v := man.getInterfaceVal(key).(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		b = []byte(v)
+	default:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			panic("Unable to parse log routes for key " + key + ": " + err.Error())
+		}
+	}
+
+	var routes []LogRoute
+	if err := json.Unmarshal(b, &routes); err != nil {
+		panic("Unable to parse log routes for key " + key + ": " + err.Error())
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+	return routes
+}
+
 // Custom handling for TLSProfile which can only accept specific values
 // for the argument
 func (man Manager) getConfigTLSProfile() string {
@@ -1632,6 +1847,7 @@ func TestConfig() FleetConfig {
 			PolicyUpdateInterval: 1 * time.Hour,
 			DetailUpdateInterval: 1 * time.Hour,
 			MaxJitterPercent:     0,
+			MaxQueryReportRows:   1000,
 		},
 		Activity: ActivityConfig{
 			EnableAuditLog: true,
@@ -342,6 +342,70 @@ osquery:
 	}
 }
 
+func TestConfigLogRoutes(t *testing.T) {
+	want := []LogRoute{
+		{Plugins: []string{"kinesis"}, Queries: []string{"security_*"}},
+		{Plugins: []string{"filesystem", "splunk"}, Packs: []string{"Team: *"}, TeamIDs: []uint{1, 2}},
+	}
+
+	cases := []struct {
+		desc    string
+		yaml    string
+		envVars []string
+		want    []LogRoute
+		panics  bool
+	}{
+		{"default", "", nil, nil, false},
+		{
+			"yaml",
+			`
+osquery:
+  result_log_routes:
+    - plugins: [kinesis]
+      queries: ["security_*"]
+    - plugins: [filesystem, splunk]
+      packs: ["Team: *"]
+      team_ids: [1, 2]
+`,
+			nil, want, false,
+		},
+		{
+			"env var",
+			"",
+			[]string{`FLEET_OSQUERY_RESULT_LOG_ROUTES=[{"plugins":["kinesis"],"queries":["security_*"]},{"plugins":["filesystem","splunk"],"packs":["Team: *"],"team_ids":[1,2]}]`},
+			want, false,
+		},
+		{"invalid env var", "", []string{`FLEET_OSQUERY_RESULT_LOG_ROUTES={"plugins":"kinesis"}`}, nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var cmd cobra.Command
+			// Leaving this flag unset means that no attempt will be made to load
+			// the config file
+			cmd.PersistentFlags().StringP("config", "c", "", "Path to a configuration file")
+			man := NewManager(&cmd)
+
+			man.viper.SetConfigType("yaml")
+			require.NoError(t, man.viper.ReadConfig(strings.NewReader(c.yaml)))
+
+			os.Clearenv()
+			for _, env := range c.envVars {
+				kv := strings.SplitN(env, "=", 2)
+				t.Setenv(kv[0], kv[1])
+			}
+
+			if c.panics {
+				require.Panics(t, func() { man.LoadConfig() })
+				return
+			}
+			loadedCfg := man.LoadConfig()
+			require.Equal(t, c.want, loadedCfg.Osquery.ResultLogRoutes)
+			require.Nil(t, loadedCfg.Osquery.StatusLogRoutes)
+		})
+	}
+}
+
 func TestToTLSConfig(t *testing.T) {
 	dir := t.TempDir()
 	caFile, certFile, keyFile, garbageFile := filepath.Join(dir, "ca"),
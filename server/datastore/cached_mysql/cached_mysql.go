@@ -25,6 +25,9 @@ const (
 	defaultTeamFeaturesExpiration     = 1 * time.Minute
 	teamMDMConfigKey                  = "TeamMDMConfig:team:%d"
 	defaultTeamMDMConfigExpiration    = 1 * time.Minute
+	teamScheduleOverridesKey          = "TeamScheduleOverrides:team:%d"
+	updateRolloutsKey                 = "UpdateRollouts"
+	defaultUpdateRolloutsExpiration   = 1 * time.Minute
 )
 
 // cloner represents any type that can clone itself. Used by types to provide a more efficient clone method.
@@ -109,6 +112,7 @@ type cachedMysql struct {
 	teamAgentOptionsExp time.Duration
 	teamFeaturesExp     time.Duration
 	teamMDMConfigExp    time.Duration
+	updateRolloutsExp   time.Duration
 }
 
 type Option func(*cachedMysql)
@@ -143,6 +147,12 @@ func WithTeamMDMConfigExpiration(d time.Duration) Option {
 	}
 }
 
+func WithUpdateRolloutsExpiration(d time.Duration) Option {
+	return func(o *cachedMysql) {
+		o.updateRolloutsExp = d
+	}
+}
+
 func New(ds fleet.Datastore, opts ...Option) fleet.Datastore {
 	c := &cachedMysql{
 		Datastore:           ds,
@@ -151,6 +161,7 @@ func New(ds fleet.Datastore, opts ...Option) fleet.Datastore {
 		scheduledQueriesExp: defaultScheduledQueriesExpiration,
 		teamAgentOptionsExp: defaultTeamAgentOptionsExpiration,
 		teamFeaturesExp:     defaultTeamFeaturesExpiration,
+		updateRolloutsExp:   defaultUpdateRolloutsExpiration,
 	}
 	for _, fn := range opts {
 		fn(c)
@@ -236,6 +247,36 @@ func (ds *cachedMysql) ListScheduledQueriesInPack(ctx context.Context, packID ui
 	return scheduledQueries, nil
 }
 
+// ListTeamScheduledQueryOverrides uses the same expiration as the scheduled
+// queries, as they are used together to build the hosts' schedule.
+func (ds *cachedMysql) ListTeamScheduledQueryOverrides(ctx context.Context, teamID uint) (fleet.TeamScheduledQueryOverrideList, error) {
+	key := fmt.Sprintf(teamScheduleOverridesKey, teamID)
+	if x, found := ds.c.Get(key); found {
+		if overrides, ok := x.(fleet.TeamScheduledQueryOverrideList); ok {
+			return overrides, nil
+		}
+	}
+
+	overrides, err := ds.Datastore.ListTeamScheduledQueryOverrides(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	ds.c.Set(key, overrides, ds.scheduledQueriesExp)
+
+	return overrides, nil
+}
+
+func (ds *cachedMysql) ReplaceTeamScheduledQueryOverrides(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error {
+	if err := ds.Datastore.ReplaceTeamScheduledQueryOverrides(ctx, teamID, overrides); err != nil {
+		return err
+	}
+
+	ds.c.Delete(fmt.Sprintf(teamScheduleOverridesKey, teamID))
+
+	return nil
+}
+
 func (ds *cachedMysql) TeamAgentOptions(ctx context.Context, teamID uint) (*json.RawMessage, error) {
 	key := fmt.Sprintf(teamAgentOptionsKey, teamID)
 	if x, found := ds.c.Get(key); found {
@@ -297,9 +338,16 @@ func (ds *cachedMysql) SaveTeam(ctx context.Context, team *fleet.Team) (*fleet.T
 	featuresKey := fmt.Sprintf(teamFeaturesKey, team.ID)
 	mdmConfigKey := fmt.Sprintf(teamMDMConfigKey, team.ID)
 
-	ds.c.Set(agentOptionsKey, team.Config.AgentOptions, ds.teamAgentOptionsExp)
 	ds.c.Set(featuresKey, &team.Config.Features, ds.teamFeaturesExp)
-	ds.c.Set(mdmConfigKey, &team.Config.MDM, ds.teamMDMConfigExp)
+	if team.ParentTeamID != nil {
+		// the team may inherit those from its ancestors, let the next read
+		// resolve them.
+		ds.c.Delete(agentOptionsKey)
+		ds.c.Delete(mdmConfigKey)
+	} else {
+		ds.c.Set(agentOptionsKey, team.Config.AgentOptions, ds.teamAgentOptionsExp)
+		ds.c.Set(mdmConfigKey, &team.Config.MDM, ds.teamMDMConfigExp)
+	}
 
 	return team, nil
 }
@@ -317,6 +365,49 @@ func (ds *cachedMysql) DeleteTeam(ctx context.Context, teamID uint) error {
 	ds.c.Delete(agentOptionsKey)
 	ds.c.Delete(featuresKey)
 	ds.c.Delete(mdmConfigKey)
+	ds.c.Delete(fmt.Sprintf(teamScheduleOverridesKey, teamID))
+
+	return nil
+}
+
+func (ds *cachedMysql) ListUpdateRollouts(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+	if x, found := ds.c.Get(updateRolloutsKey); found {
+		if rollouts, ok := x.([]*fleet.UpdateRollout); ok {
+			return rollouts, nil
+		}
+	}
+
+	rollouts, err := ds.Datastore.ListUpdateRollouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds.c.Set(updateRolloutsKey, rollouts, ds.updateRolloutsExp)
+
+	return rollouts, nil
+}
 
+func (ds *cachedMysql) NewUpdateRollout(ctx context.Context, rollout *fleet.UpdateRollout) (*fleet.UpdateRollout, error) {
+	rollout, err := ds.Datastore.NewUpdateRollout(ctx, rollout)
+	if err != nil {
+		return nil, err
+	}
+	ds.c.Delete(updateRolloutsKey)
+	return rollout, nil
+}
+
+func (ds *cachedMysql) SaveUpdateRollout(ctx context.Context, rollout *fleet.UpdateRollout) error {
+	if err := ds.Datastore.SaveUpdateRollout(ctx, rollout); err != nil {
+		return err
+	}
+	ds.c.Delete(updateRolloutsKey)
+	return nil
+}
+
+func (ds *cachedMysql) DeleteUpdateRollout(ctx context.Context, id uint) error {
+	if err := ds.Datastore.DeleteUpdateRollout(ctx, id); err != nil {
+		return err
+	}
+	ds.c.Delete(updateRolloutsKey)
 	return nil
 }
@@ -301,6 +301,53 @@ func TestCachedListScheduledQueriesInPack(t *testing.T) {
 	require.Equal(t, 2, called)
 }
 
+func TestCachedTeamScheduledQueryOverrides(t *testing.T) {
+	t.Parallel()
+
+	mockedDS := new(mock.Store)
+	ds := New(mockedDS, WithScheduledQueriesExpiration(100*time.Millisecond))
+
+	dbOverrides := fleet.TeamScheduledQueryOverrideList{
+		{TeamID: 1, ScheduledQueryID: 1, Name: "test-schedule-1", Disabled: true},
+		{TeamID: 1, ScheduledQueryID: 2, Name: "test-schedule-2", Interval: ptr.Uint(60)},
+	}
+	called := 0
+	mockedDS.ListTeamScheduledQueryOverridesFunc = func(ctx context.Context, teamID uint) (fleet.TeamScheduledQueryOverrideList, error) {
+		called++
+		return dbOverrides, nil
+	}
+	mockedDS.ReplaceTeamScheduledQueryOverridesFunc = func(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error {
+		dbOverrides = overrides
+		return nil
+	}
+
+	overrides, err := ds.ListTeamScheduledQueryOverrides(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, dbOverrides, overrides)
+
+	// the cached overrides are returned
+	_, err = ds.ListTeamScheduledQueryOverrides(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, called)
+
+	// replacing the overrides clears the cache
+	err = ds.ReplaceTeamScheduledQueryOverrides(context.Background(), 1, fleet.TeamScheduledQueryOverrideList{
+		{TeamID: 1, ScheduledQueryID: 3, Name: "test-schedule-3", Shard: ptr.Uint(10)},
+	})
+	require.NoError(t, err)
+
+	overrides, err = ds.ListTeamScheduledQueryOverrides(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, dbOverrides, overrides)
+	require.Equal(t, 2, called)
+
+	time.Sleep(200 * time.Millisecond)
+
+	_, err = ds.ListTeamScheduledQueryOverrides(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, called)
+}
+
 func TestCachedTeamAgentOptions(t *testing.T) {
 	t.Parallel()
 
@@ -387,6 +434,31 @@ func TestCachedTeamAgentOptions(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestCachedChildTeamAgentOptions(t *testing.T) {
+	t.Parallel()
+
+	mockedDS := new(mock.Store)
+	ds := New(mockedDS, WithTeamAgentOptionsExpiration(100*time.Millisecond))
+
+	inherited := json.RawMessage(`{"config": {"options": {"distributed_interval": 10}}}`)
+	mockedDS.TeamAgentOptionsFunc = func(ctx context.Context, teamID uint) (*json.RawMessage, error) {
+		return &inherited, nil
+	}
+	mockedDS.SaveTeamFunc = func(ctx context.Context, team *fleet.Team) (*fleet.Team, error) {
+		return team, nil
+	}
+
+	// saving a child team without agent options does not cache its own
+	// (empty) options, they are inherited from its parent.
+	_, err := ds.SaveTeam(context.Background(), &fleet.Team{ID: 2, Name: "child", ParentTeamID: ptr.Uint(1)})
+	require.NoError(t, err)
+
+	options, err := ds.TeamAgentOptions(context.Background(), 2)
+	require.NoError(t, err)
+	require.JSONEq(t, string(inherited), string(*options))
+	require.True(t, mockedDS.TeamAgentOptionsFuncInvoked)
+}
+
 func TestCachedTeamFeatures(t *testing.T) {
 	t.Parallel()
 
@@ -536,3 +608,46 @@ func TestCachedTeamMDMConfig(t *testing.T) {
 	_, err = ds.TeamMDMConfig(context.Background(), testTeam.ID)
 	require.Error(t, err)
 }
+
+func TestCachedUpdateRollouts(t *testing.T) {
+	t.Parallel()
+
+	mockedDS := new(mock.Store)
+	ds := New(mockedDS, WithUpdateRolloutsExpiration(100*time.Millisecond))
+
+	rollouts := []*fleet.UpdateRollout{{ID: 1, Name: "a", Target: "orbit", Channel: "stable", Percentage: 10}}
+	var calls int
+	mockedDS.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		calls++
+		return rollouts, nil
+	}
+	mockedDS.SaveUpdateRolloutFunc = func(ctx context.Context, rollout *fleet.UpdateRollout) error {
+		rollouts = []*fleet.UpdateRollout{rollout}
+		return nil
+	}
+
+	got, err := ds.ListUpdateRollouts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, rollouts, got)
+
+	// the cached rollouts are returned, and modifying them doesn't change the
+	// cache
+	got[0].Percentage = 50
+	got, err = ds.ListUpdateRollouts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint(10), got[0].Percentage)
+	require.Equal(t, 1, calls)
+
+	// saving a rollout invalidates the cache
+	require.NoError(t, ds.SaveUpdateRollout(context.Background(), &fleet.UpdateRollout{ID: 1, Name: "a", Target: "orbit", Channel: "stable", Percentage: 20}))
+	got, err = ds.ListUpdateRollouts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint(20), got[0].Percentage)
+	require.Equal(t, 2, calls)
+
+	// the cache expires
+	time.Sleep(200 * time.Millisecond)
+	_, err = ds.ListUpdateRollouts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
@@ -60,6 +60,10 @@ WHERE true`
 		query += " AND a.streamed = ?"
 		args = append(args, *opt.Streamed)
 	}
+	if opt.WebhooksProcessed != nil {
+		query += " AND a.webhooks_processed = ?"
+		args = append(args, *opt.WebhooksProcessed)
+	}
 
 	if !(opt.ListOptions.UsesCursorPagination()) {
 		opt.ListOptions.IncludeMetadata = true
@@ -98,3 +102,152 @@ func (ds *Datastore) MarkActivitiesAsStreamed(ctx context.Context, activityIDs [
 	}
 	return nil
 }
+
+func (ds *Datastore) MarkActivitiesAsWebhooksProcessed(ctx context.Context, activityIDs []uint) error {
+	if len(activityIDs) == 0 {
+		return nil
+	}
+	stmt := `UPDATE activities SET webhooks_processed = true WHERE id IN (?);`
+	query, args, err := sqlx.In(stmt, activityIDs)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "sqlx.In mark activities as webhooks processed")
+	}
+	if _, err := ds.writer.ExecContext(ctx, query, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "exec mark activities as webhooks processed")
+	}
+	return nil
+}
+
+func (ds *Datastore) NewActivityWebhookDelivery(ctx context.Context, delivery *fleet.ActivityWebhookDelivery) (*fleet.ActivityWebhookDelivery, error) {
+	const stmt = `
+INSERT INTO activity_webhook_deliveries (
+	activity_id,
+	activity_type,
+	destination_url,
+	status
+)
+VALUES (?, ?, ?, ?)`
+
+	status := delivery.Status
+	if status == "" {
+		status = fleet.ActivityWebhookDeliveryPending
+	}
+	res, err := ds.writer.ExecContext(ctx, stmt, delivery.ActivityID, delivery.ActivityType, delivery.DestinationURL, status)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "insert activity webhook delivery")
+	}
+	id, _ := res.LastInsertId()
+	return ds.ActivityWebhookDelivery(ctx, uint(id))
+}
+
+const selectActivityWebhookDeliveriesStmt = `
+SELECT
+	id,
+	created_at,
+	updated_at,
+	activity_id,
+	activity_type,
+	destination_url,
+	status,
+	attempts,
+	response_status,
+	error
+FROM
+	activity_webhook_deliveries`
+
+func (ds *Datastore) ActivityWebhookDelivery(ctx context.Context, id uint) (*fleet.ActivityWebhookDelivery, error) {
+	var delivery fleet.ActivityWebhookDelivery
+	// use the primary, the delivery is typically loaded right after it was
+	// created or updated.
+	err := sqlx.GetContext(ctx, ds.writer, &delivery, selectActivityWebhookDeliveriesStmt+` WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ctxerr.Wrap(ctx, notFound("ActivityWebhookDelivery").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get activity webhook delivery")
+	}
+	return &delivery, nil
+}
+
+func (ds *Datastore) UpdateActivityWebhookDelivery(ctx context.Context, delivery *fleet.ActivityWebhookDelivery) error {
+	return updateActivityWebhookDeliveryDB(ctx, ds.writer, delivery)
+}
+
+func (ds *Datastore) UpdateActivityWebhookDeliveryAndJob(ctx context.Context, delivery *fleet.ActivityWebhookDelivery, job *fleet.Job) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if err := updateActivityWebhookDeliveryDB(ctx, tx, delivery); err != nil {
+			return err
+		}
+		return ctxerr.Wrap(ctx, updateJobDB(ctx, tx, job), "update job")
+	})
+}
+
+func updateActivityWebhookDeliveryDB(ctx context.Context, tx sqlx.ExtContext, delivery *fleet.ActivityWebhookDelivery) error {
+	const stmt = `
+UPDATE activity_webhook_deliveries
+SET
+	status = ?,
+	attempts = ?,
+	response_status = ?,
+	error = ?
+WHERE
+	id = ?`
+
+	res, err := tx.ExecContext(ctx, stmt, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error, delivery.ID)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "update activity webhook delivery")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// the row may exist but be unchanged, make sure it does exist
+		var exists bool
+		if err := sqlx.GetContext(ctx, tx, &exists, `SELECT 1 FROM activity_webhook_deliveries WHERE id = ?`, delivery.ID); err != nil {
+			if err == sql.ErrNoRows {
+				return ctxerr.Wrap(ctx, notFound("ActivityWebhookDelivery").WithID(delivery.ID))
+			}
+			return ctxerr.Wrap(ctx, err, "check activity webhook delivery")
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) ListActivityWebhookDeliveries(ctx context.Context, opt fleet.ListActivityWebhookDeliveriesOptions) ([]*fleet.ActivityWebhookDelivery, *fleet.PaginationMetadata, error) {
+	query := selectActivityWebhookDeliveriesStmt + ` WHERE true`
+
+	var args []interface{}
+	if opt.Status != "" {
+		query += " AND status = ?"
+		args = append(args, opt.Status)
+	}
+	if opt.ActivityType != "" {
+		query += " AND activity_type = ?"
+		args = append(args, opt.ActivityType)
+	}
+	if opt.ActivityID != nil {
+		query += " AND activity_id = ?"
+		args = append(args, *opt.ActivityID)
+	}
+
+	if opt.ListOptions.OrderKey == "" {
+		opt.ListOptions.OrderKey = "id"
+		opt.ListOptions.OrderDirection = fleet.OrderDescending
+	}
+	if !(opt.ListOptions.UsesCursorPagination()) {
+		opt.ListOptions.IncludeMetadata = true
+	}
+	query, args = appendListOptionsWithCursorToSQL(query, args, &opt.ListOptions)
+
+	deliveries := []*fleet.ActivityWebhookDelivery{}
+	if err := sqlx.SelectContext(ctx, ds.reader, &deliveries, query, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "select activity webhook deliveries")
+	}
+
+	var metaData *fleet.PaginationMetadata
+	if opt.ListOptions.IncludeMetadata {
+		metaData = &fleet.PaginationMetadata{HasPreviousResults: opt.Page > 0}
+		if len(deliveries) > int(opt.ListOptions.PerPage) {
+			metaData.HasNextResults = true
+			deliveries = deliveries[:len(deliveries)-1]
+		}
+	}
+	return deliveries, metaData, nil
+}
@@ -25,6 +25,8 @@ func TestActivity(t *testing.T) {
 		{"ListActivitiesStreamed", testListActivitiesStreamed},
 		{"EmptyUser", testActivityEmptyUser},
 		{"PaginationMetadata", testActivityPaginationMetadata},
+		{"ListActivitiesWebhooksProcessed", testListActivitiesWebhooksProcessed},
+		{"WebhookDeliveries", testActivityWebhookDeliveries},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -289,3 +291,147 @@ func testActivityPaginationMetadata(t *testing.T, ds *Datastore) {
 		})
 	}
 }
+
+func testListActivitiesWebhooksProcessed(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, ds.NewActivity(ctx, nil, dummyActivity{
+			name:    fmt.Sprintf("test-%d", i),
+			details: map[string]interface{}{},
+		}))
+	}
+
+	unprocessed, _, err := ds.ListActivities(ctx, fleet.ListActivitiesOptions{
+		ListOptions:       fleet.ListOptions{OrderKey: "id"},
+		WebhooksProcessed: ptr.Bool(false),
+	})
+	require.NoError(t, err)
+	require.Len(t, unprocessed, 3)
+
+	// no-op with no IDs
+	require.NoError(t, ds.MarkActivitiesAsWebhooksProcessed(ctx, nil))
+	require.NoError(t, ds.MarkActivitiesAsWebhooksProcessed(ctx, []uint{unprocessed[0].ID, unprocessed[2].ID}))
+
+	unprocessed2, _, err := ds.ListActivities(ctx, fleet.ListActivitiesOptions{
+		WebhooksProcessed: ptr.Bool(false),
+	})
+	require.NoError(t, err)
+	require.Len(t, unprocessed2, 1)
+	require.Equal(t, unprocessed[1].ID, unprocessed2[0].ID)
+
+	processed, _, err := ds.ListActivities(ctx, fleet.ListActivitiesOptions{
+		WebhooksProcessed: ptr.Bool(true),
+	})
+	require.NoError(t, err)
+	require.Len(t, processed, 2)
+
+	// the streamed flag is independent
+	notStreamed, _, err := ds.ListActivities(ctx, fleet.ListActivitiesOptions{
+		Streamed: ptr.Bool(false),
+	})
+	require.NoError(t, err)
+	require.Len(t, notStreamed, 3)
+}
+
+func testActivityWebhookDeliveries(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, ds.NewActivity(ctx, nil, dummyActivity{
+			name:    fmt.Sprintf("test-%d", i),
+			details: map[string]interface{}{},
+		}))
+	}
+	acts, _, err := ds.ListActivities(ctx, fleet.ListActivitiesOptions{ListOptions: fleet.ListOptions{OrderKey: "id"}})
+	require.NoError(t, err)
+	require.Len(t, acts, 2)
+
+	_, err = ds.ActivityWebhookDelivery(ctx, 999)
+	var nfe fleet.NotFoundError
+	require.ErrorAs(t, err, &nfe)
+
+	d1, err := ds.NewActivityWebhookDelivery(ctx, &fleet.ActivityWebhookDelivery{
+		ActivityID:     acts[0].ID,
+		ActivityType:   acts[0].Type,
+		DestinationURL: "https://example.com/a",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, d1.ID)
+	require.Equal(t, fleet.ActivityWebhookDeliveryPending, d1.Status)
+	require.Zero(t, d1.Attempts)
+	require.Nil(t, d1.ResponseStatus)
+	require.Nil(t, d1.Error)
+
+	d2, err := ds.NewActivityWebhookDelivery(ctx, &fleet.ActivityWebhookDelivery{
+		ActivityID:     acts[1].ID,
+		ActivityType:   acts[1].Type,
+		DestinationURL: "https://example.com/b",
+	})
+	require.NoError(t, err)
+
+	d1.Status = fleet.ActivityWebhookDeliverySuccess
+	d1.Attempts = 2
+	d1.ResponseStatus = ptr.Int(204)
+	require.NoError(t, ds.UpdateActivityWebhookDelivery(ctx, d1))
+	// updating with the same values is not an error
+	require.NoError(t, ds.UpdateActivityWebhookDelivery(ctx, d1))
+	// updating a non-existing delivery is an error
+	require.Error(t, ds.UpdateActivityWebhookDelivery(ctx, &fleet.ActivityWebhookDelivery{ID: 999, Status: fleet.ActivityWebhookDeliveryFailed}))
+
+	got, err := ds.ActivityWebhookDelivery(ctx, d1.ID)
+	require.NoError(t, err)
+	require.Equal(t, fleet.ActivityWebhookDeliverySuccess, got.Status)
+	require.Equal(t, 2, got.Attempts)
+	require.Equal(t, 204, *got.ResponseStatus)
+
+	// the delivery and the job that made the attempt are updated together
+	job, err := ds.NewJob(ctx, &fleet.Job{Name: "activity_webhook", State: fleet.JobStateQueued})
+	require.NoError(t, err)
+	job.Retries = 5
+	job.Error = "boom"
+	d2.Attempts = 5
+	d2.Error = ptr.String("boom")
+	require.NoError(t, ds.UpdateActivityWebhookDeliveryAndJob(ctx, d2, job))
+	jobs, err := ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, 5, jobs[0].Retries)
+	// the job is not updated if the delivery does not exist
+	job.Retries = 6
+	require.Error(t, ds.UpdateActivityWebhookDeliveryAndJob(ctx, &fleet.ActivityWebhookDelivery{ID: 999}, job))
+	jobs, err = ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 5, jobs[0].Retries)
+
+	d2.Status = fleet.ActivityWebhookDeliveryFailed
+	d2.Attempts = 6
+	require.NoError(t, ds.UpdateActivityWebhookDelivery(ctx, d2))
+
+	cases := []struct {
+		desc string
+		opts fleet.ListActivityWebhookDeliveriesOptions
+		want []uint
+	}{
+		{"all", fleet.ListActivityWebhookDeliveriesOptions{}, []uint{d2.ID, d1.ID}},
+		{"by status", fleet.ListActivityWebhookDeliveriesOptions{Status: fleet.ActivityWebhookDeliveryFailed}, []uint{d2.ID}},
+		{"by type", fleet.ListActivityWebhookDeliveriesOptions{ActivityType: acts[0].Type}, []uint{d1.ID}},
+		{"by activity", fleet.ListActivityWebhookDeliveriesOptions{ActivityID: &acts[1].ID}, []uint{d2.ID}},
+		{"no match", fleet.ListActivityWebhookDeliveriesOptions{Status: fleet.ActivityWebhookDeliveryPending}, []uint{}},
+		{"ascending", fleet.ListActivityWebhookDeliveriesOptions{ListOptions: fleet.ListOptions{OrderKey: "id"}}, []uint{d1.ID, d2.ID}},
+		{"paginated", fleet.ListActivityWebhookDeliveriesOptions{ListOptions: fleet.ListOptions{PerPage: 1}}, []uint{d2.ID}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			list, _, err := ds.ListActivityWebhookDeliveries(ctx, c.opts)
+			require.NoError(t, err)
+			ids := make([]uint, 0, len(list))
+			for _, d := range list {
+				ids = append(ids, d.ID)
+			}
+			require.Equal(t, c.want, ids)
+		})
+	}
+
+	_, meta, err := ds.ListActivityWebhookDeliveries(ctx, fleet.ListActivityWebhookDeliveriesOptions{ListOptions: fleet.ListOptions{PerPage: 1}})
+	require.NoError(t, err)
+	require.Equal(t, &fleet.PaginationMetadata{HasNextResults: true}, meta)
+}
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
@@ -143,3 +144,119 @@ func (ds *Datastore) CleanupDistributedQueryCampaigns(ctx context.Context, now t
 
 	return uint(exp), nil
 }
+
+func (ds *Datastore) SaveDistributedQueryCampaignResult(ctx context.Context, result *fleet.DistributedQueryResult) error {
+	var hostID uint
+	var hostname, displayName string
+	if result.Host != nil && result.Host.Host != nil {
+		hostID = result.Host.ID
+		hostname = result.Host.Hostname
+		displayName = result.Host.DisplayName
+	}
+
+	var data []byte
+	if result.Rows != nil {
+		var err error
+		if data, err = json.Marshal(result.Rows); err != nil {
+			return ctxerr.Wrap(ctx, err, "marshal campaign result rows")
+		}
+	}
+
+	const stmt = `
+		INSERT INTO distributed_query_campaign_results (
+			distributed_query_campaign_id,
+			host_id,
+			hostname,
+			display_name,
+			data,
+			error
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			hostname = VALUES(hostname),
+			display_name = VALUES(display_name),
+			data = VALUES(data),
+			error = VALUES(error)
+	`
+	if _, err := ds.writer.ExecContext(ctx, stmt,
+		result.DistributedQueryCampaignID, hostID, hostname, displayName, data, result.Error,
+	); err != nil {
+		return ctxerr.Wrap(ctx, err, "insert distributed query campaign result")
+	}
+	return nil
+}
+
+func (ds *Datastore) ListDistributedQueryCampaignResults(ctx context.Context, campaignID uint, opt fleet.ListOptions) ([]*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error) {
+	query := `
+		SELECT
+			id,
+			host_id,
+			hostname,
+			display_name,
+			data,
+			error
+		FROM distributed_query_campaign_results
+		WHERE distributed_query_campaign_id = ?`
+
+	// the results are always listed in the order they were received
+	opt.OrderKey = "id"
+	opt.OrderDirection = fleet.OrderAscending
+	opt.After = ""
+	opt.IncludeMetadata = true
+	query = appendListOptionsToSQL(query, &opt)
+
+	var rows []struct {
+		ID          uint    `db:"id"`
+		HostID      uint    `db:"host_id"`
+		Hostname    string  `db:"hostname"`
+		DisplayName string  `db:"display_name"`
+		Data        []byte  `db:"data"`
+		Error       *string `db:"error"`
+	}
+	if err := sqlx.SelectContext(ctx, ds.reader, &rows, query, campaignID); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "select distributed query campaign results")
+	}
+
+	meta := &fleet.PaginationMetadata{HasPreviousResults: opt.Page > 0}
+	if len(rows) > int(opt.PerPage) {
+		meta.HasNextResults = true
+		rows = rows[:len(rows)-1]
+	}
+
+	results := make([]*fleet.DistributedQueryResult, 0, len(rows))
+	for _, row := range rows {
+		res := &fleet.DistributedQueryResult{
+			DistributedQueryCampaignID: campaignID,
+			Host: &fleet.HostResponse{
+				Host: &fleet.Host{
+					ID:       row.HostID,
+					Hostname: row.Hostname,
+				},
+				DisplayText: row.Hostname,
+				DisplayName: row.DisplayName,
+			},
+			Rows:  []map[string]string{},
+			Error: row.Error,
+		}
+		if len(row.Data) > 0 {
+			if err := json.Unmarshal(row.Data, &res.Rows); err != nil {
+				return nil, nil, ctxerr.Wrap(ctx, err, "unmarshal campaign result rows")
+			}
+		}
+		results = append(results, res)
+	}
+	return results, meta, nil
+}
+
+func (ds *Datastore) CleanupDistributedQueryCampaignResults(ctx context.Context, before time.Time) error {
+	const stmt = `
+		DELETE dqcr
+		FROM distributed_query_campaign_results dqcr
+		JOIN distributed_query_campaigns dqc ON dqc.id = dqcr.distributed_query_campaign_id
+		WHERE dqc.created_at < ?
+	`
+	if _, err := ds.writer.ExecContext(ctx, stmt, before); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete old distributed query campaign results")
+	}
+	return nil
+}
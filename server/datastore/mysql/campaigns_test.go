@@ -22,6 +22,7 @@ func TestCampaigns(t *testing.T) {
 		{"DistributedQuery", testCampaignsDistributedQuery},
 		{"CleanupDistributedQuery", testCampaignsCleanupDistributedQuery},
 		{"SaveDistributedQuery", testCampaignsSaveDistributedQuery},
+		{"DistributedQueryResults", testCampaignsDistributedQueryResults},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -173,6 +174,82 @@ func testCampaignsSaveDistributedQuery(t *testing.T, ds *Datastore) {
 	require.Equal(t, fleet.QueryComplete, gotC.Status)
 }
 
+func testCampaignsDistributedQueryResults(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Zach", "zwass@fleet.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	c1 := test.NewCampaign(t, ds, query.ID, fleet.QueryRunning, time.Now())
+	c2 := test.NewCampaign(t, ds, query.ID, fleet.QueryRunning, time.Now())
+
+	h1 := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "bar.local", "192.168.1.11", "2", "2", time.Now())
+
+	// no results yet
+	results, meta, err := ds.ListDistributedQueryCampaignResults(ctx, c1.ID, fleet.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, results)
+	require.Equal(t, &fleet.PaginationMetadata{}, meta)
+
+	errMsg := "failed"
+	for _, res := range []fleet.DistributedQueryResult{
+		{DistributedQueryCampaignID: c1.ID, Host: fleet.HostResponseForHostCheap(h2), Rows: []map[string]string{{"a": "1"}, {"a": "2"}}},
+		{DistributedQueryCampaignID: c1.ID, Host: fleet.HostResponseForHostCheap(h1), Error: &errMsg},
+		{DistributedQueryCampaignID: c2.ID, Host: fleet.HostResponseForHostCheap(h1), Rows: []map[string]string{}},
+	} {
+		res := res
+		require.NoError(t, ds.SaveDistributedQueryCampaignResult(ctx, &res))
+	}
+
+	results, meta, err = ds.ListDistributedQueryCampaignResults(ctx, c1.ID, fleet.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &fleet.PaginationMetadata{}, meta)
+	require.Len(t, results, 2)
+	assert.Equal(t, h2.ID, results[0].Host.ID)
+	assert.Equal(t, "bar.local", results[0].Host.Hostname)
+	assert.Equal(t, h2.DisplayName(), results[0].Host.DisplayName)
+	assert.Equal(t, []map[string]string{{"a": "1"}, {"a": "2"}}, results[0].Rows)
+	assert.Nil(t, results[0].Error)
+	assert.Equal(t, h1.ID, results[1].Host.ID)
+	assert.Empty(t, results[1].Rows)
+	require.NotNil(t, results[1].Error)
+	assert.Equal(t, "failed", *results[1].Error)
+
+	// a host sending its result again replaces the previous one
+	require.NoError(t, ds.SaveDistributedQueryCampaignResult(ctx, &fleet.DistributedQueryResult{
+		DistributedQueryCampaignID: c1.ID,
+		Host:                       fleet.HostResponseForHostCheap(h1),
+		Rows:                       []map[string]string{{"a": "3"}},
+	}))
+
+	// paging
+	results, meta, err = ds.ListDistributedQueryCampaignResults(ctx, c1.ID, fleet.ListOptions{PerPage: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, h2.ID, results[0].Host.ID)
+	assert.Equal(t, &fleet.PaginationMetadata{HasNextResults: true}, meta)
+
+	results, meta, err = ds.ListDistributedQueryCampaignResults(ctx, c1.ID, fleet.ListOptions{PerPage: 1, Page: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, h1.ID, results[0].Host.ID)
+	assert.Equal(t, []map[string]string{{"a": "3"}}, results[0].Rows)
+	assert.Nil(t, results[0].Error)
+	assert.Equal(t, &fleet.PaginationMetadata{HasPreviousResults: true}, meta)
+
+	// cleanup deletes the results of the campaigns created before the time
+	_, err = ds.writer.Exec(`UPDATE distributed_query_campaigns SET created_at = ? WHERE id = ?`, time.Now().Add(-48*time.Hour), c1.ID)
+	require.NoError(t, err)
+	require.NoError(t, ds.CleanupDistributedQueryCampaignResults(ctx, time.Now().Add(-24*time.Hour)))
+
+	results, _, err = ds.ListDistributedQueryCampaignResults(ctx, c1.ID, fleet.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, results)
+	results, _, err = ds.ListDistributedQueryCampaignResults(ctx, c2.ID, fleet.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+}
+
 func checkTargets(t *testing.T, ds fleet.Datastore, campaignID uint, expectedTargets fleet.HostTargets) {
 	targets, err := ds.DistributedQueryCampaignTargetIDs(context.Background(), campaignID)
 	require.Nil(t, err)
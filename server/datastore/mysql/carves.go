@@ -66,7 +66,7 @@ func (ds *Datastore) NewCarve(ctx context.Context, metadata *fleet.CarveMetadata
 }
 
 // UpdateCarve updates the carve metadata in database
-// Only max_block and expired are updatable
+// Only max_block, expired, error and the sha256 fields are updatable
 func (ds *Datastore) UpdateCarve(ctx context.Context, metadata *fleet.CarveMetadata) error {
 	return updateCarveDB(ctx, ds.writer, metadata)
 }
@@ -76,7 +76,9 @@ func updateCarveDB(ctx context.Context, exec sqlx.ExecerContext, metadata *fleet
 		UPDATE carve_metadata SET
 			max_block = ?,
 			expired = ?,
-			error = ?
+			error = ?,
+			sha256 = ?,
+			sha256_state = ?
 		WHERE id = ?
 	`
 	_, err := exec.ExecContext(
@@ -85,6 +87,8 @@ func updateCarveDB(ctx context.Context, exec sqlx.ExecerContext, metadata *fleet
 		metadata.MaxBlock,
 		metadata.Expired,
 		metadata.Error,
+		metadata.SHA256,
+		metadata.SHA256State,
 		metadata.ID,
 	)
 	return ctxerr.Wrap(ctx, err, "update carve metadata")
@@ -165,7 +169,9 @@ const carveSelectFields = `
 			session_id,
 			expired,
 			max_block,
-			error
+			error,
+			sha256,
+			sha256_state
 `
 
 func (ds *Datastore) Carve(ctx context.Context, carveId int64) (*fleet.CarveMetadata, error) {
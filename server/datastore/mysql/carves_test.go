@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
 	"github.com/fleetdm/fleet/v4/server/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -272,4 +273,20 @@ func testCarvesUpdate(t *testing.T, ds *Datastore) {
 	dbCarve, err := ds.Carve(context.Background(), carve.ID)
 	require.NoError(t, err)
 	assert.Equal(t, carve, dbCarve)
+
+	// the hash state and final hash are updatable
+	carve.SHA256State = []byte{1, 2, 3}
+	err = ds.UpdateCarve(context.Background(), carve)
+	require.NoError(t, err)
+	dbCarve, err = ds.Carve(context.Background(), carve.ID)
+	require.NoError(t, err)
+	assert.Equal(t, carve, dbCarve)
+
+	carve.SHA256State = nil
+	carve.SHA256 = ptr.String("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	err = ds.UpdateCarve(context.Background(), carve)
+	require.NoError(t, err)
+	dbCarve, err = ds.Carve(context.Background(), carve.ID)
+	require.NoError(t, err)
+	assert.Equal(t, carve, dbCarve)
 }
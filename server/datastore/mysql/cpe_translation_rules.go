@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+func (ds *Datastore) ApplyCPETranslationRuleSpecs(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		const stmt = `
+		INSERT INTO cpe_translation_rules (
+			name,
+			description,
+			software,
+			filter
+		) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			description = VALUES(description),
+			software = VALUES(software),
+			filter = VALUES(filter)
+	`
+		for _, s := range specs {
+			if s.Name == "" {
+				return ctxerr.New(ctx, "cpe translation rule name must not be empty")
+			}
+			if _, err := tx.ExecContext(ctx, stmt, s.Name, s.Description, s.Software, s.Filter); err != nil {
+				return ctxerr.Wrap(ctx, err, "exec ApplyCPETranslationRuleSpecs insert")
+			}
+		}
+		return nil
+	})
+}
+
+func (ds *Datastore) GetCPETranslationRuleSpecs(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) {
+	var specs []*fleet.CPETranslationRuleSpec
+	if err := sqlx.SelectContext(ctx, ds.reader, &specs, `
+		SELECT name, description, software, filter FROM cpe_translation_rules ORDER BY id`,
+	); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get cpe translation rules")
+	}
+	return specs, nil
+}
+
+func (ds *Datastore) DeleteCPETranslationRule(ctx context.Context, name string) error {
+	res, err := ds.writer.ExecContext(ctx, `DELETE FROM cpe_translation_rules WHERE name = ?`, name)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete cpe translation rule")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("CPETranslationRule").WithName(name))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPETranslationRules(t *testing.T) {
+	ds := CreateMySQLDS(t)
+	ctx := context.Background()
+
+	specs, err := ds.GetCPETranslationRuleSpecs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, specs)
+
+	internal := &fleet.CPETranslationRuleSpec{
+		Name:     "internal",
+		Software: fleet.CPETranslationRuleSoftware{Name: []string{"/^Acme Internal/"}, Source: []string{"apps"}},
+		Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+	}
+	zoom := &fleet.CPETranslationRuleSpec{
+		Name:        "zoom",
+		Description: "Zoom client",
+		Software:    fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"us.zoom.xos"}},
+		Filter:      fleet.CPETranslationRuleFilter{Product: []string{"zoom"}, Vendor: []string{"zoom"}},
+	}
+	require.NoError(t, ds.ApplyCPETranslationRuleSpecs(ctx, []*fleet.CPETranslationRuleSpec{internal, zoom}))
+
+	specs, err = ds.GetCPETranslationRuleSpecs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{internal, zoom}, specs)
+
+	// updating a rule keeps its position
+	internal.Description = "Acme internal apps"
+	internal.Filter = fleet.CPETranslationRuleFilter{Product: []string{"internal"}, Vendor: []string{"acme"}, TargetSW: []string{"macos"}}
+	require.NoError(t, ds.ApplyCPETranslationRuleSpecs(ctx, []*fleet.CPETranslationRuleSpec{internal}))
+	specs, err = ds.GetCPETranslationRuleSpecs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{internal, zoom}, specs)
+
+	require.Error(t, ds.ApplyCPETranslationRuleSpecs(ctx, []*fleet.CPETranslationRuleSpec{{}}))
+
+	require.NoError(t, ds.DeleteCPETranslationRule(ctx, "internal"))
+	specs, err = ds.GetCPETranslationRuleSpecs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{zoom}, specs)
+	require.True(t, fleet.IsNotFound(ds.DeleteCPETranslationRule(ctx, "internal")))
+}
@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+func (ds *Datastore) ListOfflineHosts(ctx context.Context, teamID uint, seenBefore time.Time) ([]*fleet.OfflineHost, error) {
+	stmt := `
+    SELECT
+      h.id,
+      COALESCE(NULLIF(h.computer_name, ''), h.hostname) AS display_name,
+      COALESCE(hst.seen_time, h.created_at) AS seen_time,
+      COALESCE(hls.state, '') AS lifecycle_state
+    FROM hosts h
+    LEFT JOIN host_seen_times hst ON h.id = hst.host_id
+    LEFT JOIN host_lifecycle_states hls ON h.id = hls.host_id
+    WHERE h.team_id = ? AND COALESCE(hst.seen_time, h.created_at) <= ?
+    ORDER BY h.id`
+
+	var hosts []*fleet.OfflineHost
+	if err := sqlx.SelectContext(ctx, ds.reader, &hosts, stmt, teamID, seenBefore); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list offline hosts")
+	}
+	return hosts, nil
+}
+
+func (ds *Datastore) SetHostsLifecycleState(ctx context.Context, hostIDs []uint, state fleet.HostLifecycleState) error {
+	if len(hostIDs) == 0 {
+		return nil
+	}
+
+	stmt := `INSERT INTO host_lifecycle_states (host_id, state) VALUES %s ON DUPLICATE KEY UPDATE state = VALUES(state)`
+	values := make([]string, 0, len(hostIDs))
+	args := make([]interface{}, 0, 2*len(hostIDs))
+	for _, id := range hostIDs {
+		values = append(values, "(?, ?)")
+		args = append(args, id, state)
+	}
+	if _, err := ds.writer.ExecContext(ctx, fmt.Sprintf(stmt, strings.Join(values, ",")), args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "set hosts lifecycle state")
+	}
+	return nil
+}
+
+func (ds *Datastore) CleanupHostLifecycleStates(ctx context.Context) error {
+	// a host that checked in after it was given its lifecycle state is no
+	// longer offline, so the state does not apply anymore.
+	stmt := `
+    DELETE hls FROM host_lifecycle_states hls
+    JOIN host_seen_times hst ON hst.host_id = hls.host_id
+    WHERE hst.seen_time > hls.updated_at`
+	if _, err := ds.writer.ExecContext(ctx, stmt); err != nil {
+		return ctxerr.Wrap(ctx, err, "cleanup host lifecycle states")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLifecycle(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"ListOfflineHosts", testHostLifecycleListOfflineHosts},
+		{"States", testHostLifecycleStates},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testHostLifecycleListOfflineHosts(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	team, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+	online := test.NewHost(t, ds, "online", "", "onlinekey", "onlineuuid", now)
+	offline := test.NewHost(t, ds, "offline", "", "offlinekey", "offlineuuid", now.Add(-20*24*time.Hour))
+	noTeam := test.NewHost(t, ds, "noteam", "", "noteamkey", "noteamuuid", now.Add(-20*24*time.Hour))
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{online.ID, offline.ID}))
+
+	hosts, err := ds.ListOfflineHosts(ctx, team.ID, now.Add(-14*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, offline.ID, hosts[0].ID)
+	require.Equal(t, "offline", hosts[0].DisplayName)
+	require.Equal(t, now.Add(-20*24*time.Hour), hosts[0].SeenTime)
+	require.Empty(t, hosts[0].LifecycleState)
+
+	require.NoError(t, ds.SetHostsLifecycleState(ctx, []uint{offline.ID}, fleet.HostLifecycleStateStale))
+	hosts, err = ds.ListOfflineHosts(ctx, team.ID, now.Add(-14*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, fleet.HostLifecycleStateStale, hosts[0].LifecycleState)
+
+	// the host without a team is not listed for the team
+	hosts, err = ds.ListOfflineHosts(ctx, team.ID, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	for _, h := range hosts {
+		require.NotEqual(t, noTeam.ID, h.ID)
+	}
+}
+
+func testHostLifecycleStates(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	h1 := test.NewHost(t, ds, "h1", "", "h1key", "h1uuid", now.Add(-20*24*time.Hour))
+	h2 := test.NewHost(t, ds, "h2", "", "h2key", "h2uuid", now.Add(-20*24*time.Hour))
+	require.NoError(t, ds.SetHostsLifecycleState(ctx, []uint{h1.ID, h2.ID}, fleet.HostLifecycleStateStale))
+	// setting it again is a no-op
+	require.NoError(t, ds.SetHostsLifecycleState(ctx, []uint{h1.ID}, fleet.HostLifecycleStateStale))
+
+	host, err := ds.Host(ctx, h1.ID)
+	require.NoError(t, err)
+	require.Equal(t, fleet.HostLifecycleStateStale, host.LifecycleState)
+
+	hosts := listHostsCheckCount(t, ds, fleet.TeamFilter{User: test.UserAdmin}, fleet.HostListOptions{StatusFilter: fleet.StatusStale}, 2)
+	require.Equal(t, fleet.HostLifecycleStateStale, hosts[0].LifecycleState)
+
+	// h1 checks in again, its state is cleared
+	require.NoError(t, ds.MarkHostsSeen(ctx, []uint{h1.ID}, now.Add(time.Hour)))
+	require.NoError(t, ds.CleanupHostLifecycleStates(ctx))
+
+	host, err = ds.Host(ctx, h1.ID)
+	require.NoError(t, err)
+	require.Empty(t, host.LifecycleState)
+	hosts = listHostsCheckCount(t, ds, fleet.TeamFilter{User: test.UserAdmin}, fleet.HostListOptions{StatusFilter: fleet.StatusStale}, 1)
+	require.Equal(t, h2.ID, hosts[0].ID)
+}
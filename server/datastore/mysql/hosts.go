@@ -306,6 +306,10 @@ func loadHostUsersDB(ctx context.Context, db sqlx.QueryerContext, hostID uint) (
 	return users, nil
 }
 
+// hostLifecycleStateSelect selects the lifecycle state of the host aliased as
+// h.
+const hostLifecycleStateSelect = `COALESCE((SELECT hls.state FROM host_lifecycle_states hls WHERE hls.host_id = h.id), '') AS lifecycle_state`
+
 // hostRefs are the tables referenced by hosts.
 //
 // Defined here for testing purposes.
@@ -331,6 +335,11 @@ var hostRefs = []string{
 	"operating_system_vulnerabilities",
 	"host_updates",
 	"host_disk_encryption_keys",
+	"query_results",
+	"host_lifecycle_states",
+	"update_rollout_hosts",
+	"vulnerability_exceptions",
+	"host_vulnerability_remediations",
 }
 
 func (ds *Datastore) DeleteHost(ctx context.Context, hid uint) error {
@@ -411,6 +420,7 @@ SELECT
   COALESCE(hst.seen_time, h.created_at) AS seen_time,
   t.name AS team_name,
   COALESCE(hu.software_updated_at, h.created_at) AS software_updated_at,
+  ` + hostLifecycleStateSelect + `,
   (
     SELECT
       additional
@@ -605,7 +615,8 @@ func (ds *Datastore) ListHosts(ctx context.Context, filter fleet.TeamFilter, opt
     COALESCE(hd.percent_disk_space_available, 0) as percent_disk_space_available,
     COALESCE(hst.seen_time, h.created_at) AS seen_time,
     t.name AS team_name,
-    COALESCE(hu.software_updated_at, h.created_at) AS software_updated_at
+    COALESCE(hu.software_updated_at, h.created_at) AS software_updated_at,
+    ` + hostLifecycleStateSelect + `
 	`
 
 	sql += hostMDMSelect
@@ -828,6 +839,9 @@ func filterHostsByStatus(now time.Time, sql string, opt fleet.HostListOptions, p
 	case fleet.StatusMIA, fleet.StatusMissing:
 		sql += "AND DATE_ADD(COALESCE(hst.seen_time, h.created_at), INTERVAL 30 DAY) <= ?"
 		params = append(params, now)
+	case fleet.StatusStale:
+		sql += "AND EXISTS (SELECT 1 FROM host_lifecycle_states hls WHERE hls.host_id = h.id AND hls.state = ?)"
+		params = append(params, fleet.HostLifecycleStateStale)
 	}
 	return sql, params
 }
@@ -1361,10 +1375,13 @@ func (ds *Datastore) LoadHostByNodeKey(ctx context.Context, nodeKey string) (*fl
       h.policy_updated_at,
       h.public_ip,
       h.orbit_node_key,
+      t.name AS team_name,
       COALESCE(hd.gigs_disk_space_available, 0) as gigs_disk_space_available,
       COALESCE(hd.percent_disk_space_available, 0) as percent_disk_space_available
     FROM
       hosts h
+    LEFT OUTER JOIN
+      teams t ON t.id = h.team_id
     LEFT OUTER JOIN
       host_disks hd ON hd.host_id = h.id
     WHERE node_key = ?`
@@ -1951,13 +1968,34 @@ func (ds *Datastore) ListPoliciesForHost(ctx context.Context, host *fleet.Host)
 	FROM policies p
 	LEFT JOIN policy_membership pm ON (p.id=pm.policy_id AND host_id=?)
 	LEFT JOIN users u ON p.author_id = u.id
-	WHERE (p.team_id IS NULL OR p.team_id = (select team_id from hosts WHERE id = ?))
+	WHERE (p.team_id IS NULL OR p.team_id IN (?))
 	AND (p.platforms IS NULL OR p.platforms = '' OR FIND_IN_SET(?, p.platforms) != 0)`
 
+	// policies of the ancestors of the host's team apply to the host too
+	teamIDs, err := hostTeamLineageDB(ctx, ds.reader, host.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(teamIDs) == 0 {
+		// make sure the IN clause is never empty
+		teamIDs = []uint{0}
+	}
+	query, args, err := sqlx.In(query, host.ID, teamIDs, host.FleetPlatform())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "build host policies query")
+	}
+
 	var policies []*fleet.HostPolicy
-	if err := sqlx.SelectContext(ctx, ds.reader, &policies, query, host.ID, host.ID, host.FleetPlatform()); err != nil {
+	if err := sqlx.SelectContext(ctx, ds.reader, &policies, query, args...); err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "get host policies")
 	}
+	data := make([]*fleet.PolicyData, 0, len(policies))
+	for _, p := range policies {
+		data = append(data, &p.PolicyData)
+	}
+	if err := loadPoliciesMetadataDB(ctx, ds.reader, data); err != nil {
+		return nil, err
+	}
 	return policies, nil
 }
 
@@ -2577,6 +2615,42 @@ func (ds *Datastore) SetOrUpdateHostOrbitInfo(ctx context.Context, hostID uint,
 	)
 }
 
+func (ds *Datastore) SetOrUpdateHostOrbitChannels(ctx context.Context, hostID uint, channels fleet.UpdateChannels) error {
+	// the version is set when the orbit_info version is ingested, which may
+	// happen after the channels on the first check-in.
+	stmt := `
+    INSERT INTO host_orbit_info (host_id, version, orbit_channel, osqueryd_channel, desktop_channel)
+    VALUES (?, '', ?, ?, ?)
+    ON DUPLICATE KEY UPDATE
+      orbit_channel = VALUES(orbit_channel),
+      osqueryd_channel = VALUES(osqueryd_channel),
+      desktop_channel = VALUES(desktop_channel)`
+	if _, err := ds.writer.ExecContext(ctx, stmt, hostID, channels.Orbit, channels.Osqueryd, channels.Desktop); err != nil {
+		return ctxerr.Wrap(ctx, err, "set host orbit channels")
+	}
+	return nil
+}
+
+func (ds *Datastore) HostOrbitChannels(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+	var row struct {
+		Orbit    string `db:"orbit_channel"`
+		Osqueryd string `db:"osqueryd_channel"`
+		Desktop  string `db:"desktop_channel"`
+	}
+	stmt := `SELECT orbit_channel, osqueryd_channel, desktop_channel FROM host_orbit_info WHERE host_id = ?`
+	if err := sqlx.GetContext(ctx, ds.reader, &row, stmt, hostID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get host orbit channels")
+	}
+	channels := fleet.UpdateChannels(row)
+	if channels.IsEmpty() {
+		return nil, nil
+	}
+	return &channels, nil
+}
+
 func (ds *Datastore) getOrInsertMDMSolution(ctx context.Context, serverURL string, mdmName string) (mdmID uint, err error) {
 	readStmt := &parameterizedStmt{
 		Statement: `SELECT id FROM mobile_device_management_solutions WHERE name = ? AND server_url = ?`,
@@ -3586,6 +3660,7 @@ func amountHostsByOrbitVersionDB(ctx context.Context, db sqlx.QueryerContext) ([
 	const stmt = `
 		SELECT version as orbit_version, count(*) as num_hosts
 		FROM host_orbit_info
+		WHERE version != ''
 		GROUP BY version
   	`
 	if err := sqlx.SelectContext(ctx, db, &counts, stmt); err != nil {
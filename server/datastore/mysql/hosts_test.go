@@ -144,6 +144,7 @@ func TestHosts(t *testing.T) {
 		{"EnrollOrbit", testHostsEnrollOrbit},
 		{"EnrollUpdatesMissingInfo", testHostsEnrollUpdatesMissingInfo},
 		{"EncryptionKeyRawDecryption", testHostsEncryptionKeyRawDecryption},
+		{"SetOrUpdateHostOrbitChannels", testHostsSetOrUpdateHostOrbitChannels},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -1311,7 +1312,18 @@ func testHostsLoadHostByNodeKey(t *testing.T, ds *Datastore) {
 		assert.Equal(t, h, returned)
 	}
 
-	_, err := ds.LoadHostByNodeKey(context.Background(), "7B1A9DC9-B042-489F-8D5A-EEC2412C95AA")
+	// the name of the team of the host is loaded
+	h, err := ds.EnrollHost(context.Background(), false, "team-host", "", "", "team-host-key", nil, 0)
+	require.NoError(t, err)
+	team, err := ds.NewTeam(context.Background(), &fleet.Team{Name: "Servers/EU"})
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(context.Background(), &team.ID, []uint{h.ID}))
+	returned, err := ds.LoadHostByNodeKey(context.Background(), "team-host-key")
+	require.NoError(t, err)
+	require.NotNil(t, returned.TeamName)
+	assert.Equal(t, "Servers/EU", *returned.TeamName)
+
+	_, err = ds.LoadHostByNodeKey(context.Background(), "7B1A9DC9-B042-489F-8D5A-EEC2412C95AA")
 	assert.Error(t, err)
 
 	_, err = ds.LoadHostByNodeKey(context.Background(), "")
@@ -5298,6 +5310,42 @@ func testHostsDeleteHosts(t *testing.T, ds *Datastore) {
 	)
 	require.NoError(t, err)
 
+	// Stored query results
+	reportQuery, err := ds.NewQuery(context.Background(), &fleet.Query{Name: "report", Query: "SELECT 1", StoreResults: true})
+	require.NoError(t, err)
+	err = ds.OverwriteQueryResultRows(context.Background(), reportQuery.ID, host.ID, []json.RawMessage{json.RawMessage(`{"a":"1"}`)}, time.Now(), 10)
+	require.NoError(t, err)
+
+	// Lifecycle state
+	err = ds.SetHostsLifecycleState(context.Background(), []uint{host.ID}, fleet.HostLifecycleStateStale)
+	require.NoError(t, err)
+
+	// Update rollout
+	rollout, err := ds.NewUpdateRollout(context.Background(), &fleet.UpdateRollout{
+		Name:           "rollout",
+		Target:         "orbit",
+		Channel:        "edge",
+		Percentage:     100,
+		OfflineMinutes: fleet.DefaultUpdateRolloutOfflineMinutes,
+		Status:         fleet.UpdateRolloutStatusActive,
+	})
+	require.NoError(t, err)
+	err = ds.AddHostUpdateRollouts(context.Background(), host.ID, []uint{rollout.ID}, time.Now())
+	require.NoError(t, err)
+
+	// Vulnerability exception
+	_, err = ds.NewVulnerabilityException(context.Background(), &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0001",
+		HostID:        &host.ID,
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "mitigated",
+	})
+	require.NoError(t, err)
+
+	// Vulnerability remediation, from the operating system vulnerabilities
+	err = ds.SyncHostVulnerabilityRemediations(context.Background(), time.Now())
+	require.NoError(t, err)
+
 	// Check there's an entry for the host in all the associated tables.
 	for _, hostRef := range hostRefs {
 		var ok bool
@@ -6399,3 +6447,33 @@ func testHostsEncryptionKeyRawDecryption(t *testing.T, ds *Datastore) {
 	require.NotNil(t, got.MDM.TestGetRawDecryptable())
 	require.Equal(t, 1, *got.MDM.TestGetRawDecryptable())
 }
+
+func testHostsSetOrUpdateHostOrbitChannels(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	host := test.NewHost(t, ds, "h1", "1", "1", "1", time.Now())
+
+	channels, err := ds.HostOrbitChannels(ctx, host.ID)
+	require.NoError(t, err)
+	require.Nil(t, channels)
+
+	// the channels may be ingested before the version
+	err = ds.SetOrUpdateHostOrbitChannels(ctx, host.ID, fleet.UpdateChannels{Orbit: "stable", Osqueryd: "5.8.1", Desktop: "edge"})
+	require.NoError(t, err)
+	channels, err = ds.HostOrbitChannels(ctx, host.ID)
+	require.NoError(t, err)
+	require.Equal(t, &fleet.UpdateChannels{Orbit: "stable", Osqueryd: "5.8.1", Desktop: "edge"}, channels)
+	versions, err := amountHostsByOrbitVersionDB(ctx, ds.reader)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	err = ds.SetOrUpdateHostOrbitInfo(ctx, host.ID, "1.5.0")
+	require.NoError(t, err)
+	err = ds.SetOrUpdateHostOrbitChannels(ctx, host.ID, fleet.UpdateChannels{Orbit: "stable", Osqueryd: "stable", Desktop: "stable"})
+	require.NoError(t, err)
+	channels, err = ds.HostOrbitChannels(ctx, host.ID)
+	require.NoError(t, err)
+	require.Equal(t, &fleet.UpdateChannels{Orbit: "stable", Osqueryd: "stable", Desktop: "stable"}, channels)
+	versions, err = amountHostsByOrbitVersionDB(ctx, ds.reader)
+	require.NoError(t, err)
+	require.Equal(t, []fleet.HostsCountByOrbitVersion{{OrbitVersion: "1.5.0", NumHosts: 1}}, versions)
+}
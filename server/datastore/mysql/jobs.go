@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"time"
 
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/jmoiron/sqlx"
@@ -14,11 +15,16 @@ INSERT INTO jobs (
     args,
     state,
     retries,
-    error
+    error,
+    not_before
 )
-VALUES (?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, COALESCE(?, NOW()))
 `
-	result, err := ds.writer.ExecContext(ctx, query, job.Name, job.Args, job.State, job.Retries, job.Error)
+	var notBefore *time.Time
+	if !job.NotBefore.IsZero() {
+		notBefore = &job.NotBefore
+	}
+	result, err := ds.writer.ExecContext(ctx, query, job.Name, job.Args, job.State, job.Retries, job.Error, notBefore)
 	if err != nil {
 		return nil, err
 	}
@@ -32,11 +38,12 @@ VALUES (?, ?, ?, ?, ?)
 func (ds *Datastore) GetQueuedJobs(ctx context.Context, maxNumJobs int) ([]*fleet.Job, error) {
 	query := `
 SELECT
-    id, created_at, updated_at, name, args, state, retries, error
+    id, created_at, updated_at, name, args, state, retries, error, not_before
 FROM
     jobs
 WHERE
-    state = ?
+    state = ? AND
+    not_before <= NOW()
 ORDER BY
     updated_at ASC
 LIMIT ?
@@ -52,19 +59,27 @@ LIMIT ?
 }
 
 func (ds *Datastore) UpdateJob(ctx context.Context, id uint, job *fleet.Job) (*fleet.Job, error) {
+	if err := updateJobDB(ctx, ds.writer, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func updateJobDB(ctx context.Context, tx sqlx.ExecerContext, job *fleet.Job) error {
+	// the retry delay is added to the database's time, so that the time at
+	// which the job becomes ready is compared with the same clock in
+	// GetQueuedJobs.
 	query := `
 UPDATE jobs
 SET
     state = ?,
     retries = ?,
-    error = ?
+    error = ?,
+    not_before = IF(? > 0, DATE_ADD(NOW(), INTERVAL ? SECOND), not_before)
 WHERE
     id = ?
 `
-	_, err := ds.writer.ExecContext(ctx, query, job.State, job.Retries, job.Error, job.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	return job, nil
+	delay := int64(job.RetryDelay.Seconds())
+	_, err := tx.ExecContext(ctx, query, job.State, job.Retries, job.Error, delay, delay, job.ID)
+	return err
 }
@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"QueueAndProcess", testJobsQueueAndProcess},
+		{"NotBefore", testJobsNotBefore},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testJobsQueueAndProcess(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	jobs, err := ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, jobs)
+
+	args := json.RawMessage(`{"foo":"bar"}`)
+	j, err := ds.NewJob(ctx, &fleet.Job{Name: "test", Args: &args, State: fleet.JobStateQueued})
+	require.NoError(t, err)
+	require.NotZero(t, j.ID)
+
+	jobs, err = ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, j.ID, jobs[0].ID)
+	require.JSONEq(t, string(args), string(*jobs[0].Args))
+	require.False(t, jobs[0].NotBefore.IsZero())
+
+	j.State = fleet.JobStateSuccess
+	_, err = ds.UpdateJob(ctx, j.ID, j)
+	require.NoError(t, err)
+
+	jobs, err = ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, jobs)
+}
+
+func testJobsNotBefore(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	// a job that must not run yet
+	j1, err := ds.NewJob(ctx, &fleet.Job{Name: "test1", State: fleet.JobStateQueued, NotBefore: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	// a job that can run now
+	j2, err := ds.NewJob(ctx, &fleet.Job{Name: "test2", State: fleet.JobStateQueued})
+	require.NoError(t, err)
+
+	jobs, err := ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, j2.ID, jobs[0].ID)
+
+	// delay j2, make j1 ready
+	j2.Retries = 1
+	j2.RetryDelay = time.Hour
+	_, err = ds.UpdateJob(ctx, j2.ID, j2)
+	require.NoError(t, err)
+	ExecAdhocSQL(t, ds, func(q sqlx.ExtContext) error {
+		_, err := q.ExecContext(ctx, `UPDATE jobs SET not_before = DATE_SUB(NOW(), INTERVAL 1 MINUTE) WHERE id = ?`, j1.ID)
+		return err
+	})
+
+	jobs, err = ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, j1.ID, jobs[0].ID)
+
+	// the retry delay is added to the database's time
+	var delay int
+	err = sqlx.GetContext(ctx, ds.reader, &delay, `SELECT TIMESTAMPDIFF(SECOND, NOW(), not_before) FROM jobs WHERE id = ?`, j2.ID)
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour.Seconds(), delay, 5)
+
+	// updating without a retry delay leaves not_before unchanged
+	j1.Retries = 2
+	_, err = ds.UpdateJob(ctx, j1.ID, j1)
+	require.NoError(t, err)
+	jobs, err = ds.GetQueuedJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, 2, jobs[0].Retries)
+}
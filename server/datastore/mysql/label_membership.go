@@ -0,0 +1,204 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+// hostIdentifierColumns are the columns of the hosts table that the
+// identifiers of the hosts of a manual label are matched against.
+var hostIdentifierColumns = []string{"hostname", "hardware_serial", "uuid"}
+
+type labelMembershipHostMatch struct {
+	fleet.LabelMembershipHost
+	Identifier string `db:"identifier"`
+}
+
+// matchHostIdentifiersDB returns the hosts that match the identifiers, and
+// the identifiers that matched a host.
+func matchHostIdentifiersDB(ctx context.Context, q sqlx.QueryerContext, identifiers []string) (map[uint]fleet.LabelMembershipHost, map[string]bool, error) {
+	hosts := make(map[uint]fleet.LabelMembershipHost)
+	matched := make(map[string]bool)
+	if len(identifiers) == 0 {
+		return hosts, matched, nil
+	}
+
+	// each column is queried separately so that the index of the column can be
+	// used, and the batches stay under the max number of parameters.
+	for _, col := range hostIdentifierColumns {
+		for _, batch := range batchHostnames(identifiers) {
+			stmt, args, err := sqlx.In(fmt.Sprintf(`
+    SELECT
+      id,
+      COALESCE(NULLIF(computer_name, ''), hostname) AS display_name,
+      %[1]s AS identifier
+    FROM hosts
+    WHERE %[1]s IN (?)`, col), batch)
+			if err != nil {
+				return nil, nil, ctxerr.Wrapf(ctx, err, "build match hosts by %s statement", col)
+			}
+			var rows []labelMembershipHostMatch
+			if err := sqlx.SelectContext(ctx, q, &rows, stmt, args...); err != nil {
+				return nil, nil, ctxerr.Wrapf(ctx, err, "match hosts by %s", col)
+			}
+			for _, r := range rows {
+				hosts[r.ID] = r.LabelMembershipHost
+				matched[r.Identifier] = true
+			}
+		}
+	}
+	return hosts, matched, nil
+}
+
+// insertLabelMembershipByIdentifiersDB adds the hosts that match the
+// identifiers to the label.
+func insertLabelMembershipByIdentifiersDB(ctx context.Context, tx sqlx.ExtContext, labelID uint, identifiers []string) error {
+	for _, col := range hostIdentifierColumns {
+		// Split identifiers into batches to avoid parameter limit in MySQL.
+		for _, batch := range batchHostnames(identifiers) {
+			// Use ignore because a host could match identifiers in different
+			// batches or columns and would result in duplicate key errors.
+			stmt, args, err := sqlx.In(fmt.Sprintf(`
+INSERT IGNORE INTO label_membership (label_id, host_id) (SELECT ?, id FROM hosts WHERE %s IN (?))
+`, col), labelID, batch)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "build membership IN statement")
+			}
+			if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+				return ctxerr.Wrap(ctx, err, "execute membership INSERT")
+			}
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) ApplyLabelMembership(ctx context.Context, labelID uint, identifiers []string, dryRun bool) (*fleet.LabelMembershipDiff, error) {
+	var diff *fleet.LabelMembershipDiff
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		var labelName string
+		if err := sqlx.GetContext(ctx, tx, &labelName, `SELECT name FROM labels WHERE id = ?`, labelID); err != nil {
+			if err == sql.ErrNoRows {
+				return ctxerr.Wrap(ctx, notFound("Label").WithID(labelID))
+			}
+			return ctxerr.Wrap(ctx, err, "get label name")
+		}
+
+		hosts, matched, err := matchHostIdentifiersDB(ctx, tx, identifiers)
+		if err != nil {
+			return err
+		}
+
+		var current []fleet.LabelMembershipHost
+		if err := sqlx.SelectContext(ctx, tx, &current, `
+    SELECT
+      h.id,
+      COALESCE(NULLIF(h.computer_name, ''), h.hostname) AS display_name
+    FROM label_membership lm
+    JOIN hosts h ON h.id = lm.host_id
+    WHERE lm.label_id = ?
+    ORDER BY h.id`, labelID); err != nil {
+			return ctxerr.Wrap(ctx, err, "list label members")
+		}
+
+		diff = &fleet.LabelMembershipDiff{
+			LabelID:   labelID,
+			LabelName: labelName,
+			DryRun:    dryRun,
+			Added:     []fleet.LabelMembershipHost{},
+			Removed:   []fleet.LabelMembershipHost{},
+			Unmatched: []string{},
+		}
+		members := make(map[uint]bool, len(current))
+		for _, h := range current {
+			members[h.ID] = true
+			if _, ok := hosts[h.ID]; !ok {
+				diff.Removed = append(diff.Removed, h)
+			}
+		}
+		for id, h := range hosts {
+			if !members[id] {
+				diff.Added = append(diff.Added, h)
+			}
+		}
+		sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+		for _, id := range identifiers {
+			if !matched[id] {
+				diff.Unmatched = append(diff.Unmatched, id)
+			}
+		}
+
+		if dryRun {
+			return nil
+		}
+		if len(diff.Removed) > 0 {
+			ids := make([]uint, 0, len(diff.Removed))
+			for _, h := range diff.Removed {
+				ids = append(ids, h.ID)
+			}
+			stmt, args, err := sqlx.In(`DELETE FROM label_membership WHERE label_id = ? AND host_id IN (?)`, labelID, ids)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "build delete label membership statement")
+			}
+			if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+				return ctxerr.Wrap(ctx, err, "delete label membership")
+			}
+		}
+		if len(diff.Added) > 0 {
+			values := make([]string, 0, len(diff.Added))
+			args := make([]interface{}, 0, 2*len(diff.Added))
+			for _, h := range diff.Added {
+				values = append(values, "(?, ?)")
+				args = append(args, labelID, h.ID)
+			}
+			stmt := `INSERT IGNORE INTO label_membership (label_id, host_id) VALUES ` + strings.Join(values, ",")
+			if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+				return ctxerr.Wrap(ctx, err, "insert label membership")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "apply label membership")
+	}
+	return diff, nil
+}
+
+func (ds *Datastore) ListLabelMembershipSyncs(ctx context.Context) ([]*fleet.LabelMembershipSync, error) {
+	stmt := `
+    SELECT
+      lms.label_id,
+      l.name AS label_name,
+      lms.url,
+      lms.synced_at,
+      lms.sync_error
+    FROM label_membership_syncs lms
+    JOIN labels l ON l.id = lms.label_id
+    WHERE l.label_membership_type = ?
+    ORDER BY lms.label_id`
+
+	var syncs []*fleet.LabelMembershipSync
+	if err := sqlx.SelectContext(ctx, ds.reader, &syncs, stmt, fleet.LabelMembershipTypeManual); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list label membership syncs")
+	}
+	return syncs, nil
+}
+
+func (ds *Datastore) SetLabelMembershipSyncResult(ctx context.Context, labelID uint, syncedAt time.Time, syncErr string) error {
+	var errArg *string
+	if syncErr != "" {
+		errArg = &syncErr
+	}
+	stmt := `UPDATE label_membership_syncs SET synced_at = ?, sync_error = ? WHERE label_id = ?`
+	if _, err := ds.writer.ExecContext(ctx, stmt, syncedAt, errArg, labelID); err != nil {
+		return ctxerr.Wrap(ctx, err, "set label membership sync result")
+	}
+	return nil
+}
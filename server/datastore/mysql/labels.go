@@ -52,8 +52,7 @@ func (ds *Datastore) ApplyLabelSpecs(ctx context.Context, specs []*fleet.LabelSp
 				return ctxerr.Wrap(ctx, err, "exec ApplyLabelSpecs insert")
 			}
 
-			if s.LabelType == fleet.LabelTypeBuiltIn ||
-				s.LabelMembershipType != fleet.LabelMembershipTypeManual {
+			if s.LabelType == fleet.LabelTypeBuiltIn {
 				// No need to update membership
 				continue
 			}
@@ -66,6 +65,30 @@ SELECT id from labels WHERE name = ?
 				return ctxerr.Wrap(ctx, err, "get label ID")
 			}
 
+			if s.LabelMembershipType != fleet.LabelMembershipTypeManual || s.HostsSyncURL == "" {
+				_, err = tx.ExecContext(ctx, `DELETE FROM label_membership_syncs WHERE label_id = ?`, labelID)
+				if err != nil {
+					return ctxerr.Wrap(ctx, err, "delete label membership sync")
+				}
+			} else {
+				_, err = tx.ExecContext(ctx, `
+INSERT INTO label_membership_syncs (label_id, url) VALUES (?, ?)
+ON DUPLICATE KEY UPDATE url = VALUES(url)
+`, labelID, s.HostsSyncURL)
+				if err != nil {
+					return ctxerr.Wrap(ctx, err, "upsert label membership sync")
+				}
+			}
+
+			if s.LabelMembershipType != fleet.LabelMembershipTypeManual {
+				// No need to update membership
+				continue
+			}
+			if s.Hosts == nil && s.HostsSyncURL != "" {
+				// The membership is set by the next sync from the URL.
+				continue
+			}
+
 			sql = `
 DELETE FROM label_membership WHERE label_id = ?
 `
@@ -78,21 +101,9 @@ DELETE FROM label_membership WHERE label_id = ?
 				continue
 			}
 
-			// Split hostnames into batches to avoid parameter limit in MySQL.
-			for _, hostnames := range batchHostnames(s.Hosts) {
-				// Use ignore because duplicate hostnames could appear in
-				// different batches and would result in duplicate key errors.
-				sql = `
-INSERT IGNORE INTO label_membership (label_id, host_id) (SELECT ?, id FROM hosts where hostname IN (?))
-`
-				sql, args, err := sqlx.In(sql, labelID, hostnames)
-				if err != nil {
-					return ctxerr.Wrap(ctx, err, "build membership IN statement")
-				}
-				_, err = tx.ExecContext(ctx, sql, args...)
-				if err != nil {
-					return ctxerr.Wrap(ctx, err, "execute membership INSERT")
-				}
+			// The hosts are matched by hostname, hardware serial number or UUID.
+			if err := insertLabelMembershipByIdentifiersDB(ctx, tx, labelID, s.Hosts); err != nil {
+				return err
 			}
 		}
 
@@ -120,7 +131,11 @@ func batchHostnames(hostnames []string) [][]string {
 func (ds *Datastore) GetLabelSpecs(ctx context.Context) ([]*fleet.LabelSpec, error) {
 	var specs []*fleet.LabelSpec
 	// Get basic specs
-	query := "SELECT id, name, description, query, platform, label_type, label_membership_type FROM labels"
+	query := `
+SELECT l.id, l.name, l.description, l.query, l.platform, l.label_type, l.label_membership_type, COALESCE(lms.url, '') AS hosts_sync_url
+FROM labels l
+LEFT JOIN label_membership_syncs lms ON lms.label_id = l.id
+`
 	if err := sqlx.SelectContext(ctx, ds.reader, &specs, query); err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "get labels")
 	}
@@ -140,9 +155,10 @@ func (ds *Datastore) GetLabelSpecs(ctx context.Context) ([]*fleet.LabelSpec, err
 func (ds *Datastore) GetLabelSpec(ctx context.Context, name string) (*fleet.LabelSpec, error) {
 	var specs []*fleet.LabelSpec
 	query := `
-SELECT name, description, query, platform, label_type, label_membership_type
-FROM labels
-WHERE name = ?
+SELECT l.name, l.description, l.query, l.platform, l.label_type, l.label_membership_type, COALESCE(lms.url, '') AS hosts_sync_url
+FROM labels l
+LEFT JOIN label_membership_syncs lms ON lms.label_id = l.id
+WHERE l.name = ?
 `
 	if err := sqlx.SelectContext(ctx, ds.reader, &specs, query, name); err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "get label")
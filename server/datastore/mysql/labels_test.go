@@ -63,6 +63,8 @@ func TestLabels(t *testing.T) {
 		{"DeleteLabel", testDeleteLabel},
 		{"LabelsSummary", testLabelsSummary},
 		{"ListHostsInLabelFailingPolicies", testListHostsInLabelFailingPolicies},
+		{"ApplyMembership", testLabelsApplyMembership},
+		{"MembershipSyncs", testLabelsMembershipSyncs},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -955,3 +957,127 @@ func checkLabelHostIssues(t *testing.T, ds *Datastore, hosts []*fleet.Host, lid
 	assert.Equal(t, expected, hostById.HostIssues.FailingPoliciesCount)
 	assert.Equal(t, expected, hostById.HostIssues.TotalIssuesCount)
 }
+
+func testLabelsApplyMembership(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	var hosts []*fleet.Host
+	for i := 1; i <= 3; i++ {
+		h, err := ds.NewHost(ctx, &fleet.Host{
+			DetailUpdatedAt: time.Now(),
+			LabelUpdatedAt:  time.Now(),
+			PolicyUpdatedAt: time.Now(),
+			SeenTime:        time.Now(),
+			OsqueryHostID:   ptr.String(fmt.Sprint(i)),
+			NodeKey:         ptr.String(fmt.Sprint(i)),
+			UUID:            fmt.Sprintf("uuid-%d", i),
+			Hostname:        fmt.Sprintf("host%d.local", i),
+			HardwareSerial:  fmt.Sprintf("serial-%d", i),
+		})
+		require.NoError(t, err)
+		hosts = append(hosts, h)
+	}
+
+	err := ds.ApplyLabelSpecs(ctx, []*fleet.LabelSpec{{
+		Name:                "cmdb",
+		LabelMembershipType: fleet.LabelMembershipTypeManual,
+		// hosts are matched by hostname, serial or UUID
+		Hosts: []string{"host1.local", "serial-2"},
+	}})
+	require.NoError(t, err)
+	spec, err := ds.GetLabelSpec(ctx, "cmdb")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"host1.local", "host2.local"}, spec.Hosts)
+
+	labelIDs, err := ds.LabelIDsByName(ctx, []string{"cmdb"})
+	require.NoError(t, err)
+	require.Len(t, labelIDs, 1)
+	labelID := labelIDs[0]
+
+	// dry-run does not change the membership
+	diff, err := ds.ApplyLabelMembership(ctx, labelID, []string{"uuid-3", "host2.local", "unknown"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "cmdb", diff.LabelName)
+	assert.True(t, diff.DryRun)
+	assert.Equal(t, []fleet.LabelMembershipHost{{ID: hosts[2].ID, DisplayName: "host3.local"}}, diff.Added)
+	assert.Equal(t, []fleet.LabelMembershipHost{{ID: hosts[0].ID, DisplayName: "host1.local"}}, diff.Removed)
+	assert.Equal(t, []string{"unknown"}, diff.Unmatched)
+	spec, err = ds.GetLabelSpec(ctx, "cmdb")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"host1.local", "host2.local"}, spec.Hosts)
+
+	diff, err = ds.ApplyLabelMembership(ctx, labelID, []string{"uuid-3", "host2.local", "unknown"}, false)
+	require.NoError(t, err)
+	assert.False(t, diff.DryRun)
+	assert.Len(t, diff.Added, 1)
+	assert.Len(t, diff.Removed, 1)
+	spec, err = ds.GetLabelSpec(ctx, "cmdb")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"host2.local", "host3.local"}, spec.Hosts)
+
+	// applying the same identifiers again is a no-op
+	diff, err = ds.ApplyLabelMembership(ctx, labelID, []string{"serial-3", "serial-2"}, false)
+	require.NoError(t, err)
+	assert.True(t, diff.IsEmpty())
+	assert.Empty(t, diff.Unmatched)
+
+	_, err = ds.ApplyLabelMembership(ctx, labelID+1000, nil, false)
+	require.True(t, fleet.IsNotFound(err))
+}
+
+func testLabelsMembershipSyncs(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	syncs, err := ds.ListLabelMembershipSyncs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, syncs)
+
+	// a label with a sync URL and no hosts list is valid
+	specs := []*fleet.LabelSpec{
+		{Name: "cmdb", LabelMembershipType: fleet.LabelMembershipTypeManual, HostsSyncURL: "https://cmdb.example.com/a"},
+		{Name: "other", LabelMembershipType: fleet.LabelMembershipTypeManual, Hosts: []string{}},
+	}
+	require.NoError(t, ds.ApplyLabelSpecs(ctx, specs))
+
+	spec, err := ds.GetLabelSpec(ctx, "cmdb")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cmdb.example.com/a", spec.HostsSyncURL)
+	spec, err = ds.GetLabelSpec(ctx, "other")
+	require.NoError(t, err)
+	assert.Empty(t, spec.HostsSyncURL)
+
+	syncs, err = ds.ListLabelMembershipSyncs(ctx)
+	require.NoError(t, err)
+	require.Len(t, syncs, 1)
+	assert.Equal(t, "cmdb", syncs[0].LabelName)
+	assert.Equal(t, "https://cmdb.example.com/a", syncs[0].URL)
+	assert.Nil(t, syncs[0].SyncedAt)
+	assert.Nil(t, syncs[0].SyncError)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, ds.SetLabelMembershipSyncResult(ctx, syncs[0].LabelID, now, "unexpected status 404"))
+	syncs, err = ds.ListLabelMembershipSyncs(ctx)
+	require.NoError(t, err)
+	require.Len(t, syncs, 1)
+	require.NotNil(t, syncs[0].SyncedAt)
+	assert.Equal(t, now, syncs[0].SyncedAt.UTC())
+	require.NotNil(t, syncs[0].SyncError)
+	assert.Equal(t, "unexpected status 404", *syncs[0].SyncError)
+
+	// updating the URL keeps the result of the last sync
+	specs[0].HostsSyncURL = "https://cmdb.example.com/b"
+	require.NoError(t, ds.ApplyLabelSpecs(ctx, specs))
+	syncs, err = ds.ListLabelMembershipSyncs(ctx)
+	require.NoError(t, err)
+	require.Len(t, syncs, 1)
+	assert.Equal(t, "https://cmdb.example.com/b", syncs[0].URL)
+	assert.NotNil(t, syncs[0].SyncedAt)
+
+	// removing the URL removes the sync
+	specs[0].HostsSyncURL = ""
+	specs[0].Hosts = []string{}
+	require.NoError(t, ds.ApplyLabelSpecs(ctx, specs))
+	syncs, err = ds.ListLabelMembershipSyncs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, syncs)
+}
@@ -0,0 +1,65 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230307104251, Down_20230307104251)
+}
+
+func Up_20230307104251(tx *sql.Tx) error {
+	// existing activities are marked as processed so that enabling an
+	// activities webhook does not deliver the whole history of activities.
+	if _, err := tx.Exec(
+		"ALTER TABLE `activities` ADD COLUMN `webhooks_processed` TINYINT(1) NOT NULL DEFAULT FALSE;",
+	); err != nil {
+		return errors.Wrap(err, "adding webhooks_processed column to activities")
+	}
+	if _, err := tx.Exec(
+		"UPDATE `activities` SET `webhooks_processed` = TRUE;",
+	); err != nil {
+		return errors.Wrap(err, "marking existing activities as webhooks processed")
+	}
+	if _, err := tx.Exec(
+		"CREATE INDEX activities_webhooks_processed_idx ON activities (webhooks_processed);",
+	); err != nil {
+		return errors.Wrap(err, "create activities_webhooks_processed_idx")
+	}
+
+	if _, err := tx.Exec(`
+	  CREATE TABLE activity_webhook_deliveries (
+	    id              int(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	    activity_id     int(10) UNSIGNED NOT NULL,
+	    activity_type   varchar(255) NOT NULL,
+	    destination_url text NOT NULL,
+	    status          varchar(20) NOT NULL DEFAULT 'pending',
+	    attempts        int(10) UNSIGNED NOT NULL DEFAULT 0,
+	    response_status int(10) DEFAULT NULL,
+	    error           text DEFAULT NULL,
+	    created_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (id),
+	    KEY idx_activity_webhook_deliveries_status (status),
+	    FOREIGN KEY fk_activity_webhook_deliveries_activity_id (activity_id) REFERENCES activities (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create activity_webhook_deliveries table")
+	}
+
+	// not_before allows delaying the processing of a job, e.g. to back off
+	// before retrying a failed job.
+	if _, err := tx.Exec(
+		"ALTER TABLE `jobs` ADD COLUMN `not_before` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;",
+	); err != nil {
+		return errors.Wrap(err, "adding not_before column to jobs")
+	}
+	return nil
+}
+
+func Down_20230307104251(tx *sql.Tx) error {
+	return nil
+}
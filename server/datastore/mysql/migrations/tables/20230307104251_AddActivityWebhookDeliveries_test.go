@@ -0,0 +1,54 @@
+package tables
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230307104251(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO activities (activity_type, details) VALUES ('created_pack', '{}')`)
+	require.NoError(t, err)
+	actID, _ := res.LastInsertId()
+
+	_, err = db.Exec(`INSERT INTO jobs (name, args, state) VALUES ('jira', '{}', 'queued')`)
+	require.NoError(t, err)
+
+	applyNext(t, db)
+
+	// existing activities are marked as processed
+	var processed bool
+	err = db.Get(&processed, `SELECT webhooks_processed FROM activities WHERE id = ?`, actID)
+	require.NoError(t, err)
+	require.True(t, processed)
+
+	// new activities are not
+	res, err = db.Exec(`INSERT INTO activities (activity_type, details) VALUES ('created_pack', '{}')`)
+	require.NoError(t, err)
+	newActID, _ := res.LastInsertId()
+	err = db.Get(&processed, `SELECT webhooks_processed FROM activities WHERE id = ?`, newActID)
+	require.NoError(t, err)
+	require.False(t, processed)
+
+	// existing jobs can be processed right away
+	var notBefore time.Time
+	err = db.Get(&notBefore, `SELECT not_before FROM jobs`)
+	require.NoError(t, err)
+	require.False(t, notBefore.IsZero())
+
+	execNoErr(t, db, `INSERT INTO activity_webhook_deliveries (activity_id, activity_type, destination_url) VALUES (?, 'created_pack', 'https://example.com')`, newActID)
+	var status string
+	err = db.Get(&status, `SELECT status FROM activity_webhook_deliveries WHERE activity_id = ?`, newActID)
+	require.NoError(t, err)
+	require.Equal(t, "pending", status)
+
+	// deliveries are deleted with their activity
+	execNoErr(t, db, `DELETE FROM activities WHERE id = ?`, newActID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM activity_webhook_deliveries`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
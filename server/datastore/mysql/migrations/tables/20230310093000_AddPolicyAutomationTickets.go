@@ -0,0 +1,42 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230310093000, Down_20230310093000)
+}
+
+func Up_20230310093000(tx *sql.Tx) error {
+	// policy_automation_tickets keeps track of the external tickets (Jira
+	// issues, Zendesk tickets) created by the failing policies automations, so
+	// that repeated failures update the existing ticket instead of creating a
+	// new one, and so that tickets can be resolved once the hosts pass the
+	// policy. There are no foreign keys on purpose: rows of deleted hosts or
+	// policies are used to resolve their tickets before being removed.
+	if _, err := tx.Exec(`
+	  CREATE TABLE policy_automation_tickets (
+	    id               int(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	    policy_id        int(10) UNSIGNED NOT NULL,
+	    host_id          int(10) UNSIGNED NOT NULL,
+	    integration_type varchar(20) NOT NULL,
+	    integration_key  varchar(255) NOT NULL,
+	    ticket_id        varchar(255) NOT NULL,
+	    created_at       timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at       timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	    PRIMARY KEY (id),
+	    UNIQUE KEY idx_policy_automation_tickets_unique (policy_id, host_id, integration_type, integration_key),
+	    KEY idx_policy_automation_tickets_ticket (integration_type, ticket_id)
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create policy_automation_tickets table")
+	}
+	return nil
+}
+
+func Down_20230310093000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230310093000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	applyNext(t, db)
+
+	const insStmt = `INSERT INTO policy_automation_tickets (policy_id, host_id, integration_type, integration_key, ticket_id) VALUES (?, ?, ?, ?, ?)`
+	execNoErr(t, db, insStmt, 1, 1, "jira", "https://jira.example.com\nPROJ", "PROJ-1")
+	execNoErr(t, db, insStmt, 1, 2, "jira", "https://jira.example.com\nPROJ", "PROJ-1")
+	execNoErr(t, db, insStmt, 1, 1, "zendesk", "https://zendesk.example.com\n123", "42")
+
+	// the same host cannot be tracked twice for the same policy and integration
+	_, err := db.Exec(insStmt, 1, 1, "jira", "https://jira.example.com\nPROJ", "PROJ-2")
+	require.Error(t, err)
+
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM policy_automation_tickets WHERE integration_type = ? AND ticket_id = ?`, "jira", "PROJ-1")
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
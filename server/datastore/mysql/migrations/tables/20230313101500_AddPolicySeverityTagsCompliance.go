@@ -0,0 +1,49 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230313101500, Down_20230313101500)
+}
+
+func Up_20230313101500(tx *sql.Tx) error {
+	if _, err := tx.Exec(
+		"ALTER TABLE `policies` ADD COLUMN `severity` VARCHAR(20) NOT NULL DEFAULT '';",
+	); err != nil {
+		return errors.Wrap(err, "adding severity column to policies")
+	}
+
+	if _, err := tx.Exec(`
+	  CREATE TABLE policy_tags (
+	    policy_id int(10) UNSIGNED NOT NULL,
+	    tag       varchar(255) NOT NULL,
+	    PRIMARY KEY (policy_id, tag),
+	    KEY idx_policy_tags_tag (tag),
+	    FOREIGN KEY fk_policy_tags_policy_id (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create policy_tags table")
+	}
+
+	if _, err := tx.Exec(`
+	  CREATE TABLE policy_compliance_controls (
+	    policy_id int(10) UNSIGNED NOT NULL,
+	    framework varchar(100) NOT NULL,
+	    control   varchar(100) NOT NULL,
+	    PRIMARY KEY (policy_id, framework, control),
+	    KEY idx_policy_compliance_controls_framework (framework),
+	    FOREIGN KEY fk_policy_compliance_controls_policy_id (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create policy_compliance_controls table")
+	}
+	return nil
+}
+
+func Down_20230313101500(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230313101500(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO policies (name, query, description) VALUES ('p1', 'SELECT 1', '')`)
+	require.NoError(t, err)
+	policyID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	// existing policies have no severity
+	var severity string
+	err = db.Get(&severity, `SELECT severity FROM policies WHERE id = ?`, policyID)
+	require.NoError(t, err)
+	require.Empty(t, severity)
+
+	execNoErr(t, db, `INSERT INTO policy_tags (policy_id, tag) VALUES (?, 'encryption')`, policyID)
+	execNoErr(t, db, `INSERT INTO policy_compliance_controls (policy_id, framework, control) VALUES (?, 'CIS', '1.2.3'), (?, 'CIS', '1.2.4')`, policyID, policyID)
+
+	// tags and controls are deleted with the policy
+	execNoErr(t, db, `DELETE FROM policies WHERE id = ?`, policyID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM policy_tags`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	err = db.Get(&count, `SELECT COUNT(*) FROM policy_compliance_controls`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,37 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230314093000, Down_20230314093000)
+}
+
+func Up_20230314093000(tx *sql.Tx) error {
+	// team_id is 0 for the hosts that don't belong to a team.
+	if _, err := tx.Exec(`
+	  CREATE TABLE policy_stats_history (
+	    snapshot_date      date NOT NULL,
+	    policy_id          int(10) UNSIGNED NOT NULL,
+	    team_id            int(10) UNSIGNED NOT NULL DEFAULT 0,
+	    passing_host_count int(10) UNSIGNED NOT NULL DEFAULT 0,
+	    failing_host_count int(10) UNSIGNED NOT NULL DEFAULT 0,
+	    created_at         timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at         timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	    PRIMARY KEY (snapshot_date, policy_id, team_id),
+	    KEY idx_policy_stats_history_policy_id (policy_id),
+	    KEY idx_policy_stats_history_team_id (team_id),
+	    FOREIGN KEY fk_policy_stats_history_policy_id (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create policy_stats_history table")
+	}
+	return nil
+}
+
+func Down_20230314093000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230314093000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO policies (name, query, description) VALUES ('p1', 'SELECT 1', '')`)
+	require.NoError(t, err)
+	policyID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO policy_stats_history (snapshot_date, policy_id, passing_host_count, failing_host_count) VALUES ('2023-03-14', ?, 3, 1)`, policyID)
+	execNoErr(t, db, `INSERT INTO policy_stats_history (snapshot_date, policy_id, team_id, passing_host_count) VALUES ('2023-03-14', ?, 1, 2)`, policyID)
+
+	// the same snapshot cannot be recorded twice
+	_, err = db.Exec(`INSERT INTO policy_stats_history (snapshot_date, policy_id, team_id) VALUES ('2023-03-14', ?, 1)`, policyID)
+	require.Error(t, err)
+
+	// the history is deleted with the policy
+	execNoErr(t, db, `DELETE FROM policies WHERE id = ?`, policyID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM policy_stats_history`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230315090000, Down_20230315090000)
+}
+
+func Up_20230315090000(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE queries ADD COLUMN store_results tinyint(1) NOT NULL DEFAULT '0'`); err != nil {
+		return errors.Wrap(err, "add store_results to queries")
+	}
+
+	// query_results stores the latest snapshot results reported by each host
+	// for the queries with store_results enabled, one row per result row.
+	if _, err := tx.Exec(`
+	  CREATE TABLE query_results (
+	    id           bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	    query_id     int(10) UNSIGNED NOT NULL,
+	    host_id      int(10) UNSIGNED NOT NULL,
+	    last_fetched timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    data         json NOT NULL,
+	    PRIMARY KEY (id),
+	    KEY idx_query_results_query_id_host_id (query_id, host_id),
+	    KEY idx_query_results_host_id (host_id),
+	    FOREIGN KEY fk_query_results_query_id (query_id) REFERENCES queries (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create query_results table")
+	}
+	return nil
+}
+
+func Down_20230315090000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230315090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO queries (name, query, description) VALUES ('q1', 'SELECT 1', '')`)
+	require.NoError(t, err)
+	queryID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	var storeResults bool
+	err = db.Get(&storeResults, `SELECT store_results FROM queries WHERE id = ?`, queryID)
+	require.NoError(t, err)
+	require.False(t, storeResults)
+
+	execNoErr(t, db, `INSERT INTO query_results (query_id, host_id, data) VALUES (?, 1, '{"a": "1"}')`, queryID)
+	execNoErr(t, db, `INSERT INTO query_results (query_id, host_id, data) VALUES (?, 1, '{"a": "2"}')`, queryID)
+
+	// the results are deleted with the query
+	execNoErr(t, db, `DELETE FROM queries WHERE id = ?`, queryID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM query_results`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,41 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230316090000, Down_20230316090000)
+}
+
+func Up_20230316090000(tx *sql.Tx) error {
+	// distributed_query_campaign_results stores the results of live query
+	// campaigns when their persistence is enabled, one row per host. The host
+	// names are copied so the results can still be rendered after the host is
+	// deleted.
+	if _, err := tx.Exec(`
+	  CREATE TABLE distributed_query_campaign_results (
+	    id                            bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	    distributed_query_campaign_id int(10) UNSIGNED NOT NULL,
+	    host_id                       int(10) UNSIGNED NOT NULL,
+	    hostname                      varchar(255) NOT NULL DEFAULT '',
+	    display_name                  varchar(255) NOT NULL DEFAULT '',
+	    data                          json DEFAULT NULL,
+	    error                         text,
+	    created_at                    timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    PRIMARY KEY (id),
+	    UNIQUE KEY idx_dqcr_campaign_id_host_id (distributed_query_campaign_id, host_id),
+	    KEY idx_dqcr_host_id (host_id),
+	    FOREIGN KEY fk_dqcr_campaign_id (distributed_query_campaign_id) REFERENCES distributed_query_campaigns (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create distributed_query_campaign_results table")
+	}
+	return nil
+}
+
+func Down_20230316090000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230316090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO distributed_query_campaigns (query_id, status, user_id) VALUES (1, 0, 1)`)
+	require.NoError(t, err)
+	campaignID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO distributed_query_campaign_results (distributed_query_campaign_id, host_id, hostname, data) VALUES (?, 1, 'h1', '[{"a": "1"}]')`, campaignID)
+	execNoErr(t, db, `INSERT INTO distributed_query_campaign_results (distributed_query_campaign_id, host_id, hostname, error) VALUES (?, 2, 'h2', 'failed')`, campaignID)
+
+	// a host has a single result per campaign
+	_, err = db.Exec(`INSERT INTO distributed_query_campaign_results (distributed_query_campaign_id, host_id) VALUES (?, 1)`, campaignID)
+	require.Error(t, err)
+
+	// the results are deleted with the campaign
+	execNoErr(t, db, `DELETE FROM distributed_query_campaigns WHERE id = ?`, campaignID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM distributed_query_campaign_results`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230317090000, Down_20230317090000)
+}
+
+func Up_20230317090000(tx *sql.Tx) error {
+	// sha256 is the hex-encoded hash of the carve contents, set once all the
+	// blocks were received. sha256_state is the marshaled state of the hash of
+	// the blocks received so far, as blocks can be received by any Fleet
+	// server.
+	if _, err := tx.Exec(`
+	  ALTER TABLE carve_metadata
+	    ADD COLUMN sha256 char(64) DEFAULT NULL,
+	    ADD COLUMN sha256_state varbinary(255) DEFAULT NULL`,
+	); err != nil {
+		return errors.Wrap(err, "add sha256 columns to carve_metadata")
+	}
+	return nil
+}
+
+func Down_20230317090000(tx *sql.Tx) error {
+	return nil
+}
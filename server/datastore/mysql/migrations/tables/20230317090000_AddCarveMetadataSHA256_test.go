@@ -0,0 +1,33 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230317090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO hosts (id, osquery_host_id) VALUES (1, 'h1')`)
+	execNoErr(t, db, `
+	  INSERT INTO carve_metadata (host_id, name, block_count, block_size, carve_size, carve_id, request_id, session_id)
+	  VALUES (1, 'carve1', 1, 10, 10, 'c1', 'r1', 's1')`)
+
+	applyNext(t, db)
+
+	var carve struct {
+		SHA256      *string `db:"sha256"`
+		SHA256State []byte  `db:"sha256_state"`
+	}
+	err := db.Get(&carve, `SELECT sha256, sha256_state FROM carve_metadata WHERE name = 'carve1'`)
+	require.NoError(t, err)
+	require.Nil(t, carve.SHA256)
+	require.Nil(t, carve.SHA256State)
+
+	execNoErr(t, db, `UPDATE carve_metadata SET sha256 = REPEAT('a', 64), sha256_state = X'0102' WHERE name = 'carve1'`)
+	err = db.Get(&carve, `SELECT sha256, sha256_state FROM carve_metadata WHERE name = 'carve1'`)
+	require.NoError(t, err)
+	require.Equal(t, 64, len(*carve.SHA256))
+	require.Equal(t, []byte{1, 2}, carve.SHA256State)
+}
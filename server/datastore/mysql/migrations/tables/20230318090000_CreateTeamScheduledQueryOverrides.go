@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230318090000, Down_20230318090000)
+}
+
+func Up_20230318090000(tx *sql.Tx) error {
+	// team_scheduled_query_overrides stores how a team deviates from the global
+	// schedule. A NULL interval, shard or platform means the value of the
+	// global scheduled query is used.
+	if _, err := tx.Exec(`
+	  CREATE TABLE team_scheduled_query_overrides (
+	    team_id            int(10) UNSIGNED NOT NULL,
+	    scheduled_query_id int(10) UNSIGNED NOT NULL,
+	    disabled           tinyint(1) NOT NULL DEFAULT FALSE,
+	    ` + "`interval`" + `         int(10) UNSIGNED NULL,
+	    shard              int(10) UNSIGNED NULL,
+	    platform           varchar(255) NULL,
+	    created_at         timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at         timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	    PRIMARY KEY (team_id, scheduled_query_id),
+	    KEY idx_tsqo_scheduled_query_id (scheduled_query_id),
+	    FOREIGN KEY fk_tsqo_team_id (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+	    FOREIGN KEY fk_tsqo_scheduled_query_id (scheduled_query_id) REFERENCES scheduled_queries (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create team_scheduled_query_overrides table")
+	}
+	return nil
+}
+
+func Down_20230318090000(tx *sql.Tx) error {
+	return nil
+}
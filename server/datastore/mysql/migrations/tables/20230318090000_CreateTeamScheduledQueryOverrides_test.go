@@ -0,0 +1,37 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230318090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO teams (name) VALUES ('team1')`)
+	require.NoError(t, err)
+	teamID, _ := res.LastInsertId()
+	execNoErr(t, db, `INSERT INTO queries (name, description, query) VALUES ('q1', '', 'SELECT 1')`)
+	res, err = db.Exec(`INSERT INTO packs (name, pack_type) VALUES ('Global', 'global')`)
+	require.NoError(t, err)
+	packID, _ := res.LastInsertId()
+	res, err = db.Exec(`INSERT INTO scheduled_queries (pack_id, query_name, name, `+"`interval`"+`) VALUES (?, 'q1', 'q1', 60)`, packID)
+	require.NoError(t, err)
+	sqID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO team_scheduled_query_overrides (team_id, scheduled_query_id, `+"`interval`"+`, shard) VALUES (?, ?, 120, 50)`, teamID, sqID)
+
+	// a team has a single override per scheduled query
+	_, err = db.Exec(`INSERT INTO team_scheduled_query_overrides (team_id, scheduled_query_id, disabled) VALUES (?, ?, 1)`, teamID, sqID)
+	require.Error(t, err)
+
+	// the overrides are deleted with the scheduled query
+	execNoErr(t, db, `DELETE FROM scheduled_queries WHERE id = ?`, sqID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM team_scheduled_query_overrides`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
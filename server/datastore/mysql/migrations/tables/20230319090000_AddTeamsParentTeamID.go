@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230319090000, Down_20230319090000)
+}
+
+func Up_20230319090000(tx *sql.Tx) error {
+	// parent_team_id makes a team the child of another team, from which it
+	// inherits the settings that it does not set.
+	if _, err := tx.Exec(`
+	  ALTER TABLE teams
+	    ADD COLUMN parent_team_id int(10) UNSIGNED NULL,
+	    ADD CONSTRAINT fk_teams_parent_team_id FOREIGN KEY (parent_team_id) REFERENCES teams (id) ON DELETE SET NULL`,
+	); err != nil {
+		return errors.Wrap(err, "add teams parent_team_id column")
+	}
+	return nil
+}
+
+func Down_20230319090000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230319090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO teams (name) VALUES ('parent')`)
+	require.NoError(t, err)
+	parentID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	res, err = db.Exec(`INSERT INTO teams (name, parent_team_id) VALUES ('child', ?)`, parentID)
+	require.NoError(t, err)
+	childID, _ := res.LastInsertId()
+
+	// the parent must exist
+	_, err = db.Exec(`INSERT INTO teams (name, parent_team_id) VALUES ('orphan', 9999)`)
+	require.Error(t, err)
+
+	// deleting the parent makes the child a top-level team
+	execNoErr(t, db, `DELETE FROM teams WHERE id = ?`, parentID)
+	var parent *uint
+	err = db.Get(&parent, `SELECT parent_team_id FROM teams WHERE id = ?`, childID)
+	require.NoError(t, err)
+	require.Nil(t, parent)
+}
@@ -0,0 +1,34 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230320090000, Down_20230320090000)
+}
+
+func Up_20230320090000(tx *sql.Tx) error {
+	// host_lifecycle_states stores the lifecycle state set on a host by the
+	// host lifecycle rules of its team (e.g. "stale").
+	if _, err := tx.Exec(`
+	  CREATE TABLE host_lifecycle_states (
+	    host_id    int(10) UNSIGNED NOT NULL,
+	    state      varchar(32) NOT NULL,
+	    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (host_id),
+	    KEY idx_host_lifecycle_states_state (state)
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create host_lifecycle_states table")
+	}
+	return nil
+}
+
+func Down_20230320090000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230320090000(t *testing.T) {
+	db := applyUpToPrev(t)
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO host_lifecycle_states (host_id, state) VALUES (1, 'stale')`)
+
+	// a host has a single lifecycle state
+	_, err := db.Exec(`INSERT INTO host_lifecycle_states (host_id, state) VALUES (1, 'stale')`)
+	require.Error(t, err)
+
+	var state string
+	err = db.Get(&state, `SELECT state FROM host_lifecycle_states WHERE host_id = 1`)
+	require.NoError(t, err)
+	require.Equal(t, "stale", state)
+}
@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230321090000, Down_20230321090000)
+}
+
+func Up_20230321090000(tx *sql.Tx) error {
+	// label_membership_syncs stores the URL that the membership of a manual
+	// label is periodically synced from, along with the result of the last sync.
+	if _, err := tx.Exec(`
+	  CREATE TABLE label_membership_syncs (
+	    label_id   int(10) UNSIGNED NOT NULL,
+	    url        varchar(2048) NOT NULL,
+	    synced_at  timestamp NULL DEFAULT NULL,
+	    sync_error text,
+	    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (label_id),
+	    CONSTRAINT fk_label_membership_syncs_label_id FOREIGN KEY (label_id) REFERENCES labels (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create label_membership_syncs table")
+	}
+	return nil
+}
+
+func Down_20230321090000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230321090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO labels (name, query, label_membership_type) VALUES ('manual', '', 1)`)
+	require.NoError(t, err)
+	labelID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO label_membership_syncs (label_id, url) VALUES (?, 'https://example.com/hosts')`, labelID)
+
+	var url string
+	err = db.Get(&url, `SELECT url FROM label_membership_syncs WHERE label_id = ?`, labelID)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/hosts", url)
+
+	// the sync is deleted with its label
+	execNoErr(t, db, `DELETE FROM labels WHERE id = ?`, labelID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM label_membership_syncs`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,62 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230322090000, Down_20230322090000)
+}
+
+func Up_20230322090000(tx *sql.Tx) error {
+	// update_rollouts pins an Orbit target of a percentage of the hosts of a
+	// team and/or a label to an update channel.
+	if _, err := tx.Exec(`
+	  CREATE TABLE update_rollouts (
+	    id                      int(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	    name                    varchar(255) NOT NULL,
+	    target                  varchar(32) NOT NULL,
+	    channel                 varchar(64) NOT NULL,
+	    team_id                 int(10) UNSIGNED DEFAULT NULL,
+	    label_id                int(10) UNSIGNED DEFAULT NULL,
+	    percentage              tinyint(3) UNSIGNED NOT NULL DEFAULT 0,
+	    halt_offline_percentage tinyint(3) UNSIGNED NOT NULL DEFAULT 0,
+	    offline_minutes         int(10) UNSIGNED NOT NULL,
+	    status                  varchar(16) NOT NULL,
+	    halted_at               timestamp NULL DEFAULT NULL,
+	    halt_reason             varchar(255) NOT NULL DEFAULT '',
+	    created_at              timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at              timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (id),
+	    UNIQUE KEY idx_update_rollouts_name (name),
+	    CONSTRAINT fk_update_rollouts_team_id FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+	    CONSTRAINT fk_update_rollouts_label_id FOREIGN KEY (label_id) REFERENCES labels (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create update_rollouts table")
+	}
+
+	// update_rollout_hosts records the hosts pinned by a rollout, which stay
+	// pinned when the rollout is narrowed or halted.
+	if _, err := tx.Exec(`
+	  CREATE TABLE update_rollout_hosts (
+	    rollout_id int(10) UNSIGNED NOT NULL,
+	    host_id    int(10) UNSIGNED NOT NULL,
+	    pinned_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (rollout_id, host_id),
+	    KEY idx_update_rollout_hosts_host_id (host_id),
+	    CONSTRAINT fk_update_rollout_hosts_rollout_id FOREIGN KEY (rollout_id) REFERENCES update_rollouts (id) ON DELETE CASCADE
+	  )`,
+	); err != nil {
+		return errors.Wrap(err, "create update_rollout_hosts table")
+	}
+	return nil
+}
+
+func Down_20230322090000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,34 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230322090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	res, err := db.Exec(`INSERT INTO teams (name) VALUES ('team1')`)
+	require.NoError(t, err)
+	teamID, _ := res.LastInsertId()
+
+	applyNext(t, db)
+
+	res, err = db.Exec(`
+	  INSERT INTO update_rollouts (name, target, channel, team_id, percentage, offline_minutes, status)
+	  VALUES ('canary', 'osqueryd', '5.8.2', ?, 5, 60, 'active')`, teamID)
+	require.NoError(t, err)
+	rolloutID, _ := res.LastInsertId()
+	execNoErr(t, db, `INSERT INTO update_rollout_hosts (rollout_id, host_id) VALUES (?, 1)`, rolloutID)
+
+	// the rollout and its hosts are deleted with its team
+	execNoErr(t, db, `DELETE FROM teams WHERE id = ?`, teamID)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM update_rollouts`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	err = db.Get(&count, `SELECT COUNT(*) FROM update_rollout_hosts`)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
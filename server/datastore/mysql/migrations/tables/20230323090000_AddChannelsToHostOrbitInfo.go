@@ -0,0 +1,29 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230323090000, Down_20230323090000)
+}
+
+func Up_20230323090000(tx *sql.Tx) error {
+	// the update channels that Orbit reports using for its targets, which may
+	// differ from the packaged ones when they are pinned by Fleet.
+	if _, err := tx.Exec(`
+	  ALTER TABLE host_orbit_info
+	    ADD COLUMN orbit_channel varchar(64) NOT NULL DEFAULT '',
+	    ADD COLUMN osqueryd_channel varchar(64) NOT NULL DEFAULT '',
+	    ADD COLUMN desktop_channel varchar(64) NOT NULL DEFAULT ''`,
+	); err != nil {
+		return errors.Wrap(err, "add channel columns to host_orbit_info")
+	}
+	return nil
+}
+
+func Down_20230323090000(tx *sql.Tx) error {
+	return nil
+}
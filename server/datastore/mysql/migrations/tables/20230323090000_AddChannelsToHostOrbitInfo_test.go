@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230323090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO host_orbit_info (host_id, version) VALUES (1, '1.5.0')`)
+
+	applyNext(t, db)
+
+	var info struct {
+		Version         string `db:"version"`
+		OrbitChannel    string `db:"orbit_channel"`
+		OsquerydChannel string `db:"osqueryd_channel"`
+		DesktopChannel  string `db:"desktop_channel"`
+	}
+	err := db.Get(&info, `SELECT version, orbit_channel, osqueryd_channel, desktop_channel FROM host_orbit_info WHERE host_id = 1`)
+	require.NoError(t, err)
+	require.Equal(t, "1.5.0", info.Version)
+	require.Empty(t, info.OrbitChannel)
+	require.Empty(t, info.OsquerydChannel)
+	require.Empty(t, info.DesktopChannel)
+
+	execNoErr(t, db, `UPDATE host_orbit_info SET orbit_channel = 'stable', osqueryd_channel = '5.8.1', desktop_channel = 'edge' WHERE host_id = 1`)
+	err = db.Get(&info, `SELECT version, orbit_channel, osqueryd_channel, desktop_channel FROM host_orbit_info WHERE host_id = 1`)
+	require.NoError(t, err)
+	require.Equal(t, "stable", info.OrbitChannel)
+	require.Equal(t, "5.8.1", info.OsquerydChannel)
+	require.Equal(t, "edge", info.DesktopChannel)
+}
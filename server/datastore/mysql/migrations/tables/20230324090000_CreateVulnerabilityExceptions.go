@@ -0,0 +1,47 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230324090000, Down_20230324090000)
+}
+
+func Up_20230324090000(tx *sql.Tx) error {
+	// a vulnerability exception suppresses a CVE globally, for the hosts of a
+	// team, for a software and/or for a single host. Like in the other software
+	// and host tables, software_id and host_id are not foreign keys, the rows
+	// of a deleted host are removed along with the host.
+	if _, err := tx.Exec(`
+	  CREATE TABLE vulnerability_exceptions (
+	    id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	    cve varchar(255) NOT NULL,
+	    team_id int(10) unsigned DEFAULT NULL,
+	    software_id bigint(20) unsigned DEFAULT NULL,
+	    host_id int(10) unsigned DEFAULT NULL,
+	    reason varchar(32) NOT NULL,
+	    justification text NOT NULL,
+	    owner varchar(255) NOT NULL DEFAULT '',
+	    author_id int(10) unsigned DEFAULT NULL,
+	    expires_at timestamp NULL DEFAULT NULL,
+	    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (id),
+	    KEY idx_vulnerability_exceptions_cve (cve),
+	    KEY idx_vulnerability_exceptions_host_id (host_id),
+	    CONSTRAINT fk_vulnerability_exceptions_team_id FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+	    CONSTRAINT fk_vulnerability_exceptions_author_id FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE SET NULL
+	  ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	); err != nil {
+		return errors.Wrap(err, "create vulnerability_exceptions table")
+	}
+	return nil
+}
+
+func Down_20230324090000(tx *sql.Tx) error {
+	return nil
+}
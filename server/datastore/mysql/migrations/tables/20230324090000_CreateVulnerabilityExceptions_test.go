@@ -0,0 +1,50 @@
+package tables
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230324090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	execNoErr(t, db, `INSERT INTO teams (id, name) VALUES (1, 'team1')`)
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO vulnerability_exceptions (cve, reason, justification) VALUES ('CVE-2022-0001', 'false_positive', 'not applicable')`)
+	execNoErr(t, db, `INSERT INTO vulnerability_exceptions (cve, team_id, software_id, host_id, reason, justification, owner, expires_at) VALUES ('CVE-2022-0002', 1, 2, 3, 'accepted_risk', 'mitigated', 'secops', '2030-01-01 00:00:00')`)
+
+	var exceptions []struct {
+		CVE        string     `db:"cve"`
+		TeamID     *uint      `db:"team_id"`
+		SoftwareID *uint      `db:"software_id"`
+		HostID     *uint      `db:"host_id"`
+		Reason     string     `db:"reason"`
+		Owner      string     `db:"owner"`
+		ExpiresAt  *time.Time `db:"expires_at"`
+	}
+	err := db.Select(&exceptions, `SELECT cve, team_id, software_id, host_id, reason, owner, expires_at FROM vulnerability_exceptions ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, exceptions, 2)
+	require.Equal(t, "CVE-2022-0001", exceptions[0].CVE)
+	require.Nil(t, exceptions[0].TeamID)
+	require.Nil(t, exceptions[0].HostID)
+	require.Nil(t, exceptions[0].ExpiresAt)
+	require.Empty(t, exceptions[0].Owner)
+	require.NotNil(t, exceptions[1].TeamID)
+	require.EqualValues(t, 1, *exceptions[1].TeamID)
+	require.NotNil(t, exceptions[1].SoftwareID)
+	require.EqualValues(t, 2, *exceptions[1].SoftwareID)
+	require.NotNil(t, exceptions[1].ExpiresAt)
+	require.Equal(t, "secops", exceptions[1].Owner)
+
+	// deleting the team deletes its exceptions
+	execNoErr(t, db, `DELETE FROM teams WHERE id = 1`)
+	var count int
+	err = db.Get(&count, `SELECT COUNT(*) FROM vulnerability_exceptions`)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,39 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230325090000, Down_20230325090000)
+}
+
+func Up_20230325090000(tx *sql.Tx) error {
+	// custom CPE translation rules are evaluated in order of id, before the
+	// translations downloaded from github.com/fleetdm/nvd. The software
+	// criteria and the filter are stored as JSON, like in the downloaded
+	// translations.
+	if _, err := tx.Exec(`
+	  CREATE TABLE cpe_translation_rules (
+	    id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	    name varchar(255) NOT NULL,
+	    description text NOT NULL,
+	    software json NOT NULL,
+	    filter json NOT NULL,
+	    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (id),
+	    UNIQUE KEY idx_cpe_translation_rules_name (name)
+	  ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	); err != nil {
+		return errors.Wrap(err, "create cpe_translation_rules table")
+	}
+	return nil
+}
+
+func Down_20230325090000(tx *sql.Tx) error {
+	return nil
+}
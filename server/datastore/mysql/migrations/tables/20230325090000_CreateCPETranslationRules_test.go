@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230325090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO cpe_translation_rules (name, description, software, filter) VALUES ('internal', '', '{"name": ["Internal App"]}', '{"skip": true}')`)
+
+	var rule struct {
+		Name     string `db:"name"`
+		Software string `db:"software"`
+		Filter   string `db:"filter"`
+	}
+	err := db.Get(&rule, `SELECT name, software, filter FROM cpe_translation_rules`)
+	require.NoError(t, err)
+	require.Equal(t, "internal", rule.Name)
+	require.JSONEq(t, `{"name": ["Internal App"]}`, rule.Software)
+	require.JSONEq(t, `{"skip": true}`, rule.Filter)
+
+	// names are unique
+	_, err = db.Exec(`INSERT INTO cpe_translation_rules (name, description, software, filter) VALUES ('internal', '', '{}', '{}')`)
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20230326090000, Down_20230326090000)
+}
+
+func Up_20230326090000(tx *sql.Tx) error {
+	// a row tracks a CVE on a host from the vulnerability processing run that
+	// first detected it until the one that no longer detected it, when
+	// resolved_at is set. There is at most one unresolved row per host and CVE.
+	// The table is not backfilled, the CVEs currently detected are recorded by
+	// the next vulnerability processing run.
+	if _, err := tx.Exec(`
+	  CREATE TABLE host_vulnerability_remediations (
+	    id int(10) unsigned NOT NULL AUTO_INCREMENT,
+	    host_id int(10) unsigned NOT NULL,
+	    cve varchar(255) NOT NULL,
+	    first_detected_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    resolved_at timestamp NULL DEFAULT NULL,
+	    sla_breach_notified_at timestamp NULL DEFAULT NULL,
+	    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+	    PRIMARY KEY (id),
+	    KEY idx_host_vulnerability_remediations_host_cve (host_id, cve, resolved_at),
+	    KEY idx_host_vulnerability_remediations_cve (cve),
+	    KEY idx_host_vulnerability_remediations_resolved_at (resolved_at)
+	  ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	); err != nil {
+		return errors.Wrap(err, "create host_vulnerability_remediations table")
+	}
+	return nil
+}
+
+func Down_20230326090000(tx *sql.Tx) error {
+	return nil
+}
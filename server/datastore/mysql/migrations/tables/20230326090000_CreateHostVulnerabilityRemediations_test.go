@@ -0,0 +1,24 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUp_20230326090000(t *testing.T) {
+	db := applyUpToPrev(t)
+
+	applyNext(t, db)
+
+	execNoErr(t, db, `INSERT INTO host_vulnerability_remediations (host_id, cve) VALUES (1, 'CVE-2022-0001')`)
+	execNoErr(t, db, `UPDATE host_vulnerability_remediations SET resolved_at = NOW() WHERE host_id = 1`)
+
+	// the same CVE can be detected again on the host once resolved
+	execNoErr(t, db, `INSERT INTO host_vulnerability_remediations (host_id, cve) VALUES (1, 'CVE-2022-0001')`)
+
+	var open int
+	err := db.Get(&open, `SELECT COUNT(*) FROM host_vulnerability_remediations WHERE host_id = 1 AND resolved_at IS NULL`)
+	require.NoError(t, err)
+	require.Equal(t, 1, open)
+}
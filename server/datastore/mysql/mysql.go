@@ -879,9 +879,11 @@ func (ds *Datastore) whereFilterHostsByTeams(filter fleet.TeamFilter, hostKey st
 	for _, team := range filter.User.Teams {
 		if team.Role == fleet.RoleAdmin || team.Role == fleet.RoleMaintainer ||
 			(team.Role == fleet.RoleObserver && filter.IncludeObserver) {
-			idStrs = append(idStrs, strconv.Itoa(int(team.ID)))
-			if filter.TeamID != nil && *filter.TeamID == team.ID {
-				teamIDSeen = true
+			for _, id := range team.ScopeIDs() {
+				idStrs = append(idStrs, strconv.Itoa(int(id)))
+				if filter.TeamID != nil && *filter.TeamID == id {
+					teamIDSeen = true
+				}
 			}
 		}
 	}
@@ -938,7 +940,9 @@ func (ds *Datastore) whereFilterTeams(filter fleet.TeamFilter, teamKey string) s
 	for _, team := range filter.User.Teams {
 		if team.Role == fleet.RoleAdmin || team.Role == fleet.RoleMaintainer ||
 			(team.Role == fleet.RoleObserver && filter.IncludeObserver) {
-			idStrs = append(idStrs, strconv.Itoa(int(team.ID)))
+			for _, id := range team.ScopeIDs() {
+				idStrs = append(idStrs, strconv.Itoa(int(id)))
+			}
 		}
 	}
 
@@ -508,7 +508,7 @@ func (ds *Datastore) EnsureTeamPack(ctx context.Context, teamID uint) (*fleet.Pa
 }
 
 func teamScheduleName(team *fleet.Team) string {
-	return fmt.Sprintf("Team: %s", team.Name)
+	return fleet.TeamSchedulePackName(team.Name)
 }
 
 func teamSchedulePackType(team *fleet.Team) string {
@@ -594,12 +594,25 @@ SELECT DISTINCT packs.* FROM (
 		SELECT p.*
 		FROM packs p
 		JOIN pack_targets pt
-		ON (p.id = pt.pack_id AND pt.type = ? AND pt.target_id = (SELECT team_id FROM hosts WHERE id = ?)))
+		ON (p.id = pt.pack_id AND pt.type = ? AND pt.target_id IN (?)))
 	) packs`
+
+	// packs targeting the ancestors of the host's team apply to the host too
+	teamIDs, err := hostTeamLineageDB(ctx, db, hid)
+	if err != nil {
+		return nil, err
+	}
+	if len(teamIDs) == 0 {
+		// make sure the IN clause is never empty
+		teamIDs = []uint{0}
+	}
+	query, args, err := sqlx.In(query, fleet.TargetLabel, hid, fleet.TargetHost, hid, fleet.TargetTeam, teamIDs)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "build list packs for host query")
+	}
+
 	packs := []*fleet.Pack{}
-	if err := sqlx.SelectContext(ctx, db, &packs, query,
-		fleet.TargetLabel, hid, fleet.TargetHost, hid, fleet.TargetTeam, hid,
-	); err != nil && err != sql.ErrNoRows {
+	if err := sqlx.SelectContext(ctx, db, &packs, query, args...); err != nil && err != sql.ErrNoRows {
 		return nil, ctxerr.Wrap(ctx, err, "listing hosts in pack")
 	}
 	return packs, nil
@@ -18,7 +18,7 @@ import (
 
 const policyCols = `
 	p.id, p.team_id, p.resolution, p.name, p.query, p.description,
-	p.author_id, p.platforms, p.created_at, p.updated_at, p.critical
+	p.author_id, p.platforms, p.created_at, p.updated_at, p.critical, p.severity
 `
 
 func (ds *Datastore) NewGlobalPolicy(ctx context.Context, authorID *uint, args fleet.PolicyPayload) (*fleet.Policy, error) {
@@ -31,23 +31,31 @@ func (ds *Datastore) NewGlobalPolicy(ctx context.Context, authorID *uint, args f
 		args.Query = q.Query
 		args.Description = q.Description
 	}
-	res, err := ds.writer.ExecContext(ctx,
-		`INSERT INTO policies (name, query, description, resolution, author_id, platforms, critical) VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		args.Name, args.Query, args.Description, args.Resolution, authorID, args.Platform, args.Critical,
-	)
-	switch {
-	case err == nil:
-		// OK
-	case isDuplicate(err):
-		return nil, ctxerr.Wrap(ctx, alreadyExists("Policy", args.Name))
-	default:
-		return nil, ctxerr.Wrap(ctx, err, "inserting new policy")
-	}
-	lastIdInt64, err := res.LastInsertId()
+	var policyID uint
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO policies (name, query, description, resolution, author_id, platforms, critical, severity) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			args.Name, args.Query, args.Description, args.Resolution, authorID, args.Platform, args.Critical, args.Severity,
+		)
+		switch {
+		case err == nil:
+			// OK
+		case isDuplicate(err):
+			return ctxerr.Wrap(ctx, alreadyExists("Policy", args.Name))
+		default:
+			return ctxerr.Wrap(ctx, err, "inserting new policy")
+		}
+		lastIdInt64, err := res.LastInsertId()
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "getting last id after inserting policy")
+		}
+		policyID = uint(lastIdInt64)
+		return replacePolicyMetadataDB(ctx, tx, policyID, args.Tags, args.Compliance)
+	})
 	if err != nil {
-		return nil, ctxerr.Wrap(ctx, err, "getting last id after inserting policy")
+		return nil, err
 	}
-	return policyDB(ctx, ds.writer, uint(lastIdInt64), nil)
+	return policyDB(ctx, ds.writer, policyID, nil)
 }
 
 func (ds *Datastore) Policy(ctx context.Context, id uint) (*fleet.Policy, error) {
@@ -79,31 +87,121 @@ func policyDB(ctx context.Context, q sqlx.QueryerContext, id uint, teamID *uint)
 		}
 		return nil, ctxerr.Wrap(ctx, err, "getting policy")
 	}
+	if err := loadPoliciesMetadataDB(ctx, q, []*fleet.PolicyData{&policy.PolicyData}); err != nil {
+		return nil, err
+	}
 	return &policy, nil
 }
 
+// loadPoliciesMetadataDB loads the tags and compliance controls of the given
+// policies.
+func loadPoliciesMetadataDB(ctx context.Context, q sqlx.QueryerContext, policies []*fleet.PolicyData) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	policiesByID := make(map[uint]*fleet.PolicyData, len(policies))
+	ids := make([]uint, 0, len(policies))
+	for _, p := range policies {
+		policiesByID[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+
+	stmt, args, err := sqlx.In(`SELECT policy_id, tag FROM policy_tags WHERE policy_id IN (?) ORDER BY policy_id, tag`, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build select policy tags query")
+	}
+	var tags []struct {
+		PolicyID uint   `db:"policy_id"`
+		Tag      string `db:"tag"`
+	}
+	if err := sqlx.SelectContext(ctx, q, &tags, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "select policy tags")
+	}
+	for _, t := range tags {
+		p := policiesByID[t.PolicyID]
+		p.Tags = append(p.Tags, t.Tag)
+	}
+
+	stmt, args, err = sqlx.In(`SELECT policy_id, framework, control FROM policy_compliance_controls WHERE policy_id IN (?) ORDER BY policy_id, framework, control`, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build select policy compliance controls query")
+	}
+	var controls []struct {
+		PolicyID uint `db:"policy_id"`
+		fleet.PolicyComplianceControl
+	}
+	if err := sqlx.SelectContext(ctx, q, &controls, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "select policy compliance controls")
+	}
+	for _, c := range controls {
+		p := policiesByID[c.PolicyID]
+		p.Compliance = append(p.Compliance, c.PolicyComplianceControl)
+	}
+	return nil
+}
+
+// replacePolicyMetadataDB replaces the tags and compliance controls of the
+// given policy.
+func replacePolicyMetadataDB(ctx context.Context, tx sqlx.ExtContext, policyID uint, tags []string, controls []fleet.PolicyComplianceControl) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM policy_tags WHERE policy_id = ?`, policyID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete policy tags")
+	}
+	if len(tags) > 0 {
+		args := make([]interface{}, 0, len(tags)*2)
+		for _, tag := range tags {
+			args = append(args, policyID, strings.TrimSpace(tag))
+		}
+		stmt := `INSERT IGNORE INTO policy_tags (policy_id, tag) VALUES ` + strings.TrimSuffix(strings.Repeat(`(?, ?),`, len(tags)), ",")
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert policy tags")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM policy_compliance_controls WHERE policy_id = ?`, policyID); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete policy compliance controls")
+	}
+	if len(controls) > 0 {
+		args := make([]interface{}, 0, len(controls)*3)
+		for _, c := range controls {
+			args = append(args, policyID, strings.TrimSpace(c.Framework), strings.TrimSpace(c.Control))
+		}
+		stmt := `INSERT IGNORE INTO policy_compliance_controls (policy_id, framework, control) VALUES ` +
+			strings.TrimSuffix(strings.Repeat(`(?, ?, ?),`, len(controls)), ",")
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert policy compliance controls")
+		}
+	}
+	return nil
+}
+
 // SavePolicy updates some fields of the given policy on the datastore.
 //
 // Currently SavePolicy does not allow updating the team of an existing policy.
+//
+// The tags and compliance controls of the policy are replaced by the ones of p.
 func (ds *Datastore) SavePolicy(ctx context.Context, p *fleet.Policy) error {
 	sql := `
 		UPDATE policies
-			SET name = ?, query = ?, description = ?, resolution = ?, platforms = ?, critical = ?
+			SET name = ?, query = ?, description = ?, resolution = ?, platforms = ?, critical = ?, severity = ?
 			WHERE id = ?
 	`
-	result, err := ds.writer.ExecContext(ctx, sql, p.Name, p.Query, p.Description, p.Resolution, p.Platform, p.Critical, p.ID)
-	if err != nil {
-		return ctxerr.Wrap(ctx, err, "updating policy")
-	}
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return ctxerr.Wrap(ctx, err, "rows affected updating policy")
-	}
-	if rows == 0 {
-		return ctxerr.Wrap(ctx, notFound("Policy").WithID(p.ID))
-	}
-
-	return cleanupPolicyMembershipOnPolicyUpdate(ctx, ds.writer, p.ID, p.Platform)
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		result, err := tx.ExecContext(ctx, sql, p.Name, p.Query, p.Description, p.Resolution, p.Platform, p.Critical, p.Severity, p.ID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "updating policy")
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "rows affected updating policy")
+		}
+		if rows == 0 {
+			return ctxerr.Wrap(ctx, notFound("Policy").WithID(p.ID))
+		}
+		if err := replacePolicyMetadataDB(ctx, tx, p.ID, p.Tags, p.Compliance); err != nil {
+			return err
+		}
+		return cleanupPolicyMembershipOnPolicyUpdate(ctx, tx, p.ID, p.Platform)
+	})
 }
 
 // FlippingPoliciesForHost fetches previous policy membership results and returns:
@@ -263,15 +361,17 @@ func (ds *Datastore) RecordPolicyQueryExecutions(ctx context.Context, host *flee
 	return nil
 }
 
-func (ds *Datastore) ListGlobalPolicies(ctx context.Context) ([]*fleet.Policy, error) {
-	return listPoliciesDB(ctx, ds.reader, nil, nil)
+func (ds *Datastore) ListGlobalPolicies(ctx context.Context, opts fleet.PolicyListOptions) ([]*fleet.Policy, error) {
+	return listPoliciesDB(ctx, ds.reader, nil, nil, opts)
 }
 
 // returns the list of policies associated with the provided teamID, or the
 // global policies if teamID is nil. The pass/fail host counts are the totals
 // regardless of hosts' team if countsForTeamID is nil, or the totals just for
-// hosts that belong to the provided countsForTeamID if it is not nil.
-func listPoliciesDB(ctx context.Context, q sqlx.QueryerContext, teamID, countsForTeamID *uint) ([]*fleet.Policy, error) {
+// hosts that belong to the provided countsForTeamID if it is not nil. The
+// policies are filtered by severity, tag and compliance framework as
+// specified by opts.
+func listPoliciesDB(ctx context.Context, q sqlx.QueryerContext, teamID, countsForTeamID *uint, opts fleet.PolicyListOptions) ([]*fleet.Policy, error) {
 	var args []interface{}
 
 	counts := `
@@ -291,6 +391,18 @@ func listPoliciesDB(ctx context.Context, q sqlx.QueryerContext, teamID, countsFo
 		teamWhere = "p.team_id = ?"
 		args = append(args, *teamID)
 	}
+	if opts.Severity != "" {
+		teamWhere += " AND p.severity = ?"
+		args = append(args, opts.Severity)
+	}
+	if opts.Tag != "" {
+		teamWhere += " AND EXISTS (SELECT 1 FROM policy_tags pt WHERE pt.policy_id = p.id AND pt.tag = ?)"
+		args = append(args, opts.Tag)
+	}
+	if opts.ComplianceFramework != "" {
+		teamWhere += " AND EXISTS (SELECT 1 FROM policy_compliance_controls pcc WHERE pcc.policy_id = p.id AND pcc.framework = ?)"
+		args = append(args, opts.ComplianceFramework)
+	}
 
 	var policies []*fleet.Policy
 	err := sqlx.SelectContext(
@@ -308,9 +420,20 @@ func listPoliciesDB(ctx context.Context, q sqlx.QueryerContext, teamID, countsFo
 	if err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "listing policies")
 	}
+	if err := loadPoliciesMetadataDB(ctx, q, policiesData(policies)); err != nil {
+		return nil, err
+	}
 	return policies, nil
 }
 
+func policiesData(policies []*fleet.Policy) []*fleet.PolicyData {
+	data := make([]*fleet.PolicyData, 0, len(policies))
+	for _, p := range policies {
+		data = append(data, &p.PolicyData)
+	}
+	return data
+}
+
 func (ds *Datastore) PoliciesByID(ctx context.Context, ids []uint) (map[uint]*fleet.Policy, error) {
 	sql := `SELECT ` + policyCols + `,
       COALESCE(u.name, '<deleted>') AS author_name,
@@ -335,6 +458,9 @@ func (ds *Datastore) PoliciesByID(ctx context.Context, ids []uint) (map[uint]*fl
 	if err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "getting policies by ID")
 	}
+	if err := loadPoliciesMetadataDB(ctx, ds.reader, policiesData(policies)); err != nil {
+		return nil, err
+	}
 
 	policiesByID := make(map[uint]*fleet.Policy, len(ids))
 	for _, p := range policies {
@@ -384,6 +510,15 @@ func (ds *Datastore) PolicyQueriesForHost(ctx context.Context, host *fleet.Host)
 		// won't be receiving any policies targeted for specific platforms.
 		level.Error(ds.logger).Log("err", fmt.Sprintf("host %d with empty platform", host.ID)) //nolint:errcheck
 	}
+	teamPolicies := goqu.I("team_id").Eq(host.TeamID)
+	if host.TeamID != nil {
+		// policies of the ancestors of the host's team apply to the host too
+		ancestors, err := teamAncestorIDsDB(ctx, ds.reader, *host.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		teamPolicies = goqu.I("team_id").In(append([]uint{*host.TeamID}, ancestors...))
+	}
 	q := dialect.From("policies").Select(
 		goqu.I("id"),
 		goqu.I("query"),
@@ -397,8 +532,8 @@ func (ds *Datastore) PolicyQueriesForHost(ctx context.Context, host *fleet.Host)
 				).Neq(0),
 			),
 			goqu.Or(
-				goqu.I("team_id").IsNull(),        // global policies
-				goqu.I("team_id").Eq(host.TeamID), // team policies
+				goqu.I("team_id").IsNull(), // global policies
+				teamPolicies,               // team policies
 			),
 		),
 	)
@@ -426,34 +561,55 @@ func (ds *Datastore) NewTeamPolicy(ctx context.Context, teamID uint, authorID *u
 		args.Query = q.Query
 		args.Description = q.Description
 	}
-	res, err := ds.writer.ExecContext(ctx,
-		`INSERT INTO policies (name, query, description, team_id, resolution, author_id, platforms, critical) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		args.Name, args.Query, args.Description, teamID, args.Resolution, authorID, args.Platform, args.Critical)
-	switch {
-	case err == nil:
-		// OK
-	case isDuplicate(err):
-		return nil, ctxerr.Wrap(ctx, alreadyExists("Policy", args.Name))
-	default:
-		return nil, ctxerr.Wrap(ctx, err, "inserting new policy")
-	}
-	lastIdInt64, err := res.LastInsertId()
+	var policyID uint
+	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO policies (name, query, description, team_id, resolution, author_id, platforms, critical, severity) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			args.Name, args.Query, args.Description, teamID, args.Resolution, authorID, args.Platform, args.Critical, args.Severity)
+		switch {
+		case err == nil:
+			// OK
+		case isDuplicate(err):
+			return ctxerr.Wrap(ctx, alreadyExists("Policy", args.Name))
+		default:
+			return ctxerr.Wrap(ctx, err, "inserting new policy")
+		}
+		lastIdInt64, err := res.LastInsertId()
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "getting last id after inserting policy")
+		}
+		policyID = uint(lastIdInt64)
+		return replacePolicyMetadataDB(ctx, tx, policyID, args.Tags, args.Compliance)
+	})
 	if err != nil {
-		return nil, ctxerr.Wrap(ctx, err, "getting last id after inserting policy")
+		return nil, err
 	}
-	return policyDB(ctx, ds.writer, uint(lastIdInt64), &teamID)
+	return policyDB(ctx, ds.writer, policyID, &teamID)
 }
 
-func (ds *Datastore) ListTeamPolicies(ctx context.Context, teamID uint) (teamPolicies, inheritedPolicies []*fleet.Policy, err error) {
-	teamPolicies, err = listPoliciesDB(ctx, ds.reader, &teamID, nil)
+func (ds *Datastore) ListTeamPolicies(ctx context.Context, teamID uint, opts fleet.PolicyListOptions) (teamPolicies, inheritedPolicies []*fleet.Policy, err error) {
+	teamPolicies, err = listPoliciesDB(ctx, ds.reader, &teamID, nil, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 	// get inherited (global) policies with counts of hosts for that team
-	inheritedPolicies, err = listPoliciesDB(ctx, ds.reader, nil, &teamID)
+	inheritedPolicies, err = listPoliciesDB(ctx, ds.reader, nil, &teamID, opts)
 	if err != nil {
 		return nil, nil, err
 	}
+	// and the policies inherited from the ancestors of the team
+	ancestors, err := teamAncestorIDsDB(ctx, ds.reader, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, ancestorID := range ancestors {
+		ancestorID := ancestorID
+		policies, err := listPoliciesDB(ctx, ds.reader, &ancestorID, &teamID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		inheritedPolicies = append(inheritedPolicies, policies...)
+	}
 	return teamPolicies, inheritedPolicies, err
 }
 
@@ -483,8 +639,9 @@ func (ds *Datastore) ApplyPolicySpecs(ctx context.Context, authorID uint, specs
 			resolution,
 			team_id,
 			platforms,
-		    critical
-		) VALUES ( ?, ?, ?, ?, ?, (SELECT IFNULL(MIN(id), NULL) FROM teams WHERE name = ?), ?, ?)
+		    critical,
+			severity
+		) VALUES ( ?, ?, ?, ?, ?, (SELECT IFNULL(MIN(id), NULL) FROM teams WHERE name = ?), ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 			name = VALUES(name),
 			query = VALUES(query),
@@ -492,11 +649,12 @@ func (ds *Datastore) ApplyPolicySpecs(ctx context.Context, authorID uint, specs
 			author_id = VALUES(author_id),
 			resolution = VALUES(resolution),
 			platforms = VALUES(platforms),
-			critical = VALUES(critical)
+			critical = VALUES(critical),
+			severity = VALUES(severity)
 		`
 		for _, spec := range specs {
 			res, err := tx.ExecContext(ctx,
-				sql, spec.Name, spec.Query, spec.Description, authorID, spec.Resolution, spec.Team, spec.Platform, spec.Critical,
+				sql, spec.Name, spec.Query, spec.Description, authorID, spec.Resolution, spec.Team, spec.Platform, spec.Critical, spec.Severity,
 			)
 			if err != nil {
 				return ctxerr.Wrap(ctx, err, "exec ApplyPolicySpecs insert")
@@ -511,11 +669,147 @@ func (ds *Datastore) ApplyPolicySpecs(ctx context.Context, authorID uint, specs
 					}
 				}
 			}
+
+			// policy names are unique, so the policy can be identified by name
+			// regardless of whether it was inserted or updated.
+			var policyID uint
+			if err := sqlx.GetContext(ctx, tx, &policyID, `SELECT id FROM policies WHERE name = ?`, spec.Name); err != nil {
+				return ctxerr.Wrap(ctx, err, "get applied policy id")
+			}
+			if err := replacePolicyMetadataDB(ctx, tx, policyID, spec.Tags, spec.Compliance); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
 }
 
+func (ds *Datastore) ListPolicyComplianceSummaries(ctx context.Context, teamID *uint) ([]*fleet.PolicyComplianceSummary, error) {
+	// a policy can be mapped to many controls of the same framework, so the
+	// distinct policy/framework pairs are used to count results only once.
+	stmt := `
+	SELECT
+		h.team_id,
+		COALESCE(t.name, '') AS team_name,
+		pf.framework,
+		COUNT(DISTINCT pf.policy_id) AS policy_count,
+		COALESCE(SUM(pm.passes = 1), 0) AS passing_count,
+		COALESCE(SUM(pm.passes = 0), 0) AS failing_count
+	FROM
+		(SELECT DISTINCT policy_id, framework FROM policy_compliance_controls) pf
+	INNER JOIN
+		policy_membership pm ON pm.policy_id = pf.policy_id
+	INNER JOIN
+		hosts h ON h.id = pm.host_id
+	LEFT JOIN
+		teams t ON t.id = h.team_id
+	WHERE
+		pm.passes IS NOT NULL AND %s
+	GROUP BY
+		h.team_id, t.name, pf.framework
+	ORDER BY
+		h.team_id, pf.framework`
+
+	teamWhere := "TRUE"
+	var args []interface{}
+	if teamID != nil {
+		teamWhere = "h.team_id = ?"
+		args = append(args, *teamID)
+	}
+
+	var summaries []*fleet.PolicyComplianceSummary
+	if err := sqlx.SelectContext(ctx, ds.reader, &summaries, fmt.Sprintf(stmt, teamWhere), args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "select policy compliance summaries")
+	}
+	for _, sum := range summaries {
+		if total := sum.PassingCount + sum.FailingCount; total > 0 {
+			sum.CompliancePercentage = float64(sum.PassingCount) * 100 / float64(total)
+		}
+	}
+	return summaries, nil
+}
+
+func (ds *Datastore) SnapshotPolicyStats(ctx context.Context, now time.Time) error {
+	// team_id is 0 for the hosts without a team, so that the snapshot can be
+	// part of the primary key.
+	stmt := `
+	INSERT INTO policy_stats_history (snapshot_date, policy_id, team_id, passing_host_count, failing_host_count)
+	SELECT
+		?,
+		pm.policy_id,
+		COALESCE(h.team_id, 0) AS hosts_team_id,
+		COALESCE(SUM(pm.passes = 1), 0),
+		COALESCE(SUM(pm.passes = 0), 0)
+	FROM
+		policy_membership pm
+	INNER JOIN
+		hosts h ON h.id = pm.host_id
+	WHERE
+		pm.passes IS NOT NULL
+	GROUP BY
+		pm.policy_id, hosts_team_id
+	ON DUPLICATE KEY UPDATE
+		passing_host_count = VALUES(passing_host_count),
+		failing_host_count = VALUES(failing_host_count)`
+
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		_, err := tx.ExecContext(ctx, stmt, now.UTC().Format("2006-01-02"))
+		return ctxerr.Wrap(ctx, err, "insert policy stats snapshot")
+	})
+}
+
+func (ds *Datastore) CleanupPolicyStatsHistory(ctx context.Context, before time.Time) error {
+	_, err := ds.writer.ExecContext(ctx, `DELETE FROM policy_stats_history WHERE snapshot_date < ?`, before.UTC().Format("2006-01-02"))
+	return ctxerr.Wrap(ctx, err, "delete policy stats history")
+}
+
+func (ds *Datastore) ListPolicyStatsHistory(ctx context.Context, opts fleet.PolicyStatsHistoryListOptions) ([]*fleet.PolicyStatsSnapshot, error) {
+	stmt := `
+	SELECT
+		DATE_FORMAT(psh.snapshot_date, '%%Y-%%m-%%d') AS snapshot_date,
+		psh.policy_id,
+		p.name AS policy_name,
+		NULLIF(psh.team_id, 0) AS team_id,
+		COALESCE(t.name, '') AS team_name,
+		psh.passing_host_count,
+		psh.failing_host_count
+	FROM
+		policy_stats_history psh
+	INNER JOIN
+		policies p ON p.id = psh.policy_id
+	LEFT JOIN
+		teams t ON t.id = psh.team_id
+	WHERE
+		%s
+	ORDER BY
+		psh.snapshot_date, psh.policy_id, psh.team_id`
+
+	where := "TRUE"
+	var args []interface{}
+	if opts.PolicyID != nil {
+		where += " AND psh.policy_id = ?"
+		args = append(args, *opts.PolicyID)
+	}
+	if opts.TeamID != nil {
+		where += " AND psh.team_id = ?"
+		args = append(args, *opts.TeamID)
+	}
+	if opts.From != "" {
+		where += " AND psh.snapshot_date >= ?"
+		args = append(args, opts.From)
+	}
+	if opts.To != "" {
+		where += " AND psh.snapshot_date <= ?"
+		args = append(args, opts.To)
+	}
+
+	var snapshots []*fleet.PolicyStatsSnapshot
+	if err := sqlx.SelectContext(ctx, ds.reader, &snapshots, fmt.Sprintf(stmt, where), args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "select policy stats history")
+	}
+	return snapshots, nil
+}
+
 func amountPoliciesDB(ctx context.Context, db sqlx.QueryerContext) (int, error) {
 	var amount int
 	err := sqlx.GetContext(ctx, db, &amount, `SELECT count(*) FROM policies`)
@@ -763,6 +1057,102 @@ func (ds *Datastore) OutdatedAutomationBatch(ctx context.Context) ([]fleet.Polic
 	return failures, nil
 }
 
+const policyAutomationTicketCols = `
+	t.id, t.policy_id, t.host_id, t.integration_type, t.integration_key,
+	t.ticket_id, t.created_at, t.updated_at
+`
+
+func (ds *Datastore) ListPolicyAutomationTickets(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+	stmt := `
+		SELECT ` + policyAutomationTicketCols + `
+		FROM policy_automation_tickets t
+		WHERE t.policy_id = ? AND t.integration_type = ? AND t.integration_key = ?
+		ORDER BY t.id`
+
+	var tickets []*fleet.PolicyAutomationTicket
+	if err := sqlx.SelectContext(ctx, ds.reader, &tickets, stmt, policyID, integrationType, integrationKey); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "listing policy automation tickets")
+	}
+	return tickets, nil
+}
+
+func (ds *Datastore) UpsertPolicyAutomationTickets(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	const stmt = `
+		INSERT INTO policy_automation_tickets
+			(policy_id, host_id, integration_type, integration_key, ticket_id)
+		VALUES %s
+		ON DUPLICATE KEY UPDATE
+			ticket_id = VALUES(ticket_id)`
+
+	args := make([]interface{}, 0, len(tickets)*5)
+	for _, t := range tickets {
+		args = append(args, t.PolicyID, t.HostID, t.IntegrationType, t.IntegrationKey, t.TicketID)
+	}
+	values := strings.TrimSuffix(strings.Repeat("(?,?,?,?,?),", len(tickets)), ",")
+	if _, err := ds.writer.ExecContext(ctx, fmt.Sprintf(stmt, values), args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "upserting policy automation tickets")
+	}
+	return nil
+}
+
+func (ds *Datastore) ListResolvedPolicyAutomationTickets(ctx context.Context) ([]*fleet.PolicyAutomationTicket, error) {
+	// a ticket's host is resolved if it now passes the policy, if its
+	// membership was removed (e.g. the host changed team) or if the host or
+	// the policy were deleted.
+	stmt := `
+		SELECT ` + policyAutomationTicketCols + `
+		FROM policy_automation_tickets t
+		LEFT JOIN policies p ON p.id = t.policy_id
+		LEFT JOIN hosts h ON h.id = t.host_id
+		LEFT JOIN policy_membership pm ON pm.policy_id = t.policy_id AND pm.host_id = t.host_id
+		WHERE
+			p.id IS NULL OR
+			h.id IS NULL OR
+			pm.policy_id IS NULL OR
+			pm.passes = 1
+		ORDER BY t.id`
+
+	var tickets []*fleet.PolicyAutomationTicket
+	if err := sqlx.SelectContext(ctx, ds.reader, &tickets, stmt); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "listing resolved policy automation tickets")
+	}
+	return tickets, nil
+}
+
+func (ds *Datastore) DeletePolicyAutomationTickets(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stmt, args, err := sqlx.In(`DELETE FROM policy_automation_tickets WHERE id IN (?)`, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "building delete policy automation tickets query")
+	}
+	if _, err := ds.writer.ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "deleting policy automation tickets")
+	}
+	return nil
+}
+
+func (ds *Datastore) CountPolicyAutomationTicketHosts(ctx context.Context, integrationType, integrationKey, ticketID string) (int, error) {
+	const stmt = `
+		SELECT COUNT(*)
+		FROM policy_automation_tickets
+		WHERE integration_type = ? AND integration_key = ? AND ticket_id = ?`
+
+	var count int
+	// use the writer, as this is typically called right after deleting
+	// resolved tickets.
+	if err := sqlx.GetContext(ctx, ds.writer, &count, stmt, integrationType, integrationKey, ticketID); err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "counting policy automation ticket hosts")
+	}
+	return count, nil
+}
+
 func incrementViolationDaysDB(ctx context.Context, tx sqlx.ExtContext) error {
 	const (
 		statsID        = 0
@@ -47,6 +47,10 @@ func TestPolicies(t *testing.T) {
 		{"PolicyViolationDays", testPolicyViolationDays},
 		{"IncreasePolicyAutomationIteration", testIncreasePolicyAutomationIteration},
 		{"OutdatedAutomationBatch", testOutdatedAutomationBatch},
+		{"PolicyAutomationTickets", testPolicyAutomationTickets},
+		{"PolicyMetadata", testPolicyMetadata},
+		{"PolicyComplianceSummaries", testPolicyComplianceSummaries},
+		{"PolicyStatsHistory", testPolicyStatsHistory},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -88,7 +92,7 @@ func testPoliciesNewGlobalPolicyLegacy(t *testing.T, ds *Datastore) {
 	})
 	require.NoError(t, err)
 
-	policies, err := ds.ListGlobalPolicies(context.Background())
+	policies, err := ds.ListGlobalPolicies(context.Background(), fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 2)
 	assert.Equal(t, q.Name, policies[0].Name)
@@ -106,7 +110,7 @@ func testPoliciesNewGlobalPolicyLegacy(t *testing.T, ds *Datastore) {
 	_, err = ds.DeleteGlobalPolicies(context.Background(), []uint{policies[0].ID, policies[1].ID})
 	require.NoError(t, err)
 
-	policies, err = ds.ListGlobalPolicies(context.Background())
+	policies, err = ds.ListGlobalPolicies(context.Background(), fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 0)
 }
@@ -138,7 +142,7 @@ func testPoliciesNewGlobalPolicyProprietary(t *testing.T, ds *Datastore) {
 	})
 	require.NoError(t, err)
 
-	policies, err := ds.ListGlobalPolicies(ctx)
+	policies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 2)
 	assert.Equal(t, "query1", policies[0].Name)
@@ -173,7 +177,7 @@ func testPoliciesNewGlobalPolicyProprietary(t *testing.T, ds *Datastore) {
 	_, err = ds.DeleteGlobalPolicies(ctx, []uint{policies[0].ID, policies[1].ID})
 	require.NoError(t, err)
 
-	policies, err = ds.ListGlobalPolicies(ctx)
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 0)
 
@@ -267,7 +271,7 @@ func testPoliciesMembershipView(deferred bool, t *testing.T, ds *Datastore) {
 
 	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host2, map[uint]*bool{p2.ID: nil}, time.Now(), deferred))
 
-	policies, err := ds.ListGlobalPolicies(ctx)
+	policies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 2)
 
@@ -282,7 +286,7 @@ func testPoliciesMembershipView(deferred bool, t *testing.T, ds *Datastore) {
 	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host1, map[uint]*bool{p.ID: ptr.Bool(false)}, time.Now(), deferred))
 	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host2, map[uint]*bool{p2.ID: ptr.Bool(false)}, time.Now(), deferred))
 
-	policies, err = ds.ListGlobalPolicies(ctx)
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 2)
 
@@ -339,7 +343,7 @@ func testPoliciesMembershipView(deferred bool, t *testing.T, ds *Datastore) {
 	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host4, map[uint]*bool{t2pol.ID: ptr.Bool(false), t2pol2.ID: ptr.Bool(true), p.ID: ptr.Bool(false)}, time.Now(), deferred))
 	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host5, map[uint]*bool{t2pol.ID: ptr.Bool(true), t2pol2.ID: ptr.Bool(true), p2.ID: ptr.Bool(true)}, time.Now(), deferred))
 
-	t1Pols, t1Inherited, err := ds.ListTeamPolicies(ctx, team1.ID)
+	t1Pols, t1Inherited, err := ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, t1Pols, 1)
 	assert.Equal(t, uint(1), t1Pols[0].PassingHostCount)
@@ -353,7 +357,7 @@ func testPoliciesMembershipView(deferred bool, t *testing.T, ds *Datastore) {
 	assert.Equal(t, uint(0), t1Inherited[1].PassingHostCount)
 	assert.Equal(t, uint(1), t1Inherited[1].FailingHostCount)
 
-	t2Pols, t2Inherited, err := ds.ListTeamPolicies(ctx, team2.ID)
+	t2Pols, t2Inherited, err := ds.ListTeamPolicies(ctx, team2.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, t2Pols, 2)
 	require.Equal(t, t2pol.ID, t2Pols[0].ID)
@@ -398,7 +402,7 @@ func testTeamPolicyLegacy(t *testing.T, ds *Datastore) {
 	})
 	require.NoError(t, err)
 
-	prevPolicies, err := ds.ListGlobalPolicies(ctx)
+	prevPolicies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, prevPolicies, 0)
 
@@ -428,7 +432,7 @@ func testTeamPolicyLegacy(t *testing.T, ds *Datastore) {
 	})
 	require.NoError(t, err)
 
-	globalPolicies, err := ds.ListGlobalPolicies(ctx)
+	globalPolicies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, globalPolicies, 1)
 
@@ -443,7 +447,7 @@ func testTeamPolicyLegacy(t *testing.T, ds *Datastore) {
 	require.NotNil(t, p2.AuthorID)
 	assert.Equal(t, user1.ID, *p2.AuthorID)
 
-	teamPolicies, inherited1, err := ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, inherited1, err := ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 1)
 	assert.Equal(t, q.Name, teamPolicies[0].Name)
@@ -455,7 +459,7 @@ func testTeamPolicyLegacy(t *testing.T, ds *Datastore) {
 	require.Len(t, inherited1, 1)
 	require.Equal(t, gpol, inherited1[0])
 
-	team2Policies, inherited2, err := ds.ListTeamPolicies(ctx, team2.ID)
+	team2Policies, inherited2, err := ds.ListTeamPolicies(ctx, team2.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, team2Policies, 1)
 	assert.Equal(t, q2.Name, team2Policies[0].Name)
@@ -470,7 +474,7 @@ func testTeamPolicyLegacy(t *testing.T, ds *Datastore) {
 	_, err = ds.DeleteTeamPolicies(ctx, team1.ID, []uint{teamPolicies[0].ID})
 	require.NoError(t, err)
 
-	teamPolicies, inherited1, err = ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, inherited1, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 0)
 	require.Len(t, inherited1, 1)
@@ -492,7 +496,7 @@ func testTeamPolicyProprietary(t *testing.T, ds *Datastore) {
 	})
 	require.NoError(t, err)
 
-	prevPolicies, err := ds.ListGlobalPolicies(ctx)
+	prevPolicies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, prevPolicies, 1)
 
@@ -548,7 +552,7 @@ func testTeamPolicyProprietary(t *testing.T, ds *Datastore) {
 	require.NotNil(t, p.AuthorID)
 	assert.Equal(t, user1.ID, *p.AuthorID)
 
-	globalPolicies, err := ds.ListGlobalPolicies(ctx)
+	globalPolicies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, globalPolicies, len(prevPolicies))
 
@@ -568,7 +572,7 @@ func testTeamPolicyProprietary(t *testing.T, ds *Datastore) {
 	require.NotNil(t, p2.AuthorID)
 	assert.Equal(t, user1.ID, *p2.AuthorID)
 
-	teamPolicies, inherited1, err := ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, inherited1, err := ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 1)
 	assert.Equal(t, "query1", teamPolicies[0].Name)
@@ -582,7 +586,7 @@ func testTeamPolicyProprietary(t *testing.T, ds *Datastore) {
 	require.Len(t, inherited1, 1)
 	require.Equal(t, gpol, inherited1[0])
 
-	team2Policies, inherited2, err := ds.ListTeamPolicies(ctx, team2.ID)
+	team2Policies, inherited2, err := ds.ListTeamPolicies(ctx, team2.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, team2Policies, 1)
 	assert.Equal(t, "query2", team2Policies[0].Name)
@@ -609,7 +613,7 @@ func testTeamPolicyProprietary(t *testing.T, ds *Datastore) {
 	_, err = ds.DeleteTeamPolicies(ctx, team1.ID, []uint{teamPolicies[0].ID})
 	require.NoError(t, err)
 
-	teamPolicies, inherited1, err = ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, inherited1, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 0)
 	require.Len(t, inherited1, 1)
@@ -624,7 +628,7 @@ func testTeamPolicyProprietary(t *testing.T, ds *Datastore) {
 	})
 	require.NoError(t, err)
 
-	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 1)
 	assert.Equal(t, "query1", teamPolicies[0].Name)
@@ -1085,20 +1089,20 @@ func testTeamPolicyTransfer(t *testing.T, ds *Datastore) {
 	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host2, map[uint]*bool{team1Policy.ID: ptr.Bool(true), globalPolicy.ID: ptr.Bool(true)}, time.Now(), false))
 
 	checkPassingCount := func(tm1, tm1Inherited, tm2Inherited, global uint) {
-		policies, inherited, err := ds.ListTeamPolicies(ctx, team1.ID)
+		policies, inherited, err := ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 		require.NoError(t, err)
 		require.Len(t, policies, 1)
 		assert.Equal(t, tm1, policies[0].PassingHostCount)
 		require.Len(t, inherited, 1)
 		assert.Equal(t, tm1Inherited, inherited[0].PassingHostCount)
 
-		policies, inherited, err = ds.ListTeamPolicies(ctx, team2.ID)
+		policies, inherited, err = ds.ListTeamPolicies(ctx, team2.ID, fleet.PolicyListOptions{})
 		require.NoError(t, err)
 		require.Len(t, policies, 0) // team 2 has no policies of its own
 		require.Len(t, inherited, 1)
 		assert.Equal(t, tm2Inherited, inherited[0].PassingHostCount)
 
-		policies, err = ds.ListGlobalPolicies(ctx)
+		policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 		require.NoError(t, err)
 		require.Len(t, policies, 1)
 		assert.Equal(t, global, policies[0].PassingHostCount)
@@ -1166,7 +1170,7 @@ func testApplyPolicySpec(t *testing.T, ds *Datastore) {
 		},
 	}))
 
-	policies, err := ds.ListGlobalPolicies(ctx)
+	policies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 1)
 	assert.Equal(t, "query1", policies[0].Name)
@@ -1178,7 +1182,7 @@ func testApplyPolicySpec(t *testing.T, ds *Datastore) {
 	assert.Equal(t, "some resolution", *policies[0].Resolution)
 	assert.Equal(t, "", policies[0].Platform)
 
-	teamPolicies, _, err := ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, _, err := ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 2)
 	assert.Equal(t, "query2", teamPolicies[0].Name)
@@ -1227,10 +1231,10 @@ func testApplyPolicySpec(t *testing.T, ds *Datastore) {
 		},
 	}))
 
-	policies, err = ds.ListGlobalPolicies(ctx)
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 1)
-	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 2)
 
@@ -1253,7 +1257,7 @@ func testApplyPolicySpec(t *testing.T, ds *Datastore) {
 			Platform:    "windows",
 		},
 	}))
-	policies, err = ds.ListGlobalPolicies(ctx)
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, policies, 1)
 
@@ -1266,7 +1270,7 @@ func testApplyPolicySpec(t *testing.T, ds *Datastore) {
 	assert.Equal(t, "some resolution updated", *policies[0].Resolution)
 	assert.Equal(t, "", policies[0].Platform)
 
-	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID)
+	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, teamPolicies, 2)
 
@@ -1645,11 +1649,11 @@ func testPolicyPlatformUpdate(t *testing.T, ds *Datastore) {
 	require.NoError(t, err)
 
 	// load the global policies
-	gpols, err := ds.ListGlobalPolicies(ctx)
+	gpols, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, gpols, 2)
 	// load the team policies
-	tpols, _, err := ds.ListTeamPolicies(ctx, tm.ID)
+	tpols, _, err := ds.ListTeamPolicies(ctx, tm.ID, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	require.Len(t, tpols, 2)
 
@@ -2174,3 +2178,401 @@ func testOutdatedAutomationBatch(t *testing.T, ds *Datastore) {
 	require.NoError(t, err)
 	require.ElementsMatch(t, batch, []fleet.PolicyFailure{})
 }
+
+func testPolicyAutomationTickets(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	h1, err := ds.NewHost(ctx, &fleet.Host{OsqueryHostID: ptr.String("host1"), NodeKey: ptr.String("host1")})
+	require.NoError(t, err)
+	h2, err := ds.NewHost(ctx, &fleet.Host{OsqueryHostID: ptr.String("host2"), NodeKey: ptr.String("host2")})
+	require.NoError(t, err)
+	h3, err := ds.NewHost(ctx, &fleet.Host{OsqueryHostID: ptr.String("host3"), NodeKey: ptr.String("host3")})
+	require.NoError(t, err)
+
+	pol1, err := ds.NewGlobalPolicy(ctx, nil, fleet.PolicyPayload{Name: "policy1"})
+	require.NoError(t, err)
+	pol2, err := ds.NewGlobalPolicy(ctx, nil, fleet.PolicyPayload{Name: "policy2"})
+	require.NoError(t, err)
+
+	for _, h := range []*fleet.Host{h1, h2, h3} {
+		err = ds.RecordPolicyQueryExecutions(ctx, h, map[uint]*bool{pol1.ID: ptr.Bool(false), pol2.ID: ptr.Bool(false)}, time.Now(), false)
+		require.NoError(t, err)
+	}
+
+	const jiraKey = "https://jira.example.com\nPROJ"
+	tickets, err := ds.ListPolicyAutomationTickets(ctx, pol1.ID, "jira", jiraKey)
+	require.NoError(t, err)
+	require.Empty(t, tickets)
+
+	// no-op with no tickets
+	require.NoError(t, ds.UpsertPolicyAutomationTickets(ctx, nil))
+
+	err = ds.UpsertPolicyAutomationTickets(ctx, []*fleet.PolicyAutomationTicket{
+		{PolicyID: pol1.ID, HostID: h1.ID, IntegrationType: "jira", IntegrationKey: jiraKey, TicketID: "PROJ-1"},
+		{PolicyID: pol1.ID, HostID: h2.ID, IntegrationType: "jira", IntegrationKey: jiraKey, TicketID: "PROJ-1"},
+		{PolicyID: pol2.ID, HostID: h3.ID, IntegrationType: "jira", IntegrationKey: jiraKey, TicketID: "PROJ-2"},
+		{PolicyID: pol1.ID, HostID: h1.ID, IntegrationType: "zendesk", IntegrationKey: "https://zendesk.example.com\n1", TicketID: "123"},
+	})
+	require.NoError(t, err)
+
+	tickets, err = ds.ListPolicyAutomationTickets(ctx, pol1.ID, "jira", jiraKey)
+	require.NoError(t, err)
+	require.Len(t, tickets, 2)
+	require.Equal(t, h1.ID, tickets[0].HostID)
+	require.Equal(t, "PROJ-1", tickets[0].TicketID)
+	require.Equal(t, h2.ID, tickets[1].HostID)
+	require.Equal(t, "PROJ-1", tickets[1].TicketID)
+
+	// upserting an existing host updates its ticket
+	err = ds.UpsertPolicyAutomationTickets(ctx, []*fleet.PolicyAutomationTicket{
+		{PolicyID: pol1.ID, HostID: h2.ID, IntegrationType: "jira", IntegrationKey: jiraKey, TicketID: "PROJ-3"},
+		{PolicyID: pol1.ID, HostID: h3.ID, IntegrationType: "jira", IntegrationKey: jiraKey, TicketID: "PROJ-3"},
+	})
+	require.NoError(t, err)
+	tickets, err = ds.ListPolicyAutomationTickets(ctx, pol1.ID, "jira", jiraKey)
+	require.NoError(t, err)
+	require.Len(t, tickets, 3)
+	require.Equal(t, "PROJ-1", tickets[0].TicketID)
+	require.Equal(t, "PROJ-3", tickets[1].TicketID)
+	require.Equal(t, "PROJ-3", tickets[2].TicketID)
+
+	count, err := ds.CountPolicyAutomationTicketHosts(ctx, "jira", jiraKey, "PROJ-3")
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	count, err = ds.CountPolicyAutomationTicketHosts(ctx, "jira", "other", "PROJ-3")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// nothing is resolved yet
+	resolved, err := ds.ListResolvedPolicyAutomationTickets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, resolved)
+
+	// h1 now passes policy 1, h3 is deleted
+	err = ds.RecordPolicyQueryExecutions(ctx, h1, map[uint]*bool{pol1.ID: ptr.Bool(true), pol2.ID: ptr.Bool(false)}, time.Now(), false)
+	require.NoError(t, err)
+	require.NoError(t, ds.DeleteHost(ctx, h3.ID))
+
+	resolved, err = ds.ListResolvedPolicyAutomationTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, resolved, 4)
+	got := make([]string, 0, len(resolved))
+	ids := make([]uint, 0, len(resolved))
+	for _, r := range resolved {
+		got = append(got, fmt.Sprintf("%d:%d:%s:%s", r.PolicyID, r.HostID, r.IntegrationType, r.TicketID))
+		ids = append(ids, r.ID)
+	}
+	require.ElementsMatch(t, []string{
+		fmt.Sprintf("%d:%d:jira:PROJ-1", pol1.ID, h1.ID),
+		fmt.Sprintf("%d:%d:zendesk:123", pol1.ID, h1.ID),
+		fmt.Sprintf("%d:%d:jira:PROJ-3", pol1.ID, h3.ID),
+		fmt.Sprintf("%d:%d:jira:PROJ-2", pol2.ID, h3.ID),
+	}, got)
+
+	require.NoError(t, ds.DeletePolicyAutomationTickets(ctx, ids))
+	resolved, err = ds.ListResolvedPolicyAutomationTickets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, resolved)
+
+	count, err = ds.CountPolicyAutomationTicketHosts(ctx, "jira", jiraKey, "PROJ-1")
+	require.NoError(t, err)
+	require.Zero(t, count)
+	count, err = ds.CountPolicyAutomationTicketHosts(ctx, "jira", jiraKey, "PROJ-3")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// deleting the policy resolves its remaining tickets
+	_, err = ds.DeleteGlobalPolicies(ctx, []uint{pol1.ID})
+	require.NoError(t, err)
+	resolved, err = ds.ListResolvedPolicyAutomationTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, resolved, 1)
+	require.Equal(t, h2.ID, resolved[0].HostID)
+	require.Equal(t, "PROJ-3", resolved[0].TicketID)
+}
+
+func testPolicyMetadata(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team1, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	gp, err := ds.NewGlobalPolicy(ctx, &user.ID, fleet.PolicyPayload{
+		Name:     "global",
+		Query:    "SELECT 1",
+		Severity: fleet.PolicySeverityHigh,
+		Tags:     []string{"encryption", "disk"},
+		Compliance: []fleet.PolicyComplianceControl{
+			{Framework: "CIS", Control: "1.2.3"},
+			{Framework: "NIST 800-53", Control: "AC-2"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fleet.PolicySeverityHigh, gp.Severity)
+	assert.Equal(t, []string{"disk", "encryption"}, gp.Tags)
+	assert.Equal(t, []fleet.PolicyComplianceControl{
+		{Framework: "CIS", Control: "1.2.3"},
+		{Framework: "NIST 800-53", Control: "AC-2"},
+	}, gp.Compliance)
+
+	gp2, err := ds.NewGlobalPolicy(ctx, &user.ID, fleet.PolicyPayload{
+		Name:  "global2",
+		Query: "SELECT 2",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, gp2.Severity)
+	assert.Empty(t, gp2.Tags)
+	assert.Empty(t, gp2.Compliance)
+
+	tp, err := ds.NewTeamPolicy(ctx, team1.ID, &user.ID, fleet.PolicyPayload{
+		Name:       "team",
+		Query:      "SELECT 3",
+		Severity:   fleet.PolicySeverityLow,
+		Tags:       []string{"disk"},
+		Compliance: []fleet.PolicyComplianceControl{{Framework: "CIS", Control: "2.1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"disk"}, tp.Tags)
+
+	policyIDs := func(policies []*fleet.Policy) []uint {
+		var ids []uint
+		for _, p := range policies {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	// filter global policies
+	policies, err := ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []uint{gp.ID, gp2.ID}, policyIDs(policies))
+	require.Equal(t, gp.Compliance, policies[0].Compliance)
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{Severity: fleet.PolicySeverityHigh})
+	require.NoError(t, err)
+	require.Equal(t, []uint{gp.ID}, policyIDs(policies))
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{Tag: "disk", ComplianceFramework: "NIST 800-53"})
+	require.NoError(t, err)
+	require.Equal(t, []uint{gp.ID}, policyIDs(policies))
+	policies, err = ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{Tag: "no-such-tag"})
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	// filter team policies, the filter also applies to inherited policies
+	teamPolicies, inherited, err := ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{ComplianceFramework: "CIS"})
+	require.NoError(t, err)
+	require.Equal(t, []uint{tp.ID}, policyIDs(teamPolicies))
+	require.Equal(t, []uint{gp.ID}, policyIDs(inherited))
+	teamPolicies, inherited, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{Severity: fleet.PolicySeverityHigh})
+	require.NoError(t, err)
+	require.Empty(t, teamPolicies)
+	require.Equal(t, []uint{gp.ID}, policyIDs(inherited))
+
+	// save replaces the metadata
+	gp.Severity = fleet.PolicySeverityCritical
+	gp.Tags = []string{"firewall"}
+	gp.Compliance = nil
+	require.NoError(t, ds.SavePolicy(ctx, gp))
+	gp, err = ds.Policy(ctx, gp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fleet.PolicySeverityCritical, gp.Severity)
+	assert.Equal(t, []string{"firewall"}, gp.Tags)
+	assert.Empty(t, gp.Compliance)
+
+	byID, err := ds.PoliciesByID(ctx, []uint{gp.ID, tp.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"firewall"}, byID[gp.ID].Tags)
+	assert.Equal(t, []fleet.PolicyComplianceControl{{Framework: "CIS", Control: "2.1"}}, byID[tp.ID].Compliance)
+
+	// apply specs creates and replaces the metadata
+	require.NoError(t, ds.ApplyPolicySpecs(ctx, user.ID, []*fleet.PolicySpec{
+		{
+			Name:       "global",
+			Query:      "SELECT 1",
+			Severity:   fleet.PolicySeverityMedium,
+			Compliance: []fleet.PolicyComplianceControl{{Framework: "CIS", Control: "9.9"}},
+		},
+		{
+			Name:     "spec",
+			Query:    "SELECT 4",
+			Team:     "team1",
+			Severity: fleet.PolicySeverityLow,
+			Tags:     []string{"spec"},
+		},
+	}))
+	gp, err = ds.Policy(ctx, gp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fleet.PolicySeverityMedium, gp.Severity)
+	assert.Empty(t, gp.Tags)
+	assert.Equal(t, []fleet.PolicyComplianceControl{{Framework: "CIS", Control: "9.9"}}, gp.Compliance)
+	teamPolicies, _, err = ds.ListTeamPolicies(ctx, team1.ID, fleet.PolicyListOptions{Tag: "spec"})
+	require.NoError(t, err)
+	require.Len(t, teamPolicies, 1)
+	assert.Equal(t, "spec", teamPolicies[0].Name)
+	assert.Equal(t, fleet.PolicySeverityLow, teamPolicies[0].Severity)
+
+	// host policies include the metadata
+	host, err := ds.NewHost(ctx, &fleet.Host{
+		OsqueryHostID:   ptr.String("1"),
+		NodeKey:         ptr.String("1"),
+		DetailUpdatedAt: time.Now(),
+		LabelUpdatedAt:  time.Now(),
+		PolicyUpdatedAt: time.Now(),
+		SeenTime:        time.Now(),
+		Platform:        "darwin",
+	})
+	require.NoError(t, err)
+	hostPolicies, err := ds.ListPoliciesForHost(ctx, host)
+	require.NoError(t, err)
+	require.Len(t, hostPolicies, 2)
+	for _, hp := range hostPolicies {
+		if hp.ID == gp.ID {
+			assert.Equal(t, gp.Compliance, hp.Compliance)
+			assert.Equal(t, fleet.PolicySeverityMedium, hp.Severity)
+		}
+	}
+
+	// deleting the policy deletes its metadata
+	_, err = ds.DeleteTeamPolicies(ctx, team1.ID, []uint{tp.ID})
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, sqlx.GetContext(ctx, ds.reader, &count, `SELECT COUNT(*) FROM policy_tags WHERE policy_id = ?`, tp.ID))
+	require.Zero(t, count)
+}
+
+func testPolicyComplianceSummaries(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team1, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	summaries, err := ds.ListPolicyComplianceSummaries(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, summaries)
+
+	// p1 is mapped to two CIS controls, it must only be counted once
+	p1, err := ds.NewGlobalPolicy(ctx, &user.ID, fleet.PolicyPayload{
+		Name:  "p1",
+		Query: "SELECT 1",
+		Compliance: []fleet.PolicyComplianceControl{
+			{Framework: "CIS", Control: "1.1"},
+			{Framework: "CIS", Control: "1.2"},
+			{Framework: "NIST", Control: "AC-2"},
+		},
+	})
+	require.NoError(t, err)
+	p2, err := ds.NewTeamPolicy(ctx, team1.ID, &user.ID, fleet.PolicyPayload{
+		Name:       "p2",
+		Query:      "SELECT 2",
+		Compliance: []fleet.PolicyComplianceControl{{Framework: "CIS", Control: "2.1"}},
+	})
+	require.NoError(t, err)
+	// p3 is not mapped to any framework
+	p3, err := ds.NewGlobalPolicy(ctx, &user.ID, fleet.PolicyPayload{
+		Name:  "p3",
+		Query: "SELECT 3",
+	})
+	require.NoError(t, err)
+
+	host1, err := ds.EnrollHost(ctx, false, "1", "", "", "1", nil, 0)
+	require.NoError(t, err)
+	host2, err := ds.EnrollHost(ctx, false, "2", "", "", "2", &team1.ID, 0)
+	require.NoError(t, err)
+	host3, err := ds.EnrollHost(ctx, false, "3", "", "", "3", &team1.ID, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host1, map[uint]*bool{p1.ID: ptr.Bool(false), p3.ID: ptr.Bool(true)}, time.Now(), false))
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host2, map[uint]*bool{p1.ID: ptr.Bool(true), p2.ID: ptr.Bool(true)}, time.Now(), false))
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host3, map[uint]*bool{p1.ID: ptr.Bool(true), p2.ID: ptr.Bool(false), p3.ID: nil}, time.Now(), false))
+
+	summaries, err = ds.ListPolicyComplianceSummaries(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.PolicyComplianceSummary{
+		{TeamID: nil, TeamName: "", Framework: "CIS", PolicyCount: 1, PassingCount: 0, FailingCount: 1, CompliancePercentage: 0},
+		{TeamID: nil, TeamName: "", Framework: "NIST", PolicyCount: 1, PassingCount: 0, FailingCount: 1, CompliancePercentage: 0},
+		{TeamID: &team1.ID, TeamName: "team1", Framework: "CIS", PolicyCount: 2, PassingCount: 3, FailingCount: 1, CompliancePercentage: 75},
+		{TeamID: &team1.ID, TeamName: "team1", Framework: "NIST", PolicyCount: 1, PassingCount: 2, FailingCount: 0, CompliancePercentage: 100},
+	}, summaries)
+
+	summaries, err = ds.ListPolicyComplianceSummaries(ctx, &team1.ID)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	require.Equal(t, "CIS", summaries[0].Framework)
+	require.Equal(t, float64(75), summaries[0].CompliancePercentage)
+}
+
+func testPolicyStatsHistory(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	user := test.NewUser(t, ds, "Alice", "alice@example.com", true)
+	team1, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	p1, err := ds.NewGlobalPolicy(ctx, &user.ID, fleet.PolicyPayload{Name: "p1", Query: "SELECT 1"})
+	require.NoError(t, err)
+	p2, err := ds.NewTeamPolicy(ctx, team1.ID, &user.ID, fleet.PolicyPayload{Name: "p2", Query: "SELECT 2"})
+	require.NoError(t, err)
+
+	host1, err := ds.EnrollHost(ctx, false, "1", "", "", "1", nil, 0)
+	require.NoError(t, err)
+	host2, err := ds.EnrollHost(ctx, false, "2", "", "", "2", &team1.ID, 0)
+	require.NoError(t, err)
+	host3, err := ds.EnrollHost(ctx, false, "3", "", "", "3", &team1.ID, 0)
+	require.NoError(t, err)
+
+	day1 := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	// no results yet
+	require.NoError(t, ds.SnapshotPolicyStats(ctx, day1))
+	snapshots, err := ds.ListPolicyStatsHistory(ctx, fleet.PolicyStatsHistoryListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host1, map[uint]*bool{p1.ID: ptr.Bool(false)}, time.Now(), false))
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host2, map[uint]*bool{p1.ID: ptr.Bool(true), p2.ID: ptr.Bool(false)}, time.Now(), false))
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host3, map[uint]*bool{p1.ID: ptr.Bool(true), p2.ID: nil}, time.Now(), false))
+	require.NoError(t, ds.SnapshotPolicyStats(ctx, day1))
+
+	// host2 is now passing p2, the snapshot of the same day is updated
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host2, map[uint]*bool{p1.ID: ptr.Bool(true), p2.ID: ptr.Bool(true)}, time.Now(), false))
+	require.NoError(t, ds.SnapshotPolicyStats(ctx, day1))
+
+	// host1 is now passing p1
+	require.NoError(t, ds.RecordPolicyQueryExecutions(ctx, host1, map[uint]*bool{p1.ID: ptr.Bool(true)}, time.Now(), false))
+	require.NoError(t, ds.SnapshotPolicyStats(ctx, day2))
+
+	snapshots, err = ds.ListPolicyStatsHistory(ctx, fleet.PolicyStatsHistoryListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.PolicyStatsSnapshot{
+		{Date: "2023-03-01", PolicyID: p1.ID, PolicyName: "p1", TeamID: nil, TeamName: "", PassingHostCount: 0, FailingHostCount: 1},
+		{Date: "2023-03-01", PolicyID: p1.ID, PolicyName: "p1", TeamID: &team1.ID, TeamName: "team1", PassingHostCount: 2, FailingHostCount: 0},
+		{Date: "2023-03-01", PolicyID: p2.ID, PolicyName: "p2", TeamID: &team1.ID, TeamName: "team1", PassingHostCount: 1, FailingHostCount: 0},
+		{Date: "2023-03-02", PolicyID: p1.ID, PolicyName: "p1", TeamID: nil, TeamName: "", PassingHostCount: 1, FailingHostCount: 0},
+		{Date: "2023-03-02", PolicyID: p1.ID, PolicyName: "p1", TeamID: &team1.ID, TeamName: "team1", PassingHostCount: 2, FailingHostCount: 0},
+		{Date: "2023-03-02", PolicyID: p2.ID, PolicyName: "p2", TeamID: &team1.ID, TeamName: "team1", PassingHostCount: 1, FailingHostCount: 0},
+	}, snapshots)
+
+	// filters
+	snapshots, err = ds.ListPolicyStatsHistory(ctx, fleet.PolicyStatsHistoryListOptions{PolicyID: &p1.ID, TeamID: ptr.Uint(0)})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	require.Equal(t, uint(1), snapshots[0].FailingHostCount)
+	require.Equal(t, uint(1), snapshots[1].PassingHostCount)
+
+	snapshots, err = ds.ListPolicyStatsHistory(ctx, fleet.PolicyStatsHistoryListOptions{TeamID: &team1.ID, From: "2023-03-02"})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	for _, s := range snapshots {
+		require.Equal(t, "2023-03-02", s.Date)
+	}
+
+	snapshots, err = ds.ListPolicyStatsHistory(ctx, fleet.PolicyStatsHistoryListOptions{To: "2023-03-01"})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+
+	// cleanup the snapshots before day2
+	require.NoError(t, ds.CleanupPolicyStatsHistory(ctx, day2))
+	snapshots, err = ds.ListPolicyStatsHistory(ctx, fleet.PolicyStatsHistoryListOptions{})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	require.Equal(t, "2023-03-02", snapshots[0].Date)
+}
@@ -36,15 +36,17 @@ func (ds *Datastore) ApplyQueries(ctx context.Context, authorID uint, queries []
 			query,
 			author_id,
 			saved,
-			observer_can_run
-		) VALUES ( ?, ?, ?, ?, true, ? )
+			observer_can_run,
+			store_results
+		) VALUES ( ?, ?, ?, ?, true, ?, ? )
 		ON DUPLICATE KEY UPDATE
 			name = VALUES(name),
 			description = VALUES(description),
 			query = VALUES(query),
 			author_id = VALUES(author_id),
 			saved = VALUES(saved),
-			observer_can_run = VALUES(observer_can_run)
+			observer_can_run = VALUES(observer_can_run),
+			store_results = VALUES(store_results)
 	`
 	stmt, err := tx.PrepareContext(ctx, sql)
 	if err != nil {
@@ -56,7 +58,10 @@ func (ds *Datastore) ApplyQueries(ctx context.Context, authorID uint, queries []
 		if q.Name == "" {
 			return ctxerr.New(ctx, "query name must not be empty")
 		}
-		_, err := stmt.ExecContext(ctx, q.Name, q.Description, q.Query, authorID, q.ObserverCanRun)
+		if err := discardStaleQueryResultsDB(ctx, tx, discardStaleQueryResultsByNameStmt, q.Name, q.StoreResults, q.Query); err != nil {
+			return err
+		}
+		_, err := stmt.ExecContext(ctx, q.Name, q.Description, q.Query, authorID, q.ObserverCanRun, q.StoreResults)
 		if err != nil {
 			return ctxerr.Wrap(ctx, err, "exec ApplyQueries insert")
 		}
@@ -97,10 +102,11 @@ func (ds *Datastore) NewQuery(ctx context.Context, query *fleet.Query, opts ...f
 			query,
 			saved,
 			author_id,
-			observer_can_run
-		) VALUES ( ?, ?, ?, ?, ?, ? )
+			observer_can_run,
+			store_results
+		) VALUES ( ?, ?, ?, ?, ?, ?, ? )
 	`
-	result, err := ds.writer.ExecContext(ctx, sqlStatement, query.Name, query.Description, query.Query, query.Saved, query.AuthorID, query.ObserverCanRun)
+	result, err := ds.writer.ExecContext(ctx, sqlStatement, query.Name, query.Description, query.Query, query.Saved, query.AuthorID, query.ObserverCanRun, query.StoreResults)
 
 	if err != nil && isDuplicate(err) {
 		return nil, ctxerr.Wrap(ctx, alreadyExists("Query", query.Name))
@@ -116,23 +122,50 @@ func (ds *Datastore) NewQuery(ctx context.Context, query *fleet.Query, opts ...f
 
 // SaveQuery saves changes to a Query.
 func (ds *Datastore) SaveQuery(ctx context.Context, q *fleet.Query) error {
-	sql := `
-		UPDATE queries
-			SET name = ?, description = ?, query = ?, author_id = ?, saved = ?, observer_can_run = ?
-			WHERE id = ?
-	`
-	result, err := ds.writer.ExecContext(ctx, sql, q.Name, q.Description, q.Query, q.AuthorID, q.Saved, q.ObserverCanRun, q.ID)
-	if err != nil {
-		return ctxerr.Wrap(ctx, err, "updating query")
-	}
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return ctxerr.Wrap(ctx, err, "rows affected updating query")
-	}
-	if rows == 0 {
-		return ctxerr.Wrap(ctx, notFound("Query").WithID(q.ID))
-	}
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if err := discardStaleQueryResultsDB(ctx, tx, discardStaleQueryResultsByIDStmt, q.ID, q.StoreResults, q.Query); err != nil {
+			return err
+		}
+
+		sql := `
+			UPDATE queries
+				SET name = ?, description = ?, query = ?, author_id = ?, saved = ?, observer_can_run = ?, store_results = ?
+				WHERE id = ?
+		`
+		result, err := tx.ExecContext(ctx, sql, q.Name, q.Description, q.Query, q.AuthorID, q.Saved, q.ObserverCanRun, q.StoreResults, q.ID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "updating query")
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "rows affected updating query")
+		}
+		if rows == 0 {
+			return ctxerr.Wrap(ctx, notFound("Query").WithID(q.ID))
+		}
+		return nil
+	})
+}
 
+const (
+	discardStaleQueryResultsByIDStmt = `
+		DELETE qr FROM query_results qr
+		JOIN queries q ON q.id = qr.query_id
+		WHERE q.id = ? AND (? = FALSE OR q.query <> ?)`
+	discardStaleQueryResultsByNameStmt = `
+		DELETE qr FROM query_results qr
+		JOIN queries q ON q.id = qr.query_id
+		WHERE q.name = ? AND (? = FALSE OR q.query <> ?)`
+)
+
+// discardStaleQueryResultsDB deletes the stored results of the query identified
+// by the provided statement (by ID or by name) if the query is about to stop
+// storing its results or if its SQL is about to change, as the stored rows
+// would not match the new query. It must be called before the query is updated.
+func discardStaleQueryResultsDB(ctx context.Context, tx sqlx.ExecerContext, stmt string, queryKey interface{}, storeResults bool, newSQL string) error {
+	if _, err := tx.ExecContext(ctx, stmt, queryKey, storeResults, newSQL); err != nil {
+		return ctxerr.Wrap(ctx, err, "discard stale query results")
+	}
 	return nil
 }
 
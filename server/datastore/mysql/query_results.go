@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+func (ds *Datastore) QueryStoringResultsByScheduledName(ctx context.Context, packName, scheduledQueryName string) (*fleet.Query, error) {
+	stmt := `
+		SELECT q.*
+		FROM queries q
+		JOIN scheduled_queries sq ON sq.query_name = q.name
+		JOIN packs p ON p.id = sq.pack_id
+		WHERE p.name = ? AND sq.name = ? AND q.store_results = TRUE
+		LIMIT 1`
+	var query fleet.Query
+	if err := sqlx.GetContext(ctx, ds.reader, &query, stmt, packName, scheduledQueryName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("Query").WithName(packName+"/"+scheduledQueryName))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "select query storing results by scheduled name")
+	}
+	return &query, nil
+}
+
+func (ds *Datastore) OverwriteQueryResultRows(ctx context.Context, queryID, hostID uint, rows []json.RawMessage, fetchedAt time.Time, maxRows int) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM query_results WHERE query_id = ? AND host_id = ?`, queryID, hostID); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete previous query results")
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		var count int
+		if err := sqlx.GetContext(ctx, tx, &count, `SELECT COUNT(*) FROM query_results WHERE query_id = ?`, queryID); err != nil {
+			return ctxerr.Wrap(ctx, err, "count query results")
+		}
+		if remaining := maxRows - count; remaining < len(rows) {
+			if remaining <= 0 {
+				return nil
+			}
+			rows = rows[:remaining]
+		}
+
+		values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?),", len(rows)), ",")
+		args := make([]interface{}, 0, len(rows)*4)
+		for _, row := range rows {
+			args = append(args, queryID, hostID, fetchedAt, row)
+		}
+		stmt := fmt.Sprintf(`INSERT INTO query_results (query_id, host_id, last_fetched, data) VALUES %s`, values)
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert query results")
+		}
+		return nil
+	})
+}
+
+func (ds *Datastore) QueryReport(ctx context.Context, queryID uint, filter fleet.TeamFilter, opts fleet.QueryReportListOptions) ([]*fleet.QueryReportRow, error) {
+	stmt := fmt.Sprintf(`
+		SELECT qr.host_id, h.hostname, qr.last_fetched, qr.data
+		FROM query_results qr
+		JOIN hosts h ON h.id = qr.host_id
+		WHERE qr.query_id = ? AND %s`, ds.whereFilterHostsByTeams(filter, "h"))
+	args := []interface{}{queryID}
+	if opts.HostID != nil {
+		stmt += ` AND qr.host_id = ?`
+		args = append(args, *opts.HostID)
+	}
+	if opts.TeamID != nil {
+		if *opts.TeamID == 0 {
+			stmt += ` AND h.team_id IS NULL`
+		} else {
+			stmt += ` AND h.team_id = ?`
+			args = append(args, *opts.TeamID)
+		}
+	}
+	stmt += ` ORDER BY h.hostname, qr.host_id, qr.id`
+
+	var rows []*fleet.QueryReportRow
+	if err := sqlx.SelectContext(ctx, ds.reader, &rows, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "select query report")
+	}
+	for _, row := range rows {
+		if err := json.Unmarshal(row.Data, &row.Columns); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "unmarshal query result row")
+		}
+	}
+	return rows, nil
+}
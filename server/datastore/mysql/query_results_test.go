@@ -0,0 +1,168 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryResults(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"ByScheduledName", testQueryStoringResultsByScheduledName},
+		{"OverwriteAndReport", testQueryResultsOverwriteAndReport},
+		{"DiscardStale", testQueryResultsDiscardStale},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testQueryStoringResultsByScheduledName(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	pack := test.NewPack(t, ds, "pack1")
+	stored, err := ds.NewQuery(ctx, &fleet.Query{Name: "stored", Query: "SELECT 1", Saved: true, StoreResults: true})
+	require.NoError(t, err)
+	notStored := test.NewQuery(t, ds, "not-stored", "SELECT 2", 0, true)
+	test.NewScheduledQuery(t, ds, pack.ID, stored.ID, 60, true, false, "sched-stored")
+	test.NewScheduledQuery(t, ds, pack.ID, notStored.ID, 60, true, false, "sched-not-stored")
+
+	q, err := ds.QueryStoringResultsByScheduledName(ctx, "pack1", "sched-stored")
+	require.NoError(t, err)
+	require.Equal(t, stored.ID, q.ID)
+	require.True(t, q.StoreResults)
+
+	_, err = ds.QueryStoringResultsByScheduledName(ctx, "pack1", "sched-not-stored")
+	require.True(t, fleet.IsNotFound(err))
+	_, err = ds.QueryStoringResultsByScheduledName(ctx, "no-such-pack", "sched-stored")
+	require.True(t, fleet.IsNotFound(err))
+}
+
+func testQueryResultsOverwriteAndReport(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+	h1 := test.NewHost(t, ds, "h1", "", "h1key", "h1uuid", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "h2key", "h2uuid", time.Now())
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{h2.ID}))
+
+	q, err := ds.NewQuery(ctx, &fleet.Query{Name: "stored", Query: "SELECT 1", Saved: true, StoreResults: true})
+	require.NoError(t, err)
+
+	rows := func(vals ...string) []json.RawMessage {
+		var res []json.RawMessage
+		for _, v := range vals {
+			res = append(res, json.RawMessage(`{"col":"`+v+`"}`))
+		}
+		return res
+	}
+	values := func(report []*fleet.QueryReportRow) []string {
+		var res []string
+		for _, r := range report {
+			res = append(res, r.Hostname+":"+r.Columns["col"])
+		}
+		return res
+	}
+
+	admin := fleet.TeamFilter{User: test.UserAdmin}
+	fetchedAt := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, ds.OverwriteQueryResultRows(ctx, q.ID, h1.ID, rows("a", "b"), fetchedAt, 3))
+	require.NoError(t, ds.OverwriteQueryResultRows(ctx, q.ID, h2.ID, rows("c", "d"), fetchedAt, 3))
+
+	// the rows of h2 are capped
+	report, err := ds.QueryReport(ctx, q.ID, admin, fleet.QueryReportListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h1:a", "h1:b", "h2:c"}, values(report))
+	require.Equal(t, h1.ID, report[0].HostID)
+	require.Equal(t, fetchedAt, report[0].LastFetched.UTC())
+
+	// the new results of h1 replace the previous ones
+	require.NoError(t, ds.OverwriteQueryResultRows(ctx, q.ID, h1.ID, rows("e"), fetchedAt, 3))
+	report, err = ds.QueryReport(ctx, q.ID, admin, fleet.QueryReportListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h1:e", "h2:c"}, values(report))
+
+	// filters
+	report, err = ds.QueryReport(ctx, q.ID, admin, fleet.QueryReportListOptions{HostID: &h2.ID})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h2:c"}, values(report))
+	report, err = ds.QueryReport(ctx, q.ID, admin, fleet.QueryReportListOptions{TeamID: ptr.Uint(0)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h1:e"}, values(report))
+	report, err = ds.QueryReport(ctx, q.ID, admin, fleet.QueryReportListOptions{TeamID: &team.ID})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h2:c"}, values(report))
+
+	// a team user only sees the results of the hosts of their teams
+	teamObserver := &fleet.User{Teams: []fleet.UserTeam{{Team: *team, Role: fleet.RoleObserver}}}
+	report, err = ds.QueryReport(ctx, q.ID, fleet.TeamFilter{User: teamObserver, IncludeObserver: true}, fleet.QueryReportListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h2:c"}, values(report))
+
+	// an empty snapshot clears the results of the host
+	require.NoError(t, ds.OverwriteQueryResultRows(ctx, q.ID, h2.ID, nil, fetchedAt, 3))
+	report, err = ds.QueryReport(ctx, q.ID, admin, fleet.QueryReportListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"h1:e"}, values(report))
+}
+
+func testQueryResultsDiscardStale(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	user := test.NewUser(t, ds, "Zach", "zwass@fleet.co", true)
+	h1 := test.NewHost(t, ds, "h1", "", "h1key", "h1uuid", time.Now())
+	q, err := ds.NewQuery(ctx, &fleet.Query{Name: "stored", Query: "SELECT 1", Saved: true, StoreResults: true})
+	require.NoError(t, err)
+
+	countRows := func() int {
+		var count int
+		require.NoError(t, ds.writer.Get(&count, `SELECT COUNT(*) FROM query_results WHERE query_id = ?`, q.ID))
+		return count
+	}
+	store := func() {
+		require.NoError(t, ds.OverwriteQueryResultRows(ctx, q.ID, h1.ID, []json.RawMessage{json.RawMessage(`{"a":"1"}`)}, time.Now(), 10))
+		require.Equal(t, 1, countRows())
+	}
+
+	// saving without changing the SQL keeps the results
+	store()
+	q.Description = "desc"
+	require.NoError(t, ds.SaveQuery(ctx, q))
+	require.Equal(t, 1, countRows())
+
+	// changing the SQL discards them
+	q.Query = "SELECT 2"
+	require.NoError(t, ds.SaveQuery(ctx, q))
+	require.Zero(t, countRows())
+
+	// disabling the storage discards them
+	store()
+	q.StoreResults = false
+	require.NoError(t, ds.SaveQuery(ctx, q))
+	require.Zero(t, countRows())
+
+	// same when applying a spec
+	q.StoreResults = true
+	require.NoError(t, ds.SaveQuery(ctx, q))
+	store()
+	require.NoError(t, ds.ApplyQueries(ctx, user.ID, []*fleet.Query{{Name: q.Name, Query: q.Query, StoreResults: true}}))
+	require.Equal(t, 1, countRows())
+	require.NoError(t, ds.ApplyQueries(ctx, user.ID, []*fleet.Query{{Name: q.Name, Query: "SELECT 3", StoreResults: true}}))
+	require.Zero(t, countRows())
+}
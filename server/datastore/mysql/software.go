@@ -485,17 +485,24 @@ func selectSoftwareSQL(opts fleet.SoftwareListOptions) (string, []interface{}, e
 		}
 	}
 
+	// the vulnerabilities suppressed by an exception are not listed
 	if opts.VulnerableOnly {
 		ds = ds.
 			Join(
 				goqu.I("software_cve").As("scv"),
-				goqu.On(goqu.I("s.id").Eq(goqu.I("scv.software_id"))),
+				goqu.On(
+					goqu.I("s.id").Eq(goqu.I("scv.software_id")),
+					notSuppressedSoftwareCVE(opts),
+				),
 			)
 	} else {
 		ds = ds.
 			LeftJoin(
 				goqu.I("software_cve").As("scv"),
-				goqu.On(goqu.I("s.id").Eq(goqu.I("scv.software_id"))),
+				goqu.On(
+					goqu.I("s.id").Eq(goqu.I("scv.software_id")),
+					notSuppressedSoftwareCVE(opts),
+				),
 			)
 	}
 
@@ -563,7 +570,10 @@ func selectSoftwareSQL(opts fleet.SoftwareListOptions) (string, []interface{}, e
 		).
 		LeftJoin(
 			goqu.I("software_cve").As("scv"),
-			goqu.On(goqu.I("scv.software_id").Eq(goqu.I("s.id"))),
+			goqu.On(
+				goqu.I("scv.software_id").Eq(goqu.I("s.id")),
+				notSuppressedSoftwareCVE(opts),
+			),
 		).
 		LeftJoin(
 			goqu.I("cve_meta").As("c"),
@@ -719,6 +729,55 @@ func (ds *Datastore) AllSoftwareWithoutCPEIterator(ctx context.Context, excluded
 	return &softwareIterator{rows: rows}, nil
 }
 
+// AllSoftwareWithCPEIterator Returns an iterator for the 'software' table, filtering out
+// software entries without CPEs and from the sources included in the 'excludedSources' param.
+func (ds *Datastore) AllSoftwareWithCPEIterator(ctx context.Context, excludedSources []string) (fleet.SoftwareIterator, error) {
+	var err error
+	var args []interface{}
+
+	stmt := `SELECT s.* FROM software s WHERE EXISTS (SELECT 1 FROM software_cpe sc WHERE sc.software_id = s.id)`
+	// The rows.Close call is done by the caller once iteration using the
+	// returned fleet.SoftwareIterator is done.
+	if excludedSources != nil {
+		stmt += ` AND s.source NOT IN (?)`
+		stmt, args, err = sqlx.In(stmt, excludedSources)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "loads cpes")
+		}
+	}
+
+	rows, err := ds.reader.QueryxContext(ctx, stmt, args...) //nolint:sqlclosecheck
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "load software with cpe")
+	}
+	return &softwareIterator{rows: rows}, nil
+}
+
+func (ds *Datastore) DeleteSoftwareCPEs(ctx context.Context, softwareIDs []uint) error {
+	if len(softwareIDs) == 0 {
+		return nil
+	}
+
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		stmt, args, err := sqlx.In(`DELETE FROM software_cpe WHERE software_id IN (?)`, softwareIDs)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "build delete software cpes")
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete software cpes")
+		}
+
+		stmt, args, err = sqlx.In(`DELETE FROM software_cve WHERE software_id IN (?) AND source = ?`, softwareIDs, fleet.NVDSource)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "build delete software cves")
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete software cves")
+		}
+		return nil
+	})
+}
+
 func (ds *Datastore) AddCPEForSoftware(ctx context.Context, software fleet.Software, cpe string) error {
 	_, err := addCPEForSoftwareDB(ctx, ds.writer, software, cpe)
 	return err
@@ -797,9 +856,13 @@ func (ds *Datastore) SoftwareByID(ctx context.Context, id uint, includeCVEScores
 				goqu.I("s.id").Eq(goqu.I("scp.software_id")),
 			),
 		).
+		// the vulnerabilities suppressed for all hosts are not listed
 		LeftJoin(
 			goqu.I("software_cve").As("scv"),
-			goqu.On(goqu.I("s.id").Eq(goqu.I("scv.software_id"))),
+			goqu.On(
+				goqu.I("s.id").Eq(goqu.I("scv.software_id")),
+				notSuppressedSoftwareCVE(fleet.SoftwareListOptions{}),
+			),
 		)
 
 	if includeCVEScores {
@@ -1000,11 +1063,9 @@ func (ds *Datastore) SyncHostsSoftware(ctx context.Context, updatedAt time.Time)
 	return nil
 }
 
-// HostsBySoftwareIDs returns a list of all hosts that have at least one of the specified Software
-// installed. It returns a minimal represention of matching hosts.
-func (ds *Datastore) HostsBySoftwareIDs(ctx context.Context, softwareIDs []uint) ([]*fleet.HostShort, error) {
+func (ds *Datastore) VulnerableHostsBySoftwareIDs(ctx context.Context, softwareIDs []uint, cve string) ([]*fleet.HostShort, error) {
 	queryStmt := `
-    SELECT 
+    SELECT
       h.id,
       h.hostname,
       if(h.computer_name = '', h.hostname, h.computer_name) display_name
@@ -1014,19 +1075,24 @@ func (ds *Datastore) HostsBySoftwareIDs(ctx context.Context, softwareIDs []uint)
       host_software hs
     ON
       h.id = hs.host_id
+    INNER JOIN
+      software_cve scv
+    ON
+      scv.software_id = hs.software_id AND scv.cve = ?
     WHERE
-      hs.software_id IN (?)
-	GROUP BY h.id, h.hostname
+      hs.software_id IN (?) AND
+      NOT ` + suppressedOnHostSQL + `
+    GROUP BY h.id, h.hostname
     ORDER BY
       h.id`
 
-	stmt, args, err := sqlx.In(queryStmt, softwareIDs)
+	stmt, args, err := sqlx.In(queryStmt, cve, softwareIDs)
 	if err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "building query args")
 	}
 	var hosts []*fleet.HostShort
 	if err := sqlx.SelectContext(ctx, ds.reader, &hosts, stmt, args...); err != nil {
-		return nil, ctxerr.Wrap(ctx, err, "select hosts by cpes")
+		return nil, ctxerr.Wrap(ctx, err, "select vulnerable hosts by software ids")
 	}
 	return hosts, nil
 }
@@ -1042,7 +1108,8 @@ FROM
     INNER JOIN host_software hs ON h.id = hs.host_id
     INNER JOIN software_cve scv ON scv.software_id = hs.software_id
 WHERE
-    scv.cve = ?
+    scv.cve = ? AND
+    NOT ` + suppressedOnHostSQL + `
 ORDER BY
     h.id
 `
@@ -1164,6 +1231,58 @@ func (ds *Datastore) ListSoftwareVulnerabilitiesByHostIDsSource(
 	return result, nil
 }
 
+func (ds *Datastore) ListSoftwareVulnerabilitiesBySource(
+	ctx context.Context,
+	source fleet.VulnerabilitySource,
+) ([]fleet.SoftwareVulnerability, error) {
+	var result []fleet.SoftwareVulnerability
+
+	stmt := dialect.
+		From(goqu.T("software_cve").As("sc")).
+		Select(
+			goqu.I("sc.software_id"),
+			goqu.I("sc.cve"),
+		).
+		Where(goqu.I("sc.source").Eq(source))
+
+	sql, args, err := stmt.ToSQL()
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "error generating SQL statement")
+	}
+
+	if err := sqlx.SelectContext(ctx, ds.reader, &result, sql, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "error executing SQL statement")
+	}
+
+	return result, nil
+}
+
+func (ds *Datastore) ListSoftwareVulnerabilitiesBySoftwareID(
+	ctx context.Context,
+	softwareID uint,
+) ([]fleet.SoftwareVulnerability, error) {
+	var result []fleet.SoftwareVulnerability
+
+	stmt := dialect.
+		From(goqu.T("software_cve").As("sc")).
+		Select(
+			goqu.I("sc.software_id"),
+			goqu.I("sc.cve"),
+		).
+		Where(goqu.I("sc.software_id").Eq(softwareID))
+
+	sql, args, err := stmt.ToSQL()
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "error generating SQL statement")
+	}
+
+	if err := sqlx.SelectContext(ctx, ds.reader, &result, sql, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "error executing SQL statement")
+	}
+
+	return result, nil
+}
+
 func (ds *Datastore) ListSoftwareForVulnDetection(
 	ctx context.Context,
 	hostID uint,
@@ -29,17 +29,19 @@ func TestSoftware(t *testing.T) {
 		{"HostDuplicates", testSoftwareHostDuplicates},
 		{"LoadVulnerabilities", testSoftwareLoadVulnerabilities},
 		{"ListSoftwareCPEs", testListSoftwareCPEs},
+		{"DeleteSoftwareCPEs", testDeleteSoftwareCPEs},
 		{"NothingChanged", testSoftwareNothingChanged},
 		{"LoadSupportsTonsOfCVEs", testSoftwareLoadSupportsTonsOfCVEs},
 		{"List", testSoftwareList},
 		{"SyncHostsSoftware", testSoftwareSyncHostsSoftware},
 		{"DeleteSoftwareVulnerabilities", testDeleteSoftwareVulnerabilities},
 		{"HostsByCVE", testHostsByCVE},
-		{"HostsBySoftwareIDs", testHostsBySoftwareIDs},
+		{"ListSoftwareVulnerabilitiesBySoftwareID", testListSoftwareVulnerabilitiesBySoftwareID},
 		{"UpdateHostSoftware", testUpdateHostSoftware},
 		{"ListSoftwareBySourceIter", testListSoftwareBySourceIter},
 		{"ListSoftwareByHostIDShort", testListSoftwareByHostIDShort},
 		{"ListSoftwareVulnerabilitiesByHostIDsSource", testListSoftwareVulnerabilitiesByHostIDsSource},
+		{"ListSoftwareVulnerabilitiesBySource", testListSoftwareVulnerabilitiesBySource},
 		{"InsertSoftwareVulnerabilities", testInsertSoftwareVulnerabilities},
 		{"ListCVEs", testListCVEs},
 		{"ListSoftwareForVulnDetection", testListSoftwareForVulnDetection},
@@ -323,6 +325,62 @@ func testListSoftwareCPEs(t *testing.T, ds *Datastore) {
 	assert.ElementsMatch(t, actual, expected)
 }
 
+func testDeleteSoftwareCPEs(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "host1", "", "host1key", "host1uuid", time.Now())
+	software := []fleet.Software{
+		{Name: "foo", Version: "0.0.1", Source: "chrome_extensions"},
+		{Name: "bar", Version: "0.0.3", Source: "apps"},
+		{Name: "baz", Version: "0.0.3", Source: "deb_packages"},
+	}
+	require.NoError(t, ds.UpdateHostSoftware(ctx, host.ID, software))
+	require.NoError(t, ds.LoadHostSoftware(ctx, host, false))
+	byName := make(map[string]fleet.Software)
+	for _, s := range host.Software {
+		byName[s.Name] = s
+	}
+
+	require.NoError(t, ds.AddCPEForSoftware(ctx, byName["foo"], "cpe1"))
+	require.NoError(t, ds.AddCPEForSoftware(ctx, byName["bar"], "cpe2"))
+	require.NoError(t, ds.AddCPEForSoftware(ctx, byName["baz"], "cpe3"))
+	_, err := ds.InsertSoftwareVulnerabilities(ctx, []fleet.SoftwareVulnerability{
+		{SoftwareID: byName["foo"].ID, CVE: "CVE-2022-0001"},
+	}, fleet.NVDSource)
+	require.NoError(t, err)
+	_, err = ds.InsertSoftwareVulnerabilities(ctx, []fleet.SoftwareVulnerability{
+		{SoftwareID: byName["foo"].ID, CVE: "CVE-2022-0002"},
+	}, fleet.UbuntuOVALSource)
+	require.NoError(t, err)
+
+	namesWithCPE := func() []string {
+		iter, err := ds.AllSoftwareWithCPEIterator(ctx, []string{"deb_packages"})
+		require.NoError(t, err)
+		defer iter.Close()
+		var names []string
+		for iter.Next() {
+			s, err := iter.Value()
+			require.NoError(t, err)
+			names = append(names, s.Name)
+		}
+		require.NoError(t, iter.Err())
+		return names
+	}
+	require.ElementsMatch(t, []string{"foo", "bar"}, namesWithCPE())
+
+	require.NoError(t, ds.DeleteSoftwareCPEs(ctx, nil))
+	require.NoError(t, ds.DeleteSoftwareCPEs(ctx, []uint{byName["foo"].ID}))
+	require.Equal(t, []string{"bar"}, namesWithCPE())
+
+	// only the vulnerabilities detected with NVD are deleted
+	vulns, err := ds.ListSoftwareVulnerabilitiesByHostIDsSource(ctx, []uint{host.ID}, fleet.NVDSource)
+	require.NoError(t, err)
+	require.Empty(t, vulns[host.ID])
+	vulns, err = ds.ListSoftwareVulnerabilitiesByHostIDsSource(ctx, []uint{host.ID}, fleet.UbuntuOVALSource)
+	require.NoError(t, err)
+	require.Len(t, vulns[host.ID], 1)
+}
+
 func testSoftwareNothingChanged(t *testing.T, ds *Datastore) {
 	cases := []struct {
 		desc     string
@@ -1253,62 +1311,6 @@ func testHostsByCVE(t *testing.T, ds *Datastore) {
 	require.Equal(t, hosts[0].Hostname, "host2")
 }
 
-func testHostsBySoftwareIDs(t *testing.T, ds *Datastore) {
-	ctx := context.Background()
-
-	hosts, err := ds.HostsBySoftwareIDs(ctx, []uint{0})
-	require.NoError(t, err)
-	require.Len(t, hosts, 0)
-
-	insertVulnSoftwareForTest(t, ds)
-
-	allSoftware, err := ds.ListSoftware(ctx, fleet.SoftwareListOptions{})
-	require.NoError(t, err)
-
-	var chrome3 fleet.Software
-	var barRpm fleet.Software
-
-	for _, s := range allSoftware {
-		if s.GenerateCPE == "cpe_foo_chrome_3" {
-			chrome3 = s
-		}
-
-		if s.GenerateCPE == "cpe_bar_rpm" {
-			barRpm = s
-		}
-	}
-
-	require.NotZero(t, chrome3.ID)
-	require.NotZero(t, barRpm.ID)
-
-	hosts, err = ds.HostsBySoftwareIDs(ctx, []uint{chrome3.ID})
-	require.NoError(t, err)
-	require.Len(t, hosts, 2)
-	require.ElementsMatch(t, hosts, []*fleet.HostShort{
-		{
-			ID:          1,
-			Hostname:    "host1",
-			DisplayName: "computer1",
-		}, {
-			ID:          2,
-			Hostname:    "host2",
-			DisplayName: "host2",
-		},
-	})
-
-	hosts, err = ds.HostsBySoftwareIDs(ctx, []uint{barRpm.ID})
-	require.NoError(t, err)
-	require.Len(t, hosts, 1)
-	require.Equal(t, hosts[0].Hostname, "host2")
-
-	// Duplicates should not be returned if cpes are found on the same host ie host2 should only appear once
-	hosts, err = ds.HostsBySoftwareIDs(ctx, []uint{chrome3.ID, barRpm.ID})
-	require.NoError(t, err)
-	require.Len(t, hosts, 2)
-	require.Equal(t, hosts[0].Hostname, "host1")
-	require.Equal(t, hosts[1].Hostname, "host2")
-}
-
 func testUpdateHostSoftware(t *testing.T, ds *Datastore) {
 	ctx := context.Background()
 
@@ -1512,6 +1514,84 @@ func testListSoftwareVulnerabilitiesByHostIDsSource(t *testing.T, ds *Datastore)
 	}
 }
 
+func testListSoftwareVulnerabilitiesBySoftwareID(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "host1", "", "host1key", "host1uuid", time.Now())
+	software := []fleet.Software{
+		{Name: "foo", Version: "0.0.1", Source: "apps"},
+		{Name: "bar", Version: "0.0.3", Source: "apps"},
+	}
+	require.NoError(t, ds.UpdateHostSoftware(ctx, host.ID, software))
+	require.NoError(t, ds.LoadHostSoftware(ctx, host, false))
+
+	result, err := ds.ListSoftwareVulnerabilitiesBySoftwareID(ctx, host.Software[0].ID)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	vulns := []fleet.SoftwareVulnerability{
+		{SoftwareID: host.Software[0].ID, CVE: "cve-123"},
+		{SoftwareID: host.Software[0].ID, CVE: "cve-456"},
+	}
+	_, err = ds.InsertSoftwareVulnerabilities(ctx, vulns, fleet.NVDSource)
+	require.NoError(t, err)
+	_, err = ds.InsertSoftwareVulnerabilities(ctx, []fleet.SoftwareVulnerability{
+		{SoftwareID: host.Software[1].ID, CVE: "cve-789"},
+	}, fleet.MacOfficeReleaseNotesSource)
+	require.NoError(t, err)
+
+	// the suppressed vulnerabilities are listed
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "cve-123",
+		Reason:        fleet.VulnerabilityExceptionFalsePositive,
+		Justification: "wrong match",
+	})
+	require.NoError(t, err)
+
+	result, err = ds.ListSoftwareVulnerabilitiesBySoftwareID(ctx, host.Software[0].ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, vulns, result)
+}
+
+func testListSoftwareVulnerabilitiesBySource(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	host := test.NewHost(t, ds, "host1", "", "host1key", "host1uuid", time.Now())
+	software := []fleet.Software{
+		{Name: "foo", Version: "0.0.1", Source: "python_packages"},
+		{Name: "bar", Version: "0.0.3", Source: "npm_packages"},
+		{Name: "blah", Version: "1.0", Source: "apps"},
+	}
+	require.NoError(t, ds.UpdateHostSoftware(ctx, host.ID, software))
+	require.NoError(t, ds.LoadHostSoftware(ctx, host, false))
+
+	result, err := ds.ListSoftwareVulnerabilitiesBySource(ctx, fleet.OSVSource)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	osvVulns := []fleet.SoftwareVulnerability{
+		{SoftwareID: host.Software[0].ID, CVE: "cve-123"},
+		{SoftwareID: host.Software[1].ID, CVE: "cve-456"},
+	}
+	n, err := ds.InsertSoftwareVulnerabilities(ctx, osvVulns, fleet.OSVSource)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	n, err = ds.InsertSoftwareVulnerabilities(ctx, []fleet.SoftwareVulnerability{
+		{SoftwareID: host.Software[2].ID, CVE: "cve-789"},
+	}, fleet.NVDSource)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	result, err = ds.ListSoftwareVulnerabilitiesBySource(ctx, fleet.OSVSource)
+	require.NoError(t, err)
+	require.ElementsMatch(t, osvVulns, result)
+
+	result, err = ds.ListSoftwareVulnerabilitiesBySource(ctx, fleet.NVDSource)
+	require.NoError(t, err)
+	require.Equal(t, []fleet.SoftwareVulnerability{{SoftwareID: host.Software[2].ID, CVE: "cve-789"}}, result)
+}
+
 func testInsertSoftwareVulnerabilities(t *testing.T, ds *Datastore) {
 	ctx := context.Background()
 
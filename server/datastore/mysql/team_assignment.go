@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+func (ds *Datastore) ListHostsForTeamAssignment(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error) {
+	stmt := `
+    SELECT
+      h.id,
+      COALESCE(NULLIF(h.computer_name, ''), h.hostname) AS display_name,
+      h.hostname,
+      h.platform,
+      h.hardware_serial,
+      h.team_id,
+      t.name AS team_name
+    FROM hosts h
+    LEFT JOIN teams t ON t.id = h.team_id`
+	var args []interface{}
+	if hostIDs != nil {
+		if len(hostIDs) == 0 {
+			return nil, nil
+		}
+		stmt += ` WHERE h.id IN (?)`
+		args = append(args, hostIDs)
+	}
+	stmt += ` ORDER BY h.id`
+
+	stmt, args, err := sqlx.In(stmt, args...)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "build list hosts for team assignment query")
+	}
+	var hosts []*fleet.TeamAssignmentHost
+	if err := sqlx.SelectContext(ctx, ds.reader, &hosts, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list hosts for team assignment")
+	}
+	if len(hosts) == 0 {
+		return hosts, nil
+	}
+
+	byID := make(map[uint]*fleet.TeamAssignmentHost, len(hosts))
+	for _, h := range hosts {
+		byID[h.ID] = h
+	}
+
+	type hostValue struct {
+		HostID uint   `db:"host_id"`
+		Value  string `db:"value"`
+	}
+	loadValues := func(stmt string, args []interface{}, assign func(h *fleet.TeamAssignmentHost, v string)) error {
+		if hostIDs != nil {
+			stmt += ` AND host_id IN (?)`
+			args = append(args, hostIDs)
+		}
+		stmt, args, err := sqlx.In(stmt, args...)
+		if err != nil {
+			return err
+		}
+		var values []hostValue
+		if err := sqlx.SelectContext(ctx, ds.reader, &values, stmt, args...); err != nil {
+			return err
+		}
+		for _, v := range values {
+			if h := byID[v.HostID]; h != nil {
+				assign(h, v.Value)
+			}
+		}
+		return nil
+	}
+
+	if len(labelNames) > 0 {
+		if err := loadValues(
+			`SELECT lm.host_id, l.name AS value FROM label_membership lm JOIN labels l ON l.id = lm.label_id WHERE l.name IN (?)`,
+			[]interface{}{labelNames},
+			func(h *fleet.TeamAssignmentHost, v string) { h.LabelNames = append(h.LabelNames, v) },
+		); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "load label membership for team assignment")
+		}
+	}
+	if withEmails {
+		if err := loadValues(
+			`SELECT host_id, email AS value FROM host_emails WHERE 1 = 1`,
+			nil,
+			func(h *fleet.TeamAssignmentHost, v string) { h.Emails = append(h.Emails, v) },
+		); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "load emails for team assignment")
+		}
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamAssignment(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"ListHosts", testTeamAssignmentListHosts},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testTeamAssignmentListHosts(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+	h1 := test.NewHost(t, ds, "h1", "", "h1key", "h1uuid", time.Now())
+	h2 := test.NewHost(t, ds, "h2", "", "h2key", "h2uuid", time.Now())
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{h2.ID}))
+
+	l1, err := ds.NewLabel(ctx, &fleet.Label{Name: "l1", Query: "select 1"})
+	require.NoError(t, err)
+	l2, err := ds.NewLabel(ctx, &fleet.Label{Name: "l2", Query: "select 1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.RecordLabelQueryExecutions(ctx, h1, map[uint]*bool{l1.ID: ptr.Bool(true), l2.ID: ptr.Bool(true)}, time.Now(), false))
+	require.NoError(t, ds.ReplaceHostDeviceMapping(ctx, h2.ID, []*fleet.HostDeviceMapping{{HostID: h2.ID, Email: "a@example.com", Source: "google_chrome_profiles"}}))
+
+	// no labels nor emails requested
+	hosts, err := ds.ListHostsForTeamAssignment(ctx, nil, nil, false)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	require.Equal(t, h1.ID, hosts[0].ID)
+	require.Equal(t, "h1", hosts[0].Hostname)
+	require.Equal(t, "darwin", hosts[0].Platform)
+	require.Nil(t, hosts[0].TeamID)
+	require.Empty(t, hosts[0].LabelNames)
+	require.Equal(t, &team.ID, hosts[1].TeamID)
+	require.Equal(t, ptr.String("team1"), hosts[1].TeamName)
+	require.Empty(t, hosts[1].Emails)
+
+	// only the requested labels are loaded
+	hosts, err = ds.ListHostsForTeamAssignment(ctx, nil, []string{"l1"}, true)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	require.Equal(t, []string{"l1"}, hosts[0].LabelNames)
+	require.Equal(t, []string{"a@example.com"}, hosts[1].Emails)
+
+	hosts, err = ds.ListHostsForTeamAssignment(ctx, []uint{h2.ID}, []string{"l1", "l2"}, true)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, h2.ID, hosts[0].ID)
+	require.Empty(t, hosts[0].LabelNames)
+	require.Equal(t, []string{"a@example.com"}, hosts[0].Emails)
+
+	hosts, err = ds.ListHostsForTeamAssignment(ctx, []uint{}, nil, false)
+	require.NoError(t, err)
+	require.Empty(t, hosts)
+}
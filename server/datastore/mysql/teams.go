@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -15,14 +16,19 @@ import (
 
 var teamSearchColumns = []string{"name"}
 
+// teamParentNameSelect selects the name of the parent of the team aliased as
+// t.
+const teamParentNameSelect = `(SELECT p.name FROM teams p WHERE p.id = t.parent_team_id) AS parent_team_name`
+
 func (ds *Datastore) NewTeam(ctx context.Context, team *fleet.Team) (*fleet.Team, error) {
 	err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
 		query := `
     INSERT INTO teams (
       name,
       description,
-      config
-    ) VALUES (?, ?, ?)
+      config,
+      parent_team_id
+    ) VALUES (?, ?, ?, ?)
     `
 		result, err := tx.ExecContext(
 			ctx,
@@ -30,6 +36,7 @@ func (ds *Datastore) NewTeam(ctx context.Context, team *fleet.Team) (*fleet.Team
 			team.Name,
 			team.Description,
 			team.Config,
+			team.ParentTeamID,
 		)
 		if err != nil {
 			return ctxerr.Wrap(ctx, err, "insert team")
@@ -52,8 +59,8 @@ func (ds *Datastore) Team(ctx context.Context, tid uint) (*fleet.Team, error) {
 
 func teamDB(ctx context.Context, q sqlx.QueryerContext, tid uint) (*fleet.Team, error) {
 	stmt := `
-		SELECT * FROM teams
-			WHERE id = ?
+		SELECT t.*, ` + teamParentNameSelect + ` FROM teams t
+			WHERE t.id = ?
 	`
 	team := &fleet.Team{}
 
@@ -77,6 +84,9 @@ func teamDB(ctx context.Context, q sqlx.QueryerContext, tid uint) (*fleet.Team,
 	if err := loadFeaturesForTeamDB(ctx, q, team); err != nil {
 		return nil, err
 	}
+	if err := loadScheduleOverridesForTeamsDB(ctx, q, []*fleet.Team{team}); err != nil {
+		return nil, err
+	}
 
 	return team, nil
 }
@@ -91,7 +101,16 @@ func saveTeamSecretsDB(ctx context.Context, q sqlx.ExtContext, team *fleet.Team)
 
 func (ds *Datastore) DeleteTeam(ctx context.Context, tid uint) error {
 	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
-		_, err := tx.ExecContext(ctx, `DELETE FROM teams WHERE id = ?`, tid)
+		// the children of the team are moved to its parent
+		_, err := tx.ExecContext(ctx, `
+			UPDATE teams c JOIN teams p ON p.id = c.parent_team_id
+			SET c.parent_team_id = p.parent_team_id
+			WHERE p.id = ?`, tid)
+		if err != nil {
+			return ctxerr.Wrapf(ctx, err, "reparent children of team %d", tid)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM teams WHERE id = ?`, tid)
 		if err != nil {
 			return ctxerr.Wrapf(ctx, err, "delete team %d", tid)
 		}
@@ -112,8 +131,8 @@ func (ds *Datastore) DeleteTeam(ctx context.Context, tid uint) error {
 
 func (ds *Datastore) TeamByName(ctx context.Context, name string) (*fleet.Team, error) {
 	stmt := `
-		SELECT * FROM teams
-			WHERE name = ?
+		SELECT t.*, ` + teamParentNameSelect + ` FROM teams t
+			WHERE t.name = ?
 	`
 	team := &fleet.Team{}
 
@@ -134,6 +153,9 @@ func (ds *Datastore) TeamByName(ctx context.Context, name string) (*fleet.Team,
 	if err := loadFeaturesForTeamDB(ctx, ds.reader, team); err != nil {
 		return nil, err
 	}
+	if err := loadScheduleOverridesForTeamsDB(ctx, ds.reader, []*fleet.Team{team}); err != nil {
+		return nil, err
+	}
 
 	return team, nil
 }
@@ -212,11 +234,12 @@ UPDATE teams
 SET
     name = ?,
     description = ?,
-    config = ?
+    config = ?,
+    parent_team_id = ?
 WHERE
     id = ?
 `
-		_, err := tx.ExecContext(ctx, query, team.Name, team.Description, team.Config, team.ID)
+		_, err := tx.ExecContext(ctx, query, team.Name, team.Description, team.Config, team.ParentTeamID, team.ID)
 		if err != nil {
 			return ctxerr.Wrap(ctx, err, "saving team")
 		}
@@ -244,7 +267,7 @@ func updateTeamScheduleDB(ctx context.Context, exec sqlx.ExecerContext, team *fl
 // fleet.ListOptions
 func (ds *Datastore) ListTeams(ctx context.Context, filter fleet.TeamFilter, opt fleet.ListOptions) ([]*fleet.Team, error) {
 	query := fmt.Sprintf(`
-			SELECT *,
+			SELECT t.*, `+teamParentNameSelect+`,
 				(SELECT count(*) FROM user_teams WHERE team_id = t.id) AS user_count,
 				(SELECT count(*) FROM hosts WHERE team_id = t.id) AS host_count
 			FROM teams t
@@ -261,6 +284,9 @@ func (ds *Datastore) ListTeams(ctx context.Context, filter fleet.TeamFilter, opt
 	if err := loadSecretsForTeamsDB(ctx, ds.reader, teams); err != nil {
 		return nil, ctxerr.Wrap(ctx, err, "getting secrets for teams")
 	}
+	if err := loadScheduleOverridesForTeamsDB(ctx, ds.reader, teams); err != nil {
+		return nil, err
+	}
 	return teams, nil
 }
 
@@ -283,9 +309,93 @@ func (ds *Datastore) TeamsSummary(ctx context.Context) ([]*fleet.TeamSummary, er
 	return teamsSummary, nil
 }
 
+func (ds *Datastore) TeamHierarchy(ctx context.Context) (fleet.TeamHierarchy, error) {
+	var rows []struct {
+		ID           uint  `db:"id"`
+		ParentTeamID *uint `db:"parent_team_id"`
+	}
+	if err := sqlx.SelectContext(ctx, ds.reader, &rows, `SELECT id, parent_team_id FROM teams`); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "select team hierarchy")
+	}
+
+	hierarchy := make(fleet.TeamHierarchy, len(rows))
+	for _, r := range rows {
+		var parentID uint
+		if r.ParentTeamID != nil {
+			parentID = *r.ParentTeamID
+		}
+		hierarchy[r.ID] = parentID
+	}
+	return hierarchy, nil
+}
+
+// teamAncestorIDsDB returns the IDs of the ancestors of the team, nearest
+// first. A team that does not exist has no ancestors.
+func teamAncestorIDsDB(ctx context.Context, q sqlx.QueryerContext, tid uint) ([]uint, error) {
+	var ancestors []uint
+	err := walkTeamAncestryDB(ctx, q, tid, "NULL", func(id uint, _ *json.RawMessage) (bool, error) {
+		if id != tid {
+			ancestors = append(ancestors, id)
+		}
+		return false, nil
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return ancestors, nil
+}
+
+// hostTeamLineageDB returns the ID of the team of the host followed by the IDs
+// of the ancestors of that team, nearest first. It returns an empty list if the
+// host does not belong to a team.
+func hostTeamLineageDB(ctx context.Context, q sqlx.QueryerContext, hid uint) ([]uint, error) {
+	var teamID *uint
+	if err := sqlx.GetContext(ctx, q, &teamID, `SELECT team_id FROM hosts WHERE id = ?`, hid); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, ctxerr.Wrap(ctx, err, "select host team")
+	}
+	if teamID == nil {
+		return nil, nil
+	}
+
+	ancestors, err := teamAncestorIDsDB(ctx, q, *teamID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]uint{*teamID}, ancestors...), nil
+}
+
+// walkTeamAncestryDB calls fn with the value of expr (an expression on the
+// teams table) for the team and then for each of its ancestors, nearest first,
+// until fn returns true or an error.
+func walkTeamAncestryDB(ctx context.Context, q sqlx.QueryerContext, tid uint, expr string, fn func(id uint, value *json.RawMessage) (bool, error)) error {
+	stmt := fmt.Sprintf(`SELECT %s AS value, parent_team_id FROM teams WHERE id = ?`, expr)
+
+	seen := make(map[uint]bool)
+	for id := &tid; id != nil && !seen[*id]; {
+		seen[*id] = true
+
+		var row struct {
+			Value        *json.RawMessage `db:"value"`
+			ParentTeamID *uint            `db:"parent_team_id"`
+		}
+		if err := sqlx.GetContext(ctx, q, &row, stmt, *id); err != nil {
+			return ctxerr.Wrap(ctx, err, "select team")
+		}
+		done, err := fn(*id, row.Value)
+		if err != nil || done {
+			return err
+		}
+		id = row.ParentTeamID
+	}
+	return nil
+}
+
 func (ds *Datastore) SearchTeams(ctx context.Context, filter fleet.TeamFilter, matchQuery string, omit ...uint) ([]*fleet.Team, error) {
 	sql := fmt.Sprintf(`
-			SELECT *,
+			SELECT t.*, `+teamParentNameSelect+`,
 				(SELECT count(*) FROM user_teams WHERE team_id = t.id) AS user_count,
 				(SELECT count(*) FROM hosts WHERE team_id = t.id) AS host_count
 			FROM teams t
@@ -326,16 +436,48 @@ func amountTeamsDB(ctx context.Context, db sqlx.QueryerContext) (int, error) {
 	return amount, nil
 }
 
-// TeamAgentOptions loads the agents options of a team.
+// TeamAgentOptions loads the agents options of a team, or of its nearest
+// ancestor that sets them if the team does not.
 func (ds *Datastore) TeamAgentOptions(ctx context.Context, tid uint) (*json.RawMessage, error) {
-	sql := `SELECT config->'$.agent_options' FROM teams WHERE id = ?`
 	var agentOptions *json.RawMessage
-	if err := sqlx.GetContext(ctx, ds.reader, &agentOptions, sql, tid); err != nil {
-		return nil, ctxerr.Wrap(ctx, err, "select team")
+	err := walkTeamAncestryDB(ctx, ds.reader, tid, `config->'$.agent_options'`, func(id uint, raw *json.RawMessage) (bool, error) {
+		if id == tid {
+			agentOptions = raw
+		}
+		if raw == nil || string(*raw) == "null" {
+			return false, nil
+		}
+		agentOptions = raw
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return agentOptions, nil
 }
 
+// TeamUpdateChannels loads the update channels of a team, or of its nearest
+// ancestor that sets them if the team does not. It returns nil if no team
+// sets them.
+func (ds *Datastore) TeamUpdateChannels(ctx context.Context, tid uint) (*fleet.UpdateChannels, error) {
+	var channels *fleet.UpdateChannels
+	err := walkTeamAncestryDB(ctx, ds.reader, tid, `config->'$.update_channels'`, func(id uint, raw *json.RawMessage) (bool, error) {
+		if raw == nil || string(*raw) == "null" {
+			return false, nil
+		}
+		var c fleet.UpdateChannels
+		if err := json.Unmarshal(*raw, &c); err != nil {
+			return false, ctxerr.Wrap(ctx, err, "unmarshal team update channels")
+		}
+		channels = &c
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
 // TeamFeatures loads the features enabled for a team.
 func (ds *Datastore) TeamFeatures(ctx context.Context, tid uint) (*fleet.Features, error) {
 	return teamFeaturesDB(ctx, ds.reader, tid)
@@ -370,6 +512,30 @@ func (ds *Datastore) TeamMDMConfig(ctx context.Context, tid uint) (*fleet.TeamMD
 			return nil, ctxerr.Wrap(ctx, err, "unmarshal team MDM config")
 		}
 	}
+
+	// the macOS updates are inherited from the nearest ancestor that sets them
+	if mdmConfig == nil || (mdmConfig.MacOSUpdates.MinimumVersion == "" && mdmConfig.MacOSUpdates.Deadline == "") {
+		err := walkTeamAncestryDB(ctx, ds.reader, tid, `config->'$.mdm.macos_updates'`, func(_ uint, raw *json.RawMessage) (bool, error) {
+			if raw == nil {
+				return false, nil
+			}
+			var updates fleet.MacOSUpdates
+			if err := json.Unmarshal(*raw, &updates); err != nil {
+				return false, ctxerr.Wrap(ctx, err, "unmarshal team macOS updates")
+			}
+			if updates.MinimumVersion == "" && updates.Deadline == "" {
+				return false, nil
+			}
+			if mdmConfig == nil {
+				mdmConfig = &fleet.TeamMDM{}
+			}
+			mdmConfig.MacOSUpdates = updates
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	return mdmConfig, nil
 }
 
@@ -396,9 +562,12 @@ func (ds *Datastore) DeleteIntegrationsFromTeams(ctx context.Context, deletedInt
 		// ignore errors, it's ok for some integrations to not match with the
 		// batch of deleted integrations, we're only interested in knowing if
 		// some did match.
-		if matches, _ := tm.Config.Integrations.MatchWithIntegrations(deletedIntgs); len(matches.Jira)+len(matches.Zendesk) > 0 {
+		if matches, _ := tm.Config.Integrations.MatchWithIntegrations(deletedIntgs); len(matches.Jira)+len(matches.Zendesk)+len(matches.Slack)+len(matches.MicrosoftTeams)+len(matches.CustomHTTP) > 0 {
 			delJira, _ := fleet.IndexJiraIntegrations(matches.Jira)
 			delZendesk, _ := fleet.IndexZendeskIntegrations(matches.Zendesk)
+			delSlack, _ := fleet.IndexSlackIntegrations(matches.Slack)
+			delMicrosoftTeams, _ := fleet.IndexMicrosoftTeamsIntegrations(matches.MicrosoftTeams)
+			delCustomHTTP, _ := fleet.IndexCustomHTTPIntegrations(matches.CustomHTTP)
 
 			var keepJira []*fleet.TeamJiraIntegration
 			for _, tmIntg := range tm.Config.Integrations.Jira {
@@ -414,8 +583,32 @@ func (ds *Datastore) DeleteIntegrationsFromTeams(ctx context.Context, deletedInt
 				}
 			}
 
+			var keepSlack []*fleet.TeamSlackIntegration
+			for _, tmIntg := range tm.Config.Integrations.Slack {
+				if _, ok := delSlack[tmIntg.UniqueKey()]; !ok {
+					keepSlack = append(keepSlack, tmIntg)
+				}
+			}
+
+			var keepMicrosoftTeams []*fleet.TeamMicrosoftTeamsIntegration
+			for _, tmIntg := range tm.Config.Integrations.MicrosoftTeams {
+				if _, ok := delMicrosoftTeams[tmIntg.UniqueKey()]; !ok {
+					keepMicrosoftTeams = append(keepMicrosoftTeams, tmIntg)
+				}
+			}
+
+			var keepCustomHTTP []*fleet.TeamCustomHTTPIntegration
+			for _, tmIntg := range tm.Config.Integrations.CustomHTTP {
+				if _, ok := delCustomHTTP[tmIntg.UniqueKey()]; !ok {
+					keepCustomHTTP = append(keepCustomHTTP, tmIntg)
+				}
+			}
+
 			tm.Config.Integrations.Jira = keepJira
 			tm.Config.Integrations.Zendesk = keepZendesk
+			tm.Config.Integrations.Slack = keepSlack
+			tm.Config.Integrations.MicrosoftTeams = keepMicrosoftTeams
+			tm.Config.Integrations.CustomHTTP = keepCustomHTTP
 			if _, err := ds.writer.ExecContext(ctx, updateTeam, tm.Config, tm.ID); err != nil {
 				return ctxerr.Wrap(ctx, err, "update team config")
 			}
@@ -423,3 +616,76 @@ func (ds *Datastore) DeleteIntegrationsFromTeams(ctx context.Context, deletedInt
 	}
 	return rows.Err()
 }
+
+const teamScheduledQueryOverridesSelect = `
+	SELECT
+		o.team_id,
+		o.scheduled_query_id,
+		sq.name,
+		o.disabled,
+		o.interval,
+		o.shard,
+		o.platform
+	FROM team_scheduled_query_overrides o
+	JOIN scheduled_queries sq ON (sq.id = o.scheduled_query_id)
+`
+
+func (ds *Datastore) ListTeamScheduledQueryOverrides(ctx context.Context, teamID uint) (fleet.TeamScheduledQueryOverrideList, error) {
+	var overrides fleet.TeamScheduledQueryOverrideList
+	stmt := teamScheduledQueryOverridesSelect + ` WHERE o.team_id = ? ORDER BY sq.name`
+	if err := sqlx.SelectContext(ctx, ds.reader, &overrides, stmt, teamID); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list team scheduled query overrides")
+	}
+	return overrides, nil
+}
+
+func loadScheduleOverridesForTeamsDB(ctx context.Context, q sqlx.QueryerContext, teams []*fleet.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	teamsByID := make(map[uint]*fleet.Team, len(teams))
+	ids := make([]uint, 0, len(teams))
+	for _, team := range teams {
+		teamsByID[team.ID] = team
+		ids = append(ids, team.ID)
+	}
+
+	stmt, args, err := sqlx.In(teamScheduledQueryOverridesSelect+` WHERE o.team_id IN (?) ORDER BY sq.name`, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "build load team scheduled query overrides query")
+	}
+	var overrides []*fleet.TeamScheduledQueryOverride
+	if err := sqlx.SelectContext(ctx, q, &overrides, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "load team scheduled query overrides")
+	}
+	for _, o := range overrides {
+		if team := teamsByID[o.TeamID]; team != nil {
+			team.ScheduleOverrides = append(team.ScheduleOverrides, o)
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) ReplaceTeamScheduledQueryOverrides(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error {
+	return ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM team_scheduled_query_overrides WHERE team_id = ?`, teamID); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete team scheduled query overrides")
+		}
+		if len(overrides) == 0 {
+			return nil
+		}
+
+		const valueStr = "(?,?,?,?,?,?),"
+		args := make([]interface{}, 0, len(overrides)*6)
+		for _, o := range overrides {
+			args = append(args, teamID, o.ScheduledQueryID, o.Disabled, o.Interval, o.Shard, o.Platform)
+		}
+		stmt := "INSERT INTO team_scheduled_query_overrides (team_id, scheduled_query_id, disabled, `interval`, shard, platform) VALUES " +
+			strings.TrimSuffix(strings.Repeat(valueStr, len(overrides)), ",")
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return ctxerr.Wrap(ctx, err, "insert team scheduled query overrides")
+		}
+		return nil
+	})
+}
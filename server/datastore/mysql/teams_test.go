@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sort"
 	"testing"
 	"time"
@@ -33,6 +34,8 @@ func TestTeams(t *testing.T) {
 		{"DeleteIntegrationsFromTeams", testTeamsDeleteIntegrationsFromTeams},
 		{"TeamsFeatures", testTeamsFeatures},
 		{"TeamsMDMConfig", testTeamsMDMConfig},
+		{"TeamsScheduledQueryOverrides", testTeamsScheduledQueryOverrides},
+		{"TeamsHierarchy", testTeamsHierarchy},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -602,3 +605,216 @@ func testTeamsMDMConfig(t *testing.T, ds *Datastore) {
 		}, mdm)
 	})
 }
+
+func testTeamsScheduledQueryOverrides(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team1, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+	team2, err := ds.NewTeam(ctx, &fleet.Team{Name: "team2"})
+	require.NoError(t, err)
+
+	gp, err := ds.EnsureGlobalPack(ctx)
+	require.NoError(t, err)
+	q1 := test.NewQuery(t, ds, "q1", "select 1", 0, true)
+	q2 := test.NewQuery(t, ds, "q2", "select 2", 0, true)
+	sq1 := test.NewScheduledQuery(t, ds, gp.ID, q1.ID, 60, false, false, "sq1")
+	sq2 := test.NewScheduledQuery(t, ds, gp.ID, q2.ID, 60, false, false, "sq2")
+
+	overrides, err := ds.ListTeamScheduledQueryOverrides(ctx, team1.ID)
+	require.NoError(t, err)
+	require.Empty(t, overrides)
+
+	err = ds.ReplaceTeamScheduledQueryOverrides(ctx, team1.ID, []*fleet.TeamScheduledQueryOverride{
+		{ScheduledQueryID: sq1.ID, Disabled: true},
+		{ScheduledQueryID: sq2.ID, Interval: ptr.Uint(120), Shard: ptr.Uint(10), Platform: ptr.String("linux")},
+	})
+	require.NoError(t, err)
+	err = ds.ReplaceTeamScheduledQueryOverrides(ctx, team2.ID, []*fleet.TeamScheduledQueryOverride{
+		{ScheduledQueryID: sq2.ID, Platform: ptr.String("")},
+	})
+	require.NoError(t, err)
+
+	expected1 := fleet.TeamScheduledQueryOverrideList{
+		{TeamID: team1.ID, ScheduledQueryID: sq1.ID, Name: "sq1", Disabled: true},
+		{TeamID: team1.ID, ScheduledQueryID: sq2.ID, Name: "sq2", Interval: ptr.Uint(120), Shard: ptr.Uint(10), Platform: ptr.String("linux")},
+	}
+	overrides, err = ds.ListTeamScheduledQueryOverrides(ctx, team1.ID)
+	require.NoError(t, err)
+	require.Equal(t, expected1, overrides)
+
+	// the overrides are loaded with the teams
+	team, err := ds.Team(ctx, team1.ID)
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.TeamScheduledQueryOverride(expected1), team.ScheduleOverrides)
+	team, err = ds.TeamByName(ctx, team2.Name)
+	require.NoError(t, err)
+	require.Equal(t, []*fleet.TeamScheduledQueryOverride{
+		{TeamID: team2.ID, ScheduledQueryID: sq2.ID, Name: "sq2", Platform: ptr.String("")},
+	}, team.ScheduleOverrides)
+	teams, err := ds.ListTeams(ctx, fleet.TeamFilter{User: test.UserAdmin}, fleet.ListOptions{OrderKey: "name"})
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+	require.Len(t, teams[0].ScheduleOverrides, 2)
+	require.Len(t, teams[1].ScheduleOverrides, 1)
+
+	// replacing removes the missing overrides
+	err = ds.ReplaceTeamScheduledQueryOverrides(ctx, team1.ID, []*fleet.TeamScheduledQueryOverride{
+		{ScheduledQueryID: sq1.ID, Shard: ptr.Uint(50)},
+	})
+	require.NoError(t, err)
+	overrides, err = ds.ListTeamScheduledQueryOverrides(ctx, team1.ID)
+	require.NoError(t, err)
+	require.Equal(t, fleet.TeamScheduledQueryOverrideList{
+		{TeamID: team1.ID, ScheduledQueryID: sq1.ID, Name: "sq1", Shard: ptr.Uint(50)},
+	}, overrides)
+
+	// deleting the scheduled query deletes its overrides
+	require.NoError(t, ds.DeleteScheduledQuery(ctx, sq2.ID))
+	overrides, err = ds.ListTeamScheduledQueryOverrides(ctx, team2.ID)
+	require.NoError(t, err)
+	require.Empty(t, overrides)
+
+	// clearing the overrides
+	err = ds.ReplaceTeamScheduledQueryOverrides(ctx, team1.ID, nil)
+	require.NoError(t, err)
+	overrides, err = ds.ListTeamScheduledQueryOverrides(ctx, team1.ID)
+	require.NoError(t, err)
+	require.Empty(t, overrides)
+}
+
+func testTeamsHierarchy(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	parentOptions := json.RawMessage(`{"config":{"foo":"parent"}}`)
+	parent, err := ds.NewTeam(ctx, &fleet.Team{
+		Name: "parent",
+		Config: fleet.TeamConfig{
+			AgentOptions: &parentOptions,
+			MDM: fleet.TeamMDM{
+				MacOSUpdates: fleet.MacOSUpdates{MinimumVersion: "13.1", Deadline: "2023-03-01"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	child, err := ds.NewTeam(ctx, &fleet.Team{Name: "child", ParentTeamID: &parent.ID})
+	require.NoError(t, err)
+	grandchild, err := ds.NewTeam(ctx, &fleet.Team{Name: "grandchild", ParentTeamID: &child.ID})
+	require.NoError(t, err)
+	other, err := ds.NewTeam(ctx, &fleet.Team{Name: "other"})
+	require.NoError(t, err)
+
+	tm, err := ds.Team(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, &child.ID, tm.ParentTeamID)
+	require.Equal(t, ptr.String("child"), tm.ParentTeamName)
+
+	hierarchy, err := ds.TeamHierarchy(ctx)
+	require.NoError(t, err)
+	require.Equal(t, fleet.TeamHierarchy{parent.ID: 0, child.ID: parent.ID, grandchild.ID: child.ID, other.ID: 0}, hierarchy)
+	require.Equal(t, []uint{child.ID, parent.ID}, hierarchy.Ancestors(grandchild.ID))
+	require.Equal(t, []uint{child.ID, grandchild.ID}, hierarchy.Descendants(parent.ID))
+
+	// agent options and macOS updates are inherited until the team sets them
+	opts, err := ds.TeamAgentOptions(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(parentOptions), string(*opts))
+	mdm, err := ds.TeamMDMConfig(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, "13.1", mdm.MacOSUpdates.MinimumVersion)
+
+	childOptions := json.RawMessage(`{"config":{"foo":"child"}}`)
+	child.Config.AgentOptions = &childOptions
+	child.Config.MDM.MacOSUpdates = fleet.MacOSUpdates{MinimumVersion: "13.2", Deadline: "2023-04-01"}
+	_, err = ds.SaveTeam(ctx, child)
+	require.NoError(t, err)
+	opts, err = ds.TeamAgentOptions(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(childOptions), string(*opts))
+	mdm, err = ds.TeamMDMConfig(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, "13.2", mdm.MacOSUpdates.MinimumVersion)
+	mdm, err = ds.TeamMDMConfig(ctx, other.ID)
+	require.NoError(t, err)
+	require.Nil(t, mdm)
+
+	// update channels are inherited from the nearest team that sets them
+	channels, err := ds.TeamUpdateChannels(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Nil(t, channels)
+	parent.Config.UpdateChannels = &fleet.UpdateChannels{Osqueryd: "stable"}
+	_, err = ds.SaveTeam(ctx, parent)
+	require.NoError(t, err)
+	child.Config.UpdateChannels = &fleet.UpdateChannels{Orbit: "edge"}
+	_, err = ds.SaveTeam(ctx, child)
+	require.NoError(t, err)
+	channels, err = ds.TeamUpdateChannels(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, &fleet.UpdateChannels{Orbit: "edge"}, channels)
+	channels, err = ds.TeamUpdateChannels(ctx, other.ID)
+	require.NoError(t, err)
+	require.Nil(t, channels)
+
+	// policies and packs of the ancestors apply to the hosts of the descendants
+	parentPolicy, err := ds.NewTeamPolicy(ctx, parent.ID, nil, fleet.PolicyPayload{Name: "parent policy", Query: "select 1"})
+	require.NoError(t, err)
+	otherPolicy, err := ds.NewTeamPolicy(ctx, other.ID, nil, fleet.PolicyPayload{Name: "other policy", Query: "select 1"})
+	require.NoError(t, err)
+	parentPack, err := ds.EnsureTeamPack(ctx, parent.ID)
+	require.NoError(t, err)
+
+	host := test.NewHost(t, ds, "h1", "1", "1", "1", time.Now())
+	require.NoError(t, ds.AddHostsToTeam(ctx, &grandchild.ID, []uint{host.ID}))
+	host.TeamID = &grandchild.ID
+
+	queries, err := ds.PolicyQueriesForHost(ctx, host)
+	require.NoError(t, err)
+	require.Contains(t, queries, fmt.Sprint(parentPolicy.ID))
+	require.NotContains(t, queries, fmt.Sprint(otherPolicy.ID))
+
+	hostPolicies, err := ds.ListPoliciesForHost(ctx, host)
+	require.NoError(t, err)
+	require.Len(t, hostPolicies, 1)
+	require.Equal(t, parentPolicy.ID, hostPolicies[0].ID)
+
+	teamPolicies, inherited, err := ds.ListTeamPolicies(ctx, grandchild.ID, fleet.PolicyListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, teamPolicies)
+	require.Len(t, inherited, 1)
+	require.Equal(t, parentPolicy.ID, inherited[0].ID)
+
+	packs, err := ds.ListPacksForHost(ctx, host.ID)
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+	require.Equal(t, parentPack.ID, packs[0].ID)
+
+	// the admin of the parent team sees its descendants
+	admin, err := ds.NewUser(ctx, &fleet.User{
+		Name:     "admin",
+		Email:    "admin@example.com",
+		Password: []byte("foo"),
+		Teams:    []fleet.UserTeam{{Team: *parent, Role: fleet.RoleAdmin}},
+	})
+	require.NoError(t, err)
+	admin, err = ds.UserByID(ctx, admin.ID)
+	require.NoError(t, err)
+	require.Len(t, admin.Teams, 1)
+	require.Equal(t, []uint{child.ID, grandchild.ID}, admin.Teams[0].DescendantIDs)
+
+	teams, err := ds.ListTeams(ctx, fleet.TeamFilter{User: admin}, fleet.ListOptions{OrderKey: "name"})
+	require.NoError(t, err)
+	require.Len(t, teams, 3)
+	require.Equal(t, "child", teams[0].Name)
+	require.Equal(t, ptr.String("parent"), teams[0].ParentTeamName)
+	require.Equal(t, "grandchild", teams[1].Name)
+	require.Equal(t, "parent", teams[2].Name)
+
+	// deleting a team moves its children to its parent
+	require.NoError(t, ds.DeleteTeam(ctx, child.ID))
+	tm, err = ds.Team(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, &parent.ID, tm.ParentTeamID)
+	opts, err = ds.TeamAgentOptions(ctx, grandchild.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(parentOptions), string(*opts))
+}
@@ -0,0 +1,185 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+const updateRolloutColumns = `
+      id,
+      name,
+      target,
+      channel,
+      team_id,
+      label_id,
+      percentage,
+      halt_offline_percentage,
+      offline_minutes,
+      status,
+      halted_at,
+      halt_reason,
+      created_at,
+      updated_at`
+
+func (ds *Datastore) NewUpdateRollout(ctx context.Context, rollout *fleet.UpdateRollout) (*fleet.UpdateRollout, error) {
+	stmt := `
+    INSERT INTO update_rollouts (
+      name,
+      target,
+      channel,
+      team_id,
+      label_id,
+      percentage,
+      halt_offline_percentage,
+      offline_minutes,
+      status,
+      halted_at,
+      halt_reason
+    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := ds.writer.ExecContext(ctx, stmt,
+		rollout.Name,
+		rollout.Target,
+		rollout.Channel,
+		rollout.TeamID,
+		rollout.LabelID,
+		rollout.Percentage,
+		rollout.HaltOfflinePercentage,
+		rollout.OfflineMinutes,
+		rollout.Status,
+		rollout.HaltedAt,
+		rollout.HaltReason,
+	)
+	if err != nil {
+		if isDuplicate(err) {
+			return nil, ctxerr.Wrap(ctx, alreadyExists("UpdateRollout", rollout.Name))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "insert update rollout")
+	}
+	id, _ := res.LastInsertId()
+	return ds.UpdateRollout(ctx, uint(id))
+}
+
+func (ds *Datastore) UpdateRollout(ctx context.Context, id uint) (*fleet.UpdateRollout, error) {
+	var rollout fleet.UpdateRollout
+	stmt := `SELECT ` + updateRolloutColumns + ` FROM update_rollouts WHERE id = ?`
+	if err := sqlx.GetContext(ctx, ds.writer, &rollout, stmt, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("UpdateRollout").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get update rollout")
+	}
+	return &rollout, nil
+}
+
+func (ds *Datastore) ListUpdateRollouts(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+	var rollouts []*fleet.UpdateRollout
+	stmt := `SELECT ` + updateRolloutColumns + ` FROM update_rollouts ORDER BY id`
+	if err := sqlx.SelectContext(ctx, ds.reader, &rollouts, stmt); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list update rollouts")
+	}
+	return rollouts, nil
+}
+
+func (ds *Datastore) SaveUpdateRollout(ctx context.Context, rollout *fleet.UpdateRollout) error {
+	stmt := `
+    UPDATE update_rollouts SET
+      name = ?,
+      percentage = ?,
+      halt_offline_percentage = ?,
+      offline_minutes = ?,
+      status = ?,
+      halted_at = ?,
+      halt_reason = ?
+    WHERE id = ?`
+	res, err := ds.writer.ExecContext(ctx, stmt,
+		rollout.Name,
+		rollout.Percentage,
+		rollout.HaltOfflinePercentage,
+		rollout.OfflineMinutes,
+		rollout.Status,
+		rollout.HaltedAt,
+		rollout.HaltReason,
+		rollout.ID,
+	)
+	if err != nil {
+		if isDuplicate(err) {
+			return ctxerr.Wrap(ctx, alreadyExists("UpdateRollout", rollout.Name))
+		}
+		return ctxerr.Wrap(ctx, err, "save update rollout")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// the row may be unchanged, check that it exists
+		if _, err := ds.UpdateRollout(ctx, rollout.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) DeleteUpdateRollout(ctx context.Context, id uint) error {
+	res, err := ds.writer.ExecContext(ctx, `DELETE FROM update_rollouts WHERE id = ?`, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete update rollout")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("UpdateRollout").WithID(id))
+	}
+	return nil
+}
+
+func (ds *Datastore) ListHostUpdateRolloutIDs(ctx context.Context, hostID uint) ([]uint, error) {
+	var ids []uint
+	stmt := `SELECT rollout_id FROM update_rollout_hosts WHERE host_id = ? ORDER BY rollout_id`
+	if err := sqlx.SelectContext(ctx, ds.reader, &ids, stmt, hostID); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list host update rollouts")
+	}
+	return ids, nil
+}
+
+func (ds *Datastore) AddHostUpdateRollouts(ctx context.Context, hostID uint, rolloutIDs []uint, pinnedAt time.Time) error {
+	if len(rolloutIDs) == 0 {
+		return nil
+	}
+
+	// the rollout may have been deleted since it was read, so a missing
+	// rollout is ignored.
+	stmt := `INSERT IGNORE INTO update_rollout_hosts (rollout_id, host_id, pinned_at) VALUES %s`
+	values := make([]string, 0, len(rolloutIDs))
+	args := make([]interface{}, 0, 3*len(rolloutIDs))
+	for _, id := range rolloutIDs {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, id, hostID, pinnedAt)
+	}
+	if _, err := ds.writer.ExecContext(ctx, fmt.Sprintf(stmt, strings.Join(values, ",")), args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "add host update rollouts")
+	}
+	return nil
+}
+
+func (ds *Datastore) CountUpdateRolloutHosts(ctx context.Context, rolloutID uint, offlineSince time.Time) (hosts, offline uint, err error) {
+	// a pinned host is offline if it was pinned long enough ago to have
+	// checked in since offlineSince, and did not.
+	stmt := `
+    SELECT
+      COUNT(*) AS hosts,
+      COALESCE(SUM(urh.pinned_at < ? AND COALESCE(hst.seen_time, urh.pinned_at) < ?), 0) AS offline
+    FROM update_rollout_hosts urh
+    LEFT JOIN host_seen_times hst ON hst.host_id = urh.host_id
+    WHERE urh.rollout_id = ?`
+	var counts struct {
+		Hosts   uint `db:"hosts"`
+		Offline uint `db:"offline"`
+	}
+	if err := sqlx.GetContext(ctx, ds.reader, &counts, stmt, offlineSince, offlineSince, rolloutID); err != nil {
+		return 0, 0, ctxerr.Wrap(ctx, err, "count update rollout hosts")
+	}
+	return counts.Hosts, counts.Offline, nil
+}
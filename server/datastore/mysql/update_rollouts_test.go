@@ -0,0 +1,139 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateRollouts(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CRUD", testUpdateRolloutsCRUD},
+		{"Hosts", testUpdateRolloutsHosts},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testUpdateRolloutsCRUD(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	r1, err := ds.NewUpdateRollout(ctx, &fleet.UpdateRollout{
+		Name:                  "canary",
+		Target:                "osqueryd",
+		Channel:               "5.8.2",
+		TeamID:                &team.ID,
+		Percentage:            5,
+		HaltOfflinePercentage: 10,
+		OfflineMinutes:        60,
+		Status:                fleet.UpdateRolloutStatusActive,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, r1.ID)
+	require.Equal(t, team.ID, *r1.TeamID)
+	require.Nil(t, r1.LabelID)
+	require.False(t, r1.CreatedAt.IsZero())
+
+	_, err = ds.NewUpdateRollout(ctx, &fleet.UpdateRollout{
+		Name: "canary", Target: "orbit", Channel: "edge", OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusActive,
+	})
+	var existsErr fleet.AlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	r2, err := ds.NewUpdateRollout(ctx, &fleet.UpdateRollout{
+		Name: "orbit", Target: "orbit", Channel: "edge", OfflineMinutes: 30, Status: fleet.UpdateRolloutStatusActive,
+	})
+	require.NoError(t, err)
+
+	r1.Percentage = 50
+	r1.Status = fleet.UpdateRolloutStatusHalted
+	r1.HaltedAt = ptr.Time(time.Now().UTC().Truncate(time.Second))
+	r1.HaltReason = "halted by a user"
+	require.NoError(t, ds.SaveUpdateRollout(ctx, r1))
+	// saving an unchanged rollout succeeds
+	require.NoError(t, ds.SaveUpdateRollout(ctx, r1))
+	err = ds.SaveUpdateRollout(ctx, &fleet.UpdateRollout{ID: 999, Name: "missing"})
+	require.True(t, fleet.IsNotFound(err))
+
+	got, err := ds.UpdateRollout(ctx, r1.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint(50), got.Percentage)
+	require.Equal(t, fleet.UpdateRolloutStatusHalted, got.Status)
+	require.Equal(t, *r1.HaltedAt, *got.HaltedAt)
+	require.Equal(t, "halted by a user", got.HaltReason)
+
+	rollouts, err := ds.ListUpdateRollouts(ctx)
+	require.NoError(t, err)
+	require.Len(t, rollouts, 2)
+	require.Equal(t, r1.ID, rollouts[0].ID)
+	require.Equal(t, r2.ID, rollouts[1].ID)
+
+	require.NoError(t, ds.DeleteUpdateRollout(ctx, r2.ID))
+	require.True(t, fleet.IsNotFound(ds.DeleteUpdateRollout(ctx, r2.ID)))
+	_, err = ds.UpdateRollout(ctx, r2.ID)
+	require.True(t, fleet.IsNotFound(err))
+
+	// the rollout is deleted with its team
+	require.NoError(t, ds.DeleteTeam(ctx, team.ID))
+	rollouts, err = ds.ListUpdateRollouts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, rollouts)
+}
+
+func testUpdateRolloutsHosts(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	r, err := ds.NewUpdateRollout(ctx, &fleet.UpdateRollout{
+		Name: "canary", Target: "osqueryd", Channel: "5.8.2", Percentage: 100, OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusActive,
+	})
+	require.NoError(t, err)
+
+	online := test.NewHost(t, ds, "online", "", "onlinekey", "onlineuuid", now)
+	offline := test.NewHost(t, ds, "offline", "", "offlinekey", "offlineuuid", now.Add(-2*time.Hour))
+	recent := test.NewHost(t, ds, "recent", "", "recentkey", "recentuuid", now.Add(-2*time.Hour))
+
+	ids, err := ds.ListHostUpdateRolloutIDs(ctx, online.ID)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	require.NoError(t, ds.AddHostUpdateRollouts(ctx, online.ID, []uint{r.ID}, now.Add(-3*time.Hour)))
+	require.NoError(t, ds.AddHostUpdateRollouts(ctx, offline.ID, []uint{r.ID}, now.Add(-3*time.Hour)))
+	// the host pinned recently did not have time to check in
+	require.NoError(t, ds.AddHostUpdateRollouts(ctx, recent.ID, []uint{r.ID}, now.Add(-time.Minute)))
+	// pinning again and pinning to a deleted rollout are ignored
+	require.NoError(t, ds.AddHostUpdateRollouts(ctx, online.ID, []uint{r.ID, r.ID + 1}, now))
+
+	ids, err = ds.ListHostUpdateRolloutIDs(ctx, online.ID)
+	require.NoError(t, err)
+	require.Equal(t, []uint{r.ID}, ids)
+
+	hosts, offlineCount, err := ds.CountUpdateRolloutHosts(ctx, r.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, uint(3), hosts)
+	require.Equal(t, uint(1), offlineCount)
+
+	// the pinned hosts are removed with their host
+	require.NoError(t, ds.DeleteHost(ctx, offline.ID))
+	hosts, offlineCount, err = ds.CountUpdateRolloutHosts(ctx, r.ID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, uint(2), hosts)
+	require.Zero(t, offlineCount)
+}
@@ -232,6 +232,20 @@ func (ds *Datastore) loadTeamsForUsers(ctx context.Context, users []*fleet.User)
 		return ctxerr.Wrap(ctx, err, "get loadTeamsForUsers")
 	}
 
+	// A team admin is also admin of the descendants of the team.
+	var hierarchy fleet.TeamHierarchy
+	for i, r := range rows {
+		if r.Role != fleet.RoleAdmin {
+			continue
+		}
+		if hierarchy == nil {
+			if hierarchy, err = ds.TeamHierarchy(ctx); err != nil {
+				return err
+			}
+		}
+		rows[i].DescendantIDs = hierarchy.Descendants(r.ID)
+	}
+
 	// Map each row to the appropriate user
 	for _, r := range rows {
 		user := idToUser[r.UserID]
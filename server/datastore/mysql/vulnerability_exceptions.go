@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+const vulnerabilityExceptionColumns = `
+      id,
+      cve,
+      team_id,
+      software_id,
+      host_id,
+      reason,
+      justification,
+      owner,
+      author_id,
+      expires_at,
+      created_at,
+      updated_at`
+
+func (ds *Datastore) NewVulnerabilityException(ctx context.Context, exception *fleet.VulnerabilityException) (*fleet.VulnerabilityException, error) {
+	stmt := `
+    INSERT INTO vulnerability_exceptions (
+      cve,
+      team_id,
+      software_id,
+      host_id,
+      reason,
+      justification,
+      owner,
+      author_id,
+      expires_at
+    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := ds.writer.ExecContext(ctx, stmt,
+		exception.CVE,
+		exception.TeamID,
+		exception.SoftwareID,
+		exception.HostID,
+		exception.Reason,
+		exception.Justification,
+		exception.Owner,
+		exception.AuthorID,
+		exception.ExpiresAt,
+	)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "insert vulnerability exception")
+	}
+	id, _ := res.LastInsertId()
+	return ds.VulnerabilityException(ctx, uint(id))
+}
+
+func (ds *Datastore) VulnerabilityException(ctx context.Context, id uint) (*fleet.VulnerabilityException, error) {
+	var exception fleet.VulnerabilityException
+	stmt := `SELECT ` + vulnerabilityExceptionColumns + ` FROM vulnerability_exceptions WHERE id = ?`
+	if err := sqlx.GetContext(ctx, ds.writer, &exception, stmt, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ctxerr.Wrap(ctx, notFound("VulnerabilityException").WithID(id))
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability exception")
+	}
+	return &exception, nil
+}
+
+func (ds *Datastore) ListVulnerabilityExceptions(ctx context.Context, opts fleet.VulnerabilityExceptionListOptions) ([]*fleet.VulnerabilityException, error) {
+	var where []string
+	var args []interface{}
+	if opts.TeamID != nil {
+		where = append(where, `(team_id IS NULL OR team_id = ?)`)
+		args = append(args, *opts.TeamID)
+	}
+	if opts.CVE != "" {
+		where = append(where, `cve = ?`)
+		args = append(args, opts.CVE)
+	}
+	if !opts.IncludeExpired {
+		where = append(where, `(expires_at IS NULL OR expires_at > NOW())`)
+	}
+
+	stmt := `SELECT ` + vulnerabilityExceptionColumns + ` FROM vulnerability_exceptions`
+	if len(where) > 0 {
+		stmt += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	stmt += ` ORDER BY id`
+
+	var exceptions []*fleet.VulnerabilityException
+	if err := sqlx.SelectContext(ctx, ds.reader, &exceptions, stmt, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability exceptions")
+	}
+	return exceptions, nil
+}
+
+func (ds *Datastore) SaveVulnerabilityException(ctx context.Context, exception *fleet.VulnerabilityException) error {
+	stmt := `
+    UPDATE vulnerability_exceptions SET
+      reason = ?,
+      justification = ?,
+      owner = ?,
+      expires_at = ?
+    WHERE id = ?`
+	res, err := ds.writer.ExecContext(ctx, stmt,
+		exception.Reason,
+		exception.Justification,
+		exception.Owner,
+		exception.ExpiresAt,
+		exception.ID,
+	)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "save vulnerability exception")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// the row may be unchanged, check that it exists
+		if _, err := ds.VulnerabilityException(ctx, exception.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ds *Datastore) DeleteVulnerabilityException(ctx context.Context, id uint) error {
+	res, err := ds.writer.ExecContext(ctx, `DELETE FROM vulnerability_exceptions WHERE id = ?`, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "delete vulnerability exception")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ctxerr.Wrap(ctx, notFound("VulnerabilityException").WithID(id))
+	}
+	return nil
+}
+
+// vulnerabilityExceptionExistsSQL returns the condition that is true if an
+// active vulnerability exception suppresses the CVE of the software_cve row
+// aliased scv. The scope condition selects the exceptions that apply based on
+// their team and host.
+func vulnerabilityExceptionExistsSQL(scope string) string {
+	return `EXISTS (
+      SELECT 1 FROM vulnerability_exceptions ve
+      WHERE
+        ve.cve = scv.cve AND
+        (ve.software_id IS NULL OR ve.software_id = scv.software_id) AND
+        (ve.expires_at IS NULL OR ve.expires_at > NOW()) AND
+        ` + scope + `
+    )`
+}
+
+// suppressedOnHostSQL is the condition that is true if an active
+// vulnerability exception suppresses the CVE of the software_cve row aliased
+// scv on the host aliased h: the exceptions of the host, of its team and the
+// global ones apply.
+var suppressedOnHostSQL = vulnerabilityExceptionExistsSQL(
+	`(ve.host_id = h.id OR (ve.host_id IS NULL AND (ve.team_id IS NULL OR ve.team_id = h.team_id)))`,
+)
+
+// notSuppressedSoftwareCVE returns the condition that is true if no active
+// vulnerability exception suppresses the CVE of the software_cve row aliased
+// scv in the list of software. For the software of a host, the exceptions of
+// the host, of its team and the global ones apply. Otherwise only the
+// exceptions that apply to all the hosts of the listed team do, i.e. the
+// exceptions of the team and the global ones.
+func notSuppressedSoftwareCVE(opts fleet.SoftwareListOptions) exp.Expression {
+	switch {
+	case opts.HostID != nil:
+		return goqu.L(`NOT `+vulnerabilityExceptionExistsSQL(
+			`(ve.host_id = ? OR (ve.host_id IS NULL AND (ve.team_id IS NULL OR ve.team_id = (SELECT team_id FROM hosts WHERE id = ?))))`,
+		), *opts.HostID, *opts.HostID)
+	case opts.TeamID != nil:
+		return goqu.L(`NOT `+vulnerabilityExceptionExistsSQL(
+			`ve.host_id IS NULL AND (ve.team_id IS NULL OR ve.team_id = ?)`,
+		), *opts.TeamID)
+	default:
+		return goqu.L(`NOT ` + vulnerabilityExceptionExistsSQL(
+			`ve.host_id IS NULL AND ve.team_id IS NULL`,
+		))
+	}
+}
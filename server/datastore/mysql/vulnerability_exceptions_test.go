@@ -0,0 +1,250 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityExceptions(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"CRUD", testVulnerabilityExceptionsCRUD},
+		{"Suppression", testVulnerabilityExceptionsSuppression},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+func testVulnerabilityExceptionsCRUD(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	team, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+
+	e1, err := ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0001",
+		Reason:        fleet.VulnerabilityExceptionFalsePositive,
+		Justification: "not installed from the vulnerable vendor",
+		Owner:         "secops",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, e1.ID)
+	require.Nil(t, e1.TeamID)
+	require.Nil(t, e1.ExpiresAt)
+	require.False(t, e1.CreatedAt.IsZero())
+
+	e2, err := ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0002",
+		TeamID:        &team.ID,
+		SoftwareID:    ptr.Uint(123),
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "mitigated by the firewall",
+		ExpiresAt:     ptr.Time(time.Now().Add(24 * time.Hour)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, team.ID, *e2.TeamID)
+	require.EqualValues(t, 123, *e2.SoftwareID)
+
+	expired, err := ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0003",
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "temporary",
+		ExpiresAt:     ptr.Time(time.Now().Add(-time.Hour)),
+	})
+	require.NoError(t, err)
+
+	idsOf := func(exceptions []*fleet.VulnerabilityException) []uint {
+		var ids []uint
+		for _, e := range exceptions {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	list, err := ds.ListVulnerabilityExceptions(ctx, fleet.VulnerabilityExceptionListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []uint{e1.ID, e2.ID}, idsOf(list))
+
+	list, err = ds.ListVulnerabilityExceptions(ctx, fleet.VulnerabilityExceptionListOptions{IncludeExpired: true})
+	require.NoError(t, err)
+	require.Equal(t, []uint{e1.ID, e2.ID, expired.ID}, idsOf(list))
+
+	list, err = ds.ListVulnerabilityExceptions(ctx, fleet.VulnerabilityExceptionListOptions{TeamID: ptr.Uint(team.ID + 1)})
+	require.NoError(t, err)
+	require.Equal(t, []uint{e1.ID}, idsOf(list))
+
+	list, err = ds.ListVulnerabilityExceptions(ctx, fleet.VulnerabilityExceptionListOptions{CVE: "CVE-2022-0002"})
+	require.NoError(t, err)
+	require.Equal(t, []uint{e2.ID}, idsOf(list))
+
+	e1.Reason = fleet.VulnerabilityExceptionAcceptedRisk
+	e1.Justification = "accepted"
+	e1.Owner = "it"
+	e1.ExpiresAt = ptr.Time(time.Now().Add(time.Hour).UTC().Truncate(time.Second))
+	require.NoError(t, ds.SaveVulnerabilityException(ctx, e1))
+	got, err := ds.VulnerabilityException(ctx, e1.ID)
+	require.NoError(t, err)
+	require.Equal(t, fleet.VulnerabilityExceptionAcceptedRisk, got.Reason)
+	require.Equal(t, "accepted", got.Justification)
+	require.Equal(t, "it", got.Owner)
+	require.Equal(t, *e1.ExpiresAt, got.ExpiresAt.UTC())
+
+	// saving without changes succeeds
+	require.NoError(t, ds.SaveVulnerabilityException(ctx, got))
+
+	require.NoError(t, ds.DeleteVulnerabilityException(ctx, e1.ID))
+	_, err = ds.VulnerabilityException(ctx, e1.ID)
+	require.True(t, fleet.IsNotFound(err))
+	require.True(t, fleet.IsNotFound(ds.DeleteVulnerabilityException(ctx, e1.ID)))
+	require.True(t, fleet.IsNotFound(ds.SaveVulnerabilityException(ctx, e1)))
+
+	// deleting the team deletes its exceptions
+	require.NoError(t, ds.DeleteTeam(ctx, team.ID))
+	_, err = ds.VulnerabilityException(ctx, e2.ID)
+	require.True(t, fleet.IsNotFound(err))
+}
+
+func testVulnerabilityExceptionsSuppression(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	insertVulnSoftwareForTest(t, ds)
+
+	host1, err := ds.HostByIdentifier(ctx, "host1")
+	require.NoError(t, err)
+	host2, err := ds.HostByIdentifier(ctx, "host2")
+	require.NoError(t, err)
+
+	var chrome3, barRpm fleet.Software
+	allSoftware, err := ds.ListSoftware(ctx, fleet.SoftwareListOptions{})
+	require.NoError(t, err)
+	for _, s := range allSoftware {
+		switch s.GenerateCPE {
+		case "cpe_foo_chrome_3":
+			chrome3 = s
+		case "cpe_bar_rpm":
+			barRpm = s
+		}
+	}
+	require.NotZero(t, chrome3.ID)
+	require.NotZero(t, barRpm.ID)
+
+	cvesOf := func(software []fleet.Software) map[string][]string {
+		cves := make(map[string][]string)
+		for _, s := range software {
+			for _, v := range s.Vulnerabilities {
+				cves[s.GenerateCPE] = append(cves[s.GenerateCPE], v.CVE)
+			}
+		}
+		return cves
+	}
+	hostnamesOf := func(hosts []*fleet.HostShort) []string {
+		var names []string
+		for _, h := range hosts {
+			names = append(names, h.Hostname)
+		}
+		return names
+	}
+	hostCVEs := func(host *fleet.Host) map[string][]string {
+		require.NoError(t, ds.LoadHostSoftware(ctx, host, false))
+		return cvesOf(host.Software)
+	}
+
+	// suppress CVE-2022-0001 on host2 only
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0001",
+		HostID:        &host2.ID,
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "extension disabled",
+	})
+	require.NoError(t, err)
+
+	hosts, err := ds.HostsByCVE(ctx, "CVE-2022-0001")
+	require.NoError(t, err)
+	require.Equal(t, []string{"host1"}, hostnamesOf(hosts))
+	hosts, err = ds.VulnerableHostsBySoftwareIDs(ctx, []uint{chrome3.ID}, "CVE-2022-0001")
+	require.NoError(t, err)
+	require.Equal(t, []string{"host1"}, hostnamesOf(hosts))
+	require.Equal(t, map[string][]string{"cpe_foo_chrome_3": {"CVE-2022-0001"}}, hostCVEs(host1))
+	require.Equal(t, map[string][]string{"cpe_bar_rpm": {"CVE-2022-0002", "CVE-2022-0003"}}, hostCVEs(host2))
+
+	// the software list of all hosts still lists it, as host1 has it
+	software, err := ds.ListSoftware(ctx, fleet.SoftwareListOptions{VulnerableOnly: true})
+	require.NoError(t, err)
+	require.Len(t, software, 2)
+	require.Equal(t, []string{"CVE-2022-0001"}, cvesOf(software)["cpe_foo_chrome_3"])
+
+	// suppress CVE-2022-0002 on bar.rpm globally, and an expired exception
+	// for CVE-2022-0003
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0002",
+		SoftwareID:    &barRpm.ID,
+		Reason:        fleet.VulnerabilityExceptionFalsePositive,
+		Justification: "backported fix",
+	})
+	require.NoError(t, err)
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0003",
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "expired",
+		ExpiresAt:     ptr.Time(time.Now().Add(-time.Hour)),
+	})
+	require.NoError(t, err)
+
+	hosts, err = ds.HostsByCVE(ctx, "CVE-2022-0002")
+	require.NoError(t, err)
+	require.Empty(t, hosts)
+	hosts, err = ds.HostsByCVE(ctx, "CVE-2022-0003")
+	require.NoError(t, err)
+	require.Equal(t, []string{"host2"}, hostnamesOf(hosts))
+
+	software, err = ds.ListSoftware(ctx, fleet.SoftwareListOptions{VulnerableOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"cpe_foo_chrome_3": {"CVE-2022-0001"},
+		"cpe_bar_rpm":      {"CVE-2022-0003"},
+	}, cvesOf(software))
+	count, err := ds.CountSoftware(ctx, fleet.SoftwareListOptions{VulnerableOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	// suppress CVE-2022-0001 globally, chrome is no longer vulnerable
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0001",
+		Reason:        fleet.VulnerabilityExceptionFalsePositive,
+		Justification: "wrong match",
+	})
+	require.NoError(t, err)
+	software, err = ds.ListSoftware(ctx, fleet.SoftwareListOptions{VulnerableOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"cpe_bar_rpm": {"CVE-2022-0003"}}, cvesOf(software))
+	hosts, err = ds.HostsByCVE(ctx, "CVE-2022-0001")
+	require.NoError(t, err)
+	require.Empty(t, hosts)
+
+	// the software details do not list the vulnerabilities suppressed for all
+	// hosts
+	details, err := ds.SoftwareByID(ctx, chrome3.ID, false)
+	require.NoError(t, err)
+	require.Empty(t, details.Vulnerabilities)
+	details, err = ds.SoftwareByID(ctx, barRpm.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"cpe_bar_rpm": {"CVE-2022-0003"}}, cvesOf([]fleet.Software{*details}))
+
+	// the software is still listed without the vulnerabilities
+	software, err = ds.ListSoftware(ctx, fleet.SoftwareListOptions{})
+	require.NoError(t, err)
+	require.Len(t, software, len(allSoftware))
+}
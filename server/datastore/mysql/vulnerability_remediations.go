@@ -0,0 +1,311 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/jmoiron/sqlx"
+)
+
+// hostVulnerabilityRemediationsSyncBatchSize is the size of the ranges of
+// host IDs synced in a single transaction by
+// SyncHostVulnerabilityRemediations.
+var hostVulnerabilityRemediationsSyncBatchSize uint = 1000
+
+// SyncHostVulnerabilityRemediations records the CVEs currently detected on
+// the hosts, from their software and operating system, that are not tracked
+// yet as first detected at now, and resolves at now the tracked CVEs that are
+// no longer detected. The hosts are synced in batches of ranges of host IDs,
+// each in its own transaction.
+func (ds *Datastore) SyncHostVulnerabilityRemediations(ctx context.Context, now time.Time) error {
+	const maxHostIDStmt = `
+    SELECT GREATEST(
+      COALESCE((SELECT MAX(id) FROM hosts), 0),
+      COALESCE((SELECT MAX(host_id) FROM host_vulnerability_remediations), 0)
+    )`
+
+	const resolveStmt = `
+    UPDATE host_vulnerability_remediations r
+    SET r.resolved_at = ?
+    WHERE
+      r.host_id BETWEEN ? AND ? AND
+      r.resolved_at IS NULL AND
+      NOT EXISTS (
+        SELECT 1 FROM host_software hs
+        INNER JOIN software_cve scv ON scv.software_id = hs.software_id
+        WHERE hs.host_id = r.host_id AND scv.cve = r.cve
+      ) AND
+      NOT EXISTS (
+        SELECT 1 FROM operating_system_vulnerabilities osv
+        WHERE osv.host_id = r.host_id AND osv.cve = r.cve
+      )`
+
+	const detectStmt = `
+    INSERT INTO host_vulnerability_remediations (host_id, cve, first_detected_at)
+    SELECT cur.host_id, cur.cve, ?
+    FROM (
+      SELECT hs.host_id, scv.cve
+      FROM host_software hs
+      INNER JOIN software_cve scv ON scv.software_id = hs.software_id
+      WHERE hs.host_id BETWEEN ? AND ?
+      UNION
+      SELECT osv.host_id, osv.cve
+      FROM operating_system_vulnerabilities osv
+      WHERE osv.host_id BETWEEN ? AND ?
+    ) cur
+    LEFT JOIN host_vulnerability_remediations r
+    ON r.host_id = cur.host_id AND r.cve = cur.cve AND r.resolved_at IS NULL
+    WHERE r.id IS NULL`
+
+	var maxHostID uint
+	if err := sqlx.GetContext(ctx, ds.reader, &maxHostID, maxHostIDStmt); err != nil {
+		return ctxerr.Wrap(ctx, err, "select max host id of vulnerability remediations")
+	}
+
+	for minID := uint(1); minID <= maxHostID; minID += hostVulnerabilityRemediationsSyncBatchSize {
+		maxID := minID + hostVulnerabilityRemediationsSyncBatchSize - 1
+		err := ds.withRetryTxx(ctx, func(tx sqlx.ExtContext) error {
+			if _, err := tx.ExecContext(ctx, resolveStmt, now, minID, maxID); err != nil {
+				return ctxerr.Wrap(ctx, err, "resolve host vulnerability remediations")
+			}
+			if _, err := tx.ExecContext(ctx, detectStmt, now, minID, maxID, minID, maxID); err != nil {
+				return ctxerr.Wrap(ctx, err, "detect host vulnerability remediations")
+			}
+			return nil
+		})
+		if err != nil {
+			return ctxerr.Wrapf(ctx, err, "sync vulnerability remediations of hosts %d to %d", minID, maxID)
+		}
+	}
+	return nil
+}
+
+// hostVulnerabilityRemediationsSQL returns the query that selects the
+// remediations with their severity and SLA, to be used as a derived table.
+// The severity is derived from the CVSS score like in
+// fleet.CVESeverityFromScore, and the SLA is breached if the CVE was not
+// resolved by its due time, or is still not resolved at now.
+func hostVulnerabilityRemediationsSQL(sla fleet.VulnerabilityRemediationSLA, now time.Time) (string, []interface{}) {
+	const stmt = `
+    SELECT
+      id,
+      host_id,
+      host_hostname,
+      host_display_name,
+      team_id,
+      cve,
+      cvss_score,
+      severity,
+      first_detected_at,
+      resolved_at,
+      sla_breach_notified_at,
+      IF(sla_days > 0, DATE_ADD(first_detected_at, INTERVAL sla_days DAY), NULL) sla_due_at,
+      (sla_days > 0 AND COALESCE(resolved_at, ?) > DATE_ADD(first_detected_at, INTERVAL sla_days DAY)) sla_breached
+    FROM (
+      SELECT
+        r.id,
+        r.host_id,
+        h.hostname host_hostname,
+        if(h.computer_name = '', h.hostname, h.computer_name) host_display_name,
+        h.team_id,
+        r.cve,
+        cm.cvss_score,
+        CASE
+          WHEN cm.cvss_score IS NULL THEN 'unknown'
+          WHEN cm.cvss_score >= 9 THEN 'critical'
+          WHEN cm.cvss_score >= 7 THEN 'high'
+          WHEN cm.cvss_score >= 4 THEN 'medium'
+          ELSE 'low'
+        END severity,
+        CASE
+          WHEN cm.cvss_score IS NULL THEN 0
+          WHEN cm.cvss_score >= 9 THEN ?
+          WHEN cm.cvss_score >= 7 THEN ?
+          WHEN cm.cvss_score >= 4 THEN ?
+          ELSE ?
+        END sla_days,
+        r.first_detected_at,
+        r.resolved_at,
+        r.sla_breach_notified_at
+      FROM host_vulnerability_remediations r
+      INNER JOIN hosts h ON h.id = r.host_id
+      LEFT JOIN cve_meta cm ON cm.cve = r.cve
+    ) rs`
+
+	return stmt, []interface{}{now, sla.CriticalDays, sla.HighDays, sla.MediumDays, sla.LowDays}
+}
+
+const selectHostVulnerabilityRemediationColumns = `
+      id,
+      host_id,
+      host_hostname,
+      host_display_name,
+      team_id,
+      cve,
+      cvss_score,
+      severity,
+      first_detected_at,
+      resolved_at,
+      sla_due_at,
+      sla_breached`
+
+func (ds *Datastore) ListHostVulnerabilityRemediations(
+	ctx context.Context,
+	opt fleet.VulnerabilityRemediationListOptions,
+	sla fleet.VulnerabilityRemediationSLA,
+	now time.Time,
+) ([]*fleet.HostVulnerabilityRemediation, *fleet.PaginationMetadata, error) {
+	remediationsSQL, args := hostVulnerabilityRemediationsSQL(sla, now)
+	query := `SELECT ` + selectHostVulnerabilityRemediationColumns + ` FROM (` + remediationsSQL + `) rem WHERE true`
+
+	if opt.TeamID != nil {
+		query += " AND team_id = ?"
+		args = append(args, *opt.TeamID)
+	}
+	if opt.HostID != nil {
+		query += " AND host_id = ?"
+		args = append(args, *opt.HostID)
+	}
+	if opt.CVE != "" {
+		query += " AND cve = ?"
+		args = append(args, opt.CVE)
+	}
+	switch opt.Status {
+	case fleet.VulnerabilityRemediationOpen:
+		query += " AND resolved_at IS NULL"
+	case fleet.VulnerabilityRemediationResolved:
+		query += " AND resolved_at IS NOT NULL"
+	}
+	if opt.Severity != "" {
+		query += " AND severity = ?"
+		args = append(args, opt.Severity)
+	}
+	if opt.SLABreached {
+		query += " AND sla_breached"
+	}
+
+	if opt.ListOptions.OrderKey == "" {
+		opt.ListOptions.OrderKey = "id"
+		opt.ListOptions.OrderDirection = fleet.OrderDescending
+	}
+	if !(opt.ListOptions.UsesCursorPagination()) {
+		opt.ListOptions.IncludeMetadata = true
+	}
+	query, args = appendListOptionsWithCursorToSQL(query, args, &opt.ListOptions)
+
+	remediations := []*fleet.HostVulnerabilityRemediation{}
+	if err := sqlx.SelectContext(ctx, ds.reader, &remediations, query, args...); err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "select host vulnerability remediations")
+	}
+
+	var metaData *fleet.PaginationMetadata
+	if opt.ListOptions.IncludeMetadata {
+		metaData = &fleet.PaginationMetadata{HasPreviousResults: opt.Page > 0}
+		if len(remediations) > int(opt.ListOptions.PerPage) {
+			metaData.HasNextResults = true
+			remediations = remediations[:len(remediations)-1]
+		}
+	}
+	return remediations, metaData, nil
+}
+
+// VulnerabilityRemediationStats returns the remediation statistics of the
+// hosts of the team, or of all the hosts if teamID is nil, by severity. All
+// the severities are returned, from the most to the least severe.
+func (ds *Datastore) VulnerabilityRemediationStats(
+	ctx context.Context,
+	teamID *uint,
+	sla fleet.VulnerabilityRemediationSLA,
+	now time.Time,
+) ([]*fleet.VulnerabilityRemediationStats, error) {
+	remediationsSQL, args := hostVulnerabilityRemediationsSQL(sla, now)
+	query := `
+    SELECT
+      severity,
+      COALESCE(SUM(resolved_at IS NULL), 0) open_count,
+      COALESCE(SUM(resolved_at IS NOT NULL), 0) resolved_count,
+      AVG(IF(resolved_at IS NOT NULL, TIMESTAMPDIFF(SECOND, first_detected_at, resolved_at), NULL)) mean_time_to_remediate,
+      COALESCE(SUM(sla_breached AND resolved_at IS NULL), 0) open_sla_breached_count,
+      COALESCE(SUM(sla_breached AND resolved_at IS NOT NULL), 0) resolved_sla_breached_count
+    FROM (` + remediationsSQL + `) rem`
+	if teamID != nil {
+		query += " WHERE team_id = ?"
+		args = append(args, *teamID)
+	}
+	query += " GROUP BY severity"
+
+	var rows []*fleet.VulnerabilityRemediationStats
+	if err := sqlx.SelectContext(ctx, ds.reader, &rows, query, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "select vulnerability remediation stats")
+	}
+
+	bySeverity := make(map[fleet.CVESeverity]*fleet.VulnerabilityRemediationStats, len(rows))
+	for _, r := range rows {
+		bySeverity[r.Severity] = r
+	}
+	stats := make([]*fleet.VulnerabilityRemediationStats, 0, len(fleet.CVESeverities))
+	for _, sev := range fleet.CVESeverities {
+		s := bySeverity[sev]
+		if s == nil {
+			s = &fleet.VulnerabilityRemediationStats{Severity: sev}
+		}
+		s.SLADays = sla.Days(sev)
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// ListUnnotifiedVulnerabilitySLABreaches returns the remediations of the CVEs
+// still detected on hosts past their SLA at now, for which no SLA breach
+// notification was sent yet, ordered by CVE and host. The CVEs suppressed on
+// the host by a vulnerability exception are excluded.
+func (ds *Datastore) ListUnnotifiedVulnerabilitySLABreaches(
+	ctx context.Context,
+	sla fleet.VulnerabilityRemediationSLA,
+	now time.Time,
+) ([]*fleet.HostVulnerabilityRemediation, error) {
+	remediationsSQL, args := hostVulnerabilityRemediationsSQL(sla, now)
+	query := `
+    SELECT ` + selectHostVulnerabilityRemediationColumns + `
+    FROM (` + remediationsSQL + `) rem
+    WHERE
+      resolved_at IS NULL AND
+      sla_breach_notified_at IS NULL AND
+      sla_breached AND
+      NOT EXISTS (
+        SELECT 1
+        FROM hosts h
+        INNER JOIN host_software hs ON hs.host_id = h.id
+        INNER JOIN software_cve scv ON scv.software_id = hs.software_id
+        WHERE
+          h.id = rem.host_id AND
+          scv.cve = rem.cve AND
+          ` + suppressedOnHostSQL + `
+      )
+    ORDER BY cve, host_id`
+
+	var breaches []*fleet.HostVulnerabilityRemediation
+	if err := sqlx.SelectContext(ctx, ds.reader, &breaches, query, args...); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "select unnotified vulnerability sla breaches")
+	}
+	return breaches, nil
+}
+
+// MarkVulnerabilitySLABreachesNotified records that the SLA breach
+// notification of the remediations was sent at now.
+func (ds *Datastore) MarkVulnerabilitySLABreachesNotified(ctx context.Context, ids []uint, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stmt, args, err := sqlx.In(`UPDATE host_vulnerability_remediations SET sla_breach_notified_at = ? WHERE id IN (?)`, now, ids)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "building query args")
+	}
+	if _, err := ds.writer.ExecContext(ctx, stmt, args...); err != nil {
+		return ctxerr.Wrap(ctx, err, "mark vulnerability sla breaches notified")
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package mysql
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityRemediations(t *testing.T) {
+	ds := CreateMySQLDS(t)
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, ds *Datastore)
+	}{
+		{"Sync", testVulnerabilityRemediationsSync},
+		{"ListAndStats", testVulnerabilityRemediationsListAndStats},
+		{"SLABreaches", testVulnerabilityRemediationsSLABreaches},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer TruncateTables(t, ds)
+			c.fn(t, ds)
+		})
+	}
+}
+
+type remediationKey struct {
+	Host     string
+	CVE      string
+	Resolved bool
+}
+
+func remediationKeysOf(remediations []*fleet.HostVulnerabilityRemediation) []remediationKey {
+	keys := make([]remediationKey, 0, len(remediations))
+	for _, r := range remediations {
+		keys = append(keys, remediationKey{Host: r.HostHostname, CVE: r.CVE, Resolved: r.ResolvedAt != nil})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Host != keys[j].Host {
+			return keys[i].Host < keys[j].Host
+		}
+		if keys[i].CVE != keys[j].CVE {
+			return keys[i].CVE < keys[j].CVE
+		}
+		return !keys[i].Resolved && keys[j].Resolved
+	})
+	return keys
+}
+
+// setupVulnerabilityRemediationsForTest inserts the vulnerable software of
+// insertVulnSoftwareForTest and syncs the remediations at the returned start
+// time, 10 days ago: CVE-2022-0001 is detected on host1 and host2,
+// CVE-2022-0002 and CVE-2022-0003 on host2. CVE-2022-0002 is then resolved on
+// host2 after 2 days, and detected again after 3 days.
+func setupVulnerabilityRemediationsForTest(t *testing.T, ds *Datastore) (start time.Time, barRpm fleet.Software) {
+	ctx := context.Background()
+
+	insertVulnSoftwareForTest(t, ds)
+
+	allSoftware, err := ds.ListSoftware(ctx, fleet.SoftwareListOptions{})
+	require.NoError(t, err)
+	for _, s := range allSoftware {
+		if s.GenerateCPE == "cpe_bar_rpm" {
+			barRpm = s
+		}
+	}
+	require.NotZero(t, barRpm.ID)
+
+	start = time.Now().UTC().Truncate(time.Second).Add(-10 * 24 * time.Hour)
+	require.NoError(t, ds.SyncHostVulnerabilityRemediations(ctx, start))
+	// syncing again does not duplicate the detections
+	require.NoError(t, ds.SyncHostVulnerabilityRemediations(ctx, start.Add(24*time.Hour)))
+
+	vuln := fleet.SoftwareVulnerability{SoftwareID: barRpm.ID, CVE: "CVE-2022-0002"}
+	require.NoError(t, ds.DeleteSoftwareVulnerabilities(ctx, []fleet.SoftwareVulnerability{vuln}))
+	require.NoError(t, ds.SyncHostVulnerabilityRemediations(ctx, start.Add(2*24*time.Hour)))
+
+	_, err = ds.InsertSoftwareVulnerabilities(ctx, []fleet.SoftwareVulnerability{vuln}, fleet.NVDSource)
+	require.NoError(t, err)
+	require.NoError(t, ds.SyncHostVulnerabilityRemediations(ctx, start.Add(3*24*time.Hour)))
+
+	return start, barRpm
+}
+
+func testVulnerabilityRemediationsSync(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+
+	// sync each host in its own batch
+	defer func(size uint) { hostVulnerabilityRemediationsSyncBatchSize = size }(hostVulnerabilityRemediationsSyncBatchSize)
+	hostVulnerabilityRemediationsSyncBatchSize = 1
+
+	start, barRpm := setupVulnerabilityRemediationsForTest(t, ds)
+
+	host1, err := ds.HostByIdentifier(ctx, "host1")
+	require.NoError(t, err)
+
+	list := func(opts fleet.VulnerabilityRemediationListOptions) []*fleet.HostVulnerabilityRemediation {
+		remediations, _, err := ds.ListHostVulnerabilityRemediations(ctx, opts, fleet.VulnerabilityRemediationSLA{}, time.Now())
+		require.NoError(t, err)
+		return remediations
+	}
+
+	remediations := list(fleet.VulnerabilityRemediationListOptions{})
+	require.Equal(t, []remediationKey{
+		{"host1", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0002", false},
+		{"host2", "CVE-2022-0002", true},
+		{"host2", "CVE-2022-0003", false},
+	}, remediationKeysOf(remediations))
+	for _, r := range remediations {
+		switch {
+		case r.CVE == "CVE-2022-0002" && r.ResolvedAt != nil:
+			require.Equal(t, start, r.FirstDetectedAt.UTC())
+			require.Equal(t, start.Add(2*24*time.Hour), r.ResolvedAt.UTC())
+		case r.CVE == "CVE-2022-0002":
+			require.Equal(t, start.Add(3*24*time.Hour), r.FirstDetectedAt.UTC())
+		default:
+			require.Equal(t, start, r.FirstDetectedAt.UTC())
+		}
+		// no SLA is configured
+		require.Nil(t, r.SLADueAt)
+		require.False(t, r.SLABreached)
+	}
+
+	// operating system vulnerabilities are tracked too
+	_, err = ds.InsertOSVulnerabilities(ctx, []fleet.OSVulnerability{
+		{OSID: 1, HostID: host1.ID, CVE: "CVE-2022-0004"},
+	}, fleet.MSRCSource)
+	require.NoError(t, err)
+	// the software is removed from host2
+	_, err = ds.writer.ExecContext(ctx, `DELETE FROM host_software WHERE software_id = ?`, barRpm.ID)
+	require.NoError(t, err)
+	resolvedAt := start.Add(4 * 24 * time.Hour)
+	require.NoError(t, ds.SyncHostVulnerabilityRemediations(ctx, resolvedAt))
+
+	remediations = list(fleet.VulnerabilityRemediationListOptions{})
+	require.Equal(t, []remediationKey{
+		{"host1", "CVE-2022-0001", false},
+		{"host1", "CVE-2022-0004", false},
+		{"host2", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0002", true},
+		{"host2", "CVE-2022-0002", true},
+		{"host2", "CVE-2022-0003", true},
+	}, remediationKeysOf(remediations))
+	for _, r := range remediations {
+		if r.CVE == "CVE-2022-0003" {
+			require.Equal(t, resolvedAt, r.ResolvedAt.UTC())
+		}
+	}
+}
+
+func testVulnerabilityRemediationsListAndStats(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	start, _ := setupVulnerabilityRemediationsForTest(t, ds)
+
+	team, err := ds.NewTeam(ctx, &fleet.Team{Name: "team1"})
+	require.NoError(t, err)
+	host2, err := ds.HostByIdentifier(ctx, "host2")
+	require.NoError(t, err)
+	require.NoError(t, ds.AddHostsToTeam(ctx, &team.ID, []uint{host2.ID}))
+
+	require.NoError(t, ds.InsertCVEMeta(ctx, []fleet.CVEMeta{
+		{CVE: "CVE-2022-0001", CVSSScore: ptr.Float64(9.8)},
+		{CVE: "CVE-2022-0002", CVSSScore: ptr.Float64(5.5)},
+	}))
+
+	// the open critical CVEs are past their 7 days SLA, the medium CVE was
+	// resolved after 2 days, within its 3 days SLA, and detected again 7 days
+	// ago, so it is past its SLA too.
+	sla := fleet.VulnerabilityRemediationSLA{CriticalDays: 7, MediumDays: 3}
+	now := time.Now()
+
+	list := func(opts fleet.VulnerabilityRemediationListOptions) []*fleet.HostVulnerabilityRemediation {
+		remediations, _, err := ds.ListHostVulnerabilityRemediations(ctx, opts, sla, now)
+		require.NoError(t, err)
+		return remediations
+	}
+
+	remediations := list(fleet.VulnerabilityRemediationListOptions{})
+	require.Len(t, remediations, 5)
+	for _, r := range remediations {
+		switch r.CVE {
+		case "CVE-2022-0001":
+			require.Equal(t, fleet.CVESeverityCritical, r.Severity)
+			require.Equal(t, start.Add(7*24*time.Hour), r.SLADueAt.UTC())
+			require.True(t, r.SLABreached)
+		case "CVE-2022-0002":
+			require.Equal(t, fleet.CVESeverityMedium, r.Severity)
+			require.NotNil(t, r.SLADueAt)
+			require.Equal(t, r.ResolvedAt == nil, r.SLABreached)
+		case "CVE-2022-0003":
+			require.Equal(t, fleet.CVESeverityUnknown, r.Severity)
+			require.Nil(t, r.CVSSScore)
+			require.Nil(t, r.SLADueAt)
+			require.False(t, r.SLABreached)
+		}
+		if r.HostHostname == "host1" {
+			require.Equal(t, "computer1", r.HostDisplayName)
+			require.Nil(t, r.HostTeamID)
+		} else {
+			require.Equal(t, team.ID, *r.HostTeamID)
+		}
+	}
+
+	require.Equal(t, []remediationKey{
+		{"host2", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0002", false},
+		{"host2", "CVE-2022-0002", true},
+		{"host2", "CVE-2022-0003", false},
+	}, remediationKeysOf(list(fleet.VulnerabilityRemediationListOptions{TeamID: &team.ID})))
+	require.Equal(t, []remediationKey{
+		{"host2", "CVE-2022-0002", true},
+	}, remediationKeysOf(list(fleet.VulnerabilityRemediationListOptions{HostID: &host2.ID, Status: fleet.VulnerabilityRemediationResolved})))
+	require.Equal(t, []remediationKey{
+		{"host1", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0002", false},
+	}, remediationKeysOf(list(fleet.VulnerabilityRemediationListOptions{SLABreached: true})))
+	require.Equal(t, []remediationKey{
+		{"host2", "CVE-2022-0002", false},
+		{"host2", "CVE-2022-0002", true},
+	}, remediationKeysOf(list(fleet.VulnerabilityRemediationListOptions{Severity: fleet.CVESeverityMedium})))
+	require.Equal(t, []remediationKey{
+		{"host1", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0001", false},
+	}, remediationKeysOf(list(fleet.VulnerabilityRemediationListOptions{CVE: "CVE-2022-0001"})))
+
+	// pagination
+	remediations, meta, err := ds.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{
+		ListOptions: fleet.ListOptions{PerPage: 2},
+	}, sla, now)
+	require.NoError(t, err)
+	require.Len(t, remediations, 2)
+	require.True(t, meta.HasNextResults)
+	require.False(t, meta.HasPreviousResults)
+
+	// stats of all the hosts
+	stats, err := ds.VulnerabilityRemediationStats(ctx, nil, sla, now)
+	require.NoError(t, err)
+	require.Len(t, stats, len(fleet.CVESeverities))
+	bySeverity := make(map[fleet.CVESeverity]*fleet.VulnerabilityRemediationStats)
+	for i, s := range stats {
+		require.Equal(t, fleet.CVESeverities[i], s.Severity)
+		bySeverity[s.Severity] = s
+	}
+	require.Equal(t, &fleet.VulnerabilityRemediationStats{
+		Severity:             fleet.CVESeverityCritical,
+		SLADays:              7,
+		OpenCount:            2,
+		OpenSLABreachedCount: 2,
+	}, bySeverity[fleet.CVESeverityCritical])
+	require.Equal(t, &fleet.VulnerabilityRemediationStats{
+		Severity:             fleet.CVESeverityMedium,
+		SLADays:              3,
+		OpenCount:            1,
+		ResolvedCount:        1,
+		MeanTimeToRemediate:  ptr.Float64((2 * 24 * time.Hour).Seconds()),
+		OpenSLABreachedCount: 1,
+	}, bySeverity[fleet.CVESeverityMedium])
+	require.Equal(t, &fleet.VulnerabilityRemediationStats{
+		Severity:  fleet.CVESeverityUnknown,
+		OpenCount: 1,
+	}, bySeverity[fleet.CVESeverityUnknown])
+	require.Equal(t, &fleet.VulnerabilityRemediationStats{Severity: fleet.CVESeverityHigh}, bySeverity[fleet.CVESeverityHigh])
+
+	// with a shorter SLA, the resolved CVE breached it
+	stats, err = ds.VulnerabilityRemediationStats(ctx, &team.ID, fleet.VulnerabilityRemediationSLA{CriticalDays: 7, MediumDays: 1}, now)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), stats[0].OpenCount)
+	require.Equal(t, uint(1), stats[2].ResolvedSLABreachedCount)
+	require.Equal(t, uint(1), stats[2].OpenSLABreachedCount)
+
+	// no stats for an empty team
+	team2, err := ds.NewTeam(ctx, &fleet.Team{Name: "team2"})
+	require.NoError(t, err)
+	stats, err = ds.VulnerabilityRemediationStats(ctx, &team2.ID, sla, now)
+	require.NoError(t, err)
+	for _, s := range stats {
+		require.Zero(t, s.OpenCount)
+		require.Zero(t, s.ResolvedCount)
+		require.Nil(t, s.MeanTimeToRemediate)
+	}
+}
+
+func testVulnerabilityRemediationsSLABreaches(t *testing.T, ds *Datastore) {
+	ctx := context.Background()
+	setupVulnerabilityRemediationsForTest(t, ds)
+
+	require.NoError(t, ds.InsertCVEMeta(ctx, []fleet.CVEMeta{
+		{CVE: "CVE-2022-0001", CVSSScore: ptr.Float64(9.8)},
+		{CVE: "CVE-2022-0002", CVSSScore: ptr.Float64(5.5)},
+	}))
+
+	now := time.Now()
+
+	// no SLA, no breach
+	breaches, err := ds.ListUnnotifiedVulnerabilitySLABreaches(ctx, fleet.VulnerabilityRemediationSLA{}, now)
+	require.NoError(t, err)
+	require.Empty(t, breaches)
+
+	sla := fleet.VulnerabilityRemediationSLA{CriticalDays: 7, MediumDays: 3}
+	breaches, err = ds.ListUnnotifiedVulnerabilitySLABreaches(ctx, sla, now)
+	require.NoError(t, err)
+	require.Equal(t, []remediationKey{
+		{"host1", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0001", false},
+		{"host2", "CVE-2022-0002", false},
+	}, remediationKeysOf(breaches))
+	// ordered by CVE and host
+	require.Equal(t, "CVE-2022-0001", breaches[0].CVE)
+	require.Equal(t, "host1", breaches[0].HostHostname)
+	require.Equal(t, "CVE-2022-0002", breaches[2].CVE)
+
+	require.NoError(t, ds.MarkVulnerabilitySLABreachesNotified(ctx, []uint{breaches[0].ID, breaches[1].ID}, now))
+	require.NoError(t, ds.MarkVulnerabilitySLABreachesNotified(ctx, nil, now))
+
+	breaches, err = ds.ListUnnotifiedVulnerabilitySLABreaches(ctx, sla, now)
+	require.NoError(t, err)
+	require.Equal(t, []remediationKey{
+		{"host2", "CVE-2022-0002", false},
+	}, remediationKeysOf(breaches))
+
+	// the CVEs suppressed on the host are excluded
+	host1, err := ds.HostByIdentifier(ctx, "host1")
+	require.NoError(t, err)
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0002",
+		HostID:        &host1.ID,
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "not exposed",
+	})
+	require.NoError(t, err)
+	breaches, err = ds.ListUnnotifiedVulnerabilitySLABreaches(ctx, sla, now)
+	require.NoError(t, err)
+	require.Len(t, breaches, 1)
+
+	host2, err := ds.HostByIdentifier(ctx, "host2")
+	require.NoError(t, err)
+	_, err = ds.NewVulnerabilityException(ctx, &fleet.VulnerabilityException{
+		CVE:           "CVE-2022-0002",
+		HostID:        &host2.ID,
+		Reason:        fleet.VulnerabilityExceptionAcceptedRisk,
+		Justification: "not exposed",
+	})
+	require.NoError(t, err)
+	breaches, err = ds.ListUnnotifiedVulnerabilitySLABreaches(ctx, sla, now)
+	require.NoError(t, err)
+	require.Empty(t, breaches)
+}
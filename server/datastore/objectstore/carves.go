@@ -0,0 +1,144 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+const (
+	// carves are kept for 24 hours, see mysql.Datastore.CleanupCarves.
+	carveRetention = 24 * time.Hour
+	// same layout as the S3 carve store, to easily list carves
+	// chronologically: year/month/day/hour.
+	timePrefixFormat = "2006/01/02/15"
+)
+
+// CarveStore is a fleet.CarveStore that keeps the carve metadata in the
+// metadata store (MySQL) and each block of data as an object of the Store,
+// under the "<prefix><year>/<month>/<day>/<hour>/<carve id>/<block id>" key.
+type CarveStore struct {
+	store      Store
+	prefix     string
+	metadatadb fleet.CarveStore
+}
+
+var _ fleet.CarveStore = (*CarveStore)(nil)
+
+// NewCarveStore creates a carve store that stores the blocks of data in store,
+// under the given key prefix.
+func NewCarveStore(store Store, prefix string, metadatadb fleet.CarveStore) *CarveStore {
+	return &CarveStore{store: store, prefix: prefix, metadatadb: metadatadb}
+}
+
+func (c *CarveStore) carvePrefix(metadata *fleet.CarveMetadata) string {
+	return fmt.Sprintf("%s%s/%d/", c.prefix, metadata.CreatedAt.UTC().Format(timePrefixFormat), metadata.ID)
+}
+
+func (c *CarveStore) blockKey(metadata *fleet.CarveMetadata, blockID int64) string {
+	return fmt.Sprintf("%s%d", c.carvePrefix(metadata), blockID)
+}
+
+// NewCarve initializes a new file carving session
+func (c *CarveStore) NewCarve(ctx context.Context, metadata *fleet.CarveMetadata) (*fleet.CarveMetadata, error) {
+	return c.metadatadb.NewCarve(ctx, metadata)
+}
+
+// UpdateCarve updates carve definition in database
+// Only max_block and expired are updatable
+func (c *CarveStore) UpdateCarve(ctx context.Context, metadata *fleet.CarveMetadata) error {
+	return c.metadatadb.UpdateCarve(ctx, metadata)
+}
+
+// Carve returns carve metadata by ID
+func (c *CarveStore) Carve(ctx context.Context, carveID int64) (*fleet.CarveMetadata, error) {
+	return c.metadatadb.Carve(ctx, carveID)
+}
+
+// CarveBySessionId returns carve metadata by session ID
+func (c *CarveStore) CarveBySessionId(ctx context.Context, sessionID string) (*fleet.CarveMetadata, error) {
+	return c.metadatadb.CarveBySessionId(ctx, sessionID)
+}
+
+// CarveByName returns carve metadata by name
+func (c *CarveStore) CarveByName(ctx context.Context, name string) (*fleet.CarveMetadata, error) {
+	return c.metadatadb.CarveByName(ctx, name)
+}
+
+// ListCarves returns a list of the currently available carves
+func (c *CarveStore) ListCarves(ctx context.Context, opt fleet.CarveListOptions) ([]*fleet.CarveMetadata, error) {
+	return c.metadatadb.ListCarves(ctx, opt)
+}
+
+// NewBlock stores a new block for a specific carve
+func (c *CarveStore) NewBlock(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64, data []byte) error {
+	if err := c.store.Put(ctx, c.blockKey(metadata, blockID), data); err != nil {
+		return ctxerr.Wrap(ctx, err, "store carve block")
+	}
+	if metadata.MaxBlock < blockID {
+		metadata.MaxBlock = blockID
+		if err := c.UpdateCarve(ctx, metadata); err != nil {
+			return ctxerr.Wrap(ctx, err, "update carve max block")
+		}
+	}
+	return nil
+}
+
+// GetBlock returns a block of data for a carve
+func (c *CarveStore) GetBlock(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64) ([]byte, error) {
+	data, err := c.store.Get(ctx, c.blockKey(metadata, blockID))
+	if err != nil {
+		if IsNotFound(err) && blockID <= metadata.MaxBlock && !metadata.Expired {
+			// the block was received but is no longer in the store, mark the
+			// carve expired
+			metadata.Expired = true
+			if updateErr := c.UpdateCarve(ctx, metadata); updateErr != nil {
+				err = ctxerr.Wrap(ctx, err, updateErr.Error())
+			}
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get carve block")
+	}
+	return data, nil
+}
+
+// CleanupCarves marks the carves older than 24 hours expired and deletes
+// their blocks from the store. Blocks are deleted by hour prefix, so that
+// blocks of carves that failed to be recorded in the metadata store are
+// deleted too.
+func (c *CarveStore) CleanupCarves(ctx context.Context, now time.Time) (int, error) {
+	expired, err := c.metadatadb.CleanupCarves(ctx, now)
+	if err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "expire carves metadata")
+	}
+
+	keys, err := c.store.List(ctx, c.prefix)
+	if err != nil {
+		return expired, ctxerr.Wrap(ctx, err, "list carve blocks")
+	}
+	// an hour prefix can be deleted once all the carves created during that
+	// hour are older than the retention.
+	cutoff := now.UTC().Add(-carveRetention)
+	var toDelete []string
+	for _, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, c.prefix), "/", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		hour, err := time.Parse(timePrefixFormat, strings.Join(parts[:4], "/"))
+		if err != nil {
+			// not a carve block
+			continue
+		}
+		if !hour.Add(time.Hour).After(cutoff) {
+			toDelete = append(toDelete, key)
+		}
+	}
+	if err := c.store.Delete(ctx, toDelete...); err != nil {
+		return expired, ctxerr.Wrap(ctx, err, "delete carve blocks")
+	}
+	return expired, nil
+}
@@ -0,0 +1,98 @@
+package objectstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCarveStoreBlocks(t *testing.T) {
+	ctx := context.Background()
+	fs, err := NewFilesystemStore(t.TempDir())
+	require.NoError(t, err)
+
+	ds := new(mock.Store)
+	var updated []*fleet.CarveMetadata
+	ds.UpdateCarveFunc = func(ctx context.Context, metadata *fleet.CarveMetadata) error {
+		c := *metadata
+		updated = append(updated, &c)
+		return nil
+	}
+	store := NewCarveStore(fs, "carves/", ds)
+
+	carve := &fleet.CarveMetadata{
+		ID:         7,
+		CreatedAt:  time.Date(2023, 3, 16, 9, 30, 0, 0, time.UTC),
+		BlockCount: 2,
+		MaxBlock:   -1,
+	}
+	require.NoError(t, store.NewBlock(ctx, carve, 0, []byte("block0")))
+	require.NoError(t, store.NewBlock(ctx, carve, 1, []byte("block1")))
+	require.Len(t, updated, 2)
+	assert.EqualValues(t, 1, carve.MaxBlock)
+
+	keys, err := fs.List(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"carves/2023/03/16/09/7/0", "carves/2023/03/16/09/7/1"}, keys)
+
+	data, err := store.GetBlock(ctx, carve, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("block1"), data)
+
+	// a block that was never received is not found, the carve is untouched
+	updated = nil
+	_, err = store.GetBlock(ctx, carve, 2)
+	require.True(t, IsNotFound(err))
+	require.Empty(t, updated)
+
+	// a received block that is missing expires the carve
+	require.NoError(t, fs.Delete(ctx, "carves/2023/03/16/09/7/0"))
+	_, err = store.GetBlock(ctx, carve, 0)
+	require.True(t, IsNotFound(err))
+	require.Len(t, updated, 1)
+	require.True(t, updated[0].Expired)
+}
+
+func TestCarveStoreCleanup(t *testing.T) {
+	ctx := context.Background()
+	fs, err := NewFilesystemStore(t.TempDir())
+	require.NoError(t, err)
+
+	ds := new(mock.Store)
+	ds.UpdateCarveFunc = func(ctx context.Context, metadata *fleet.CarveMetadata) error {
+		return nil
+	}
+	ds.CleanupCarvesFunc = func(ctx context.Context, now time.Time) (int, error) {
+		return 3, nil
+	}
+	store := NewCarveStore(fs, "", ds)
+
+	now := time.Date(2023, 3, 16, 9, 30, 0, 0, time.UTC)
+	for i, createdAt := range []time.Time{
+		now.Add(-48 * time.Hour),
+		now.Add(-25 * time.Hour),
+		// created during the 9h hour of the day before, the carves created
+		// after 9:30 that day are not expired yet.
+		now.Add(-24*time.Hour - 10*time.Minute),
+		now.Add(-time.Hour),
+	} {
+		carve := &fleet.CarveMetadata{ID: int64(i + 1), CreatedAt: createdAt, MaxBlock: 1}
+		require.NoError(t, store.NewBlock(ctx, carve, 0, []byte("data")))
+	}
+	// unrelated object
+	require.NoError(t, fs.Put(ctx, "other", []byte("other")))
+
+	expired, err := store.CleanupCarves(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, expired)
+	assert.True(t, ds.CleanupCarvesFuncInvoked)
+
+	keys, err := fs.List(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2023/03/15/09/3/0", "2023/03/16/08/4/0", "other"}, keys)
+}
@@ -0,0 +1,101 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+// encryptedBlockHeader prefixes the encrypted blocks, to tell them apart
+// from blocks stored before encryption was enabled.
+var encryptedBlockHeader = []byte("fleetenc1")
+
+// EncryptedCarveStore wraps a fleet.CarveStore to encrypt the blocks of data
+// with AES-256-GCM before they are stored, and decrypt them when they are
+// read. The wrapped store must store and return each block as is, which is
+// not the case of the S3 carve store (it reads blocks by byte range of the
+// whole carve).
+type EncryptedCarveStore struct {
+	fleet.CarveStore
+	aead cipher.AEAD
+}
+
+// ParseEncryptionKey decodes a base64-encoded 32 bytes key.
+func ParseEncryptionKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewEncryptedCarveStore returns a carve store that encrypts the blocks of
+// store with key, which must be 32 bytes long.
+func NewEncryptedCarveStore(store fleet.CarveStore, key []byte) (*EncryptedCarveStore, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
+	return &EncryptedCarveStore{CarveStore: store, aead: aead}, nil
+}
+
+// additionalData binds the encrypted block to its carve and position, so that
+// blocks can't be swapped undetected.
+func additionalData(metadata *fleet.CarveMetadata, blockID int64) []byte {
+	return []byte(fmt.Sprintf("%d/%d", metadata.ID, blockID))
+}
+
+// NewBlock encrypts and stores a new block for a specific carve
+func (e *EncryptedCarveStore) NewBlock(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64, data []byte) error {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return ctxerr.Wrap(ctx, err, "generate carve block nonce")
+	}
+
+	out := make([]byte, 0, len(encryptedBlockHeader)+len(nonce)+len(data)+e.aead.Overhead())
+	out = append(out, encryptedBlockHeader...)
+	out = append(out, nonce...)
+	out = e.aead.Seal(out, nonce, data, additionalData(metadata, blockID))
+	return e.CarveStore.NewBlock(ctx, metadata, blockID, out)
+}
+
+// GetBlock returns the decrypted block of data for a carve. Blocks that were
+// stored unencrypted are returned as is.
+func (e *EncryptedCarveStore) GetBlock(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64) ([]byte, error) {
+	data, err := e.CarveStore.GetBlock(ctx, metadata, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, encryptedBlockHeader) {
+		return data, nil
+	}
+
+	data = data[len(encryptedBlockHeader):]
+	if len(data) < e.aead.NonceSize() {
+		return nil, ctxerr.New(ctx, "encrypted carve block too short")
+	}
+	nonce, ciphertext := data[:e.aead.NonceSize()], data[e.aead.NonceSize():]
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, additionalData(metadata, blockID))
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "decrypt carve block")
+	}
+	return plaintext, nil
+}
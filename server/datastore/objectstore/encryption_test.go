@@ -0,0 +1,72 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEncryptionKey(t *testing.T) {
+	key, err := ParseEncryptionKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+
+	_, err = ParseEncryptionKey("not base64!")
+	require.Error(t, err)
+	_, err = ParseEncryptionKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.ErrorContains(t, err, "must be 32 bytes")
+}
+
+func TestEncryptedCarveStore(t *testing.T) {
+	ctx := context.Background()
+
+	ds := new(mock.Store)
+	blocks := make(map[int64][]byte)
+	ds.NewBlockFunc = func(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64, data []byte) error {
+		blocks[blockID] = data
+		return nil
+	}
+	ds.GetBlockFunc = func(ctx context.Context, metadata *fleet.CarveMetadata, blockID int64) ([]byte, error) {
+		return blocks[blockID], nil
+	}
+
+	_, err := NewEncryptedCarveStore(ds, []byte("short"))
+	require.Error(t, err)
+
+	store, err := NewEncryptedCarveStore(ds, bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	carve := &fleet.CarveMetadata{ID: 1}
+	require.NoError(t, store.NewBlock(ctx, carve, 0, []byte("secret data")))
+	require.NoError(t, store.NewBlock(ctx, carve, 1, []byte("more secret data")))
+	// the stored blocks are encrypted
+	require.NotContains(t, string(blocks[0]), "secret")
+	require.True(t, bytes.HasPrefix(blocks[0], encryptedBlockHeader))
+
+	data, err := store.GetBlock(ctx, carve, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret data"), data)
+
+	// swapped blocks fail to decrypt
+	blocks[0], blocks[1] = blocks[1], blocks[0]
+	_, err = store.GetBlock(ctx, carve, 0)
+	require.ErrorContains(t, err, "decrypt carve block")
+
+	// a different key fails to decrypt
+	otherStore, err := NewEncryptedCarveStore(ds, bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = otherStore.GetBlock(ctx, carve, 1)
+	require.ErrorContains(t, err, "decrypt carve block")
+
+	// blocks stored before encryption was enabled are returned as is
+	blocks[2] = []byte("plain")
+	data, err = store.GetBlock(ctx, carve, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plain"), data)
+}
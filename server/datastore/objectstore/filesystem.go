@@ -0,0 +1,147 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FilesystemStore is a Store that keeps the objects as files under a root
+// directory, which can be local or on a filesystem shared by multiple Fleet
+// servers.
+type FilesystemStore struct {
+	root string
+}
+
+var _ Store = (*FilesystemStore)(nil)
+
+// NewFilesystemStore creates a store rooted at dir, creating the directory if
+// it doesn't exist.
+func NewFilesystemStore(dir string) (*FilesystemStore, error) {
+	if dir == "" {
+		return nil, errors.New("filesystem store: missing directory")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("filesystem store: create directory: %w", err)
+	}
+	return &FilesystemStore{root: filepath.Clean(dir)}, nil
+}
+
+func (s *FilesystemStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file first and renames it, so that
+// concurrent readers never see a partially written object.
+func (s *FilesystemStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create object file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write object file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync object file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close object file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename object file: %w", err)
+	}
+	return nil
+}
+
+func (s *FilesystemStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, NewNotFoundError(key)
+		}
+		return nil, fmt.Errorf("read object file: %w", err)
+	}
+	return data, nil
+}
+
+func (s *FilesystemStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// removed concurrently
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if d.IsDir() {
+			// skip the directories that can't contain keys with the prefix
+			if key != "." && !strings.HasPrefix(key+"/", prefix) && !strings.HasPrefix(prefix, key+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			// temporary files of in-progress writes
+			return nil
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list object files: %w", err)
+	}
+	return keys, nil
+}
+
+// Delete removes the files of the objects along with the directories left
+// empty.
+func (s *FilesystemStore) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		path, err := s.path(key)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("delete object file: %w", err)
+		}
+		for dir := filepath.Dir(path); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+			// fails if the directory is not empty, which is expected
+			if err := os.Remove(dir); err != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
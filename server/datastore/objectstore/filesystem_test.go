@@ -0,0 +1,64 @@
+package objectstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFilesystemStore(filepath.Join(dir, "carves"))
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "a/b")
+	require.True(t, IsNotFound(err))
+
+	require.NoError(t, store.Put(ctx, "a/b", []byte("ab")))
+	require.NoError(t, store.Put(ctx, "a/c/d", []byte("acd")))
+	require.NoError(t, store.Put(ctx, "e", []byte("e")))
+	// overwrite
+	require.NoError(t, store.Put(ctx, "e", []byte("ee")))
+
+	data, err := store.Get(ctx, "a/c/d")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("acd"), data)
+	data, err = store.Get(ctx, "e")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ee"), data)
+
+	keys, err := store.List(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a/b", "a/c/d", "e"}, keys)
+	keys, err = store.List(ctx, "a/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a/b", "a/c/d"}, keys)
+	keys, err = store.List(ctx, "a/c")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a/c/d"}, keys)
+	keys, err = store.List(ctx, "x")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, store.Delete(ctx, "a/c/d", "missing"))
+	_, err = store.Get(ctx, "a/c/d")
+	require.True(t, IsNotFound(err))
+	// the empty directory was removed, but not the root
+	_, err = os.Stat(filepath.Join(dir, "carves", "a", "c"))
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, store.Delete(ctx, "a/b", "e"))
+	_, err = os.Stat(filepath.Join(dir, "carves"))
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/a", "a/", "a//b", "../a", "a/../../b", "./a"} {
+		require.Error(t, store.Put(ctx, key, nil), key)
+		_, err := store.Get(ctx, key)
+		require.Error(t, err, key)
+		require.False(t, IsNotFound(err), key)
+	}
+}
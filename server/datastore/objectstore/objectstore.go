@@ -0,0 +1,66 @@
+// Package objectstore provides a minimal abstraction over object stores (a
+// filesystem directory, an S3-compatible bucket, etc.) and a file carving
+// store built on top of it.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+// Store is a flat key/value object store. Keys are slash-separated paths.
+type Store interface {
+	// Put stores the data under the key, replacing any existing object.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the data stored under the key, or an error that satisfies
+	// IsNotFound if there is no such object.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys of all the objects whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete deletes the objects with the given keys, missing objects are
+	// ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type notFoundError struct {
+	key string
+}
+
+var _ fleet.NotFoundError = notFoundError{}
+
+// NewNotFoundError returns the error that Store implementations must return
+// when the requested object doesn't exist.
+func NewNotFoundError(key string) error {
+	return notFoundError{key: key}
+}
+
+func (e notFoundError) Error() string {
+	return "object not found: " + e.key
+}
+
+func (e notFoundError) IsNotFound() bool {
+	return true
+}
+
+// IsNotFound returns whether the error is a missing object error.
+func IsNotFound(err error) bool {
+	var nfe notFoundError
+	return errors.As(err, &nfe)
+}
+
+// validateKey checks that the key is a clean relative path, so that it can
+// safely be mapped to a file path.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return errors.New("invalid object key: " + key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return errors.New("invalid object key: " + key)
+		}
+	}
+	return nil
+}
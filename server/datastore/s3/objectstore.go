@@ -0,0 +1,98 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/fleetdm/fleet/v4/server/config"
+	"github.com/fleetdm/fleet/v4/server/datastore/objectstore"
+)
+
+// maxDeleteKeys is the maximum number of keys of a DeleteObjects request.
+const maxDeleteKeys = 1000
+
+// ObjectStore is an objectstore.Store relying on AWS S3 or any S3-compatible
+// storage (e.g. MinIO, or Google Cloud Storage through its interoperability
+// API). The configured prefix is not applied to the keys.
+type ObjectStore struct {
+	*s3store
+}
+
+var _ objectstore.Store = (*ObjectStore)(nil)
+
+// NewObjectStore creates a new object store with the given config
+func NewObjectStore(config config.S3Config) (*ObjectStore, error) {
+	s3store, err := newS3store(config)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectStore{s3store}, nil
+}
+
+func (o *ObjectStore) Put(ctx context.Context, key string, data []byte) error {
+	_, err := o.s3client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: &o.bucket,
+		Key:    &key,
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (o *ObjectStore) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := o.s3client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: &o.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, objectstore.NewNotFoundError(key)
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	return ioutil.ReadAll(res.Body)
+}
+
+func (o *ObjectStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := o.s3client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: &o.bucket,
+		Prefix: &prefix,
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (o *ObjectStore) Delete(ctx context.Context, keys ...string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxDeleteKeys {
+			n = maxDeleteKeys
+		}
+		objs := make([]*s3.ObjectIdentifier, 0, n)
+		for _, key := range keys[:n] {
+			objs = append(objs, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+		if _, err := o.s3client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: &o.bucket,
+			Delete: &s3.Delete{Objects: objs, Quiet: aws.Bool(true)},
+		}); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package fleet
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 //go:generate go run gen_activity_doc.go ../../docs/Using-Fleet/Audit-Activities.md
@@ -57,6 +58,22 @@ var ActivityDetailsList = []ActivityDetails{
 
 	ActivityTypeEnabledMacosDiskEncryption{},
 	ActivityTypeDisabledMacosDiskEncryption{},
+
+	ActivityTypeTransferredHostsByLifecycleRule{},
+	ActivityTypeDeletedHostsByLifecycleRule{},
+
+	ActivityTypeAssignedHostToTeamByRule{},
+	ActivityTypeEditedLabelMembership{},
+	ActivityTypeCreatedUpdateRollout{},
+	ActivityTypeEditedUpdateRollout{},
+	ActivityTypeDeletedUpdateRollout{},
+	ActivityTypeHaltedUpdateRollout{},
+	ActivityTypeCreatedVulnerabilityException{},
+	ActivityTypeEditedVulnerabilityException{},
+	ActivityTypeDeletedVulnerabilityException{},
+
+	ActivityTypeAppliedSpecCPETranslationRule{},
+	ActivityTypeDeletedCPETranslationRule{},
 }
 
 type ActivityDetails interface {
@@ -845,6 +862,368 @@ func (a ActivityTypeDisabledMacosDiskEncryption) Documentation() (activity, deta
 }`
 }
 
+type ActivityTypeTransferredHostsByLifecycleRule struct {
+	TeamID           uint     `json:"team_id"`
+	TeamName         string   `json:"team_name"`
+	NewTeamID        uint     `json:"new_team_id"`
+	NewTeamName      string   `json:"new_team_name"`
+	HostIDs          []uint   `json:"host_ids"`
+	HostDisplayNames []string `json:"host_display_names"`
+}
+
+func (a ActivityTypeTransferredHostsByLifecycleRule) ActivityName() string {
+	return "transferred_hosts_by_lifecycle_rule"
+}
+
+func (a ActivityTypeTransferredHostsByLifecycleRule) Documentation() (activity, details, detailsExample string) {
+	return `Generated when the host lifecycle rules of a team transfer offline hosts to another team. This activity has no actor.`,
+		`This activity contains the following fields:
+- "team_id": The ID of the team the hosts were transferred from.
+- "team_name": The name of the team the hosts were transferred from.
+- "new_team_id": The ID of the team the hosts were transferred to.
+- "new_team_name": The name of the team the hosts were transferred to.
+- "host_ids": IDs of the transferred hosts.
+- "host_display_names": Display names of the transferred hosts, in the same order as the IDs.`, `{
+  "team_id": 123,
+  "team_name": "Workstations",
+  "new_team_id": 456,
+  "new_team_name": "Quarantine",
+  "host_ids": [1, 2],
+  "host_display_names": ["alice-macbook", "bob-macbook"]
+}`
+}
+
+type ActivityTypeDeletedHostsByLifecycleRule struct {
+	TeamID           uint     `json:"team_id"`
+	TeamName         string   `json:"team_name"`
+	HostIDs          []uint   `json:"host_ids"`
+	HostDisplayNames []string `json:"host_display_names"`
+}
+
+func (a ActivityTypeDeletedHostsByLifecycleRule) ActivityName() string {
+	return "deleted_hosts_by_lifecycle_rule"
+}
+
+func (a ActivityTypeDeletedHostsByLifecycleRule) Documentation() (activity, details, detailsExample string) {
+	return `Generated when the host lifecycle rules of a team delete offline hosts. This activity has no actor.`,
+		`This activity contains the following fields:
+- "team_id": The ID of the team the hosts were deleted from.
+- "team_name": The name of the team the hosts were deleted from.
+- "host_ids": IDs of the deleted hosts.
+- "host_display_names": Display names of the deleted hosts, in the same order as the IDs.`, `{
+  "team_id": 123,
+  "team_name": "Quarantine",
+  "host_ids": [1, 2],
+  "host_display_names": ["alice-macbook", "bob-macbook"]
+}`
+}
+
+type ActivityTypeAssignedHostToTeamByRule struct {
+	HostID          uint    `json:"host_id"`
+	HostDisplayName string  `json:"host_display_name"`
+	FromTeamID      *uint   `json:"from_team_id"`
+	FromTeamName    *string `json:"from_team_name"`
+	TeamID          uint    `json:"team_id"`
+	TeamName        string  `json:"team_name"`
+	RuleIndex       int     `json:"rule_index"`
+}
+
+func (a ActivityTypeAssignedHostToTeamByRule) ActivityName() string {
+	return "assigned_host_to_team_by_rule"
+}
+
+func (a ActivityTypeAssignedHostToTeamByRule) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a team assignment rule transfers a host to a team. This activity has no actor.`,
+		`This activity contains the following fields:
+- "host_id": ID of the host.
+- "host_display_name": Display name of the host.
+- "from_team_id": The ID of the team the host was in, null if it was not in a team.
+- "from_team_name": The name of the team the host was in, null if it was not in a team.
+- "team_id": The ID of the team the host was transferred to.
+- "team_name": The name of the team the host was transferred to.
+- "rule_index": The index of the team assignment rule that matched the host, starting at 0.`, `{
+  "host_id": 1,
+  "host_display_name": "alice-macbook",
+  "from_team_id": null,
+  "from_team_name": null,
+  "team_id": 123,
+  "team_name": "Workstations",
+  "rule_index": 0
+}`
+}
+
+type ActivityTypeEditedLabelMembership struct {
+	LabelID        uint                  `json:"label_id"`
+	LabelName      string                `json:"label_name"`
+	Source         LabelMembershipSource `json:"source"`
+	AddedCount     int                   `json:"added_count"`
+	RemovedCount   int                   `json:"removed_count"`
+	UnmatchedCount int                   `json:"unmatched_count"`
+}
+
+func (a ActivityTypeEditedLabelMembership) ActivityName() string {
+	return "edited_label_membership"
+}
+
+func (a ActivityTypeEditedLabelMembership) Documentation() (activity, details, detailsExample string) {
+	return `Generated when the hosts of a manual label are replaced from an uploaded CSV file or by the sync from its hosts URL. The activity has no actor when generated by the sync.`,
+		`This activity contains the following fields:
+- "label_id": The ID of the label.
+- "label_name": The name of the label.
+- "source": The source of the hosts, either "csv" or "url".
+- "added_count": The number of hosts added to the label.
+- "removed_count": The number of hosts removed from the label.
+- "unmatched_count": The number of host identifiers that did not match any host.`, `{
+  "label_id": 7,
+  "label_name": "CMDB - Finance laptops",
+  "source": "url",
+  "added_count": 12,
+  "removed_count": 3,
+  "unmatched_count": 1
+}`
+}
+
+type ActivityTypeCreatedUpdateRollout struct {
+	RolloutID   uint   `json:"rollout_id"`
+	RolloutName string `json:"rollout_name"`
+	Target      string `json:"target"`
+	Channel     string `json:"channel"`
+	Percentage  uint   `json:"percentage"`
+}
+
+func (a ActivityTypeCreatedUpdateRollout) ActivityName() string {
+	return "created_update_rollout"
+}
+
+func (a ActivityTypeCreatedUpdateRollout) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user creates an update rollout, which pins an Orbit target of a percentage of hosts to an update channel.`,
+		`This activity contains the following fields:
+- "rollout_id": The ID of the rollout.
+- "rollout_name": The name of the rollout.
+- "target": The Orbit target of the rollout, one of "orbit", "osqueryd" or "desktop".
+- "channel": The update channel the hosts are pinned to.
+- "percentage": The percentage of the hosts in the scope of the rollout that are pinned.`, `{
+  "rollout_id": 3,
+  "rollout_name": "osquery 5.8.2 canary",
+  "target": "osqueryd",
+  "channel": "5.8.2",
+  "percentage": 5
+}`
+}
+
+type ActivityTypeEditedUpdateRollout struct {
+	RolloutID   uint                `json:"rollout_id"`
+	RolloutName string              `json:"rollout_name"`
+	Target      string              `json:"target"`
+	Channel     string              `json:"channel"`
+	Percentage  uint                `json:"percentage"`
+	Status      UpdateRolloutStatus `json:"status"`
+}
+
+func (a ActivityTypeEditedUpdateRollout) ActivityName() string {
+	return "edited_update_rollout"
+}
+
+func (a ActivityTypeEditedUpdateRollout) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user modifies an update rollout, e.g. to widen it, halt it or resume it.`,
+		`This activity contains the following fields:
+- "rollout_id": The ID of the rollout.
+- "rollout_name": The name of the rollout.
+- "target": The Orbit target of the rollout, one of "orbit", "osqueryd" or "desktop".
+- "channel": The update channel the hosts are pinned to.
+- "percentage": The percentage of the hosts in the scope of the rollout that are pinned.
+- "status": The status of the rollout, either "active" or "halted".`, `{
+  "rollout_id": 3,
+  "rollout_name": "osquery 5.8.2 canary",
+  "target": "osqueryd",
+  "channel": "5.8.2",
+  "percentage": 25,
+  "status": "active"
+}`
+}
+
+type ActivityTypeDeletedUpdateRollout struct {
+	RolloutID   uint   `json:"rollout_id"`
+	RolloutName string `json:"rollout_name"`
+	Target      string `json:"target"`
+	Channel     string `json:"channel"`
+}
+
+func (a ActivityTypeDeletedUpdateRollout) ActivityName() string {
+	return "deleted_update_rollout"
+}
+
+func (a ActivityTypeDeletedUpdateRollout) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user deletes an update rollout. The hosts it pinned go back to the update channels they were packaged with.`,
+		`This activity contains the following fields:
+- "rollout_id": The ID of the rollout.
+- "rollout_name": The name of the rollout.
+- "target": The Orbit target of the rollout, one of "orbit", "osqueryd" or "desktop".
+- "channel": The update channel the hosts were pinned to.`, `{
+  "rollout_id": 3,
+  "rollout_name": "osquery 5.8.2 canary",
+  "target": "osqueryd",
+  "channel": "5.8.2"
+}`
+}
+
+type ActivityTypeHaltedUpdateRollout struct {
+	RolloutID         uint   `json:"rollout_id"`
+	RolloutName       string `json:"rollout_name"`
+	Target            string `json:"target"`
+	Channel           string `json:"channel"`
+	HostsCount        uint   `json:"hosts_count"`
+	OfflineHostsCount uint   `json:"offline_hosts_count"`
+}
+
+func (a ActivityTypeHaltedUpdateRollout) ActivityName() string {
+	return "halted_update_rollout"
+}
+
+func (a ActivityTypeHaltedUpdateRollout) Documentation() (activity, details, detailsExample string) {
+	return `Generated when Fleet halts an update rollout because too many of the hosts it pinned stopped checking in. The activity has no actor.`,
+		`This activity contains the following fields:
+- "rollout_id": The ID of the rollout.
+- "rollout_name": The name of the rollout.
+- "target": The Orbit target of the rollout, one of "orbit", "osqueryd" or "desktop".
+- "channel": The update channel the hosts are pinned to.
+- "hosts_count": The number of hosts pinned by the rollout.
+- "offline_hosts_count": The number of pinned hosts that stopped checking in.`, `{
+  "rollout_id": 3,
+  "rollout_name": "osquery 5.8.2 canary",
+  "target": "osqueryd",
+  "channel": "5.8.2",
+  "hosts_count": 40,
+  "offline_hosts_count": 6
+}`
+}
+
+type ActivityTypeCreatedVulnerabilityException struct {
+	ExceptionID uint                         `json:"exception_id"`
+	CVE         string                       `json:"cve"`
+	Reason      VulnerabilityExceptionReason `json:"reason"`
+	TeamID      *uint                        `json:"team_id"`
+	SoftwareID  *uint                        `json:"software_id"`
+	HostID      *uint                        `json:"host_id"`
+	ExpiresAt   *time.Time                   `json:"expires_at"`
+}
+
+func (a ActivityTypeCreatedVulnerabilityException) ActivityName() string {
+	return "created_vulnerability_exception"
+}
+
+func (a ActivityTypeCreatedVulnerabilityException) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user creates a vulnerability exception, which suppresses a CVE as a false positive or an accepted risk.`,
+		`This activity contains the following fields:
+- "exception_id": The ID of the exception.
+- "cve": The suppressed CVE.
+- "reason": The reason of the exception, either "false_positive" or "accepted_risk".
+- "team_id": The ID of the team the exception is restricted to, or null.
+- "software_id": The ID of the software the exception is restricted to, or null.
+- "host_id": The ID of the host the exception is restricted to, or null.
+- "expires_at": The time the exception expires at, or null if it never expires.`, `{
+  "exception_id": 7,
+  "cve": "CVE-2022-30190",
+  "reason": "accepted_risk",
+  "team_id": 2,
+  "software_id": null,
+  "host_id": null,
+  "expires_at": "2023-06-30T00:00:00Z"
+}`
+}
+
+type ActivityTypeEditedVulnerabilityException struct {
+	ExceptionID uint                         `json:"exception_id"`
+	CVE         string                       `json:"cve"`
+	Reason      VulnerabilityExceptionReason `json:"reason"`
+	ExpiresAt   *time.Time                   `json:"expires_at"`
+}
+
+func (a ActivityTypeEditedVulnerabilityException) ActivityName() string {
+	return "edited_vulnerability_exception"
+}
+
+func (a ActivityTypeEditedVulnerabilityException) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user modifies a vulnerability exception, e.g. to extend it.`,
+		`This activity contains the following fields:
+- "exception_id": The ID of the exception.
+- "cve": The suppressed CVE.
+- "reason": The reason of the exception, either "false_positive" or "accepted_risk".
+- "expires_at": The time the exception expires at, or null if it never expires.`, `{
+  "exception_id": 7,
+  "cve": "CVE-2022-30190",
+  "reason": "accepted_risk",
+  "expires_at": "2023-09-30T00:00:00Z"
+}`
+}
+
+type ActivityTypeDeletedVulnerabilityException struct {
+	ExceptionID uint   `json:"exception_id"`
+	CVE         string `json:"cve"`
+}
+
+func (a ActivityTypeDeletedVulnerabilityException) ActivityName() string {
+	return "deleted_vulnerability_exception"
+}
+
+func (a ActivityTypeDeletedVulnerabilityException) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user deletes a vulnerability exception. The CVE is no longer suppressed by it.`,
+		`This activity contains the following fields:
+- "exception_id": The ID of the exception.
+- "cve": The CVE that was suppressed.`, `{
+  "exception_id": 7,
+  "cve": "CVE-2022-30190"
+}`
+}
+
+type ActivityTypeAppliedSpecCPETranslationRule struct {
+	Rules []*CPETranslationRuleSpec `json:"rules"`
+}
+
+func (a ActivityTypeAppliedSpecCPETranslationRule) ActivityName() string {
+	return "applied_spec_cpe_translation_rule"
+}
+
+func (a ActivityTypeAppliedSpecCPETranslationRule) Documentation() (activity, details, detailsExample string) {
+	return `Generated when applying custom CPE translation rule specs.`,
+		`This activity contains a field "rules" where each item is a CPE translation rule spec with the following fields:
+- "name": Name of the applied rule.
+- "description": Description of the rule.
+- "software": The criteria the software must match ("name", "bundle_identifier" and "source").
+- "filter": The filter of the CPE dictionary entries the software is translated to ("product", "vendor", "target_sw" and "skip").`, `{
+  "rules": [
+    {
+      "name": "acme-vpn",
+      "description": "Acme VPN client",
+      "software": {
+        "name": ["/^Acme VPN/"],
+        "source": ["apps"]
+      },
+      "filter": {
+        "product": ["vpn_client"],
+        "vendor": ["acme"]
+      }
+    }
+  ]
+}`
+}
+
+type ActivityTypeDeletedCPETranslationRule struct {
+	Name string `json:"name"`
+}
+
+func (a ActivityTypeDeletedCPETranslationRule) ActivityName() string {
+	return "deleted_cpe_translation_rule"
+}
+
+func (a ActivityTypeDeletedCPETranslationRule) Documentation() (activity, details, detailsExample string) {
+	return `Generated when a user deletes a custom CPE translation rule.`,
+		`This activity contains the following fields:
+- "name": Name of the deleted rule.`, `{
+  "name": "acme-vpn"
+}`
+}
+
 // LogRoleChangeActivities logs activities for each role change, globally and one for each change in teams.
 func LogRoleChangeActivities(ctx context.Context, ds Datastore, adminUser *User, oldGlobalRole *string, oldTeamRoles []UserTeam, user *User) error {
 	if user.GlobalRole != nil && (oldGlobalRole == nil || *oldGlobalRole != *user.GlobalRole) {
@@ -0,0 +1,139 @@
+package fleet
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ActivityWebhookSettings holds the settings of a webhook subscription that
+// receives the activities generated in Fleet.
+type ActivityWebhookSettings struct {
+	// DestinationURL is the webhook's URL.
+	DestinationURL string `json:"destination_url"`
+	// Secret is the shared secret used to sign the payloads sent to the
+	// webhook (HMAC-SHA256 of the request body).
+	Secret string `json:"secret"`
+	// ActivityTypes is the list of activity types (as returned by
+	// ActivityDetails.ActivityName) that are sent to the webhook. An empty list
+	// means that all activities are sent.
+	ActivityTypes []string `json:"activity_types"`
+}
+
+// Copy returns a deep copy of the webhook settings.
+func (w *ActivityWebhookSettings) Copy() *ActivityWebhookSettings {
+	if w == nil {
+		return nil
+	}
+	clone := *w
+	if w.ActivityTypes != nil {
+		clone.ActivityTypes = make([]string, len(w.ActivityTypes))
+		copy(clone.ActivityTypes, w.ActivityTypes)
+	}
+	return &clone
+}
+
+// MatchesActivityType returns true if activities of the provided type must be
+// sent to that webhook.
+func (w *ActivityWebhookSettings) MatchesActivityType(activityType string) bool {
+	if len(w.ActivityTypes) == 0 {
+		return true
+	}
+	for _, t := range w.ActivityTypes {
+		if t == activityType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateActivitiesWebhooks checks that the activities webhooks are properly
+// configured. It adds any error it finds to the invalid argument error, that
+// can then be checked after the call for errors using invalid.HasErrors.
+func ValidateActivitiesWebhooks(webhooks []*ActivityWebhookSettings, invalid *InvalidArgumentError) {
+	knownTypes := make(map[string]bool, len(ActivityDetailsList))
+	for _, act := range ActivityDetailsList {
+		knownTypes[act.ActivityName()] = true
+	}
+
+	seen := make(map[string]bool, len(webhooks))
+	for i, w := range webhooks {
+		key := fmt.Sprintf("activities_webhooks[%d]", i)
+		if w.DestinationURL == "" {
+			invalid.Append(key+".destination_url", "destination_url is required for the activities webhook")
+			continue
+		}
+		if u, err := url.Parse(w.DestinationURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid.Append(key+".destination_url", "destination_url must be a valid http or https URL")
+		}
+		if seen[w.DestinationURL] {
+			invalid.Appendf(key+".destination_url", "duplicate activities webhook for url %s", w.DestinationURL)
+		}
+		seen[w.DestinationURL] = true
+
+		for _, t := range w.ActivityTypes {
+			if !knownTypes[t] {
+				invalid.Appendf(key+".activity_types", "unknown activity type: %s", t)
+			}
+		}
+	}
+}
+
+// ActivityWebhookDeliveryStatus is the status of the delivery of an activity
+// to a webhook.
+type ActivityWebhookDeliveryStatus string
+
+// The possible statuses of a delivery.
+//
+//	Pending ───► Success
+//	  │
+//	  │
+//	  └──────► Failed
+const (
+	ActivityWebhookDeliveryPending ActivityWebhookDeliveryStatus = "pending"
+	ActivityWebhookDeliverySuccess ActivityWebhookDeliveryStatus = "success"
+	ActivityWebhookDeliveryFailed  ActivityWebhookDeliveryStatus = "failed"
+)
+
+// IsValid returns true if the status is one of the known statuses.
+func (s ActivityWebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case ActivityWebhookDeliveryPending, ActivityWebhookDeliverySuccess, ActivityWebhookDeliveryFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// ActivityWebhookDelivery is the record of the delivery of an activity to an
+// activities webhook.
+type ActivityWebhookDelivery struct {
+	ID             uint                          `json:"id" db:"id"`
+	CreatedAt      time.Time                     `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time                     `json:"updated_at" db:"updated_at"`
+	ActivityID     uint                          `json:"activity_id" db:"activity_id"`
+	ActivityType   string                        `json:"activity_type" db:"activity_type"`
+	DestinationURL string                        `json:"destination_url" db:"destination_url"`
+	Status         ActivityWebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                           `json:"attempts" db:"attempts"`
+	ResponseStatus *int                          `json:"response_status" db:"response_status"`
+	Error          *string                       `json:"error" db:"error"`
+}
+
+// AuthzType implements authz.AuthzTyper.
+func (*ActivityWebhookDelivery) AuthzType() string {
+	return "activity_webhook_delivery"
+}
+
+// ListActivityWebhookDeliveriesOptions are the options to filter the list of
+// activity webhook deliveries.
+type ListActivityWebhookDeliveriesOptions struct {
+	ListOptions
+
+	// Status filters the deliveries by status if set.
+	Status ActivityWebhookDeliveryStatus `query:"status,optional"`
+	// ActivityType filters the deliveries by activity type if set.
+	ActivityType string `query:"activity_type,optional"`
+	// ActivityID filters the deliveries by activity if set.
+	ActivityID *uint `query:"activity_id,optional"`
+}
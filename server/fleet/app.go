@@ -100,6 +100,9 @@ type SMTPSettings struct {
 type VulnerabilitySettings struct {
 	// DatabasesPath is the directory where fleet will store the different databases
 	DatabasesPath string `json:"databases_path"`
+	// RemediationSLA defines the number of days the CVEs detected on hosts have
+	// to be resolved in, depending on their severity.
+	RemediationSLA VulnerabilityRemediationSLA `json:"remediation_sla"`
 }
 
 // MDM is part of AppConfig and defines the mdm settings.
@@ -255,6 +258,11 @@ type AppConfig struct {
 
 	MDM MDM `json:"mdm"`
 
+	// TeamAssignmentRules are the rules that assign hosts to teams based on
+	// their attributes, evaluated in order on enrollment and after the host
+	// details are refreshed.
+	TeamAssignmentRules TeamAssignmentRules `json:"team_assignment_rules,omitempty"`
+
 	// when true, strictDecoding causes the UnmarshalJSON method to return an
 	// error if there are unknown fields in the raw JSON.
 	strictDecoding bool
@@ -318,6 +326,12 @@ func (c *AppConfig) Copy() *AppConfig {
 		clone.WebhookSettings.FailingPoliciesWebhook.PolicyIDs = make([]uint, len(c.WebhookSettings.FailingPoliciesWebhook.PolicyIDs))
 		copy(clone.WebhookSettings.FailingPoliciesWebhook.PolicyIDs, c.WebhookSettings.FailingPoliciesWebhook.PolicyIDs)
 	}
+	if c.WebhookSettings.ActivitiesWebhooks != nil {
+		clone.WebhookSettings.ActivitiesWebhooks = make([]*ActivityWebhookSettings, len(c.WebhookSettings.ActivitiesWebhooks))
+		for i, w := range c.WebhookSettings.ActivitiesWebhooks {
+			clone.WebhookSettings.ActivitiesWebhooks[i] = w.Copy()
+		}
+	}
 	if c.Integrations.Jira != nil {
 		clone.Integrations.Jira = make([]*JiraIntegration, len(c.Integrations.Jira))
 		for i, j := range c.Integrations.Jira {
@@ -332,12 +346,37 @@ func (c *AppConfig) Copy() *AppConfig {
 			clone.Integrations.Zendesk[i] = &zd
 		}
 	}
+	if c.Integrations.Slack != nil {
+		clone.Integrations.Slack = make([]*SlackIntegration, len(c.Integrations.Slack))
+		for i, s := range c.Integrations.Slack {
+			sl := *s
+			clone.Integrations.Slack[i] = &sl
+		}
+	}
+	if c.Integrations.MicrosoftTeams != nil {
+		clone.Integrations.MicrosoftTeams = make([]*MicrosoftTeamsIntegration, len(c.Integrations.MicrosoftTeams))
+		for i, m := range c.Integrations.MicrosoftTeams {
+			mt := *m
+			clone.Integrations.MicrosoftTeams[i] = &mt
+		}
+	}
+	if c.Integrations.CustomHTTP != nil {
+		clone.Integrations.CustomHTTP = make([]*CustomHTTPIntegration, len(c.Integrations.CustomHTTP))
+		for i, ch := range c.Integrations.CustomHTTP {
+			clone.Integrations.CustomHTTP[i] = ch.Copy()
+		}
+	}
 
 	if c.MDM.MacOSSettings.CustomSettings != nil {
 		clone.MDM.MacOSSettings.CustomSettings = make([]string, len(c.MDM.MacOSSettings.CustomSettings))
 		copy(clone.MDM.MacOSSettings.CustomSettings, c.MDM.MacOSSettings.CustomSettings)
 	}
 
+	if c.TeamAssignmentRules != nil {
+		clone.TeamAssignmentRules = make(TeamAssignmentRules, len(c.TeamAssignmentRules))
+		copy(clone.TeamAssignmentRules, c.TeamAssignmentRules)
+	}
+
 	return &clone
 }
 
@@ -414,6 +453,9 @@ type WebhookSettings struct {
 	HostStatusWebhook      HostStatusWebhookSettings      `json:"host_status_webhook"`
 	FailingPoliciesWebhook FailingPoliciesWebhookSettings `json:"failing_policies_webhook"`
 	VulnerabilitiesWebhook VulnerabilitiesWebhookSettings `json:"vulnerabilities_webhook"`
+	// ActivitiesWebhooks is the list of webhook subscriptions that receive
+	// the activities as they are created.
+	ActivitiesWebhooks []*ActivityWebhookSettings `json:"activities_webhooks"`
 	// Interval is the interval for running the webhooks.
 	//
 	// This value currently configures both the host status and failing policies webhooks.
@@ -440,6 +482,10 @@ type FailingPoliciesWebhookSettings struct {
 	HostBatchSize int `json:"host_batch_size"`
 }
 
+func (f FailingPoliciesWebhookSettings) isZero() bool {
+	return !f.Enable && f.DestinationURL == "" && len(f.PolicyIDs) == 0 && f.HostBatchSize == 0
+}
+
 // VulnerabilitiesWebhookSettings holds the settings for vulnerabilities webhooks.
 type VulnerabilitiesWebhookSettings struct {
 	// Enable indicates whether the webhook for vulnerabilities is enabled.
@@ -619,7 +665,8 @@ type ListQueryOptions struct {
 type ListActivitiesOptions struct {
 	ListOptions
 
-	Streamed *bool
+	Streamed          *bool
+	WebhooksProcessed *bool
 }
 
 // ApplySpecOptions are the options available when applying a YAML or JSON spec.
@@ -804,6 +851,39 @@ type KafkaRESTConfig struct {
 	ProxyHost   string `json:"proxyhost"`
 }
 
+// CompositeLoggingConfig is the configuration of the "composite" logging
+// plugin, used when the logs are sent to multiple plugins.
+type CompositeLoggingConfig struct {
+	Plugins []LoggingPlugin   `json:"plugins"`
+	Routes  []config.LogRoute `json:"routes,omitempty"`
+}
+
+// HTTPLogConfig shadows config.HTTPLogConfig only exposing a subset of fields
+type HTTPLogConfig struct {
+	StatusURL string `json:"status_url"`
+	ResultURL string `json:"result_url"`
+	AuditURL  string `json:"audit_url"`
+}
+
+// SplunkConfig shadows config.SplunkConfig only exposing a subset of fields
+type SplunkConfig struct {
+	URL         string `json:"url"`
+	StatusIndex string `json:"status_index"`
+	ResultIndex string `json:"result_index"`
+	AuditIndex  string `json:"audit_index"`
+	SourceType  string `json:"source_type"`
+	Source      string `json:"source"`
+}
+
+// ElasticsearchConfig shadows config.ElasticsearchConfig only exposing a
+// subset of fields
+type ElasticsearchConfig struct {
+	URL         string `json:"url"`
+	StatusIndex string `json:"status_index"`
+	ResultIndex string `json:"result_index"`
+	AuditIndex  string `json:"audit_index"`
+}
+
 // DeviceGlobalConfig is a subset of AppConfig with information used by the
 // device endpoints
 type DeviceGlobalConfig struct {
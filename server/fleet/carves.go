@@ -1,6 +1,7 @@
 package fleet
 
 import (
+	"math"
 	"time"
 )
 
@@ -29,11 +30,24 @@ type CarveMetadata struct {
 	Expired bool `json:"expired" db:"expired"`
 	// Error is the error message if the carve failed.
 	Error *string `json:"error" db:"error"`
+	// SHA256 is the hex-encoded SHA-256 hash of the carve contents, set once
+	// all the blocks were received.
+	SHA256 *string `json:"sha256" db:"sha256"`
+	// SHA256State is the marshaled state of the hash of the blocks received so
+	// far, cleared once all the blocks were received.
+	SHA256State []byte `json:"-" db:"sha256_state"`
 
 	// MaxBlock is the highest block number currently stored for this carve.
 	// This value is not stored directly, but generated from the carve_blocks
 	// table.
 	MaxBlock int64 `json:"max_block" db:"max_block"`
+
+	// ReceivedBytes is the number of bytes of the carve received so far. It is
+	// not stored, see ComputeProgress.
+	ReceivedBytes int64 `json:"received_bytes" db:"-"`
+	// Progress is the percentage of the carve received so far. It is not
+	// stored, see ComputeProgress.
+	Progress float64 `json:"progress" db:"-"`
 }
 
 func (c CarveMetadata) AuthzType() string {
@@ -44,6 +58,22 @@ func (c *CarveMetadata) BlocksComplete() bool {
 	return c.MaxBlock == c.BlockCount-1
 }
 
+// ComputeProgress sets the ReceivedBytes and Progress fields from the number
+// of blocks received.
+func (c *CarveMetadata) ComputeProgress() {
+	c.ReceivedBytes = (c.MaxBlock + 1) * c.BlockSize
+	if c.ReceivedBytes > c.CarveSize {
+		c.ReceivedBytes = c.CarveSize
+	}
+	if c.ReceivedBytes < 0 {
+		c.ReceivedBytes = 0
+	}
+	c.Progress = 0
+	if c.CarveSize > 0 {
+		c.Progress = math.Floor(float64(c.ReceivedBytes)/float64(c.CarveSize)*10000) / 100
+	}
+}
+
 type CarveListOptions struct {
 	ListOptions
 
@@ -0,0 +1,24 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarveMetadataComputeProgress(t *testing.T) {
+	carve := CarveMetadata{BlockCount: 3, BlockSize: 10, CarveSize: 25, MaxBlock: -1}
+	carve.ComputeProgress()
+	assert.Zero(t, carve.ReceivedBytes)
+	assert.Zero(t, carve.Progress)
+
+	carve.MaxBlock = 0
+	carve.ComputeProgress()
+	assert.EqualValues(t, 10, carve.ReceivedBytes)
+	assert.Equal(t, 40.0, carve.Progress)
+
+	carve.MaxBlock = 2
+	carve.ComputeProgress()
+	assert.EqualValues(t, 25, carve.ReceivedBytes)
+	assert.Equal(t, 100.0, carve.Progress)
+}
@@ -0,0 +1,168 @@
+package fleet
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const CPETranslationKind = "cpe_translation"
+
+// CPETranslationRuleSpec is a custom rule used to translate software to a CPE
+// of the NVD CPE dictionary, for software that fails to match with the
+// standard logic (e.g. internal or oddly named apps). Custom rules are
+// evaluated in order before the translations downloaded from
+// github.com/fleetdm/nvd, the first rule that matches the software is used.
+//
+// Rules are identified by name (unique).
+type CPETranslationRuleSpec struct {
+	// Name is the name of the rule.
+	Name string `json:"name"`
+	// Description describes the rule.
+	Description string `json:"description"`
+	// Software are the criteria the software must match.
+	Software CPETranslationRuleSoftware `json:"software"`
+	// Filter is used to find the CPE of the matching software.
+	Filter CPETranslationRuleFilter `json:"filter"`
+}
+
+func (r *CPETranslationRuleSpec) AuthzType() string {
+	return "cpe_translation_rule"
+}
+
+// CPETranslationRuleSoftware are the criteria of a CPE translation rule. A
+// software matches if it satisfies all the non-empty criteria, a criterion is
+// satisfied if any of its values matches. Values enclosed in '/' are regular
+// expressions.
+type CPETranslationRuleSoftware struct {
+	Name             []string `json:"name,omitempty"`
+	BundleIdentifier []string `json:"bundle_identifier,omitempty"`
+	Source           []string `json:"source,omitempty"`
+}
+
+// Scan implements the sql.Scanner interface
+func (s *CPETranslationRuleSoftware) Scan(val interface{}) error {
+	return scanJSON(val, s)
+}
+
+// Value implements the sql.Valuer interface
+func (s CPETranslationRuleSoftware) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// CPETranslationRuleFilter restricts the entries of the CPE dictionary the
+// matching software can be translated to, the first entry that matches is
+// used.
+type CPETranslationRuleFilter struct {
+	Product  []string `json:"product,omitempty"`
+	Vendor   []string `json:"vendor,omitempty"`
+	TargetSW []string `json:"target_sw,omitempty"`
+	// If Skip is set, the matching software is not translated to a CPE, so no
+	// NVD vulnerabilities are reported for it.
+	Skip bool `json:"skip,omitempty"`
+}
+
+// Scan implements the sql.Scanner interface
+func (f *CPETranslationRuleFilter) Scan(val interface{}) error {
+	return scanJSON(val, f)
+}
+
+// Value implements the sql.Valuer interface
+func (f CPETranslationRuleFilter) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+func scanJSON(val interface{}, dst interface{}) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, dst)
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	case nil: // sql NULL
+		return nil
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+}
+
+var errCPETranslationRuleNameEmpty = errors.New("cpe translation rule name must not be empty")
+
+// Validate checks that the rule is well-formed.
+func (r *CPETranslationRuleSpec) Validate() error {
+	if emptyString(r.Name) {
+		return errCPETranslationRuleNameEmpty
+	}
+	if len(r.Name) > 255 {
+		return fmt.Errorf("cpe translation rule name %q is too long", r.Name)
+	}
+
+	s := r.Software
+	if len(s.Name) == 0 && len(s.BundleIdentifier) == 0 && len(s.Source) == 0 {
+		return fmt.Errorf("cpe translation rule %q: at least one of software name, bundle_identifier or source is required", r.Name)
+	}
+	for _, values := range [][]string{s.Name, s.BundleIdentifier, s.Source} {
+		for _, v := range values {
+			if pattern, ok := CPETranslationPattern(v); ok {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("cpe translation rule %q: invalid regular expression %q: %w", r.Name, v, err)
+				}
+			}
+		}
+	}
+
+	f := r.Filter
+	if f.Skip {
+		if len(f.Product) > 0 || len(f.Vendor) > 0 || len(f.TargetSW) > 0 {
+			return fmt.Errorf("cpe translation rule %q: filter skip cannot be combined with product, vendor or target_sw", r.Name)
+		}
+		return nil
+	}
+	if len(f.Product) == 0 && len(f.Vendor) == 0 {
+		return fmt.Errorf("cpe translation rule %q: filter requires a product or a vendor, or skip", r.Name)
+	}
+	return nil
+}
+
+// CPETranslationPattern returns the regular expression of a value of the
+// software criteria of a CPE translation, if the value is enclosed in '/'.
+func CPETranslationPattern(v string) (string, bool) {
+	if len(v) > 2 && v[0] == '/' && v[len(v)-1] == '/' {
+		return v[1 : len(v)-1], true
+	}
+	return "", false
+}
+
+// CPEResolutionMethod is how a software was resolved to a CPE.
+type CPEResolutionMethod string
+
+const (
+	// CPEResolutionCustomTranslation is used when a custom CPE translation
+	// rule matches the software.
+	CPEResolutionCustomTranslation CPEResolutionMethod = "custom_translation"
+	// CPEResolutionTranslation is used when one of the CPE translations from
+	// github.com/fleetdm/nvd matches the software.
+	CPEResolutionTranslation CPEResolutionMethod = "translation"
+	// CPEResolutionDictionarySearch is used when no translation matches, the
+	// CPE dictionary is searched by the software name, vendor and bundle
+	// identifier.
+	CPEResolutionDictionarySearch CPEResolutionMethod = "dictionary_search"
+)
+
+// CPEResolution explains which CPE a software resolves to during
+// vulnerability processing, and why.
+type CPEResolution struct {
+	// CPE is the resolved CPE, empty if the software does not resolve to any.
+	CPE string `json:"cpe"`
+	// Method is how the CPE was looked up.
+	Method CPEResolutionMethod `json:"method"`
+	// Rule is the name of the custom CPE translation rule that matched the
+	// software, if any.
+	Rule string `json:"rule,omitempty"`
+	// Filter is the filter of the translation that matched the software, if
+	// any.
+	Filter *CPETranslationRuleFilter `json:"filter,omitempty"`
+	// Reason is a human readable explanation of the resolution.
+	Reason string `json:"reason"`
+}
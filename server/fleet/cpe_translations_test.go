@@ -0,0 +1,53 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPETranslationRuleSpecValidate(t *testing.T) {
+	software := CPETranslationRuleSoftware{Name: []string{"Acme VPN"}}
+	filter := CPETranslationRuleFilter{Product: []string{"vpn_client"}, Vendor: []string{"acme"}}
+
+	for _, tc := range []struct {
+		name string
+		spec CPETranslationRuleSpec
+		err  string
+	}{
+		{"valid", CPETranslationRuleSpec{Name: "acme", Software: software, Filter: filter}, ""},
+		{"valid skip", CPETranslationRuleSpec{Name: "acme", Software: software, Filter: CPETranslationRuleFilter{Skip: true}}, ""},
+		{"valid regexp", CPETranslationRuleSpec{
+			Name:     "acme",
+			Software: CPETranslationRuleSoftware{BundleIdentifier: []string{"/^com\\.acme\\./"}},
+			Filter:   CPETranslationRuleFilter{Vendor: []string{"acme"}},
+		}, ""},
+		{"empty name", CPETranslationRuleSpec{Name: " ", Software: software, Filter: filter}, "name must not be empty"},
+		{"no software criteria", CPETranslationRuleSpec{Name: "acme", Filter: filter}, "at least one of software name"},
+		{"invalid regexp", CPETranslationRuleSpec{
+			Name:     "acme",
+			Software: CPETranslationRuleSoftware{Name: []string{"/acme(/"}},
+			Filter:   filter,
+		}, "invalid regular expression"},
+		{"empty filter", CPETranslationRuleSpec{Name: "acme", Software: software}, "requires a product or a vendor"},
+		{"only target_sw", CPETranslationRuleSpec{
+			Name:     "acme",
+			Software: software,
+			Filter:   CPETranslationRuleFilter{TargetSW: []string{"macos"}},
+		}, "requires a product or a vendor"},
+		{"skip with product", CPETranslationRuleSpec{
+			Name:     "acme",
+			Software: software,
+			Filter:   CPETranslationRuleFilter{Product: []string{"vpn_client"}, Skip: true},
+		}, "cannot be combined"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
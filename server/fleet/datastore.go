@@ -84,6 +84,15 @@ type Datastore interface {
 	// ObserverCanRunQuery returns whether a user with an observer role is permitted to run the
 	// identified query
 	ObserverCanRunQuery(ctx context.Context, queryID uint) (bool, error)
+	// QueryStoringResultsByScheduledName returns the query scheduled with the provided name in the provided pack,
+	// if that query stores its results. It returns a not found error otherwise.
+	QueryStoringResultsByScheduledName(ctx context.Context, packName, scheduledQueryName string) (*Query, error)
+	// OverwriteQueryResultRows replaces the stored results of the query for the host with the provided rows. The
+	// number of rows stored for the query across all hosts is capped to maxRows, the rows over the cap are
+	// dropped.
+	OverwriteQueryResultRows(ctx context.Context, queryID, hostID uint, rows []json.RawMessage, fetchedAt time.Time, maxRows int) error
+	// QueryReport returns the stored results of the query, for the hosts visible with the provided filter.
+	QueryReport(ctx context.Context, queryID uint, filter TeamFilter, opts QueryReportListOptions) ([]*QueryReportRow, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// CampaignStore defines the distributed query campaign related datastore methods
@@ -108,6 +117,16 @@ type Datastore interface {
 
 	DistributedQueryCampaignsForQuery(ctx context.Context, queryID uint) ([]*DistributedQueryCampaign, error)
 
+	// SaveDistributedQueryCampaignResult persists the result of a live query campaign reported by a host, replacing
+	// any previous result of that host for the campaign.
+	SaveDistributedQueryCampaignResult(ctx context.Context, result *DistributedQueryResult) error
+	// ListDistributedQueryCampaignResults returns a page of the persisted results of a live query campaign, in the order
+	// they were received.
+	ListDistributedQueryCampaignResults(ctx context.Context, campaignID uint, opt ListOptions) ([]*DistributedQueryResult, *PaginationMetadata, error)
+	// CleanupDistributedQueryCampaignResults deletes the persisted results of the live query campaigns created before
+	// the provided time.
+	CleanupDistributedQueryCampaignResults(ctx context.Context, before time.Time) error
+
 	///////////////////////////////////////////////////////////////////////////////
 	// PackStore is the datastore interface for managing query packs.
 
@@ -186,6 +205,93 @@ type Datastore interface {
 	AsyncBatchDeleteLabelMembership(ctx context.Context, batch [][2]uint) error
 	AsyncBatchUpdateLabelTimestamp(ctx context.Context, ids []uint, ts time.Time) error
 
+	// ApplyLabelMembership replaces the hosts of a manual label by the hosts
+	// that match the identifiers (hostnames, hardware serial numbers or UUIDs),
+	// and returns the difference with the previous membership. The membership
+	// is not changed if dryRun is true.
+	ApplyLabelMembership(ctx context.Context, labelID uint, identifiers []string, dryRun bool) (*LabelMembershipDiff, error)
+	// ListLabelMembershipSyncs returns the URLs that the membership of the
+	// manual labels is synced from.
+	ListLabelMembershipSyncs(ctx context.Context) ([]*LabelMembershipSync, error)
+	// SetLabelMembershipSyncResult records the time and error, if any, of the
+	// last sync of the membership of the label.
+	SetLabelMembershipSyncResult(ctx context.Context, labelID uint, syncedAt time.Time, syncErr string) error
+
+	///////////////////////////////////////////////////////////////////////////////
+	// UpdateRolloutStore
+
+	// NewUpdateRollout creates a new update rollout.
+	NewUpdateRollout(ctx context.Context, rollout *UpdateRollout) (*UpdateRollout, error)
+	// UpdateRollout returns the update rollout with the provided ID.
+	UpdateRollout(ctx context.Context, id uint) (*UpdateRollout, error)
+	// ListUpdateRollouts returns all update rollouts, ordered by ID.
+	ListUpdateRollouts(ctx context.Context) ([]*UpdateRollout, error)
+	// SaveUpdateRollout saves the changes to the update rollout.
+	SaveUpdateRollout(ctx context.Context, rollout *UpdateRollout) error
+	// DeleteUpdateRollout deletes the update rollout, which unpins its hosts.
+	DeleteUpdateRollout(ctx context.Context, id uint) error
+	// ListHostUpdateRolloutIDs returns the IDs of the update rollouts that
+	// pinned the host.
+	ListHostUpdateRolloutIDs(ctx context.Context, hostID uint) ([]uint, error)
+	// AddHostUpdateRollouts records that the update rollouts pinned the host at
+	// the provided time.
+	AddHostUpdateRollouts(ctx context.Context, hostID uint, rolloutIDs []uint, pinnedAt time.Time) error
+	// CountUpdateRolloutHosts returns the number of hosts pinned by the update
+	// rollout, and the number of those that were pinned before offlineSince
+	// and have not checked in since then.
+	CountUpdateRolloutHosts(ctx context.Context, rolloutID uint, offlineSince time.Time) (hosts, offline uint, err error)
+
+	///////////////////////////////////////////////////////////////////////////////
+	// VulnerabilityExceptionStore
+
+	// NewVulnerabilityException creates a new vulnerability exception.
+	NewVulnerabilityException(ctx context.Context, exception *VulnerabilityException) (*VulnerabilityException, error)
+	// VulnerabilityException returns the vulnerability exception with the
+	// provided ID.
+	VulnerabilityException(ctx context.Context, id uint) (*VulnerabilityException, error)
+	// ListVulnerabilityExceptions returns the vulnerability exceptions matching
+	// the options, ordered by ID.
+	ListVulnerabilityExceptions(ctx context.Context, opts VulnerabilityExceptionListOptions) ([]*VulnerabilityException, error)
+	// SaveVulnerabilityException saves the reason, justification, owner and
+	// expiration of the vulnerability exception.
+	SaveVulnerabilityException(ctx context.Context, exception *VulnerabilityException) error
+	// DeleteVulnerabilityException deletes the vulnerability exception.
+	DeleteVulnerabilityException(ctx context.Context, id uint) error
+
+	///////////////////////////////////////////////////////////////////////////////
+	// CPETranslationRuleStore
+
+	// ApplyCPETranslationRuleSpecs creates or updates the custom CPE
+	// translation rules, identified by name.
+	ApplyCPETranslationRuleSpecs(ctx context.Context, specs []*CPETranslationRuleSpec) error
+	// GetCPETranslationRuleSpecs returns all the custom CPE translation rules,
+	// in the order they are evaluated.
+	GetCPETranslationRuleSpecs(ctx context.Context) ([]*CPETranslationRuleSpec, error)
+	// DeleteCPETranslationRule deletes the custom CPE translation rule with the
+	// given name.
+	DeleteCPETranslationRule(ctx context.Context, name string) error
+
+	///////////////////////////////////////////////////////////////////////////////
+	// VulnerabilityRemediationStore
+
+	// SyncHostVulnerabilityRemediations records the CVEs detected on the hosts
+	// that are not tracked yet as first detected at now, and resolves at now
+	// the tracked CVEs that are no longer detected.
+	SyncHostVulnerabilityRemediations(ctx context.Context, now time.Time) error
+	// ListHostVulnerabilityRemediations returns the remediations of the CVEs
+	// detected on hosts matching the options, with their SLA at now.
+	ListHostVulnerabilityRemediations(ctx context.Context, opts VulnerabilityRemediationListOptions, sla VulnerabilityRemediationSLA, now time.Time) ([]*HostVulnerabilityRemediation, *PaginationMetadata, error)
+	// VulnerabilityRemediationStats returns the remediation statistics by
+	// severity of the hosts of the team, or of all the hosts if teamID is nil.
+	VulnerabilityRemediationStats(ctx context.Context, teamID *uint, sla VulnerabilityRemediationSLA, now time.Time) ([]*VulnerabilityRemediationStats, error)
+	// ListUnnotifiedVulnerabilitySLABreaches returns the remediations of the
+	// CVEs still detected on hosts past their SLA at now, for which no SLA
+	// breach notification was sent yet.
+	ListUnnotifiedVulnerabilitySLABreaches(ctx context.Context, sla VulnerabilityRemediationSLA, now time.Time) ([]*HostVulnerabilityRemediation, error)
+	// MarkVulnerabilitySLABreachesNotified records that the SLA breach
+	// notification of the remediations was sent at now.
+	MarkVulnerabilitySLABreachesNotified(ctx context.Context, ids []uint, now time.Time) error
+
 	///////////////////////////////////////////////////////////////////////////////
 	// HostStore
 
@@ -357,6 +463,20 @@ type Datastore interface {
 	DeleteScheduledQuery(ctx context.Context, id uint) error
 	ScheduledQuery(ctx context.Context, id uint) (*ScheduledQuery, error)
 	CleanupExpiredHosts(ctx context.Context) ([]uint, error)
+
+	// ListOfflineHosts returns the hosts of the team that were last seen at or
+	// before seenBefore, with their lifecycle state.
+	ListOfflineHosts(ctx context.Context, teamID uint, seenBefore time.Time) ([]*OfflineHost, error)
+	// ListHostsForTeamAssignment returns the attributes of the hosts that the
+	// team assignment rules match, with their membership of the provided labels
+	// and, if withEmails is true, their emails. If hostIDs is nil, all hosts are
+	// returned.
+	ListHostsForTeamAssignment(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*TeamAssignmentHost, error)
+	// SetHostsLifecycleState sets the lifecycle state of the provided hosts.
+	SetHostsLifecycleState(ctx context.Context, hostIDs []uint, state HostLifecycleState) error
+	// CleanupHostLifecycleStates clears the lifecycle state of the hosts that
+	// checked in after it was set.
+	CleanupHostLifecycleStates(ctx context.Context) error
 	// ScheduledQueryIDsByName loads the IDs associated with the given pack and
 	// query names. It returns a slice of IDs in the same order as
 	// packAndSchedQueryNames, with the ID set to 0 if the corresponding
@@ -380,6 +500,8 @@ type Datastore interface {
 	ListTeams(ctx context.Context, filter TeamFilter, opt ListOptions) ([]*Team, error)
 	// TeamsSummary lists id, name and description for all teams.
 	TeamsSummary(ctx context.Context) ([]*TeamSummary, error)
+	// TeamHierarchy returns the parent team of every team.
+	TeamHierarchy(ctx context.Context) (TeamHierarchy, error)
 	// SearchTeams searches teams using the provided query and ommitting the provided existing selection.
 	SearchTeams(ctx context.Context, filter TeamFilter, matchQuery string, omit ...uint) ([]*Team, error)
 	// TeamEnrollSecrets lists the enroll secrets for the team.
@@ -387,6 +509,13 @@ type Datastore interface {
 	// DeleteIntegrationsFromTeams deletes integrations used by teams, as they
 	// are being deleted from the global configuration.
 	DeleteIntegrationsFromTeams(ctx context.Context, deletedIntgs Integrations) error
+	// ListTeamScheduledQueryOverrides lists the overrides of the global
+	// schedule for the team.
+	ListTeamScheduledQueryOverrides(ctx context.Context, teamID uint) (TeamScheduledQueryOverrideList, error)
+	// ReplaceTeamScheduledQueryOverrides replaces the overrides of the global
+	// schedule for the team with the provided ones, identified by their
+	// ScheduledQueryID.
+	ReplaceTeamScheduledQueryOverrides(ctx context.Context, teamID uint, overrides []*TeamScheduledQueryOverride) error
 
 	///////////////////////////////////////////////////////////////////////////////
 	// SoftwareStore
@@ -395,6 +524,12 @@ type Datastore interface {
 	// used for vulnerability detection populated (id, name, version, cpe_id, cpe)
 	ListSoftwareForVulnDetection(ctx context.Context, hostID uint) ([]Software, error)
 	ListSoftwareVulnerabilitiesByHostIDsSource(ctx context.Context, hostIDs []uint, source VulnerabilitySource) (map[uint][]SoftwareVulnerability, error)
+	// ListSoftwareVulnerabilitiesBySource returns all the software vulnerabilities that were detected using
+	// the given source.
+	ListSoftwareVulnerabilitiesBySource(ctx context.Context, source VulnerabilitySource) ([]SoftwareVulnerability, error)
+	// ListSoftwareVulnerabilitiesBySoftwareID returns all the vulnerabilities detected on the
+	// software, including the ones suppressed by vulnerability exceptions.
+	ListSoftwareVulnerabilitiesBySoftwareID(ctx context.Context, softwareID uint) ([]SoftwareVulnerability, error)
 	LoadHostSoftware(ctx context.Context, host *Host, includeCVEScores bool) error
 
 	// ListSoftwareBySourceIter returns an iterator for consuming all software rows filtered by
@@ -404,9 +539,18 @@ type Datastore interface {
 	AllSoftwareWithoutCPEIterator(ctx context.Context, excludedPlatforms []string) (SoftwareIterator, error)
 	AddCPEForSoftware(ctx context.Context, software Software, cpe string) error
 	ListSoftwareCPEs(ctx context.Context) ([]SoftwareCPE, error)
+	// AllSoftwareWithCPEIterator returns an iterator for the software that has
+	// a CPE, filtering out the software from the excludedSources.
+	AllSoftwareWithCPEIterator(ctx context.Context, excludedSources []string) (SoftwareIterator, error)
+	// DeleteSoftwareCPEs deletes the CPEs of the given software, along with
+	// the vulnerabilities detected from them, so that the software is
+	// translated to a CPE again.
+	DeleteSoftwareCPEs(ctx context.Context, softwareIDs []uint) error
 	// InsertSoftwareVulnerabilities inserts the given vulnerabilities in the datastore, returns the number
 	// of rows inserted. If a vulnerability already exists in the datastore, then it will be ignored.
 	InsertSoftwareVulnerabilities(ctx context.Context, vulns []SoftwareVulnerability, source VulnerabilitySource) (int64, error)
+	// SoftwareByID returns the software with its vulnerabilities, except the ones suppressed for
+	// all hosts by a global vulnerability exception.
 	SoftwareByID(ctx context.Context, id uint, includeCVEScores bool) (*Software, error)
 	// ListSoftwareByHostIDShort lists software by host ID, but does not include CPEs or vulnerabilites.
 	// It is meant to be used when only minimal software fields are required eg when updating host software.
@@ -418,7 +562,12 @@ type Datastore interface {
 	// After aggregation, it cleans up unused software (e.g. software installed
 	// on removed hosts, software uninstalled on hosts, etc.)
 	SyncHostsSoftware(ctx context.Context, updatedAt time.Time) error
-	HostsBySoftwareIDs(ctx context.Context, softwareIDs []uint) ([]*HostShort, error)
+	// VulnerableHostsBySoftwareIDs returns the hosts that have at least one of
+	// the specified software installed with the CVE, unless the CVE is
+	// suppressed on the host by a vulnerability exception.
+	VulnerableHostsBySoftwareIDs(ctx context.Context, softwareIDs []uint, cve string) ([]*HostShort, error)
+	// HostsByCVE returns the hosts that have software installed with the CVE,
+	// unless the CVE is suppressed on the host by a vulnerability exception.
 	HostsByCVE(ctx context.Context, cve string) ([]*HostShort, error)
 	InsertCVEMeta(ctx context.Context, cveMeta []CVEMeta) error
 	ListCVEs(ctx context.Context, maxAge time.Duration) ([]CVEMeta, error)
@@ -451,6 +600,24 @@ type Datastore interface {
 	NewActivity(ctx context.Context, user *User, activity ActivityDetails) error
 	ListActivities(ctx context.Context, opt ListActivitiesOptions) ([]*Activity, *PaginationMetadata, error)
 	MarkActivitiesAsStreamed(ctx context.Context, activityIDs []uint) error
+	// MarkActivitiesAsWebhooksProcessed marks the activities as processed for
+	// the activities webhooks, i.e. their deliveries have been queued (if any).
+	MarkActivitiesAsWebhooksProcessed(ctx context.Context, activityIDs []uint) error
+
+	// NewActivityWebhookDelivery creates the record of the delivery of an
+	// activity to an activities webhook.
+	NewActivityWebhookDelivery(ctx context.Context, delivery *ActivityWebhookDelivery) (*ActivityWebhookDelivery, error)
+	// ActivityWebhookDelivery returns the activity webhook delivery identified by id.
+	ActivityWebhookDelivery(ctx context.Context, id uint) (*ActivityWebhookDelivery, error)
+	// UpdateActivityWebhookDelivery updates the status, attempts and result of
+	// the provided activity webhook delivery.
+	UpdateActivityWebhookDelivery(ctx context.Context, delivery *ActivityWebhookDelivery) error
+	// UpdateActivityWebhookDeliveryAndJob updates the activity webhook delivery
+	// and the job that made the delivery attempt in a single transaction.
+	UpdateActivityWebhookDeliveryAndJob(ctx context.Context, delivery *ActivityWebhookDelivery, job *Job) error
+	// ListActivityWebhookDeliveries returns the activity webhook deliveries
+	// that match the provided options.
+	ListActivityWebhookDeliveries(ctx context.Context, opt ListActivityWebhookDeliveriesOptions) ([]*ActivityWebhookDelivery, *PaginationMetadata, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// StatisticsStore
@@ -475,7 +642,8 @@ type Datastore interface {
 	// It is also used to update team policies.
 	SavePolicy(ctx context.Context, p *Policy) error
 
-	ListGlobalPolicies(ctx context.Context) ([]*Policy, error)
+	// ListGlobalPolicies lists the global policies, filtered by the given options.
+	ListGlobalPolicies(ctx context.Context, opts PolicyListOptions) ([]*Policy, error)
 	PoliciesByID(ctx context.Context, ids []uint) (map[uint]*Policy, error)
 	DeleteGlobalPolicies(ctx context.Context, ids []uint) ([]uint, error)
 
@@ -501,11 +669,25 @@ type Datastore interface {
 	// Team Policies
 
 	NewTeamPolicy(ctx context.Context, teamID uint, authorID *uint, args PolicyPayload) (*Policy, error)
-	ListTeamPolicies(ctx context.Context, teamID uint) (teamPolicies, inheritedPolicies []*Policy, err error)
+	// ListTeamPolicies lists the policies of a team and the global policies inherited by the team, filtered
+	// by the given options.
+	ListTeamPolicies(ctx context.Context, teamID uint, opts PolicyListOptions) (teamPolicies, inheritedPolicies []*Policy, err error)
 	DeleteTeamPolicies(ctx context.Context, teamID uint, ids []uint) ([]uint, error)
 	TeamPolicy(ctx context.Context, teamID uint, policyID uint) (*Policy, error)
 
+	// ListPolicyComplianceSummaries returns, for each team and compliance framework, the number of passing and
+	// failing results of the team's hosts for the policies mapped to the framework. If teamID is not nil, only the
+	// summaries of that team are returned.
+	ListPolicyComplianceSummaries(ctx context.Context, teamID *uint) ([]*PolicyComplianceSummary, error)
+
 	CleanupPolicyMembership(ctx context.Context, now time.Time) error
+	// SnapshotPolicyStats records the number of passing and failing hosts of each policy, per team, for the day of
+	// now. Calling it again on the same day updates the snapshot of that day.
+	SnapshotPolicyStats(ctx context.Context, now time.Time) error
+	// CleanupPolicyStatsHistory deletes the policy stats snapshots taken before the given time.
+	CleanupPolicyStatsHistory(ctx context.Context, before time.Time) error
+	// ListPolicyStatsHistory returns the policy stats snapshots, ordered by date, filtered by the given options.
+	ListPolicyStatsHistory(ctx context.Context, opts PolicyStatsHistoryListOptions) ([]*PolicyStatsSnapshot, error)
 	// IncrementPolicyViolationDays increments the aggregate count of policy violation days. One
 	// policy violation day is added for each policy that a host is failing as of the time the count
 	// is incremented. The count only increments once per 24-hour interval. If the interval has not
@@ -581,13 +763,20 @@ type Datastore interface {
 	// UpdateHostOsqueryIntervals updates the osquery intervals of a host.
 	UpdateHostOsqueryIntervals(ctx context.Context, hostID uint, intervals HostOsqueryIntervals) error
 
-	// TeamAgentOptions loads the agents options of a team.
+	// TeamAgentOptions loads the agents options of a team, inherited from its
+	// ancestors if the team does not set them.
 	TeamAgentOptions(ctx context.Context, teamID uint) (*json.RawMessage, error)
 
+	// TeamUpdateChannels loads the update channels of the Orbit targets of a
+	// team, inherited from its ancestors if the team does not set them. It
+	// returns nil if neither the team nor its ancestors set them.
+	TeamUpdateChannels(ctx context.Context, teamID uint) (*UpdateChannels, error)
+
 	// TeamFeatures loads the features enabled for a team.
 	TeamFeatures(ctx context.Context, teamID uint) (*Features, error)
 
-	// TeamMDMConfig loads the MDM config for a team.
+	// TeamMDMConfig loads the MDM config for a team, with the macOS updates
+	// inherited from its ancestors if the team does not set them.
 	TeamMDMConfig(ctx context.Context, teamID uint) (*TeamMDM, error)
 
 	// SaveHostPackStats stores (and updates) the pack's scheduled queries stats of a host.
@@ -656,6 +845,14 @@ type Datastore interface {
 	// SetOrUpdateHostOrbitInfo inserts of updates the orbit info for a host
 	SetOrUpdateHostOrbitInfo(ctx context.Context, hostID uint, version string) error
 
+	// SetOrUpdateHostOrbitChannels inserts or updates the update channels that
+	// Orbit reports using on a host.
+	SetOrUpdateHostOrbitChannels(ctx context.Context, hostID uint, channels UpdateChannels) error
+
+	// HostOrbitChannels returns the update channels that Orbit reported using
+	// on the host, or nil if it did not report them.
+	HostOrbitChannels(ctx context.Context, hostID uint) (*UpdateChannels, error)
+
 	ReplaceHostDeviceMapping(ctx context.Context, id uint, mappings []*HostDeviceMapping) error
 
 	// ReplaceHostBatteries creates or updates the battery mappings of a host.
@@ -789,6 +986,28 @@ type Datastore interface {
 	// OutdatedAutomationBatch returns a batch of hosts that had a failing policy.
 	OutdatedAutomationBatch(ctx context.Context) ([]PolicyFailure, error)
 
+	// ListPolicyAutomationTickets returns the tickets created by the failing
+	// policy automation of the given integration for the policy.
+	ListPolicyAutomationTickets(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*PolicyAutomationTicket, error)
+
+	// UpsertPolicyAutomationTickets records the tickets created (or updated)
+	// by a failing policy automation. If a host is already tracked for the
+	// same policy and integration, its ticket ID is updated.
+	UpsertPolicyAutomationTickets(ctx context.Context, tickets []*PolicyAutomationTicket) error
+
+	// ListResolvedPolicyAutomationTickets returns the policy automation tickets
+	// of hosts that now pass the policy, and of hosts or policies that have
+	// been deleted.
+	ListResolvedPolicyAutomationTickets(ctx context.Context) ([]*PolicyAutomationTicket, error)
+
+	// DeletePolicyAutomationTickets deletes the policy automation tickets
+	// identified by ids.
+	DeletePolicyAutomationTickets(ctx context.Context, ids []uint) error
+
+	// CountPolicyAutomationTicketHosts returns the number of hosts still
+	// tracked by the given external ticket.
+	CountPolicyAutomationTicketHosts(ctx context.Context, integrationType, integrationKey, ticketID string) (int, error)
+
 	// ListMDMAppleProfilesToInstall returns all the profiles that should
 	// be installed based on diffing the ideal state vs the state we have
 	// registered in `host_mdm_apple_profiles`
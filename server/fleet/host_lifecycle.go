@@ -0,0 +1,150 @@
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HostLifecycleState is the lifecycle state of a host, as set by the host
+// lifecycle rules of its team.
+type HostLifecycleState string
+
+const (
+	// HostLifecycleStateStale is the state of the hosts marked as stale by a
+	// lifecycle rule. The state is cleared when the host checks in again.
+	HostLifecycleStateStale = HostLifecycleState("stale")
+)
+
+// HostLifecycleSettings are the rules applied by the cleanups cron job to the
+// hosts of a team that are offline.
+type HostLifecycleSettings struct {
+	// DryRun only reports the actions that the rules would take, without
+	// applying them.
+	DryRun bool `json:"dry_run"`
+	// Rules are the lifecycle rules of the team. For each host, only the rule
+	// with the largest OfflineDays that the host exceeds applies.
+	Rules []HostLifecycleRule `json:"rules"`
+}
+
+// HostLifecycleRule is the action to take on the hosts that have been offline
+// for at least OfflineDays.
+type HostLifecycleRule struct {
+	// OfflineDays is the minimum number of days since the host was last seen.
+	OfflineDays int `json:"offline_days"`
+	// MarkStale sets the lifecycle state of the host to stale.
+	MarkStale bool `json:"mark_stale"`
+	// TransferToTeam is the name of the team to transfer the host to.
+	TransferToTeam string `json:"transfer_to_team,omitempty"`
+	// Delete deletes the host.
+	Delete bool `json:"delete"`
+}
+
+// Validate checks that the rules are consistent. It does not check that the
+// teams to transfer the hosts to exist.
+func (s HostLifecycleSettings) Validate() error {
+	seen := make(map[int]bool, len(s.Rules))
+	for _, r := range s.Rules {
+		if r.OfflineDays <= 0 {
+			return errors.New("offline_days must be greater than 0")
+		}
+		if seen[r.OfflineDays] {
+			return fmt.Errorf("multiple rules for offline_days %d", r.OfflineDays)
+		}
+		seen[r.OfflineDays] = true
+
+		if !r.MarkStale && r.TransferToTeam == "" && !r.Delete {
+			return fmt.Errorf("rule for offline_days %d has no action", r.OfflineDays)
+		}
+		if r.Delete && (r.MarkStale || r.TransferToTeam != "") {
+			return fmt.Errorf("rule for offline_days %d cannot delete the hosts and apply other actions", r.OfflineDays)
+		}
+	}
+	return nil
+}
+
+// RuleFor returns the rule that applies to a host last seen at seenTime, or
+// nil if none does.
+func (s HostLifecycleSettings) RuleFor(seenTime, now time.Time) *HostLifecycleRule {
+	rules := make([]HostLifecycleRule, len(s.Rules))
+	copy(rules, s.Rules)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].OfflineDays > rules[j].OfflineDays })
+
+	for _, r := range rules {
+		if !seenTime.After(now.Add(-time.Duration(r.OfflineDays) * 24 * time.Hour)) {
+			r := r
+			return &r
+		}
+	}
+	return nil
+}
+
+// MinOfflineDays returns the smallest OfflineDays of the rules, 0 if there
+// are no rules.
+func (s HostLifecycleSettings) MinOfflineDays() int {
+	var min int
+	for _, r := range s.Rules {
+		if min == 0 || r.OfflineDays < min {
+			min = r.OfflineDays
+		}
+	}
+	return min
+}
+
+// OfflineHost is a host that has not been seen for some time, as considered
+// by the host lifecycle rules.
+type OfflineHost struct {
+	ID             uint               `json:"id" db:"id"`
+	DisplayName    string             `json:"display_name" db:"display_name"`
+	SeenTime       time.Time          `json:"seen_time" db:"seen_time"`
+	LifecycleState HostLifecycleState `json:"lifecycle_state" db:"lifecycle_state"`
+}
+
+// HostLifecycleAction is the action taken (or that would be taken in dry-run
+// mode) on a host by a lifecycle rule.
+type HostLifecycleAction struct {
+	HostID          uint      `json:"host_id"`
+	HostDisplayName string    `json:"host_display_name"`
+	SeenTime        time.Time `json:"seen_time"`
+	OfflineDays     int       `json:"offline_days"`
+	MarkStale       bool      `json:"mark_stale"`
+	TransferToTeam  string    `json:"transfer_to_team,omitempty"`
+	Delete          bool      `json:"delete"`
+}
+
+// HostLifecycleReport lists the actions taken by the lifecycle rules of a
+// team.
+type HostLifecycleReport struct {
+	TeamID   uint                  `json:"team_id"`
+	TeamName string                `json:"team_name"`
+	DryRun   bool                  `json:"dry_run"`
+	Actions  []HostLifecycleAction `json:"actions"`
+}
+
+// ActionsFor returns the actions that the rules take on the provided hosts.
+// Hosts already marked as stale are not marked again, so a rule that only
+// marks hosts as stale takes no action on them.
+func (s HostLifecycleSettings) ActionsFor(hosts []*OfflineHost, now time.Time) []HostLifecycleAction {
+	actions := []HostLifecycleAction{}
+	for _, h := range hosts {
+		rule := s.RuleFor(h.SeenTime, now)
+		if rule == nil {
+			continue
+		}
+		markStale := rule.MarkStale && h.LifecycleState != HostLifecycleStateStale
+		if !markStale && rule.TransferToTeam == "" && !rule.Delete {
+			continue
+		}
+		actions = append(actions, HostLifecycleAction{
+			HostID:          h.ID,
+			HostDisplayName: h.DisplayName,
+			SeenTime:        h.SeenTime,
+			OfflineDays:     rule.OfflineDays,
+			MarkStale:       markStale,
+			TransferToTeam:  rule.TransferToTeam,
+			Delete:          rule.Delete,
+		})
+	}
+	return actions
+}
@@ -0,0 +1,65 @@
+package fleet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLifecycleSettingsValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   []HostLifecycleRule
+		wantErr string
+	}{
+		{"no rules", nil, ""},
+		{"valid", []HostLifecycleRule{{OfflineDays: 14, MarkStale: true, TransferToTeam: "quarantine"}, {OfflineDays: 60, Delete: true}}, ""},
+		{"zero days", []HostLifecycleRule{{OfflineDays: 0, MarkStale: true}}, "offline_days must be greater than 0"},
+		{"duplicate days", []HostLifecycleRule{{OfflineDays: 14, MarkStale: true}, {OfflineDays: 14, Delete: true}}, "multiple rules for offline_days 14"},
+		{"no action", []HostLifecycleRule{{OfflineDays: 14}}, "has no action"},
+		{"delete and transfer", []HostLifecycleRule{{OfflineDays: 14, Delete: true, TransferToTeam: "quarantine"}}, "cannot delete the hosts and apply other actions"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := HostLifecycleSettings{Rules: c.rules}.Validate()
+			if c.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostLifecycleSettingsActionsFor(t *testing.T) {
+	now := time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+	s := HostLifecycleSettings{Rules: []HostLifecycleRule{
+		{OfflineDays: 60, Delete: true},
+		{OfflineDays: 14, MarkStale: true, TransferToTeam: "quarantine"},
+		{OfflineDays: 7, MarkStale: true},
+	}}
+	require.Equal(t, 7, s.MinOfflineDays())
+
+	require.Nil(t, s.RuleFor(daysAgo(6), now))
+	require.Equal(t, 7, s.RuleFor(daysAgo(7), now).OfflineDays)
+	require.Equal(t, 14, s.RuleFor(daysAgo(20), now).OfflineDays)
+	require.Equal(t, 60, s.RuleFor(daysAgo(90), now).OfflineDays)
+
+	actions := s.ActionsFor([]*OfflineHost{
+		{ID: 1, SeenTime: daysAgo(1)},
+		{ID: 2, SeenTime: daysAgo(8)},
+		// already stale, the rule only marks hosts as stale
+		{ID: 3, SeenTime: daysAgo(8), LifecycleState: HostLifecycleStateStale},
+		// already stale, the rule still transfers the host
+		{ID: 4, SeenTime: daysAgo(20), LifecycleState: HostLifecycleStateStale},
+		{ID: 5, SeenTime: daysAgo(90)},
+	}, now)
+	require.Equal(t, []HostLifecycleAction{
+		{HostID: 2, SeenTime: daysAgo(8), OfflineDays: 7, MarkStale: true},
+		{HostID: 4, SeenTime: daysAgo(20), OfflineDays: 14, TransferToTeam: "quarantine"},
+		{HostID: 5, SeenTime: daysAgo(90), OfflineDays: 60, Delete: true},
+	}, actions)
+}
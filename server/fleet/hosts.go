@@ -24,6 +24,10 @@ const (
 	// StatusMissing means the host is missing for 30 days. It is identical
 	// with StatusMIA, but StatusMIA is deprecated.
 	StatusMissing = HostStatus("missing")
+	// StatusStale means the host was marked as stale by the lifecycle rules of
+	// its team. It is only used to filter hosts, as it is not derived from the
+	// host's last seen time.
+	StatusStale = HostStatus("stale")
 
 	// NewDuration if a host has been created within this time period it's
 	// considered new.
@@ -201,6 +205,10 @@ type Host struct {
 	PackStats []PackStats `json:"pack_stats" csv:"-"`
 	// TeamName is the name of the team, loaded by JOIN to the teams table.
 	TeamName *string `json:"team_name" db:"team_name" csv:"team_name"`
+	// LifecycleState is the lifecycle state set by the host lifecycle rules of
+	// the team, if any. It is only loaded when listing hosts and getting a
+	// single host.
+	LifecycleState HostLifecycleState `json:"lifecycle_state,omitempty" db:"lifecycle_state" csv:"-"`
 	// Additional is the additional information from the host
 	// additional_queries. This should be stored in a separate DB table.
 	Additional *json.RawMessage `json:"additional,omitempty" db:"additional" csv:"-"`
@@ -445,6 +453,9 @@ type HostDetail struct {
 	// but when unset, it doesn't get marshaled (e.g. we don't return that
 	// information for the List Hosts endpoint).
 	Batteries *[]*HostBattery `json:"batteries,omitempty"`
+	// UpdateChannels are the update channels of the Orbit targets of the host.
+	// It is not set if the host neither reported nor was pinned to a channel.
+	UpdateChannels *HostUpdateChannels `json:"update_channels,omitempty"`
 }
 
 const (
@@ -513,7 +524,7 @@ func (h *Host) FleetPlatform() string {
 
 // HostLinuxOSs are the possible linux values for Host.Platform.
 var HostLinuxOSs = []string{
-	"linux", "ubuntu", "debian", "rhel", "centos", "sles", "kali", "gentoo", "amzn", "pop", "arch", "linuxmint", "void", "nixos",
+	"linux", "ubuntu", "debian", "rhel", "centos", "sles", "kali", "gentoo", "amzn", "pop", "arch", "linuxmint", "void", "nixos", "opensuse-leap", "alpine",
 }
 
 func IsLinux(hostPlatform string) bool {
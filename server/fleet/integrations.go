@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -13,8 +14,11 @@ import (
 // TeamIntegrations contains the configuration for external services'
 // integrations for a specific team.
 type TeamIntegrations struct {
-	Jira    []*TeamJiraIntegration    `json:"jira"`
-	Zendesk []*TeamZendeskIntegration `json:"zendesk"`
+	Jira           []*TeamJiraIntegration           `json:"jira"`
+	Zendesk        []*TeamZendeskIntegration        `json:"zendesk"`
+	Slack          []*TeamSlackIntegration          `json:"slack"`
+	MicrosoftTeams []*TeamMicrosoftTeamsIntegration `json:"microsoft_teams"`
+	CustomHTTP     []*TeamCustomHTTPIntegration     `json:"custom_http"`
 }
 
 // MatchWithIntegrations matches the team integrations to their corresponding
@@ -33,6 +37,18 @@ func (ti TeamIntegrations) MatchWithIntegrations(globalIntgs Integrations) (Inte
 	if err != nil {
 		return result, err
 	}
+	slackIntgs, err := IndexSlackIntegrations(globalIntgs.Slack)
+	if err != nil {
+		return result, err
+	}
+	teamsIntgs, err := IndexMicrosoftTeamsIntegrations(globalIntgs.MicrosoftTeams)
+	if err != nil {
+		return result, err
+	}
+	customHTTPIntgs, err := IndexCustomHTTPIntegrations(globalIntgs.CustomHTTP)
+	if err != nil {
+		return result, err
+	}
 
 	var errs []string
 	for _, tmJira := range ti.Jira {
@@ -55,6 +71,33 @@ func (ti TeamIntegrations) MatchWithIntegrations(globalIntgs Integrations) (Inte
 		intg.EnableFailingPolicies = tmZendesk.EnableFailingPolicies
 		result.Zendesk = append(result.Zendesk, &intg)
 	}
+	for _, tmSlack := range ti.Slack {
+		intg, ok := slackIntgs[tmSlack.UniqueKey()]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown Slack integration for url %s", tmSlack.UniqueKey()))
+			continue
+		}
+		intg.EnableFailingPolicies = tmSlack.EnableFailingPolicies
+		result.Slack = append(result.Slack, &intg)
+	}
+	for _, tmTeams := range ti.MicrosoftTeams {
+		intg, ok := teamsIntgs[tmTeams.UniqueKey()]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown Microsoft Teams integration for url %s", tmTeams.UniqueKey()))
+			continue
+		}
+		intg.EnableFailingPolicies = tmTeams.EnableFailingPolicies
+		result.MicrosoftTeams = append(result.MicrosoftTeams, &intg)
+	}
+	for _, tmCustom := range ti.CustomHTTP {
+		intg, ok := customHTTPIntgs[tmCustom.UniqueKey()]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown custom HTTP integration with name %s", tmCustom.Name))
+			continue
+		}
+		intg.EnableFailingPolicies = tmCustom.EnableFailingPolicies
+		result.CustomHTTP = append(result.CustomHTTP, &intg)
+	}
 
 	if len(errs) > 0 {
 		err = errors.New(strings.Join(errs, "\n"))
@@ -62,6 +105,10 @@ func (ti TeamIntegrations) MatchWithIntegrations(globalIntgs Integrations) (Inte
 	return result, err
 }
 
+func (ti TeamIntegrations) isEmpty() bool {
+	return len(ti.Jira)+len(ti.Zendesk)+len(ti.Slack)+len(ti.MicrosoftTeams)+len(ti.CustomHTTP) == 0
+}
+
 // Validate validates the team integrations for uniqueness.
 func (ti TeamIntegrations) Validate() error {
 	jira := make(map[string]*TeamJiraIntegration, len(ti.Jira))
@@ -81,6 +128,33 @@ func (ti TeamIntegrations) Validate() error {
 		}
 		zendesk[key] = z
 	}
+
+	slack := make(map[string]*TeamSlackIntegration, len(ti.Slack))
+	for _, sl := range ti.Slack {
+		key := sl.UniqueKey()
+		if _, ok := slack[key]; ok {
+			return fmt.Errorf("duplicate Slack integration for url %s", key)
+		}
+		slack[key] = sl
+	}
+
+	teams := make(map[string]*TeamMicrosoftTeamsIntegration, len(ti.MicrosoftTeams))
+	for _, m := range ti.MicrosoftTeams {
+		key := m.UniqueKey()
+		if _, ok := teams[key]; ok {
+			return fmt.Errorf("duplicate Microsoft Teams integration for url %s", key)
+		}
+		teams[key] = m
+	}
+
+	custom := make(map[string]*TeamCustomHTTPIntegration, len(ti.CustomHTTP))
+	for _, c := range ti.CustomHTTP {
+		key := c.UniqueKey()
+		if _, ok := custom[key]; ok {
+			return fmt.Errorf("duplicate custom HTTP integration with name %s", c.Name)
+		}
+		custom[key] = c
+	}
 	return nil
 }
 
@@ -110,6 +184,44 @@ func (z TeamZendeskIntegration) UniqueKey() string {
 	return z.URL + "\n" + strconv.FormatInt(z.GroupID, 10)
 }
 
+// TeamSlackIntegration configures an instance of an integration with a Slack
+// incoming webhook for a team.
+type TeamSlackIntegration struct {
+	URL                   string `json:"url"`
+	EnableFailingPolicies bool   `json:"enable_failing_policies"`
+}
+
+// UniqueKey returns the unique key of this integration, the masked URL of the
+// webhook (see MaskWebhookURL).
+func (s TeamSlackIntegration) UniqueKey() string {
+	return MaskWebhookURL(s.URL)
+}
+
+// TeamMicrosoftTeamsIntegration configures an instance of an integration with
+// a Microsoft Teams incoming webhook for a team.
+type TeamMicrosoftTeamsIntegration struct {
+	URL                   string `json:"url"`
+	EnableFailingPolicies bool   `json:"enable_failing_policies"`
+}
+
+// UniqueKey returns the unique key of this integration, the masked URL of the
+// webhook (see MaskWebhookURL).
+func (m TeamMicrosoftTeamsIntegration) UniqueKey() string {
+	return MaskWebhookURL(m.URL)
+}
+
+// TeamCustomHTTPIntegration configures an instance of a custom HTTP
+// integration for a team.
+type TeamCustomHTTPIntegration struct {
+	Name                  string `json:"name"`
+	EnableFailingPolicies bool   `json:"enable_failing_policies"`
+}
+
+// UniqueKey returns the unique key of this integration.
+func (c TeamCustomHTTPIntegration) UniqueKey() string {
+	return c.Name
+}
+
 // JiraIntegration configures an instance of an integration with the Jira
 // system.
 type JiraIntegration struct {
@@ -335,10 +447,326 @@ func makeTestZendeskRequest(ctx context.Context, intg *ZendeskIntegration) error
 	return nil
 }
 
+// SlackIntegration configures an instance of an integration with a Slack
+// channel via an incoming webhook.
+type SlackIntegration struct {
+	// URL is the URL of the Slack incoming webhook.
+	URL                           string `json:"url"`
+	EnableFailingPolicies         bool   `json:"enable_failing_policies"`
+	EnableSoftwareVulnerabilities bool   `json:"enable_software_vulnerabilities"`
+}
+
+func (s SlackIntegration) uniqueKey() string {
+	return MaskWebhookURL(s.URL)
+}
+
+// MaskWebhookURL returns the URL of a Slack or Microsoft Teams incoming
+// webhook with its secret parts masked: the last segment of the path, which
+// holds the token of the webhook, and the query string, which holds the
+// signature of workflow webhooks. The masked URL still identifies the webhook,
+// it is the URL returned by the API and the unique key of those integrations,
+// so that teams can reference them without knowing the secret URL.
+func MaskWebhookURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return MaskedPassword
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	u.Path = path[:strings.LastIndex(path, "/")+1]
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawPath = ""
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	// append the mask after encoding the URL, it would be escaped otherwise
+	return u.String() + MaskedPassword
+}
+
+// IndexSlackIntegrations indexes the provided Slack integrations in a map
+// keyed by masked URL. It returns an error if a duplicate configuration is
+// found for the same masked URL. This is typically used to index the original integrations
+// before applying the changes requested to modify the AppConfig.
+//
+// As for IndexJiraIntegrations, the returned map uses non-pointer struct
+// values so that changes to the original values do not modify the map.
+func IndexSlackIntegrations(slackIntgs []*SlackIntegration) (map[string]SlackIntegration, error) {
+	indexed := make(map[string]SlackIntegration, len(slackIntgs))
+	for _, intg := range slackIntgs {
+		key := intg.uniqueKey()
+		if _, ok := indexed[key]; ok {
+			return nil, fmt.Errorf("duplicate Slack integration for url %s", key)
+		}
+		indexed[key] = *intg
+	}
+	return indexed, nil
+}
+
+// ValidateSlackIntegrations validates that the merge of the original and new
+// Slack integrations does not result in any duplicate configuration, and that
+// each added integration can successfully post a message to the Slack
+// incoming webhook. It returns the list of integrations that were deleted, if
+// any.
+func ValidateSlackIntegrations(ctx context.Context, oriSlackIntgsIndexed map[string]SlackIntegration, newSlackIntgs []*SlackIntegration) (deleted []*SlackIntegration, err error) {
+	newIndexed := make(map[string]*SlackIntegration, len(newSlackIntgs))
+	for i, new := range newSlackIntgs {
+		// first check for uniqueness
+		key := new.uniqueKey()
+		if _, ok := newIndexed[key]; ok {
+			return nil, fmt.Errorf("duplicate Slack integration for url %s", key)
+		}
+		newIndexed[key] = new
+
+		if ori, ok := oriSlackIntgsIndexed[key]; ok {
+			// the URL is returned masked by the API, keep the stored URL so that
+			// the output of GET config can be applied as-is.
+			if new.URL == key {
+				new.URL = ori.URL
+			}
+			// an existing integration can only have its enabled automations
+			// changed, no need to test it again (which would post a message to
+			// the channel).
+			if new.URL == ori.URL {
+				continue
+			}
+		}
+
+		// new, test it
+		if err := makeTestSlackRequest(ctx, new); err != nil {
+			return nil, fmt.Errorf("Slack integration at index %d: %w", i, err)
+		}
+	}
+
+	// collect any deleted integration
+	for key, intg := range oriSlackIntgsIndexed {
+		intg := intg // do not take address of iteration variable
+		if _, ok := newIndexed[key]; !ok {
+			deleted = append(deleted, &intg)
+		}
+	}
+	return deleted, nil
+}
+
+func makeTestSlackRequest(ctx context.Context, intg *SlackIntegration) error {
+	client, err := externalsvc.NewSlackClient(&externalsvc.SlackOptions{URL: intg.URL})
+	if err != nil {
+		return IntegrationTestError{Err: fmt.Errorf("Slack integration request failed: %w", err)}
+	}
+	if err := client.PostSlackMessage(ctx, &externalsvc.SlackMessage{Text: integrationTestMessage}); err != nil {
+		return IntegrationTestError{Err: fmt.Errorf("Slack integration request failed: %w", err)}
+	}
+	return nil
+}
+
+// MicrosoftTeamsIntegration configures an instance of an integration with a
+// Microsoft Teams channel via an incoming webhook.
+type MicrosoftTeamsIntegration struct {
+	// URL is the URL of the Microsoft Teams incoming webhook.
+	URL                           string `json:"url"`
+	EnableFailingPolicies         bool   `json:"enable_failing_policies"`
+	EnableSoftwareVulnerabilities bool   `json:"enable_software_vulnerabilities"`
+}
+
+func (m MicrosoftTeamsIntegration) uniqueKey() string {
+	return MaskWebhookURL(m.URL)
+}
+
+// IndexMicrosoftTeamsIntegrations indexes the provided Microsoft Teams
+// integrations in a map keyed by masked URL. It returns an error if a
+// duplicate configuration is found for the same masked URL.
+func IndexMicrosoftTeamsIntegrations(teamsIntgs []*MicrosoftTeamsIntegration) (map[string]MicrosoftTeamsIntegration, error) {
+	indexed := make(map[string]MicrosoftTeamsIntegration, len(teamsIntgs))
+	for _, intg := range teamsIntgs {
+		key := intg.uniqueKey()
+		if _, ok := indexed[key]; ok {
+			return nil, fmt.Errorf("duplicate Microsoft Teams integration for url %s", key)
+		}
+		indexed[key] = *intg
+	}
+	return indexed, nil
+}
+
+// ValidateMicrosoftTeamsIntegrations is like ValidateSlackIntegrations, but
+// for Microsoft Teams integrations.
+func ValidateMicrosoftTeamsIntegrations(ctx context.Context, oriTeamsIntgsIndexed map[string]MicrosoftTeamsIntegration, newTeamsIntgs []*MicrosoftTeamsIntegration) (deleted []*MicrosoftTeamsIntegration, err error) {
+	newIndexed := make(map[string]*MicrosoftTeamsIntegration, len(newTeamsIntgs))
+	for i, new := range newTeamsIntgs {
+		// first check for uniqueness
+		key := new.uniqueKey()
+		if _, ok := newIndexed[key]; ok {
+			return nil, fmt.Errorf("duplicate Microsoft Teams integration for url %s", key)
+		}
+		newIndexed[key] = new
+
+		if ori, ok := oriTeamsIntgsIndexed[key]; ok {
+			// restore the stored URL if it was masked by the API
+			if new.URL == key {
+				new.URL = ori.URL
+			}
+			// existing integration, no need to test it again
+			if new.URL == ori.URL {
+				continue
+			}
+		}
+
+		// new, test it
+		if err := makeTestMicrosoftTeamsRequest(ctx, new); err != nil {
+			return nil, fmt.Errorf("Microsoft Teams integration at index %d: %w", i, err)
+		}
+	}
+
+	// collect any deleted integration
+	for key, intg := range oriTeamsIntgsIndexed {
+		intg := intg // do not take address of iteration variable
+		if _, ok := newIndexed[key]; !ok {
+			deleted = append(deleted, &intg)
+		}
+	}
+	return deleted, nil
+}
+
+func makeTestMicrosoftTeamsRequest(ctx context.Context, intg *MicrosoftTeamsIntegration) error {
+	client, err := externalsvc.NewMicrosoftTeamsClient(&externalsvc.MicrosoftTeamsOptions{URL: intg.URL})
+	if err != nil {
+		return IntegrationTestError{Err: fmt.Errorf("Microsoft Teams integration request failed: %w", err)}
+	}
+	if err := client.PostMicrosoftTeamsMessage(ctx, &externalsvc.MicrosoftTeamsMessage{
+		Title: "Fleet integration",
+		Text:  integrationTestMessage,
+	}); err != nil {
+		return IntegrationTestError{Err: fmt.Errorf("Microsoft Teams integration request failed: %w", err)}
+	}
+	return nil
+}
+
+// CustomHTTPIntegration configures an instance of an integration that sends
+// an HTTP request built from a template to an arbitrary endpoint, e.g. to
+// create tickets in a ticketing system that Fleet does not support natively.
+type CustomHTTPIntegration struct {
+	// Name identifies the integration, it is the value used to enable it for a
+	// team.
+	Name string `json:"name"`
+	// URL is the URL of the endpoint that receives the requests.
+	URL string `json:"url"`
+	// Method is the HTTP method of the requests (POST, PUT or PATCH), POST if
+	// empty.
+	Method string `json:"method"`
+	// Headers are additional headers to set on the requests. Their values are
+	// masked when the configuration is returned by the API, as they typically
+	// hold credentials.
+	Headers map[string]string `json:"headers"`
+	// BodyTemplate is the Go text/template used to render the body of the
+	// requests.
+	BodyTemplate          string `json:"body_template"`
+	EnableFailingPolicies bool   `json:"enable_failing_policies"`
+}
+
+func (c CustomHTTPIntegration) uniqueKey() string {
+	return c.Name
+}
+
+// Copy returns a deep copy of the custom HTTP integration.
+func (c *CustomHTTPIntegration) Copy() *CustomHTTPIntegration {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	if c.Headers != nil {
+		clone.Headers = make(map[string]string, len(c.Headers))
+		for k, v := range c.Headers {
+			clone.Headers[k] = v
+		}
+	}
+	return &clone
+}
+
+// IndexCustomHTTPIntegrations indexes the provided custom HTTP integrations in
+// a map keyed by name. It returns an error if a duplicate configuration is
+// found for the same name.
+//
+// As for IndexJiraIntegrations, the returned map uses non-pointer struct
+// values so that changes to the original values do not modify the map (the
+// headers are deep-copied for the same reason).
+func IndexCustomHTTPIntegrations(customIntgs []*CustomHTTPIntegration) (map[string]CustomHTTPIntegration, error) {
+	indexed := make(map[string]CustomHTTPIntegration, len(customIntgs))
+	for _, intg := range customIntgs {
+		key := intg.uniqueKey()
+		if _, ok := indexed[key]; ok {
+			return nil, fmt.Errorf("duplicate custom HTTP integration with name %s", intg.Name)
+		}
+		indexed[key] = *intg.Copy()
+	}
+	return indexed, nil
+}
+
+// ValidateCustomHTTPIntegrations validates that the merge of the original and
+// new custom HTTP integrations does not result in any duplicate
+// configuration, and that each integration is properly configured. Unlike
+// other integrations, no test request is sent as it would typically create a
+// ticket in the target system. Masked header values are replaced by the
+// stored value of the same header. It returns the list of integrations that
+// were deleted, if any.
+func ValidateCustomHTTPIntegrations(ctx context.Context, oriCustomIntgsIndexed map[string]CustomHTTPIntegration, newCustomIntgs []*CustomHTTPIntegration) (deleted []*CustomHTTPIntegration, err error) {
+	newIndexed := make(map[string]*CustomHTTPIntegration, len(newCustomIntgs))
+	for i, new := range newCustomIntgs {
+		if new.Name == "" {
+			return nil, fmt.Errorf("custom HTTP integration at index %d: name is required", i)
+		}
+
+		// first check for uniqueness
+		key := new.uniqueKey()
+		if _, ok := newIndexed[key]; ok {
+			return nil, fmt.Errorf("duplicate custom HTTP integration with name %s", new.Name)
+		}
+		newIndexed[key] = new
+
+		// use stored header values if the request does not contain new values
+		if old, ok := oriCustomIntgsIndexed[key]; ok {
+			for k, v := range new.Headers {
+				if v == MaskedPassword {
+					new.Headers[k] = old.Headers[k]
+				}
+			}
+		}
+		for k, v := range new.Headers {
+			if v == MaskedPassword {
+				return nil, fmt.Errorf("custom HTTP integration %s: missing value for header %s", new.Name, k)
+			}
+		}
+
+		opts := &externalsvc.CustomHTTPOptions{URL: new.URL, Method: new.Method, Headers: new.Headers}
+		if _, err := externalsvc.NewCustomHTTPClient(opts); err != nil {
+			return nil, fmt.Errorf("custom HTTP integration %s: %w", new.Name, err)
+		}
+		new.Method = opts.Method // normalized to the default method if empty
+
+		if _, err := externalsvc.ParseCustomHTTPBodyTemplate(new.BodyTemplate); err != nil {
+			return nil, fmt.Errorf("custom HTTP integration %s: invalid body template: %w", new.Name, err)
+		}
+	}
+
+	// collect any deleted integration
+	for key, intg := range oriCustomIntgsIndexed {
+		intg := intg // do not take address of iteration variable
+		if _, ok := newIndexed[key]; !ok {
+			deleted = append(deleted, &intg)
+		}
+	}
+	return deleted, nil
+}
+
+// integrationTestMessage is the message posted to chat integrations (Slack,
+// Microsoft Teams) when they are added, to validate their configuration.
+const integrationTestMessage = "This channel is now connected to Fleet and will receive the notifications of the enabled automations."
+
 // Integrations configures the integrations with external systems.
 type Integrations struct {
-	Jira    []*JiraIntegration    `json:"jira"`
-	Zendesk []*ZendeskIntegration `json:"zendesk"`
+	Jira           []*JiraIntegration           `json:"jira"`
+	Zendesk        []*ZendeskIntegration        `json:"zendesk"`
+	Slack          []*SlackIntegration          `json:"slack"`
+	MicrosoftTeams []*MicrosoftTeamsIntegration `json:"microsoft_teams"`
+	CustomHTTP     []*CustomHTTPIntegration     `json:"custom_http"`
 }
 
 // ValidateEnabledHostStatusIntegrations checks that the host status integrations
@@ -377,12 +805,27 @@ func ValidateEnabledVulnerabilitiesIntegrations(webhook VulnerabilitiesWebhookSe
 			zendeskEnabledCount++
 		}
 	}
+	var slackEnabledCount int
+	for _, slack := range intgs.Slack {
+		if slack.EnableSoftwareVulnerabilities {
+			slackEnabledCount++
+		}
+	}
+	var teamsEnabledCount int
+	for _, teams := range intgs.MicrosoftTeams {
+		if teams.EnableSoftwareVulnerabilities {
+			teamsEnabledCount++
+		}
+	}
+	enabledTypes := countEnabledIntegrationTypes(jiraEnabledCount, zendeskEnabledCount, slackEnabledCount, teamsEnabledCount)
 
-	if webhookEnabled && (jiraEnabledCount > 0 || zendeskEnabledCount > 0) {
+	if webhookEnabled && enabledTypes > 0 {
 		invalid.Append("vulnerabilities", "cannot enable both webhook vulnerabilities and integration automations")
 	}
 	if jiraEnabledCount > 0 && zendeskEnabledCount > 0 {
 		invalid.Append("vulnerabilities", "cannot enable both jira integration and zendesk automations")
+	} else if enabledTypes > 1 {
+		invalid.Append("vulnerabilities", "cannot enable more than one type of integration automations")
 	}
 	if jiraEnabledCount > 1 {
 		invalid.Append("vulnerabilities", "cannot enable more than one jira integration")
@@ -390,11 +833,30 @@ func ValidateEnabledVulnerabilitiesIntegrations(webhook VulnerabilitiesWebhookSe
 	if zendeskEnabledCount > 1 {
 		invalid.Append("vulnerabilities", "cannot enable more than one zendesk integration")
 	}
+	if slackEnabledCount > 1 {
+		invalid.Append("vulnerabilities", "cannot enable more than one slack integration")
+	}
+	if teamsEnabledCount > 1 {
+		invalid.Append("vulnerabilities", "cannot enable more than one microsoft teams integration")
+	}
 	if webhookEnabled && webhook.DestinationURL == "" {
 		invalid.Append("destination_url", "destination_url is required to enable the vulnerabilities webhook")
 	}
 }
 
+// countEnabledIntegrationTypes returns the number of integration types that
+// have at least one integration enabled, given the count of enabled
+// integrations of each type.
+func countEnabledIntegrationTypes(enabledCounts ...int) int {
+	var n int
+	for _, c := range enabledCounts {
+		if c > 0 {
+			n++
+		}
+	}
+	return n
+}
+
 // ValidateEnabledFailingPoliciesIntegrations checks that a single integration
 // is enabled for failing policies. It adds any error it finds to the invalid
 // argument error, that can then be checked after the call for errors using
@@ -413,12 +875,33 @@ func ValidateEnabledFailingPoliciesIntegrations(webhook FailingPoliciesWebhookSe
 			zendeskEnabledCount++
 		}
 	}
+	var slackEnabledCount int
+	for _, slack := range intgs.Slack {
+		if slack.EnableFailingPolicies {
+			slackEnabledCount++
+		}
+	}
+	var teamsEnabledCount int
+	for _, teams := range intgs.MicrosoftTeams {
+		if teams.EnableFailingPolicies {
+			teamsEnabledCount++
+		}
+	}
+	var customHTTPEnabledCount int
+	for _, custom := range intgs.CustomHTTP {
+		if custom.EnableFailingPolicies {
+			customHTTPEnabledCount++
+		}
+	}
+	enabledTypes := countEnabledIntegrationTypes(jiraEnabledCount, zendeskEnabledCount, slackEnabledCount, teamsEnabledCount, customHTTPEnabledCount)
 
-	if webhookEnabled && (jiraEnabledCount > 0 || zendeskEnabledCount > 0) {
+	if webhookEnabled && enabledTypes > 0 {
 		invalid.Append("failing policies", "cannot enable both webhook failing policies and integration automations")
 	}
 	if jiraEnabledCount > 0 && zendeskEnabledCount > 0 {
 		invalid.Append("failing policies", "cannot enable both jira and zendesk automations")
+	} else if enabledTypes > 1 {
+		invalid.Append("failing policies", "cannot enable more than one type of integration automations")
 	}
 	if jiraEnabledCount > 1 {
 		invalid.Append("failing policies", "cannot enable more than one jira integration")
@@ -426,6 +909,15 @@ func ValidateEnabledFailingPoliciesIntegrations(webhook FailingPoliciesWebhookSe
 	if zendeskEnabledCount > 1 {
 		invalid.Append("failing policies", "cannot enable more than one zendesk integration")
 	}
+	if slackEnabledCount > 1 {
+		invalid.Append("failing policies", "cannot enable more than one slack integration")
+	}
+	if teamsEnabledCount > 1 {
+		invalid.Append("failing policies", "cannot enable more than one microsoft teams integration")
+	}
+	if customHTTPEnabledCount > 1 {
+		invalid.Append("failing policies", "cannot enable more than one custom http integration")
+	}
 	if webhookEnabled && webhook.DestinationURL == "" {
 		invalid.Append("destination_url", "destination_url is required to enable the failing policies webhook")
 	}
@@ -436,8 +928,11 @@ func ValidateEnabledFailingPoliciesIntegrations(webhook FailingPoliciesWebhookSe
 // integration structs.
 func ValidateEnabledFailingPoliciesTeamIntegrations(webhook FailingPoliciesWebhookSettings, teamIntgs TeamIntegrations, invalid *InvalidArgumentError) {
 	intgs := Integrations{
-		Jira:    make([]*JiraIntegration, len(teamIntgs.Jira)),
-		Zendesk: make([]*ZendeskIntegration, len(teamIntgs.Zendesk)),
+		Jira:           make([]*JiraIntegration, len(teamIntgs.Jira)),
+		Zendesk:        make([]*ZendeskIntegration, len(teamIntgs.Zendesk)),
+		Slack:          make([]*SlackIntegration, len(teamIntgs.Slack)),
+		MicrosoftTeams: make([]*MicrosoftTeamsIntegration, len(teamIntgs.MicrosoftTeams)),
+		CustomHTTP:     make([]*CustomHTTPIntegration, len(teamIntgs.CustomHTTP)),
 	}
 	for i, j := range teamIntgs.Jira {
 		intgs.Jira[i] = &JiraIntegration{
@@ -453,5 +948,23 @@ func ValidateEnabledFailingPoliciesTeamIntegrations(webhook FailingPoliciesWebho
 			EnableFailingPolicies: z.EnableFailingPolicies,
 		}
 	}
+	for i, sl := range teamIntgs.Slack {
+		intgs.Slack[i] = &SlackIntegration{
+			URL:                   sl.URL,
+			EnableFailingPolicies: sl.EnableFailingPolicies,
+		}
+	}
+	for i, m := range teamIntgs.MicrosoftTeams {
+		intgs.MicrosoftTeams[i] = &MicrosoftTeamsIntegration{
+			URL:                   m.URL,
+			EnableFailingPolicies: m.EnableFailingPolicies,
+		}
+	}
+	for i, c := range teamIntgs.CustomHTTP {
+		intgs.CustomHTTP[i] = &CustomHTTPIntegration{
+			Name:                  c.Name,
+			EnableFailingPolicies: c.EnableFailingPolicies,
+		}
+	}
 	ValidateEnabledFailingPoliciesIntegrations(webhook, intgs, invalid)
 }
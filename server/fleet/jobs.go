@@ -29,4 +29,11 @@ type Job struct {
 	State     JobState         `json:"state" db:"state"`
 	Retries   int              `json:"retries" db:"retries"`
 	Error     string           `json:"error" db:"error"`
+	// NotBefore is the time before which the job must not be processed. The
+	// zero value means that the job can be processed immediately.
+	NotBefore time.Time `json:"not_before" db:"not_before"`
+	// RetryDelay is the delay to wait before the next attempt of a failed job,
+	// set by the worker. It is added to the database's current time when the
+	// job is updated, the zero value leaves the job's NotBefore unchanged.
+	RetryDelay time.Duration `json:"-" db:"-"`
 }
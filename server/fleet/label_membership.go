@@ -0,0 +1,140 @@
+package fleet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+// LabelMembershipSource is the source of a change of the membership of a
+// manual label.
+type LabelMembershipSource string
+
+// List of the sources of label membership changes.
+const (
+	LabelMembershipSourceCSV  LabelMembershipSource = "csv"
+	LabelMembershipSourceURL  LabelMembershipSource = "url"
+	LabelMembershipSourceSpec LabelMembershipSource = "spec"
+)
+
+// LabelMembershipHost is a host added to or removed from a manual label.
+type LabelMembershipHost struct {
+	ID          uint   `json:"id" db:"id"`
+	DisplayName string `json:"display_name" db:"display_name"`
+}
+
+// LabelMembershipDiff is the change of the membership of a manual label
+// when its hosts are replaced by the hosts matching a list of identifiers.
+type LabelMembershipDiff struct {
+	LabelID   uint                  `json:"label_id"`
+	LabelName string                `json:"label_name"`
+	DryRun    bool                  `json:"dry_run"`
+	Added     []LabelMembershipHost `json:"added"`
+	Removed   []LabelMembershipHost `json:"removed"`
+	// Unmatched are the identifiers that did not match any host.
+	Unmatched []string `json:"unmatched"`
+}
+
+// IsEmpty returns true if the membership of the label did not change.
+func (d *LabelMembershipDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// LabelMembershipSync is the URL that the membership of a manual label is
+// periodically synced from.
+type LabelMembershipSync struct {
+	LabelID   uint       `db:"label_id"`
+	LabelName string     `db:"label_name"`
+	URL       string     `db:"url"`
+	SyncedAt  *time.Time `db:"synced_at"`
+	SyncError *string    `db:"sync_error"`
+}
+
+// maxHostIdentifierLength is the maximum length of a host identifier, the
+// size of the longest column it can match (hosts.hostname).
+const maxHostIdentifierLength = 255
+
+// ParseHostIdentifiersCSV parses the host identifiers (hostnames, hardware
+// serial numbers or UUIDs) from the first column of a CSV document. A header
+// row naming the column is ignored.
+func ParseHostIdentifiersCSV(r io.Reader) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var values []string
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse CSV: %w", err)
+		}
+		if len(values) == 0 && len(record) > 0 && isHostIdentifierHeader(record[0]) {
+			continue
+		}
+		if len(record) > 0 {
+			values = append(values, record[0])
+		}
+	}
+	return normalizeHostIdentifiers(values)
+}
+
+func isHostIdentifierHeader(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "host", "hostname", "serial", "hardware_serial", "uuid", "identifier":
+		return true
+	}
+	return false
+}
+
+// normalizeHostIdentifiers trims the identifiers and removes the empty and
+// duplicate ones.
+func normalizeHostIdentifiers(values []string) ([]string, error) {
+	identifiers := []string{}
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		id := strings.TrimSpace(v)
+		if id == "" || seen[id] {
+			continue
+		}
+		if len(id) > maxHostIdentifierLength {
+			return nil, fmt.Errorf("a host identifier is longer than %d characters", maxHostIdentifierLength)
+		}
+		seen[id] = true
+		identifiers = append(identifiers, id)
+	}
+	return identifiers, nil
+}
+
+// ParseHostIdentifiersResponse parses the host identifiers returned by a
+// label membership sync URL. A JSON response is either an array of
+// identifiers or an object with a "hosts" array, any other response is parsed
+// as CSV.
+func ParseHostIdentifiersResponse(contentType string, r io.Reader) ([]string, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+		return ParseHostIdentifiersCSV(r)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		var obj struct {
+			Hosts []string `json:"hosts"`
+		}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return nil, errors.New("parse JSON: expected an array of host identifiers or an object with a hosts array")
+		}
+		list = obj.Hosts
+	}
+	return normalizeHostIdentifiers(list)
+}
@@ -0,0 +1,64 @@
+package fleet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostIdentifiersCSV(t *testing.T) {
+	cases := []struct {
+		name    string
+		csv     string
+		want    []string
+		wantErr string
+	}{
+		{"empty", "", []string{}, ""},
+		{"header only", "hostname\n", []string{}, ""},
+		{"no header", "host1\nC02ABC\n", []string{"host1", "C02ABC"}, ""},
+		{"header and extra columns", "Serial,owner\nC02ABC,alice\n C02DEF ,bob\n", []string{"C02ABC", "C02DEF"}, ""},
+		{"duplicates and blanks", "host1\n\n\"\"\nhost1\nhost2", []string{"host1", "host2"}, ""},
+		{"header not first", "host1\nhostname\n", []string{"host1", "hostname"}, ""},
+		{"too long", strings.Repeat("a", 256), nil, "longer than 255"},
+		{"invalid", "\"host1\n", nil, "parse CSV"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseHostIdentifiersCSV(strings.NewReader(c.csv))
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestParseHostIdentifiersResponse(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []string
+		wantErr     string
+	}{
+		{"json array", "application/json", `["host1", "host2", "host1"]`, []string{"host1", "host2"}, ""},
+		{"json object", "application/json; charset=utf-8", `{"hosts": ["host1"]}`, []string{"host1"}, ""},
+		{"json invalid", "application/json", `{"hosts": "host1"}`, nil, "parse JSON"},
+		{"csv", "text/csv", "uuid\nabc-123\n", []string{"abc-123"}, ""},
+		{"plain text", "", "host1\nhost2\n", []string{"host1", "host2"}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseHostIdentifiersResponse(c.contentType, strings.NewReader(c.body))
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.want, got)
+		})
+	}
+}
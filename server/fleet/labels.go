@@ -129,5 +129,10 @@ type LabelSpec struct {
 	Platform            string              `json:"platform,omitempty"`
 	LabelType           LabelType           `json:"label_type,omitempty" db:"label_type"`
 	LabelMembershipType LabelMembershipType `json:"label_membership_type" db:"label_membership_type"`
-	Hosts               []string            `json:"hosts,omitempty"`
+	// Hosts are the identifiers (hostnames, hardware serial numbers or UUIDs)
+	// of the hosts of a manual label.
+	Hosts []string `json:"hosts,omitempty"`
+	// HostsSyncURL is the URL that the hosts of a manual label are
+	// periodically synced from.
+	HostsSyncURL string `json:"hosts_sync_url,omitempty" db:"hosts_sync_url"`
 }
@@ -14,4 +14,8 @@ type OrbitConfig struct {
 	Extensions    json.RawMessage          `json:"extensions,omitempty"`
 	NudgeConfig   *NudgeConfig             `json:"nudge_config,omitempty"`
 	Notifications OrbitConfigNotifications `json:"notifications,omitempty"`
+	// UpdateChannels are the update channels that Fleet pins the targets of
+	// Orbit (orbit, osqueryd and desktop) to, by target name. The targets that
+	// are not pinned use the channels Orbit was packaged with.
+	UpdateChannels map[string]string `json:"update_channels,omitempty"`
 }
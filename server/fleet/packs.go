@@ -11,6 +11,35 @@ import (
 // MaxScheduledQueryInterval is the maximum interval value (in seconds) allowed by osquery
 const MaxScheduledQueryInterval = 604800
 
+// TeamSchedulePackName returns the name of the pack holding the schedule of
+// the team.
+func TeamSchedulePackName(teamName string) string {
+	return "Team: " + teamName
+}
+
+// SplitScheduledQueryLogName splits the name of a scheduled query as reported
+// by osquery in its logs, in the "pack/<pack name>/<query name>" format, into
+// its pack and query names. Both names may contain the "/" delimiter, so the
+// schedule pack of the team of the host, if any, is matched by name first,
+// otherwise the pack name ends at the first delimiter.
+func SplitScheduledQueryLogName(name string, host *Host) (packName, queryName string, ok bool) {
+	trimmed := strings.TrimPrefix(name, "pack/")
+	if trimmed == name {
+		return "", "", false
+	}
+	if host != nil && host.TeamName != nil {
+		teamPack := TeamSchedulePackName(*host.TeamName)
+		if queryName := strings.TrimPrefix(trimmed, teamPack+"/"); queryName != trimmed && queryName != "" {
+			return teamPack, queryName, true
+		}
+	}
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
 type PackListOptions struct {
 	ListOptions
 
@@ -133,3 +133,31 @@ func TestPack_TeamPack(t *testing.T) {
 	_, err = p.teamPack()
 	assert.Error(t, err)
 }
+
+func TestSplitScheduledQueryLogName(t *testing.T) {
+	teamHost := &Host{TeamID: ptr.Uint(1), TeamName: ptr.String("Servers/EU")}
+	cases := []struct {
+		name        string
+		host        *Host
+		pack, sched string
+		ok          bool
+	}{
+		{"pack/Global/q1", nil, "Global", "q1", true},
+		{"pack/Team: foo/q/with/slashes", nil, "Team: foo", "q/with/slashes", true},
+		{"pack/Team: Servers/EU/q/with/slashes", teamHost, "Team: Servers/EU", "q/with/slashes", true},
+		{"pack/Global/q1", teamHost, "Global", "q1", true},
+		{"pack/Team: Servers/EU", teamHost, "Team: Servers", "EU", true},
+		{"pack/Team: Servers/EU/", teamHost, "Team: Servers", "EU/", true},
+		{"pack/Global", nil, "", "", false},
+		{"q1", teamHost, "", "", false},
+		{"", nil, "", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pack, sched, ok := SplitScheduledQueryLogName(c.name, c.host)
+			require.Equal(t, c.ok, ok)
+			require.Equal(t, c.pack, pack)
+			require.Equal(t, c.sched, sched)
+		})
+	}
+}
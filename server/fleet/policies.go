@@ -1,10 +1,31 @@
 package fleet
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 )
 
+// Policy severity levels.
+const (
+	PolicySeverityLow      = "low"
+	PolicySeverityMedium   = "medium"
+	PolicySeverityHigh     = "high"
+	PolicySeverityCritical = "critical"
+)
+
+// PolicyComplianceControl maps a policy to a control of a compliance
+// framework, e.g. framework "CIS" and control "1.2.3", or framework
+// "NIST 800-53" and control "AC-2".
+type PolicyComplianceControl struct {
+	// Framework is the name of the compliance framework.
+	Framework string `json:"framework" db:"framework"`
+	// Control is the identifier of the control in the framework.
+	Control string `json:"control" db:"control"`
+}
+
 // PolicyPayload holds data for policy creation.
 //
 // If QueryID is not nil, then Name, Query and Description are ignored
@@ -29,6 +50,13 @@ type PolicyPayload struct {
 	//
 	// Empty string targets all platforms.
 	Platform string
+	// Severity is the severity level of the policy, one of "low", "medium",
+	// "high" or "critical". Empty string means no severity.
+	Severity string
+	// Tags are free-form categories for the policy.
+	Tags []string
+	// Compliance are the compliance framework controls the policy maps to.
+	Compliance []PolicyComplianceControl
 }
 
 var (
@@ -37,6 +65,8 @@ var (
 	errPolicyIDAndQuerySet   = errors.New("both fields \"queryID\" and \"query\" cannot be set")
 	errPolicyInvalidQuery    = errors.New("invalid policy query")
 	errPolicyInvalidPlatform = errors.New("invalid policy platform")
+	errPolicyInvalidSeverity = errors.New("invalid policy severity, must be one of \"low\", \"medium\", \"high\" or \"critical\"")
+	errPolicyEmptyTag        = errors.New("policy tags cannot be empty")
 )
 
 // Verify verifies the policy payload is valid.
@@ -56,7 +86,7 @@ func (p PolicyPayload) Verify() error {
 	if err := verifyPolicyPlatforms(p.Platform); err != nil {
 		return err
 	}
-	return nil
+	return verifyPolicyMetadata(p.Severity, p.Tags, p.Compliance)
 }
 
 func verifyPolicyName(name string) error {
@@ -95,6 +125,49 @@ func verifyPolicyPlatforms(platforms string) error {
 	return nil
 }
 
+func verifyPolicySeverity(severity string) error {
+	switch severity {
+	case "", PolicySeverityLow, PolicySeverityMedium, PolicySeverityHigh, PolicySeverityCritical:
+		return nil
+	default:
+		return errPolicyInvalidSeverity
+	}
+}
+
+func verifyPolicyTags(tags []string) error {
+	for _, tag := range tags {
+		if emptyString(tag) {
+			return errPolicyEmptyTag
+		}
+		if len(tag) > 255 {
+			return fmt.Errorf("policy tag %q is too long", tag)
+		}
+	}
+	return nil
+}
+
+func verifyPolicyCompliance(controls []PolicyComplianceControl) error {
+	for _, c := range controls {
+		if emptyString(c.Framework) || emptyString(c.Control) {
+			return errors.New("policy compliance controls must have a framework and a control")
+		}
+		if len(c.Framework) > 100 || len(c.Control) > 100 {
+			return fmt.Errorf("policy compliance control %s %s is too long", c.Framework, c.Control)
+		}
+	}
+	return nil
+}
+
+func verifyPolicyMetadata(severity string, tags []string, controls []PolicyComplianceControl) error {
+	if err := verifyPolicySeverity(severity); err != nil {
+		return err
+	}
+	if err := verifyPolicyTags(tags); err != nil {
+		return err
+	}
+	return verifyPolicyCompliance(controls)
+}
+
 // ModifyPolicyPayload holds data for policy modification.
 type ModifyPolicyPayload struct {
 	// Name is the name of the policy.
@@ -110,6 +183,15 @@ type ModifyPolicyPayload struct {
 	Platform *string `json:"platform"`
 	// Critical marks the policy as high impact.
 	Critical *bool `json:"critical" premium:"true"`
+	// Severity is the severity level of the policy.
+	// If non-nil, empty string clears the severity.
+	Severity *string `json:"severity"`
+	// Tags are free-form categories for the policy.
+	// If non-nil, they replace the existing tags.
+	Tags *[]string `json:"tags"`
+	// Compliance are the compliance framework controls the policy maps to.
+	// If non-nil, they replace the existing controls.
+	Compliance *[]PolicyComplianceControl `json:"compliance"`
 }
 
 // Verify verifies the policy payload is valid.
@@ -129,6 +211,21 @@ func (p ModifyPolicyPayload) Verify() error {
 			return err
 		}
 	}
+	if p.Severity != nil {
+		if err := verifyPolicySeverity(*p.Severity); err != nil {
+			return err
+		}
+	}
+	if p.Tags != nil {
+		if err := verifyPolicyTags(*p.Tags); err != nil {
+			return err
+		}
+	}
+	if p.Compliance != nil {
+		if err := verifyPolicyCompliance(*p.Compliance); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -161,6 +258,18 @@ type PolicyData struct {
 	//
 	// Empty string targets all platforms.
 	Platform string `json:"platform" db:"platforms"`
+	// Severity is the severity level of the policy ("low", "medium", "high"
+	// or "critical"), empty if not set.
+	Severity string `json:"severity" db:"severity"`
+	// Tags are free-form categories for the policy.
+	//
+	// Tags are loaded from the policy_tags table in the MySQL backend.
+	Tags []string `json:"tags,omitempty" db:"-"`
+	// Compliance are the compliance framework controls the policy maps to.
+	//
+	// Compliance is loaded from the policy_compliance_controls table in the
+	// MySQL backend.
+	Compliance []PolicyComplianceControl `json:"compliance,omitempty" db:"-"`
 
 	UpdateCreateTimestamps
 }
@@ -214,6 +323,42 @@ type PolicySpec struct {
 	//
 	// Empty string targets all platforms.
 	Platform string `json:"platform,omitempty"`
+	// Severity is the severity level of the policy.
+	Severity string `json:"severity,omitempty"`
+	// Tags are free-form categories for the policy.
+	//
+	// In specs they can be set either as a list or as a comma-separated string.
+	Tags []string `json:"tags,omitempty"`
+	// Compliance are the compliance framework controls the policy maps to.
+	Compliance []PolicyComplianceControl `json:"compliance,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler to support the comma-separated
+// tags used by the standard query library and the CIS policies.
+func (p *PolicySpec) UnmarshalJSON(b []byte) error {
+	type policySpecAlias PolicySpec
+	aux := struct {
+		*policySpecAlias
+		Tags json.RawMessage `json:"tags"`
+	}{policySpecAlias: (*policySpecAlias)(p)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	p.Tags = nil
+	if len(aux.Tags) == 0 || string(aux.Tags) == "null" {
+		return nil
+	}
+	var tags string
+	if err := json.Unmarshal(aux.Tags, &tags); err != nil {
+		return json.Unmarshal(aux.Tags, &p.Tags)
+	}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+	return nil
 }
 
 // Verify verifies the policy data is valid.
@@ -227,7 +372,46 @@ func (p PolicySpec) Verify() error {
 	if err := verifyPolicyPlatforms(p.Platform); err != nil {
 		return err
 	}
-	return nil
+	return verifyPolicyMetadata(p.Severity, p.Tags, p.Compliance)
+}
+
+// PolicyListOptions are the options to filter policies when listing them.
+type PolicyListOptions struct {
+	// Severity filters policies by severity level.
+	Severity string `query:"severity,optional"`
+	// Tag filters policies that have the given tag.
+	Tag string `query:"tag,optional"`
+	// ComplianceFramework filters policies mapped to at least one control of
+	// the given compliance framework.
+	ComplianceFramework string `query:"compliance_framework,optional"`
+}
+
+// Verify verifies the policy list options are valid.
+func (o PolicyListOptions) Verify() error {
+	return verifyPolicySeverity(o.Severity)
+}
+
+// PolicyComplianceSummary is the compliance of the hosts of a team with the
+// policies mapped to a compliance framework.
+type PolicyComplianceSummary struct {
+	// TeamID is the ID of the team, nil for hosts without a team.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// TeamName is the name of the team, empty for hosts without a team.
+	TeamName string `json:"team_name" db:"team_name"`
+	// Framework is the name of the compliance framework.
+	Framework string `json:"framework" db:"framework"`
+	// PolicyCount is the number of policies mapped to the framework that
+	// have results for the hosts of the team.
+	PolicyCount uint `json:"policy_count" db:"policy_count"`
+	// PassingCount is the number of passing policy results of the team's
+	// hosts for the framework's policies.
+	PassingCount uint `json:"passing_count" db:"passing_count"`
+	// FailingCount is the number of failing policy results of the team's
+	// hosts for the framework's policies.
+	FailingCount uint `json:"failing_count" db:"failing_count"`
+	// CompliancePercentage is the percentage of passing results over all
+	// results, 0 if there are no results yet.
+	CompliancePercentage float64 `json:"compliance_percentage" db:"-"`
 }
 
 // FailingPolicySet holds sets of hosts that failed policy executions.
@@ -259,3 +443,74 @@ type PolicyMembershipResult struct {
 	PolicyID uint
 	Passes   *bool
 }
+
+// PolicyAutomationTicket is the record of an external ticket (a Jira issue or
+// a Zendesk ticket) created by a failing policy automation, for one of the
+// hosts failing the policy. All hosts reported in the same ticket share the
+// same TicketID.
+type PolicyAutomationTicket struct {
+	ID       uint `db:"id"`
+	PolicyID uint `db:"policy_id"`
+	HostID   uint `db:"host_id"`
+	// IntegrationType is the type of integration that created the ticket,
+	// e.g. "jira" or "zendesk".
+	IntegrationType string `db:"integration_type"`
+	// IntegrationKey identifies the integration in the global configuration,
+	// e.g. the Jira URL and project key.
+	IntegrationKey string `db:"integration_key"`
+	// TicketID is the identifier of the ticket in the external service.
+	TicketID string `db:"ticket_id"`
+
+	UpdateCreateTimestamps
+}
+
+// PolicyStatsSnapshot holds the number of passing and failing hosts of a
+// policy, for the hosts of a team, as recorded on a given day.
+type PolicyStatsSnapshot struct {
+	// Date is the day of the snapshot, in the YYYY-MM-DD format (UTC).
+	Date string `json:"date" db:"snapshot_date"`
+	// PolicyID is the ID of the policy.
+	PolicyID uint `json:"policy_id" db:"policy_id"`
+	// PolicyName is the name of the policy.
+	PolicyName string `json:"policy_name" db:"policy_name"`
+	// TeamID is the ID of the team of the hosts, nil for hosts without a team.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// TeamName is the name of the team of the hosts, empty for hosts without
+	// a team.
+	TeamName string `json:"team_name" db:"team_name"`
+	// PassingHostCount is the number of hosts of the team passing the policy.
+	PassingHostCount uint `json:"passing_host_count" db:"passing_host_count"`
+	// FailingHostCount is the number of hosts of the team failing the policy.
+	FailingHostCount uint `json:"failing_host_count" db:"failing_host_count"`
+}
+
+// PolicyStatsHistoryListOptions are the options to filter the policy stats
+// history.
+type PolicyStatsHistoryListOptions struct {
+	// PolicyID filters the snapshots of the given policy.
+	PolicyID *uint
+	// TeamID filters the snapshots of the hosts of the given team, 0 filters
+	// the snapshots of the hosts without a team.
+	TeamID *uint
+	// From filters the snapshots taken on or after the given day, in the
+	// YYYY-MM-DD format.
+	From string
+	// To filters the snapshots taken on or before the given day, in the
+	// YYYY-MM-DD format.
+	To string
+}
+
+// Verify verifies the options are valid.
+func (o PolicyStatsHistoryListOptions) Verify() error {
+	if o.From != "" {
+		if _, err := time.Parse("2006-01-02", o.From); err != nil {
+			return errors.New("invalid from date, must be in the YYYY-MM-DD format")
+		}
+	}
+	if o.To != "" {
+		if _, err := time.Parse("2006-01-02", o.To); err != nil {
+			return errors.New("invalid to date, must be in the YYYY-MM-DD format")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package fleet
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicySpecUnmarshalTags(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		json string
+		tags []string
+	}{
+		{"list", `{"name": "p", "tags": ["CIS", "compliance"]}`, []string{"CIS", "compliance"}},
+		{"comma-separated", `{"name": "p", "tags": "compliance, CIS,, CIS_Level1 "}`, []string{"compliance", "CIS", "CIS_Level1"}},
+		{"empty string", `{"name": "p", "tags": ""}`, nil},
+		{"null", `{"name": "p", "tags": null}`, nil},
+		{"missing", `{"name": "p"}`, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var spec PolicySpec
+			require.NoError(t, json.Unmarshal([]byte(tc.json), &spec))
+			require.Equal(t, "p", spec.Name)
+			require.Equal(t, tc.tags, spec.Tags)
+		})
+	}
+
+	var spec PolicySpec
+	require.Error(t, json.Unmarshal([]byte(`{"name": "p", "tags": 1}`), &spec))
+}
@@ -1,10 +1,12 @@
 package fleet
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 )
@@ -14,6 +16,7 @@ type QueryPayload struct {
 	Description    *string
 	Query          *string
 	ObserverCanRun *bool `json:"observer_can_run"`
+	StoreResults   *bool `json:"store_results"`
 }
 
 type Query struct {
@@ -25,8 +28,11 @@ type Query struct {
 	Saved       bool   `json:"saved"`
 	// ObserverCanRun indicates whether users with Observer role can run this as
 	// a live query.
-	ObserverCanRun bool  `json:"observer_can_run" db:"observer_can_run"`
-	AuthorID       *uint `json:"author_id" db:"author_id"`
+	ObserverCanRun bool `json:"observer_can_run" db:"observer_can_run"`
+	// StoreResults indicates whether the latest snapshot results reported by
+	// the hosts for this query are stored in Fleet, see QueryReportRow.
+	StoreResults bool  `json:"store_results" db:"store_results"`
+	AuthorID     *uint `json:"author_id" db:"author_id"`
 	// AuthorName is retrieved with a join to the users table in the MySQL
 	// backend (using AuthorID)
 	AuthorName string `json:"author_name" db:"author_name"`
@@ -123,6 +129,9 @@ type QuerySpec struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Query       string `json:"query"`
+	// StoreResults enables the storage of the latest snapshot results of the
+	// query in Fleet.
+	StoreResults bool `json:"store_results,omitempty"`
 }
 
 func LoadQueriesFromYaml(yml string) ([]*Query, error) {
@@ -139,7 +148,7 @@ func LoadQueriesFromYaml(yml string) ([]*Query, error) {
 			return nil, fmt.Errorf("unmarshal yaml: %w", err)
 		}
 		queries = append(queries,
-			&Query{Name: q.Spec.Name, Description: q.Spec.Description, Query: q.Spec.Query},
+			&Query{Name: q.Spec.Name, Description: q.Spec.Description, Query: q.Spec.Query, StoreResults: q.Spec.StoreResults},
 		)
 	}
 
@@ -155,9 +164,10 @@ func WriteQueriesToYaml(queries []*Query) (string, error) {
 				Kind:       QueryKind,
 			},
 			Spec: QuerySpec{
-				Name:        q.Name,
-				Description: q.Description,
-				Query:       q.Query,
+				Name:         q.Name,
+				Description:  q.Description,
+				Query:        q.Query,
+				StoreResults: q.StoreResults,
 			},
 		}
 		yml, err := yaml.Marshal(qYaml)
@@ -169,3 +179,23 @@ func WriteQueriesToYaml(queries []*Query) (string, error) {
 
 	return strings.Join(ymlStrings, "---\n"), nil
 }
+
+// QueryReportRow is a row of the latest snapshot results reported by a host
+// for a query that stores its results.
+type QueryReportRow struct {
+	HostID      uint      `json:"host_id" db:"host_id"`
+	Hostname    string    `json:"host_name" db:"hostname"`
+	LastFetched time.Time `json:"last_fetched" db:"last_fetched"`
+	// Columns maps the names of the columns returned by the query to their
+	// value for this row.
+	Columns map[string]string `json:"columns" db:"-"`
+	// Data is the raw JSON row as stored in the database.
+	Data json.RawMessage `json:"-" db:"data"`
+}
+
+// QueryReportListOptions are the options to filter the rows of a query
+// report.
+type QueryReportListOptions struct {
+	HostID *uint
+	TeamID *uint
+}
@@ -1,6 +1,8 @@
 package fleet
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fleetdm/fleet/v4/server/ptr"
@@ -127,3 +129,69 @@ type ScheduledQueryStats struct {
 	UserTime     int       `json:"user_time" db:"user_time"`
 	WallTime     int       `json:"wall_time" db:"wall_time"`
 }
+
+// TeamScheduledQueryOverride changes how a global scheduled query runs on the
+// hosts of a team. The unset fields keep the value of the global scheduled
+// query.
+type TeamScheduledQueryOverride struct {
+	TeamID           uint `json:"-" db:"team_id"`
+	ScheduledQueryID uint `json:"-" db:"scheduled_query_id"`
+	// Name is the name of the global scheduled query, populated via a join on
+	// scheduled_queries.
+	Name string `json:"name" db:"name"`
+	// Disabled removes the scheduled query from the team's hosts schedule.
+	Disabled bool `json:"disabled,omitempty" db:"disabled"`
+	// Interval specifies the query frequency, in seconds.
+	Interval *uint `json:"interval,omitempty" db:"interval"`
+	// Shard restricts the query to a percentage (1-100) of the team's hosts.
+	Shard *uint `json:"shard,omitempty" db:"shard"`
+	// Platform is a comma-separated list of the target platforms, an empty
+	// string means the query runs on all platforms.
+	Platform *string `json:"platform,omitempty" db:"platform"`
+}
+
+// Validate checks that the override values are valid.
+func (o TeamScheduledQueryOverride) Validate() error {
+	if o.Name == "" {
+		return NewInvalidArgumentError("schedule_overrides.name", "name may not be empty")
+	}
+	if o.Interval != nil && *o.Interval == 0 {
+		return NewInvalidArgumentError("schedule_overrides.interval", fmt.Sprintf("%s: interval must be greater than 0", o.Name))
+	}
+	if o.Shard != nil && (*o.Shard < 1 || *o.Shard > 100) {
+		return NewInvalidArgumentError("schedule_overrides.shard", fmt.Sprintf("%s: shard must be between 1 and 100", o.Name))
+	}
+	if o.Platform != nil && *o.Platform != "" {
+		for _, s := range strings.Split(*o.Platform, ",") {
+			switch strings.TrimSpace(s) {
+			case "windows", "linux", "darwin":
+				// OK
+			default:
+				return NewInvalidArgumentError("schedule_overrides.platform", fmt.Sprintf("%s: invalid platform %q", o.Name, s))
+			}
+		}
+	}
+	return nil
+}
+
+// TeamScheduledQueryOverrideList is a list of team overrides, it implements
+// cloning for the cached datastore.
+type TeamScheduledQueryOverrideList []*TeamScheduledQueryOverride
+
+func (l TeamScheduledQueryOverrideList) Clone() (interface{}, error) {
+	var cloned TeamScheduledQueryOverrideList
+	for _, o := range l {
+		newO := *o
+		if o.Interval != nil {
+			newO.Interval = ptr.Uint(*o.Interval)
+		}
+		if o.Shard != nil {
+			newO.Shard = ptr.Uint(*o.Shard)
+		}
+		if o.Platform != nil {
+			newO.Platform = ptr.String(*o.Platform)
+		}
+		cloned = append(cloned, &newO)
+	}
+	return cloned, nil
+}
@@ -224,6 +224,10 @@ type Service interface {
 
 	// ListHostsInLabel returns a slice of hosts in the label with the given ID.
 	ListHostsInLabel(ctx context.Context, lid uint, opt HostListOptions) ([]*Host, error)
+	// ApplyLabelMembershipCSV replaces the hosts of a manual label by the hosts
+	// that match the identifiers (hostnames, hardware serial numbers or UUIDs)
+	// of a CSV file, and returns the difference with the previous membership.
+	ApplyLabelMembershipCSV(ctx context.Context, labelID uint, r io.Reader, dryRun bool) (*LabelMembershipDiff, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// QueryService
@@ -239,6 +243,8 @@ type Service interface {
 	// for distributed queries but not saved should not be returned).
 	ListQueries(ctx context.Context, opt ListOptions) ([]*Query, error)
 	GetQuery(ctx context.Context, id uint) (*Query, error)
+	// QueryReport returns the latest results stored for the query, for the hosts visible by the user.
+	QueryReport(ctx context.Context, id uint, opts QueryReportListOptions) ([]*QueryReportRow, error)
 	NewQuery(ctx context.Context, p QueryPayload) (*Query, error)
 	ModifyQuery(ctx context.Context, id uint, p QueryPayload) (*Query, error)
 	DeleteQuery(ctx context.Context, name string) error
@@ -272,6 +278,10 @@ type Service interface {
 	CompleteCampaign(ctx context.Context, campaign *DistributedQueryCampaign) error
 	RunLiveQueryDeadline(ctx context.Context, queryIDs []uint, hostIDs []uint, deadline time.Duration) ([]QueryCampaignResult, int)
 
+	// ListCampaignResults returns the campaign and a page of its persisted results, it fails if the persistence of the
+	// live query results is disabled.
+	ListCampaignResults(ctx context.Context, campaignID uint, opt ListOptions) (*DistributedQueryCampaign, []*DistributedQueryResult, *PaginationMetadata, error)
+
 	///////////////////////////////////////////////////////////////////////////////
 	// AgentOptionsService
 
@@ -440,6 +450,9 @@ type Service interface {
 	GetCarve(ctx context.Context, id int64) (*CarveMetadata, error)
 	ListCarves(ctx context.Context, opt CarveListOptions) ([]*CarveMetadata, error)
 	GetBlock(ctx context.Context, carveId, blockId int64) ([]byte, error)
+	// DownloadCarve returns the metadata of a complete carve and a reader of its
+	// contents.
+	DownloadCarve(ctx context.Context, id int64) (*CarveMetadata, io.ReadSeeker, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// TeamService
@@ -470,6 +483,73 @@ type Service interface {
 	ModifyTeamEnrollSecrets(ctx context.Context, teamID uint, secrets []EnrollSecret) ([]*EnrollSecret, error)
 	// ApplyTeamSpecs applies the changes for each team as defined in the specs.
 	ApplyTeamSpecs(ctx context.Context, specs []*TeamSpec, applyOpts ApplySpecOptions) error
+	// PreviewTeamAssignmentRules returns the transfers of hosts that the team
+	// assignment rules would make if they were applied now. The configured rules
+	// are used if rules is nil.
+	PreviewTeamAssignmentRules(ctx context.Context, rules *TeamAssignmentRules) ([]TeamAssignmentMove, error)
+	// PreviewTeamHostLifecycle returns the actions that the host lifecycle rules
+	// of the team would take if they were applied now.
+	PreviewTeamHostLifecycle(ctx context.Context, teamID uint) (*HostLifecycleReport, error)
+
+	///////////////////////////////////////////////////////////////////////////////
+	// UpdateRolloutService
+
+	// ListUpdateRollouts returns all update rollouts with the counts of their
+	// pinned and offline hosts.
+	ListUpdateRollouts(ctx context.Context) ([]*UpdateRollout, error)
+	// GetUpdateRollout returns the update rollout with the counts of its pinned
+	// and offline hosts.
+	GetUpdateRollout(ctx context.Context, id uint) (*UpdateRollout, error)
+	// NewUpdateRollout creates an update rollout.
+	NewUpdateRollout(ctx context.Context, p UpdateRolloutPayload) (*UpdateRollout, error)
+	// ModifyUpdateRollout modifies the percentage, halt settings or status of
+	// an update rollout.
+	ModifyUpdateRollout(ctx context.Context, id uint, p UpdateRolloutPayload) (*UpdateRollout, error)
+	// DeleteUpdateRollout deletes an update rollout, which unpins its hosts.
+	DeleteUpdateRollout(ctx context.Context, id uint) error
+
+	///////////////////////////////////////////////////////////////////////////////
+	// VulnerabilityExceptionService
+
+	// ListVulnerabilityExceptions returns the vulnerability exceptions matching
+	// the options.
+	ListVulnerabilityExceptions(ctx context.Context, opts VulnerabilityExceptionListOptions) ([]*VulnerabilityException, error)
+	// GetVulnerabilityException returns the vulnerability exception with the
+	// provided ID.
+	GetVulnerabilityException(ctx context.Context, id uint) (*VulnerabilityException, error)
+	// NewVulnerabilityException creates a vulnerability exception, which
+	// suppresses a CVE globally, for a team, a software and/or a host.
+	NewVulnerabilityException(ctx context.Context, p VulnerabilityExceptionPayload) (*VulnerabilityException, error)
+	// ModifyVulnerabilityException modifies the reason, justification, owner or
+	// expiration of a vulnerability exception.
+	ModifyVulnerabilityException(ctx context.Context, id uint, p VulnerabilityExceptionPayload) (*VulnerabilityException, error)
+	// DeleteVulnerabilityException deletes a vulnerability exception.
+	DeleteVulnerabilityException(ctx context.Context, id uint) error
+
+	///////////////////////////////////////////////////////////////////////////////
+	// CPETranslationRuleService
+
+	// ApplyCPETranslationRuleSpecs creates or updates the custom CPE
+	// translation rules, identified by name.
+	ApplyCPETranslationRuleSpecs(ctx context.Context, specs []*CPETranslationRuleSpec) error
+	// GetCPETranslationRuleSpecs returns the custom CPE translation rules.
+	GetCPETranslationRuleSpecs(ctx context.Context) ([]*CPETranslationRuleSpec, error)
+	// DeleteCPETranslationRule deletes the custom CPE translation rule with the
+	// given name.
+	DeleteCPETranslationRule(ctx context.Context, name string) error
+	// DryRunCPETranslation returns which CPE the software resolves to and why,
+	// evaluating the specs as if they were applied.
+	DryRunCPETranslation(ctx context.Context, software Software, specs []*CPETranslationRuleSpec) (*CPEResolution, error)
+
+	///////////////////////////////////////////////////////////////////////////////
+	// VulnerabilityRemediationService
+
+	// ListHostVulnerabilityRemediations returns the remediations of the CVEs
+	// detected on hosts matching the options, with their SLA.
+	ListHostVulnerabilityRemediations(ctx context.Context, opts VulnerabilityRemediationListOptions) ([]*HostVulnerabilityRemediation, *PaginationMetadata, error)
+	// VulnerabilityRemediationStats returns the remediation statistics by
+	// severity of the hosts of the team, or of all the hosts if teamID is nil.
+	VulnerabilityRemediationStats(ctx context.Context, teamID *uint) ([]*VulnerabilityRemediationStats, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// ActivitiesService
@@ -484,6 +564,9 @@ type Service interface {
 	// What we call "Activities" are administrative operations,
 	// logins, running a live query, etc.
 	ListActivities(ctx context.Context, opt ListActivitiesOptions) ([]*Activity, *PaginationMetadata, error)
+	// ListActivityWebhookDeliveries lists the deliveries of activities to the
+	// activities webhooks.
+	ListActivityWebhookDeliveries(ctx context.Context, opt ListActivityWebhookDeliveriesOptions) ([]*ActivityWebhookDelivery, *PaginationMetadata, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// UserRolesService
@@ -518,11 +601,16 @@ type Service interface {
 	// GlobalPolicyService
 
 	NewGlobalPolicy(ctx context.Context, p PolicyPayload) (*Policy, error)
-	ListGlobalPolicies(ctx context.Context) ([]*Policy, error)
+	ListGlobalPolicies(ctx context.Context, opts PolicyListOptions) ([]*Policy, error)
 	DeleteGlobalPolicies(ctx context.Context, ids []uint) ([]uint, error)
 	ModifyGlobalPolicy(ctx context.Context, id uint, p ModifyPolicyPayload) (*Policy, error)
 	GetPolicyByIDQueries(ctx context.Context, policyID uint) (*Policy, error)
 	ApplyPolicySpecs(ctx context.Context, policies []*PolicySpec) error
+	// ListPolicyCompliance returns the per-team compliance with the policies mapped to each compliance framework.
+	// If teamID is not nil, only the compliance of that team is returned.
+	ListPolicyCompliance(ctx context.Context, teamID *uint) ([]*PolicyComplianceSummary, error)
+	// ListPolicyHistory returns the daily snapshots of the policies' passing and failing host counts per team.
+	ListPolicyHistory(ctx context.Context, opts PolicyStatsHistoryListOptions) ([]*PolicyStatsSnapshot, error)
 
 	///////////////////////////////////////////////////////////////////////////////
 	// Software
@@ -535,7 +623,7 @@ type Service interface {
 	// Team Policies
 
 	NewTeamPolicy(ctx context.Context, teamID uint, p PolicyPayload) (*Policy, error)
-	ListTeamPolicies(ctx context.Context, teamID uint) (teamPolicies, inheritedPolicies []*Policy, err error)
+	ListTeamPolicies(ctx context.Context, teamID uint, opts PolicyListOptions) (teamPolicies, inheritedPolicies []*Policy, err error)
 	DeleteTeamPolicies(ctx context.Context, teamID uint, ids []uint) ([]uint, error)
 	ModifyTeamPolicy(ctx context.Context, teamID uint, id uint, p ModifyPolicyPayload) (*Policy, error)
 	GetTeamPolicyByIDQueries(ctx context.Context, teamID uint, policyID uint) (*Policy, error)
@@ -0,0 +1,194 @@
+package fleet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TeamAssignmentRule assigns the hosts that match all of its criteria to a
+// team. At least one criterion must be set.
+type TeamAssignmentRule struct {
+	// Team is the name of the team the matching hosts are assigned to.
+	Team string `json:"team"`
+	// Label is the name of a label the host must be a member of.
+	Label string `json:"label,omitempty"`
+	// HostnameRegex is a regular expression the hostname must match.
+	HostnameRegex string `json:"hostname_regex,omitempty"`
+	// Platform is the platform of the host, either a generic platform (e.g.
+	// "linux") or a specific one (e.g. "ubuntu").
+	Platform string `json:"platform,omitempty"`
+	// SerialPrefix is a prefix of the hardware serial number of the host.
+	SerialPrefix string `json:"serial_prefix,omitempty"`
+	// EmailDomain is the domain of an email address mapped to the host (e.g.
+	// by Google Chrome profiles).
+	EmailDomain string `json:"email_domain,omitempty"`
+}
+
+// TeamAssignmentRules is an ordered list of team assignment rules, the first
+// rule that matches a host assigns it.
+type TeamAssignmentRules []TeamAssignmentRule
+
+// Validate checks that the rules are well-formed. It does not check that the
+// teams and labels exist.
+func (rs TeamAssignmentRules) Validate() error {
+	for i, r := range rs {
+		if r.Team == "" {
+			return fmt.Errorf("rule %d: team may not be empty", i)
+		}
+		if r.Label == "" && r.HostnameRegex == "" && r.Platform == "" && r.SerialPrefix == "" && r.EmailDomain == "" {
+			return fmt.Errorf("rule %d: at least one of label, hostname_regex, platform, serial_prefix or email_domain is required", i)
+		}
+		if r.HostnameRegex != "" {
+			if _, err := regexp.Compile(r.HostnameRegex); err != nil {
+				return fmt.Errorf("rule %d: invalid hostname_regex: %w", i, err)
+			}
+		}
+		if strings.Contains(r.EmailDomain, "@") {
+			return fmt.Errorf("rule %d: email_domain must not contain @", i)
+		}
+	}
+	return nil
+}
+
+// LabelNames returns the names of the labels referenced by the rules.
+func (rs TeamAssignmentRules) LabelNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, r := range rs {
+		if r.Label != "" && !seen[r.Label] {
+			seen[r.Label] = true
+			names = append(names, r.Label)
+		}
+	}
+	return names
+}
+
+// UseEmails returns true if a rule matches hosts by the domain of their
+// emails.
+func (rs TeamAssignmentRules) UseEmails() bool {
+	for _, r := range rs {
+		if r.EmailDomain != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// CompiledTeamAssignmentRules are team assignment rules ready to match
+// hosts, with their regular expressions compiled.
+type CompiledTeamAssignmentRules struct {
+	rules           TeamAssignmentRules
+	hostnameRegexps []*regexp.Regexp
+}
+
+// Compile compiles the rules to match hosts.
+func (rs TeamAssignmentRules) Compile() (*CompiledTeamAssignmentRules, error) {
+	c := &CompiledTeamAssignmentRules{
+		rules:           rs,
+		hostnameRegexps: make([]*regexp.Regexp, len(rs)),
+	}
+	for i, r := range rs {
+		if r.HostnameRegex == "" {
+			continue
+		}
+		re, err := regexp.Compile(r.HostnameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: invalid hostname_regex: %w", i, err)
+		}
+		c.hostnameRegexps[i] = re
+	}
+	return c, nil
+}
+
+// Match returns the index of the first rule that matches the host, or -1 if
+// none does.
+func (c *CompiledTeamAssignmentRules) Match(h *TeamAssignmentHost) int {
+	for i, r := range c.rules {
+		if r.matches(h, c.hostnameRegexps[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Team returns the name of the team of the rule at index i.
+func (c *CompiledTeamAssignmentRules) Team(i int) string {
+	return c.rules[i].Team
+}
+
+func (r TeamAssignmentRule) matches(h *TeamAssignmentHost, hostnameRegexp *regexp.Regexp) bool {
+	if r.Label != "" {
+		var found bool
+		for _, name := range h.LabelNames {
+			if name == r.Label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if hostnameRegexp != nil && !hostnameRegexp.MatchString(h.Hostname) {
+		return false
+	}
+	if r.Platform != "" {
+		var found bool
+		for _, p := range ExpandPlatform(r.Platform) {
+			if p == h.Platform {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.SerialPrefix != "" && (h.HardwareSerial == "" || !strings.HasPrefix(h.HardwareSerial, r.SerialPrefix)) {
+		return false
+	}
+	if r.EmailDomain != "" {
+		var found bool
+		for _, email := range h.Emails {
+			if i := strings.LastIndex(email, "@"); i >= 0 && strings.EqualFold(email[i+1:], r.EmailDomain) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// TeamAssignmentHost holds the attributes of a host that the team assignment
+// rules match.
+type TeamAssignmentHost struct {
+	ID             uint    `db:"id"`
+	DisplayName    string  `db:"display_name"`
+	Hostname       string  `db:"hostname"`
+	Platform       string  `db:"platform"`
+	HardwareSerial string  `db:"hardware_serial"`
+	TeamID         *uint   `db:"team_id"`
+	TeamName       *string `db:"team_name"`
+	// LabelNames are the names of the labels the host is a member of, among
+	// the labels referenced by the rules.
+	LabelNames []string `db:"-"`
+	// Emails are the emails mapped to the host.
+	Emails []string `db:"-"`
+}
+
+// TeamAssignmentMove is the transfer of a host to a team by a team assignment
+// rule.
+type TeamAssignmentMove struct {
+	HostID          uint    `json:"host_id"`
+	HostDisplayName string  `json:"host_display_name"`
+	FromTeamID      *uint   `json:"from_team_id"`
+	FromTeamName    *string `json:"from_team_name"`
+	ToTeamID        uint    `json:"to_team_id"`
+	ToTeamName      string  `json:"to_team_name"`
+	// RuleIndex is the index of the rule that matched the host.
+	RuleIndex int `json:"rule_index"`
+}
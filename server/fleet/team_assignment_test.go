@@ -0,0 +1,75 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamAssignmentRulesValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   TeamAssignmentRules
+		wantErr string
+	}{
+		{"no rules", nil, ""},
+		{"valid", TeamAssignmentRules{{Team: "a", Label: "l"}, {Team: "b", HostnameRegex: "^web-", Platform: "linux"}}, ""},
+		{"no team", TeamAssignmentRules{{Label: "l"}}, "rule 0: team may not be empty"},
+		{"no criteria", TeamAssignmentRules{{Team: "a", Label: "l"}, {Team: "b"}}, "rule 1: at least one of"},
+		{"invalid regex", TeamAssignmentRules{{Team: "a", HostnameRegex: "("}}, "rule 0: invalid hostname_regex"},
+		{"email in domain", TeamAssignmentRules{{Team: "a", EmailDomain: "a@example.com"}}, "email_domain must not contain @"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.rules.Validate()
+			if c.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestTeamAssignmentRulesMatch(t *testing.T) {
+	rules := TeamAssignmentRules{
+		{Team: "servers", HostnameRegex: `^web-\d+$`, Platform: "linux"},
+		{Team: "laptops", Label: "macOS laptops", SerialPrefix: "C02"},
+		{Team: "contractors", EmailDomain: "contractor.example.com"},
+		{Team: "windows", Platform: "windows"},
+	}
+	require.Equal(t, []string{"macOS laptops"}, rules.LabelNames())
+	require.True(t, rules.UseEmails())
+	compiled, err := rules.Compile()
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		host TeamAssignmentHost
+		want int
+	}{
+		{"hostname and platform", TeamAssignmentHost{Hostname: "web-12", Platform: "ubuntu"}, 0},
+		{"hostname but not platform", TeamAssignmentHost{Hostname: "web-12", Platform: "darwin"}, -1},
+		{"label and serial", TeamAssignmentHost{Platform: "darwin", HardwareSerial: "C02XYZ", LabelNames: []string{"macOS laptops"}}, 1},
+		{"label but not serial", TeamAssignmentHost{Platform: "darwin", HardwareSerial: "D02XYZ", LabelNames: []string{"macOS laptops"}}, -1},
+		{"email domain", TeamAssignmentHost{Platform: "darwin", Emails: []string{"a@example.com", "b@Contractor.Example.com"}}, 2},
+		{"first rule wins", TeamAssignmentHost{Platform: "windows", Emails: []string{"b@contractor.example.com"}}, 2},
+		{"platform", TeamAssignmentHost{Platform: "windows"}, 3},
+		{"no match", TeamAssignmentHost{Platform: "chrome"}, -1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, compiled.Match(&c.host))
+		})
+	}
+}
+
+func TestTeamAssignmentRulesCompile(t *testing.T) {
+	_, err := TeamAssignmentRules{{Team: "a", Label: "l"}, {Team: "b", HostnameRegex: "("}}.Compile()
+	require.ErrorContains(t, err, "rule 1: invalid hostname_regex")
+
+	compiled, err := TeamAssignmentRules{{Team: "a", Label: "l"}, {Team: "b", HostnameRegex: "^db-"}}.Compile()
+	require.NoError(t, err)
+	require.Equal(t, 1, compiled.Match(&TeamAssignmentHost{Hostname: "db-1"}))
+	require.Equal(t, "b", compiled.Team(1))
+}
@@ -1,9 +1,11 @@
 package fleet
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -20,6 +22,12 @@ type TeamPayload struct {
 	WebhookSettings *TeamWebhookSettings `json:"webhook_settings"`
 	Integrations    *TeamIntegrations    `json:"integrations"`
 	MDM             *TeamPayloadMDM      `json:"mdm"`
+	// ParentTeamID sets the parent of the team, 0 makes it a top-level team.
+	ParentTeamID  *uint                  `json:"parent_team_id"`
+	HostLifecycle *HostLifecycleSettings `json:"host_lifecycle"`
+	// UpdateChannels pins the Orbit targets of the team's hosts to update
+	// channels, empty channels leave the targets on their packaged channels.
+	UpdateChannels *UpdateChannels `json:"update_channels"`
 	// Note AgentOptions must be set by a separate endpoint.
 }
 
@@ -45,9 +53,15 @@ type Team struct {
 	// Description is an optional description for the team.
 	Description string     `json:"description" db:"description"`
 	Config      TeamConfig `json:"-" db:"config"` // see json.MarshalJSON/UnmarshalJSON implementations
+	// ParentTeamID is the ID of the parent team, if any. A team inherits the
+	// configuration it does not set from its ancestors.
+	ParentTeamID *uint `json:"parent_team_id,omitempty" db:"parent_team_id"`
 
 	// Derived from JOINs
 
+	// ParentTeamName is the name of the parent team, if any.
+	ParentTeamName *string `json:"parent_team_name,omitempty" db:"parent_team_name"`
+
 	// UserCount is the count of users with explicit roles on this team.
 	UserCount int `json:"user_count" db:"user_count"`
 	// Users is the users that have a role on this team.
@@ -58,6 +72,11 @@ type Team struct {
 	Hosts []Host `json:"hosts,omitempty"`
 	// Secrets is the enroll secrets valid for this team.
 	Secrets []*EnrollSecret `json:"secrets,omitempty"`
+	// ScheduleOverrides are the team's overrides of the global schedule.
+	ScheduleOverrides []*TeamScheduledQueryOverride `json:"schedule_overrides,omitempty"`
+	// Children are the child teams, only set when the teams are listed as a
+	// tree.
+	Children []*Team `json:"children,omitempty"`
 }
 
 func (t Team) MarshalJSON() ([]byte, error) {
@@ -76,6 +95,11 @@ func (t Team) MarshalJSON() ([]byte, error) {
 		HostCount   int             `json:"host_count"`
 		Hosts       []HostResponse  `json:"hosts,omitempty"`
 		Secrets     []*EnrollSecret `json:"secrets,omitempty"`
+
+		ParentTeamID      *uint                         `json:"parent_team_id,omitempty"`
+		ParentTeamName    *string                       `json:"parent_team_name,omitempty"`
+		ScheduleOverrides []*TeamScheduledQueryOverride `json:"schedule_overrides,omitempty"`
+		Children          []*Team                       `json:"children,omitempty"`
 	}{
 		ID:          t.ID,
 		CreatedAt:   t.CreatedAt,
@@ -87,6 +111,11 @@ func (t Team) MarshalJSON() ([]byte, error) {
 		HostCount:   t.HostCount,
 		Hosts:       HostResponsesForHostsCheap(t.Hosts),
 		Secrets:     t.Secrets,
+
+		ParentTeamID:      t.ParentTeamID,
+		ParentTeamName:    t.ParentTeamName,
+		ScheduleOverrides: t.ScheduleOverrides,
+		Children:          t.Children,
 	}
 
 	return json.Marshal(x)
@@ -104,6 +133,11 @@ func (t *Team) UnmarshalJSON(b []byte) error {
 		HostCount   int             `json:"host_count"`
 		Hosts       []Host          `json:"hosts,omitempty"`
 		Secrets     []*EnrollSecret `json:"secrets,omitempty"`
+
+		ParentTeamID      *uint                         `json:"parent_team_id,omitempty"`
+		ParentTeamName    *string                       `json:"parent_team_name,omitempty"`
+		ScheduleOverrides []*TeamScheduledQueryOverride `json:"schedule_overrides,omitempty"`
+		Children          []*Team                       `json:"children,omitempty"`
 	}
 
 	if err := json.Unmarshal(b, &x); err != nil {
@@ -121,6 +155,11 @@ func (t *Team) UnmarshalJSON(b []byte) error {
 		HostCount:   x.HostCount,
 		Hosts:       x.Hosts,
 		Secrets:     x.Secrets,
+
+		ParentTeamID:      x.ParentTeamID,
+		ParentTeamName:    x.ParentTeamName,
+		ScheduleOverrides: x.ScheduleOverrides,
+		Children:          x.Children,
 	}
 
 	return nil
@@ -133,6 +172,10 @@ type TeamConfig struct {
 	Integrations    TeamIntegrations    `json:"integrations"`
 	Features        Features            `json:"features"`
 	MDM             TeamMDM             `json:"mdm"`
+	// HostLifecycle are the rules applied to the offline hosts of the team.
+	HostLifecycle *HostLifecycleSettings `json:"host_lifecycle,omitempty"`
+	// UpdateChannels are the update channels Orbit uses for the team's hosts.
+	UpdateChannels *UpdateChannels `json:"update_channels,omitempty"`
 }
 
 type TeamWebhookSettings struct {
@@ -158,6 +201,29 @@ type TeamSpecMDM struct {
 	// NOTE: TeamMDM must be kept in sync with TeamSpecMDM.
 }
 
+// InheritFrom fills the settings that are not set in the team's configuration
+// with the ones of its parent team. Agent options, the failing policies
+// webhook, the integrations, the update channels and the macOS updates are
+// inherited, while features and macOS settings (and their profiles) always
+// apply to the team that defines them.
+func (t *TeamConfig) InheritFrom(parent TeamConfig) {
+	if t.AgentOptions == nil || string(*t.AgentOptions) == "null" {
+		t.AgentOptions = parent.AgentOptions
+	}
+	if t.WebhookSettings.FailingPoliciesWebhook.isZero() {
+		t.WebhookSettings.FailingPoliciesWebhook = parent.WebhookSettings.FailingPoliciesWebhook
+	}
+	if t.Integrations.isEmpty() {
+		t.Integrations = parent.Integrations
+	}
+	if t.MDM.MacOSUpdates.MinimumVersion == "" && t.MDM.MacOSUpdates.Deadline == "" {
+		t.MDM.MacOSUpdates = parent.MDM.MacOSUpdates
+	}
+	if t.UpdateChannels == nil {
+		t.UpdateChannels = parent.UpdateChannels
+	}
+}
+
 // Scan implements the sql.Scanner interface
 func (t *TeamConfig) Scan(val interface{}) error {
 	switch v := val.(type) {
@@ -187,6 +253,102 @@ func (t Team) AuthzType() string {
 	return "team"
 }
 
+// TeamHierarchy maps the ID of every team to the ID of its parent team, 0 for
+// top-level teams.
+type TeamHierarchy map[uint]uint
+
+// Ancestors returns the IDs of the ancestors of the team, nearest first.
+func (h TeamHierarchy) Ancestors(teamID uint) []uint {
+	var ancestors []uint
+	seen := map[uint]bool{teamID: true}
+	for parentID := h[teamID]; parentID != 0 && !seen[parentID]; parentID = h[parentID] {
+		seen[parentID] = true
+		ancestors = append(ancestors, parentID)
+	}
+	return ancestors
+}
+
+// Descendants returns the IDs of all the descendants of the team, sorted.
+func (h TeamHierarchy) Descendants(teamID uint) []uint {
+	children := make(map[uint][]uint, len(h))
+	for id, parentID := range h {
+		if parentID != 0 {
+			children[parentID] = append(children[parentID], id)
+		}
+	}
+
+	var descendants []uint
+	seen := map[uint]bool{teamID: true}
+	queue := []uint{teamID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, childID := range children[id] {
+			if !seen[childID] {
+				seen[childID] = true
+				descendants = append(descendants, childID)
+				queue = append(queue, childID)
+			}
+		}
+	}
+	sort.Slice(descendants, func(i, j int) bool { return descendants[i] < descendants[j] })
+	return descendants
+}
+
+// IsDescendant returns true if the team identified by teamID is a descendant
+// of the team identified by ancestorID.
+func (h TeamHierarchy) IsDescendant(teamID, ancestorID uint) bool {
+	for _, id := range h.Ancestors(teamID) {
+		if id == ancestorID {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamTree nests the teams under their parent team using the Children field
+// and returns the root teams, keeping the order of the provided list. Teams
+// whose parent is not part of the list are returned as roots.
+func TeamTree(teams []*Team) []*Team {
+	byID := make(map[uint]*Team, len(teams))
+	for _, t := range teams {
+		byID[t.ID] = t
+	}
+
+	roots := make([]*Team, 0, len(teams))
+	for _, t := range teams {
+		if t.ParentTeamID != nil {
+			if parent := byID[*t.ParentTeamID]; parent != nil && parent != t {
+				parent.Children = append(parent.Children, t)
+				continue
+			}
+		}
+		roots = append(roots, t)
+	}
+	return roots
+}
+
+// TeamWithInheritedConfig returns the team identified by teamID with the
+// configuration it inherits from its ancestors applied to it.
+func TeamWithInheritedConfig(ctx context.Context, ds Datastore, teamID uint) (*Team, error) {
+	team, err := ds.Team(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[uint]bool{team.ID: true}
+	for parentID := team.ParentTeamID; parentID != nil && !seen[*parentID]; {
+		seen[*parentID] = true
+		parent, err := ds.Team(ctx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		team.Config.InheritFrom(parent.Config)
+		parentID = parent.ParentTeamID
+	}
+	return team, nil
+}
+
 // TeamUser is a user mapped to a team with a role.
 type TeamUser struct {
 	// User is the user object. At least ID must be specified for most uses.
@@ -294,6 +456,26 @@ type TeamSpec struct {
 	Secrets  []EnrollSecret   `json:"secrets,omitempty"`
 	Features *json.RawMessage `json:"features"`
 	MDM      TeamSpecMDM      `json:"mdm"`
+
+	// ParentTeam is the name of the parent team. If the key is not provided,
+	// the existing parent is left unmodified, an empty name makes the team a
+	// top-level team.
+	ParentTeam *string `json:"parent_team,omitempty"`
+
+	// HostLifecycle are the lifecycle rules of the team's hosts. If the key is
+	// not provided, the existing rules are left unmodified.
+	HostLifecycle *HostLifecycleSettings `json:"host_lifecycle,omitempty"`
+
+	// UpdateChannels are the update channels of the Orbit targets of the
+	// team's hosts. If the key is not provided, the existing channels are left
+	// unmodified.
+	UpdateChannels *UpdateChannels `json:"update_channels,omitempty"`
+
+	// ScheduleOverrides identifies the global scheduled queries by name. If
+	// the key is not provided, the existing overrides are left unmodified,
+	// otherwise they are replaced by the provided ones (an empty list clears
+	// them).
+	ScheduleOverrides *[]TeamScheduledQueryOverride `json:"schedule_overrides,omitempty"`
 }
 
 // TeamSpecFromTeam returns a TeamSpec constructed from the given Team.
@@ -318,11 +500,24 @@ func TeamSpecFromTeam(t *Team) (*TeamSpec, error) {
 	var mdmSpec TeamSpecMDM
 	mdmSpec.MacOSUpdates = t.Config.MDM.MacOSUpdates
 	mdmSpec.MacOSSettings = t.Config.MDM.MacOSSettings.ToMap()
+
+	var overrides *[]TeamScheduledQueryOverride
+	if len(t.ScheduleOverrides) > 0 {
+		list := make([]TeamScheduledQueryOverride, 0, len(t.ScheduleOverrides))
+		for _, o := range t.ScheduleOverrides {
+			list = append(list, *o)
+		}
+		overrides = &list
+	}
 	return &TeamSpec{
-		Name:         t.Name,
-		AgentOptions: agentOptions,
-		Features:     &featuresJSON,
-		Secrets:      secrets,
-		MDM:          mdmSpec,
+		Name:              t.Name,
+		ParentTeam:        t.ParentTeamName,
+		HostLifecycle:     t.Config.HostLifecycle,
+		UpdateChannels:    t.Config.UpdateChannels,
+		AgentOptions:      agentOptions,
+		Features:          &featuresJSON,
+		Secrets:           secrets,
+		MDM:               mdmSpec,
+		ScheduleOverrides: overrides,
 	}, nil
 }
@@ -0,0 +1,92 @@
+package fleet
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamHierarchy(t *testing.T) {
+	h := TeamHierarchy{1: 0, 2: 1, 3: 2, 4: 1, 5: 0}
+
+	require.Equal(t, []uint{2, 1}, h.Ancestors(3))
+	require.Empty(t, h.Ancestors(1))
+	require.Empty(t, h.Ancestors(99))
+
+	require.Equal(t, []uint{2, 3, 4}, h.Descendants(1))
+	require.Equal(t, []uint{3}, h.Descendants(2))
+	require.Empty(t, h.Descendants(5))
+
+	require.True(t, h.IsDescendant(3, 1))
+	require.False(t, h.IsDescendant(1, 3))
+	require.False(t, h.IsDescendant(5, 1))
+
+	// a cycle does not loop forever
+	cycle := TeamHierarchy{1: 2, 2: 1}
+	require.Equal(t, []uint{2}, cycle.Ancestors(1))
+	require.Equal(t, []uint{2}, cycle.Descendants(1))
+}
+
+func TestTeamTree(t *testing.T) {
+	teams := []*Team{
+		{ID: 1, Name: "parent"},
+		{ID: 2, Name: "child", ParentTeamID: ptr.Uint(1)},
+		{ID: 3, Name: "grandchild", ParentTeamID: ptr.Uint(2)},
+		{ID: 4, Name: "orphan", ParentTeamID: ptr.Uint(99)},
+	}
+
+	roots := TeamTree(teams)
+	require.Len(t, roots, 2)
+	require.Equal(t, "parent", roots[0].Name)
+	require.Equal(t, "orphan", roots[1].Name)
+	require.Len(t, roots[0].Children, 1)
+	require.Equal(t, "child", roots[0].Children[0].Name)
+	require.Len(t, roots[0].Children[0].Children, 1)
+	require.Equal(t, "grandchild", roots[0].Children[0].Children[0].Name)
+}
+
+func TestTeamConfigInheritFrom(t *testing.T) {
+	parentOpts := json.RawMessage(`{"config":{}}`)
+	parent := TeamConfig{
+		AgentOptions: &parentOpts,
+		WebhookSettings: TeamWebhookSettings{
+			FailingPoliciesWebhook: FailingPoliciesWebhookSettings{Enable: true, DestinationURL: "https://example.com"},
+		},
+		Integrations: TeamIntegrations{
+			Jira: []*TeamJiraIntegration{{URL: "https://jira.example.com", ProjectKey: "P"}},
+		},
+		Features: Features{EnableHostUsers: true},
+		MDM: TeamMDM{
+			MacOSUpdates:  MacOSUpdates{MinimumVersion: "13.1", Deadline: "2023-03-01"},
+			MacOSSettings: MacOSSettings{EnableDiskEncryption: true},
+		},
+		UpdateChannels: &UpdateChannels{Osqueryd: "stable"},
+	}
+
+	// an empty config inherits everything but the features and macOS settings
+	var child TeamConfig
+	child.InheritFrom(parent)
+	require.Equal(t, &parentOpts, child.AgentOptions)
+	require.Equal(t, parent.WebhookSettings, child.WebhookSettings)
+	require.Equal(t, parent.Integrations, child.Integrations)
+	require.Equal(t, parent.MDM.MacOSUpdates, child.MDM.MacOSUpdates)
+	require.Equal(t, parent.UpdateChannels, child.UpdateChannels)
+	require.False(t, child.Features.EnableHostUsers)
+	require.False(t, child.MDM.MacOSSettings.EnableDiskEncryption)
+
+	// the settings of the team take precedence
+	childOpts := json.RawMessage(`{"config":{"options":{}}}`)
+	child = TeamConfig{
+		AgentOptions: &childOpts,
+		MDM: TeamMDM{
+			MacOSUpdates: MacOSUpdates{MinimumVersion: "13.2", Deadline: "2023-04-01"},
+		},
+		UpdateChannels: &UpdateChannels{Orbit: "edge"},
+	}
+	child.InheritFrom(parent)
+	require.Equal(t, &childOpts, child.AgentOptions)
+	require.Equal(t, "13.2", child.MDM.MacOSUpdates.MinimumVersion)
+	require.Equal(t, &UpdateChannels{Orbit: "edge"}, child.UpdateChannels)
+}
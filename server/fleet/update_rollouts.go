@@ -0,0 +1,286 @@
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// OrbitUpdateTargets are the targets of Orbit that can be pinned to an update
+// channel by Fleet.
+var OrbitUpdateTargets = []string{"orbit", "osqueryd", "desktop"}
+
+// IsOrbitUpdateTarget returns true if the target can be pinned to an update
+// channel by Fleet.
+func IsOrbitUpdateTarget(target string) bool {
+	for _, t := range OrbitUpdateTargets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+var updateChannelRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateUpdateChannel checks that the channel is a valid name of an update
+// channel (e.g. "stable", "edge" or "5.8.1"). The channel is part of the path
+// of the targets in the update repository, so it must not contain separators.
+func ValidateUpdateChannel(channel string) error {
+	if len(channel) > 64 || !updateChannelRegexp.MatchString(channel) {
+		return fmt.Errorf("invalid update channel %q", channel)
+	}
+	return nil
+}
+
+// UpdateChannels are the update channels of the Orbit targets. A channel may
+// be a version (e.g. "5.8.1") to pin the target to that exact version. An
+// empty channel leaves the target on the channel Orbit was packaged with.
+type UpdateChannels struct {
+	Orbit    string `json:"orbit,omitempty"`
+	Osqueryd string `json:"osqueryd,omitempty"`
+	Desktop  string `json:"desktop,omitempty"`
+}
+
+// Validate checks that the channels that are set are valid.
+func (c UpdateChannels) Validate() error {
+	for target, channel := range c.ByTarget() {
+		if err := ValidateUpdateChannel(channel); err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// ByTarget returns the channels that are set, keyed by target.
+func (c UpdateChannels) ByTarget() map[string]string {
+	channels := make(map[string]string)
+	for target, channel := range map[string]string{
+		"orbit":    c.Orbit,
+		"osqueryd": c.Osqueryd,
+		"desktop":  c.Desktop,
+	} {
+		if channel != "" {
+			channels[target] = channel
+		}
+	}
+	return channels
+}
+
+// UpdateChannelsByTarget returns the update channels of the provided targets,
+// as returned by ByTarget.
+func UpdateChannelsByTarget(byTarget map[string]string) UpdateChannels {
+	return UpdateChannels{
+		Orbit:    byTarget["orbit"],
+		Osqueryd: byTarget["osqueryd"],
+		Desktop:  byTarget["desktop"],
+	}
+}
+
+// HostUpdateChannels are the update channels of the Orbit targets of a host.
+type HostUpdateChannels struct {
+	// Desired are the channels that Fleet pins the targets of the host to,
+	// from its team and the update rollouts.
+	Desired UpdateChannels `json:"desired"`
+	// Reported are the channels that Orbit reported using, including the ones
+	// it was packaged with.
+	Reported UpdateChannels `json:"reported"`
+}
+
+// IsEmpty returns true if no channel is set.
+func (c UpdateChannels) IsEmpty() bool {
+	return c.Orbit == "" && c.Osqueryd == "" && c.Desktop == ""
+}
+
+// UpdateRolloutStatus is the status of an update rollout.
+type UpdateRolloutStatus string
+
+const (
+	// UpdateRolloutStatusActive is the status of a rollout that pins new hosts
+	// of its ring to its channel.
+	UpdateRolloutStatusActive = UpdateRolloutStatus("active")
+	// UpdateRolloutStatusHalted is the status of a rollout that was halted,
+	// manually or because too many of its hosts went offline. A halted rollout
+	// does not pin new hosts, but the hosts already pinned stay on its channel.
+	UpdateRolloutStatusHalted = UpdateRolloutStatus("halted")
+)
+
+const (
+	// DefaultUpdateRolloutHaltOfflinePercentage is the default percentage of
+	// the pinned hosts that must be offline to halt a rollout.
+	DefaultUpdateRolloutHaltOfflinePercentage = 10
+	// DefaultUpdateRolloutOfflineMinutes is the default number of minutes
+	// without checking in after which a pinned host is considered offline.
+	DefaultUpdateRolloutOfflineMinutes = 60
+)
+
+// UpdateRollout pins an Orbit target of a percentage of the hosts of a team
+// and/or a label to an update channel, so that new versions of the target can
+// be rolled out in stages.
+type UpdateRollout struct {
+	ID   uint   `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	// Target is the Orbit target pinned by the rollout, one of
+	// OrbitUpdateTargets.
+	Target string `json:"target" db:"target"`
+	// Channel is the update channel the hosts are pinned to, which may be a
+	// version (e.g. "5.8.1").
+	Channel string `json:"channel" db:"channel"`
+	// TeamID restricts the rollout to the hosts of the team, if set.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// LabelID restricts the rollout to the hosts that are members of the label,
+	// if set.
+	LabelID *uint `json:"label_id" db:"label_id"`
+	// Percentage is the percentage of the hosts in the scope of the rollout
+	// that are pinned to its channel. Increasing it keeps the hosts already
+	// pinned.
+	Percentage uint `json:"percentage" db:"percentage"`
+	// HaltOfflinePercentage is the percentage of the pinned hosts that must be
+	// offline for the rollout to be halted automatically. Zero disables the
+	// automatic halt.
+	HaltOfflinePercentage uint `json:"halt_offline_percentage" db:"halt_offline_percentage"`
+	// OfflineMinutes is the number of minutes without checking in after which
+	// a pinned host is considered offline.
+	OfflineMinutes uint                `json:"offline_minutes" db:"offline_minutes"`
+	Status         UpdateRolloutStatus `json:"status" db:"status"`
+	HaltedAt       *time.Time          `json:"halted_at" db:"halted_at"`
+	// HaltReason explains why the rollout was halted.
+	HaltReason string `json:"halt_reason" db:"halt_reason"`
+	UpdateCreateTimestamps
+
+	// HostsCount is the number of hosts pinned to the channel of the rollout.
+	HostsCount uint `json:"hosts_count" db:"-"`
+	// OfflineHostsCount is the number of pinned hosts that are offline.
+	OfflineHostsCount uint `json:"offline_hosts_count" db:"-"`
+}
+
+// UpdateRolloutPayload is the payload to create or modify an update rollout.
+// The target, channel and scope of a rollout cannot be modified.
+type UpdateRolloutPayload struct {
+	Name                  *string              `json:"name"`
+	Target                *string              `json:"target"`
+	Channel               *string              `json:"channel"`
+	TeamID                *uint                `json:"team_id"`
+	LabelID               *uint                `json:"label_id"`
+	Percentage            *uint                `json:"percentage"`
+	HaltOfflinePercentage *uint                `json:"halt_offline_percentage"`
+	OfflineMinutes        *uint                `json:"offline_minutes"`
+	Status                *UpdateRolloutStatus `json:"status"`
+}
+
+// Validate checks the fields of the rollout.
+func (r *UpdateRollout) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if !IsOrbitUpdateTarget(r.Target) {
+		return fmt.Errorf("target must be one of %v", OrbitUpdateTargets)
+	}
+	if err := ValidateUpdateChannel(r.Channel); err != nil {
+		return err
+	}
+	if r.Percentage > 100 {
+		return errors.New("percentage must be between 0 and 100")
+	}
+	if r.HaltOfflinePercentage > 100 {
+		return errors.New("halt_offline_percentage must be between 0 and 100")
+	}
+	if r.OfflineMinutes == 0 {
+		return errors.New("offline_minutes must be greater than 0")
+	}
+	if r.Status != UpdateRolloutStatusActive && r.Status != UpdateRolloutStatusHalted {
+		return fmt.Errorf("status must be %q or %q", UpdateRolloutStatusActive, UpdateRolloutStatusHalted)
+	}
+	return nil
+}
+
+// InScope returns true if the host is in the team and the label of the
+// rollout.
+func (r *UpdateRollout) InScope(host UpdateRolloutHost) bool {
+	if !r.InTeamScope(host.TeamID) {
+		return false
+	}
+	if r.LabelID != nil && !host.LabelIDs[*r.LabelID] {
+		return false
+	}
+	return true
+}
+
+// InTeamScope returns true if a host in the team with the provided ID, nil
+// for no team, is in the team of the rollout.
+func (r *UpdateRollout) InTeamScope(teamID *uint) bool {
+	return r.TeamID == nil || (teamID != nil && *teamID == *r.TeamID)
+}
+
+// InRing returns true if the host with the provided UUID is in the percentage
+// of the hosts selected by the rollout. The selection is stable, so that
+// widening the rollout keeps the hosts already selected, and differs between
+// rollouts.
+func (r *UpdateRollout) InRing(hostUUID string) bool {
+	return UpdateRolloutBucket(r.ID, hostUUID) < r.Percentage
+}
+
+// UpdateRolloutBucket returns the bucket, between 0 and 99, of the host with
+// the provided UUID for the rollout.
+func UpdateRolloutBucket(rolloutID uint, hostUUID string) uint {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%s", rolloutID, hostUUID)
+	return uint(h.Sum32() % 100)
+}
+
+// ShouldHalt returns true if the offline hosts among the pinned hosts of the
+// rollout exceed its halt threshold.
+func (r *UpdateRollout) ShouldHalt(hosts, offline uint) bool {
+	if r.Status != UpdateRolloutStatusActive || r.HaltOfflinePercentage == 0 || hosts == 0 {
+		return false
+	}
+	return offline*100 >= hosts*r.HaltOfflinePercentage
+}
+
+// UpdateRolloutHost is the host attributes that determine the update rollouts
+// that apply to it.
+type UpdateRolloutHost struct {
+	UUID   string
+	TeamID *uint
+	// LabelIDs are the IDs of the labels the host is a member of.
+	LabelIDs map[uint]bool
+	// PinnedRolloutIDs are the IDs of the rollouts that already pinned the
+	// host.
+	PinnedRolloutIDs map[uint]bool
+}
+
+// UpdateRolloutChannels returns the update channels that the rollouts pin the
+// targets of the host to, along with the IDs of the rollouts that pin the host
+// for the first time. When several rollouts apply to the same target, the most
+// recently created wins.
+func UpdateRolloutChannels(rollouts []*UpdateRollout, host UpdateRolloutHost) (map[string]string, []uint) {
+	sorted := make([]*UpdateRollout, len(rollouts))
+	copy(sorted, rollouts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID > sorted[j].ID })
+
+	var channels map[string]string
+	var newIDs []uint
+	for _, r := range sorted {
+		if _, ok := channels[r.Target]; ok {
+			continue
+		}
+		if !r.InScope(host) {
+			continue
+		}
+		pinned := host.PinnedRolloutIDs[r.ID]
+		if !pinned && (r.Status != UpdateRolloutStatusActive || !r.InRing(host.UUID)) {
+			continue
+		}
+		if channels == nil {
+			channels = make(map[string]string)
+		}
+		channels[r.Target] = r.Channel
+		if !pinned {
+			newIDs = append(newIDs, r.ID)
+		}
+	}
+	return channels, newIDs
+}
@@ -0,0 +1,137 @@
+package fleet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUpdateChannel(t *testing.T) {
+	for _, c := range []string{"stable", "edge", "5.8.1", "1.x", "beta_2"} {
+		require.NoError(t, ValidateUpdateChannel(c), c)
+	}
+	for _, c := range []string{"", ".", "..", "../stable", "a/b", `a\b`, "-edge", "with space"} {
+		require.Error(t, ValidateUpdateChannel(c), c)
+	}
+}
+
+func TestUpdateChannels(t *testing.T) {
+	channels := UpdateChannels{Osqueryd: "5.8.1", Desktop: "edge"}
+	require.NoError(t, channels.Validate())
+	require.False(t, channels.IsEmpty())
+	require.Equal(t, map[string]string{"osqueryd": "5.8.1", "desktop": "edge"}, channels.ByTarget())
+	require.Equal(t, channels, UpdateChannelsByTarget(channels.ByTarget()))
+
+	require.True(t, UpdateChannels{}.IsEmpty())
+	require.Empty(t, UpdateChannels{}.ByTarget())
+	require.NoError(t, UpdateChannels{}.Validate())
+
+	err := UpdateChannels{Orbit: "../stable"}.Validate()
+	require.ErrorContains(t, err, "orbit")
+}
+
+func TestUpdateRolloutValidate(t *testing.T) {
+	valid := UpdateRollout{
+		Name:           "canary",
+		Target:         "osqueryd",
+		Channel:        "5.8.2",
+		Percentage:     5,
+		OfflineMinutes: 60,
+		Status:         UpdateRolloutStatusActive,
+	}
+	require.NoError(t, valid.Validate())
+
+	for _, c := range []struct {
+		name   string
+		modify func(r *UpdateRollout)
+	}{
+		{"no name", func(r *UpdateRollout) { r.Name = "" }},
+		{"unknown target", func(r *UpdateRollout) { r.Target = "nudge" }},
+		{"invalid channel", func(r *UpdateRollout) { r.Channel = "../edge" }},
+		{"percentage", func(r *UpdateRollout) { r.Percentage = 101 }},
+		{"halt percentage", func(r *UpdateRollout) { r.HaltOfflinePercentage = 101 }},
+		{"offline minutes", func(r *UpdateRollout) { r.OfflineMinutes = 0 }},
+		{"status", func(r *UpdateRollout) { r.Status = "paused" }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := valid
+			c.modify(&r)
+			require.Error(t, r.Validate())
+		})
+	}
+}
+
+func TestUpdateRolloutInRing(t *testing.T) {
+	r := &UpdateRollout{ID: 1, Percentage: 10}
+	var ring []string
+	for i := 0; i < 1000; i++ {
+		uuid := fmt.Sprintf("uuid-%d", i)
+		if r.InRing(uuid) {
+			ring = append(ring, uuid)
+		}
+	}
+	require.InDelta(t, 100, len(ring), 30)
+
+	// widening the rollout keeps the hosts already in the ring
+	r.Percentage = 50
+	for _, uuid := range ring {
+		require.True(t, r.InRing(uuid), uuid)
+	}
+
+	r.Percentage = 0
+	require.False(t, r.InRing(ring[0]))
+	r.Percentage = 100
+	require.True(t, r.InRing("any"))
+}
+
+func TestUpdateRolloutChannels(t *testing.T) {
+	active, halted := UpdateRolloutStatusActive, UpdateRolloutStatusHalted
+	rollouts := []*UpdateRollout{
+		{ID: 1, Target: "osqueryd", Channel: "5.8.1", Percentage: 100, Status: active},
+		{ID: 2, Target: "osqueryd", Channel: "5.8.2", TeamID: ptr.Uint(1), Percentage: 100, Status: active},
+		{ID: 3, Target: "orbit", Channel: "1.8.0", LabelID: ptr.Uint(7), Percentage: 100, Status: active},
+		{ID: 4, Target: "desktop", Channel: "1.8.0", Percentage: 100, Status: halted},
+		{ID: 5, Target: "desktop", Channel: "1.9.0", Percentage: 0, Status: active},
+	}
+
+	// a host of no team and no label only gets the global rollout
+	channels, newIDs := UpdateRolloutChannels(rollouts, UpdateRolloutHost{UUID: "a"})
+	require.Equal(t, map[string]string{"osqueryd": "5.8.1"}, channels)
+	require.Equal(t, []uint{1}, newIDs)
+
+	// the most recent rollout wins, and the halted rollout only applies to the
+	// hosts it already pinned
+	channels, newIDs = UpdateRolloutChannels(rollouts, UpdateRolloutHost{
+		UUID:             "b",
+		TeamID:           ptr.Uint(1),
+		LabelIDs:         map[uint]bool{7: true},
+		PinnedRolloutIDs: map[uint]bool{1: true, 4: true},
+	})
+	require.Equal(t, map[string]string{"osqueryd": "5.8.2", "orbit": "1.8.0", "desktop": "1.8.0"}, channels)
+	require.Equal(t, []uint{3, 2}, newIDs)
+
+	// a pinned host that left the scope of the rollout is unpinned
+	channels, newIDs = UpdateRolloutChannels(rollouts[1:2], UpdateRolloutHost{
+		UUID:             "c",
+		TeamID:           ptr.Uint(2),
+		PinnedRolloutIDs: map[uint]bool{2: true},
+	})
+	require.Nil(t, channels)
+	require.Nil(t, newIDs)
+}
+
+func TestUpdateRolloutShouldHalt(t *testing.T) {
+	r := &UpdateRollout{HaltOfflinePercentage: 10, Status: UpdateRolloutStatusActive}
+	require.False(t, r.ShouldHalt(0, 0))
+	require.False(t, r.ShouldHalt(20, 1))
+	require.True(t, r.ShouldHalt(20, 2))
+
+	r.HaltOfflinePercentage = 0
+	require.False(t, r.ShouldHalt(20, 20))
+
+	r.HaltOfflinePercentage = 10
+	r.Status = UpdateRolloutStatusHalted
+	require.False(t, r.ShouldHalt(20, 20))
+}
@@ -48,6 +48,15 @@ type UserTeam struct {
 	Team
 	// Role is the role the user has for the team.
 	Role string `json:"role" db:"role"`
+	// DescendantIDs are the IDs of the descendants of the team, only loaded
+	// when Role is admin as the role then applies to those teams too.
+	DescendantIDs []uint `json:"descendant_ids,omitempty" db:"-"`
+}
+
+// ScopeIDs returns the IDs of the teams the role applies to, that is the team
+// and, for admins, its descendants.
+func (u UserTeam) ScopeIDs() []uint {
+	return append([]uint{u.ID}, u.DescendantIDs...)
 }
 
 func (u UserTeam) MarshalJSON() ([]byte, error) {
@@ -63,6 +72,8 @@ func (u UserTeam) MarshalJSON() ([]byte, error) {
 		Hosts     []HostResponse  `json:"hosts,omitempty"`
 		Secrets   []*EnrollSecret `json:"secrets,omitempty"`
 		Role      string          `json:"role"`
+
+		DescendantIDs []uint `json:"descendant_ids,omitempty"`
 	}{
 		ID:          u.ID,
 		CreatedAt:   u.CreatedAt,
@@ -75,6 +86,8 @@ func (u UserTeam) MarshalJSON() ([]byte, error) {
 		Hosts:       HostResponsesForHostsCheap(u.Hosts),
 		Secrets:     u.Secrets,
 		Role:        u.Role,
+
+		DescendantIDs: u.DescendantIDs,
 	}
 
 	return json.Marshal(x)
@@ -93,6 +106,8 @@ func (u *UserTeam) UnmarshalJSON(b []byte) error {
 		Hosts     []Host          `json:"hosts,omitempty"`
 		Secrets   []*EnrollSecret `json:"secrets,omitempty"`
 		Role      string          `json:"role"`
+
+		DescendantIDs []uint `json:"descendant_ids,omitempty"`
 	}
 
 	if err := json.Unmarshal(b, &x); err != nil {
@@ -112,7 +127,8 @@ func (u *UserTeam) UnmarshalJSON(b []byte) error {
 			Hosts:       x.Hosts,
 			Secrets:     x.Secrets,
 		},
-		Role: x.Role,
+		Role:          x.Role,
+		DescendantIDs: x.DescendantIDs,
 	}
 
 	return nil
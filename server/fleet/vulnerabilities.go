@@ -112,4 +112,9 @@ const (
 	RHELOVALSource
 	MSRCSource
 	MacOfficeReleaseNotesSource
+	DebianOVALSource
+	SUSEOVALSource
+	AmazonLinuxALASSource
+	AlpineSecDBSource
+	OSVSource
 )
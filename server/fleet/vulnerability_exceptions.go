@@ -0,0 +1,108 @@
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// VulnerabilityExceptionReason is the reason why a vulnerability is
+// suppressed.
+type VulnerabilityExceptionReason string
+
+const (
+	// VulnerabilityExceptionFalsePositive is the reason of an exception for a
+	// CVE that was wrongly matched to the software.
+	VulnerabilityExceptionFalsePositive = VulnerabilityExceptionReason("false_positive")
+	// VulnerabilityExceptionAcceptedRisk is the reason of an exception for a
+	// CVE that affects the software, but whose risk was accepted (e.g. because
+	// it is mitigated).
+	VulnerabilityExceptionAcceptedRisk = VulnerabilityExceptionReason("accepted_risk")
+)
+
+var cveRegexp = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// VulnerabilityException suppresses a CVE until it expires, so that it is not
+// listed in the software vulnerabilities and does not trigger the
+// vulnerability automations (webhook and integrations).
+//
+// An exception without a team, software and host suppresses the CVE
+// everywhere. A team restricts it to the hosts of the team, a software to that
+// software, and a host to that single host.
+type VulnerabilityException struct {
+	ID  uint   `json:"id" db:"id"`
+	CVE string `json:"cve" db:"cve"`
+	// TeamID restricts the exception to the hosts of the team, if set. For an
+	// exception on a host, it is the team of the host when the exception was
+	// created, which determines who can manage it.
+	TeamID *uint `json:"team_id" db:"team_id"`
+	// SoftwareID restricts the exception to the software, if set.
+	SoftwareID *uint `json:"software_id" db:"software_id"`
+	// HostID restricts the exception to the host, if set.
+	HostID        *uint                        `json:"host_id" db:"host_id"`
+	Reason        VulnerabilityExceptionReason `json:"reason" db:"reason"`
+	Justification string                       `json:"justification" db:"justification"`
+	// Owner is the person or group accountable for the exception, e.g. to
+	// review it before it expires.
+	Owner string `json:"owner" db:"owner"`
+	// ExpiresAt is the time after which the exception no longer suppresses the
+	// CVE. The exception never expires if it is not set.
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	// AuthorID is the ID of the user who created the exception.
+	AuthorID *uint `json:"author_id" db:"author_id"`
+	UpdateCreateTimestamps
+}
+
+// AuthzType implements authz.AuthzTyper.
+func (e *VulnerabilityException) AuthzType() string {
+	return "vulnerability_exception"
+}
+
+// Validate checks the fields of the exception.
+func (e *VulnerabilityException) Validate() error {
+	if !cveRegexp.MatchString(e.CVE) {
+		return fmt.Errorf("invalid CVE %q", e.CVE)
+	}
+	if e.Reason != VulnerabilityExceptionFalsePositive && e.Reason != VulnerabilityExceptionAcceptedRisk {
+		return fmt.Errorf("reason must be %q or %q", VulnerabilityExceptionFalsePositive, VulnerabilityExceptionAcceptedRisk)
+	}
+	if e.Justification == "" {
+		return errors.New("justification is required")
+	}
+	if len(e.Owner) > 255 {
+		return errors.New("owner must be at most 255 characters")
+	}
+	return nil
+}
+
+// IsActive returns true if the exception has not expired at the provided time.
+func (e *VulnerabilityException) IsActive(now time.Time) bool {
+	return e.ExpiresAt == nil || e.ExpiresAt.After(now)
+}
+
+// VulnerabilityExceptionPayload is the payload to create or modify a
+// vulnerability exception. The CVE and the scope (team, software and host) of
+// an exception cannot be modified.
+type VulnerabilityExceptionPayload struct {
+	CVE           *string                       `json:"cve"`
+	TeamID        *uint                         `json:"team_id"`
+	SoftwareID    *uint                         `json:"software_id"`
+	HostID        *uint                         `json:"host_id"`
+	Reason        *VulnerabilityExceptionReason `json:"reason"`
+	Justification *string                       `json:"justification"`
+	Owner         *string                       `json:"owner"`
+	ExpiresAt     *time.Time                    `json:"expires_at"`
+}
+
+// VulnerabilityExceptionListOptions are the options to list vulnerability
+// exceptions.
+type VulnerabilityExceptionListOptions struct {
+	// TeamID lists the exceptions of the team and the ones without a team, if
+	// set. All exceptions are listed otherwise.
+	TeamID *uint `query:"team_id,optional"`
+	// CVE lists only the exceptions of the CVE, if set.
+	CVE string `query:"cve,optional"`
+	// IncludeExpired lists the expired exceptions too.
+	IncludeExpired bool `query:"include_expired,optional"`
+}
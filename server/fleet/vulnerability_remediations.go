@@ -0,0 +1,169 @@
+package fleet
+
+import (
+	"fmt"
+	"time"
+)
+
+// CVESeverity is the severity rating of a CVE, derived from its CVSS score
+// using the CVSS v3 qualitative ratings.
+type CVESeverity string
+
+const (
+	CVESeverityCritical = CVESeverity("critical")
+	CVESeverityHigh     = CVESeverity("high")
+	CVESeverityMedium   = CVESeverity("medium")
+	CVESeverityLow      = CVESeverity("low")
+	// CVESeverityUnknown is the severity of the CVEs without a CVSS score.
+	CVESeverityUnknown = CVESeverity("unknown")
+)
+
+// CVESeverities lists the severities from the most to the least severe.
+var CVESeverities = []CVESeverity{
+	CVESeverityCritical,
+	CVESeverityHigh,
+	CVESeverityMedium,
+	CVESeverityLow,
+	CVESeverityUnknown,
+}
+
+// CVESeverityFromScore returns the severity of a CVE with the provided CVSS
+// score, which may be nil if the CVE has no score.
+func CVESeverityFromScore(score *float64) CVESeverity {
+	switch {
+	case score == nil:
+		return CVESeverityUnknown
+	case *score >= 9:
+		return CVESeverityCritical
+	case *score >= 7:
+		return CVESeverityHigh
+	case *score >= 4:
+		return CVESeverityMedium
+	default:
+		return CVESeverityLow
+	}
+}
+
+// VulnerabilityRemediationSLA is part of the VulnerabilitySettings of the
+// AppConfig, it defines the number of days the CVEs detected on a host have to
+// be resolved in, depending on their severity. A value of 0 means no SLA for
+// the severity. CVEs of unknown severity have no SLA.
+type VulnerabilityRemediationSLA struct {
+	CriticalDays int `json:"critical_days"`
+	HighDays     int `json:"high_days"`
+	MediumDays   int `json:"medium_days"`
+	LowDays      int `json:"low_days"`
+}
+
+// Days returns the number of days of the SLA for the severity, 0 if there is
+// none.
+func (s VulnerabilityRemediationSLA) Days(severity CVESeverity) int {
+	switch severity {
+	case CVESeverityCritical:
+		return s.CriticalDays
+	case CVESeverityHigh:
+		return s.HighDays
+	case CVESeverityMedium:
+		return s.MediumDays
+	case CVESeverityLow:
+		return s.LowDays
+	default:
+		return 0
+	}
+}
+
+// ValidateVulnerabilityRemediationSLA checks the SLA windows of the
+// vulnerability settings.
+func ValidateVulnerabilityRemediationSLA(sla VulnerabilityRemediationSLA, invalid *InvalidArgumentError) {
+	for _, sev := range CVESeverities {
+		if sla.Days(sev) < 0 {
+			invalid.Append(fmt.Sprintf("vulnerability_settings.remediation_sla.%s_days", sev), "must be 0 or a positive number of days")
+		}
+	}
+}
+
+// VulnerabilityRemediationStatus is the status of a CVE detected on a host.
+type VulnerabilityRemediationStatus string
+
+const (
+	// VulnerabilityRemediationOpen is the status of a CVE still detected on
+	// the host.
+	VulnerabilityRemediationOpen = VulnerabilityRemediationStatus("open")
+	// VulnerabilityRemediationResolved is the status of a CVE no longer
+	// detected on the host.
+	VulnerabilityRemediationResolved = VulnerabilityRemediationStatus("resolved")
+)
+
+// HostVulnerabilityRemediation tracks a CVE on a host, from the vulnerability
+// processing run that first detected it to the one that no longer detected it
+// (i.e. the software or operating system was updated or removed). A CVE that
+// is detected again after it was resolved is tracked as a new remediation.
+type HostVulnerabilityRemediation struct {
+	ID              uint   `json:"id" db:"id"`
+	HostID          uint   `json:"host_id" db:"host_id"`
+	HostDisplayName string `json:"host_display_name" db:"host_display_name"`
+	// HostHostname is used in the SLA breaches webhook payloads.
+	HostHostname string   `json:"-" db:"host_hostname"`
+	HostTeamID   *uint    `json:"team_id" db:"team_id"`
+	CVE          string   `json:"cve" db:"cve"`
+	CVSSScore    *float64 `json:"cvss_score" db:"cvss_score"`
+	// Severity is derived from the CVSS score.
+	Severity        CVESeverity `json:"severity" db:"severity"`
+	FirstDetectedAt time.Time   `json:"first_detected_at" db:"first_detected_at"`
+	// ResolvedAt is the time the CVE was no longer detected on the host, nil
+	// if it is still detected.
+	ResolvedAt *time.Time `json:"resolved_at" db:"resolved_at"`
+	// SLADueAt is the time the CVE must be resolved by, nil if there is no
+	// SLA for its severity.
+	SLADueAt *time.Time `json:"sla_due_at" db:"sla_due_at"`
+	// SLABreached is true if the CVE was not resolved by the SLA due time.
+	SLABreached bool `json:"sla_breached" db:"sla_breached"`
+}
+
+// Status returns the status of the remediation.
+func (r *HostVulnerabilityRemediation) Status() VulnerabilityRemediationStatus {
+	if r.ResolvedAt != nil {
+		return VulnerabilityRemediationResolved
+	}
+	return VulnerabilityRemediationOpen
+}
+
+// VulnerabilityRemediationListOptions are the options to list the
+// remediations of the CVEs detected on hosts.
+type VulnerabilityRemediationListOptions struct {
+	ListOptions
+
+	// TeamID lists the remediations of the hosts of the team, if set.
+	TeamID *uint `query:"team_id,optional"`
+	// HostID lists the remediations of the host, if set.
+	HostID *uint `query:"host_id,optional"`
+	// CVE lists the remediations of the CVE, if set.
+	CVE string `query:"cve,optional"`
+	// Status lists the remediations with this status, if set.
+	Status VulnerabilityRemediationStatus `query:"status,optional"`
+	// Severity lists the remediations of the CVEs with this severity, if set.
+	Severity CVESeverity `query:"severity,optional"`
+	// SLABreached lists the remediations that breached their SLA, if set.
+	SLABreached bool `query:"sla_breached,optional"`
+}
+
+// VulnerabilityRemediationStats are the remediation statistics of the CVEs of
+// a severity.
+type VulnerabilityRemediationStats struct {
+	Severity CVESeverity `json:"severity" db:"severity"`
+	// SLADays is the SLA of the severity, 0 if there is none.
+	SLADays int `json:"sla_days" db:"-"`
+	// OpenCount is the number of CVEs still detected on hosts.
+	OpenCount uint `json:"open_count" db:"open_count"`
+	// ResolvedCount is the number of CVEs resolved on hosts.
+	ResolvedCount uint `json:"resolved_count" db:"resolved_count"`
+	// MeanTimeToRemediate is the mean time in seconds between the detection and
+	// the resolution of the resolved CVEs, nil if none was resolved.
+	MeanTimeToRemediate *float64 `json:"mean_time_to_remediate_seconds" db:"mean_time_to_remediate"`
+	// OpenSLABreachedCount is the number of CVEs still detected on hosts past
+	// their SLA.
+	OpenSLABreachedCount uint `json:"open_sla_breached_count" db:"open_sla_breached_count"`
+	// ResolvedSLABreachedCount is the number of CVEs resolved on hosts after
+	// their SLA.
+	ResolvedSLABreachedCount uint `json:"resolved_sla_breached_count" db:"resolved_sla_breached_count"`
+}
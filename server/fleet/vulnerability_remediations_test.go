@@ -0,0 +1,46 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVESeverityFromScore(t *testing.T) {
+	cases := []struct {
+		score *float64
+		want  CVESeverity
+	}{
+		{nil, CVESeverityUnknown},
+		{ptr.Float64(0), CVESeverityLow},
+		{ptr.Float64(3.9), CVESeverityLow},
+		{ptr.Float64(4), CVESeverityMedium},
+		{ptr.Float64(6.9), CVESeverityMedium},
+		{ptr.Float64(7), CVESeverityHigh},
+		{ptr.Float64(8.9), CVESeverityHigh},
+		{ptr.Float64(9), CVESeverityCritical},
+		{ptr.Float64(10), CVESeverityCritical},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, CVESeverityFromScore(c.score))
+	}
+}
+
+func TestVulnerabilityRemediationSLA(t *testing.T) {
+	sla := VulnerabilityRemediationSLA{CriticalDays: 7, HighDays: 30, MediumDays: 90}
+	require.Equal(t, 7, sla.Days(CVESeverityCritical))
+	require.Equal(t, 30, sla.Days(CVESeverityHigh))
+	require.Equal(t, 90, sla.Days(CVESeverityMedium))
+	require.Equal(t, 0, sla.Days(CVESeverityLow))
+	require.Equal(t, 0, sla.Days(CVESeverityUnknown))
+
+	invalid := &InvalidArgumentError{}
+	ValidateVulnerabilityRemediationSLA(sla, invalid)
+	require.False(t, invalid.HasErrors())
+
+	sla.HighDays = -1
+	ValidateVulnerabilityRemediationSLA(sla, invalid)
+	require.True(t, invalid.HasErrors())
+	require.ErrorContains(t, invalid, "vulnerability_settings.remediation_sla.high_days")
+}
@@ -0,0 +1,180 @@
+// Package hostlifecycle implements the host lifecycle rules of the teams,
+// which mark as stale, transfer or delete the hosts that have been offline for
+// some time.
+package hostlifecycle
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/hashicorp/go-multierror"
+)
+
+// Plan returns the actions that the lifecycle rules of the team take on its
+// hosts at the provided time, without applying them.
+func Plan(ctx context.Context, ds fleet.Datastore, team *fleet.Team, now time.Time) (*fleet.HostLifecycleReport, error) {
+	report := &fleet.HostLifecycleReport{
+		TeamID:   team.ID,
+		TeamName: team.Name,
+		Actions:  []fleet.HostLifecycleAction{},
+	}
+
+	settings := team.Config.HostLifecycle
+	if settings == nil || len(settings.Rules) == 0 {
+		return report, nil
+	}
+	report.DryRun = settings.DryRun
+
+	seenBefore := now.Add(-time.Duration(settings.MinOfflineDays()) * 24 * time.Hour)
+	hosts, err := ds.ListOfflineHosts(ctx, team.ID, seenBefore)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list offline hosts")
+	}
+	report.Actions = settings.ActionsFor(hosts, now)
+	return report, nil
+}
+
+// Apply runs the lifecycle rules of all teams. The actions of the teams in
+// dry-run mode are only logged. An error with a team doesn't prevent the rules
+// of the other teams from running, the errors are returned once all teams are
+// processed.
+func Apply(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger, now time.Time) error {
+	teams, err := ds.TeamsSummary(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list teams")
+	}
+
+	var errs error
+	for _, summary := range teams {
+		if err := applyTeam(ctx, ds, logger, summary.ID, now); err != nil {
+			level.Error(logger).Log("msg", "apply host lifecycle rules", "team_id", summary.ID, "err", err)
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	if err := ds.CleanupHostLifecycleStates(ctx); err != nil {
+		errs = multierror.Append(errs, ctxerr.Wrap(ctx, err, "cleanup host lifecycle states"))
+	}
+	return errs
+}
+
+func applyTeam(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger, teamID uint, now time.Time) error {
+	team, err := ds.Team(ctx, teamID)
+	if err != nil {
+		return ctxerr.Wrapf(ctx, err, "get team %d", teamID)
+	}
+	report, err := Plan(ctx, ds, team, now)
+	if err != nil {
+		return ctxerr.Wrapf(ctx, err, "plan host lifecycle of team %d", team.ID)
+	}
+
+	for _, a := range report.Actions {
+		level.Info(logger).Log(
+			"msg", "host lifecycle rule",
+			"dry_run", report.DryRun,
+			"team_id", team.ID,
+			"host_id", a.HostID,
+			"host_display_name", a.HostDisplayName,
+			"offline_days", a.OfflineDays,
+			"mark_stale", a.MarkStale,
+			"transfer_to_team", a.TransferToTeam,
+			"delete", a.Delete,
+		)
+	}
+	if report.DryRun || len(report.Actions) == 0 {
+		return nil
+	}
+	if err := applyReport(ctx, ds, logger, team, report); err != nil {
+		return ctxerr.Wrapf(ctx, err, "apply host lifecycle of team %d", team.ID)
+	}
+	return nil
+}
+
+func applyReport(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger, team *fleet.Team, report *fleet.HostLifecycleReport) error {
+	var staleIDs []uint
+	var deleted []fleet.HostLifecycleAction
+	transfers := make(map[string][]fleet.HostLifecycleAction)
+	for _, a := range report.Actions {
+		if a.MarkStale {
+			staleIDs = append(staleIDs, a.HostID)
+		}
+		if a.TransferToTeam != "" {
+			transfers[a.TransferToTeam] = append(transfers[a.TransferToTeam], a)
+		}
+		if a.Delete {
+			deleted = append(deleted, a)
+		}
+	}
+
+	if err := ds.SetHostsLifecycleState(ctx, staleIDs, fleet.HostLifecycleStateStale); err != nil {
+		return ctxerr.Wrap(ctx, err, "mark hosts as stale")
+	}
+
+	teamNames := make([]string, 0, len(transfers))
+	for name := range transfers {
+		teamNames = append(teamNames, name)
+	}
+	sort.Strings(teamNames)
+	for _, name := range teamNames {
+		newTeam, err := ds.TeamByName(ctx, name)
+		if err != nil {
+			if fleet.IsNotFound(err) {
+				// the team was deleted after the rules were configured, keep the
+				// hosts where they are.
+				level.Error(logger).Log("msg", "host lifecycle transfer team not found", "team_id", team.ID, "transfer_to_team", name)
+				continue
+			}
+			return ctxerr.Wrapf(ctx, err, "get team %s", name)
+		}
+		if newTeam.ID == team.ID {
+			continue
+		}
+
+		hostIDs, displayNames := hostsOf(transfers[name])
+		if err := ds.AddHostsToTeam(ctx, &newTeam.ID, hostIDs); err != nil {
+			return ctxerr.Wrap(ctx, err, "transfer hosts")
+		}
+		if err := ds.NewActivity(ctx, nil, fleet.ActivityTypeTransferredHostsByLifecycleRule{
+			TeamID:           team.ID,
+			TeamName:         team.Name,
+			NewTeamID:        newTeam.ID,
+			NewTeamName:      newTeam.Name,
+			HostIDs:          hostIDs,
+			HostDisplayNames: displayNames,
+		}); err != nil {
+			return ctxerr.Wrap(ctx, err, "create activity for transferred hosts")
+		}
+	}
+
+	if len(deleted) > 0 {
+		hostIDs, displayNames := hostsOf(deleted)
+		if err := ds.DeleteHosts(ctx, hostIDs); err != nil {
+			return ctxerr.Wrap(ctx, err, "delete hosts")
+		}
+		if err := ds.NewActivity(ctx, nil, fleet.ActivityTypeDeletedHostsByLifecycleRule{
+			TeamID:           team.ID,
+			TeamName:         team.Name,
+			HostIDs:          hostIDs,
+			HostDisplayNames: displayNames,
+		}); err != nil {
+			return ctxerr.Wrap(ctx, err, "create activity for deleted hosts")
+		}
+	}
+
+	return nil
+}
+
+func hostsOf(actions []fleet.HostLifecycleAction) ([]uint, []string) {
+	ids := make([]uint, 0, len(actions))
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		ids = append(ids, a.HostID)
+		names = append(names, a.HostDisplayName)
+	}
+	return ids, names
+}
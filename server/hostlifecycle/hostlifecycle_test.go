@@ -0,0 +1,152 @@
+package hostlifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	now := time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+	// team 1 marks hosts offline for 14 days as stale and transfers them to
+	// the quarantine team, team 2 (the quarantine team) deletes hosts offline
+	// for 60 days and team 3 is in dry-run mode.
+	rules := []fleet.HostLifecycleRule{{OfflineDays: 14, MarkStale: true, TransferToTeam: "quarantine"}}
+	teams := map[uint]*fleet.Team{
+		1: {ID: 1, Name: "workstations", Config: fleet.TeamConfig{HostLifecycle: &fleet.HostLifecycleSettings{Rules: rules}}},
+		2: {ID: 2, Name: "quarantine", Config: fleet.TeamConfig{HostLifecycle: &fleet.HostLifecycleSettings{
+			Rules: []fleet.HostLifecycleRule{{OfflineDays: 60, Delete: true}},
+		}}},
+		3: {ID: 3, Name: "servers", Config: fleet.TeamConfig{HostLifecycle: &fleet.HostLifecycleSettings{DryRun: true, Rules: rules}}},
+		4: {ID: 4, Name: "no rules"},
+	}
+	offline := map[uint][]*fleet.OfflineHost{
+		1: {{ID: 1, DisplayName: "h1", SeenTime: daysAgo(20)}},
+		2: {{ID: 2, DisplayName: "h2", SeenTime: daysAgo(61), LifecycleState: fleet.HostLifecycleStateStale}},
+		3: {{ID: 3, DisplayName: "h3", SeenTime: daysAgo(20)}},
+	}
+
+	ds.TeamsSummaryFunc = func(ctx context.Context) ([]*fleet.TeamSummary, error) {
+		return []*fleet.TeamSummary{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		return teams[tid], nil
+	}
+	ds.TeamByNameFunc = func(ctx context.Context, name string) (*fleet.Team, error) {
+		require.Equal(t, "quarantine", name)
+		return teams[2], nil
+	}
+	ds.ListOfflineHostsFunc = func(ctx context.Context, teamID uint, seenBefore time.Time) ([]*fleet.OfflineHost, error) {
+		require.NotEqual(t, uint(4), teamID)
+		return offline[teamID], nil
+	}
+	ds.SetHostsLifecycleStateFunc = func(ctx context.Context, hostIDs []uint, state fleet.HostLifecycleState) error {
+		if len(hostIDs) > 0 {
+			require.Equal(t, []uint{1}, hostIDs)
+			require.Equal(t, fleet.HostLifecycleStateStale, state)
+		}
+		return nil
+	}
+	ds.AddHostsToTeamFunc = func(ctx context.Context, teamID *uint, hostIDs []uint) error {
+		require.Equal(t, uint(2), *teamID)
+		require.Equal(t, []uint{1}, hostIDs)
+		return nil
+	}
+	ds.DeleteHostsFunc = func(ctx context.Context, ids []uint) error {
+		require.Equal(t, []uint{2}, ids)
+		return nil
+	}
+	var activities []fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+		require.Nil(t, user)
+		activities = append(activities, activity)
+		return nil
+	}
+	ds.CleanupHostLifecycleStatesFunc = func(ctx context.Context) error {
+		return nil
+	}
+
+	require.NoError(t, Apply(ctx, ds, kitlog.NewNopLogger(), now))
+	require.True(t, ds.AddHostsToTeamFuncInvoked)
+	require.True(t, ds.DeleteHostsFuncInvoked)
+	require.True(t, ds.CleanupHostLifecycleStatesFuncInvoked)
+	require.Equal(t, []fleet.ActivityDetails{
+		fleet.ActivityTypeTransferredHostsByLifecycleRule{
+			TeamID:           1,
+			TeamName:         "workstations",
+			NewTeamID:        2,
+			NewTeamName:      "quarantine",
+			HostIDs:          []uint{1},
+			HostDisplayNames: []string{"h1"},
+		},
+		fleet.ActivityTypeDeletedHostsByLifecycleRule{
+			TeamID:           2,
+			TeamName:         "quarantine",
+			HostIDs:          []uint{2},
+			HostDisplayNames: []string{"h2"},
+		},
+	}, activities)
+
+	// a failing team doesn't prevent the rules of the other teams from running
+	activities = nil
+	ds.DeleteHostsFuncInvoked = false
+	ds.CleanupHostLifecycleStatesFuncInvoked = false
+	ds.ListOfflineHostsFunc = func(ctx context.Context, teamID uint, seenBefore time.Time) ([]*fleet.OfflineHost, error) {
+		if teamID == 1 {
+			return nil, errors.New("list failed")
+		}
+		return offline[teamID], nil
+	}
+	err := Apply(ctx, ds, kitlog.NewNopLogger(), now)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "list failed")
+	require.True(t, ds.DeleteHostsFuncInvoked)
+	require.True(t, ds.CleanupHostLifecycleStatesFuncInvoked)
+	require.Len(t, activities, 1)
+	require.IsType(t, fleet.ActivityTypeDeletedHostsByLifecycleRule{}, activities[0])
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	now := time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	// a team without rules has no actions
+	report, err := Plan(ctx, ds, &fleet.Team{ID: 1, Name: "team1"}, now)
+	require.NoError(t, err)
+	require.Empty(t, report.Actions)
+	require.False(t, ds.ListOfflineHostsFuncInvoked)
+
+	ds.ListOfflineHostsFunc = func(ctx context.Context, teamID uint, seenBefore time.Time) ([]*fleet.OfflineHost, error) {
+		// only the hosts offline for the smallest number of days are listed
+		require.Equal(t, now.Add(-14*24*time.Hour), seenBefore)
+		return []*fleet.OfflineHost{
+			{ID: 1, DisplayName: "h1", SeenTime: now.Add(-15 * 24 * time.Hour)},
+			{ID: 2, DisplayName: "h2", SeenTime: now.Add(-90 * 24 * time.Hour)},
+		}, nil
+	}
+	team := &fleet.Team{ID: 1, Name: "team1", Config: fleet.TeamConfig{HostLifecycle: &fleet.HostLifecycleSettings{
+		DryRun: true,
+		Rules: []fleet.HostLifecycleRule{
+			{OfflineDays: 60, Delete: true},
+			{OfflineDays: 14, MarkStale: true},
+		},
+	}}}
+	report, err = Plan(ctx, ds, team, now)
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.Equal(t, []fleet.HostLifecycleAction{
+		{HostID: 1, HostDisplayName: "h1", SeenTime: now.Add(-15 * 24 * time.Hour), OfflineDays: 14, MarkStale: true},
+		{HostID: 2, HostDisplayName: "h2", SeenTime: now.Add(-90 * 24 * time.Hour), OfflineDays: 60, Delete: true},
+	}, report.Actions)
+}
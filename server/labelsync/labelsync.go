@@ -0,0 +1,142 @@
+// Package labelsync implements the sync of the membership of the manual
+// labels from the URLs that return their host identifiers (hostnames,
+// hardware serial numbers or UUIDs), e.g. to mirror the groups of a CMDB.
+package labelsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/hashicorp/go-multierror"
+)
+
+// maxResponseSize is the maximum size of the response of a sync URL.
+const maxResponseSize = 10 << 20
+
+// NewClient returns the HTTP client to fetch the sync URLs with. As the URLs
+// are provided by users, the client refuses to connect to loopback,
+// link-local and private addresses so that the sync cannot be used to reach
+// the services of the network of the Fleet server (e.g. a cloud metadata
+// endpoint). The addresses are checked once resolved, including on redirects,
+// and the proxy settings of the environment are ignored.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDialAddress,
+	}
+	tr := fleethttp.NewTransport()
+	tr.Proxy = nil
+	tr.DialContext = dialer.DialContext
+
+	client := fleethttp.NewClient(fleethttp.WithTimeout(timeout))
+	client.Transport = tr
+	return client
+}
+
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() {
+		return fmt.Errorf("connecting to address %s is not allowed", host)
+	}
+	return nil
+}
+
+// Sync replaces the hosts of each manual label that has a sync URL by the
+// hosts returned by the URL. A label whose URL cannot be fetched or parsed, or
+// whose hosts cannot be replaced, keeps its hosts and the error is recorded on
+// its sync, the other labels are still synced.
+func Sync(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger, client *http.Client, now time.Time) error {
+	syncs, err := ds.ListLabelMembershipSyncs(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list label membership syncs")
+	}
+
+	var errs error
+	for _, s := range syncs {
+		var syncErr string
+		identifiers, err := fetch(ctx, client, s.URL)
+		if err != nil {
+			syncErr = err.Error()
+			level.Error(logger).Log("msg", "fetch label hosts", "label_id", s.LabelID, "url", s.URL, "err", err)
+		} else if err := apply(ctx, ds, logger, s.LabelID, identifiers); err != nil {
+			syncErr = "apply hosts: " + err.Error()
+			level.Error(logger).Log("msg", "apply label hosts", "label_id", s.LabelID, "err", err)
+		}
+
+		if err := ds.SetLabelMembershipSyncResult(ctx, s.LabelID, now, syncErr); err != nil {
+			errs = multierror.Append(errs, ctxerr.Wrapf(ctx, err, "set sync result of label %d", s.LabelID))
+		}
+	}
+	return errs
+}
+
+func fetch(ctx context.Context, client *http.Client, url string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, text/csv")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get hosts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get hosts: unexpected status %s", resp.Status)
+	}
+
+	// read one more byte than allowed to detect the responses that are too
+	// large, rather than parsing them truncated
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read hosts: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("get hosts: response is larger than %d bytes", maxResponseSize)
+	}
+	return fleet.ParseHostIdentifiersResponse(resp.Header.Get("Content-Type"), bytes.NewReader(body))
+}
+
+func apply(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger, labelID uint, identifiers []string) error {
+	diff, err := ds.ApplyLabelMembership(ctx, labelID, identifiers, false)
+	if err != nil {
+		return err
+	}
+
+	level.Info(logger).Log(
+		"msg", "synced label hosts",
+		"label_id", labelID,
+		"added", len(diff.Added),
+		"removed", len(diff.Removed),
+		"unmatched", len(diff.Unmatched),
+	)
+	if diff.IsEmpty() {
+		return nil
+	}
+	return ds.NewActivity(ctx, nil, fleet.ActivityTypeEditedLabelMembership{
+		LabelID:        diff.LabelID,
+		LabelName:      diff.LabelName,
+		Source:         fleet.LabelMembershipSourceURL,
+		AddedCount:     len(diff.Added),
+		RemovedCount:   len(diff.Removed),
+		UnmatchedCount: len(diff.Unmatched),
+	})
+}
@@ -0,0 +1,116 @@
+package labelsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	now := time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"hosts": ["host1", "C02ABC", "host1"]}`))
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = w.Write([]byte("hostname\nhost2\n"))
+		case "/large":
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = w.Write([]byte("hostname\n" + strings.Repeat("a", maxResponseSize)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ds.ListLabelMembershipSyncsFunc = func(ctx context.Context) ([]*fleet.LabelMembershipSync, error) {
+		return []*fleet.LabelMembershipSync{
+			{LabelID: 1, LabelName: "json", URL: srv.URL + "/json"},
+			{LabelID: 2, LabelName: "csv", URL: srv.URL + "/csv"},
+			{LabelID: 3, LabelName: "missing", URL: srv.URL + "/missing"},
+			{LabelID: 4, LabelName: "large", URL: srv.URL + "/large"},
+			{LabelID: 5, LabelName: "failing", URL: srv.URL + "/csv"},
+			{LabelID: 6, LabelName: "after failing", URL: srv.URL + "/json"},
+		}, nil
+	}
+	applied := make(map[uint][]string)
+	ds.ApplyLabelMembershipFunc = func(ctx context.Context, labelID uint, identifiers []string, dryRun bool) (*fleet.LabelMembershipDiff, error) {
+		require.False(t, dryRun)
+		if labelID == 5 {
+			return nil, errors.New("apply failed")
+		}
+		applied[labelID] = identifiers
+		diff := &fleet.LabelMembershipDiff{LabelID: labelID}
+		if labelID == 1 {
+			diff.Added = []fleet.LabelMembershipHost{{ID: 1, DisplayName: "host1"}}
+			diff.Unmatched = []string{"C02ABC"}
+		}
+		return diff, nil
+	}
+	results := make(map[uint]string)
+	ds.SetLabelMembershipSyncResultFunc = func(ctx context.Context, labelID uint, syncedAt time.Time, syncErr string) error {
+		require.Equal(t, now, syncedAt)
+		results[labelID] = syncErr
+		return nil
+	}
+	var activities []fleet.ActivityTypeEditedLabelMembership
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+		require.Nil(t, user)
+		activities = append(activities, activity.(fleet.ActivityTypeEditedLabelMembership))
+		return nil
+	}
+
+	err := Sync(ctx, ds, kitlog.NewNopLogger(), srv.Client(), now)
+	require.NoError(t, err)
+
+	require.Equal(t, map[uint][]string{
+		1: {"host1", "C02ABC"},
+		2: {"host2"},
+		6: {"host1", "C02ABC"},
+	}, applied)
+	require.Len(t, results, 6)
+	require.Empty(t, results[1])
+	require.Empty(t, results[2])
+	require.Contains(t, results[3], "404")
+	require.Contains(t, results[4], "response is larger than")
+	require.Contains(t, results[5], "apply failed")
+	require.Empty(t, results[6])
+
+	// only the label whose membership changed generates an activity
+	require.Equal(t, []fleet.ActivityTypeEditedLabelMembership{{
+		LabelID:        1,
+		Source:         fleet.LabelMembershipSourceURL,
+		AddedCount:     1,
+		UnmatchedCount: 1,
+	}}, activities)
+}
+
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// the test server listens on a loopback address
+	_, err := fetch(context.Background(), NewClient(time.Second), srv.URL)
+	require.ErrorContains(t, err, "is not allowed")
+
+	for _, addr := range []string{"127.0.0.1:80", "[::1]:443", "169.254.169.254:80", "10.1.2.3:80", "192.168.0.1:80", "0.0.0.0:80"} {
+		require.Error(t, checkDialAddress("tcp", addr, nil), addr)
+	}
+	require.NoError(t, checkDialAddress("tcp", "93.184.216.34:443", nil))
+}
@@ -0,0 +1,229 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	hostctx "github.com/fleetdm/fleet/v4/server/contexts/host"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/hashicorp/go-multierror"
+)
+
+// Route sends the logs that match all of its non-empty criteria to its
+// plugins. The name, pack and query criteria are glob patterns (see
+// path.Match).
+type Route struct {
+	// Plugins are the destinations of the matching logs, they must be part of
+	// the configured plugins.
+	Plugins []string
+	// Names match the "name" field of the log, e.g. "pack/Global/users".
+	Names []string
+	// Packs match the pack name of scheduled query results, e.g. "Global" or
+	// "Team: Workstations". Unlike in Names, "*" also matches "/" in Packs and
+	// Queries.
+	Packs []string
+	// Queries match the name of the scheduled query.
+	Queries []string
+	// TeamIDs match the team of the host sending the logs, 0 matches the
+	// hosts that are not in a team.
+	TeamIDs []uint
+}
+
+// matches returns whether the log matches the route. logName is the "name"
+// field of the log and host the host that sent it, if any.
+func (r Route) matches(logName string, host *fleet.Host) bool {
+	if len(r.Names) > 0 && !matchAny(r.Names, logName) {
+		return false
+	}
+
+	if len(r.Packs) > 0 || len(r.Queries) > 0 {
+		packName, queryName, ok := fleet.SplitScheduledQueryLogName(logName, host)
+		if !ok {
+			return false
+		}
+		if len(r.Packs) > 0 && !matchAnyName(r.Packs, packName) {
+			return false
+		}
+		if len(r.Queries) > 0 && !matchAnyName(r.Queries, queryName) {
+			return false
+		}
+	}
+
+	if len(r.TeamIDs) > 0 {
+		if host == nil {
+			return false
+		}
+		var teamID uint
+		if host.TeamID != nil {
+			teamID = *host.TeamID
+		}
+		found := false
+		for _, id := range r.TeamIDs {
+			if id == teamID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Route) needsLogName() bool {
+	return len(r.Names) > 0 || len(r.Packs) > 0 || len(r.Queries) > 0
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyName is like matchAny for a pack or query name, in which "/" is not
+// a delimiter, so that e.g. "Team: *" matches "Team: Servers/EU".
+func matchAnyName(patterns []string, s string) bool {
+	const slash = "\x00"
+	s = strings.ReplaceAll(s, "/", slash)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ReplaceAll(p, "/", slash), s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type compositeSink struct {
+	plugin string
+	writer fleet.JSONLogger
+}
+
+// compositeLogWriter fans out the logs to multiple plugins. Each log is sent
+// to the plugins of the first route it matches, or to all the plugins if it
+// doesn't match any route.
+type compositeLogWriter struct {
+	sinks  []compositeSink
+	routes []Route
+	logger log.Logger
+}
+
+func newCompositeLogWriter(sinks []compositeSink, routes []Route, logger log.Logger) (*compositeLogWriter, error) {
+	known := make(map[string]bool, len(sinks))
+	for _, s := range sinks {
+		known[s.plugin] = true
+	}
+	for i, r := range routes {
+		if len(r.Plugins) == 0 {
+			return nil, fmt.Errorf("route %d: missing plugins", i)
+		}
+		for _, p := range r.Plugins {
+			if !known[p] {
+				return nil, fmt.Errorf("route %d: plugin %q is not configured", i, p)
+			}
+		}
+		for _, patterns := range [][]string{r.Names, r.Packs, r.Queries} {
+			for _, p := range patterns {
+				if _, err := path.Match(p, ""); err != nil {
+					return nil, fmt.Errorf("route %d: invalid pattern %q: %w", i, p, err)
+				}
+			}
+		}
+	}
+	return &compositeLogWriter{sinks: sinks, routes: routes, logger: logger}, nil
+}
+
+// Write routes the logs and writes them to the plugins concurrently, so that a
+// slow or failing plugin doesn't block the others. An error is returned if any
+// plugin fails to write its logs, like the single plugin writers do, so that
+// osquery sends the logs again instead of dropping the logs routed to that
+// plugin. The plugins that succeeded receive those logs again.
+func (w *compositeLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	batches := w.route(ctx, logs)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+	for _, sink := range w.sinks {
+		batch := batches[sink.plugin]
+		if len(batch) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(sink compositeSink, batch []json.RawMessage) {
+			defer wg.Done()
+			err := sink.writer.Write(ctx, batch)
+
+			if err != nil {
+				level.Error(w.logger).Log("msg", "write logs", "plugin", sink.plugin, "err", err)
+				mu.Lock()
+				errs = multierror.Append(errs, fmt.Errorf("%s: %w", sink.plugin, err))
+				mu.Unlock()
+			}
+		}(sink, batch)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// route returns the logs to write by plugin name.
+func (w *compositeLogWriter) route(ctx context.Context, logs []json.RawMessage) map[string][]json.RawMessage {
+	batches := make(map[string][]json.RawMessage, len(w.sinks))
+	if len(w.routes) == 0 {
+		for _, s := range w.sinks {
+			batches[s.plugin] = logs
+		}
+		return batches
+	}
+
+	needsLogName := false
+	for _, r := range w.routes {
+		if r.needsLogName() {
+			needsLogName = true
+			break
+		}
+	}
+	host, _ := hostctx.FromContext(ctx)
+
+	for _, l := range logs {
+		var logName string
+		if needsLogName {
+			var named struct {
+				Name string `json:"name"`
+			}
+			// logs that are not JSON objects or have no name only match the
+			// routes without name criteria.
+			_ = json.Unmarshal(l, &named)
+			logName = named.Name
+		}
+
+		matched := false
+		for _, r := range w.routes {
+			if r.matches(logName, host) {
+				for _, p := range r.Plugins {
+					batches[p] = append(batches[p], l)
+				}
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			for _, s := range w.sinks {
+				batches[s.plugin] = append(batches[s.plugin], l)
+			}
+		}
+	}
+	return batches
+}
@@ -0,0 +1,165 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	hostctx "github.com/fleetdm/fleet/v4/server/contexts/host"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogWriter struct {
+	mu   sync.Mutex
+	logs []string
+	err  error
+}
+
+func (w *recordingLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	for _, l := range logs {
+		w.logs = append(w.logs, string(l))
+	}
+	return nil
+}
+
+func TestCompositeLogWriterFanOut(t *testing.T) {
+	a, b := &recordingLogWriter{}, &recordingLogWriter{}
+	w, err := newCompositeLogWriter([]compositeSink{{"a", a}, {"b", b}}, nil, log.NewNopLogger())
+	require.NoError(t, err)
+
+	logs := []json.RawMessage{json.RawMessage(`{"name":"foo"}`), json.RawMessage(`{"name":"bar"}`)}
+	require.NoError(t, w.Write(context.Background(), logs))
+	assert.Equal(t, []string{`{"name":"foo"}`, `{"name":"bar"}`}, a.logs)
+	assert.Equal(t, []string{`{"name":"foo"}`, `{"name":"bar"}`}, b.logs)
+}
+
+func TestCompositeLogWriterRoutes(t *testing.T) {
+	kinesis, fs, splunk := &recordingLogWriter{}, &recordingLogWriter{}, &recordingLogWriter{}
+	sinks := []compositeSink{{"kinesis", kinesis}, {"filesystem", fs}, {"splunk", splunk}}
+	routes := []Route{
+		{Plugins: []string{"kinesis"}, Queries: []string{"security_*"}},
+		{Plugins: []string{"splunk", "filesystem"}, Packs: []string{"Team: *"}, TeamIDs: []uint{1}},
+		{Plugins: []string{"splunk"}, Names: []string{"osquery_info"}},
+		{Plugins: []string{"filesystem"}, TeamIDs: []uint{0}},
+	}
+	w, err := newCompositeLogWriter(sinks, routes, log.NewNopLogger())
+	require.NoError(t, err)
+
+	security := `{"name":"pack/Global/security_processes"}`
+	teamQuery := `{"name":"pack/Team: Workstations/users"}`
+	named := `{"name":"osquery_info"}`
+	global := `{"name":"pack/Global/users"}`
+	status := `{"severity":"0","message":"hello"}`
+	logs := []json.RawMessage{
+		json.RawMessage(security),
+		json.RawMessage(teamQuery),
+		json.RawMessage(named),
+		json.RawMessage(global),
+		json.RawMessage(status),
+	}
+
+	// host in team 1: the team query goes to splunk and filesystem, the
+	// other unmatched logs go to all plugins.
+	ctx := hostctx.NewContext(context.Background(), &fleet.Host{ID: 1, TeamID: ptr.Uint(1)})
+	require.NoError(t, w.Write(ctx, logs))
+	assert.Equal(t, []string{security, global, status}, kinesis.logs)
+	assert.Equal(t, []string{teamQuery, global, status}, fs.logs)
+	assert.Equal(t, []string{teamQuery, named, global, status}, splunk.logs)
+
+	// host without team: the catch-all route for team 0 sends the unmatched
+	// logs only to the filesystem.
+	kinesis.logs, fs.logs, splunk.logs = nil, nil, nil
+	ctx = hostctx.NewContext(context.Background(), &fleet.Host{ID: 2})
+	require.NoError(t, w.Write(ctx, logs))
+	assert.Equal(t, []string{security}, kinesis.logs)
+	assert.Equal(t, []string{teamQuery, global, status}, fs.logs)
+	assert.Equal(t, []string{named}, splunk.logs)
+
+	// no host (e.g. audit logs): team routes never match.
+	kinesis.logs, fs.logs, splunk.logs = nil, nil, nil
+	require.NoError(t, w.Write(context.Background(), []json.RawMessage{json.RawMessage(status)}))
+	assert.Equal(t, []string{status}, kinesis.logs)
+	assert.Equal(t, []string{status}, fs.logs)
+	assert.Equal(t, []string{status}, splunk.logs)
+}
+
+func TestCompositeLogWriterRoutesTeamNameWithSlash(t *testing.T) {
+	splunk, fs := &recordingLogWriter{}, &recordingLogWriter{}
+	sinks := []compositeSink{{"splunk", splunk}, {"filesystem", fs}}
+	routes := []Route{
+		{Plugins: []string{"splunk"}, Packs: []string{"Team: *"}, Queries: []string{"users"}},
+		{Plugins: []string{"filesystem"}, Packs: []string{"Team: Servers/EU"}},
+	}
+	w, err := newCompositeLogWriter(sinks, routes, log.NewNopLogger())
+	require.NoError(t, err)
+
+	teamQuery := `{"name":"pack/Team: Servers/EU/users"}`
+	otherQuery := `{"name":"pack/Team: Servers/EU/processes"}`
+	ctx := hostctx.NewContext(context.Background(), &fleet.Host{ID: 1, TeamID: ptr.Uint(1), TeamName: ptr.String("Servers/EU")})
+	require.NoError(t, w.Write(ctx, []json.RawMessage{json.RawMessage(teamQuery), json.RawMessage(otherQuery)}))
+	assert.Equal(t, []string{teamQuery}, splunk.logs)
+	assert.Equal(t, []string{otherQuery}, fs.logs)
+}
+
+func TestCompositeLogWriterFailures(t *testing.T) {
+	good, bad := &recordingLogWriter{}, &recordingLogWriter{err: errors.New("boom")}
+	routes := []Route{{Plugins: []string{"bad"}, Names: []string{"bad_*"}}}
+	w, err := newCompositeLogWriter([]compositeSink{{"good", good}, {"bad", bad}}, routes, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// one plugin fails, the other still gets the logs but an error is returned
+	// so that the logs are sent again
+	err = w.Write(context.Background(), []json.RawMessage{json.RawMessage(`{"name":"foo"}`)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bad: boom")
+	assert.Equal(t, []string{`{"name":"foo"}`}, good.logs)
+
+	// all the plugins the logs were routed to failed
+	err = w.Write(context.Background(), []json.RawMessage{json.RawMessage(`{"name":"bad_query"}`)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bad: boom")
+}
+
+func TestCompositeLogWriterInvalidRoutes(t *testing.T) {
+	sinks := []compositeSink{{"a", &recordingLogWriter{}}}
+
+	_, err := newCompositeLogWriter(sinks, []Route{{Names: []string{"foo"}}}, log.NewNopLogger())
+	require.ErrorContains(t, err, "missing plugins")
+
+	_, err = newCompositeLogWriter(sinks, []Route{{Plugins: []string{"b"}}}, log.NewNopLogger())
+	require.ErrorContains(t, err, `plugin "b" is not configured`)
+
+	_, err = newCompositeLogWriter(sinks, []Route{{Plugins: []string{"a"}, Queries: []string{"[bad"}}}, log.NewNopLogger())
+	require.ErrorContains(t, err, "invalid pattern")
+}
+
+func TestNewJSONLoggerComposite(t *testing.T) {
+	_, err := NewJSONLogger("result", Config{Plugin: "stdout, stdout"}, log.NewNopLogger())
+	require.ErrorContains(t, err, "duplicate result log plugin")
+
+	_, err = NewJSONLogger("result", Config{Plugin: "stdout,foo"}, log.NewNopLogger())
+	require.ErrorContains(t, err, "unknown result log plugin: foo")
+
+	writer, err := NewJSONLogger("result", Config{
+		Plugin: "stdout,http",
+		HTTP:   HTTPConfig{URL: "http://localhost:1"},
+		Routes: []Route{{Plugins: []string{"stdout"}}},
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.IsType(t, &compositeLogWriter{}, writer)
+
+	writer, err = NewJSONLogger("result", Config{Plugin: "stdout"}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.IsType(t, &stdoutLogWriter{}, writer)
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// elasticsearchBulkResponse is the subset of the response of the _bulk API
+// needed to retry the failed records, see
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// NewElasticsearchLogWriter returns a writer that indexes the logs in
+// Elasticsearch with the _bulk API.
+func NewElasticsearchLogWriter(conf ElasticsearchConfig, logger log.Logger) (*httpBatchLogWriter, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("create Elasticsearch writer: missing URL")
+	}
+	if conf.Index == "" {
+		return nil, fmt.Errorf("create Elasticsearch writer: missing index")
+	}
+
+	header := http.Header{"Content-Type": {"application/x-ndjson"}}
+	switch {
+	case conf.APIKey != "":
+		header.Set("Authorization", "ApiKey "+conf.APIKey)
+	case conf.Username != "":
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(conf.Username+":"+conf.Password)))
+	}
+	url := strings.TrimSuffix(conf.URL, "/") + "/_bulk"
+	w, err := newHTTPBatchLogWriter(url, header, conf.Batch, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create Elasticsearch writer: %w", err)
+	}
+
+	// The create action works with both indices and data streams.
+	action, err := json.Marshal(map[string]interface{}{"create": map[string]string{"_index": conf.Index}})
+	if err != nil {
+		return nil, fmt.Errorf("create Elasticsearch writer: %w", err)
+	}
+	action = append(action, '\n')
+
+	w.encode = func(log json.RawMessage) ([]byte, error) {
+		record := make([]byte, 0, len(action)+len(log)+1)
+		record = append(record, action...)
+		record = append(record, log...)
+		return append(record, '\n'), nil
+	}
+	w.handleResponse = func(resp *http.Response, records [][]byte) ([][]byte, error) {
+		if resp.StatusCode != http.StatusOK {
+			return checkHTTPBatchResponse(resp, records)
+		}
+
+		var bulkResp elasticsearchBulkResponse
+		if err := json.NewDecoder(resp.Body).Decode(&bulkResp); err != nil {
+			return nil, fmt.Errorf("decode bulk response: %w", err)
+		}
+		if !bulkResp.Errors {
+			return nil, nil
+		}
+		if len(bulkResp.Items) != len(records) {
+			return nil, fmt.Errorf("bulk response has %d items for %d records", len(bulkResp.Items), len(records))
+		}
+
+		// Collect the records rejected because of throttling for retry, the
+		// other failures cannot be fixed by retrying.
+		var retry [][]byte
+		var dropped int
+		var firstErr string
+		for i, item := range bulkResp.Items {
+			for _, result := range item {
+				if result.Error == nil {
+					continue
+				}
+				if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+					retry = append(retry, records[i])
+					continue
+				}
+				dropped++
+				if firstErr == "" {
+					firstErr = result.Error.Type + ": " + result.Error.Reason
+				}
+			}
+		}
+		if dropped > 0 {
+			level.Info(logger).Log(
+				"msg", "dropping logs rejected by Elasticsearch",
+				"count", dropped,
+				"first_error", firstErr,
+			)
+		}
+		return retry, nil
+	}
+	return w, nil
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticsearchLogWriter(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "elastic", user)
+		assert.Equal(t, "changeme", pass)
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(b))
+
+		switch len(bodies) {
+		case 1:
+			// the second record is throttled and the third one is rejected
+			fmt.Fprint(w, `{"errors":true,"items":[
+				{"create":{"status":201}},
+				{"create":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"rejected"}}},
+				{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}
+			]}`)
+		default:
+			fmt.Fprint(w, `{"errors":false,"items":[{"create":{"status":201}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	w, err := NewElasticsearchLogWriter(ElasticsearchConfig{
+		URL:      srv.URL,
+		Index:    "fleet-osquery-results",
+		Username: "elastic",
+		Password: "changeme",
+		Batch:    testHTTPBatchConfig(),
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+	w.retryBackoff = time.Millisecond
+
+	require.NoError(t, w.Write(context.Background(), logs))
+	action := `{"create":{"_index":"fleet-osquery-results"}}` + "\n"
+	require.Equal(t, []string{
+		action + `{"foo":"bar"}` + "\n" + action + `{"flim":"flam"}` + "\n" + action + `{"jim":"jom"}` + "\n",
+		// only the throttled record is retried
+		action + `{"flim":"flam"}` + "\n",
+	}, bodies)
+}
+
+func TestElasticsearchLogWriterAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ApiKey some-key", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"errors":false}`)
+	}))
+	defer srv.Close()
+
+	w, err := NewElasticsearchLogWriter(ElasticsearchConfig{URL: srv.URL, Index: "idx", APIKey: "some-key", Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, w.Write(context.Background(), logs))
+
+	_, err = NewElasticsearchLogWriter(ElasticsearchConfig{URL: srv.URL, Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.ErrorContains(t, err, "missing index")
+}
@@ -0,0 +1,198 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+	"github.com/fleetdm/fleet/v4/server/config"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// httpBatchResponseFunc checks the response to a batch of records. It returns
+// the records that must be retried, or an error if the batch failed and must
+// not be retried.
+type httpBatchResponseFunc func(resp *http.Response, records [][]byte) (retry [][]byte, err error)
+
+// httpBatchLogWriter is the writer used by the HTTP-based logging plugins. It
+// batches the records like the Kinesis and Firehose writers do, and leaves the
+// encoding of the records and the handling of the responses to the plugin.
+type httpBatchLogWriter struct {
+	client *http.Client
+	url    string
+	header http.Header
+	logger log.Logger
+
+	maxRecordsInBatch int
+	maxSizeOfRecord   int
+	maxSizeOfBatch    int
+	maxRetries        int
+	// retryBackoff is the base delay between retries, doubled at each retry.
+	retryBackoff time.Duration
+
+	// encode returns the bytes of the log in the request body.
+	encode         func(log json.RawMessage) ([]byte, error)
+	handleResponse httpBatchResponseFunc
+}
+
+func newHTTPBatchLogWriter(url string, header http.Header, conf HTTPBatchConfig, logger log.Logger) (*httpBatchLogWriter, error) {
+	if url == "" {
+		return nil, fmt.Errorf("missing URL")
+	}
+
+	tlsConf := config.TLS{
+		TLSCA:         conf.TLSCA,
+		TLSCert:       conf.TLSCert,
+		TLSKey:        conf.TLSKey,
+		TLSServerName: conf.TLSServerName,
+	}
+	tlsConfig, err := tlsConf.ToTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("tls config: %w", err)
+	}
+
+	return &httpBatchLogWriter{
+		client:            fleethttp.NewClient(fleethttp.WithTimeout(conf.Timeout), fleethttp.WithTLSClientConfig(tlsConfig)),
+		url:               url,
+		header:            header,
+		logger:            logger,
+		maxRecordsInBatch: conf.MaxRecordsInBatch,
+		maxSizeOfRecord:   conf.MaxSizeOfRecord,
+		maxSizeOfBatch:    conf.MaxSizeOfBatch,
+		maxRetries:        conf.MaxRetries,
+		retryBackoff:      100 * time.Millisecond,
+		encode: func(log json.RawMessage) ([]byte, error) {
+			// so we get nice NDJSON
+			return append(log, '\n'), nil
+		},
+		handleResponse: checkHTTPBatchResponse,
+	}, nil
+}
+
+func (w *httpBatchLogWriter) Write(ctx context.Context, logs []json.RawMessage) error {
+	var records [][]byte
+	totalBytes := 0
+	for _, log := range logs {
+		record, err := w.encode(log)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "encode log")
+		}
+
+		// Consistent with the Kinesis and Firehose writers, logs that are too
+		// big are dropped, the beginning bytes of the log should help the Fleet
+		// admin diagnose the query generating huge results.
+		if w.maxSizeOfRecord > 0 && len(record) > w.maxSizeOfRecord {
+			prefix := log
+			if len(prefix) > 100 {
+				prefix = prefix[:100]
+			}
+			level.Info(w.logger).Log(
+				"msg", "dropping log over the record size limit",
+				"size", len(record),
+				"limit", w.maxSizeOfRecord,
+				"log", string(prefix)+"...",
+			)
+			continue
+		}
+
+		// If adding this log will exceed the limit on number of records in the
+		// batch, or the limit on total size of the batch, we need to push this
+		// batch before adding any more.
+		if len(records) > 0 &&
+			((w.maxRecordsInBatch > 0 && len(records) >= w.maxRecordsInBatch) ||
+				(w.maxSizeOfBatch > 0 && totalBytes+len(record) > w.maxSizeOfBatch)) {
+			if err := w.post(ctx, 0, records); err != nil {
+				return ctxerr.Wrap(ctx, err, "post records")
+			}
+			totalBytes = 0
+			records = nil
+		}
+
+		records = append(records, record)
+		totalBytes += len(record)
+	}
+
+	// Push the final batch
+	if len(records) > 0 {
+		if err := w.post(ctx, 0, records); err != nil {
+			return ctxerr.Wrap(ctx, err, "post records")
+		}
+	}
+	return nil
+}
+
+func (w *httpBatchLogWriter) post(ctx context.Context, try int, records [][]byte) error {
+	if try > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.retryBackoff * time.Duration(math.Pow(2.0, float64(try)))):
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(bytes.Join(records, nil)))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	for k, v := range w.header {
+		req.Header[k] = v
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		if try < w.maxRetries {
+			// Retry with backoff
+			return w.post(ctx, try+1, records)
+		}
+		// Retries expired
+		return err
+	}
+	defer resp.Body.Close()
+
+	retry, err := w.handleResponse(resp, records)
+	if err != nil {
+		// Not retryable
+		return err
+	}
+	if len(retry) > 0 {
+		if try >= w.maxRetries {
+			return fmt.Errorf("failed to post %d records, retries exhausted. Last status: %d", len(retry), resp.StatusCode)
+		}
+		return w.post(ctx, try+1, retry)
+	}
+	return nil
+}
+
+// checkHTTPBatchResponse is the default httpBatchResponseFunc, it retries the
+// whole batch on throttling and server errors.
+func checkHTTPBatchResponse(resp *http.Response, records [][]byte) ([][]byte, error) {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return records, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+func NewHTTPLogWriter(conf HTTPConfig, logger log.Logger) (*httpBatchLogWriter, error) {
+	header := http.Header{"Content-Type": {"application/x-ndjson"}}
+	if conf.Authorization != "" {
+		header.Set("Authorization", conf.Authorization)
+	}
+	w, err := newHTTPBatchLogWriter(conf.URL, header, conf.Batch, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create HTTP writer: %w", err)
+	}
+	return w, nil
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer returns a test server that records the bodies of the
+// requests it receives and responds with the status returned by statusFn.
+func recordingServer(t *testing.T, statusFn func(call int, r *http.Request, body string) int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		call := len(bodies)
+		mu.Unlock()
+
+		w.WriteHeader(statusFn(call, r, string(b)))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+}
+
+func testHTTPBatchConfig() HTTPBatchConfig {
+	return HTTPBatchConfig{
+		MaxRecordsInBatch: 500,
+		MaxSizeOfRecord:   1000 * 1000,
+		MaxSizeOfBatch:    5 * 1000 * 1000,
+		MaxRetries:        3,
+		Timeout:           5 * time.Second,
+	}
+}
+
+func TestHTTPLogWriterNormalPost(t *testing.T) {
+	srv, bodies := recordingServer(t, func(call int, r *http.Request, body string) int {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		return http.StatusOK
+	})
+
+	w, err := NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Authorization: "Bearer secret", Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(context.Background(), logs))
+	require.Equal(t, []string{`{"foo":"bar"}` + "\n" + `{"flim":"flam"}` + "\n" + `{"jim":"jom"}` + "\n"}, bodies())
+}
+
+func TestHTTPLogWriterBatching(t *testing.T) {
+	srv, bodies := recordingServer(t, func(call int, r *http.Request, body string) int {
+		return http.StatusOK
+	})
+
+	conf := testHTTPBatchConfig()
+	conf.MaxRecordsInBatch = 2
+	w, err := NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: conf}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// batches are split on the number of records
+	require.NoError(t, w.Write(context.Background(), logs))
+	require.Equal(t, []string{
+		`{"foo":"bar"}` + "\n" + `{"flim":"flam"}` + "\n",
+		`{"jim":"jom"}` + "\n",
+	}, bodies())
+
+	// and on the size of the batch, the records over the size limit are dropped
+	srv, bodies = recordingServer(t, func(call int, r *http.Request, body string) int {
+		return http.StatusOK
+	})
+	conf = testHTTPBatchConfig()
+	conf.MaxSizeOfBatch = 30
+	conf.MaxSizeOfRecord = 20
+	w, err = NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: conf}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(context.Background(), []json.RawMessage{
+		json.RawMessage(`{"foo":"bar"}`),
+		json.RawMessage(`{"too":"big to fit in a record"}`),
+		json.RawMessage(`{"flim":"flam"}`),
+		json.RawMessage(`{"jim":"jom"}`),
+	}))
+	require.Equal(t, []string{
+		`{"foo":"bar"}` + "\n" + `{"flim":"flam"}` + "\n",
+		`{"jim":"jom"}` + "\n",
+	}, bodies())
+}
+
+func TestHTTPLogWriterRetries(t *testing.T) {
+	// server errors and throttling are retried
+	srv, bodies := recordingServer(t, func(call int, r *http.Request, body string) int {
+		switch call {
+		case 1:
+			return http.StatusServiceUnavailable
+		case 2:
+			return http.StatusTooManyRequests
+		default:
+			return http.StatusOK
+		}
+	})
+	w, err := NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.NoError(t, err)
+	w.retryBackoff = time.Millisecond
+
+	require.NoError(t, w.Write(context.Background(), logs))
+	require.Len(t, bodies(), 3)
+
+	// until the retries are exhausted
+	srv, bodies = recordingServer(t, func(call int, r *http.Request, body string) int {
+		return http.StatusBadGateway
+	})
+	w, err = NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.NoError(t, err)
+	w.retryBackoff = time.Millisecond
+
+	err = w.Write(context.Background(), logs)
+	require.ErrorContains(t, err, "retries exhausted")
+	require.Len(t, bodies(), 4)
+
+	// client errors are not retried
+	srv, bodies = recordingServer(t, func(call int, r *http.Request, body string) int {
+		return http.StatusBadRequest
+	})
+	w, err = NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.NoError(t, err)
+	w.retryBackoff = time.Millisecond
+
+	err = w.Write(context.Background(), logs)
+	require.ErrorContains(t, err, "unexpected status 400")
+	require.Len(t, bodies(), 1)
+}
+
+func TestHTTPLogWriterTLS(t *testing.T) {
+	var got string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		got = string(b)
+	}))
+	defer srv.Close()
+
+	// the server's certificate is not trusted by default
+	w, err := NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.NoError(t, err)
+	w.maxRetries = 0
+	require.Error(t, w.Write(context.Background(), logs))
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	require.NoError(t, err)
+
+	conf := testHTTPBatchConfig()
+	conf.TLSCA = caFile
+	w, err = NewHTTPLogWriter(HTTPConfig{URL: srv.URL, Batch: conf}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, w.Write(context.Background(), logs))
+	require.Equal(t, 3, strings.Count(got, "\n"))
+
+	_, err = NewHTTPLogWriter(HTTPConfig{Batch: conf}, log.NewNopLogger())
+	require.ErrorContains(t, err, "missing URL")
+}
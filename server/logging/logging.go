@@ -3,6 +3,8 @@ package logging
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/go-kit/kit/log"
@@ -63,18 +65,113 @@ type KafkaRESTConfig struct {
 	Timeout          int
 }
 
+// HTTPBatchConfig holds the batching, retry, size limit and TLS options shared
+// by the logging plugins that send the logs in batches of HTTP POST requests.
+type HTTPBatchConfig struct {
+	MaxRecordsInBatch int
+	MaxSizeOfRecord   int
+	MaxSizeOfBatch    int
+	MaxRetries        int
+	Timeout           time.Duration
+
+	TLSCA         string
+	TLSCert       string
+	TLSKey        string
+	TLSServerName string
+}
+
+// HTTPConfig holds the options of the generic HTTP logging plugin, which POSTs
+// the logs as newline-delimited JSON.
+type HTTPConfig struct {
+	URL string
+
+	// Authorization is the value of the Authorization header sent with the
+	// requests, if any.
+	Authorization string
+	Batch         HTTPBatchConfig
+}
+
+// SplunkConfig holds the options of the Splunk HTTP Event Collector logging
+// plugin.
+type SplunkConfig struct {
+	// URL is the base URL of the HTTP Event Collector, e.g.
+	// https://splunk.example.com:8088.
+	URL        string
+	Token      string
+	Index      string
+	SourceType string
+	Source     string
+	Batch      HTTPBatchConfig
+}
+
+// ElasticsearchConfig holds the options of the Elasticsearch logging plugin,
+// which uses the _bulk API.
+type ElasticsearchConfig struct {
+	// URL is the base URL of the Elasticsearch cluster, e.g.
+	// https://elasticsearch.example.com:9200.
+	URL      string
+	Index    string
+	Username string
+	Password string
+	APIKey   string
+	Batch    HTTPBatchConfig
+}
+
 type Config struct {
+	// Plugin is the name of the plugin, or a comma-separated list of plugins
+	// to send the logs to multiple destinations.
 	Plugin string
+	// Routes select the plugins of each log when there are multiple plugins,
+	// see Route.
+	Routes []Route
 
-	Filesystem FilesystemConfig
-	Firehose   FirehoseConfig
-	Kinesis    KinesisConfig
-	Lambda     LambdaConfig
-	PubSub     PubSubConfig
-	KafkaREST  KafkaRESTConfig
+	Filesystem    FilesystemConfig
+	Firehose      FirehoseConfig
+	Kinesis       KinesisConfig
+	Lambda        LambdaConfig
+	PubSub        PubSubConfig
+	KafkaREST     KafkaRESTConfig
+	HTTP          HTTPConfig
+	Splunk        SplunkConfig
+	Elasticsearch ElasticsearchConfig
 }
 
 func NewJSONLogger(name string, config Config, logger log.Logger) (fleet.JSONLogger, error) {
+	var plugins []string
+	for _, p := range strings.Split(config.Plugin, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			plugins = append(plugins, p)
+		}
+	}
+	if len(plugins) <= 1 && len(config.Routes) == 0 {
+		return newPluginJSONLogger(name, config, logger)
+	}
+
+	seen := make(map[string]bool, len(plugins))
+	sinks := make([]compositeSink, 0, len(plugins))
+	for _, p := range plugins {
+		if seen[p] {
+			return nil, fmt.Errorf("duplicate %s log plugin: %s", name, p)
+		}
+		seen[p] = true
+
+		pluginConfig := config
+		pluginConfig.Plugin = p
+		pluginConfig.Routes = nil
+		writer, err := newPluginJSONLogger(name, pluginConfig, log.With(logger, "plugin", p))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, compositeSink{plugin: p, writer: writer})
+	}
+	writer, err := newCompositeLogWriter(sinks, config.Routes, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create composite %s logger: %w", name, err)
+	}
+	return fleet.JSONLogger(writer), nil
+}
+
+func newPluginJSONLogger(name string, config Config, logger log.Logger) (fleet.JSONLogger, error) {
 	switch config.Plugin {
 	case "":
 		// Allow "" to mean filesystem for backwards compatibility
@@ -166,6 +263,24 @@ func NewJSONLogger(name string, config Config, logger log.Logger) (fleet.JSONLog
 			return nil, fmt.Errorf("create kafka rest %s logger: %w", name, err)
 		}
 		return fleet.JSONLogger(writer), nil
+	case "http":
+		writer, err := NewHTTPLogWriter(config.HTTP, logger)
+		if err != nil {
+			return nil, fmt.Errorf("create http %s logger: %w", name, err)
+		}
+		return fleet.JSONLogger(writer), nil
+	case "splunk":
+		writer, err := NewSplunkLogWriter(config.Splunk, logger)
+		if err != nil {
+			return nil, fmt.Errorf("create splunk %s logger: %w", name, err)
+		}
+		return fleet.JSONLogger(writer), nil
+	case "elasticsearch":
+		writer, err := NewElasticsearchLogWriter(config.Elasticsearch, logger)
+		if err != nil {
+			return nil, fmt.Errorf("create elasticsearch %s logger: %w", name, err)
+		}
+		return fleet.JSONLogger(writer), nil
 	default:
 		return nil, fmt.Errorf(
 			"unknown %s log plugin: %s", name, config.Plugin,
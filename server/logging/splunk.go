@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+// splunkEvent is the format of an event sent to the HTTP Event Collector, see
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector
+type splunkEvent struct {
+	Index      string          `json:"index,omitempty"`
+	SourceType string          `json:"sourcetype,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// NewSplunkLogWriter returns a writer that sends the logs to the Splunk HTTP
+// Event Collector, in batches of events.
+func NewSplunkLogWriter(conf SplunkConfig, logger log.Logger) (*httpBatchLogWriter, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("create Splunk writer: missing URL")
+	}
+	if conf.Token == "" {
+		return nil, fmt.Errorf("create Splunk writer: missing token")
+	}
+	header := http.Header{
+		"Content-Type":  {"application/json"},
+		"Authorization": {"Splunk " + conf.Token},
+	}
+	url := strings.TrimSuffix(conf.URL, "/") + "/services/collector/event"
+	w, err := newHTTPBatchLogWriter(url, header, conf.Batch, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create Splunk writer: %w", err)
+	}
+
+	w.encode = func(log json.RawMessage) ([]byte, error) {
+		b, err := json.Marshal(splunkEvent{
+			Index:      conf.Index,
+			SourceType: conf.SourceType,
+			Source:     conf.Source,
+			Event:      log,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+	return w, nil
+}
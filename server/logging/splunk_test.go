@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplunkLogWriter(t *testing.T) {
+	srv, bodies := recordingServer(t, func(call int, r *http.Request, body string) int {
+		assert.Equal(t, "/services/collector/event", r.URL.Path)
+		assert.Equal(t, "Splunk some-token", r.Header.Get("Authorization"))
+		return http.StatusOK
+	})
+
+	w, err := NewSplunkLogWriter(SplunkConfig{
+		URL:        srv.URL + "/",
+		Token:      "some-token",
+		Index:      "osquery",
+		SourceType: "osquery:result",
+		Batch:      testHTTPBatchConfig(),
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(context.Background(), logs[:2]))
+	require.Equal(t, []string{
+		`{"index":"osquery","sourcetype":"osquery:result","event":{"foo":"bar"}}` + "\n" +
+			`{"index":"osquery","sourcetype":"osquery:result","event":{"flim":"flam"}}` + "\n",
+	}, bodies())
+
+	_, err = NewSplunkLogWriter(SplunkConfig{URL: srv.URL, Batch: testHTTPBatchConfig()}, log.NewNopLogger())
+	require.ErrorContains(t, err, "missing token")
+}
@@ -71,6 +71,12 @@ type QueryByNameFunc func(ctx context.Context, name string, opts ...fleet.Option
 
 type ObserverCanRunQueryFunc func(ctx context.Context, queryID uint) (bool, error)
 
+type QueryStoringResultsByScheduledNameFunc func(ctx context.Context, packName string, scheduledQueryName string) (*fleet.Query, error)
+
+type OverwriteQueryResultRowsFunc func(ctx context.Context, queryID uint, hostID uint, rows []json.RawMessage, fetchedAt time.Time, maxRows int) error
+
+type QueryReportFunc func(ctx context.Context, queryID uint, filter fleet.TeamFilter, opts fleet.QueryReportListOptions) ([]*fleet.QueryReportRow, error)
+
 type NewDistributedQueryCampaignFunc func(ctx context.Context, camp *fleet.DistributedQueryCampaign) (*fleet.DistributedQueryCampaign, error)
 
 type DistributedQueryCampaignFunc func(ctx context.Context, id uint) (*fleet.DistributedQueryCampaign, error)
@@ -85,6 +91,12 @@ type CleanupDistributedQueryCampaignsFunc func(ctx context.Context, now time.Tim
 
 type DistributedQueryCampaignsForQueryFunc func(ctx context.Context, queryID uint) ([]*fleet.DistributedQueryCampaign, error)
 
+type SaveDistributedQueryCampaignResultFunc func(ctx context.Context, result *fleet.DistributedQueryResult) error
+
+type ListDistributedQueryCampaignResultsFunc func(ctx context.Context, campaignID uint, opt fleet.ListOptions) ([]*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error)
+
+type CleanupDistributedQueryCampaignResultsFunc func(ctx context.Context, before time.Time) error
+
 type ApplyPackSpecsFunc func(ctx context.Context, specs []*fleet.PackSpec) error
 
 type GetPackSpecsFunc func(ctx context.Context) ([]*fleet.PackSpec, error)
@@ -145,6 +157,54 @@ type AsyncBatchDeleteLabelMembershipFunc func(ctx context.Context, batch [][2]ui
 
 type AsyncBatchUpdateLabelTimestampFunc func(ctx context.Context, ids []uint, ts time.Time) error
 
+type ApplyLabelMembershipFunc func(ctx context.Context, labelID uint, identifiers []string, dryRun bool) (*fleet.LabelMembershipDiff, error)
+
+type ListLabelMembershipSyncsFunc func(ctx context.Context) ([]*fleet.LabelMembershipSync, error)
+
+type SetLabelMembershipSyncResultFunc func(ctx context.Context, labelID uint, syncedAt time.Time, syncErr string) error
+
+type NewUpdateRolloutFunc func(ctx context.Context, rollout *fleet.UpdateRollout) (*fleet.UpdateRollout, error)
+
+type UpdateRolloutFunc func(ctx context.Context, id uint) (*fleet.UpdateRollout, error)
+
+type ListUpdateRolloutsFunc func(ctx context.Context) ([]*fleet.UpdateRollout, error)
+
+type SaveUpdateRolloutFunc func(ctx context.Context, rollout *fleet.UpdateRollout) error
+
+type DeleteUpdateRolloutFunc func(ctx context.Context, id uint) error
+
+type ListHostUpdateRolloutIDsFunc func(ctx context.Context, hostID uint) ([]uint, error)
+
+type AddHostUpdateRolloutsFunc func(ctx context.Context, hostID uint, rolloutIDs []uint, pinnedAt time.Time) error
+
+type CountUpdateRolloutHostsFunc func(ctx context.Context, rolloutID uint, offlineSince time.Time) (hosts uint, offline uint, err error)
+
+type NewVulnerabilityExceptionFunc func(ctx context.Context, exception *fleet.VulnerabilityException) (*fleet.VulnerabilityException, error)
+
+type VulnerabilityExceptionFunc func(ctx context.Context, id uint) (*fleet.VulnerabilityException, error)
+
+type ListVulnerabilityExceptionsFunc func(ctx context.Context, opts fleet.VulnerabilityExceptionListOptions) ([]*fleet.VulnerabilityException, error)
+
+type SaveVulnerabilityExceptionFunc func(ctx context.Context, exception *fleet.VulnerabilityException) error
+
+type DeleteVulnerabilityExceptionFunc func(ctx context.Context, id uint) error
+
+type ApplyCPETranslationRuleSpecsFunc func(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error
+
+type GetCPETranslationRuleSpecsFunc func(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error)
+
+type DeleteCPETranslationRuleFunc func(ctx context.Context, name string) error
+
+type SyncHostVulnerabilityRemediationsFunc func(ctx context.Context, now time.Time) error
+
+type ListHostVulnerabilityRemediationsFunc func(ctx context.Context, opts fleet.VulnerabilityRemediationListOptions, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, *fleet.PaginationMetadata, error)
+
+type VulnerabilityRemediationStatsFunc func(ctx context.Context, teamID *uint, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.VulnerabilityRemediationStats, error)
+
+type ListUnnotifiedVulnerabilitySLABreachesFunc func(ctx context.Context, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, error)
+
+type MarkVulnerabilitySLABreachesNotifiedFunc func(ctx context.Context, ids []uint, now time.Time) error
+
 type NewHostFunc func(ctx context.Context, host *fleet.Host) (*fleet.Host, error)
 
 type DeleteHostFunc func(ctx context.Context, hid uint) error
@@ -283,6 +343,14 @@ type ScheduledQueryFunc func(ctx context.Context, id uint) (*fleet.ScheduledQuer
 
 type CleanupExpiredHostsFunc func(ctx context.Context) ([]uint, error)
 
+type ListOfflineHostsFunc func(ctx context.Context, teamID uint, seenBefore time.Time) ([]*fleet.OfflineHost, error)
+
+type ListHostsForTeamAssignmentFunc func(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error)
+
+type SetHostsLifecycleStateFunc func(ctx context.Context, hostIDs []uint, state fleet.HostLifecycleState) error
+
+type CleanupHostLifecycleStatesFunc func(ctx context.Context) error
+
 type ScheduledQueryIDsByNameFunc func(ctx context.Context, batchSize int, packAndSchedQueryNames ...[2]string) ([]uint, error)
 
 type NewTeamFunc func(ctx context.Context, team *fleet.Team) (*fleet.Team, error)
@@ -299,16 +367,26 @@ type ListTeamsFunc func(ctx context.Context, filter fleet.TeamFilter, opt fleet.
 
 type TeamsSummaryFunc func(ctx context.Context) ([]*fleet.TeamSummary, error)
 
+type TeamHierarchyFunc func(ctx context.Context) (fleet.TeamHierarchy, error)
+
 type SearchTeamsFunc func(ctx context.Context, filter fleet.TeamFilter, matchQuery string, omit ...uint) ([]*fleet.Team, error)
 
 type TeamEnrollSecretsFunc func(ctx context.Context, teamID uint) ([]*fleet.EnrollSecret, error)
 
 type DeleteIntegrationsFromTeamsFunc func(ctx context.Context, deletedIntgs fleet.Integrations) error
 
+type ListTeamScheduledQueryOverridesFunc func(ctx context.Context, teamID uint) (fleet.TeamScheduledQueryOverrideList, error)
+
+type ReplaceTeamScheduledQueryOverridesFunc func(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error
+
 type ListSoftwareForVulnDetectionFunc func(ctx context.Context, hostID uint) ([]fleet.Software, error)
 
 type ListSoftwareVulnerabilitiesByHostIDsSourceFunc func(ctx context.Context, hostIDs []uint, source fleet.VulnerabilitySource) (map[uint][]fleet.SoftwareVulnerability, error)
 
+type ListSoftwareVulnerabilitiesBySourceFunc func(ctx context.Context, source fleet.VulnerabilitySource) ([]fleet.SoftwareVulnerability, error)
+
+type ListSoftwareVulnerabilitiesBySoftwareIDFunc func(ctx context.Context, softwareID uint) ([]fleet.SoftwareVulnerability, error)
+
 type LoadHostSoftwareFunc func(ctx context.Context, host *fleet.Host, includeCVEScores bool) error
 
 type ListSoftwareBySourceIterFunc func(ctx context.Context, sources []string) (fleet.SoftwareIterator, error)
@@ -319,6 +397,10 @@ type AddCPEForSoftwareFunc func(ctx context.Context, software fleet.Software, cp
 
 type ListSoftwareCPEsFunc func(ctx context.Context) ([]fleet.SoftwareCPE, error)
 
+type AllSoftwareWithCPEIteratorFunc func(ctx context.Context, excludedSources []string) (fleet.SoftwareIterator, error)
+
+type DeleteSoftwareCPEsFunc func(ctx context.Context, softwareIDs []uint) error
+
 type InsertSoftwareVulnerabilitiesFunc func(ctx context.Context, vulns []fleet.SoftwareVulnerability, source fleet.VulnerabilitySource) (int64, error)
 
 type SoftwareByIDFunc func(ctx context.Context, id uint, includeCVEScores bool) (*fleet.Software, error)
@@ -327,7 +409,7 @@ type ListSoftwareByHostIDShortFunc func(ctx context.Context, hostID uint) ([]fle
 
 type SyncHostsSoftwareFunc func(ctx context.Context, updatedAt time.Time) error
 
-type HostsBySoftwareIDsFunc func(ctx context.Context, softwareIDs []uint) ([]*fleet.HostShort, error)
+type VulnerableHostsBySoftwareIDsFunc func(ctx context.Context, softwareIDs []uint, cve string) ([]*fleet.HostShort, error)
 
 type HostsByCVEFunc func(ctx context.Context, cve string) ([]*fleet.HostShort, error)
 
@@ -349,6 +431,18 @@ type ListActivitiesFunc func(ctx context.Context, opt fleet.ListActivitiesOption
 
 type MarkActivitiesAsStreamedFunc func(ctx context.Context, activityIDs []uint) error
 
+type MarkActivitiesAsWebhooksProcessedFunc func(ctx context.Context, activityIDs []uint) error
+
+type NewActivityWebhookDeliveryFunc func(ctx context.Context, delivery *fleet.ActivityWebhookDelivery) (*fleet.ActivityWebhookDelivery, error)
+
+type ActivityWebhookDeliveryFunc func(ctx context.Context, id uint) (*fleet.ActivityWebhookDelivery, error)
+
+type UpdateActivityWebhookDeliveryFunc func(ctx context.Context, delivery *fleet.ActivityWebhookDelivery) error
+
+type UpdateActivityWebhookDeliveryAndJobFunc func(ctx context.Context, delivery *fleet.ActivityWebhookDelivery, job *fleet.Job) error
+
+type ListActivityWebhookDeliveriesFunc func(ctx context.Context, opt fleet.ListActivityWebhookDeliveriesOptions) ([]*fleet.ActivityWebhookDelivery, *fleet.PaginationMetadata, error)
+
 type ShouldSendStatisticsFunc func(ctx context.Context, frequency time.Duration, config config.FleetConfig) (fleet.StatisticsPayload, bool, error)
 
 type RecordStatisticsSentFunc func(ctx context.Context) error
@@ -363,7 +457,7 @@ type PolicyFunc func(ctx context.Context, id uint) (*fleet.Policy, error)
 
 type SavePolicyFunc func(ctx context.Context, p *fleet.Policy) error
 
-type ListGlobalPoliciesFunc func(ctx context.Context) ([]*fleet.Policy, error)
+type ListGlobalPoliciesFunc func(ctx context.Context, opts fleet.PolicyListOptions) ([]*fleet.Policy, error)
 
 type PoliciesByIDFunc func(ctx context.Context, ids []uint) (map[uint]*fleet.Policy, error)
 
@@ -389,14 +483,22 @@ type DeleteSoftwareVulnerabilitiesFunc func(ctx context.Context, vulnerabilities
 
 type NewTeamPolicyFunc func(ctx context.Context, teamID uint, authorID *uint, args fleet.PolicyPayload) (*fleet.Policy, error)
 
-type ListTeamPoliciesFunc func(ctx context.Context, teamID uint) (teamPolicies []*fleet.Policy, inheritedPolicies []*fleet.Policy, err error)
+type ListTeamPoliciesFunc func(ctx context.Context, teamID uint, opts fleet.PolicyListOptions) (teamPolicies []*fleet.Policy, inheritedPolicies []*fleet.Policy, err error)
 
 type DeleteTeamPoliciesFunc func(ctx context.Context, teamID uint, ids []uint) ([]uint, error)
 
 type TeamPolicyFunc func(ctx context.Context, teamID uint, policyID uint) (*fleet.Policy, error)
 
+type ListPolicyComplianceSummariesFunc func(ctx context.Context, teamID *uint) ([]*fleet.PolicyComplianceSummary, error)
+
 type CleanupPolicyMembershipFunc func(ctx context.Context, now time.Time) error
 
+type SnapshotPolicyStatsFunc func(ctx context.Context, now time.Time) error
+
+type CleanupPolicyStatsHistoryFunc func(ctx context.Context, before time.Time) error
+
+type ListPolicyStatsHistoryFunc func(ctx context.Context, opts fleet.PolicyStatsHistoryListOptions) ([]*fleet.PolicyStatsSnapshot, error)
+
 type IncrementPolicyViolationDaysFunc func(ctx context.Context) error
 
 type InitializePolicyViolationDaysFunc func(ctx context.Context) error
@@ -431,6 +533,8 @@ type UpdateHostOsqueryIntervalsFunc func(ctx context.Context, hostID uint, inter
 
 type TeamAgentOptionsFunc func(ctx context.Context, teamID uint) (*json.RawMessage, error)
 
+type TeamUpdateChannelsFunc func(ctx context.Context, teamID uint) (*fleet.UpdateChannels, error)
+
 type TeamFeaturesFunc func(ctx context.Context, teamID uint) (*fleet.Features, error)
 
 type TeamMDMConfigFunc func(ctx context.Context, teamID uint) (*fleet.TeamMDM, error)
@@ -475,6 +579,10 @@ type GetHostDiskEncryptionKeyFunc func(ctx context.Context, hostID uint) (*fleet
 
 type SetOrUpdateHostOrbitInfoFunc func(ctx context.Context, hostID uint, version string) error
 
+type SetOrUpdateHostOrbitChannelsFunc func(ctx context.Context, hostID uint, channels fleet.UpdateChannels) error
+
+type HostOrbitChannelsFunc func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error)
+
 type ReplaceHostDeviceMappingFunc func(ctx context.Context, id uint, mappings []*fleet.HostDeviceMapping) error
 
 type ReplaceHostBatteriesFunc func(ctx context.Context, id uint, mappings []*fleet.HostBattery) error
@@ -553,6 +661,16 @@ type IncreasePolicyAutomationIterationFunc func(ctx context.Context, policyID ui
 
 type OutdatedAutomationBatchFunc func(ctx context.Context) ([]fleet.PolicyFailure, error)
 
+type ListPolicyAutomationTicketsFunc func(ctx context.Context, policyID uint, integrationType string, integrationKey string) ([]*fleet.PolicyAutomationTicket, error)
+
+type UpsertPolicyAutomationTicketsFunc func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error
+
+type ListResolvedPolicyAutomationTicketsFunc func(ctx context.Context) ([]*fleet.PolicyAutomationTicket, error)
+
+type DeletePolicyAutomationTicketsFunc func(ctx context.Context, ids []uint) error
+
+type CountPolicyAutomationTicketHostsFunc func(ctx context.Context, integrationType string, integrationKey string, ticketID string) (int, error)
+
 type ListMDMAppleProfilesToInstallFunc func(ctx context.Context) ([]*fleet.MDMAppleProfilePayload, error)
 
 type ListMDMAppleProfilesToRemoveFunc func(ctx context.Context) ([]*fleet.MDMAppleProfilePayload, error)
@@ -652,6 +770,15 @@ type DataStore struct {
 	ObserverCanRunQueryFunc        ObserverCanRunQueryFunc
 	ObserverCanRunQueryFuncInvoked bool
 
+	QueryStoringResultsByScheduledNameFunc        QueryStoringResultsByScheduledNameFunc
+	QueryStoringResultsByScheduledNameFuncInvoked bool
+
+	OverwriteQueryResultRowsFunc        OverwriteQueryResultRowsFunc
+	OverwriteQueryResultRowsFuncInvoked bool
+
+	QueryReportFunc        QueryReportFunc
+	QueryReportFuncInvoked bool
+
 	NewDistributedQueryCampaignFunc        NewDistributedQueryCampaignFunc
 	NewDistributedQueryCampaignFuncInvoked bool
 
@@ -673,6 +800,15 @@ type DataStore struct {
 	DistributedQueryCampaignsForQueryFunc        DistributedQueryCampaignsForQueryFunc
 	DistributedQueryCampaignsForQueryFuncInvoked bool
 
+	SaveDistributedQueryCampaignResultFunc        SaveDistributedQueryCampaignResultFunc
+	SaveDistributedQueryCampaignResultFuncInvoked bool
+
+	ListDistributedQueryCampaignResultsFunc        ListDistributedQueryCampaignResultsFunc
+	ListDistributedQueryCampaignResultsFuncInvoked bool
+
+	CleanupDistributedQueryCampaignResultsFunc        CleanupDistributedQueryCampaignResultsFunc
+	CleanupDistributedQueryCampaignResultsFuncInvoked bool
+
 	ApplyPackSpecsFunc        ApplyPackSpecsFunc
 	ApplyPackSpecsFuncInvoked bool
 
@@ -763,6 +899,78 @@ type DataStore struct {
 	AsyncBatchUpdateLabelTimestampFunc        AsyncBatchUpdateLabelTimestampFunc
 	AsyncBatchUpdateLabelTimestampFuncInvoked bool
 
+	ApplyLabelMembershipFunc        ApplyLabelMembershipFunc
+	ApplyLabelMembershipFuncInvoked bool
+
+	ListLabelMembershipSyncsFunc        ListLabelMembershipSyncsFunc
+	ListLabelMembershipSyncsFuncInvoked bool
+
+	SetLabelMembershipSyncResultFunc        SetLabelMembershipSyncResultFunc
+	SetLabelMembershipSyncResultFuncInvoked bool
+
+	NewUpdateRolloutFunc        NewUpdateRolloutFunc
+	NewUpdateRolloutFuncInvoked bool
+
+	UpdateRolloutFunc        UpdateRolloutFunc
+	UpdateRolloutFuncInvoked bool
+
+	ListUpdateRolloutsFunc        ListUpdateRolloutsFunc
+	ListUpdateRolloutsFuncInvoked bool
+
+	SaveUpdateRolloutFunc        SaveUpdateRolloutFunc
+	SaveUpdateRolloutFuncInvoked bool
+
+	DeleteUpdateRolloutFunc        DeleteUpdateRolloutFunc
+	DeleteUpdateRolloutFuncInvoked bool
+
+	ListHostUpdateRolloutIDsFunc        ListHostUpdateRolloutIDsFunc
+	ListHostUpdateRolloutIDsFuncInvoked bool
+
+	AddHostUpdateRolloutsFunc        AddHostUpdateRolloutsFunc
+	AddHostUpdateRolloutsFuncInvoked bool
+
+	CountUpdateRolloutHostsFunc        CountUpdateRolloutHostsFunc
+	CountUpdateRolloutHostsFuncInvoked bool
+
+	NewVulnerabilityExceptionFunc        NewVulnerabilityExceptionFunc
+	NewVulnerabilityExceptionFuncInvoked bool
+
+	VulnerabilityExceptionFunc        VulnerabilityExceptionFunc
+	VulnerabilityExceptionFuncInvoked bool
+
+	ListVulnerabilityExceptionsFunc        ListVulnerabilityExceptionsFunc
+	ListVulnerabilityExceptionsFuncInvoked bool
+
+	SaveVulnerabilityExceptionFunc        SaveVulnerabilityExceptionFunc
+	SaveVulnerabilityExceptionFuncInvoked bool
+
+	DeleteVulnerabilityExceptionFunc        DeleteVulnerabilityExceptionFunc
+	DeleteVulnerabilityExceptionFuncInvoked bool
+
+	ApplyCPETranslationRuleSpecsFunc        ApplyCPETranslationRuleSpecsFunc
+	ApplyCPETranslationRuleSpecsFuncInvoked bool
+
+	GetCPETranslationRuleSpecsFunc        GetCPETranslationRuleSpecsFunc
+	GetCPETranslationRuleSpecsFuncInvoked bool
+
+	DeleteCPETranslationRuleFunc        DeleteCPETranslationRuleFunc
+	DeleteCPETranslationRuleFuncInvoked bool
+
+	SyncHostVulnerabilityRemediationsFunc        SyncHostVulnerabilityRemediationsFunc
+	SyncHostVulnerabilityRemediationsFuncInvoked bool
+
+	ListHostVulnerabilityRemediationsFunc        ListHostVulnerabilityRemediationsFunc
+	ListHostVulnerabilityRemediationsFuncInvoked bool
+
+	VulnerabilityRemediationStatsFunc        VulnerabilityRemediationStatsFunc
+	VulnerabilityRemediationStatsFuncInvoked bool
+
+	ListUnnotifiedVulnerabilitySLABreachesFunc        ListUnnotifiedVulnerabilitySLABreachesFunc
+	ListUnnotifiedVulnerabilitySLABreachesFuncInvoked bool
+
+	MarkVulnerabilitySLABreachesNotifiedFunc        MarkVulnerabilitySLABreachesNotifiedFunc
+	MarkVulnerabilitySLABreachesNotifiedFuncInvoked bool
+
 	NewHostFunc        NewHostFunc
 	NewHostFuncInvoked bool
 
@@ -970,6 +1178,18 @@ type DataStore struct {
 	CleanupExpiredHostsFunc        CleanupExpiredHostsFunc
 	CleanupExpiredHostsFuncInvoked bool
 
+	ListOfflineHostsFunc        ListOfflineHostsFunc
+	ListOfflineHostsFuncInvoked bool
+
+	ListHostsForTeamAssignmentFunc        ListHostsForTeamAssignmentFunc
+	ListHostsForTeamAssignmentFuncInvoked bool
+
+	SetHostsLifecycleStateFunc        SetHostsLifecycleStateFunc
+	SetHostsLifecycleStateFuncInvoked bool
+
+	CleanupHostLifecycleStatesFunc        CleanupHostLifecycleStatesFunc
+	CleanupHostLifecycleStatesFuncInvoked bool
+
 	ScheduledQueryIDsByNameFunc        ScheduledQueryIDsByNameFunc
 	ScheduledQueryIDsByNameFuncInvoked bool
 
@@ -994,6 +1214,9 @@ type DataStore struct {
 	TeamsSummaryFunc        TeamsSummaryFunc
 	TeamsSummaryFuncInvoked bool
 
+	TeamHierarchyFunc        TeamHierarchyFunc
+	TeamHierarchyFuncInvoked bool
+
 	SearchTeamsFunc        SearchTeamsFunc
 	SearchTeamsFuncInvoked bool
 
@@ -1003,12 +1226,24 @@ type DataStore struct {
 	DeleteIntegrationsFromTeamsFunc        DeleteIntegrationsFromTeamsFunc
 	DeleteIntegrationsFromTeamsFuncInvoked bool
 
+	ListTeamScheduledQueryOverridesFunc        ListTeamScheduledQueryOverridesFunc
+	ListTeamScheduledQueryOverridesFuncInvoked bool
+
+	ReplaceTeamScheduledQueryOverridesFunc        ReplaceTeamScheduledQueryOverridesFunc
+	ReplaceTeamScheduledQueryOverridesFuncInvoked bool
+
 	ListSoftwareForVulnDetectionFunc        ListSoftwareForVulnDetectionFunc
 	ListSoftwareForVulnDetectionFuncInvoked bool
 
 	ListSoftwareVulnerabilitiesByHostIDsSourceFunc        ListSoftwareVulnerabilitiesByHostIDsSourceFunc
 	ListSoftwareVulnerabilitiesByHostIDsSourceFuncInvoked bool
 
+	ListSoftwareVulnerabilitiesBySourceFunc        ListSoftwareVulnerabilitiesBySourceFunc
+	ListSoftwareVulnerabilitiesBySourceFuncInvoked bool
+
+	ListSoftwareVulnerabilitiesBySoftwareIDFunc        ListSoftwareVulnerabilitiesBySoftwareIDFunc
+	ListSoftwareVulnerabilitiesBySoftwareIDFuncInvoked bool
+
 	LoadHostSoftwareFunc        LoadHostSoftwareFunc
 	LoadHostSoftwareFuncInvoked bool
 
@@ -1024,6 +1259,12 @@ type DataStore struct {
 	ListSoftwareCPEsFunc        ListSoftwareCPEsFunc
 	ListSoftwareCPEsFuncInvoked bool
 
+	AllSoftwareWithCPEIteratorFunc        AllSoftwareWithCPEIteratorFunc
+	AllSoftwareWithCPEIteratorFuncInvoked bool
+
+	DeleteSoftwareCPEsFunc        DeleteSoftwareCPEsFunc
+	DeleteSoftwareCPEsFuncInvoked bool
+
 	InsertSoftwareVulnerabilitiesFunc        InsertSoftwareVulnerabilitiesFunc
 	InsertSoftwareVulnerabilitiesFuncInvoked bool
 
@@ -1036,8 +1277,8 @@ type DataStore struct {
 	SyncHostsSoftwareFunc        SyncHostsSoftwareFunc
 	SyncHostsSoftwareFuncInvoked bool
 
-	HostsBySoftwareIDsFunc        HostsBySoftwareIDsFunc
-	HostsBySoftwareIDsFuncInvoked bool
+	VulnerableHostsBySoftwareIDsFunc        VulnerableHostsBySoftwareIDsFunc
+	VulnerableHostsBySoftwareIDsFuncInvoked bool
 
 	HostsByCVEFunc        HostsByCVEFunc
 	HostsByCVEFuncInvoked bool
@@ -1069,6 +1310,24 @@ type DataStore struct {
 	MarkActivitiesAsStreamedFunc        MarkActivitiesAsStreamedFunc
 	MarkActivitiesAsStreamedFuncInvoked bool
 
+	MarkActivitiesAsWebhooksProcessedFunc        MarkActivitiesAsWebhooksProcessedFunc
+	MarkActivitiesAsWebhooksProcessedFuncInvoked bool
+
+	NewActivityWebhookDeliveryFunc        NewActivityWebhookDeliveryFunc
+	NewActivityWebhookDeliveryFuncInvoked bool
+
+	ActivityWebhookDeliveryFunc        ActivityWebhookDeliveryFunc
+	ActivityWebhookDeliveryFuncInvoked bool
+
+	UpdateActivityWebhookDeliveryFunc        UpdateActivityWebhookDeliveryFunc
+	UpdateActivityWebhookDeliveryFuncInvoked bool
+
+	UpdateActivityWebhookDeliveryAndJobFunc        UpdateActivityWebhookDeliveryAndJobFunc
+	UpdateActivityWebhookDeliveryAndJobFuncInvoked bool
+
+	ListActivityWebhookDeliveriesFunc        ListActivityWebhookDeliveriesFunc
+	ListActivityWebhookDeliveriesFuncInvoked bool
+
 	ShouldSendStatisticsFunc        ShouldSendStatisticsFunc
 	ShouldSendStatisticsFuncInvoked bool
 
@@ -1138,9 +1397,21 @@ type DataStore struct {
 	TeamPolicyFunc        TeamPolicyFunc
 	TeamPolicyFuncInvoked bool
 
+	ListPolicyComplianceSummariesFunc        ListPolicyComplianceSummariesFunc
+	ListPolicyComplianceSummariesFuncInvoked bool
+
 	CleanupPolicyMembershipFunc        CleanupPolicyMembershipFunc
 	CleanupPolicyMembershipFuncInvoked bool
 
+	SnapshotPolicyStatsFunc        SnapshotPolicyStatsFunc
+	SnapshotPolicyStatsFuncInvoked bool
+
+	CleanupPolicyStatsHistoryFunc        CleanupPolicyStatsHistoryFunc
+	CleanupPolicyStatsHistoryFuncInvoked bool
+
+	ListPolicyStatsHistoryFunc        ListPolicyStatsHistoryFunc
+	ListPolicyStatsHistoryFuncInvoked bool
+
 	IncrementPolicyViolationDaysFunc        IncrementPolicyViolationDaysFunc
 	IncrementPolicyViolationDaysFuncInvoked bool
 
@@ -1192,6 +1463,9 @@ type DataStore struct {
 	TeamAgentOptionsFunc        TeamAgentOptionsFunc
 	TeamAgentOptionsFuncInvoked bool
 
+	TeamUpdateChannelsFunc        TeamUpdateChannelsFunc
+	TeamUpdateChannelsFuncInvoked bool
+
 	TeamFeaturesFunc        TeamFeaturesFunc
 	TeamFeaturesFuncInvoked bool
 
@@ -1258,6 +1532,12 @@ type DataStore struct {
 	SetOrUpdateHostOrbitInfoFunc        SetOrUpdateHostOrbitInfoFunc
 	SetOrUpdateHostOrbitInfoFuncInvoked bool
 
+	SetOrUpdateHostOrbitChannelsFunc        SetOrUpdateHostOrbitChannelsFunc
+	SetOrUpdateHostOrbitChannelsFuncInvoked bool
+
+	HostOrbitChannelsFunc        HostOrbitChannelsFunc
+	HostOrbitChannelsFuncInvoked bool
+
 	ReplaceHostDeviceMappingFunc        ReplaceHostDeviceMappingFunc
 	ReplaceHostDeviceMappingFuncInvoked bool
 
@@ -1375,6 +1655,21 @@ type DataStore struct {
 	OutdatedAutomationBatchFunc        OutdatedAutomationBatchFunc
 	OutdatedAutomationBatchFuncInvoked bool
 
+	ListPolicyAutomationTicketsFunc        ListPolicyAutomationTicketsFunc
+	ListPolicyAutomationTicketsFuncInvoked bool
+
+	UpsertPolicyAutomationTicketsFunc        UpsertPolicyAutomationTicketsFunc
+	UpsertPolicyAutomationTicketsFuncInvoked bool
+
+	ListResolvedPolicyAutomationTicketsFunc        ListResolvedPolicyAutomationTicketsFunc
+	ListResolvedPolicyAutomationTicketsFuncInvoked bool
+
+	DeletePolicyAutomationTicketsFunc        DeletePolicyAutomationTicketsFunc
+	DeletePolicyAutomationTicketsFuncInvoked bool
+
+	CountPolicyAutomationTicketHostsFunc        CountPolicyAutomationTicketHostsFunc
+	CountPolicyAutomationTicketHostsFuncInvoked bool
+
 	ListMDMAppleProfilesToInstallFunc        ListMDMAppleProfilesToInstallFunc
 	ListMDMAppleProfilesToInstallFuncInvoked bool
 
@@ -1595,6 +1890,27 @@ func (s *DataStore) ObserverCanRunQuery(ctx context.Context, queryID uint) (bool
 	return s.ObserverCanRunQueryFunc(ctx, queryID)
 }
 
+func (s *DataStore) QueryStoringResultsByScheduledName(ctx context.Context, packName string, scheduledQueryName string) (*fleet.Query, error) {
+	s.mu.Lock()
+	s.QueryStoringResultsByScheduledNameFuncInvoked = true
+	s.mu.Unlock()
+	return s.QueryStoringResultsByScheduledNameFunc(ctx, packName, scheduledQueryName)
+}
+
+func (s *DataStore) OverwriteQueryResultRows(ctx context.Context, queryID uint, hostID uint, rows []json.RawMessage, fetchedAt time.Time, maxRows int) error {
+	s.mu.Lock()
+	s.OverwriteQueryResultRowsFuncInvoked = true
+	s.mu.Unlock()
+	return s.OverwriteQueryResultRowsFunc(ctx, queryID, hostID, rows, fetchedAt, maxRows)
+}
+
+func (s *DataStore) QueryReport(ctx context.Context, queryID uint, filter fleet.TeamFilter, opts fleet.QueryReportListOptions) ([]*fleet.QueryReportRow, error) {
+	s.mu.Lock()
+	s.QueryReportFuncInvoked = true
+	s.mu.Unlock()
+	return s.QueryReportFunc(ctx, queryID, filter, opts)
+}
+
 func (s *DataStore) NewDistributedQueryCampaign(ctx context.Context, camp *fleet.DistributedQueryCampaign) (*fleet.DistributedQueryCampaign, error) {
 	s.mu.Lock()
 	s.NewDistributedQueryCampaignFuncInvoked = true
@@ -1644,6 +1960,27 @@ func (s *DataStore) DistributedQueryCampaignsForQuery(ctx context.Context, query
 	return s.DistributedQueryCampaignsForQueryFunc(ctx, queryID)
 }
 
+func (s *DataStore) SaveDistributedQueryCampaignResult(ctx context.Context, result *fleet.DistributedQueryResult) error {
+	s.mu.Lock()
+	s.SaveDistributedQueryCampaignResultFuncInvoked = true
+	s.mu.Unlock()
+	return s.SaveDistributedQueryCampaignResultFunc(ctx, result)
+}
+
+func (s *DataStore) ListDistributedQueryCampaignResults(ctx context.Context, campaignID uint, opt fleet.ListOptions) ([]*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListDistributedQueryCampaignResultsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListDistributedQueryCampaignResultsFunc(ctx, campaignID, opt)
+}
+
+func (s *DataStore) CleanupDistributedQueryCampaignResults(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	s.CleanupDistributedQueryCampaignResultsFuncInvoked = true
+	s.mu.Unlock()
+	return s.CleanupDistributedQueryCampaignResultsFunc(ctx, before)
+}
+
 func (s *DataStore) ApplyPackSpecs(ctx context.Context, specs []*fleet.PackSpec) error {
 	s.mu.Lock()
 	s.ApplyPackSpecsFuncInvoked = true
@@ -1854,6 +2191,174 @@ func (s *DataStore) AsyncBatchUpdateLabelTimestamp(ctx context.Context, ids []ui
 	return s.AsyncBatchUpdateLabelTimestampFunc(ctx, ids, ts)
 }
 
+func (s *DataStore) ApplyLabelMembership(ctx context.Context, labelID uint, identifiers []string, dryRun bool) (*fleet.LabelMembershipDiff, error) {
+	s.mu.Lock()
+	s.ApplyLabelMembershipFuncInvoked = true
+	s.mu.Unlock()
+	return s.ApplyLabelMembershipFunc(ctx, labelID, identifiers, dryRun)
+}
+
+func (s *DataStore) ListLabelMembershipSyncs(ctx context.Context) ([]*fleet.LabelMembershipSync, error) {
+	s.mu.Lock()
+	s.ListLabelMembershipSyncsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListLabelMembershipSyncsFunc(ctx)
+}
+
+func (s *DataStore) SetLabelMembershipSyncResult(ctx context.Context, labelID uint, syncedAt time.Time, syncErr string) error {
+	s.mu.Lock()
+	s.SetLabelMembershipSyncResultFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetLabelMembershipSyncResultFunc(ctx, labelID, syncedAt, syncErr)
+}
+
+func (s *DataStore) NewUpdateRollout(ctx context.Context, rollout *fleet.UpdateRollout) (*fleet.UpdateRollout, error) {
+	s.mu.Lock()
+	s.NewUpdateRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewUpdateRolloutFunc(ctx, rollout)
+}
+
+func (s *DataStore) UpdateRollout(ctx context.Context, id uint) (*fleet.UpdateRollout, error) {
+	s.mu.Lock()
+	s.UpdateRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpdateRolloutFunc(ctx, id)
+}
+
+func (s *DataStore) ListUpdateRollouts(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+	s.mu.Lock()
+	s.ListUpdateRolloutsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListUpdateRolloutsFunc(ctx)
+}
+
+func (s *DataStore) SaveUpdateRollout(ctx context.Context, rollout *fleet.UpdateRollout) error {
+	s.mu.Lock()
+	s.SaveUpdateRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.SaveUpdateRolloutFunc(ctx, rollout)
+}
+
+func (s *DataStore) DeleteUpdateRollout(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	s.DeleteUpdateRolloutFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteUpdateRolloutFunc(ctx, id)
+}
+
+func (s *DataStore) ListHostUpdateRolloutIDs(ctx context.Context, hostID uint) ([]uint, error) {
+	s.mu.Lock()
+	s.ListHostUpdateRolloutIDsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListHostUpdateRolloutIDsFunc(ctx, hostID)
+}
+
+func (s *DataStore) AddHostUpdateRollouts(ctx context.Context, hostID uint, rolloutIDs []uint, pinnedAt time.Time) error {
+	s.mu.Lock()
+	s.AddHostUpdateRolloutsFuncInvoked = true
+	s.mu.Unlock()
+	return s.AddHostUpdateRolloutsFunc(ctx, hostID, rolloutIDs, pinnedAt)
+}
+
+func (s *DataStore) CountUpdateRolloutHosts(ctx context.Context, rolloutID uint, offlineSince time.Time) (hosts uint, offline uint, err error) {
+	s.mu.Lock()
+	s.CountUpdateRolloutHostsFuncInvoked = true
+	s.mu.Unlock()
+	return s.CountUpdateRolloutHostsFunc(ctx, rolloutID, offlineSince)
+}
+
+func (s *DataStore) NewVulnerabilityException(ctx context.Context, exception *fleet.VulnerabilityException) (*fleet.VulnerabilityException, error) {
+	s.mu.Lock()
+	s.NewVulnerabilityExceptionFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewVulnerabilityExceptionFunc(ctx, exception)
+}
+
+func (s *DataStore) VulnerabilityException(ctx context.Context, id uint) (*fleet.VulnerabilityException, error) {
+	s.mu.Lock()
+	s.VulnerabilityExceptionFuncInvoked = true
+	s.mu.Unlock()
+	return s.VulnerabilityExceptionFunc(ctx, id)
+}
+
+func (s *DataStore) ListVulnerabilityExceptions(ctx context.Context, opts fleet.VulnerabilityExceptionListOptions) ([]*fleet.VulnerabilityException, error) {
+	s.mu.Lock()
+	s.ListVulnerabilityExceptionsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListVulnerabilityExceptionsFunc(ctx, opts)
+}
+
+func (s *DataStore) SaveVulnerabilityException(ctx context.Context, exception *fleet.VulnerabilityException) error {
+	s.mu.Lock()
+	s.SaveVulnerabilityExceptionFuncInvoked = true
+	s.mu.Unlock()
+	return s.SaveVulnerabilityExceptionFunc(ctx, exception)
+}
+
+func (s *DataStore) DeleteVulnerabilityException(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	s.DeleteVulnerabilityExceptionFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteVulnerabilityExceptionFunc(ctx, id)
+}
+
+func (s *DataStore) ApplyCPETranslationRuleSpecs(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error {
+	s.mu.Lock()
+	s.ApplyCPETranslationRuleSpecsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ApplyCPETranslationRuleSpecsFunc(ctx, specs)
+}
+
+func (s *DataStore) GetCPETranslationRuleSpecs(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) {
+	s.mu.Lock()
+	s.GetCPETranslationRuleSpecsFuncInvoked = true
+	s.mu.Unlock()
+	return s.GetCPETranslationRuleSpecsFunc(ctx)
+}
+
+func (s *DataStore) DeleteCPETranslationRule(ctx context.Context, name string) error {
+	s.mu.Lock()
+	s.DeleteCPETranslationRuleFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteCPETranslationRuleFunc(ctx, name)
+}
+
+func (s *DataStore) SyncHostVulnerabilityRemediations(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	s.SyncHostVulnerabilityRemediationsFuncInvoked = true
+	s.mu.Unlock()
+	return s.SyncHostVulnerabilityRemediationsFunc(ctx, now)
+}
+
+func (s *DataStore) ListHostVulnerabilityRemediations(ctx context.Context, opts fleet.VulnerabilityRemediationListOptions, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, *fleet.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListHostVulnerabilityRemediationsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListHostVulnerabilityRemediationsFunc(ctx, opts, sla, now)
+}
+
+func (s *DataStore) VulnerabilityRemediationStats(ctx context.Context, teamID *uint, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.VulnerabilityRemediationStats, error) {
+	s.mu.Lock()
+	s.VulnerabilityRemediationStatsFuncInvoked = true
+	s.mu.Unlock()
+	return s.VulnerabilityRemediationStatsFunc(ctx, teamID, sla, now)
+}
+
+func (s *DataStore) ListUnnotifiedVulnerabilitySLABreaches(ctx context.Context, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, error) {
+	s.mu.Lock()
+	s.ListUnnotifiedVulnerabilitySLABreachesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListUnnotifiedVulnerabilitySLABreachesFunc(ctx, sla, now)
+}
+
+func (s *DataStore) MarkVulnerabilitySLABreachesNotified(ctx context.Context, ids []uint, now time.Time) error {
+	s.mu.Lock()
+	s.MarkVulnerabilitySLABreachesNotifiedFuncInvoked = true
+	s.mu.Unlock()
+	return s.MarkVulnerabilitySLABreachesNotifiedFunc(ctx, ids, now)
+}
+
 func (s *DataStore) NewHost(ctx context.Context, host *fleet.Host) (*fleet.Host, error) {
 	s.mu.Lock()
 	s.NewHostFuncInvoked = true
@@ -2337,6 +2842,34 @@ func (s *DataStore) CleanupExpiredHosts(ctx context.Context) ([]uint, error) {
 	return s.CleanupExpiredHostsFunc(ctx)
 }
 
+func (s *DataStore) ListOfflineHosts(ctx context.Context, teamID uint, seenBefore time.Time) ([]*fleet.OfflineHost, error) {
+	s.mu.Lock()
+	s.ListOfflineHostsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListOfflineHostsFunc(ctx, teamID, seenBefore)
+}
+
+func (s *DataStore) ListHostsForTeamAssignment(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error) {
+	s.mu.Lock()
+	s.ListHostsForTeamAssignmentFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListHostsForTeamAssignmentFunc(ctx, hostIDs, labelNames, withEmails)
+}
+
+func (s *DataStore) SetHostsLifecycleState(ctx context.Context, hostIDs []uint, state fleet.HostLifecycleState) error {
+	s.mu.Lock()
+	s.SetHostsLifecycleStateFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetHostsLifecycleStateFunc(ctx, hostIDs, state)
+}
+
+func (s *DataStore) CleanupHostLifecycleStates(ctx context.Context) error {
+	s.mu.Lock()
+	s.CleanupHostLifecycleStatesFuncInvoked = true
+	s.mu.Unlock()
+	return s.CleanupHostLifecycleStatesFunc(ctx)
+}
+
 func (s *DataStore) ScheduledQueryIDsByName(ctx context.Context, batchSize int, packAndSchedQueryNames ...[2]string) ([]uint, error) {
 	s.mu.Lock()
 	s.ScheduledQueryIDsByNameFuncInvoked = true
@@ -2393,6 +2926,13 @@ func (s *DataStore) TeamsSummary(ctx context.Context) ([]*fleet.TeamSummary, err
 	return s.TeamsSummaryFunc(ctx)
 }
 
+func (s *DataStore) TeamHierarchy(ctx context.Context) (fleet.TeamHierarchy, error) {
+	s.mu.Lock()
+	s.TeamHierarchyFuncInvoked = true
+	s.mu.Unlock()
+	return s.TeamHierarchyFunc(ctx)
+}
+
 func (s *DataStore) SearchTeams(ctx context.Context, filter fleet.TeamFilter, matchQuery string, omit ...uint) ([]*fleet.Team, error) {
 	s.mu.Lock()
 	s.SearchTeamsFuncInvoked = true
@@ -2414,6 +2954,20 @@ func (s *DataStore) DeleteIntegrationsFromTeams(ctx context.Context, deletedIntg
 	return s.DeleteIntegrationsFromTeamsFunc(ctx, deletedIntgs)
 }
 
+func (s *DataStore) ListTeamScheduledQueryOverrides(ctx context.Context, teamID uint) (fleet.TeamScheduledQueryOverrideList, error) {
+	s.mu.Lock()
+	s.ListTeamScheduledQueryOverridesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListTeamScheduledQueryOverridesFunc(ctx, teamID)
+}
+
+func (s *DataStore) ReplaceTeamScheduledQueryOverrides(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error {
+	s.mu.Lock()
+	s.ReplaceTeamScheduledQueryOverridesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ReplaceTeamScheduledQueryOverridesFunc(ctx, teamID, overrides)
+}
+
 func (s *DataStore) ListSoftwareForVulnDetection(ctx context.Context, hostID uint) ([]fleet.Software, error) {
 	s.mu.Lock()
 	s.ListSoftwareForVulnDetectionFuncInvoked = true
@@ -2428,6 +2982,20 @@ func (s *DataStore) ListSoftwareVulnerabilitiesByHostIDsSource(ctx context.Conte
 	return s.ListSoftwareVulnerabilitiesByHostIDsSourceFunc(ctx, hostIDs, source)
 }
 
+func (s *DataStore) ListSoftwareVulnerabilitiesBySource(ctx context.Context, source fleet.VulnerabilitySource) ([]fleet.SoftwareVulnerability, error) {
+	s.mu.Lock()
+	s.ListSoftwareVulnerabilitiesBySourceFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareVulnerabilitiesBySourceFunc(ctx, source)
+}
+
+func (s *DataStore) ListSoftwareVulnerabilitiesBySoftwareID(ctx context.Context, softwareID uint) ([]fleet.SoftwareVulnerability, error) {
+	s.mu.Lock()
+	s.ListSoftwareVulnerabilitiesBySoftwareIDFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListSoftwareVulnerabilitiesBySoftwareIDFunc(ctx, softwareID)
+}
+
 func (s *DataStore) LoadHostSoftware(ctx context.Context, host *fleet.Host, includeCVEScores bool) error {
 	s.mu.Lock()
 	s.LoadHostSoftwareFuncInvoked = true
@@ -2463,6 +3031,20 @@ func (s *DataStore) ListSoftwareCPEs(ctx context.Context) ([]fleet.SoftwareCPE,
 	return s.ListSoftwareCPEsFunc(ctx)
 }
 
+func (s *DataStore) AllSoftwareWithCPEIterator(ctx context.Context, excludedSources []string) (fleet.SoftwareIterator, error) {
+	s.mu.Lock()
+	s.AllSoftwareWithCPEIteratorFuncInvoked = true
+	s.mu.Unlock()
+	return s.AllSoftwareWithCPEIteratorFunc(ctx, excludedSources)
+}
+
+func (s *DataStore) DeleteSoftwareCPEs(ctx context.Context, softwareIDs []uint) error {
+	s.mu.Lock()
+	s.DeleteSoftwareCPEsFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeleteSoftwareCPEsFunc(ctx, softwareIDs)
+}
+
 func (s *DataStore) InsertSoftwareVulnerabilities(ctx context.Context, vulns []fleet.SoftwareVulnerability, source fleet.VulnerabilitySource) (int64, error) {
 	s.mu.Lock()
 	s.InsertSoftwareVulnerabilitiesFuncInvoked = true
@@ -2491,11 +3073,11 @@ func (s *DataStore) SyncHostsSoftware(ctx context.Context, updatedAt time.Time)
 	return s.SyncHostsSoftwareFunc(ctx, updatedAt)
 }
 
-func (s *DataStore) HostsBySoftwareIDs(ctx context.Context, softwareIDs []uint) ([]*fleet.HostShort, error) {
+func (s *DataStore) VulnerableHostsBySoftwareIDs(ctx context.Context, softwareIDs []uint, cve string) ([]*fleet.HostShort, error) {
 	s.mu.Lock()
-	s.HostsBySoftwareIDsFuncInvoked = true
+	s.VulnerableHostsBySoftwareIDsFuncInvoked = true
 	s.mu.Unlock()
-	return s.HostsBySoftwareIDsFunc(ctx, softwareIDs)
+	return s.VulnerableHostsBySoftwareIDsFunc(ctx, softwareIDs, cve)
 }
 
 func (s *DataStore) HostsByCVE(ctx context.Context, cve string) ([]*fleet.HostShort, error) {
@@ -2568,6 +3150,48 @@ func (s *DataStore) MarkActivitiesAsStreamed(ctx context.Context, activityIDs []
 	return s.MarkActivitiesAsStreamedFunc(ctx, activityIDs)
 }
 
+func (s *DataStore) MarkActivitiesAsWebhooksProcessed(ctx context.Context, activityIDs []uint) error {
+	s.mu.Lock()
+	s.MarkActivitiesAsWebhooksProcessedFuncInvoked = true
+	s.mu.Unlock()
+	return s.MarkActivitiesAsWebhooksProcessedFunc(ctx, activityIDs)
+}
+
+func (s *DataStore) NewActivityWebhookDelivery(ctx context.Context, delivery *fleet.ActivityWebhookDelivery) (*fleet.ActivityWebhookDelivery, error) {
+	s.mu.Lock()
+	s.NewActivityWebhookDeliveryFuncInvoked = true
+	s.mu.Unlock()
+	return s.NewActivityWebhookDeliveryFunc(ctx, delivery)
+}
+
+func (s *DataStore) ActivityWebhookDelivery(ctx context.Context, id uint) (*fleet.ActivityWebhookDelivery, error) {
+	s.mu.Lock()
+	s.ActivityWebhookDeliveryFuncInvoked = true
+	s.mu.Unlock()
+	return s.ActivityWebhookDeliveryFunc(ctx, id)
+}
+
+func (s *DataStore) UpdateActivityWebhookDelivery(ctx context.Context, delivery *fleet.ActivityWebhookDelivery) error {
+	s.mu.Lock()
+	s.UpdateActivityWebhookDeliveryFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpdateActivityWebhookDeliveryFunc(ctx, delivery)
+}
+
+func (s *DataStore) UpdateActivityWebhookDeliveryAndJob(ctx context.Context, delivery *fleet.ActivityWebhookDelivery, job *fleet.Job) error {
+	s.mu.Lock()
+	s.UpdateActivityWebhookDeliveryAndJobFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpdateActivityWebhookDeliveryAndJobFunc(ctx, delivery, job)
+}
+
+func (s *DataStore) ListActivityWebhookDeliveries(ctx context.Context, opt fleet.ListActivityWebhookDeliveriesOptions) ([]*fleet.ActivityWebhookDelivery, *fleet.PaginationMetadata, error) {
+	s.mu.Lock()
+	s.ListActivityWebhookDeliveriesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListActivityWebhookDeliveriesFunc(ctx, opt)
+}
+
 func (s *DataStore) ShouldSendStatistics(ctx context.Context, frequency time.Duration, config config.FleetConfig) (fleet.StatisticsPayload, bool, error) {
 	s.mu.Lock()
 	s.ShouldSendStatisticsFuncInvoked = true
@@ -2617,11 +3241,11 @@ func (s *DataStore) SavePolicy(ctx context.Context, p *fleet.Policy) error {
 	return s.SavePolicyFunc(ctx, p)
 }
 
-func (s *DataStore) ListGlobalPolicies(ctx context.Context) ([]*fleet.Policy, error) {
+func (s *DataStore) ListGlobalPolicies(ctx context.Context, opts fleet.PolicyListOptions) ([]*fleet.Policy, error) {
 	s.mu.Lock()
 	s.ListGlobalPoliciesFuncInvoked = true
 	s.mu.Unlock()
-	return s.ListGlobalPoliciesFunc(ctx)
+	return s.ListGlobalPoliciesFunc(ctx, opts)
 }
 
 func (s *DataStore) PoliciesByID(ctx context.Context, ids []uint) (map[uint]*fleet.Policy, error) {
@@ -2708,11 +3332,11 @@ func (s *DataStore) NewTeamPolicy(ctx context.Context, teamID uint, authorID *ui
 	return s.NewTeamPolicyFunc(ctx, teamID, authorID, args)
 }
 
-func (s *DataStore) ListTeamPolicies(ctx context.Context, teamID uint) (teamPolicies []*fleet.Policy, inheritedPolicies []*fleet.Policy, err error) {
+func (s *DataStore) ListTeamPolicies(ctx context.Context, teamID uint, opts fleet.PolicyListOptions) (teamPolicies []*fleet.Policy, inheritedPolicies []*fleet.Policy, err error) {
 	s.mu.Lock()
 	s.ListTeamPoliciesFuncInvoked = true
 	s.mu.Unlock()
-	return s.ListTeamPoliciesFunc(ctx, teamID)
+	return s.ListTeamPoliciesFunc(ctx, teamID, opts)
 }
 
 func (s *DataStore) DeleteTeamPolicies(ctx context.Context, teamID uint, ids []uint) ([]uint, error) {
@@ -2729,6 +3353,13 @@ func (s *DataStore) TeamPolicy(ctx context.Context, teamID uint, policyID uint)
 	return s.TeamPolicyFunc(ctx, teamID, policyID)
 }
 
+func (s *DataStore) ListPolicyComplianceSummaries(ctx context.Context, teamID *uint) ([]*fleet.PolicyComplianceSummary, error) {
+	s.mu.Lock()
+	s.ListPolicyComplianceSummariesFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListPolicyComplianceSummariesFunc(ctx, teamID)
+}
+
 func (s *DataStore) CleanupPolicyMembership(ctx context.Context, now time.Time) error {
 	s.mu.Lock()
 	s.CleanupPolicyMembershipFuncInvoked = true
@@ -2736,6 +3367,27 @@ func (s *DataStore) CleanupPolicyMembership(ctx context.Context, now time.Time)
 	return s.CleanupPolicyMembershipFunc(ctx, now)
 }
 
+func (s *DataStore) SnapshotPolicyStats(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	s.SnapshotPolicyStatsFuncInvoked = true
+	s.mu.Unlock()
+	return s.SnapshotPolicyStatsFunc(ctx, now)
+}
+
+func (s *DataStore) CleanupPolicyStatsHistory(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	s.CleanupPolicyStatsHistoryFuncInvoked = true
+	s.mu.Unlock()
+	return s.CleanupPolicyStatsHistoryFunc(ctx, before)
+}
+
+func (s *DataStore) ListPolicyStatsHistory(ctx context.Context, opts fleet.PolicyStatsHistoryListOptions) ([]*fleet.PolicyStatsSnapshot, error) {
+	s.mu.Lock()
+	s.ListPolicyStatsHistoryFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListPolicyStatsHistoryFunc(ctx, opts)
+}
+
 func (s *DataStore) IncrementPolicyViolationDays(ctx context.Context) error {
 	s.mu.Lock()
 	s.IncrementPolicyViolationDaysFuncInvoked = true
@@ -2855,6 +3507,13 @@ func (s *DataStore) TeamAgentOptions(ctx context.Context, teamID uint) (*json.Ra
 	return s.TeamAgentOptionsFunc(ctx, teamID)
 }
 
+func (s *DataStore) TeamUpdateChannels(ctx context.Context, teamID uint) (*fleet.UpdateChannels, error) {
+	s.mu.Lock()
+	s.TeamUpdateChannelsFuncInvoked = true
+	s.mu.Unlock()
+	return s.TeamUpdateChannelsFunc(ctx, teamID)
+}
+
 func (s *DataStore) TeamFeatures(ctx context.Context, teamID uint) (*fleet.Features, error) {
 	s.mu.Lock()
 	s.TeamFeaturesFuncInvoked = true
@@ -3009,6 +3668,20 @@ func (s *DataStore) SetOrUpdateHostOrbitInfo(ctx context.Context, hostID uint, v
 	return s.SetOrUpdateHostOrbitInfoFunc(ctx, hostID, version)
 }
 
+func (s *DataStore) SetOrUpdateHostOrbitChannels(ctx context.Context, hostID uint, channels fleet.UpdateChannels) error {
+	s.mu.Lock()
+	s.SetOrUpdateHostOrbitChannelsFuncInvoked = true
+	s.mu.Unlock()
+	return s.SetOrUpdateHostOrbitChannelsFunc(ctx, hostID, channels)
+}
+
+func (s *DataStore) HostOrbitChannels(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+	s.mu.Lock()
+	s.HostOrbitChannelsFuncInvoked = true
+	s.mu.Unlock()
+	return s.HostOrbitChannelsFunc(ctx, hostID)
+}
+
 func (s *DataStore) ReplaceHostDeviceMapping(ctx context.Context, id uint, mappings []*fleet.HostDeviceMapping) error {
 	s.mu.Lock()
 	s.ReplaceHostDeviceMappingFuncInvoked = true
@@ -3282,6 +3955,41 @@ func (s *DataStore) OutdatedAutomationBatch(ctx context.Context) ([]fleet.Policy
 	return s.OutdatedAutomationBatchFunc(ctx)
 }
 
+func (s *DataStore) ListPolicyAutomationTickets(ctx context.Context, policyID uint, integrationType string, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+	s.mu.Lock()
+	s.ListPolicyAutomationTicketsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListPolicyAutomationTicketsFunc(ctx, policyID, integrationType, integrationKey)
+}
+
+func (s *DataStore) UpsertPolicyAutomationTickets(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+	s.mu.Lock()
+	s.UpsertPolicyAutomationTicketsFuncInvoked = true
+	s.mu.Unlock()
+	return s.UpsertPolicyAutomationTicketsFunc(ctx, tickets)
+}
+
+func (s *DataStore) ListResolvedPolicyAutomationTickets(ctx context.Context) ([]*fleet.PolicyAutomationTicket, error) {
+	s.mu.Lock()
+	s.ListResolvedPolicyAutomationTicketsFuncInvoked = true
+	s.mu.Unlock()
+	return s.ListResolvedPolicyAutomationTicketsFunc(ctx)
+}
+
+func (s *DataStore) DeletePolicyAutomationTickets(ctx context.Context, ids []uint) error {
+	s.mu.Lock()
+	s.DeletePolicyAutomationTicketsFuncInvoked = true
+	s.mu.Unlock()
+	return s.DeletePolicyAutomationTicketsFunc(ctx, ids)
+}
+
+func (s *DataStore) CountPolicyAutomationTicketHosts(ctx context.Context, integrationType string, integrationKey string, ticketID string) (int, error) {
+	s.mu.Lock()
+	s.CountPolicyAutomationTicketHostsFuncInvoked = true
+	s.mu.Unlock()
+	return s.CountPolicyAutomationTicketHostsFunc(ctx, integrationType, integrationKey, ticketID)
+}
+
 func (s *DataStore) ListMDMAppleProfilesToInstall(ctx context.Context) ([]*fleet.MDMAppleProfilePayload, error) {
 	s.mu.Lock()
 	s.ListMDMAppleProfilesToInstallFuncInvoked = true
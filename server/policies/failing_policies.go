@@ -19,9 +19,12 @@ type FailingPolicyAutomationType string
 
 // List of supported failing policy automation types.
 const (
-	FailingPolicyWebhook FailingPolicyAutomationType = "webhook"
-	FailingPolicyJira    FailingPolicyAutomationType = "jira"
-	FailingPolicyZendesk FailingPolicyAutomationType = "zendesk"
+	FailingPolicyWebhook        FailingPolicyAutomationType = "webhook"
+	FailingPolicyJira           FailingPolicyAutomationType = "jira"
+	FailingPolicyZendesk        FailingPolicyAutomationType = "zendesk"
+	FailingPolicySlack          FailingPolicyAutomationType = "slack"
+	FailingPolicyMicrosoftTeams FailingPolicyAutomationType = "microsoft_teams"
+	FailingPolicyCustomHTTP     FailingPolicyAutomationType = "custom_http"
 )
 
 // FailingPolicyAutomationConfig holds the configuration for proessing a
@@ -162,7 +165,7 @@ func makeTeamConfigCache(ds fleet.Datastore, globalIntgs fleet.Integrations) fun
 			return cfg, nil
 		}
 
-		team, err := ds.Team(ctx, teamID)
+		team, err := fleet.TeamWithInheritedConfig(ctx, ds, teamID)
 		if err != nil {
 			return cfg, ctxerr.Wrapf(ctx, err, "get team: %d", teamID)
 		}
@@ -220,5 +223,26 @@ func getActiveAutomation(webhook fleet.FailingPoliciesWebhookSettings, intgs fle
 			return FailingPolicyZendesk
 		}
 	}
+
+	// check for slack integrations
+	for _, s := range intgs.Slack {
+		if s.EnableFailingPolicies {
+			return FailingPolicySlack
+		}
+	}
+
+	// check for microsoft teams integrations
+	for _, m := range intgs.MicrosoftTeams {
+		if m.EnableFailingPolicies {
+			return FailingPolicyMicrosoftTeams
+		}
+	}
+
+	// check for custom http integrations
+	for _, c := range intgs.CustomHTTP {
+		if c.EnableFailingPolicies {
+			return FailingPolicyCustomHTTP
+		}
+	}
 	return ""
 }
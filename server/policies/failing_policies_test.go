@@ -29,9 +29,13 @@ func TestTriggerFailingPolicies(t *testing.T) {
 	// pol-unknown-11: policy that does not exist anymore, id 11
 	// pol-teamD-{12-14}: team D policies (only 12 and 13 is enabled), ids 12-13-14
 	// pol-teamE-15: team E policy, integration does not exist at the global level
+	// pol-teamF-{16-17}: team F policies (only 16 is enabled), ids 16-17
+	// pol-teamG-{18-19}: team G policies (only 19 is enabled), ids 18-19
+	// pol-teamH-20: team H policy (enabled), id 20
 	//
 	// Global config uses the webhook, team A a Jira integration, team B a
-	// Zendesk integration, team D a webhook.
+	// Zendesk integration, team D a webhook, team F a Slack integration, team
+	// G a Microsoft Teams integration and team H a custom HTTP integration.
 
 	pols := map[uint]*fleet.PolicyData{
 		1:  {ID: 1, Name: "pol-global-1"},
@@ -48,6 +52,11 @@ func TestTriggerFailingPolicies(t *testing.T) {
 		13: {ID: 13, Name: "pol-teamD-13", TeamID: ptr.Uint(4)},
 		14: {ID: 14, Name: "pol-teamD-14", TeamID: ptr.Uint(4)},
 		15: {ID: 15, Name: "pol-teamE-15", TeamID: ptr.Uint(5)},
+		16: {ID: 16, Name: "pol-teamF-16", TeamID: ptr.Uint(6)},
+		17: {ID: 17, Name: "pol-teamF-17", TeamID: ptr.Uint(6)},
+		18: {ID: 18, Name: "pol-teamG-18", TeamID: ptr.Uint(7)},
+		19: {ID: 19, Name: "pol-teamG-19", TeamID: ptr.Uint(7)},
+		20: {ID: 20, Name: "pol-teamH-20", TeamID: ptr.Uint(8)},
 	}
 	ds.PolicyFunc = func(ctx context.Context, id uint) (*fleet.Policy, error) {
 		pd, ok := pols[id]
@@ -110,6 +119,45 @@ func TestTriggerFailingPolicies(t *testing.T) {
 				},
 			},
 		}},
+		6: {ID: 6, Name: "teamF", Config: fleet.TeamConfig{
+			WebhookSettings: fleet.TeamWebhookSettings{
+				FailingPoliciesWebhook: fleet.FailingPoliciesWebhookSettings{
+					PolicyIDs: []uint{16},
+				},
+			},
+			Integrations: fleet.TeamIntegrations{
+				Slack: []*fleet.TeamSlackIntegration{
+					{URL: "https://s.com/hook", EnableFailingPolicies: true},
+				},
+			},
+		}},
+		7: {ID: 7, Name: "teamG", Config: fleet.TeamConfig{
+			WebhookSettings: fleet.TeamWebhookSettings{
+				FailingPoliciesWebhook: fleet.FailingPoliciesWebhookSettings{
+					PolicyIDs: []uint{19},
+				},
+			},
+			Integrations: fleet.TeamIntegrations{
+				Slack: []*fleet.TeamSlackIntegration{
+					{URL: "https://s.com/hook", EnableFailingPolicies: false},
+				},
+				MicrosoftTeams: []*fleet.TeamMicrosoftTeamsIntegration{
+					{URL: "https://t.com/hook", EnableFailingPolicies: true},
+				},
+			},
+		}},
+		8: {ID: 8, Name: "teamH", Config: fleet.TeamConfig{
+			WebhookSettings: fleet.TeamWebhookSettings{
+				FailingPoliciesWebhook: fleet.FailingPoliciesWebhookSettings{
+					PolicyIDs: []uint{20},
+				},
+			},
+			Integrations: fleet.TeamIntegrations{
+				CustomHTTP: []*fleet.TeamCustomHTTPIntegration{
+					{Name: "tracker", EnableFailingPolicies: true},
+				},
+			},
+		}},
 	}
 	ds.TeamFunc = func(ctx context.Context, id uint) (*fleet.Team, error) {
 		tm, ok := teams[id]
@@ -134,6 +182,15 @@ func TestTriggerFailingPolicies(t *testing.T) {
 			Zendesk: []*fleet.ZendeskIntegration{
 				{URL: "http://z.com", GroupID: 1, Email: "zendesk@z.com", APIToken: "secret"},
 			},
+			Slack: []*fleet.SlackIntegration{
+				{URL: "https://s.com/hook"},
+			},
+			MicrosoftTeams: []*fleet.MicrosoftTeamsIntegration{
+				{URL: "https://t.com/hook"},
+			},
+			CustomHTTP: []*fleet.CustomHTTPIntegration{
+				{Name: "tracker", URL: "https://c.com/tickets", BodyTemplate: "{{ .PolicyName }}"},
+			},
 		},
 		ServerSettings: fleet.ServerSettings{
 			ServerURL: "https://fleet.example.com",
@@ -186,6 +243,9 @@ func TestTriggerFailingPolicies(t *testing.T) {
 		{8, FailingPolicyZendesk},
 		{12, FailingPolicyWebhook},
 		{13, FailingPolicyWebhook},
+		{16, FailingPolicySlack},
+		{19, FailingPolicyMicrosoftTeams},
+		{20, FailingPolicyCustomHTTP},
 	}
 	// order of calls is undefined
 	require.ElementsMatch(t, wantCalls, triggerCalls)
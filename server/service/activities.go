@@ -45,3 +45,52 @@ func (svc *Service) ListActivities(ctx context.Context, opt fleet.ListActivities
 func (svc *Service) NewActivity(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
 	return svc.ds.NewActivity(ctx, user, activity)
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Get activity webhook deliveries
+////////////////////////////////////////////////////////////////////////////////
+
+type listActivityWebhookDeliveriesRequest struct {
+	ListOptions  fleet.ListOptions `url:"list_options"`
+	Status       string            `query:"status,optional"`
+	ActivityType string            `query:"activity_type,optional"`
+	ActivityID   *uint             `query:"activity_id,optional"`
+}
+
+type listActivityWebhookDeliveriesResponse struct {
+	Meta       *fleet.PaginationMetadata        `json:"meta"`
+	Deliveries []*fleet.ActivityWebhookDelivery `json:"deliveries"`
+	Err        error                            `json:"error,omitempty"`
+}
+
+func (r listActivityWebhookDeliveriesResponse) error() error { return r.Err }
+
+func listActivityWebhookDeliveriesEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listActivityWebhookDeliveriesRequest)
+	deliveries, metadata, err := svc.ListActivityWebhookDeliveries(ctx, fleet.ListActivityWebhookDeliveriesOptions{
+		ListOptions:  req.ListOptions,
+		Status:       fleet.ActivityWebhookDeliveryStatus(req.Status),
+		ActivityType: req.ActivityType,
+		ActivityID:   req.ActivityID,
+	})
+	if err != nil {
+		return listActivityWebhookDeliveriesResponse{Err: err}, nil
+	}
+	if deliveries == nil {
+		deliveries = []*fleet.ActivityWebhookDelivery{}
+	}
+
+	return listActivityWebhookDeliveriesResponse{Meta: metadata, Deliveries: deliveries}, nil
+}
+
+// ListActivityWebhookDeliveries returns the log of deliveries of activities to
+// the activities webhooks.
+func (svc *Service) ListActivityWebhookDeliveries(ctx context.Context, opt fleet.ListActivityWebhookDeliveriesOptions) ([]*fleet.ActivityWebhookDelivery, *fleet.PaginationMetadata, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.ActivityWebhookDelivery{}, fleet.ActionRead); err != nil {
+		return nil, nil, err
+	}
+	if opt.Status != "" && !opt.Status.IsValid() {
+		return nil, nil, fleet.NewInvalidArgumentError("status", "invalid status, must be one of pending, success or failed")
+	}
+	return svc.ds.ListActivityWebhookDeliveries(ctx, opt)
+}
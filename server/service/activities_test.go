@@ -51,6 +51,31 @@ func TestListActivities(t *testing.T) {
 	require.Contains(t, err.Error(), authz.ForbiddenErrorMessage)
 }
 
+func TestListActivityWebhookDeliveries(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	ds.ListActivityWebhookDeliveriesFunc = func(ctx context.Context, opt fleet.ListActivityWebhookDeliveriesOptions) ([]*fleet.ActivityWebhookDelivery, *fleet.PaginationMetadata, error) {
+		return []*fleet.ActivityWebhookDelivery{{ID: 1}}, nil, nil
+	}
+
+	// only global admins can read the deliveries
+	deliveries, _, err := svc.ListActivityWebhookDeliveries(test.UserContext(ctx, test.UserAdmin), fleet.ListActivityWebhookDeliveriesOptions{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	for _, u := range []*fleet.User{test.UserMaintainer, test.UserObserver, test.UserTeamAdminTeam1, test.UserNoRoles} {
+		_, _, err := svc.ListActivityWebhookDeliveries(test.UserContext(ctx, u), fleet.ListActivityWebhookDeliveriesOptions{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), authz.ForbiddenErrorMessage)
+	}
+
+	// invalid status filter
+	_, _, err = svc.ListActivityWebhookDeliveries(test.UserContext(ctx, test.UserAdmin), fleet.ListActivityWebhookDeliveriesOptions{Status: "nope"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid status")
+}
+
 func Test_logRoleChangeActivities(t *testing.T) {
 	tests := []struct {
 		name             string
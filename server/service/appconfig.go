@@ -184,6 +184,27 @@ func (svc *Service) AppConfig(ctx context.Context) (*fleet.AppConfig, error) {
 		zdIntegration.APIToken = fleet.MaskedPassword
 	}
 
+	// the URLs of incoming webhooks act as credentials
+	for _, slackIntegration := range ac.Integrations.Slack {
+		slackIntegration.URL = fleet.MaskWebhookURL(slackIntegration.URL)
+	}
+
+	for _, teamsIntegration := range ac.Integrations.MicrosoftTeams {
+		teamsIntegration.URL = fleet.MaskWebhookURL(teamsIntegration.URL)
+	}
+
+	for _, customIntegration := range ac.Integrations.CustomHTTP {
+		for k := range customIntegration.Headers {
+			customIntegration.Headers[k] = fleet.MaskedPassword
+		}
+	}
+
+	for _, webhook := range ac.WebhookSettings.ActivitiesWebhooks {
+		if webhook.Secret != "" {
+			webhook.Secret = fleet.MaskedPassword
+		}
+	}
+
 	return ac, nil
 }
 
@@ -234,6 +255,26 @@ func modifyAppConfigEndpoint(ctx context.Context, request interface{}, svc fleet
 	return response, nil
 }
 
+// restoreActivitiesWebhooksSecrets returns the new activities webhooks with
+// their masked secrets replaced by the stored secret of the webhook with the
+// same URL, so that the output of GET config can be applied as-is.
+func restoreActivitiesWebhooksSecrets(stored, webhooks []*fleet.ActivityWebhookSettings) []*fleet.ActivityWebhookSettings {
+	storedByURL := make(map[string]*fleet.ActivityWebhookSettings, len(stored))
+	for _, w := range stored {
+		storedByURL[w.DestinationURL] = w
+	}
+	for _, w := range webhooks {
+		if w.Secret != fleet.MaskedPassword {
+			continue
+		}
+		w.Secret = ""
+		if old := storedByURL[w.DestinationURL]; old != nil {
+			w.Secret = old.Secret
+		}
+	}
+	return webhooks
+}
+
 func (svc *Service) ModifyAppConfig(ctx context.Context, p []byte, applyOpts fleet.ApplySpecOptions) (*fleet.AppConfig, error) {
 	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionWrite); err != nil {
 		return nil, err
@@ -268,6 +309,21 @@ func (svc *Service) ModifyAppConfig(ctx context.Context, p []byte, applyOpts fle
 		return nil, ctxerr.Wrap(ctx, err, "modify AppConfig")
 	}
 
+	storedSlackByURL, err := fleet.IndexSlackIntegrations(appConfig.Integrations.Slack)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "modify AppConfig")
+	}
+
+	storedMicrosoftTeamsByURL, err := fleet.IndexMicrosoftTeamsIntegrations(appConfig.Integrations.MicrosoftTeams)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "modify AppConfig")
+	}
+
+	storedCustomHTTPByName, err := fleet.IndexCustomHTTPIntegrations(appConfig.Integrations.CustomHTTP)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "modify AppConfig")
+	}
+
 	invalid := &fleet.InvalidArgumentError{}
 	var newAppConfig fleet.AppConfig
 	if err := json.Unmarshal(p, &newAppConfig); err != nil {
@@ -332,7 +388,23 @@ func (svc *Service) ModifyAppConfig(ctx context.Context, p []byte, applyOpts fle
 	fleet.ValidateEnabledVulnerabilitiesIntegrations(appConfig.WebhookSettings.VulnerabilitiesWebhook, appConfig.Integrations, invalid)
 	fleet.ValidateEnabledFailingPoliciesIntegrations(appConfig.WebhookSettings.FailingPoliciesWebhook, appConfig.Integrations, invalid)
 	fleet.ValidateEnabledHostStatusIntegrations(appConfig.WebhookSettings.HostStatusWebhook, invalid)
+	if newAppConfig.WebhookSettings.ActivitiesWebhooks != nil {
+		// the activities webhooks were provided, they replace the existing ones
+		// (the merge of the JSON payload into the existing slice would otherwise
+		// keep stale values).
+		appConfig.WebhookSettings.ActivitiesWebhooks = restoreActivitiesWebhooksSecrets(
+			oldAppConfig.WebhookSettings.ActivitiesWebhooks, newAppConfig.WebhookSettings.ActivitiesWebhooks)
+	}
+	fleet.ValidateActivitiesWebhooks(appConfig.WebhookSettings.ActivitiesWebhooks, invalid)
+	fleet.ValidateVulnerabilityRemediationSLA(appConfig.VulnerabilitySettings.RemediationSLA, invalid)
 	svc.validateMDM(ctx, license, &oldAppConfig.MDM, &appConfig.MDM, invalid)
+	if newAppConfig.TeamAssignmentRules != nil {
+		// the rules were provided, they replace the existing ones (the merge of
+		// the JSON payload into the existing slice would otherwise keep stale
+		// values).
+		appConfig.TeamAssignmentRules = newAppConfig.TeamAssignmentRules
+		svc.validateTeamAssignmentRules(ctx, license, appConfig.TeamAssignmentRules, invalid)
+	}
 
 	if invalid.HasErrors() {
 		return nil, ctxerr.Wrap(ctx, invalid)
@@ -390,9 +462,39 @@ func (svc *Service) ModifyAppConfig(ctx context.Context, p []byte, applyOpts fle
 	}
 	appConfig.Integrations.Zendesk = newAppConfig.Integrations.Zendesk
 
+	delSlack, err := fleet.ValidateSlackIntegrations(ctx, storedSlackByURL, newAppConfig.Integrations.Slack)
+	if err != nil {
+		if errors.As(err, &fleet.IntegrationTestError{}) {
+			return nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{Message: err.Error()})
+		}
+		return nil, ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("Slack integration", err.Error()))
+	}
+	appConfig.Integrations.Slack = newAppConfig.Integrations.Slack
+
+	delMicrosoftTeams, err := fleet.ValidateMicrosoftTeamsIntegrations(ctx, storedMicrosoftTeamsByURL, newAppConfig.Integrations.MicrosoftTeams)
+	if err != nil {
+		if errors.As(err, &fleet.IntegrationTestError{}) {
+			return nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{Message: err.Error()})
+		}
+		return nil, ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("Microsoft Teams integration", err.Error()))
+	}
+	appConfig.Integrations.MicrosoftTeams = newAppConfig.Integrations.MicrosoftTeams
+
+	delCustomHTTP, err := fleet.ValidateCustomHTTPIntegrations(ctx, storedCustomHTTPByName, newAppConfig.Integrations.CustomHTTP)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("custom HTTP integration", err.Error()))
+	}
+	appConfig.Integrations.CustomHTTP = newAppConfig.Integrations.CustomHTTP
+
 	// if any integration was deleted, remove it from any team that uses it
-	if len(delJira)+len(delZendesk) > 0 {
-		if err := svc.ds.DeleteIntegrationsFromTeams(ctx, fleet.Integrations{Jira: delJira, Zendesk: delZendesk}); err != nil {
+	if len(delJira)+len(delZendesk)+len(delSlack)+len(delMicrosoftTeams)+len(delCustomHTTP) > 0 {
+		if err := svc.ds.DeleteIntegrationsFromTeams(ctx, fleet.Integrations{
+			Jira:           delJira,
+			Zendesk:        delZendesk,
+			Slack:          delSlack,
+			MicrosoftTeams: delMicrosoftTeams,
+			CustomHTTP:     delCustomHTTP,
+		}); err != nil {
 			return nil, ctxerr.Wrap(ctx, err, "delete integrations from teams")
 		}
 	}
@@ -518,6 +620,40 @@ func (svc *Service) validateMDM(
 	}
 }
 
+func (svc *Service) validateTeamAssignmentRules(
+	ctx context.Context,
+	license *fleet.LicenseInfo,
+	rules fleet.TeamAssignmentRules,
+	invalid *fleet.InvalidArgumentError,
+) {
+	if len(rules) == 0 {
+		return
+	}
+	if !license.IsPremium() {
+		invalid.Append("team_assignment_rules", ErrMissingLicense.Error())
+		return
+	}
+	if err := rules.Validate(); err != nil {
+		invalid.Append("team_assignment_rules", err.Error())
+		return
+	}
+	for i, r := range rules {
+		if _, err := svc.ds.TeamByName(ctx, r.Team); err != nil {
+			invalid.Append("team_assignment_rules", fmt.Sprintf("rule %d: team %q not found", i, r.Team))
+		}
+	}
+	if names := rules.LabelNames(); len(names) > 0 {
+		ids, err := svc.ds.LabelIDsByName(ctx, names)
+		if err != nil {
+			invalid.Append("team_assignment_rules", err.Error())
+			return
+		}
+		if len(ids) != len(names) {
+			invalid.Append("team_assignment_rules", fmt.Sprintf("one or more of the labels %q do not exist", names))
+		}
+	}
+}
+
 func validateSSOSettings(p fleet.AppConfig, existing *fleet.AppConfig, invalid *fleet.InvalidArgumentError, license *fleet.LicenseInfo) {
 	if p.SSOSettings.EnableSSO {
 		if p.SSOSettings.Metadata == "" && p.SSOSettings.MetadataURL == "" {
@@ -368,6 +368,11 @@ func TestAppConfigSecretsObfuscated(t *testing.T) {
 					{APIToken: "zendesktoken"},
 				},
 			},
+			WebhookSettings: fleet.WebhookSettings{
+				ActivitiesWebhooks: []*fleet.ActivityWebhookSettings{
+					{DestinationURL: "https://example.com", Secret: "webhooksecret"},
+				},
+			},
 		}, nil
 	}
 
@@ -413,8 +418,314 @@ func TestAppConfigSecretsObfuscated(t *testing.T) {
 			require.Equal(t, ac.SMTPSettings.SMTPPassword, fleet.MaskedPassword)
 			require.Equal(t, ac.Integrations.Jira[0].APIToken, fleet.MaskedPassword)
 			require.Equal(t, ac.Integrations.Zendesk[0].APIToken, fleet.MaskedPassword)
+			require.Equal(t, ac.WebhookSettings.ActivitiesWebhooks[0].Secret, fleet.MaskedPassword)
+		})
+	}
+}
+
+func TestModifyAppConfigActivitiesWebhooks(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	dsAppConfig := &fleet.AppConfig{
+		OrgInfo:        fleet.OrgInfo{OrgName: "Test"},
+		ServerSettings: fleet.ServerSettings{ServerURL: "https://example.org"},
+		WebhookSettings: fleet.WebhookSettings{
+			ActivitiesWebhooks: []*fleet.ActivityWebhookSettings{
+				{DestinationURL: "https://a.example.com", Secret: "secreta", ActivityTypes: []string{"created_pack"}},
+			},
+		},
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return dsAppConfig.Copy(), nil
+	}
+	ds.SaveAppConfigFunc = func(ctx context.Context, conf *fleet.AppConfig) error {
+		dsAppConfig = conf
+		return nil
+	}
+
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	cases := []struct {
+		desc    string
+		payload string
+		wantErr string
+		want    []*fleet.ActivityWebhookSettings
+	}{
+		{
+			"not provided",
+			`{"webhook_settings": {"interval": "1h"}}`,
+			"",
+			[]*fleet.ActivityWebhookSettings{
+				{DestinationURL: "https://a.example.com", Secret: "secreta", ActivityTypes: []string{"created_pack"}},
+			},
+		},
+		{
+			"masked secret kept, new webhook added",
+			`{"webhook_settings": {"activities_webhooks": [
+				{"destination_url": "https://a.example.com", "secret": "` + fleet.MaskedPassword + `"},
+				{"destination_url": "https://b.example.com", "secret": "secretb", "activity_types": ["deleted_pack"]}
+			]}}`,
+			"",
+			[]*fleet.ActivityWebhookSettings{
+				{DestinationURL: "https://a.example.com", Secret: "secreta"},
+				{DestinationURL: "https://b.example.com", Secret: "secretb", ActivityTypes: []string{"deleted_pack"}},
+			},
+		},
+		{
+			"missing url",
+			`{"webhook_settings": {"activities_webhooks": [{"secret": "x"}]}}`,
+			"destination_url is required",
+			nil,
+		},
+		{
+			"invalid url",
+			`{"webhook_settings": {"activities_webhooks": [{"destination_url": "ftp://a.example.com"}]}}`,
+			"must be a valid http or https URL",
+			nil,
+		},
+		{
+			"duplicate url",
+			`{"webhook_settings": {"activities_webhooks": [{"destination_url": "https://a.example.com"}, {"destination_url": "https://a.example.com"}]}}`,
+			"duplicate activities webhook",
+			nil,
+		},
+		{
+			"unknown activity type",
+			`{"webhook_settings": {"activities_webhooks": [{"destination_url": "https://a.example.com", "activity_types": ["no_such_activity"]}]}}`,
+			"unknown activity type: no_such_activity",
+			nil,
+		},
+		{
+			"cleared",
+			`{"webhook_settings": {"activities_webhooks": []}}`,
+			"",
+			[]*fleet.ActivityWebhookSettings{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := svc.ModifyAppConfig(ctx, []byte(c.payload), fleet.ApplySpecOptions{})
+			if c.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.want, dsAppConfig.WebhookSettings.ActivitiesWebhooks)
+		})
+	}
+}
+
+func TestModifyAppConfigChatIntegrations(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = append(posted, r.URL.Path)
+		if r.URL.Path == "/fail/token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	dsAppConfig := &fleet.AppConfig{
+		OrgInfo:        fleet.OrgInfo{OrgName: "Test"},
+		ServerSettings: fleet.ServerSettings{ServerURL: "https://example.org"},
+		Integrations: fleet.Integrations{
+			Slack: []*fleet.SlackIntegration{
+				{URL: srv.URL + "/slack1/token", EnableFailingPolicies: true},
+			},
+		},
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return dsAppConfig.Copy(), nil
+	}
+	ds.SaveAppConfigFunc = func(ctx context.Context, conf *fleet.AppConfig) error {
+		dsAppConfig = conf
+		return nil
+	}
+	var deleted fleet.Integrations
+	ds.DeleteIntegrationsFromTeamsFunc = func(ctx context.Context, deletedIntgs fleet.Integrations) error {
+		deleted = deletedIntgs
+		return nil
+	}
+
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	// toggling an existing integration does not post a test message
+	_, err := svc.ModifyAppConfig(ctx, []byte(`{"integrations": {"slack": [
+		{"url": "`+srv.URL+`/slack1/token", "enable_failing_policies": false, "enable_software_vulnerabilities": true}
+	]}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.Empty(t, posted)
+	require.Len(t, dsAppConfig.Integrations.Slack, 1)
+	require.True(t, dsAppConfig.Integrations.Slack[0].EnableSoftwareVulnerabilities)
+
+	// the URL is returned masked
+	ac, err := svc.AppConfig(ctx)
+	require.NoError(t, err)
+	require.Len(t, ac.Integrations.Slack, 1)
+	maskedURL := srv.URL + "/slack1/" + fleet.MaskedPassword
+	require.Equal(t, maskedURL, ac.Integrations.Slack[0].URL)
+
+	// applying the masked URL keeps the stored one
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {"slack": [
+		{"url": "`+maskedURL+`", "enable_failing_policies": true}
+	]}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.Empty(t, posted)
+	require.Len(t, dsAppConfig.Integrations.Slack, 1)
+	require.Equal(t, srv.URL+"/slack1/token", dsAppConfig.Integrations.Slack[0].URL)
+	require.True(t, dsAppConfig.Integrations.Slack[0].EnableFailingPolicies)
+	require.False(t, ds.DeleteIntegrationsFromTeamsFuncInvoked)
+
+	// a new token for the same webhook is tested
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {"slack": [
+		{"url": "`+srv.URL+`/slack1/token2"}
+	]}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/slack1/token2"}, posted)
+	require.Equal(t, srv.URL+"/slack1/token2", dsAppConfig.Integrations.Slack[0].URL)
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {"slack": [
+		{"url": "`+srv.URL+`/slack1/token"}
+	]}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	posted = nil
+
+	// adding a new one posts a test message
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {
+		"slack": [{"url": "`+srv.URL+`/slack1/token"}],
+		"microsoft_teams": [{"url": "`+srv.URL+`/teams1/token", "enable_failing_policies": true}]
+	}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/teams1/token"}, posted)
+	require.Len(t, dsAppConfig.Integrations.MicrosoftTeams, 1)
+
+	// more than one type of failing policies integration enabled
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {
+		"slack": [{"url": "`+srv.URL+`/slack1/token", "enable_failing_policies": true}],
+		"microsoft_teams": [{"url": "`+srv.URL+`/teams1/token", "enable_failing_policies": true}]
+	}}`), fleet.ApplySpecOptions{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot enable more than one type of integration automations")
+
+	// duplicate urls
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {
+		"slack": [{"url": "`+srv.URL+`/slack1/token"}, {"url": "`+srv.URL+`/slack1/token"}],
+		"microsoft_teams": [{"url": "`+srv.URL+`/teams1/token"}]
+	}}`), fleet.ApplySpecOptions{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "duplicate Slack integration")
+
+	// the test message fails
+	posted = nil
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {
+		"slack": [{"url": "`+srv.URL+`/slack1/token"}, {"url": "`+srv.URL+`/fail/token"}],
+		"microsoft_teams": [{"url": "`+srv.URL+`/teams1/token"}]
+	}}`), fleet.ApplySpecOptions{})
+	require.Error(t, err)
+	var bre *fleet.BadRequestError
+	require.ErrorAs(t, err, &bre)
+	require.Contains(t, err.Error(), "Slack integration request failed")
+	require.Equal(t, []string{"/fail/token"}, posted)
+
+	// deleting integrations removes them from the teams
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {"slack": [], "microsoft_teams": []}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.True(t, ds.DeleteIntegrationsFromTeamsFuncInvoked)
+	require.Len(t, deleted.Slack, 1)
+	require.Equal(t, srv.URL+"/slack1/token", deleted.Slack[0].URL)
+	require.Len(t, deleted.MicrosoftTeams, 1)
+	require.Equal(t, srv.URL+"/teams1/token", deleted.MicrosoftTeams[0].URL)
+	require.Empty(t, dsAppConfig.Integrations.Slack)
+	require.Empty(t, dsAppConfig.Integrations.MicrosoftTeams)
+}
+
+func TestModifyAppConfigCustomHTTPIntegrations(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	dsAppConfig := &fleet.AppConfig{
+		OrgInfo:        fleet.OrgInfo{OrgName: "Test"},
+		ServerSettings: fleet.ServerSettings{ServerURL: "https://example.org"},
+		Integrations: fleet.Integrations{
+			CustomHTTP: []*fleet.CustomHTTPIntegration{
+				{
+					Name:         "servicenow",
+					URL:          "https://servicenow.example.com/api",
+					Method:       "POST",
+					Headers:      map[string]string{"Authorization": "Basic secret"},
+					BodyTemplate: `{"title": {{ json .PolicyName }}}`,
+				},
+			},
+		},
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return dsAppConfig.Copy(), nil
+	}
+	ds.SaveAppConfigFunc = func(ctx context.Context, conf *fleet.AppConfig) error {
+		dsAppConfig = conf
+		return nil
+	}
+	var deleted fleet.Integrations
+	ds.DeleteIntegrationsFromTeamsFunc = func(ctx context.Context, deletedIntgs fleet.Integrations) error {
+		deleted = deletedIntgs
+		return nil
+	}
+
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	// header values are masked
+	ac, err := svc.AppConfig(ctx)
+	require.NoError(t, err)
+	require.Len(t, ac.Integrations.CustomHTTP, 1)
+	require.Equal(t, fleet.MaskedPassword, ac.Integrations.CustomHTTP[0].Headers["Authorization"])
+	require.Equal(t, "Basic secret", dsAppConfig.Integrations.CustomHTTP[0].Headers["Authorization"])
+
+	// the masked config can be applied as-is, the stored header value is kept
+	b, err := json.Marshal(map[string]interface{}{"integrations": ac.Integrations})
+	require.NoError(t, err)
+	_, err = svc.ModifyAppConfig(ctx, b, fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Basic secret", dsAppConfig.Integrations.CustomHTTP[0].Headers["Authorization"])
+
+	cases := []struct {
+		desc    string
+		payload string
+		wantErr string
+	}{
+		{"missing name", `{"integrations": {"custom_http": [{"url": "https://a.example.com", "body_template": "x"}]}}`, "name is required"},
+		{"duplicate name", `{"integrations": {"custom_http": [{"name": "a", "url": "https://a.example.com", "body_template": "x"}, {"name": "a", "url": "https://b.example.com", "body_template": "x"}]}}`, "duplicate custom HTTP integration with name a"},
+		{"invalid url", `{"integrations": {"custom_http": [{"name": "a", "url": "ftp://a.example.com", "body_template": "x"}]}}`, "must be an http or https URL"},
+		{"invalid method", `{"integrations": {"custom_http": [{"name": "a", "url": "https://a.example.com", "method": "DELETE", "body_template": "x"}]}}`, "invalid method DELETE"},
+		{"empty template", `{"integrations": {"custom_http": [{"name": "a", "url": "https://a.example.com"}]}}`, "body template is empty"},
+		{"invalid template", `{"integrations": {"custom_http": [{"name": "a", "url": "https://a.example.com", "body_template": "{{ .PolicyName "}]}}`, "invalid body template"},
+		{"masked header of new integration", `{"integrations": {"custom_http": [{"name": "a", "url": "https://a.example.com", "body_template": "x", "headers": {"X-Token": "` + fleet.MaskedPassword + `"}}]}}`, "missing value for header X-Token"},
+		{"more than one enabled", `{"integrations": {"custom_http": [{"name": "a", "url": "https://a.example.com", "body_template": "x", "enable_failing_policies": true}, {"name": "b", "url": "https://b.example.com", "body_template": "x", "enable_failing_policies": true}]}}`, "cannot enable more than one custom http integration"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := svc.ModifyAppConfig(ctx, []byte(c.payload), fleet.ApplySpecOptions{})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.wantErr)
 		})
 	}
+
+	// replace the integration, the default method is set and the old one is
+	// deleted from the teams
+	_, err = svc.ModifyAppConfig(ctx, []byte(`{"integrations": {"custom_http": [
+		{"name": "tracker", "url": "https://tracker.example.com", "body_template": "{{ .PolicyName }}", "enable_failing_policies": true}
+	]}}`), fleet.ApplySpecOptions{})
+	require.NoError(t, err)
+	require.Len(t, dsAppConfig.Integrations.CustomHTTP, 1)
+	require.Equal(t, "tracker", dsAppConfig.Integrations.CustomHTTP[0].Name)
+	require.Equal(t, "POST", dsAppConfig.Integrations.CustomHTTP[0].Method)
+	require.Len(t, deleted.CustomHTTP, 1)
+	require.Equal(t, "servicenow", deleted.CustomHTTP[0].Name)
 }
 
 // TestModifyAppConfigSMTPConfigured tests that disabling SMTP
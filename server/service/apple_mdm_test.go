@@ -522,6 +522,12 @@ func TestHostDetailsMDMProfiles(t *testing.T) {
 	ds.ListHostBatteriesFunc = func(ctx context.Context, id uint) ([]*fleet.HostBattery, error) {
 		return nil, nil
 	}
+	ds.HostOrbitChannelsFunc = func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+		return nil, nil
+	}
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return nil, nil
+	}
 	ds.ListPoliciesForHostFunc = func(ctx context.Context, host *fleet.Host) ([]*fleet.HostPolicy, error) {
 		return nil, nil
 	}
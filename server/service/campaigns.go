@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
+	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/fleetdm/fleet/v4/server/authz"
+	authzctx "github.com/fleetdm/fleet/v4/server/contexts/authz"
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
 	"github.com/fleetdm/fleet/v4/server/contexts/logging"
 	"github.com/fleetdm/fleet/v4/server/contexts/viewer"
@@ -223,3 +227,147 @@ func (svc *Service) NewDistributedQueryCampaignByNames(ctx context.Context, quer
 	targets := fleet.HostTargets{HostIDs: hostIDs, LabelIDs: labelIDs}
 	return svc.NewDistributedQueryCampaign(ctx, queryString, queryID, targets)
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// List Distributed Query Campaign Results
+////////////////////////////////////////////////////////////////////////////////
+
+type listCampaignResultsRequest struct {
+	ID          uint              `url:"id"`
+	ListOptions fleet.ListOptions `url:"list_options"`
+	Format      string            `query:"format,optional"`
+}
+
+type listCampaignResultsResponse struct {
+	Campaign *fleet.DistributedQueryCampaign `json:"campaign,omitempty"`
+	Results  []*fleet.DistributedQueryResult `json:"results"`
+	Meta     *fleet.PaginationMetadata       `json:"meta,omitempty"`
+	Err      error                           `json:"error,omitempty"`
+}
+
+func (r listCampaignResultsResponse) error() error { return r.Err }
+
+type listCampaignResultsCSVResponse struct {
+	CampaignID uint                            `json:"-"`
+	Results    []*fleet.DistributedQueryResult `json:"-"` // they get rendered explicitly, in csv
+	Err        error                           `json:"error,omitempty"`
+}
+
+func (r listCampaignResultsCSVResponse) error() error { return r.Err }
+
+func (r listCampaignResultsCSVResponse) hijackRender(ctx context.Context, w http.ResponseWriter) {
+	// the columns of the query are not known in advance, use the union of the
+	// columns of all rows, in a stable order.
+	colSet := make(map[string]struct{})
+	for _, res := range r.Results {
+		for _, row := range res.Rows {
+			for col := range row {
+				colSet[col] = struct{}{}
+			}
+		}
+	}
+	cols := make([]string, 0, len(colSet))
+	for col := range colSet {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	recs := make([][]string, 0, len(r.Results)+1)
+	recs = append(recs, append([]string{"host_id", "host_hostname", "host_display_name", "error"}, cols...))
+	for _, res := range r.Results {
+		var hostID uint
+		var hostname, displayName, errMsg string
+		if res.Host != nil && res.Host.Host != nil {
+			hostID, hostname, displayName = res.Host.ID, res.Host.Hostname, res.Host.DisplayName
+		}
+		if res.Error != nil {
+			errMsg = *res.Error
+		}
+		prefix := []string{fmt.Sprint(hostID), hostname, displayName, errMsg}
+
+		// hosts without rows still get a record, so that their errors or
+		// empty results are visible
+		rows := res.Rows
+		if len(rows) == 0 {
+			rows = []map[string]string{nil}
+		}
+		for _, row := range rows {
+			rec := append([]string{}, prefix...)
+			for _, col := range cols {
+				rec = append(rec, row[col])
+			}
+			recs = append(recs, rec)
+		}
+	}
+
+	w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="Live query %d results %s.csv"`, r.CampaignID, time.Now().Format("2006-01-02")))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if err := csv.NewWriter(w).WriteAll(recs); err != nil {
+		logging.WithErr(ctx, err)
+	}
+}
+
+func listCampaignResultsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listCampaignResultsRequest)
+
+	if req.Format != "" && req.Format != "json" && req.Format != "csv" {
+		// prevent returning an "unauthorized" error, we want that specific error
+		if az, ok := authzctx.FromContext(ctx); ok {
+			az.SetChecked()
+		}
+		err := ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("format", "unsupported results format").
+			WithStatus(http.StatusUnsupportedMediaType))
+		return listCampaignResultsResponse{Err: err}, nil
+	}
+
+	campaign, results, meta, err := svc.ListCampaignResults(ctx, req.ID, req.ListOptions)
+	if err != nil {
+		return listCampaignResultsResponse{Err: err}, nil
+	}
+	if req.Format == "csv" {
+		return listCampaignResultsCSVResponse{CampaignID: req.ID, Results: results}, nil
+	}
+	if results == nil {
+		results = []*fleet.DistributedQueryResult{}
+	}
+	return listCampaignResultsResponse{Campaign: campaign, Results: results, Meta: meta}, nil
+}
+
+func (svc *Service) ListCampaignResults(ctx context.Context, campaignID uint, opt fleet.ListOptions) (*fleet.DistributedQueryCampaign, []*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error) {
+	// Explicitly set ObserverCanRun: true in this check because we check that
+	// the user trying to read results is the same user that initiated the
+	// query, like when streaming the results.
+	if err := svc.authz.Authorize(ctx, &fleet.TargetedQuery{Query: &fleet.Query{ObserverCanRun: true}}, fleet.ActionRun); err != nil {
+		return nil, nil, nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, nil, nil, fleet.ErrNoContext
+	}
+
+	if svc.config.App.LiveQueryResultsRetention <= 0 {
+		return nil, nil, nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{
+			Message: "live query results are not persisted, set app_live_query_results_retention to enable it",
+		})
+	}
+
+	campaign, err := svc.ds.DistributedQueryCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, nil, nil, ctxerr.Wrap(ctx, err, "get campaign")
+	}
+	if campaign.UserID != vc.User.ID {
+		return nil, nil, nil, authz.ForbiddenWithInternal(
+			"campaign user ID does not match", vc.User, campaign, fleet.ActionRun,
+		)
+	}
+
+	results, meta, err := svc.ds.ListDistributedQueryCampaignResults(ctx, campaignID, opt)
+	if err != nil {
+		return nil, nil, nil, ctxerr.Wrap(ctx, err, "list campaign results")
+	}
+	return campaign, results, meta, nil
+}
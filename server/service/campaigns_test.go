@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/fleetdm/fleet/v4/server/config"
 	"github.com/fleetdm/fleet/v4/server/contexts/viewer"
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/fleetdm/fleet/v4/server/mock"
@@ -253,3 +254,51 @@ func TestLiveQueryAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestListCampaignResults(t *testing.T) {
+	ds := new(mock.Store)
+	cfg := config.TestConfig()
+	cfg.App.LiveQueryResultsRetention = time.Hour
+	svc, ctx := newTestServiceWithConfig(t, ds, cfg, pubsub.NewInmemQueryResults(), nopLiveQuery{})
+
+	owner := &fleet.User{ID: 1, GlobalRole: ptr.String(fleet.RoleMaintainer)}
+	other := &fleet.User{ID: 2, GlobalRole: ptr.String(fleet.RoleAdmin)}
+	observer := &fleet.User{ID: 3, GlobalRole: ptr.String(fleet.RoleObserver)}
+
+	ds.DistributedQueryCampaignFunc = func(ctx context.Context, id uint) (*fleet.DistributedQueryCampaign, error) {
+		if id != 42 {
+			return nil, &notFoundError{}
+		}
+		return &fleet.DistributedQueryCampaign{ID: 42, UserID: owner.ID, Status: fleet.QueryComplete}, nil
+	}
+	ds.ListDistributedQueryCampaignResultsFunc = func(ctx context.Context, campaignID uint, opt fleet.ListOptions) ([]*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error) {
+		return []*fleet.DistributedQueryResult{
+			{DistributedQueryCampaignID: campaignID, Host: &fleet.HostResponse{Host: &fleet.Host{ID: 1}}},
+		}, &fleet.PaginationMetadata{}, nil
+	}
+
+	// the user who ran the query gets the results
+	campaign, results, meta, err := svc.ListCampaignResults(viewer.NewContext(ctx, viewer.Viewer{User: owner}), 42, fleet.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, uint(42), campaign.ID)
+	require.Len(t, results, 1)
+	require.NotNil(t, meta)
+
+	// other users don't, even admins
+	_, _, _, err = svc.ListCampaignResults(viewer.NewContext(ctx, viewer.Viewer{User: other}), 42, fleet.ListOptions{})
+	checkAuthErr(t, true, err)
+	_, _, _, err = svc.ListCampaignResults(viewer.NewContext(ctx, viewer.Viewer{User: observer}), 42, fleet.ListOptions{})
+	checkAuthErr(t, true, err)
+
+	// unknown campaign
+	_, _, _, err = svc.ListCampaignResults(viewer.NewContext(ctx, viewer.Viewer{User: owner}), 1, fleet.ListOptions{})
+	require.Error(t, err)
+	var nfe fleet.NotFoundError
+	require.ErrorAs(t, err, &nfe)
+
+	// persistence disabled
+	svc, ctx = newTestService(t, ds, pubsub.NewInmemQueryResults(), nopLiveQuery{})
+	_, _, _, err = svc.ListCampaignResults(viewer.NewContext(ctx, viewer.Viewer{User: owner}), 42, fleet.ListOptions{})
+	var bre *fleet.BadRequestError
+	require.ErrorAs(t, err, &bre)
+}
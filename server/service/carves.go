@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
 	"time"
 
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
@@ -48,7 +54,14 @@ func (svc *Service) ListCarves(ctx context.Context, opt fleet.CarveListOptions)
 		return nil, err
 	}
 
-	return svc.carveStore.ListCarves(ctx, opt)
+	carves, err := svc.carveStore.ListCarves(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	for _, carve := range carves {
+		carve.ComputeProgress()
+	}
+	return carves, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -81,7 +94,12 @@ func (svc *Service) GetCarve(ctx context.Context, id int64) (*fleet.CarveMetadat
 		return nil, err
 	}
 
-	return svc.carveStore.Carve(ctx, id)
+	carve, err := svc.carveStore.Carve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	carve.ComputeProgress()
+	return carve, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -136,6 +154,147 @@ func (svc *Service) GetBlock(ctx context.Context, carveId, blockId int64) ([]byt
 	return data, nil
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Download Carve
+////////////////////////////////////////////////////////////////////////////////
+
+type downloadCarveRequest struct {
+	ID int64
+
+	// httpReq is used by the response to handle the HTTP range and
+	// conditional request headers.
+	httpReq *http.Request
+}
+
+func (downloadCarveRequest) DecodeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := intFromRequest(r, "id")
+	if err != nil {
+		return nil, badRequestErr("parse carve id", err)
+	}
+	return &downloadCarveRequest{ID: id, httpReq: r}, nil
+}
+
+type downloadCarveResponse struct {
+	Err error `json:"error,omitempty"`
+
+	// the fields below are used in hijackRender for the response.
+	carve    *fleet.CarveMetadata
+	contents io.ReadSeeker
+	httpReq  *http.Request
+}
+
+func (r downloadCarveResponse) error() error { return r.Err }
+
+func (r downloadCarveResponse) hijackRender(ctx context.Context, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": r.carve.Name + ".tar",
+	}))
+	if r.carve.SHA256 != nil {
+		// the hash identifies the contents, so it's a strong ETag (used for
+		// If-Range requests when resuming a download).
+		w.Header().Set("ETag", `"`+*r.carve.SHA256+`"`)
+		w.Header().Set("X-Fleet-Carve-SHA256", *r.carve.SHA256)
+	}
+	// ServeContent handles the Range, If-Range and conditional headers. Errors
+	// while streaming the blocks can't change the status code anymore, the
+	// client detects them by the short Content-Length.
+	http.ServeContent(w, r.httpReq, "", r.carve.CreatedAt, r.contents)
+}
+
+func downloadCarveEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*downloadCarveRequest)
+	carve, contents, err := svc.DownloadCarve(ctx, req.ID)
+	if err != nil {
+		return downloadCarveResponse{Err: err}, nil
+	}
+	return downloadCarveResponse{carve: carve, contents: contents, httpReq: req.httpReq}, nil
+}
+
+func (svc *Service) DownloadCarve(ctx context.Context, id int64) (*fleet.CarveMetadata, io.ReadSeeker, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.CarveMetadata{}, fleet.ActionRead); err != nil {
+		return nil, nil, err
+	}
+
+	carve, err := svc.carveStore.Carve(ctx, id)
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "get carve")
+	}
+
+	if carve.Expired {
+		return nil, nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{Message: "cannot download expired carve"})
+	}
+	if carve.Error != nil {
+		return nil, nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{Message: "cannot download failed carve: " + *carve.Error})
+	}
+	if !carve.BlocksComplete() || carve.BlockSize <= 0 {
+		return nil, nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{
+			Message: fmt.Sprintf("carve is not complete: received %d of %d blocks", carve.MaxBlock+1, carve.BlockCount),
+		})
+	}
+	carve.ComputeProgress()
+
+	return carve, &carveContentsReader{ctx: ctx, store: svc.carveStore, carve: carve, blockID: -1}, nil
+}
+
+// carveContentsReader reads the contents of a carve from its blocks, one
+// block at a time. It implements io.Seeker so that ranges of the carve can be
+// served.
+type carveContentsReader struct {
+	ctx   context.Context
+	store fleet.CarveStore
+	carve *fleet.CarveMetadata
+
+	offset int64
+	// blockID is the ID of the block in buf, -1 if none.
+	blockID int64
+	buf     []byte
+}
+
+func (r *carveContentsReader) Read(p []byte) (int, error) {
+	if r.offset >= r.carve.CarveSize {
+		return 0, io.EOF
+	}
+
+	blockID := r.offset / r.carve.BlockSize
+	if blockID != r.blockID {
+		data, err := r.store.GetBlock(r.ctx, r.carve, blockID)
+		if err != nil {
+			return 0, ctxerr.Wrapf(r.ctx, err, "get block %d", blockID)
+		}
+		r.blockID, r.buf = blockID, data
+	}
+
+	start := r.offset - blockID*r.carve.BlockSize
+	if start >= int64(len(r.buf)) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	chunk := r.buf[start:]
+	if remaining := r.carve.CarveSize - r.offset; int64(len(chunk)) > remaining {
+		chunk = chunk[:remaining]
+	}
+	n := copy(p, chunk)
+	r.offset += int64(n)
+	return n, nil
+}
+
+func (r *carveContentsReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.carve.CarveSize
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Begin File Carve
 ////////////////////////////////////////////////////////////////////////////////
@@ -300,7 +459,15 @@ func (svc *Service) CarveBlock(ctx context.Context, payload fleet.CarveBlockPayl
 		return ctxerr.Wrap(ctx, err, "validate carve block")
 	}
 
+	// The hash of the blocks received so far is updated before storing the
+	// block, so that it is saved along with the new max block by NewBlock.
+	prevSHA256, prevSHA256State := carve.SHA256, carve.SHA256State
+	if err := updateCarveHash(carve, payload.BlockId, payload.Data); err != nil {
+		logging.WithExtras(ctx, "update_carve_hash_error", err, "carve_id", carve.ID)
+	}
+
 	if err := svc.carveStore.NewBlock(ctx, carve, payload.BlockId, payload.Data); err != nil {
+		carve.SHA256, carve.SHA256State = prevSHA256, prevSHA256State
 		carve.Error = ptr.String(err.Error())
 		if errRecord := svc.carveStore.UpdateCarve(ctx, carve); err != nil {
 			logging.WithExtras(ctx, "record_carve_error", errRecord, "carve_id", carve.ID)
@@ -312,6 +479,37 @@ func (svc *Service) CarveBlock(ctx context.Context, payload fleet.CarveBlockPayl
 	return nil
 }
 
+// updateCarveHash updates the SHA-256 hash state of the carve with the data
+// of the block, and sets the final hash if it is the last block. Carves that
+// started without a hash state (e.g. received by an older Fleet version) are
+// not hashed.
+func updateCarveHash(carve *fleet.CarveMetadata, blockID int64, data []byte) error {
+	h := sha256.New()
+	if blockID > 0 {
+		if len(carve.SHA256State) == 0 {
+			return nil
+		}
+		if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(carve.SHA256State); err != nil {
+			carve.SHA256State = nil
+			return fmt.Errorf("unmarshal hash state: %w", err)
+		}
+	}
+	h.Write(data)
+
+	if blockID >= carve.BlockCount-1 {
+		carve.SHA256 = ptr.String(hex.EncodeToString(h.Sum(nil)))
+		carve.SHA256State = nil
+		return nil
+	}
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		carve.SHA256State = nil
+		return fmt.Errorf("marshal hash state: %w", err)
+	}
+	carve.SHA256State = state
+	return nil
+}
+
 func (svc *Service) validateCarveBlock(payload fleet.CarveBlockPayload, carve *fleet.CarveMetadata) error {
 	if payload.BlockId > carve.BlockCount-1 {
 		return fmt.Errorf("block_id exceeds expected max (%d): %d", carve.BlockCount-1, payload.BlockId)
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -10,6 +15,7 @@ import (
 	hostctx "github.com/fleetdm/fleet/v4/server/contexts/host"
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
 	"github.com/fleetdm/fleet/v4/server/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -623,3 +629,148 @@ func TestCarveCarveBlock(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, ms.NewBlockFuncInvoked)
 }
+
+func TestCarveCarveBlockHash(t *testing.T) {
+	sessionId := "foobar"
+	blocks := [][]byte{[]byte("first block "), []byte("second block"), []byte("last")}
+	metadata := &fleet.CarveMetadata{
+		ID:         2,
+		HostId:     3,
+		BlockCount: int64(len(blocks)),
+		BlockSize:  12,
+		CarveSize:  28,
+		RequestId:  "carve_request",
+		SessionId:  sessionId,
+		MaxBlock:   -1,
+	}
+	ms := new(mock.Store)
+	svc := &Service{carveStore: ms}
+	var stored fleet.CarveMetadata
+	ms.CarveBySessionIdFunc = func(ctx context.Context, sessionId string) (*fleet.CarveMetadata, error) {
+		// return a copy of the stored metadata, as a new request would
+		c := *metadata
+		return &c, nil
+	}
+	ms.NewBlockFunc = func(ctx context.Context, carve *fleet.CarveMetadata, blockId int64, data []byte) error {
+		// like the carve stores, NewBlock saves the metadata with the new max
+		// block
+		carve.MaxBlock = blockId
+		*metadata = *carve
+		stored = *carve
+		return nil
+	}
+
+	for i, data := range blocks {
+		err := svc.CarveBlock(context.Background(), fleet.CarveBlockPayload{
+			Data:      data,
+			RequestId: "carve_request",
+			SessionId: sessionId,
+			BlockId:   int64(i),
+		})
+		require.NoError(t, err)
+		if i < len(blocks)-1 {
+			require.Nil(t, stored.SHA256)
+			require.NotEmpty(t, stored.SHA256State)
+		}
+	}
+
+	sum := sha256.Sum256([]byte("first block second blocklast"))
+	require.NotNil(t, stored.SHA256)
+	assert.Equal(t, hex.EncodeToString(sum[:]), *stored.SHA256)
+	assert.Nil(t, stored.SHA256State)
+}
+
+func TestUpdateCarveHashMissingState(t *testing.T) {
+	// a carve started by an older Fleet version has no hash state, it is not
+	// hashed.
+	carve := &fleet.CarveMetadata{BlockCount: 2, MaxBlock: 0}
+	require.NoError(t, updateCarveHash(carve, 1, []byte("data")))
+	require.Nil(t, carve.SHA256)
+	require.Nil(t, carve.SHA256State)
+
+	carve.SHA256State = []byte("invalid")
+	require.Error(t, updateCarveHash(carve, 1, []byte("data")))
+	require.Nil(t, carve.SHA256)
+	require.Nil(t, carve.SHA256State)
+}
+
+func TestDownloadCarve(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	contents := "0123456789abcdefghijklmnopqrstuvwxyz"
+	carve := &fleet.CarveMetadata{
+		ID:         1,
+		Name:       "host-carve",
+		BlockCount: 4,
+		BlockSize:  10,
+		CarveSize:  int64(len(contents)),
+		MaxBlock:   3,
+		SHA256:     ptr.String("abcd"),
+	}
+	ds.CarveFunc = func(ctx context.Context, id int64) (*fleet.CarveMetadata, error) {
+		c := *carve
+		return &c, nil
+	}
+	var blockReads []int64
+	ds.GetBlockFunc = func(ctx context.Context, metadata *fleet.CarveMetadata, blockId int64) ([]byte, error) {
+		blockReads = append(blockReads, blockId)
+		end := (blockId + 1) * 10
+		if end > int64(len(contents)) {
+			end = int64(len(contents))
+		}
+		return []byte(contents[blockId*10 : end]), nil
+	}
+
+	// only global admin can read carves
+	_, _, err := svc.DownloadCarve(test.UserContext(ctx, test.UserNoRoles), 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), authz.ForbiddenErrorMessage)
+
+	adminCtx := test.UserContext(ctx, test.UserAdmin)
+	metadata, reader, err := svc.DownloadCarve(adminCtx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), metadata.Progress)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, contents, string(data))
+	assert.Equal(t, []int64{0, 1, 2, 3}, blockReads)
+
+	// serve a range spanning multiple blocks
+	blockReads = nil
+	_, reader, err = svc.DownloadCarve(adminCtx, 1)
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/api/latest/fleet/carves/1/download", nil)
+	req.Header.Set("Range", "bytes=8-21")
+	rec := httptest.NewRecorder()
+	downloadCarveResponse{carve: metadata, contents: reader, httpReq: req}.hijackRender(adminCtx, rec)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, contents[8:22], rec.Body.String())
+	assert.Equal(t, "bytes 8-21/36", rec.Header().Get("Content-Range"))
+	assert.Equal(t, `"abcd"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "application/x-tar", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=host-carve.tar`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, []int64{0, 1, 2}, blockReads)
+
+	// suffix range
+	_, reader, err = svc.DownloadCarve(adminCtx, 1)
+	require.NoError(t, err)
+	req.Header.Set("Range", "bytes=-3")
+	rec = httptest.NewRecorder()
+	downloadCarveResponse{carve: metadata, contents: reader, httpReq: req}.hijackRender(adminCtx, rec)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "xyz", rec.Body.String())
+
+	// incomplete, expired and failed carves can't be downloaded
+	carve.MaxBlock = 2
+	_, _, err = svc.DownloadCarve(adminCtx, 1)
+	require.ErrorContains(t, err, "carve is not complete: received 3 of 4 blocks")
+	carve.MaxBlock = 3
+	carve.Expired = true
+	_, _, err = svc.DownloadCarve(adminCtx, 1)
+	require.ErrorContains(t, err, "cannot download expired carve")
+	carve.Expired = false
+	carve.Error = ptr.String("boom")
+	_, _, err = svc.DownloadCarve(adminCtx, 1)
+	require.ErrorContains(t, err, "cannot download failed carve: boom")
+}
@@ -256,6 +256,17 @@ func (c *Client) ApplyGroup(
 		}
 	}
 
+	if len(specs.CPETranslations) > 0 {
+		if opts.DryRun {
+			logfn("[!] ignoring cpe translations, dry run mode only supported for 'config' and 'team' specs\n")
+		} else {
+			if err := c.ApplyCPETranslationRules(specs.CPETranslations); err != nil {
+				return fmt.Errorf("applying cpe translations: %w", err)
+			}
+			logfn("[+] applied %d cpe translations\n", len(specs.CPETranslations))
+		}
+	}
+
 	if len(specs.Policies) > 0 {
 		if opts.DryRun {
 			logfn("[!] ignoring policies, dry run mode only supported for 'config' and 'team' specs\n")
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+// ListActivityWebhookDeliveries retrieves the log of deliveries of activities
+// to the activities webhooks.
+func (c *Client) ListActivityWebhookDeliveries(query string) ([]*fleet.ActivityWebhookDelivery, error) {
+	verb, path := "GET", "/api/latest/fleet/activities/webhook_deliveries"
+	var responseBody listActivityWebhookDeliveriesResponse
+	err := c.authenticatedRequestWithQuery(nil, verb, path, &responseBody, query)
+	if err != nil {
+		return nil, err
+	}
+	return responseBody.Deliveries, nil
+}
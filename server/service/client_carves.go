@@ -158,8 +158,35 @@ func (r *carveReader) Read(p []byte) (n int, err error) {
 	return copyLen, nil
 }
 
-// DownloadCarve creates a Reader downloading a carve (by ID)
-func (c *Client) DownloadCarve(id int64) (io.Reader, error) {
+// DownloadCarve creates a Reader downloading a carve (by ID). The carve is
+// streamed by the server, or downloaded block by block from servers that
+// don't support streaming.
+func (c *Client) DownloadCarve(id int64) (io.ReadCloser, error) {
+	path := fmt.Sprintf("/api/latest/fleet/carves/%d/download", id)
+	response, err := c.AuthenticatedDo("GET", path, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.Body, nil
+	case http.StatusNotFound:
+		// the carve exists (checked by the caller) but the endpoint doesn't,
+		// fallback to downloading the blocks.
+		response.Body.Close()
+		return c.downloadCarveBlocks(id)
+	default:
+		defer response.Body.Close()
+		return nil, fmt.Errorf(
+			"download carve received status %d: %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+}
+
+func (c *Client) downloadCarveBlocks(id int64) (io.ReadCloser, error) {
 	path := fmt.Sprintf("/api/latest/fleet/carves/%d", id)
 	response, err := c.AuthenticatedDo("GET", path, "", nil)
 	if err != nil {
@@ -186,5 +213,5 @@ func (c *Client) DownloadCarve(id int64) (io.Reader, error) {
 
 	reader := newCarveReader(responseBody.Carve, c)
 
-	return reader, nil
+	return io.NopCloser(reader), nil
 }
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"net/url"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+// ApplyCPETranslationRules sends the list of custom CPE translation rules to
+// be applied (upserted) to the Fleet instance.
+func (c *Client) ApplyCPETranslationRules(specs []*fleet.CPETranslationRuleSpec) error {
+	req := applyCPETranslationRuleSpecsRequest{Specs: specs}
+	verb, path := "POST", "/api/latest/fleet/spec/cpe_translations"
+	var responseBody applyCPETranslationRuleSpecsResponse
+	return c.authenticatedRequest(req, verb, path, &responseBody)
+}
+
+// GetCPETranslationRules retrieves the list of all custom CPE translation
+// rules.
+func (c *Client) GetCPETranslationRules() ([]*fleet.CPETranslationRuleSpec, error) {
+	verb, path := "GET", "/api/latest/fleet/spec/cpe_translations"
+	var responseBody getCPETranslationRuleSpecsResponse
+	err := c.authenticatedRequest(nil, verb, path, &responseBody)
+	return responseBody.Specs, err
+}
+
+// DeleteCPETranslationRule deletes the custom CPE translation rule with the
+// matching name.
+func (c *Client) DeleteCPETranslationRule(name string) error {
+	verb, path := "DELETE", "/api/latest/fleet/cpe_translations/"+url.PathEscape(name)
+	var responseBody deleteCPETranslationRuleResponse
+	return c.authenticatedRequest(nil, verb, path, &responseBody)
+}
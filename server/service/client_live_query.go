@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -180,3 +181,15 @@ func (c *Client) LiveQueryWithContext(ctx context.Context, query string, labels
 
 	return resHandler, nil
 }
+
+// ListCampaignResults returns a page of the persisted results of the live
+// query campaign, along with the campaign.
+func (c *Client) ListCampaignResults(campaignID uint, page, perPage uint) (*fleet.DistributedQueryCampaign, []*fleet.DistributedQueryResult, *fleet.PaginationMetadata, error) {
+	verb, path := "GET", fmt.Sprintf("/api/latest/fleet/campaigns/%d/results", campaignID)
+	query := fmt.Sprintf("page=%d&per_page=%d", page, perPage)
+	var responseBody listCampaignResultsResponse
+	if err := c.authenticatedRequestWithQuery(nil, verb, path, &responseBody, query); err != nil {
+		return nil, nil, nil, err
+	}
+	return responseBody.Campaign, responseBody.Results, responseBody.Meta, nil
+}
@@ -1,5 +1,7 @@
 package service
 
+import "github.com/fleetdm/fleet/v4/server/fleet"
+
 func (c *Client) CreateGlobalPolicy(name, query, description, resolution, platform string) error {
 	req := globalPolicyRequest{
 		Name:        name,
@@ -12,3 +14,15 @@ func (c *Client) CreateGlobalPolicy(name, query, description, resolution, platfo
 	var responseBody globalPolicyResponse
 	return c.authenticatedRequest(req, verb, path, &responseBody)
 }
+
+// ListPolicyHistory retrieves the daily snapshots of the policies' passing and
+// failing host counts.
+func (c *Client) ListPolicyHistory(query string) ([]*fleet.PolicyStatsSnapshot, error) {
+	verb, path := "GET", "/api/latest/fleet/policies/history"
+	var responseBody listPolicyHistoryResponse
+	err := c.authenticatedRequestWithQuery(nil, verb, path, &responseBody, query)
+	if err != nil {
+		return nil, err
+	}
+	return responseBody.History, nil
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/fleetdm/fleet/v4/server/authz"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/nvd"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Apply CPE translation rule specs
+////////////////////////////////////////////////////////////////////////////////
+
+type applyCPETranslationRuleSpecsRequest struct {
+	Specs []*fleet.CPETranslationRuleSpec `json:"specs"`
+}
+
+type applyCPETranslationRuleSpecsResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r applyCPETranslationRuleSpecsResponse) error() error { return r.Err }
+
+func applyCPETranslationRuleSpecsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*applyCPETranslationRuleSpecsRequest)
+	if err := svc.ApplyCPETranslationRuleSpecs(ctx, req.Specs); err != nil {
+		return applyCPETranslationRuleSpecsResponse{Err: err}, nil
+	}
+	return applyCPETranslationRuleSpecsResponse{}, nil
+}
+
+func (svc *Service) ApplyCPETranslationRuleSpecs(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error {
+	if err := svc.authz.Authorize(ctx, &fleet.CPETranslationRuleSpec{}, fleet.ActionWrite); err != nil {
+		return err
+	}
+
+	if err := validateCPETranslationRuleSpecs(specs); err != nil {
+		return err
+	}
+
+	if err := svc.ds.ApplyCPETranslationRuleSpecs(ctx, specs); err != nil {
+		return ctxerr.Wrap(ctx, err, "apply cpe translation rules")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeAppliedSpecCPETranslationRule{
+		Rules: specs,
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for cpe translation rule specs")
+	}
+	return nil
+}
+
+func validateCPETranslationRuleSpecs(specs []*fleet.CPETranslationRuleSpec) error {
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return fleet.NewInvalidArgumentError("specs", err.Error())
+		}
+		if names[spec.Name] {
+			return fleet.NewInvalidArgumentError("specs", "duplicate cpe translation rule name: "+spec.Name)
+		}
+		names[spec.Name] = true
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Get CPE translation rule specs
+////////////////////////////////////////////////////////////////////////////////
+
+type getCPETranslationRuleSpecsResponse struct {
+	Specs []*fleet.CPETranslationRuleSpec `json:"specs"`
+	Err   error                           `json:"error,omitempty"`
+}
+
+func (r getCPETranslationRuleSpecsResponse) error() error { return r.Err }
+
+func getCPETranslationRuleSpecsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	specs, err := svc.GetCPETranslationRuleSpecs(ctx)
+	if err != nil {
+		return getCPETranslationRuleSpecsResponse{Err: err}, nil
+	}
+	return getCPETranslationRuleSpecsResponse{Specs: specs}, nil
+}
+
+func (svc *Service) GetCPETranslationRuleSpecs(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.CPETranslationRuleSpec{}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	specs, err := svc.ds.GetCPETranslationRuleSpecs(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get cpe translation rules")
+	}
+	if specs == nil {
+		specs = []*fleet.CPETranslationRuleSpec{}
+	}
+	return specs, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete CPE translation rule
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteCPETranslationRuleRequest struct {
+	Name string `url:"name"`
+}
+
+type deleteCPETranslationRuleResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteCPETranslationRuleResponse) error() error { return r.Err }
+
+func deleteCPETranslationRuleEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*deleteCPETranslationRuleRequest)
+	if err := svc.DeleteCPETranslationRule(ctx, req.Name); err != nil {
+		return deleteCPETranslationRuleResponse{Err: err}, nil
+	}
+	return deleteCPETranslationRuleResponse{}, nil
+}
+
+func (svc *Service) DeleteCPETranslationRule(ctx context.Context, name string) error {
+	if err := svc.authz.Authorize(ctx, &fleet.CPETranslationRuleSpec{}, fleet.ActionWrite); err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeleteCPETranslationRule(ctx, name); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete cpe translation rule")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeDeletedCPETranslationRule{
+		Name: name,
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for deleted cpe translation rule")
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Dry run CPE translation
+////////////////////////////////////////////////////////////////////////////////
+
+type dryRunCPETranslationRequest struct {
+	Software fleet.Software `json:"software"`
+	// Specs are evaluated as if they were applied.
+	Specs []*fleet.CPETranslationRuleSpec `json:"specs"`
+}
+
+type dryRunCPETranslationResponse struct {
+	Resolution *fleet.CPEResolution `json:"resolution,omitempty"`
+	Err        error                `json:"error,omitempty"`
+}
+
+func (r dryRunCPETranslationResponse) error() error { return r.Err }
+
+func dryRunCPETranslationEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*dryRunCPETranslationRequest)
+	res, err := svc.DryRunCPETranslation(ctx, req.Software, req.Specs)
+	if err != nil {
+		return dryRunCPETranslationResponse{Err: err}, nil
+	}
+	return dryRunCPETranslationResponse{Resolution: res}, nil
+}
+
+func (svc *Service) DryRunCPETranslation(ctx context.Context, software fleet.Software, specs []*fleet.CPETranslationRuleSpec) (*fleet.CPEResolution, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.CPETranslationRuleSpec{}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	if software.Name == "" {
+		return nil, fleet.NewInvalidArgumentError("software", "software name is required")
+	}
+	if err := validateCPETranslationRuleSpecs(specs); err != nil {
+		return nil, err
+	}
+
+	vulnPath := svc.config.Vulnerabilities.DatabasesPath
+	if vulnPath == "" {
+		appConfig, err := svc.ds.AppConfig(ctx)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "get app config")
+		}
+		vulnPath = appConfig.VulnerabilitySettings.DatabasesPath
+	}
+	if vulnPath == "" {
+		return nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{
+			Message: "vulnerability processing is not configured, the vulnerabilities databases path is empty",
+		})
+	}
+
+	rules, err := svc.ds.GetCPETranslationRuleSpecs(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get cpe translation rules")
+	}
+
+	res, err := nvd.ResolveCPE(vulnPath, &software, mergeCPETranslationRuleSpecs(rules, specs))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{
+				Message: "the CPE database was not found on this Fleet server, it is downloaded when vulnerabilities are processed",
+			})
+		}
+		return nil, ctxerr.Wrap(ctx, err, "resolve cpe")
+	}
+	return res, nil
+}
+
+// mergeCPETranslationRuleSpecs returns the rules after applying the specs:
+// the rules with the same name are replaced in place, the others are added
+// at the end.
+func mergeCPETranslationRuleSpecs(rules, specs []*fleet.CPETranslationRuleSpec) []*fleet.CPETranslationRuleSpec {
+	merged := make([]*fleet.CPETranslationRuleSpec, len(rules))
+	copy(merged, rules)
+
+	byName := make(map[string]int, len(merged))
+	for i, r := range merged {
+		byName[r.Name] = i
+	}
+	for _, spec := range specs {
+		if i, ok := byName[spec.Name]; ok {
+			merged[i] = spec
+			continue
+		}
+		byName[spec.Name] = len(merged)
+		merged = append(merged, spec)
+	}
+	return merged
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/config"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPETranslationRulesAuth(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{SkipCreateTestUsers: true})
+
+	ds.ApplyCPETranslationRuleSpecsFunc = func(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error { return nil }
+	ds.GetCPETranslationRuleSpecsFunc = func(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) { return nil, nil }
+	ds.DeleteCPETranslationRuleFunc = func(ctx context.Context, name string) error { return nil }
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error { return nil }
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) { return &fleet.AppConfig{}, nil }
+
+	testCases := []struct {
+		name            string
+		user            *fleet.User
+		shouldFailWrite bool
+		shouldFailRead  bool
+	}{
+		{"global admin", test.UserAdmin, false, false},
+		{"global maintainer", test.UserMaintainer, false, false},
+		{"global observer", test.UserObserver, true, false},
+		{"team admin", test.UserTeamAdminTeam1, true, true},
+		{"team observer", test.UserTeamObserverTeam1, true, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := test.UserContext(ctx, tt.user)
+
+			err := svc.ApplyCPETranslationRuleSpecs(ctx, nil)
+			checkAuthErr(t, tt.shouldFailWrite, err)
+			err = svc.DeleteCPETranslationRule(ctx, "acme")
+			checkAuthErr(t, tt.shouldFailWrite, err)
+
+			_, err = svc.GetCPETranslationRuleSpecs(ctx)
+			checkAuthErr(t, tt.shouldFailRead, err)
+			// vulnerability processing is not configured, which is only reported
+			// to authorized users
+			_, err = svc.DryRunCPETranslation(ctx, fleet.Software{Name: "Acme VPN.app"}, nil)
+			if tt.shouldFailRead {
+				checkAuthErr(t, true, err)
+			} else {
+				require.ErrorContains(t, err, "vulnerability processing is not configured")
+			}
+		})
+	}
+}
+
+func TestApplyCPETranslationRuleSpecs(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{SkipCreateTestUsers: true})
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	var applied []*fleet.CPETranslationRuleSpec
+	ds.ApplyCPETranslationRuleSpecsFunc = func(ctx context.Context, specs []*fleet.CPETranslationRuleSpec) error {
+		applied = specs
+		return nil
+	}
+	var activity fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, a fleet.ActivityDetails) error {
+		activity = a
+		return nil
+	}
+
+	spec := &fleet.CPETranslationRuleSpec{
+		Name:     "acme-vpn",
+		Software: fleet.CPETranslationRuleSoftware{Name: []string{"/^Acme VPN/"}},
+		Filter:   fleet.CPETranslationRuleFilter{Product: []string{"vpn_client"}, Vendor: []string{"acme"}},
+	}
+
+	err := svc.ApplyCPETranslationRuleSpecs(ctx, []*fleet.CPETranslationRuleSpec{{Name: "empty"}})
+	require.ErrorContains(t, err, "at least one of software name")
+	err = svc.ApplyCPETranslationRuleSpecs(ctx, []*fleet.CPETranslationRuleSpec{spec, spec})
+	require.ErrorContains(t, err, "duplicate cpe translation rule name: acme-vpn")
+	require.Nil(t, applied)
+
+	require.NoError(t, svc.ApplyCPETranslationRuleSpecs(ctx, []*fleet.CPETranslationRuleSpec{spec}))
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{spec}, applied)
+	require.Equal(t, fleet.ActivityTypeAppliedSpecCPETranslationRule{Rules: applied}, activity)
+
+	ds.DeleteCPETranslationRuleFunc = func(ctx context.Context, name string) error { return nil }
+	require.NoError(t, svc.DeleteCPETranslationRule(ctx, "acme-vpn"))
+	require.Equal(t, fleet.ActivityTypeDeletedCPETranslationRule{Name: "acme-vpn"}, activity)
+}
+
+func TestDryRunCPETranslation(t *testing.T) {
+	ds := new(mock.Store)
+	vulnPath := t.TempDir()
+	cfg := config.TestConfig()
+	cfg.Vulnerabilities.DatabasesPath = vulnPath
+	svc, ctx := newTestServiceWithConfig(t, ds, cfg, nil, nil, &TestServerOpts{SkipCreateTestUsers: true})
+	ctx = test.UserContext(ctx, test.UserObserver)
+
+	ds.GetCPETranslationRuleSpecsFunc = func(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) {
+		return []*fleet.CPETranslationRuleSpec{{
+			Name:     "acme-tools",
+			Software: fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"com.acme.tools"}},
+			Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+		}}, nil
+	}
+
+	_, err := svc.DryRunCPETranslation(ctx, fleet.Software{}, nil)
+	require.ErrorContains(t, err, "software name is required")
+
+	// the CPE database was not downloaded
+	_, err = svc.DryRunCPETranslation(ctx, fleet.Software{Name: "Tools.app", BundleIdentifier: "com.acme.tools"}, nil)
+	var badReq *fleet.BadRequestError
+	require.ErrorAs(t, err, &badReq)
+	require.Contains(t, badReq.Message, "CPE database was not found")
+}
+
+func TestMergeCPETranslationRuleSpecs(t *testing.T) {
+	a := &fleet.CPETranslationRuleSpec{Name: "a"}
+	b := &fleet.CPETranslationRuleSpec{Name: "b"}
+	b2 := &fleet.CPETranslationRuleSpec{Name: "b", Description: "updated"}
+	c := &fleet.CPETranslationRuleSpec{Name: "c"}
+
+	rules := []*fleet.CPETranslationRuleSpec{a, b}
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{a, b}, mergeCPETranslationRuleSpecs(rules, nil))
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{a, b2, c}, mergeCPETranslationRuleSpecs(rules, []*fleet.CPETranslationRuleSpec{c, b2}))
+	// the rules are not modified
+	require.Equal(t, []*fleet.CPETranslationRuleSpec{a, b}, rules)
+}
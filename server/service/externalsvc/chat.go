@@ -0,0 +1,17 @@
+package externalsvc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// postChatWebhook sends the JSON-encoded payload to the incoming webhook url
+// of a chat service. The request is retried on temporary failures.
+func postChatWebhook(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return sendWebhookRequest(ctx, client, http.MethodPost, url, nil, body)
+}
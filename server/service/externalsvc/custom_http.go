@@ -0,0 +1,104 @@
+package externalsvc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+)
+
+// CustomHTTP is a client to be used to send requests to an arbitrary HTTP
+// endpoint, e.g. to create tickets in a ticketing system that Fleet does not
+// integrate with natively.
+type CustomHTTP struct {
+	client *http.Client
+	opts   CustomHTTPOptions
+}
+
+// CustomHTTPOptions defines the options to configure a custom HTTP client.
+type CustomHTTPOptions struct {
+	// URL is the URL of the endpoint to send the requests to.
+	URL string
+	// Method is the HTTP method of the requests, POST if empty.
+	Method string
+	// Headers are the additional headers to set on the requests, e.g. for
+	// authentication.
+	Headers map[string]string
+}
+
+// CustomHTTPMethods is the list of HTTP methods supported by custom HTTP
+// integrations.
+var CustomHTTPMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+
+// NewCustomHTTPClient returns a client to use to send requests to the
+// endpoint of a custom HTTP integration.
+func NewCustomHTTPClient(opts *CustomHTTPOptions) (*CustomHTTP, error) {
+	if err := validateWebhookURL(opts.URL); err != nil {
+		return nil, err
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodPost
+	}
+	var validMethod bool
+	for _, m := range CustomHTTPMethods {
+		if opts.Method == m {
+			validMethod = true
+			break
+		}
+	}
+	if !validMethod {
+		return nil, fmt.Errorf("invalid method %s, must be one of %s", opts.Method, strings.Join(CustomHTTPMethods, ", "))
+	}
+	return &CustomHTTP{
+		client: fleethttp.NewClient(fleethttp.WithTimeout(defaultChatTimeout)),
+		opts:   *opts,
+	}, nil
+}
+
+// SendCustomHTTPRequest sends the body to the endpoint targeted by the client.
+// The Content-Type of the request is application/json unless it is overridden
+// by the configured headers.
+func (c *CustomHTTP) SendCustomHTTPRequest(ctx context.Context, body []byte) error {
+	return sendWebhookRequest(ctx, c.client, c.opts.Method, c.opts.URL, c.opts.Headers, body)
+}
+
+// CustomHTTPConfigMatches returns true if the custom HTTP client has been
+// configured using those same options. The CustomHTTP in the name is required
+// so that the interface method is not the same as the one for other
+// integrations (for the failer client wrapper).
+func (c *CustomHTTP) CustomHTTPConfigMatches(opts *CustomHTTPOptions) bool {
+	method := opts.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	if c.opts.URL != opts.URL || c.opts.Method != method || len(c.opts.Headers) != len(opts.Headers) {
+		return false
+	}
+	for k, v := range opts.Headers {
+		if cur, ok := c.opts.Headers[k]; !ok || cur != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCustomHTTPBodyTemplate parses the body template of a custom HTTP
+// integration. In addition to the standard text/template functions, the
+// template can use the "json" function to render a value as JSON (e.g. to
+// safely embed a string in a JSON body).
+func ParseCustomHTTPBodyTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("body template is empty")
+	}
+	return template.New("").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+}
@@ -0,0 +1,119 @@
+package externalsvc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomHTTP(t *testing.T) {
+	var (
+		countCalls int
+		gotMethod  string
+		gotHeaders http.Header
+		gotBody    []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countCalls++
+		gotMethod = r.Method
+		gotHeaders = r.Header
+
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case "/unauthorized":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("bad credentials"))
+			return
+		}
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		gotBody = b
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewCustomHTTPClient(&CustomHTTPOptions{URL: "ftp://example.com"})
+		require.Error(t, err)
+		_, err = NewCustomHTTPClient(&CustomHTTPOptions{URL: srv.URL, Method: "GET"})
+		require.ErrorContains(t, err, "invalid method GET")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewCustomHTTPClient(&CustomHTTPOptions{
+			URL:     srv.URL + "/tickets",
+			Method:  http.MethodPut,
+			Headers: map[string]string{"Authorization": "Bearer abc", "Content-Type": "text/plain"},
+		})
+		require.NoError(t, err)
+		err = client.SendCustomHTTPRequest(context.Background(), []byte("hello"))
+		require.NoError(t, err)
+		require.Equal(t, 1, countCalls)
+		require.Equal(t, http.MethodPut, gotMethod)
+		require.Equal(t, "Bearer abc", gotHeaders.Get("Authorization"))
+		require.Equal(t, "text/plain", gotHeaders.Get("Content-Type"))
+		require.Equal(t, []byte("hello"), gotBody)
+	})
+
+	t.Run("default method and content type", func(t *testing.T) {
+		client, err := NewCustomHTTPClient(&CustomHTTPOptions{URL: srv.URL + "/tickets"})
+		require.NoError(t, err)
+		err = client.SendCustomHTTPRequest(context.Background(), []byte(`{}`))
+		require.NoError(t, err)
+		require.Equal(t, http.MethodPost, gotMethod)
+		require.Equal(t, "application/json", gotHeaders.Get("Content-Type"))
+	})
+
+	t.Run("retryable failure", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewCustomHTTPClient(&CustomHTTPOptions{URL: srv.URL + "/fail"})
+		require.NoError(t, err)
+		err = client.SendCustomHTTPRequest(context.Background(), []byte(`{}`))
+		require.ErrorContains(t, err, "status 502")
+		require.Equal(t, maxRetries+1, countCalls)
+	})
+
+	t.Run("non-retryable failure", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewCustomHTTPClient(&CustomHTTPOptions{URL: srv.URL + "/unauthorized"})
+		require.NoError(t, err)
+		err = client.SendCustomHTTPRequest(context.Background(), []byte(`{}`))
+		require.ErrorContains(t, err, "bad credentials")
+		require.Equal(t, 1, countCalls)
+	})
+
+	t.Run("config matches", func(t *testing.T) {
+		opts := &CustomHTTPOptions{URL: srv.URL, Headers: map[string]string{"X-A": "1"}}
+		client, err := NewCustomHTTPClient(opts)
+		require.NoError(t, err)
+		require.True(t, client.CustomHTTPConfigMatches(&CustomHTTPOptions{URL: srv.URL, Headers: map[string]string{"X-A": "1"}}))
+		require.True(t, client.CustomHTTPConfigMatches(&CustomHTTPOptions{URL: srv.URL, Method: "POST", Headers: map[string]string{"X-A": "1"}}))
+		require.False(t, client.CustomHTTPConfigMatches(&CustomHTTPOptions{URL: srv.URL, Headers: map[string]string{"X-A": "2"}}))
+		require.False(t, client.CustomHTTPConfigMatches(&CustomHTTPOptions{URL: srv.URL, Method: "PUT", Headers: map[string]string{"X-A": "1"}}))
+		require.False(t, client.CustomHTTPConfigMatches(&CustomHTTPOptions{URL: srv.URL}))
+	})
+}
+
+func TestParseCustomHTTPBodyTemplate(t *testing.T) {
+	_, err := ParseCustomHTTPBodyTemplate("  ")
+	require.Error(t, err)
+
+	_, err = ParseCustomHTTPBodyTemplate("{{ .Name ")
+	require.Error(t, err)
+
+	tpl, err := ParseCustomHTTPBodyTemplate(`{"title": {{ json .Name }}}`)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	err = tpl.Execute(&buf, struct{ Name string }{Name: `a "quoted" name`})
+	require.NoError(t, err)
+	require.Equal(t, `{"title": "a \"quoted\" name"}`, buf.String())
+}
@@ -8,4 +8,5 @@ const (
 	maxRetries           = 5
 	retryBackoff         = 300 * time.Millisecond
 	maxWaitForRetryAfter = 10 * time.Second
+	defaultChatTimeout   = 30 * time.Second
 )
@@ -94,6 +94,101 @@ func (j *Jira) CreateJiraIssue(ctx context.Context, issue *jira.Issue) (*jira.Is
 	return createdIssue, nil
 }
 
+// ReopenJiraIssue adds a comment to the issue identified by issueKey and, if
+// that issue is in a "done" status, transitions it back to a "to do" (or "in
+// progress" if there is no such transition) status.
+func (j *Jira) ReopenJiraIssue(ctx context.Context, issueKey, comment string) error {
+	if err := j.addComment(ctx, issueKey, comment); err != nil {
+		return err
+	}
+
+	category, err := j.statusCategory(ctx, issueKey)
+	if err != nil {
+		return err
+	}
+	if category != jira.StatusCategoryComplete {
+		return nil
+	}
+	return j.transition(ctx, issueKey, jira.StatusCategoryToDo, jira.StatusCategoryInProgress)
+}
+
+// ResolveJiraIssue adds a comment to the issue identified by issueKey and
+// transitions it to a "done" status, unless it already is in such a status.
+func (j *Jira) ResolveJiraIssue(ctx context.Context, issueKey, comment string) error {
+	if err := j.addComment(ctx, issueKey, comment); err != nil {
+		return err
+	}
+
+	category, err := j.statusCategory(ctx, issueKey)
+	if err != nil {
+		return err
+	}
+	if category == jira.StatusCategoryComplete {
+		return nil
+	}
+	return j.transition(ctx, issueKey, jira.StatusCategoryComplete)
+}
+
+func (j *Jira) addComment(ctx context.Context, issueKey, comment string) error {
+	op := func() (*jira.Response, error) {
+		_, resp, err := j.client.Issue.AddCommentWithContext(ctx, issueKey, &jira.Comment{Body: comment})
+		return resp, err
+	}
+	return doWithRetry(op)
+}
+
+// statusCategory returns the key of the status category of the issue, e.g.
+// "new", "indeterminate" or "done".
+func (j *Jira) statusCategory(ctx context.Context, issueKey string) (string, error) {
+	var issue *jira.Issue
+	op := func() (*jira.Response, error) {
+		var (
+			err  error
+			resp *jira.Response
+		)
+		issue, resp, err = j.client.Issue.GetWithContext(ctx, issueKey, &jira.GetQueryOptions{Fields: "status"})
+		return resp, err
+	}
+	if err := doWithRetry(op); err != nil {
+		return "", err
+	}
+	if issue.Fields == nil || issue.Fields.Status == nil {
+		return "", nil
+	}
+	return issue.Fields.Status.StatusCategory.Key, nil
+}
+
+// transition applies the first transition of the issue leading to a status of
+// one of the categories, in order of preference. It is a no-op if no
+// transition leads to such a status, as this depends on the workflow of the
+// project.
+func (j *Jira) transition(ctx context.Context, issueKey string, categories ...string) error {
+	var transitions []jira.Transition
+	op := func() (*jira.Response, error) {
+		var (
+			err  error
+			resp *jira.Response
+		)
+		transitions, resp, err = j.client.Issue.GetTransitionsWithContext(ctx, issueKey)
+		return resp, err
+	}
+	if err := doWithRetry(op); err != nil {
+		return err
+	}
+
+	for _, category := range categories {
+		for _, tr := range transitions {
+			if tr.To.StatusCategory.Key != category {
+				continue
+			}
+			return doWithRetry(func() (*jira.Response, error) {
+				return j.client.Issue.DoTransitionWithContext(ctx, issueKey, tr.ID)
+			})
+		}
+	}
+	return nil
+}
+
 // JiraConfigMatches returns true if the jira client has been configured using
 // those same options. The Jira in the name is required so that the interface
 // method is not the same as the one for Zendesk (for mock or wrapper
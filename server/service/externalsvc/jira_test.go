@@ -2,6 +2,7 @@ package externalsvc
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -123,3 +124,75 @@ func TestJira(t *testing.T) {
 		require.Equal(t, 1, countCalls)
 	})
 }
+
+func TestJiraReopenResolve(t *testing.T) {
+	var category string
+	var calls []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		var resp string
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/ED-24/comment":
+			w.WriteHeader(http.StatusCreated)
+			resp = `{"id": "1", "body": "comment"}`
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/ED-24":
+			resp = `{"id": "10000", "key": "ED-24", "fields": {"status": {"name": "x", "statusCategory": {"key": "` + category + `"}}}}`
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/ED-24/transitions":
+			resp = `{"transitions": [
+				{"id": "11", "name": "Start", "to": {"statusCategory": {"key": "indeterminate"}}},
+				{"id": "21", "name": "Reopen", "to": {"statusCategory": {"key": "new"}}},
+				{"id": "31", "name": "Done", "to": {"statusCategory": {"key": "done"}}}
+			]}`
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/ED-24/transitions":
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			calls[len(calls)-1] += " " + string(body)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(resp))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	client, err := NewJiraClient(&JiraOptions{BaseURL: srv.URL})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	cases := []struct {
+		desc     string
+		category string
+		fn       func(ctx context.Context, key, comment string) error
+		wantTr   string
+	}{
+		{"reopen open issue", "new", client.ReopenJiraIssue, ""},
+		{"reopen in progress issue", "indeterminate", client.ReopenJiraIssue, ""},
+		{"reopen done issue", "done", client.ReopenJiraIssue, `"id":"21"`},
+		{"resolve open issue", "new", client.ResolveJiraIssue, `"id":"31"`},
+		{"resolve done issue", "done", client.ResolveJiraIssue, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			calls = calls[:0]
+			category = c.category
+
+			err := c.fn(ctx, "ED-24", "comment")
+			require.NoError(t, err)
+			require.Equal(t, "POST /rest/api/2/issue/ED-24/comment", calls[0])
+			require.Equal(t, "GET /rest/api/2/issue/ED-24", calls[1])
+			if c.wantTr == "" {
+				require.Len(t, calls, 2)
+				return
+			}
+			require.Len(t, calls, 4)
+			require.Equal(t, "GET /rest/api/2/issue/ED-24/transitions", calls[2])
+			require.Contains(t, calls[3], "POST /rest/api/2/issue/ED-24/transitions")
+			require.Contains(t, calls[3], c.wantTr)
+		})
+	}
+}
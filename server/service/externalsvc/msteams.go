@@ -0,0 +1,63 @@
+package externalsvc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+)
+
+// MicrosoftTeams is a Microsoft Teams client to be used to send messages to a
+// Microsoft Teams channel via an incoming webhook.
+type MicrosoftTeams struct {
+	client *http.Client
+	opts   MicrosoftTeamsOptions
+}
+
+// MicrosoftTeamsOptions defines the options to configure a Microsoft Teams
+// client.
+type MicrosoftTeamsOptions struct {
+	// URL is the URL of the Microsoft Teams incoming webhook.
+	URL string
+}
+
+// MicrosoftTeamsMessage is a message sent to a Microsoft Teams incoming
+// webhook, using the MessageCard format. The text is formatted using
+// markdown, see
+// https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference.
+type MicrosoftTeamsMessage struct {
+	Type    string `json:"@type"`
+	Context string `json:"@context"`
+	Summary string `json:"summary"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+}
+
+// NewMicrosoftTeamsClient returns a Microsoft Teams client to use to send
+// messages to the Microsoft Teams incoming webhook.
+func NewMicrosoftTeamsClient(opts *MicrosoftTeamsOptions) (*MicrosoftTeams, error) {
+	if err := validateWebhookURL(opts.URL); err != nil {
+		return nil, err
+	}
+	return &MicrosoftTeams{
+		client: fleethttp.NewClient(fleethttp.WithTimeout(defaultChatTimeout)),
+		opts:   *opts,
+	}, nil
+}
+
+// PostMicrosoftTeamsMessage sends the message to the Microsoft Teams incoming
+// webhook targeted by the client.
+func (m *MicrosoftTeams) PostMicrosoftTeamsMessage(ctx context.Context, msg *MicrosoftTeamsMessage) error {
+	msg.Type = "MessageCard"
+	msg.Context = "https://schema.org/extensions"
+	if msg.Summary == "" {
+		msg.Summary = msg.Title
+	}
+	return postChatWebhook(ctx, m.client, m.opts.URL, msg)
+}
+
+// MicrosoftTeamsConfigMatches returns true if the Microsoft Teams client has
+// been configured using those same options.
+func (m *MicrosoftTeams) MicrosoftTeamsConfigMatches(opts *MicrosoftTeamsOptions) bool {
+	return m.opts == *opts
+}
@@ -0,0 +1,56 @@
+package externalsvc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMicrosoftTeams(t *testing.T) {
+	var (
+		countCalls int
+		gotMsg     map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countCalls++
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotMsg))
+		_, _ = w.Write([]byte("1"))
+	}))
+	defer srv.Close()
+
+	t.Run("failure", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewMicrosoftTeamsClient(&MicrosoftTeamsOptions{URL: srv.URL + "/fail"})
+		require.NoError(t, err)
+		err = client.PostMicrosoftTeamsMessage(context.Background(), &MicrosoftTeamsMessage{Title: "test"})
+		require.Error(t, err)
+		require.Equal(t, maxRetries+1, countCalls)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		countCalls = 0
+		opts := &MicrosoftTeamsOptions{URL: srv.URL + "/ok"}
+		client, err := NewMicrosoftTeamsClient(opts)
+		require.NoError(t, err)
+		require.True(t, client.MicrosoftTeamsConfigMatches(opts))
+
+		err = client.PostMicrosoftTeamsMessage(context.Background(), &MicrosoftTeamsMessage{Title: "title", Text: "text"})
+		require.NoError(t, err)
+		require.Equal(t, 1, countCalls)
+		require.Equal(t, map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "title",
+			"title":    "title",
+			"text":     "text",
+		}, gotMsg)
+	})
+}
@@ -0,0 +1,54 @@
+package externalsvc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+)
+
+// Slack is a Slack client to be used to send messages to a Slack channel via
+// an incoming webhook.
+type Slack struct {
+	client *http.Client
+	opts   SlackOptions
+}
+
+// SlackOptions defines the options to configure a Slack client.
+type SlackOptions struct {
+	// URL is the URL of the Slack incoming webhook.
+	URL string
+}
+
+// SlackMessage is a message sent to a Slack incoming webhook. The text is
+// formatted using Slack's mrkdwn syntax, see
+// https://api.slack.com/reference/surfaces/formatting.
+type SlackMessage struct {
+	Text string `json:"text"`
+}
+
+// NewSlackClient returns a Slack client to use to send messages to the Slack
+// incoming webhook.
+func NewSlackClient(opts *SlackOptions) (*Slack, error) {
+	if err := validateWebhookURL(opts.URL); err != nil {
+		return nil, err
+	}
+	return &Slack{
+		client: fleethttp.NewClient(fleethttp.WithTimeout(defaultChatTimeout)),
+		opts:   *opts,
+	}, nil
+}
+
+// PostSlackMessage sends the message to the Slack incoming webhook targeted
+// by the Slack client.
+func (s *Slack) PostSlackMessage(ctx context.Context, msg *SlackMessage) error {
+	return postChatWebhook(ctx, s.client, s.opts.URL, msg)
+}
+
+// SlackConfigMatches returns true if the Slack client has been configured
+// using those same options. The Slack in the name is required so that the
+// interface method is not the same as the one for other integrations (for
+// mock or wrapper implementations).
+func (s *Slack) SlackConfigMatches(opts *SlackOptions) bool {
+	return s.opts == *opts
+}
@@ -0,0 +1,91 @@
+package externalsvc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlack(t *testing.T) {
+	var (
+		countCalls int
+		gotMsg     SlackMessage
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countCalls++
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case "/badrequest":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid_payload"))
+			return
+		case "/retrysmall":
+			if countCalls == 1 {
+				w.Header().Add("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotMsg))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := NewSlackClient(&SlackOptions{URL: "not a url"})
+		require.Error(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewSlackClient(&SlackOptions{URL: srv.URL + "/fail"})
+		require.NoError(t, err)
+		err = client.PostSlackMessage(context.Background(), &SlackMessage{Text: "test"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status 500")
+		require.Equal(t, maxRetries+1, countCalls)
+	})
+
+	t.Run("non-retryable failure", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewSlackClient(&SlackOptions{URL: srv.URL + "/badrequest"})
+		require.NoError(t, err)
+		err = client.PostSlackMessage(context.Background(), &SlackMessage{Text: "test"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid_payload")
+		require.Equal(t, 1, countCalls)
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		countCalls = 0
+		client, err := NewSlackClient(&SlackOptions{URL: srv.URL + "/retrysmall"})
+		require.NoError(t, err)
+		err = client.PostSlackMessage(context.Background(), &SlackMessage{Text: "retried"})
+		require.NoError(t, err)
+		require.Equal(t, 2, countCalls)
+		require.Equal(t, "retried", gotMsg.Text)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		countCalls = 0
+		opts := &SlackOptions{URL: srv.URL + "/ok"}
+		client, err := NewSlackClient(opts)
+		require.NoError(t, err)
+		require.True(t, client.SlackConfigMatches(opts))
+		require.False(t, client.SlackConfigMatches(&SlackOptions{URL: srv.URL + "/other"}))
+
+		err = client.PostSlackMessage(context.Background(), &SlackMessage{Text: "hello"})
+		require.NoError(t, err)
+		require.Equal(t, 1, countCalls)
+		require.Equal(t, "hello", gotMsg.Text)
+	})
+}
@@ -0,0 +1,97 @@
+package externalsvc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// webhookError is the error returned when a request to a webhook (e.g. a
+// Slack or Microsoft Teams incoming webhook, a custom HTTP integration)
+// returns a non-successful status code.
+type webhookError struct {
+	statusCode int
+	retryAfter string
+	body       string
+}
+
+func (e *webhookError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.statusCode, e.body)
+}
+
+// sendWebhookRequest sends the body to the url using the provided method and
+// headers. The request is retried on temporary failures.
+func sendWebhookRequest(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body []byte) error {
+	op := func() error {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				// retryable error
+				return err
+			}
+			return backoff.Permanent(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			return nil
+		}
+
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		whErr := &webhookError{
+			statusCode: resp.StatusCode,
+			retryAfter: resp.Header.Get("Retry-After"),
+			body:       string(respBody),
+		}
+		if whErr.statusCode >= http.StatusInternalServerError {
+			// 500+ status, can be worth retrying
+			return whErr
+		}
+		if whErr.statusCode == http.StatusTooManyRequests {
+			afterSecs, err := strconv.ParseInt(whErr.retryAfter, 10, 0)
+			if err == nil && (time.Duration(afterSecs)*time.Second) < maxWaitForRetryAfter {
+				// the retry-after duration is reasonable, wait for it and return a
+				// retryable error so that we try again.
+				time.Sleep(time.Duration(afterSecs) * time.Second)
+				return whErr
+			}
+		}
+
+		// at this point, this is a non-retryable error
+		return backoff.Permanent(whErr)
+	}
+
+	boff := backoff.WithMaxRetries(backoff.NewConstantBackOff(retryBackoff), uint64(maxRetries))
+	return backoff.Retry(op, backoff.WithContext(boff, ctx))
+}
+
+// validateWebhookURL validates that the url is a valid http or https URL.
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("invalid webhook URL, must be an http or https URL")
+	}
+	return nil
+}
@@ -96,6 +96,60 @@ func (z *Zendesk) CreateZendeskTicket(ctx context.Context, ticket *zendesk.Ticke
 	return createdTicket, nil
 }
 
+// ReopenZendeskTicket adds a comment to the ticket identified by ticketID and,
+// if that ticket is solved, sets its status back to open.
+func (z *Zendesk) ReopenZendeskTicket(ctx context.Context, ticketID int64, comment string) error {
+	ticket, err := z.getTicket(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+
+	update := zendesk.Ticket{Comment: &zendesk.TicketComment{Body: comment}}
+	if ticket.Status == "solved" {
+		update.Status = "open"
+	}
+	return z.updateTicket(ctx, ticketID, update)
+}
+
+// ResolveZendeskTicket adds a comment to the ticket identified by ticketID and
+// sets its status to solved. It is a no-op if the ticket is already closed, as
+// closed tickets cannot be updated.
+func (z *Zendesk) ResolveZendeskTicket(ctx context.Context, ticketID int64, comment string) error {
+	ticket, err := z.getTicket(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if ticket.Status == "closed" {
+		return nil
+	}
+
+	update := zendesk.Ticket{Comment: &zendesk.TicketComment{Body: comment}}
+	if ticket.Status != "solved" {
+		update.Status = "solved"
+	}
+	return z.updateTicket(ctx, ticketID, update)
+}
+
+func (z *Zendesk) getTicket(ctx context.Context, ticketID int64) (*zendesk.Ticket, error) {
+	var ticket *zendesk.Ticket
+	op := func() (interface{}, error) {
+		t, err := z.client.GetTicket(ctx, ticketID)
+		ticket = &t
+		return ticket, err
+	}
+	if err := doZendeskWithRetry(op); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func (z *Zendesk) updateTicket(ctx context.Context, ticketID int64, ticket zendesk.Ticket) error {
+	op := func() (interface{}, error) {
+		return z.client.UpdateTicket(ctx, ticketID, ticket)
+	}
+	return doZendeskWithRetry(op)
+}
+
 // ZendeskConfigMatches returns true if the zendesk client has been configured
 // using those same options. The Zendesk in the name is required so that the
 // interface method is not the same as the one for Jira (for mock or wrapper
@@ -2,6 +2,8 @@ package externalsvc
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -126,3 +128,69 @@ func TestZendesk(t *testing.T) {
 		require.Equal(t, 1, countCalls)
 	})
 }
+
+func TestZendeskReopenResolve(t *testing.T) {
+	var status string
+	var updates []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/tickets/123.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			updates = append(updates, string(body))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, err := w.Write([]byte(`{"ticket": {"id": 123, "status": "` + status + `"}}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	client, err := NewZendeskTestClient(&ZendeskOptions{URL: srv.URL})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	cases := []struct {
+		desc       string
+		status     string
+		fn         func(ctx context.Context, id int64, comment string) error
+		wantUpdate bool
+		wantStatus string
+	}{
+		{"reopen open ticket", "open", client.ReopenZendeskTicket, true, ""},
+		{"reopen solved ticket", "solved", client.ReopenZendeskTicket, true, "open"},
+		{"resolve open ticket", "open", client.ResolveZendeskTicket, true, "solved"},
+		{"resolve solved ticket", "solved", client.ResolveZendeskTicket, true, ""},
+		{"resolve closed ticket", "closed", client.ResolveZendeskTicket, false, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			updates = updates[:0]
+			status = c.status
+
+			err := c.fn(ctx, 123, "comment")
+			require.NoError(t, err)
+			if !c.wantUpdate {
+				require.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+
+			var payload struct {
+				Ticket zendesk.Ticket `json:"ticket"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(updates[0]), &payload))
+			require.Equal(t, c.wantStatus, payload.Ticket.Status)
+			require.NotNil(t, payload.Ticket.Comment)
+			require.Equal(t, "comment", payload.Ticket.Comment.Body)
+		})
+	}
+}
@@ -18,13 +18,16 @@ import (
 /////////////////////////////////////////////////////////////////////////////////
 
 type globalPolicyRequest struct {
-	QueryID     *uint  `json:"query_id"`
-	Query       string `json:"query"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Resolution  string `json:"resolution"`
-	Platform    string `json:"platform"`
-	Critical    bool   `json:"critical" premium:"true"`
+	QueryID     *uint                           `json:"query_id"`
+	Query       string                          `json:"query"`
+	Name        string                          `json:"name"`
+	Description string                          `json:"description"`
+	Resolution  string                          `json:"resolution"`
+	Platform    string                          `json:"platform"`
+	Critical    bool                            `json:"critical" premium:"true"`
+	Severity    string                          `json:"severity"`
+	Tags        []string                        `json:"tags"`
+	Compliance  []fleet.PolicyComplianceControl `json:"compliance"`
 }
 
 type globalPolicyResponse struct {
@@ -44,6 +47,9 @@ func globalPolicyEndpoint(ctx context.Context, request interface{}, svc fleet.Se
 		Resolution:  req.Resolution,
 		Platform:    req.Platform,
 		Critical:    req.Critical,
+		Severity:    req.Severity,
+		Tags:        req.Tags,
+		Compliance:  req.Compliance,
 	})
 	if err != nil {
 		return globalPolicyResponse{Err: err}, nil
@@ -87,6 +93,12 @@ func (svc Service) NewGlobalPolicy(ctx context.Context, p fleet.PolicyPayload) (
 // List
 /////////////////////////////////////////////////////////////////////////////////
 
+type listGlobalPoliciesRequest struct {
+	Severity            string `query:"severity,optional"`
+	Tag                 string `query:"tag,optional"`
+	ComplianceFramework string `query:"compliance_framework,optional"`
+}
+
 type listGlobalPoliciesResponse struct {
 	Policies []*fleet.Policy `json:"policies,omitempty"`
 	Err      error           `json:"error,omitempty"`
@@ -94,20 +106,188 @@ type listGlobalPoliciesResponse struct {
 
 func (r listGlobalPoliciesResponse) error() error { return r.Err }
 
-func listGlobalPoliciesEndpoint(ctx context.Context, _ interface{}, svc fleet.Service) (errorer, error) {
-	resp, err := svc.ListGlobalPolicies(ctx)
+func listGlobalPoliciesEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listGlobalPoliciesRequest)
+	resp, err := svc.ListGlobalPolicies(ctx, fleet.PolicyListOptions{
+		Severity:            req.Severity,
+		Tag:                 req.Tag,
+		ComplianceFramework: req.ComplianceFramework,
+	})
 	if err != nil {
 		return listGlobalPoliciesResponse{Err: err}, nil
 	}
 	return listGlobalPoliciesResponse{Policies: resp}, nil
 }
 
-func (svc Service) ListGlobalPolicies(ctx context.Context) ([]*fleet.Policy, error) {
+func (svc Service) ListGlobalPolicies(ctx context.Context, opts fleet.PolicyListOptions) ([]*fleet.Policy, error) {
 	if err := svc.authz.Authorize(ctx, &fleet.Policy{}, fleet.ActionRead); err != nil {
 		return nil, err
 	}
 
-	return svc.ds.ListGlobalPolicies(ctx)
+	if err := opts.Verify(); err != nil {
+		return nil, fleet.NewInvalidArgumentError("severity", err.Error())
+	}
+
+	return svc.ds.ListGlobalPolicies(ctx, opts)
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Compliance
+/////////////////////////////////////////////////////////////////////////////////
+
+type listPolicyComplianceRequest struct {
+	TeamID *uint `query:"team_id,optional"`
+}
+
+type listPolicyComplianceResponse struct {
+	Compliance []*fleet.PolicyComplianceSummary `json:"compliance"`
+	Err        error                            `json:"error,omitempty"`
+}
+
+func (r listPolicyComplianceResponse) error() error { return r.Err }
+
+func listPolicyComplianceEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listPolicyComplianceRequest)
+	compliance, err := svc.ListPolicyCompliance(ctx, req.TeamID)
+	if err != nil {
+		return listPolicyComplianceResponse{Err: err}, nil
+	}
+	if compliance == nil {
+		compliance = []*fleet.PolicyComplianceSummary{}
+	}
+	return listPolicyComplianceResponse{Compliance: compliance}, nil
+}
+
+func (svc *Service) ListPolicyCompliance(ctx context.Context, teamID *uint) ([]*fleet.PolicyComplianceSummary, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.Policy{
+		PolicyData: fleet.PolicyData{
+			TeamID: teamID,
+		},
+	}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	summaries, err := svc.ds.ListPolicyComplianceSummaries(ctx, teamID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list policy compliance summaries")
+	}
+	if teamID != nil {
+		return summaries, nil
+	}
+
+	// team users can read global policies, but must only see the compliance
+	// of their own teams.
+	userTeams, err := viewerTeamIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userTeams == nil {
+		return summaries, nil
+	}
+	filtered := summaries[:0]
+	for _, sum := range summaries {
+		if sum.TeamID == nil {
+			continue
+		}
+		if _, ok := userTeams[*sum.TeamID]; ok {
+			filtered = append(filtered, sum)
+		}
+	}
+	return filtered, nil
+}
+
+// viewerTeamIDs returns the IDs of the teams of the user in the context, or
+// nil if the user has a global role.
+func viewerTeamIDs(ctx context.Context) (map[uint]struct{}, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, fleet.ErrNoContext
+	}
+	if vc.User.GlobalRole != nil {
+		return nil, nil
+	}
+	userTeams := make(map[uint]struct{}, len(vc.User.Teams))
+	for _, t := range vc.User.Teams {
+		userTeams[t.ID] = struct{}{}
+	}
+	return userTeams, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// History
+/////////////////////////////////////////////////////////////////////////////////
+
+type listPolicyHistoryRequest struct {
+	PolicyID *uint  `query:"policy_id,optional"`
+	TeamID   *uint  `query:"team_id,optional"`
+	From     string `query:"from,optional"`
+	To       string `query:"to,optional"`
+}
+
+type listPolicyHistoryResponse struct {
+	History []*fleet.PolicyStatsSnapshot `json:"history"`
+	Err     error                        `json:"error,omitempty"`
+}
+
+func (r listPolicyHistoryResponse) error() error { return r.Err }
+
+func listPolicyHistoryEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listPolicyHistoryRequest)
+	history, err := svc.ListPolicyHistory(ctx, fleet.PolicyStatsHistoryListOptions{
+		PolicyID: req.PolicyID,
+		TeamID:   req.TeamID,
+		From:     req.From,
+		To:       req.To,
+	})
+	if err != nil {
+		return listPolicyHistoryResponse{Err: err}, nil
+	}
+	if history == nil {
+		history = []*fleet.PolicyStatsSnapshot{}
+	}
+	return listPolicyHistoryResponse{History: history}, nil
+}
+
+func (svc *Service) ListPolicyHistory(ctx context.Context, opts fleet.PolicyStatsHistoryListOptions) ([]*fleet.PolicyStatsSnapshot, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.Policy{
+		PolicyData: fleet.PolicyData{
+			TeamID: opts.TeamID,
+		},
+	}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Verify(); err != nil {
+		return nil, ctxerr.Wrap(ctx, badRequest(err.Error()))
+	}
+
+	history, err := svc.ds.ListPolicyStatsHistory(ctx, opts)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list policy stats history")
+	}
+	if opts.TeamID != nil {
+		return history, nil
+	}
+
+	// team users can read global policies, but must only see the history of
+	// their own teams.
+	userTeams, err := viewerTeamIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userTeams == nil {
+		return history, nil
+	}
+	filtered := history[:0]
+	for _, h := range history {
+		if h.TeamID == nil {
+			continue
+		}
+		if _, ok := userTeams[*h.TeamID]; ok {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered, nil
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -307,13 +487,13 @@ func (svc *Service) ResetAutomation(ctx context.Context, teamIDs, policyIDs []ui
 	if err != nil {
 		return err
 	}
-	allAutoPolicies := automationPolicies(ac.WebhookSettings.FailingPoliciesWebhook, ac.Integrations.Jira, ac.Integrations.Zendesk)
+	allAutoPolicies := automationPolicies(ac.WebhookSettings.FailingPoliciesWebhook, ac.Integrations)
 	pIDs := make(map[uint]struct{})
 	for _, id := range policyIDs {
 		pIDs[id] = struct{}{}
 	}
 	for _, teamID := range teamIDs {
-		p1, p2, err := svc.ds.ListTeamPolicies(ctx, teamID)
+		p1, p2, err := svc.ds.ListTeamPolicies(ctx, teamID, fleet.PolicyListOptions{})
 		if err != nil {
 			return err
 		}
@@ -345,7 +525,7 @@ func (svc *Service) ResetAutomation(ctx context.Context, teamIDs, policyIDs []ui
 		if err != nil {
 			return err
 		}
-		for pID := range teamAutomationPolicies(t.Config.WebhookSettings.FailingPoliciesWebhook, t.Config.Integrations.Jira, t.Config.Integrations.Zendesk) {
+		for pID := range teamAutomationPolicies(t.Config.WebhookSettings.FailingPoliciesWebhook, t.Config.Integrations) {
 			allAutoPolicies[pID] = struct{}{}
 		}
 	}
@@ -369,18 +549,33 @@ func (svc *Service) ResetAutomation(ctx context.Context, teamIDs, policyIDs []ui
 	return nil
 }
 
-func automationPolicies(wh fleet.FailingPoliciesWebhookSettings, ji []*fleet.JiraIntegration, zi []*fleet.ZendeskIntegration) map[uint]struct{} {
+func automationPolicies(wh fleet.FailingPoliciesWebhookSettings, intgs fleet.Integrations) map[uint]struct{} {
 	enabled := wh.Enable
-	for _, j := range ji {
+	for _, j := range intgs.Jira {
 		if j.EnableFailingPolicies {
 			enabled = true
 		}
 	}
-	for _, z := range zi {
+	for _, z := range intgs.Zendesk {
 		if z.EnableFailingPolicies {
 			enabled = true
 		}
 	}
+	for _, sl := range intgs.Slack {
+		if sl.EnableFailingPolicies {
+			enabled = true
+		}
+	}
+	for _, mt := range intgs.MicrosoftTeams {
+		if mt.EnableFailingPolicies {
+			enabled = true
+		}
+	}
+	for _, ch := range intgs.CustomHTTP {
+		if ch.EnableFailingPolicies {
+			enabled = true
+		}
+	}
 	pols := make(map[uint]struct{}, len(wh.PolicyIDs))
 	if !enabled {
 		return pols
@@ -391,18 +586,33 @@ func automationPolicies(wh fleet.FailingPoliciesWebhookSettings, ji []*fleet.Jir
 	return pols
 }
 
-func teamAutomationPolicies(wh fleet.FailingPoliciesWebhookSettings, ji []*fleet.TeamJiraIntegration, zi []*fleet.TeamZendeskIntegration) map[uint]struct{} {
+func teamAutomationPolicies(wh fleet.FailingPoliciesWebhookSettings, intgs fleet.TeamIntegrations) map[uint]struct{} {
 	enabled := wh.Enable
-	for _, j := range ji {
+	for _, j := range intgs.Jira {
 		if j.EnableFailingPolicies {
 			enabled = true
 		}
 	}
-	for _, z := range zi {
+	for _, z := range intgs.Zendesk {
 		if z.EnableFailingPolicies {
 			enabled = true
 		}
 	}
+	for _, sl := range intgs.Slack {
+		if sl.EnableFailingPolicies {
+			enabled = true
+		}
+	}
+	for _, mt := range intgs.MicrosoftTeams {
+		if mt.EnableFailingPolicies {
+			enabled = true
+		}
+	}
+	for _, ch := range intgs.CustomHTTP {
+		if ch.EnableFailingPolicies {
+			enabled = true
+		}
+	}
 	pols := make(map[uint]struct{}, len(wh.PolicyIDs))
 	if !enabled {
 		return pols
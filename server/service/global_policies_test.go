@@ -18,7 +18,7 @@ func TestGlobalPoliciesAuth(t *testing.T) {
 	ds.NewGlobalPolicyFunc = func(ctx context.Context, authorID *uint, args fleet.PolicyPayload) (*fleet.Policy, error) {
 		return &fleet.Policy{}, nil
 	}
-	ds.ListGlobalPoliciesFunc = func(ctx context.Context) ([]*fleet.Policy, error) {
+	ds.ListGlobalPoliciesFunc = func(ctx context.Context, opts fleet.PolicyListOptions) ([]*fleet.Policy, error) {
 		return nil, nil
 	}
 	ds.PoliciesByIDFunc = func(ctx context.Context, ids []uint) (map[uint]*fleet.Policy, error) {
@@ -109,7 +109,7 @@ func TestGlobalPoliciesAuth(t *testing.T) {
 			})
 			checkAuthErr(t, tt.shouldFailWrite, err)
 
-			_, err = svc.ListGlobalPolicies(ctx)
+			_, err = svc.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 			checkAuthErr(t, tt.shouldFailRead, err)
 
 			_, err = svc.GetPolicyByIDQueries(ctx, 1)
@@ -197,3 +197,151 @@ func TestRemoveGlobalPoliciesFromWebhookConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestListPoliciesInvalidSeverity(t *testing.T) {
+	ds := new(mock.Store)
+	ds.ListGlobalPoliciesFunc = func(ctx context.Context, opts fleet.PolicyListOptions) ([]*fleet.Policy, error) {
+		return nil, nil
+	}
+	ds.ListTeamPoliciesFunc = func(ctx context.Context, teamID uint, opts fleet.PolicyListOptions) ([]*fleet.Policy, []*fleet.Policy, error) {
+		return nil, nil, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, id uint) (*fleet.Team, error) {
+		return &fleet.Team{ID: id}, nil
+	}
+	svc, ctx := newTestService(t, ds, nil, nil)
+	ctx = viewer.NewContext(ctx, viewer.Viewer{User: &fleet.User{GlobalRole: ptr.String(fleet.RoleAdmin)}})
+
+	var invalidArgErr *fleet.InvalidArgumentError
+	_, err := svc.ListGlobalPolicies(ctx, fleet.PolicyListOptions{Severity: "hgih"})
+	require.ErrorAs(t, err, &invalidArgErr)
+	require.ErrorContains(t, err, "invalid policy severity")
+	require.False(t, ds.ListGlobalPoliciesFuncInvoked)
+
+	_, _, err = svc.ListTeamPolicies(ctx, 1, fleet.PolicyListOptions{Severity: "hgih"})
+	require.ErrorAs(t, err, &invalidArgErr)
+	require.False(t, ds.ListTeamPoliciesFuncInvoked)
+
+	_, err = svc.ListGlobalPolicies(ctx, fleet.PolicyListOptions{Severity: fleet.PolicySeverityHigh})
+	require.NoError(t, err)
+	_, _, err = svc.ListTeamPolicies(ctx, 1, fleet.PolicyListOptions{Severity: fleet.PolicySeverityHigh})
+	require.NoError(t, err)
+}
+
+func TestListPolicyCompliance(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	summaries := []*fleet.PolicyComplianceSummary{
+		{TeamID: nil, Framework: "CIS", PassingCount: 1, CompliancePercentage: 100},
+		{TeamID: ptr.Uint(1), TeamName: "team1", Framework: "CIS", PassingCount: 1, FailingCount: 1, CompliancePercentage: 50},
+		{TeamID: ptr.Uint(2), TeamName: "team2", Framework: "CIS", FailingCount: 1},
+	}
+	ds.ListPolicyComplianceSummariesFunc = func(ctx context.Context, teamID *uint) ([]*fleet.PolicyComplianceSummary, error) {
+		if teamID != nil {
+			var res []*fleet.PolicyComplianceSummary
+			for _, s := range summaries {
+				if s.TeamID != nil && *s.TeamID == *teamID {
+					res = append(res, s)
+				}
+			}
+			return res, nil
+		}
+		// return a copy, as the service filters the slice in place
+		return append([]*fleet.PolicyComplianceSummary(nil), summaries...), nil
+	}
+
+	teamNames := func(res []*fleet.PolicyComplianceSummary) []string {
+		var names []string
+		for _, s := range res {
+			names = append(names, s.TeamName)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		name       string
+		user       *fleet.User
+		teamID     *uint
+		shouldFail bool
+		wantTeams  []string
+	}{
+		{
+			"global observer all teams",
+			&fleet.User{GlobalRole: ptr.String(fleet.RoleObserver)},
+			nil,
+			false,
+			[]string{"", "team1", "team2"},
+		},
+		{
+			"global observer one team",
+			&fleet.User{GlobalRole: ptr.String(fleet.RoleObserver)},
+			ptr.Uint(2),
+			false,
+			[]string{"team2"},
+		},
+		{
+			"team observer all teams",
+			&fleet.User{Teams: []fleet.UserTeam{{Team: fleet.Team{ID: 1}, Role: fleet.RoleObserver}}},
+			nil,
+			false,
+			[]string{"team1"},
+		},
+		{
+			"team observer own team",
+			&fleet.User{Teams: []fleet.UserTeam{{Team: fleet.Team{ID: 1}, Role: fleet.RoleObserver}}},
+			ptr.Uint(1),
+			false,
+			[]string{"team1"},
+		},
+		{
+			"team observer other team",
+			&fleet.User{Teams: []fleet.UserTeam{{Team: fleet.Team{ID: 1}, Role: fleet.RoleObserver}}},
+			ptr.Uint(2),
+			true,
+			nil,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := viewer.NewContext(ctx, viewer.Viewer{User: tt.user})
+
+			res, err := svc.ListPolicyCompliance(ctx, tt.teamID)
+			checkAuthErr(t, tt.shouldFail, err)
+			require.Equal(t, tt.wantTeams, teamNames(res))
+		})
+	}
+}
+
+func TestListPolicyHistory(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	ds.ListPolicyStatsHistoryFunc = func(ctx context.Context, opts fleet.PolicyStatsHistoryListOptions) ([]*fleet.PolicyStatsSnapshot, error) {
+		return []*fleet.PolicyStatsSnapshot{
+			{Date: "2023-03-13", PolicyID: 1, TeamID: nil},
+			{Date: "2023-03-13", PolicyID: 1, TeamID: ptr.Uint(1)},
+			{Date: "2023-03-13", PolicyID: 1, TeamID: ptr.Uint(2)},
+		}, nil
+	}
+
+	globalCtx := viewer.NewContext(ctx, viewer.Viewer{User: &fleet.User{GlobalRole: ptr.String(fleet.RoleObserver)}})
+	history, err := svc.ListPolicyHistory(globalCtx, fleet.PolicyStatsHistoryListOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	_, err = svc.ListPolicyHistory(globalCtx, fleet.PolicyStatsHistoryListOptions{From: "2023-13-01"})
+	require.ErrorContains(t, err, "invalid from date")
+
+	// team users only get the history of their teams
+	teamCtx := viewer.NewContext(ctx, viewer.Viewer{User: &fleet.User{Teams: []fleet.UserTeam{{Team: fleet.Team{ID: 1}, Role: fleet.RoleObserver}}}})
+	history, err = svc.ListPolicyHistory(teamCtx, fleet.PolicyStatsHistoryListOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, uint(1), *history[0].TeamID)
+
+	_, err = svc.ListPolicyHistory(teamCtx, fleet.PolicyStatsHistoryListOptions{TeamID: ptr.Uint(2)})
+	checkAuthErr(t, true, err)
+	_, err = svc.ListPolicyHistory(teamCtx, fleet.PolicyStatsHistoryListOptions{TeamID: ptr.Uint(0)})
+	checkAuthErr(t, true, err)
+}
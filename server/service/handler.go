@@ -274,6 +274,28 @@ func attachFleetAPIRoutes(r *mux.Router, svc fleet.Service, config config.FleetC
 	ue.PATCH("/api/_version_/fleet/teams/{id:[0-9]+}/users", addTeamUsersEndpoint, modifyTeamUsersRequest{})
 	ue.DELETE("/api/_version_/fleet/teams/{id:[0-9]+}/users", deleteTeamUsersEndpoint, modifyTeamUsersRequest{})
 	ue.GET("/api/_version_/fleet/teams/{id:[0-9]+}/secrets", teamEnrollSecretsEndpoint, teamEnrollSecretsRequest{})
+	ue.GET("/api/_version_/fleet/teams/{id:[0-9]+}/host_lifecycle/preview", previewTeamHostLifecycleEndpoint, previewTeamHostLifecycleRequest{})
+	ue.POST("/api/_version_/fleet/team_assignment_rules/preview", previewTeamAssignmentRulesEndpoint, previewTeamAssignmentRulesRequest{})
+
+	ue.GET("/api/_version_/fleet/update_rollouts", listUpdateRolloutsEndpoint, listUpdateRolloutsRequest{})
+	ue.POST("/api/_version_/fleet/update_rollouts", createUpdateRolloutEndpoint, createUpdateRolloutRequest{})
+	ue.GET("/api/_version_/fleet/update_rollouts/{id:[0-9]+}", getUpdateRolloutEndpoint, getUpdateRolloutRequest{})
+	ue.PATCH("/api/_version_/fleet/update_rollouts/{id:[0-9]+}", modifyUpdateRolloutEndpoint, modifyUpdateRolloutRequest{})
+	ue.DELETE("/api/_version_/fleet/update_rollouts/{id:[0-9]+}", deleteUpdateRolloutEndpoint, deleteUpdateRolloutRequest{})
+
+	ue.GET("/api/_version_/fleet/vulnerability_exceptions", listVulnerabilityExceptionsEndpoint, listVulnerabilityExceptionsRequest{})
+	ue.POST("/api/_version_/fleet/vulnerability_exceptions", createVulnerabilityExceptionEndpoint, createVulnerabilityExceptionRequest{})
+	ue.GET("/api/_version_/fleet/vulnerability_exceptions/{id:[0-9]+}", getVulnerabilityExceptionEndpoint, getVulnerabilityExceptionRequest{})
+	ue.PATCH("/api/_version_/fleet/vulnerability_exceptions/{id:[0-9]+}", modifyVulnerabilityExceptionEndpoint, modifyVulnerabilityExceptionRequest{})
+	ue.DELETE("/api/_version_/fleet/vulnerability_exceptions/{id:[0-9]+}", deleteVulnerabilityExceptionEndpoint, deleteVulnerabilityExceptionRequest{})
+
+	ue.GET("/api/_version_/fleet/vulnerabilities/remediations", listHostVulnerabilityRemediationsEndpoint, listHostVulnerabilityRemediationsRequest{})
+	ue.GET("/api/_version_/fleet/vulnerabilities/remediations/stats", vulnerabilityRemediationStatsEndpoint, vulnerabilityRemediationStatsRequest{})
+
+	ue.POST("/api/_version_/fleet/spec/cpe_translations", applyCPETranslationRuleSpecsEndpoint, applyCPETranslationRuleSpecsRequest{})
+	ue.GET("/api/_version_/fleet/spec/cpe_translations", getCPETranslationRuleSpecsEndpoint, nil)
+	ue.DELETE("/api/_version_/fleet/cpe_translations/{name}", deleteCPETranslationRuleEndpoint, deleteCPETranslationRuleRequest{})
+	ue.POST("/api/_version_/fleet/cpe_translations/dry_run", dryRunCPETranslationEndpoint, dryRunCPETranslationRequest{})
 
 	ue.GET("/api/_version_/fleet/users", listUsersEndpoint, listUsersRequest{})
 	ue.POST("/api/_version_/fleet/users/admin", createUserEndpoint, createUserRequest{})
@@ -297,8 +319,11 @@ func attachFleetAPIRoutes(r *mux.Router, svc fleet.Service, config config.FleetC
 
 	ue.EndingAtVersion("v1").POST("/api/_version_/fleet/global/policies", globalPolicyEndpoint, globalPolicyRequest{})
 	ue.StartingAtVersion("2022-04").POST("/api/_version_/fleet/policies", globalPolicyEndpoint, globalPolicyRequest{})
-	ue.EndingAtVersion("v1").GET("/api/_version_/fleet/global/policies", listGlobalPoliciesEndpoint, nil)
-	ue.StartingAtVersion("2022-04").GET("/api/_version_/fleet/policies", listGlobalPoliciesEndpoint, nil)
+	ue.EndingAtVersion("v1").GET("/api/_version_/fleet/global/policies", listGlobalPoliciesEndpoint, listGlobalPoliciesRequest{})
+	ue.StartingAtVersion("2022-04").GET("/api/_version_/fleet/policies", listGlobalPoliciesEndpoint, listGlobalPoliciesRequest{})
+	// must be registered before /policies/{policy_id}
+	ue.StartingAtVersion("2022-04").GET("/api/_version_/fleet/policies/compliance", listPolicyComplianceEndpoint, listPolicyComplianceRequest{})
+	ue.StartingAtVersion("2022-04").GET("/api/_version_/fleet/policies/history", listPolicyHistoryEndpoint, listPolicyHistoryRequest{})
 	ue.EndingAtVersion("v1").GET("/api/_version_/fleet/global/policies/{policy_id}", getPolicyByIDEndpoint, getPolicyByIDRequest{})
 	ue.StartingAtVersion("2022-04").GET("/api/_version_/fleet/policies/{policy_id}", getPolicyByIDEndpoint, getPolicyByIDRequest{})
 	ue.EndingAtVersion("v1").POST("/api/_version_/fleet/global/policies/delete", deleteGlobalPoliciesEndpoint, deleteGlobalPoliciesRequest{})
@@ -320,6 +345,7 @@ func attachFleetAPIRoutes(r *mux.Router, svc fleet.Service, config config.FleetC
 	ue.POST("/api/_version_/fleet/spec/policies", applyPolicySpecsEndpoint, applyPolicySpecsRequest{})
 
 	ue.GET("/api/_version_/fleet/queries/{id:[0-9]+}", getQueryEndpoint, getQueryRequest{})
+	ue.GET("/api/_version_/fleet/queries/{id:[0-9]+}/report", getQueryReportEndpoint, getQueryReportRequest{})
 	ue.GET("/api/_version_/fleet/queries", listQueriesEndpoint, listQueriesRequest{})
 	ue.POST("/api/_version_/fleet/queries", createQueryEndpoint, createQueryRequest{})
 	ue.PATCH("/api/_version_/fleet/queries/{id:[0-9]+}", modifyQueryEndpoint, modifyQueryRequest{})
@@ -368,6 +394,7 @@ func attachFleetAPIRoutes(r *mux.Router, svc fleet.Service, config config.FleetC
 	ue.GET("/api/_version_/fleet/labels", listLabelsEndpoint, listLabelsRequest{})
 	ue.GET("/api/_version_/fleet/labels/summary", getLabelsSummaryEndpoint, nil)
 	ue.GET("/api/_version_/fleet/labels/{id:[0-9]+}/hosts", listHostsInLabelEndpoint, listHostsInLabelRequest{})
+	ue.POST("/api/_version_/fleet/labels/{id:[0-9]+}/membership", applyLabelMembershipCSVEndpoint, applyLabelMembershipCSVRequest{})
 	ue.DELETE("/api/_version_/fleet/labels/{name}", deleteLabelEndpoint, deleteLabelRequest{})
 	ue.DELETE("/api/_version_/fleet/labels/id/{id:[0-9]+}", deleteLabelByIDEndpoint, deleteLabelByIDRequest{})
 	ue.POST("/api/_version_/fleet/spec/labels", applyLabelSpecsEndpoint, applyLabelSpecsRequest{})
@@ -381,8 +408,10 @@ func attachFleetAPIRoutes(r *mux.Router, svc fleet.Service, config config.FleetC
 	// websockets via the `GET /api/_version_/fleet/results/` endpoint.
 	ue.POST("/api/_version_/fleet/queries/run", createDistributedQueryCampaignEndpoint, createDistributedQueryCampaignRequest{})
 	ue.POST("/api/_version_/fleet/queries/run_by_names", createDistributedQueryCampaignByNamesEndpoint, createDistributedQueryCampaignByNamesRequest{})
+	ue.GET("/api/_version_/fleet/campaigns/{id:[0-9]+}/results", listCampaignResultsEndpoint, listCampaignResultsRequest{})
 
 	ue.GET("/api/_version_/fleet/activities", listActivitiesEndpoint, listActivitiesRequest{})
+	ue.GET("/api/_version_/fleet/activities/webhook_deliveries", listActivityWebhookDeliveriesEndpoint, listActivityWebhookDeliveriesRequest{})
 
 	ue.POST("/api/_version_/fleet/download_installer/{kind}", getInstallerEndpoint, getInstallerRequest{})
 	ue.HEAD("/api/_version_/fleet/download_installer/{kind}", checkInstallerEndpoint, checkInstallerRequest{})
@@ -418,6 +447,7 @@ func attachFleetAPIRoutes(r *mux.Router, svc fleet.Service, config config.FleetC
 	ue.GET("/api/_version_/fleet/carves", listCarvesEndpoint, listCarvesRequest{})
 	ue.GET("/api/_version_/fleet/carves/{id:[0-9]+}", getCarveEndpoint, getCarveRequest{})
 	ue.GET("/api/_version_/fleet/carves/{id:[0-9]+}/block/{block_id}", getCarveBlockEndpoint, getCarveBlockRequest{})
+	ue.GET("/api/_version_/fleet/carves/{id:[0-9]+}/download", downloadCarveEndpoint, downloadCarveRequest{})
 
 	ue.GET("/api/_version_/fleet/hosts/{id:[0-9]+}/macadmins", getMacadminsDataEndpoint, getMacadminsDataRequest{})
 	ue.GET("/api/_version_/fleet/macadmins", getAggregatedMacadminsDataEndpoint, getAggregatedMacadminsDataRequest{})
@@ -815,12 +815,18 @@ func (svc *Service) getHostDetails(ctx context.Context, host *fleet.Host, opts f
 	}
 	host.MDM.Profiles = &profiles
 
+	updateChannels, err := svc.hostUpdateChannels(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
 	return &fleet.HostDetail{
-		Host:      *host,
-		Labels:    labels,
-		Packs:     packs,
-		Policies:  policies,
-		Batteries: &bats,
+		Host:           *host,
+		Labels:         labels,
+		Packs:          packs,
+		Policies:       policies,
+		Batteries:      &bats,
+		UpdateChannels: updateChannels,
 	}, nil
 }
 
@@ -65,6 +65,12 @@ func TestHostDetails(t *testing.T) {
 	ds.ListHostBatteriesFunc = func(ctx context.Context, hostID uint) ([]*fleet.HostBattery, error) {
 		return dsBats, nil
 	}
+	ds.HostOrbitChannelsFunc = func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+		return &fleet.UpdateChannels{Orbit: "stable", Osqueryd: "stable", Desktop: "stable"}, nil
+	}
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return nil, nil
+	}
 	// Health should be replaced at the service layer with custom values determined by the cycle count. See https://github.com/fleetdm/fleet/issues/6763.
 	expectedBats := []*fleet.HostBattery{{HostID: host.ID, SerialNumber: "a", CycleCount: 999, Health: "Normal"}, {HostID: host.ID, SerialNumber: "b", CycleCount: 1001, Health: "Replacement recommended"}}
 
@@ -79,6 +85,9 @@ func TestHostDetails(t *testing.T) {
 	require.NotNil(t, hostDetail.Batteries)
 	assert.Equal(t, expectedBats, *hostDetail.Batteries)
 	require.Nil(t, hostDetail.MDM.MacOSSettings)
+	require.NotNil(t, hostDetail.UpdateChannels)
+	assert.Equal(t, fleet.UpdateChannels{}, hostDetail.UpdateChannels.Desired)
+	assert.Equal(t, fleet.UpdateChannels{Orbit: "stable", Osqueryd: "stable", Desktop: "stable"}, hostDetail.UpdateChannels.Reported)
 }
 
 func TestHostDetailsMDMDiskEncryption(t *testing.T) {
@@ -103,6 +112,12 @@ func TestHostDetailsMDMDiskEncryption(t *testing.T) {
 	ds.ListHostBatteriesFunc = func(ctx context.Context, hostID uint) ([]*fleet.HostBattery, error) {
 		return nil, nil
 	}
+	ds.HostOrbitChannelsFunc = func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+		return nil, nil
+	}
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return nil, nil
+	}
 
 	cases := []struct {
 		name       string
@@ -363,6 +378,15 @@ func TestHostAuth(t *testing.T) {
 	ds.ListHostBatteriesFunc = func(ctx context.Context, hostID uint) ([]*fleet.HostBattery, error) {
 		return nil, nil
 	}
+	ds.HostOrbitChannelsFunc = func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+		return nil, nil
+	}
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return nil, nil
+	}
+	ds.TeamUpdateChannelsFunc = func(ctx context.Context, teamID uint) (*fleet.UpdateChannels, error) {
+		return nil, nil
+	}
 	ds.DeleteHostsFunc = func(ctx context.Context, ids []uint) error {
 		return nil
 	}
@@ -4579,6 +4579,200 @@ func (s *integrationTestSuite) TestGlobalPoliciesBrowsing() {
 	assert.Equal(t, "select * from osquery;", policiesResponse.Policies[0].Query)
 }
 
+func (s *integrationTestSuite) TestPoliciesMetadataAndCompliance() {
+	t := s.T()
+	ctx := context.Background()
+
+	// invalid severity
+	gpParams := globalPolicyRequest{
+		Name:     "metadata policy",
+		Query:    "select 1;",
+		Severity: "urgent",
+	}
+	s.DoJSON("POST", "/api/latest/fleet/policies", gpParams, http.StatusBadRequest, &globalPolicyResponse{})
+
+	gpParams.Severity = fleet.PolicySeverityHigh
+	gpParams.Tags = []string{"encryption"}
+	gpParams.Compliance = []fleet.PolicyComplianceControl{{Framework: "TestFramework", Control: "AC-2"}}
+	gpResp := globalPolicyResponse{}
+	s.DoJSON("POST", "/api/latest/fleet/policies", gpParams, http.StatusOK, &gpResp)
+	require.NotNil(t, gpResp.Policy)
+	assert.Equal(t, fleet.PolicySeverityHigh, gpResp.Policy.Severity)
+	assert.Equal(t, []string{"encryption"}, gpResp.Policy.Tags)
+	assert.Equal(t, gpParams.Compliance, gpResp.Policy.Compliance)
+
+	gpResp2 := globalPolicyResponse{}
+	s.DoJSON("POST", "/api/latest/fleet/policies", globalPolicyRequest{Name: "no metadata policy", Query: "select 2;"}, http.StatusOK, &gpResp2)
+	require.NotNil(t, gpResp2.Policy)
+
+	listResp := listGlobalPoliciesResponse{}
+	s.DoJSON("GET", "/api/latest/fleet/policies", nil, http.StatusOK, &listResp, "compliance_framework", "TestFramework")
+	require.Len(t, listResp.Policies, 1)
+	assert.Equal(t, gpResp.Policy.ID, listResp.Policies[0].ID)
+
+	// modify the tags, keeping the other metadata as is
+	mResp := modifyGlobalPolicyResponse{}
+	s.DoJSON("PATCH", fmt.Sprintf("/api/latest/fleet/policies/%d", gpResp.Policy.ID), modifyGlobalPolicyRequest{
+		ModifyPolicyPayload: fleet.ModifyPolicyPayload{Tags: &[]string{"disk"}},
+	}, http.StatusOK, &mResp)
+	assert.Equal(t, []string{"disk"}, mResp.Policy.Tags)
+	assert.Equal(t, fleet.PolicySeverityHigh, mResp.Policy.Severity)
+
+	listResp = listGlobalPoliciesResponse{}
+	s.DoJSON("GET", "/api/latest/fleet/policies", nil, http.StatusOK, &listResp, "tag", "encryption")
+	require.Empty(t, listResp.Policies)
+	s.DoJSON("GET", "/api/latest/fleet/policies", nil, http.StatusOK, &listResp, "tag", "disk", "severity", "high")
+	require.Len(t, listResp.Policies, 1)
+	s.DoJSON("GET", "/api/latest/fleet/policies", nil, http.StatusUnprocessableEntity, &listResp, "severity", "hgih")
+
+	host, err := s.ds.NewHost(ctx, &fleet.Host{
+		DetailUpdatedAt: time.Now(),
+		LabelUpdatedAt:  time.Now(),
+		PolicyUpdatedAt: time.Now(),
+		SeenTime:        time.Now(),
+		OsqueryHostID:   ptr.String(t.Name()),
+		NodeKey:         ptr.String(t.Name()),
+		UUID:            t.Name(),
+		Hostname:        t.Name() + "foo.local",
+		Platform:        "darwin",
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.ds.RecordPolicyQueryExecutions(ctx, host, map[uint]*bool{gpResp.Policy.ID: ptr.Bool(true)}, time.Now(), false))
+
+	complianceResp := listPolicyComplianceResponse{}
+	s.DoJSON("GET", "/api/latest/fleet/policies/compliance", nil, http.StatusOK, &complianceResp)
+	var found bool
+	for _, c := range complianceResp.Compliance {
+		if c.Framework == "TestFramework" {
+			found = true
+			assert.Nil(t, c.TeamID)
+			assert.Equal(t, uint(1), c.PolicyCount)
+			assert.Equal(t, uint(1), c.PassingCount)
+			assert.Equal(t, float64(100), c.CompliancePercentage)
+		}
+	}
+	require.True(t, found)
+}
+
+func (s *integrationTestSuite) TestQueryReport() {
+	t := s.T()
+	ctx := context.Background()
+
+	var createQueryResp createQueryResponse
+	s.DoJSON("POST", "/api/latest/fleet/queries", fleet.QueryPayload{
+		Name:         ptr.String(t.Name()),
+		Query:        ptr.String("SELECT days, hours FROM uptime;"),
+		StoreResults: ptr.Bool(true),
+	}, http.StatusOK, &createQueryResp)
+	require.True(t, createQueryResp.Query.StoreResults)
+	queryID := createQueryResp.Query.ID
+
+	var scheduleResp globalScheduleQueryResponse
+	s.DoJSON("POST", "/api/latest/fleet/schedule", fleet.ScheduledQueryPayload{
+		QueryID:  ptr.Uint(queryID),
+		Interval: ptr.Uint(60),
+		Snapshot: ptr.Bool(true),
+	}, http.StatusOK, &scheduleResp)
+
+	host, err := s.ds.NewHost(ctx, &fleet.Host{
+		DetailUpdatedAt: time.Now(),
+		LabelUpdatedAt:  time.Now(),
+		PolicyUpdatedAt: time.Now(),
+		SeenTime:        time.Now(),
+		OsqueryHostID:   ptr.String(t.Name()),
+		NodeKey:         ptr.String(t.Name()),
+		UUID:            t.Name(),
+		Hostname:        t.Name() + "foo.local",
+		Platform:        "darwin",
+	})
+	require.NoError(t, err)
+
+	// no results yet
+	var reportResp getQueryReportResponse
+	s.DoJSON("GET", fmt.Sprintf("/api/latest/fleet/queries/%d/report", queryID), nil, http.StatusOK, &reportResp)
+	require.Equal(t, queryID, reportResp.QueryID)
+	require.Empty(t, reportResp.Results)
+
+	logs := fmt.Sprintf(`[{"snapshot":[{"days":"1","hours":"2"},{"days":"3","hours":"4"}],"action":"snapshot","name":"pack/Global/%s","unixTime":1678838400}]`, scheduleResp.Scheduled.Name)
+	s.DoJSON("POST", "/api/osquery/log", submitLogsRequest{
+		NodeKey: *host.NodeKey,
+		LogType: "result",
+		Data:    json.RawMessage(logs),
+	}, http.StatusOK, &submitLogsResponse{})
+
+	reportResp = getQueryReportResponse{}
+	s.DoJSON("GET", fmt.Sprintf("/api/latest/fleet/queries/%d/report", queryID), nil, http.StatusOK, &reportResp, "host_id", fmt.Sprint(host.ID))
+	require.Len(t, reportResp.Results, 2)
+	assert.Equal(t, host.ID, reportResp.Results[0].HostID)
+	assert.Equal(t, host.Hostname, reportResp.Results[0].Hostname)
+	assert.Equal(t, time.Unix(1678838400, 0).UTC(), reportResp.Results[0].LastFetched.UTC())
+	assert.Equal(t, map[string]string{"days": "1", "hours": "2"}, reportResp.Results[0].Columns)
+	assert.Equal(t, map[string]string{"days": "3", "hours": "4"}, reportResp.Results[1].Columns)
+
+	// csv export
+	res := s.Do("GET", fmt.Sprintf("/api/latest/fleet/queries/%d/report", queryID), nil, http.StatusOK, "format", "csv")
+	defer res.Body.Close()
+	require.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"host_id", "host_name", "last_fetched", "days", "hours"},
+		{fmt.Sprint(host.ID), host.Hostname, "2023-03-15T00:00:00Z", "1", "2"},
+		{fmt.Sprint(host.ID), host.Hostname, "2023-03-15T00:00:00Z", "3", "4"},
+	}, rows)
+
+	s.Do("GET", fmt.Sprintf("/api/latest/fleet/queries/%d/report", queryID), nil, http.StatusUnsupportedMediaType, "format", "xml")
+	s.Do("GET", "/api/latest/fleet/queries/999999/report", nil, http.StatusNotFound)
+
+	// changing the SQL of the query discards the stale results
+	s.DoJSON("PATCH", fmt.Sprintf("/api/latest/fleet/queries/%d", queryID), fleet.QueryPayload{
+		Query: ptr.String("SELECT days FROM uptime;"),
+	}, http.StatusOK, &modifyQueryResponse{})
+	reportResp = getQueryReportResponse{}
+	s.DoJSON("GET", fmt.Sprintf("/api/latest/fleet/queries/%d/report", queryID), nil, http.StatusOK, &reportResp)
+	require.Empty(t, reportResp.Results)
+}
+
+func (s *integrationTestSuite) TestPolicyHistory() {
+	t := s.T()
+	ctx := context.Background()
+
+	gpResp := globalPolicyResponse{}
+	s.DoJSON("POST", "/api/latest/fleet/policies", globalPolicyRequest{Name: t.Name(), Query: "select 1;"}, http.StatusOK, &gpResp)
+	require.NotNil(t, gpResp.Policy)
+
+	host, err := s.ds.NewHost(ctx, &fleet.Host{
+		DetailUpdatedAt: time.Now(),
+		LabelUpdatedAt:  time.Now(),
+		PolicyUpdatedAt: time.Now(),
+		SeenTime:        time.Now(),
+		OsqueryHostID:   ptr.String(t.Name()),
+		NodeKey:         ptr.String(t.Name()),
+		UUID:            t.Name(),
+		Hostname:        t.Name() + "foo.local",
+		Platform:        "darwin",
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.ds.RecordPolicyQueryExecutions(ctx, host, map[uint]*bool{gpResp.Policy.ID: ptr.Bool(false)}, time.Now(), false))
+	require.NoError(t, s.ds.SnapshotPolicyStats(ctx, time.Date(2023, 3, 13, 10, 0, 0, 0, time.UTC)))
+
+	historyResp := listPolicyHistoryResponse{}
+	s.DoJSON("GET", "/api/latest/fleet/policies/history", nil, http.StatusOK, &historyResp,
+		"policy_id", fmt.Sprint(gpResp.Policy.ID), "from", "2023-03-13", "to", "2023-03-13")
+	require.Len(t, historyResp.History, 1)
+	assert.Equal(t, "2023-03-13", historyResp.History[0].Date)
+	assert.Equal(t, t.Name(), historyResp.History[0].PolicyName)
+	assert.Nil(t, historyResp.History[0].TeamID)
+	assert.Equal(t, uint(1), historyResp.History[0].FailingHostCount)
+
+	historyResp = listPolicyHistoryResponse{}
+	s.DoJSON("GET", "/api/latest/fleet/policies/history", nil, http.StatusOK, &historyResp,
+		"policy_id", fmt.Sprint(gpResp.Policy.ID), "from", "2023-03-14")
+	require.Empty(t, historyResp.History)
+
+	s.DoJSON("GET", "/api/latest/fleet/policies/history", nil, http.StatusBadRequest, &historyResp, "to", "yesterday")
+}
+
 func (s *integrationTestSuite) TestTeamPoliciesTeamNotExists() {
 	t := s.T()
 
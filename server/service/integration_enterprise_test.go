@@ -730,6 +730,50 @@ func (s *integrationEnterpriseTestSuite) TestTeamEndpoints() {
 	s.DoJSON("DELETE", fmt.Sprintf("/api/latest/fleet/teams/%d", tm1ID), nil, http.StatusNotFound, &delResp)
 }
 
+func (s *integrationEnterpriseTestSuite) TestNestedTeams() {
+	t := s.T()
+
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+
+	var tmResp teamResponse
+	s.DoJSON("POST", "/api/latest/fleet/teams", fleet.TeamPayload{Name: ptr.String(name + "_parent")}, http.StatusOK, &tmResp)
+	parent := tmResp.Team
+	require.Nil(t, parent.ParentTeamID)
+
+	// a child team inherits the agent options of its parent
+	tmResp.Team = nil
+	s.DoJSON("POST", "/api/latest/fleet/teams", fleet.TeamPayload{Name: ptr.String(name + "_child"), ParentTeamID: &parent.ID}, http.StatusOK, &tmResp)
+	child := tmResp.Team
+	require.Equal(t, &parent.ID, child.ParentTeamID)
+	require.Nil(t, child.Config.AgentOptions)
+
+	// the parent must exist
+	tmResp.Team = nil
+	s.DoJSON("POST", "/api/latest/fleet/teams", fleet.TeamPayload{Name: ptr.String(name + "_orphan"), ParentTeamID: ptr.Uint(999999)}, http.StatusUnprocessableEntity, &tmResp)
+
+	// a team cannot be its own parent nor the child of its descendants
+	tmResp.Team = nil
+	s.DoJSON("PATCH", fmt.Sprintf("/api/latest/fleet/teams/%d", parent.ID), fleet.TeamPayload{ParentTeamID: &parent.ID}, http.StatusUnprocessableEntity, &tmResp)
+	s.DoJSON("PATCH", fmt.Sprintf("/api/latest/fleet/teams/%d", parent.ID), fleet.TeamPayload{ParentTeamID: &child.ID}, http.StatusUnprocessableEntity, &tmResp)
+
+	// list the teams as a tree
+	var listResp listTeamsResponse
+	s.DoJSON("GET", "/api/latest/fleet/teams", nil, http.StatusOK, &listResp, "query", name, "tree", "true")
+	require.Len(t, listResp.Teams, 1)
+	require.Equal(t, parent.ID, listResp.Teams[0].ID)
+	require.Len(t, listResp.Teams[0].Children, 1)
+	require.Equal(t, child.ID, listResp.Teams[0].Children[0].ID)
+	require.Equal(t, ptr.String(parent.Name), listResp.Teams[0].Children[0].ParentTeamName)
+
+	// detach the child team
+	tmResp.Team = nil
+	s.DoJSON("PATCH", fmt.Sprintf("/api/latest/fleet/teams/%d", child.ID), fleet.TeamPayload{ParentTeamID: ptr.Uint(0)}, http.StatusOK, &tmResp)
+	require.Nil(t, tmResp.Team.ParentTeamID)
+
+	s.Do("DELETE", fmt.Sprintf("/api/latest/fleet/teams/%d", child.ID), nil, http.StatusOK)
+	s.Do("DELETE", fmt.Sprintf("/api/latest/fleet/teams/%d", parent.ID), nil, http.StatusOK)
+}
+
 func (s *integrationEnterpriseTestSuite) TestExternalIntegrationsTeamConfig() {
 	t := s.T()
 
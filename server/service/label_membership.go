@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/docker/go-units"
+	"github.com/fleetdm/fleet/v4/server/authz"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Apply label membership from CSV
+////////////////////////////////////////////////////////////////////////////////
+
+type applyLabelMembershipCSVRequest struct {
+	ID     uint
+	DryRun bool
+	CSV    *multipart.FileHeader
+}
+
+func (applyLabelMembershipCSVRequest) DecodeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	decoded := applyLabelMembershipCSVRequest{}
+
+	id, err := uintFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	decoded.ID = uint(id)
+
+	if err := r.ParseMultipartForm(10 * units.MiB); err != nil {
+		return nil, &fleet.BadRequestError{Message: err.Error()}
+	}
+
+	if val, ok := r.MultipartForm.Value["dry_run"]; ok && len(val) > 0 {
+		dryRun, err := strconv.ParseBool(val[0])
+		if err != nil {
+			return nil, &fleet.BadRequestError{Message: "invalid dry_run value: " + err.Error()}
+		}
+		decoded.DryRun = dryRun
+	}
+
+	fhs, ok := r.MultipartForm.File["csv"]
+	if !ok || len(fhs) < 1 {
+		return nil, &fleet.BadRequestError{Message: "no file headers for csv"}
+	}
+	decoded.CSV = fhs[0]
+
+	return &decoded, nil
+}
+
+type applyLabelMembershipCSVResponse struct {
+	*fleet.LabelMembershipDiff
+	Err error `json:"error,omitempty"`
+}
+
+func (r applyLabelMembershipCSVResponse) error() error { return r.Err }
+
+func applyLabelMembershipCSVEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*applyLabelMembershipCSVRequest)
+
+	f, err := req.CSV.Open()
+	if err != nil {
+		return applyLabelMembershipCSVResponse{Err: err}, nil
+	}
+	defer f.Close()
+
+	diff, err := svc.ApplyLabelMembershipCSV(ctx, req.ID, f, req.DryRun)
+	if err != nil {
+		return applyLabelMembershipCSVResponse{Err: err}, nil
+	}
+	return applyLabelMembershipCSVResponse{LabelMembershipDiff: diff}, nil
+}
+
+func (svc *Service) ApplyLabelMembershipCSV(ctx context.Context, labelID uint, r io.Reader, dryRun bool) (*fleet.LabelMembershipDiff, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.Label{}, fleet.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	label, err := svc.ds.Label(ctx, labelID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get label")
+	}
+	if label.LabelType == fleet.LabelTypeBuiltIn || label.LabelMembershipType != fleet.LabelMembershipTypeManual {
+		return nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{
+			Message: "the hosts of a label can only be set for manual labels that are not built-in",
+		})
+	}
+
+	identifiers, err := fleet.ParseHostIdentifiersCSV(r)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, &fleet.BadRequestError{Message: err.Error()})
+	}
+
+	diff, err := svc.ds.ApplyLabelMembership(ctx, labelID, identifiers, dryRun)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "apply label membership")
+	}
+
+	if !dryRun && !diff.IsEmpty() {
+		if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeEditedLabelMembership{
+			LabelID:        diff.LabelID,
+			LabelName:      diff.LabelName,
+			Source:         fleet.LabelMembershipSourceCSV,
+			AddedCount:     len(diff.Added),
+			RemovedCount:   len(diff.Removed),
+			UnmatchedCount: len(diff.Unmatched),
+		}); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "create activity for label membership")
+		}
+	}
+	return diff, nil
+}
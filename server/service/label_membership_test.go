@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/viewer"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLabelMembershipCSV(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	labels := map[uint]*fleet.Label{
+		1: {ID: 1, Name: "cmdb", LabelType: fleet.LabelTypeRegular, LabelMembershipType: fleet.LabelMembershipTypeManual},
+		2: {ID: 2, Name: "dynamic", LabelType: fleet.LabelTypeRegular, LabelMembershipType: fleet.LabelMembershipTypeDynamic},
+		3: {ID: 3, Name: "All Hosts", LabelType: fleet.LabelTypeBuiltIn, LabelMembershipType: fleet.LabelMembershipTypeManual},
+	}
+	ds.LabelFunc = func(ctx context.Context, id uint) (*fleet.Label, error) {
+		return labels[id], nil
+	}
+	ds.ApplyLabelMembershipFunc = func(ctx context.Context, labelID uint, identifiers []string, dryRun bool) (*fleet.LabelMembershipDiff, error) {
+		require.Equal(t, uint(1), labelID)
+		require.Equal(t, []string{"host1", "C02ABC"}, identifiers)
+		return &fleet.LabelMembershipDiff{
+			LabelID:   labelID,
+			LabelName: "cmdb",
+			DryRun:    dryRun,
+			Added:     []fleet.LabelMembershipHost{{ID: 1, DisplayName: "host1"}},
+			Removed:   []fleet.LabelMembershipHost{},
+			Unmatched: []string{"C02ABC"},
+		}, nil
+	}
+	var activity *fleet.ActivityTypeEditedLabelMembership
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, a fleet.ActivityDetails) error {
+		require.NotNil(t, user)
+		act := a.(fleet.ActivityTypeEditedLabelMembership)
+		activity = &act
+		return nil
+	}
+
+	const csv = "hostname\nhost1\nC02ABC\n"
+
+	t.Run("authorization", func(t *testing.T) {
+		for _, u := range []*fleet.User{
+			{GlobalRole: ptr.String(fleet.RoleObserver)},
+			{Teams: []fleet.UserTeam{{Team: fleet.Team{ID: 1}, Role: fleet.RoleMaintainer}}},
+		} {
+			ctx := viewer.NewContext(ctx, viewer.Viewer{User: u})
+			_, err := svc.ApplyLabelMembershipCSV(ctx, 1, strings.NewReader(csv), true)
+			checkAuthErr(t, true, err)
+		}
+		ctx := viewer.NewContext(ctx, viewer.Viewer{User: &fleet.User{GlobalRole: ptr.String(fleet.RoleMaintainer)}})
+		_, err := svc.ApplyLabelMembershipCSV(ctx, 1, strings.NewReader(csv), true)
+		checkAuthErr(t, false, err)
+	})
+
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	t.Run("not a manual label", func(t *testing.T) {
+		for _, id := range []uint{2, 3} {
+			_, err := svc.ApplyLabelMembershipCSV(ctx, id, strings.NewReader(csv), false)
+			require.ErrorContains(t, err, "only be set for manual labels")
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		activity = nil
+		diff, err := svc.ApplyLabelMembershipCSV(ctx, 1, strings.NewReader(csv), true)
+		require.NoError(t, err)
+		require.True(t, diff.DryRun)
+		require.Len(t, diff.Added, 1)
+		require.Equal(t, []string{"C02ABC"}, diff.Unmatched)
+		require.Nil(t, activity)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		activity = nil
+		diff, err := svc.ApplyLabelMembershipCSV(ctx, 1, strings.NewReader(csv), false)
+		require.NoError(t, err)
+		require.False(t, diff.DryRun)
+		require.Equal(t, &fleet.ActivityTypeEditedLabelMembership{
+			LabelID:        1,
+			LabelName:      "cmdb",
+			Source:         fleet.LabelMembershipSourceCSV,
+			AddedCount:     1,
+			UnmatchedCount: 1,
+		}, activity)
+	})
+}
+
+func TestApplyLabelSpecsHostsSyncURL(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	ds.ApplyLabelSpecsFunc = func(ctx context.Context, specs []*fleet.LabelSpec) error {
+		return nil
+	}
+
+	err := svc.ApplyLabelSpecs(ctx, []*fleet.LabelSpec{{
+		Name:                "cmdb",
+		LabelMembershipType: fleet.LabelMembershipTypeManual,
+		HostsSyncURL:        "https://cmdb.example.com/groups/finance/hosts",
+	}})
+	require.NoError(t, err)
+
+	err = svc.ApplyLabelSpecs(ctx, []*fleet.LabelSpec{{
+		Name:                "cmdb",
+		LabelMembershipType: fleet.LabelMembershipTypeManual,
+		HostsSyncURL:        "ftp://cmdb.example.com/hosts",
+	}})
+	require.ErrorContains(t, err, "hosts_sync_url must be an http or https URL")
+
+	err = svc.ApplyLabelSpecs(ctx, []*fleet.LabelSpec{{
+		Name:                "dynamic",
+		Query:               "SELECT 1",
+		LabelMembershipType: fleet.LabelMembershipTypeDynamic,
+		HostsSyncURL:        "https://cmdb.example.com/hosts",
+	}})
+	require.ErrorContains(t, err, "declared as dynamic but contains `hosts_sync_url` key")
+}
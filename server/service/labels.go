@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
 	"github.com/fleetdm/fleet/v4/server/contexts/viewer"
@@ -393,10 +395,18 @@ func (svc *Service) ApplyLabelSpecs(ctx context.Context, specs []*fleet.LabelSpe
 		if spec.LabelMembershipType == fleet.LabelMembershipTypeDynamic && len(spec.Hosts) > 0 {
 			return ctxerr.Errorf(ctx, "label %s is declared as dynamic but contains `hosts` key", spec.Name)
 		}
-		if spec.LabelMembershipType == fleet.LabelMembershipTypeManual && spec.Hosts == nil {
+		if spec.LabelMembershipType == fleet.LabelMembershipTypeDynamic && spec.HostsSyncURL != "" {
+			return ctxerr.Errorf(ctx, "label %s is declared as dynamic but contains `hosts_sync_url` key", spec.Name)
+		}
+		if spec.LabelMembershipType == fleet.LabelMembershipTypeManual && spec.Hosts == nil && spec.HostsSyncURL == "" {
 			// Hosts list doesn't need to contain anything, but it should at least not be nil.
 			return ctxerr.Errorf(ctx, "label %s is declared as manual but contains no `hosts key`", spec.Name)
 		}
+		if spec.HostsSyncURL != "" {
+			if u, err := url.Parse(spec.HostsSyncURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("hosts_sync_url", fmt.Sprintf("label %s: hosts_sync_url must be an http or https URL", spec.Name)))
+			}
+		}
 	}
 	return svc.ds.ApplyLabelSpecs(ctx, specs)
 }
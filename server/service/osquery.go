@@ -188,6 +188,10 @@ func (svc *Service) EnrollAgent(ctx context.Context, enrollSecret, hostIdentifie
 	}
 
 	if save {
+		if err := svc.applyTeamAssignmentRules(ctx, host, appConfig.TeamAssignmentRules); err != nil {
+			level.Error(svc.logger).Log("msg", "apply team assignment rules on enroll", "err", err)
+		}
+
 		if appConfig.ServerSettings.DeferredSaveHost {
 			go svc.serialUpdateHost(host)
 		} else {
@@ -373,10 +377,38 @@ func (svc *Service) GetClientConfig(ctx context.Context) (map[string]interface{}
 			return nil, osqueryError{message: "database error: " + err.Error()}
 		}
 
+		// the hosts of a team may override the queries of the global schedule
+		var overrides map[uint]*fleet.TeamScheduledQueryOverride
+		if host.TeamID != nil && pack.Type != nil && *pack.Type == "global" {
+			teamOverrides, err := svc.ds.ListTeamScheduledQueryOverrides(ctx, *host.TeamID)
+			if err != nil {
+				return nil, osqueryError{message: "database error: " + err.Error()}
+			}
+			overrides = make(map[uint]*fleet.TeamScheduledQueryOverride, len(teamOverrides))
+			for _, o := range teamOverrides {
+				overrides[o.ScheduledQueryID] = o
+			}
+		}
+
 		// the serializable osquery config struct expects content in a
 		// particular format, so we do the conversion here
 		configQueries := fleet.Queries{}
 		for _, query := range queries {
+			if o := overrides[query.ID]; o != nil {
+				if o.Disabled {
+					continue
+				}
+				if o.Interval != nil {
+					query.Interval = *o.Interval
+				}
+				if o.Shard != nil {
+					query.Shard = o.Shard
+				}
+				if o.Platform != nil {
+					query.Platform = o.Platform
+				}
+			}
+
 			queryContent := fleet.QueryContent{
 				Query:    query.Query,
 				Interval: query.Interval,
@@ -825,6 +857,8 @@ func (svc *Service) SubmitDistributedQueryResults(
 	additionalUpdated := false
 	labelResults := map[uint]*bool{}
 	policyResults := map[uint]*bool{}
+	teamAssignmentBefore := hostTeamAssignmentAttributes(host)
+	emailsReported := false
 
 	svc.maybeDebugHost(ctx, host, results, statuses, messages)
 
@@ -850,6 +884,9 @@ func (svc *Service) SubmitDistributedQueryResults(
 		}
 
 		detailUpdated = detailUpdated || ingestedDetailUpdated
+		if query == hostDetailQueryPrefix+"google_chrome_profiles" && !failed {
+			emailsReported = true
+		}
 		additionalUpdated = additionalUpdated || ingestedAdditionalUpdated
 	}
 
@@ -866,19 +903,19 @@ func (svc *Service) SubmitDistributedQueryResults(
 
 	if len(policyResults) > 0 {
 
-		// filter policy results for webhooks
+		// filter policy results for automations (webhook or integrations)
 		var policyIDs []uint
-		if ac.WebhookSettings.FailingPoliciesWebhook.Enable {
-			policyIDs = append(policyIDs, ac.WebhookSettings.FailingPoliciesWebhook.PolicyIDs...)
+		for id := range automationPolicies(ac.WebhookSettings.FailingPoliciesWebhook, ac.Integrations) {
+			policyIDs = append(policyIDs, id)
 		}
 
 		if host.TeamID != nil {
-			team, err := svc.ds.Team(ctx, *host.TeamID)
+			team, err := fleet.TeamWithInheritedConfig(ctx, svc.ds, *host.TeamID)
 			if err != nil {
 				logging.WithErr(ctx, err)
 			} else {
-				if team.Config.WebhookSettings.FailingPoliciesWebhook.Enable {
-					policyIDs = append(policyIDs, team.Config.WebhookSettings.FailingPoliciesWebhook.PolicyIDs...)
+				for id := range teamAutomationPolicies(team.Config.WebhookSettings.FailingPoliciesWebhook, team.Config.Integrations) {
+					policyIDs = append(policyIDs, id)
 				}
 			}
 		}
@@ -924,6 +961,10 @@ func (svc *Service) SubmitDistributedQueryResults(
 		host.DetailUpdatedAt = svc.clock.Now()
 	}
 
+	if err := svc.maybeApplyTeamAssignmentRules(ctx, host, ac.TeamAssignmentRules, teamAssignmentBefore, labelResults, emailsReported); err != nil {
+		logging.WithErr(ctx, err)
+	}
+
 	refetchRequested := host.RefetchRequested
 	if refetchRequested {
 		host.RefetchRequested = false
@@ -1058,6 +1099,15 @@ func (svc *Service) ingestDistributedQuery(ctx context.Context, host fleet.Host,
 		res.Error = &errMsg
 	}
 
+	persistResults := svc.config.App.LiveQueryResultsRetention > 0
+	if persistResults {
+		// a failure to persist the result must not prevent it from being
+		// sent to the live listener, nor fail the host's distributed write.
+		if err := svc.ds.SaveDistributedQueryCampaignResult(ctx, &res); err != nil {
+			logging.WithErr(ctx, ctxerr.Wrap(ctx, err, "persisting live query result"))
+		}
+	}
+
 	err = svc.resultStore.WriteResult(res)
 	if err != nil {
 		var pse pubsub.Error
@@ -1066,6 +1116,21 @@ func (svc *Service) ingestDistributedQuery(ctx context.Context, host fleet.Host,
 			return osqueryError{message: "writing results: " + err.Error()}
 		}
 
+		if persistResults {
+			// The results are persisted so the campaign keeps running without
+			// a listener, until it is reopened and completed or it expires.
+			campaign, err := svc.ds.DistributedQueryCampaign(ctx, uint(campaignID))
+			if err != nil {
+				return osqueryError{message: "loading campaign: " + err.Error()}
+			}
+			if campaign.Status != fleet.QueryComplete {
+				if err := svc.liveQueryStore.QueryCompletedByHost(strconv.Itoa(campaignID), host.ID); err != nil {
+					return osqueryError{message: "record query completion: " + err.Error()}
+				}
+				return nil
+			}
+		}
+
 		// If there are no subscribers, the campaign is "orphaned"
 		// and should be closed so that we don't continue trying to
 		// execute that query when we can't write to any subscriber
@@ -1285,5 +1350,81 @@ func (svc *Service) SubmitResultLogs(ctx context.Context, logs []json.RawMessage
 	if err := svc.osqueryLogWriter.Result.Write(ctx, logs); err != nil {
 		return osqueryError{message: "error writing result logs: " + err.Error()}
 	}
+
+	// Storing the results is best effort, the logs were already written to the
+	// result log destination so failing here would only make osquery send them
+	// again.
+	if host, ok := hostctx.FromContext(ctx); ok {
+		if err := svc.storeQueryResults(ctx, host, logs); err != nil {
+			logging.WithErr(ctx, ctxerr.Wrap(ctx, err, "store query results"))
+		}
+	}
+	return nil
+}
+
+// scheduledQueryResultLog holds the fields of an osquery result log that are
+// needed to store the results of the scheduled queries.
+type scheduledQueryResultLog struct {
+	Name     string                   `json:"name"`
+	Action   string                   `json:"action"`
+	UnixTime interface{}              `json:"unixTime"`
+	Snapshot []map[string]interface{} `json:"snapshot"`
+}
+
+// storeQueryResults stores the snapshot results of the scheduled queries that
+// have store_results enabled, overwriting the previous results of the host.
+func (svc *Service) storeQueryResults(ctx context.Context, host *fleet.Host, logs []json.RawMessage) error {
+	// cache the lookup of the queries by scheduled name for the batch of logs, a
+	// nil query means the scheduled query does not store its results.
+	queries := make(map[string]*fleet.Query)
+	for _, raw := range logs {
+		var result scheduledQueryResultLog
+		if err := json.Unmarshal(raw, &result); err != nil {
+			level.Debug(svc.logger).Log("msg", "unmarshal result log", "host", host.Hostname, "err", err)
+			continue
+		}
+		// only snapshot queries report their full results
+		if result.Action != "snapshot" {
+			continue
+		}
+
+		query, ok := queries[result.Name]
+		if !ok {
+			packName, scheduledName, ok := fleet.SplitScheduledQueryLogName(result.Name, host)
+			if ok {
+				var err error
+				query, err = svc.ds.QueryStoringResultsByScheduledName(ctx, packName, scheduledName)
+				if err != nil && !fleet.IsNotFound(err) {
+					return err
+				}
+			}
+			queries[result.Name] = query
+		}
+		if query == nil {
+			continue
+		}
+
+		rows := make([]json.RawMessage, 0, len(result.Snapshot))
+		for _, row := range result.Snapshot {
+			// osquery may log numbers as such, store all values as strings
+			columns := make(map[string]string, len(row))
+			for k, v := range row {
+				columns[k] = cast.ToString(v)
+			}
+			b, err := json.Marshal(columns)
+			if err != nil {
+				return ctxerr.Wrap(ctx, err, "marshal query result row")
+			}
+			rows = append(rows, b)
+		}
+
+		fetchedAt := time.Now()
+		if unixTime := cast.ToInt64(result.UnixTime); unixTime > 0 {
+			fetchedAt = time.Unix(unixTime, 0)
+		}
+		if err := svc.ds.OverwriteQueryResultRows(ctx, query.ID, host.ID, rows, fetchedAt, svc.config.Osquery.MaxQueryReportRows); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -146,6 +146,89 @@ func TestGetClientConfig(t *testing.T) {
 	)
 }
 
+func TestGetClientConfigTeamScheduleOverrides(t *testing.T) {
+	ds := new(mock.Store)
+	ds.ListPacksForHostFunc = func(ctx context.Context, hid uint) ([]*fleet.Pack, error) {
+		return []*fleet.Pack{
+			{ID: 1, Name: "Global Schedule", Type: ptr.String("global")},
+			{ID: 2, Name: "Team: team1", Type: ptr.String("team-1")},
+		}, nil
+	}
+	ds.ListScheduledQueriesInPackFunc = func(ctx context.Context, pid uint) (fleet.ScheduledQueryList, error) {
+		switch pid {
+		case 1:
+			return []*fleet.ScheduledQuery{
+				{ID: 1, Name: "time", Query: "select * from time", Interval: 30},
+				{ID: 2, Name: "disabled", Query: "select 1", Interval: 30},
+				{ID: 3, Name: "uptime", Query: "select * from uptime", Interval: 60, Shard: ptr.Uint(10)},
+			}, nil
+		case 2:
+			return []*fleet.ScheduledQuery{
+				{ID: 4, Name: "team_time", Query: "select * from time", Interval: 30},
+			}, nil
+		default:
+			return []*fleet.ScheduledQuery{}, nil
+		}
+	}
+	ds.ListTeamScheduledQueryOverridesFunc = func(ctx context.Context, teamID uint) (fleet.TeamScheduledQueryOverrideList, error) {
+		require.Equal(t, uint(1), teamID)
+		return fleet.TeamScheduledQueryOverrideList{
+			{TeamID: 1, ScheduledQueryID: 2, Name: "disabled", Disabled: true},
+			{TeamID: 1, ScheduledQueryID: 3, Name: "uptime", Interval: ptr.Uint(120), Shard: ptr.Uint(50), Platform: ptr.String("linux")},
+			// overrides only apply to the global schedule
+			{TeamID: 1, ScheduledQueryID: 4, Name: "team_time", Disabled: true},
+		}, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{AgentOptions: ptr.RawMessage(json.RawMessage(`{"config":{"options":{"baz":"bar"}}}`))}, nil
+	}
+	ds.TeamAgentOptionsFunc = func(ctx context.Context, id uint) (*json.RawMessage, error) {
+		return nil, nil
+	}
+
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	// hosts without a team use the global schedule as is
+	conf, err := svc.GetClientConfig(hostctx.NewContext(ctx, &fleet.Host{ID: 1}))
+	require.NoError(t, err)
+	require.False(t, ds.ListTeamScheduledQueryOverridesFuncInvoked)
+	assert.JSONEq(t, `{
+		"Global Schedule": {
+			"queries": {
+				"time":{"query":"select * from time","interval":30},
+				"disabled":{"query":"select 1","interval":30},
+				"uptime":{"query":"select * from uptime","interval":60,"shard":10}
+			}
+		},
+		"Team: team1": {
+			"queries": {
+				"team_time":{"query":"select * from time","interval":30}
+			}
+		}
+	}`,
+		string(conf["packs"].(json.RawMessage)),
+	)
+
+	conf, err = svc.GetClientConfig(hostctx.NewContext(ctx, &fleet.Host{ID: 2, TeamID: ptr.Uint(1)}))
+	require.NoError(t, err)
+	require.True(t, ds.ListTeamScheduledQueryOverridesFuncInvoked)
+	assert.JSONEq(t, `{
+		"Global Schedule": {
+			"queries": {
+				"time":{"query":"select * from time","interval":30},
+				"uptime":{"query":"select * from uptime","interval":120,"shard":50,"platform":"linux"}
+			}
+		},
+		"Team: team1": {
+			"queries": {
+				"team_time":{"query":"select * from time","interval":30}
+			}
+		}
+	}`,
+		string(conf["packs"].(json.RawMessage)),
+	)
+}
+
 func TestAgentOptionsForHost(t *testing.T) {
 	ds := new(mock.Store)
 	svc, ctx := newTestService(t, ds, nil, nil)
@@ -497,6 +580,73 @@ func TestSubmitResultLogs(t *testing.T) {
 	assert.Equal(t, results, testLogger.logs)
 }
 
+func TestSubmitResultLogsStoresQueryResults(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	serv := ((svc.(validationMiddleware)).Service).(*Service)
+	serv.osqueryLogWriter = &OsqueryLogger{Result: &testJSONLogger{}}
+
+	ds.QueryStoringResultsByScheduledNameFunc = func(ctx context.Context, packName, scheduledQueryName string) (*fleet.Query, error) {
+		if packName == "Global" && scheduledQueryName == "uptime/days" {
+			return &fleet.Query{ID: 42, StoreResults: true}, nil
+		}
+		if packName == "Team: Servers/EU" && scheduledQueryName == "uptime" {
+			return &fleet.Query{ID: 43, StoreResults: true}, nil
+		}
+		return nil, notFoundError{}
+	}
+	type stored struct {
+		queryID, hostID uint
+		rows            []string
+		fetchedAt       time.Time
+		maxRows         int
+	}
+	var got []stored
+	ds.OverwriteQueryResultRowsFunc = func(ctx context.Context, queryID, hostID uint, rows []json.RawMessage, fetchedAt time.Time, maxRows int) error {
+		var strRows []string
+		for _, r := range rows {
+			strRows = append(strRows, string(r))
+		}
+		got = append(got, stored{queryID, hostID, strRows, fetchedAt, maxRows})
+		return nil
+	}
+
+	logs := []string{
+		`{"snapshot":[{"days":"1","hours":2},{"days":"3","hours":4}],"action":"snapshot","name":"pack/Global/uptime/days","unixTime":1484078931}`,
+		`{"snapshot":[{"a":"1"}],"action":"snapshot","name":"pack/Global/not-stored","unixTime":1484078931}`,
+		`{"snapshot":[{"a":"1"}],"action":"snapshot","name":"not-a-pack","unixTime":1484078931}`,
+		`{"diffResults":{"added":[{"days":"1"}]},"name":"pack/Global/uptime/days","unixTime":1484078931}`,
+		`{"snapshot":[],"action":"snapshot","name":"pack/Global/uptime/days","unixTime":"1484078932"}`,
+	}
+	var results []json.RawMessage
+	err := json.Unmarshal([]byte(fmt.Sprintf("[%s]", strings.Join(logs, ","))), &results)
+	require.NoError(t, err)
+
+	ctx = hostctx.NewContext(ctx, &fleet.Host{ID: 7})
+	err = serv.SubmitResultLogs(ctx, results)
+	require.NoError(t, err)
+
+	// the lookup of the scheduled queries is done once per name
+	require.Equal(t, []stored{
+		{42, 7, []string{`{"days":"1","hours":"2"}`, `{"days":"3","hours":"4"}`}, time.Unix(1484078931, 0), 1000},
+		{42, 7, nil, time.Unix(1484078932, 0), 1000},
+	}, got)
+
+	// the schedule pack of a team with a "/" in its name is resolved using the
+	// team of the host
+	got = nil
+	results = []json.RawMessage{
+		json.RawMessage(`{"snapshot":[{"days":"5"}],"action":"snapshot","name":"pack/Team: Servers/EU/uptime","unixTime":1484078933}`),
+	}
+	ctx = hostctx.NewContext(ctx, &fleet.Host{ID: 8, TeamID: ptr.Uint(1), TeamName: ptr.String("Servers/EU")})
+	err = serv.SubmitResultLogs(ctx, results)
+	require.NoError(t, err)
+	require.Equal(t, []stored{
+		{43, 8, []string{`{"days":"5"}`}, time.Unix(1484078933, 0), 1000},
+	}, got)
+}
+
 func verifyDiscovery(t *testing.T, queries, discovery map[string]string) {
 	assert.Equal(t, len(queries), len(discovery))
 	// discoveryUsed holds the queries where we know use the distributed discovery feature.
@@ -507,6 +657,9 @@ func verifyDiscovery(t *testing.T, queries, discovery map[string]string) {
 		hostDetailQueryPrefix + "windows_update_history": {},
 		hostDetailQueryPrefix + "kubequery_info":         {},
 		hostDetailQueryPrefix + "orbit_info":             {},
+		hostDetailQueryPrefix + "orbit_info_channels":    {},
+		hostDetailQueryPrefix + "software_linux":         {},
+		hostDetailQueryPrefix + "software_linux_apk":     {},
 	}
 	for name := range queries {
 		require.NotEmpty(t, discovery[name])
@@ -1120,8 +1273,8 @@ func TestDetailQueries(t *testing.T) {
 	// queries)
 	queries, discovery, acc, err := svc.GetDistributedQueries(ctx)
 	require.NoError(t, err)
-	// +1 for software inventory
-	if expected := expectedDetailQueriesForPlatform(host.Platform); !assert.Equal(t, len(expected)+1, len(queries)) {
+	// +2 for software inventory (software_linux and software_linux_apk)
+	if expected := expectedDetailQueriesForPlatform(host.Platform); !assert.Equal(t, len(expected)+2, len(queries)) {
 		// this is just to print the diff between the expected and actual query
 		// keys when the count assertion fails, to help debugging - they are not
 		// expected to match.
@@ -1819,6 +1972,91 @@ func TestIngestDistributedQuery(t *testing.T) {
 	lq.AssertExpectations(t)
 }
 
+func TestIngestDistributedQueryPersistedResults(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	ds := new(mock.Store)
+	rs := pubsub.NewInmemQueryResults()
+	lq := live_query_mock.New(t)
+	cfg := config.TestConfig()
+	cfg.App.LiveQueryResultsRetention = time.Hour
+	svc := &Service{
+		ds:             ds,
+		resultStore:    rs,
+		liveQueryStore: lq,
+		logger:         log.NewNopLogger(),
+		clock:          mockClock,
+		config:         cfg,
+	}
+
+	campaign := &fleet.DistributedQueryCampaign{
+		ID:     42,
+		Status: fleet.QueryRunning,
+		UpdateCreateTimestamps: fleet.UpdateCreateTimestamps{
+			CreateTimestamp: fleet.CreateTimestamp{
+				CreatedAt: mockClock.Now().Add(-2 * time.Minute),
+			},
+		},
+	}
+	host := fleet.Host{ID: 1, Hostname: "foo"}
+
+	var saved []*fleet.DistributedQueryResult
+	ds.SaveDistributedQueryCampaignResultFunc = func(ctx context.Context, result *fleet.DistributedQueryResult) error {
+		saved = append(saved, result)
+		return nil
+	}
+	ds.DistributedQueryCampaignFunc = func(ctx context.Context, id uint) (*fleet.DistributedQueryCampaign, error) {
+		return campaign, nil
+	}
+	ds.SaveDistributedQueryCampaignFunc = func(ctx context.Context, campaign *fleet.DistributedQueryCampaign) error {
+		return nil
+	}
+	lq.On("QueryCompletedByHost", strconv.Itoa(int(campaign.ID)), host.ID).Return(nil)
+
+	// without listener, the result is persisted and the campaign keeps running
+	rows := []map[string]string{{"a": "1"}}
+	err := svc.ingestDistributedQuery(context.Background(), host, "fleet_distributed_query_42", rows, false, "")
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, campaign.ID, saved[0].DistributedQueryCampaignID)
+	assert.Equal(t, host.ID, saved[0].Host.ID)
+	assert.Equal(t, rows, saved[0].Rows)
+	assert.Nil(t, saved[0].Error)
+	lq.AssertNotCalled(t, "StopQuery", strconv.Itoa(int(campaign.ID)))
+
+	// once the campaign is completed, it is stopped like an orphaned campaign
+	campaign.Status = fleet.QueryComplete
+	lq.On("StopQuery", strconv.Itoa(int(campaign.ID))).Return(nil)
+	err = svc.ingestDistributedQuery(context.Background(), host, "fleet_distributed_query_42", nil, true, "failed")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "campaign stopped")
+	require.Len(t, saved, 2)
+	require.NotNil(t, saved[1].Error)
+	assert.Equal(t, "failed", *saved[1].Error)
+	lq.AssertExpectations(t)
+
+	// persisting errors do not prevent the result from reaching the listener
+	ds.SaveDistributedQueryCampaignResultFunc = func(ctx context.Context, result *fleet.DistributedQueryResult) error {
+		return errors.New("fail")
+	}
+	campaign.Status = fleet.QueryRunning
+	got := make(chan interface{}, 1)
+	go func() {
+		ch, err := rs.ReadChannel(context.Background(), *campaign)
+		require.NoError(t, err)
+		got <- <-ch
+	}()
+	time.Sleep(10 * time.Millisecond)
+	err = svc.ingestDistributedQuery(context.Background(), host, "fleet_distributed_query_42", rows, false, "")
+	require.NoError(t, err)
+	select {
+	case res := <-got:
+		require.IsType(t, fleet.DistributedQueryResult{}, res)
+		assert.Equal(t, rows, res.(fleet.DistributedQueryResult).Rows)
+	case <-time.After(time.Second):
+		t.Fatal("result not received by the listener")
+	}
+}
+
 func TestUpdateHostIntervals(t *testing.T) {
 	ds := new(mock.Store)
 
@@ -2483,6 +2721,83 @@ func TestPolicyQueries(t *testing.T) {
 	noPolicyResults(queries)
 }
 
+func TestPolicyIntegrationsFlippedPolicies(t *testing.T) {
+	ds := new(mock.Store)
+	lq := live_query_mock.New(t)
+	pool := redistest.SetupRedis(t, t.Name(), false, false, false)
+	failingPolicySet := redis_policy_set.NewFailingTest(t, pool)
+	svc, ctx := newTestServiceWithConfig(t, ds, config.TestConfig(), nil, lq, &TestServerOpts{
+		FailingPolicySet: failingPolicySet,
+	})
+
+	host := &fleet.Host{
+		ID:       5,
+		Platform: "darwin",
+		Hostname: "test.hostname",
+		TeamID:   ptr.Uint(1),
+	}
+	ds.HostLiteFunc = func(ctx context.Context, id uint) (*fleet.Host, error) {
+		return host, nil
+	}
+	ds.UpdateHostFunc = func(ctx context.Context, gotHost *fleet.Host) error {
+		return nil
+	}
+	// the failing policies webhooks are disabled, but integrations are enabled
+	// for the global and team policies.
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{
+			WebhookSettings: fleet.WebhookSettings{
+				FailingPoliciesWebhook: fleet.FailingPoliciesWebhookSettings{
+					PolicyIDs: []uint{1, 2},
+				},
+			},
+			Integrations: fleet.Integrations{
+				Jira: []*fleet.JiraIntegration{{EnableFailingPolicies: true}},
+			},
+		}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		return &fleet.Team{
+			ID: tid,
+			Config: fleet.TeamConfig{
+				WebhookSettings: fleet.TeamWebhookSettings{
+					FailingPoliciesWebhook: fleet.FailingPoliciesWebhookSettings{
+						PolicyIDs: []uint{4},
+					},
+				},
+				Integrations: fleet.TeamIntegrations{
+					Zendesk: []*fleet.TeamZendeskIntegration{{EnableFailingPolicies: true}},
+				},
+			},
+		}, nil
+	}
+	ds.RecordPolicyQueryExecutionsFunc = func(ctx context.Context, gotHost *fleet.Host, results map[uint]*bool, updated time.Time, deferred bool) error {
+		return nil
+	}
+	var flippingResults map[uint]*bool
+	ds.FlippingPoliciesForHostFunc = func(ctx context.Context, hostID uint, incomingResults map[uint]*bool) (newFailing []uint, newPassing []uint, err error) {
+		flippingResults = incomingResults
+		return nil, nil, nil
+	}
+	ctx = hostctx.NewContext(ctx, host)
+
+	err := svc.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{
+			hostPolicyQueryPrefix + "1": {{"col1": "val1"}},
+			hostPolicyQueryPrefix + "3": {},
+			hostPolicyQueryPrefix + "4": {},
+		},
+		map[string]fleet.OsqueryStatus{},
+		map[string]string{},
+	)
+	require.NoError(t, err)
+	require.True(t, ds.FlippingPoliciesForHostFuncInvoked)
+	require.Len(t, flippingResults, 2)
+	require.Contains(t, flippingResults, uint(1))
+	require.Contains(t, flippingResults, uint(4))
+}
+
 func TestPolicyWebhooks(t *testing.T) {
 	mockClock := clock.NewMockClock()
 	ds := new(mock.Store)
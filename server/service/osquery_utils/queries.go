@@ -520,6 +520,13 @@ var extraDetailQueries = map[string]DetailQuery{
 		DirectIngestFunc: directIngestOrbitInfo,
 		Discovery:        discoveryTable("orbit_info"),
 	},
+	// the channels are queried separately so that the version is still
+	// ingested from the versions of Orbit that do not report them.
+	"orbit_info_channels": {
+		Query:            `SELECT orbit_channel, osqueryd_channel, desktop_channel FROM orbit_info`,
+		DirectIngestFunc: directIngestOrbitInfoChannels,
+		Discovery:        discoveryTable("orbit_info"),
+	},
 	"disk_encryption_darwin": {
 		Query:            `SELECT 1 FROM disk_encryption WHERE user_uuid IS NOT "" AND filevault_status = 'on' LIMIT 1;`,
 		Platforms:        []string{"darwin"},
@@ -763,7 +770,30 @@ SELECT
   '' AS arch
 FROM python_packages;
 `),
+	Platforms: fleet.HostLinuxOSs,
+	// On hosts using apk, software_linux_apk runs instead.
+	Discovery:        `SELECT 1 WHERE NOT EXISTS (` + strings.TrimSuffix(discoveryTable("apk_packages"), ";") + `);`,
+	DirectIngestFunc: directIngestSoftware,
+}
+
+// softwareLinuxAPK is softwareLinux with the packages installed with apk (e.g. on Alpine Linux),
+// which are listed by the apk_packages table of the fleetd osquery extension. It only runs on the
+// hosts where that table is available, as a failing table would fail the whole query.
+var softwareLinuxAPK = DetailQuery{
+	Query: strings.TrimSuffix(softwareLinux.Query, ";\n") + `
+UNION
+SELECT
+  name AS name,
+  version AS version,
+  'Package (APK)' AS type,
+  'apk_packages' AS source,
+  '' AS release,
+  '' AS vendor,
+  arch AS arch
+FROM apk_packages;
+`,
 	Platforms:        fleet.HostLinuxOSs,
+	Discovery:        discoveryTable("apk_packages"),
 	DirectIngestFunc: directIngestSoftware,
 }
 
@@ -851,6 +881,24 @@ func directIngestOrbitInfo(ctx context.Context, logger log.Logger, host *fleet.H
 	return nil
 }
 
+// directIngestOrbitInfoChannels ingests the update channels from the
+// orbit_info extension table.
+func directIngestOrbitInfoChannels(ctx context.Context, logger log.Logger, host *fleet.Host, ds fleet.Datastore, rows []map[string]string) error {
+	if len(rows) != 1 {
+		return ctxerr.Errorf(ctx, "directIngestOrbitInfoChannels invalid number of rows: %d", len(rows))
+	}
+	channels := fleet.UpdateChannels{
+		Orbit:    rows[0]["orbit_channel"],
+		Osqueryd: rows[0]["osqueryd_channel"],
+		Desktop:  rows[0]["desktop_channel"],
+	}
+	if err := ds.SetOrUpdateHostOrbitChannels(ctx, host.ID, channels); err != nil {
+		return ctxerr.Wrap(ctx, err, "directIngestOrbitInfoChannels update host orbit channels")
+	}
+
+	return nil
+}
+
 // directIngestOSWindows ingests selected operating system data from a host on a Windows platform
 func directIngestOSWindows(ctx context.Context, logger log.Logger, host *fleet.Host, ds fleet.Datastore, rows []map[string]string) error {
 	if len(rows) != 1 {
@@ -1324,6 +1372,7 @@ func GetDetailQueries(
 	if features != nil && features.EnableSoftwareInventory {
 		generatedMap["software_macos"] = softwareMacOS
 		generatedMap["software_linux"] = softwareLinux
+		generatedMap["software_linux_apk"] = softwareLinuxAPK
 		generatedMap["software_windows"] = softwareWindows
 	}
 
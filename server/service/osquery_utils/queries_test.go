@@ -2,6 +2,7 @@ package osquery_utils
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"sort"
@@ -16,6 +17,7 @@ import (
 	"github.com/fleetdm/fleet/v4/server/ptr"
 	"github.com/fleetdm/fleet/v4/server/service/async"
 	"github.com/go-kit/kit/log"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -258,6 +260,7 @@ func TestGetDetailQueries(t *testing.T) {
 		"windows_update_history",
 		"kubequery_info",
 		"orbit_info",
+		"orbit_info_channels",
 		"disk_encryption_darwin",
 		"disk_encryption_linux",
 		"disk_encryption_windows",
@@ -267,7 +270,7 @@ func TestGetDetailQueries(t *testing.T) {
 	sortedKeysCompare(t, queriesNoConfig, baseQueries)
 
 	queriesWithoutWinOSVuln := GetDetailQueries(context.Background(), config.FleetConfig{Vulnerabilities: config.VulnerabilitiesConfig{DisableWinOSVulnerabilities: true}}, nil, nil)
-	require.Len(t, queriesWithoutWinOSVuln, 23)
+	require.Len(t, queriesWithoutWinOSVuln, 24)
 
 	queriesWithUsers := GetDetailQueries(context.Background(), config.FleetConfig{App: config.AppConfig{EnableScheduledQueryStats: true}}, nil, &fleet.Features{EnableHostUsers: true})
 	qs := append(baseQueries, "users", "scheduled_query_stats")
@@ -275,7 +278,7 @@ func TestGetDetailQueries(t *testing.T) {
 	sortedKeysCompare(t, queriesWithUsers, qs)
 
 	queriesWithUsersAndSoftware := GetDetailQueries(context.Background(), config.FleetConfig{App: config.AppConfig{EnableScheduledQueryStats: true}}, nil, &fleet.Features{EnableHostUsers: true, EnableSoftwareInventory: true})
-	qs = append(baseQueries, "users", "software_macos", "software_linux", "software_windows", "scheduled_query_stats")
+	qs = append(baseQueries, "users", "software_macos", "software_linux", "software_linux_apk", "software_windows", "scheduled_query_stats")
 	require.Len(t, queriesWithUsersAndSoftware, len(qs))
 	sortedKeysCompare(t, queriesWithUsersAndSoftware, qs)
 }
@@ -530,6 +533,28 @@ func TestDirectIngestBattery(t *testing.T) {
 	require.True(t, ds.ReplaceHostBatteriesFuncInvoked)
 }
 
+func TestDirectIngestOrbitInfoChannels(t *testing.T) {
+	ds := new(mock.Store)
+	ds.SetOrUpdateHostOrbitChannelsFunc = func(ctx context.Context, hostID uint, channels fleet.UpdateChannels) error {
+		require.Equal(t, uint(1), hostID)
+		require.Equal(t, fleet.UpdateChannels{Orbit: "stable", Osqueryd: "5.8.1", Desktop: "edge"}, channels)
+		return nil
+	}
+
+	host := fleet.Host{
+		ID: 1,
+	}
+
+	err := directIngestOrbitInfoChannels(context.Background(), log.NewNopLogger(), &host, ds, []map[string]string{
+		{"orbit_channel": "stable", "osqueryd_channel": "5.8.1", "desktop_channel": "edge"},
+	})
+	require.NoError(t, err)
+	require.True(t, ds.SetOrUpdateHostOrbitChannelsFuncInvoked)
+
+	err = directIngestOrbitInfoChannels(context.Background(), log.NewNopLogger(), &host, ds, nil)
+	require.Error(t, err)
+}
+
 func TestDirectIngestOSWindows(t *testing.T) {
 	ds := new(mock.Store)
 
@@ -761,6 +786,47 @@ func TestDirectIngestSoftware(t *testing.T) {
 	})
 }
 
+func TestSoftwareLinuxAPK(t *testing.T) {
+	queries := GetDetailQueries(context.Background(), config.FleetConfig{}, nil, &fleet.Features{EnableSoftwareInventory: true})
+	linux, apk := queries["software_linux"], queries["software_linux_apk"]
+	require.Contains(t, apk.Query, strings.TrimSuffix(linux.Query, ";\n"))
+	require.Contains(t, apk.Query, "FROM apk_packages;")
+
+	// exactly one of the queries runs, depending on whether the apk_packages table is available
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE osquery_registry (active INTEGER, registry TEXT, name TEXT)`)
+	require.NoError(t, err)
+
+	discovered := func(query string) bool {
+		rows, err := db.Query(query)
+		require.NoError(t, err)
+		defer rows.Close()
+		return rows.Next()
+	}
+	require.True(t, discovered(linux.Discovery))
+	require.False(t, discovered(apk.Discovery))
+
+	_, err = db.Exec(`INSERT INTO osquery_registry VALUES (1, 'table', 'apk_packages')`)
+	require.NoError(t, err)
+	require.False(t, discovered(linux.Discovery))
+	require.True(t, discovered(apk.Discovery))
+
+	ds := new(mock.Store)
+	ds.UpdateHostSoftwareFunc = func(ctx context.Context, hostID uint, software []fleet.Software) error {
+		require.Equal(t, []fleet.Software{
+			{Name: "musl", Version: "1.2.3-r4", Source: "apk_packages", Arch: "x86_64"},
+		}, software)
+		return nil
+	}
+	err = apk.DirectIngestFunc(context.Background(), log.NewNopLogger(), &fleet.Host{ID: 1}, ds, []map[string]string{
+		{"name": "musl", "version": "1.2.3-r4", "type": "Package (APK)", "source": "apk_packages", "release": "", "vendor": "", "arch": "x86_64"},
+	})
+	require.NoError(t, err)
+	require.True(t, ds.UpdateHostSoftwareFuncInvoked)
+}
+
 func TestDirectIngestWindowsUpdateHistory(t *testing.T) {
 	ds := new(mock.Store)
 	ds.InsertWindowsUpdatesFunc = func(ctx context.Context, hostID uint, updates []fleet.WindowsUpdate) error {
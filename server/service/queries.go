@@ -3,10 +3,15 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"time"
 
 	"github.com/fleetdm/fleet/v4/server/authz"
+	authzctx "github.com/fleetdm/fleet/v4/server/contexts/authz"
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
 	"github.com/fleetdm/fleet/v4/server/contexts/logging"
 	"github.com/fleetdm/fleet/v4/server/contexts/viewer"
@@ -46,6 +51,118 @@ func (svc *Service) GetQuery(ctx context.Context, id uint) (*fleet.Query, error)
 	return svc.ds.Query(ctx, id)
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Get Query Report
+////////////////////////////////////////////////////////////////////////////////
+
+type getQueryReportRequest struct {
+	ID     uint   `url:"id"`
+	HostID *uint  `query:"host_id,optional"`
+	TeamID *uint  `query:"team_id,optional"`
+	Format string `query:"format,optional"`
+}
+
+type getQueryReportResponse struct {
+	QueryID uint                    `json:"query_id"`
+	Results []*fleet.QueryReportRow `json:"results"`
+	Err     error                   `json:"error,omitempty"`
+}
+
+func (r getQueryReportResponse) error() error { return r.Err }
+
+type getQueryReportCSVResponse struct {
+	QueryID uint                    `json:"-"`
+	Results []*fleet.QueryReportRow `json:"-"` // they get rendered explicitly, in csv
+	Err     error                   `json:"error,omitempty"`
+}
+
+func (r getQueryReportCSVResponse) error() error { return r.Err }
+
+func (r getQueryReportCSVResponse) hijackRender(ctx context.Context, w http.ResponseWriter) {
+	// the columns of the query are not known in advance, use the union of the
+	// columns of all rows, in a stable order.
+	colSet := make(map[string]struct{})
+	for _, row := range r.Results {
+		for col := range row.Columns {
+			colSet[col] = struct{}{}
+		}
+	}
+	cols := make([]string, 0, len(colSet))
+	for col := range colSet {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	recs := make([][]string, 0, len(r.Results)+1)
+	recs = append(recs, append([]string{"host_id", "host_name", "last_fetched"}, cols...))
+	for _, row := range r.Results {
+		rec := []string{fmt.Sprint(row.HostID), row.Hostname, row.LastFetched.UTC().Format(time.RFC3339)}
+		for _, col := range cols {
+			rec = append(rec, row.Columns[col])
+		}
+		recs = append(recs, rec)
+	}
+
+	w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="Query %d report %s.csv"`, r.QueryID, time.Now().Format("2006-01-02")))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if err := csv.NewWriter(w).WriteAll(recs); err != nil {
+		logging.WithErr(ctx, err)
+	}
+}
+
+func getQueryReportEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*getQueryReportRequest)
+
+	if req.Format != "" && req.Format != "json" && req.Format != "csv" {
+		// prevent returning an "unauthorized" error, we want that specific error
+		if az, ok := authzctx.FromContext(ctx); ok {
+			az.SetChecked()
+		}
+		err := ctxerr.Wrap(ctx, fleet.NewInvalidArgumentError("format", "unsupported report format").
+			WithStatus(http.StatusUnsupportedMediaType))
+		return getQueryReportResponse{Err: err}, nil
+	}
+
+	results, err := svc.QueryReport(ctx, req.ID, fleet.QueryReportListOptions{HostID: req.HostID, TeamID: req.TeamID})
+	if err != nil {
+		return getQueryReportResponse{Err: err}, nil
+	}
+	if req.Format == "csv" {
+		return getQueryReportCSVResponse{QueryID: req.ID, Results: results}, nil
+	}
+	if results == nil {
+		results = []*fleet.QueryReportRow{}
+	}
+	return getQueryReportResponse{QueryID: req.ID, Results: results}, nil
+}
+
+func (svc *Service) QueryReport(ctx context.Context, id uint, opts fleet.QueryReportListOptions) ([]*fleet.QueryReportRow, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.Query{}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+	// the report exposes the results of the hosts, so the user must also be
+	// able to list the hosts, and only sees the results of the hosts they can
+	// see.
+	if err := svc.authz.Authorize(ctx, &fleet.Host{}, fleet.ActionList); err != nil {
+		return nil, err
+	}
+
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, fleet.ErrNoContext
+	}
+
+	if _, err := svc.ds.Query(ctx, id); err != nil {
+		return nil, err
+	}
+
+	filter := fleet.TeamFilter{User: vc.User, IncludeObserver: true}
+	return svc.ds.QueryReport(ctx, id, filter, opts)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // List Queries
 ////////////////////////////////////////////////////////////////////////////////
@@ -170,6 +287,10 @@ func (svc *Service) NewQuery(ctx context.Context, p fleet.QueryPayload) (*fleet.
 		query.ObserverCanRun = *p.ObserverCanRun
 	}
 
+	if p.StoreResults != nil {
+		query.StoreResults = *p.StoreResults
+	}
+
 	vc, ok := viewer.FromContext(ctx)
 	if ok {
 		query.AuthorID = ptr.Uint(vc.UserID())
@@ -261,6 +382,10 @@ func (svc *Service) ModifyQuery(ctx context.Context, id uint, p fleet.QueryPaylo
 		query.ObserverCanRun = *p.ObserverCanRun
 	}
 
+	if p.StoreResults != nil {
+		query.StoreResults = *p.StoreResults
+	}
+
 	if err := svc.ds.SaveQuery(ctx, query); err != nil {
 		return nil, err
 	}
@@ -524,9 +649,10 @@ func (svc *Service) ApplyQuerySpecs(ctx context.Context, specs []*fleet.QuerySpe
 
 func queryFromSpec(spec *fleet.QuerySpec) *fleet.Query {
 	return &fleet.Query{
-		Name:        spec.Name,
-		Description: spec.Description,
-		Query:       spec.Query,
+		Name:         spec.Name,
+		Description:  spec.Description,
+		Query:        spec.Query,
+		StoreResults: spec.StoreResults,
 	}
 }
 
@@ -568,9 +694,10 @@ func (svc *Service) GetQuerySpecs(ctx context.Context) ([]*fleet.QuerySpec, erro
 
 func specFromQuery(query *fleet.Query) *fleet.QuerySpec {
 	return &fleet.QuerySpec{
-		Name:        query.Name,
-		Description: query.Description,
-		Query:       query.Query,
+		Name:         query.Name,
+		Description:  query.Description,
+		Query:        query.Query,
+		StoreResults: query.StoreResults,
 	}
 }
 
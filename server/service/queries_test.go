@@ -128,6 +128,9 @@ func TestQueryAuth(t *testing.T) {
 	ds.ApplyQueriesFunc = func(ctx context.Context, authID uint, queries []*fleet.Query) error {
 		return nil
 	}
+	ds.QueryReportFunc = func(ctx context.Context, queryID uint, filter fleet.TeamFilter, opts fleet.QueryReportListOptions) ([]*fleet.QueryReportRow, error) {
+		return nil, nil
+	}
 
 	testCases := []struct {
 		name            string
@@ -208,6 +211,9 @@ func TestQueryAuth(t *testing.T) {
 			_, err = svc.GetQuery(ctx, tt.qid)
 			checkAuthErr(t, tt.shouldFailRead, err)
 
+			_, err = svc.QueryReport(ctx, tt.qid, fleet.QueryReportListOptions{})
+			checkAuthErr(t, tt.shouldFailRead, err)
+
 			_, err = svc.ListQueries(ctx, fleet.ListOptions{})
 			checkAuthErr(t, tt.shouldFailRead, err)
 
@@ -48,6 +48,8 @@ type Service struct {
 	jitterMu *sync.Mutex
 	jitterH  map[time.Duration]*jitterHashTable
 
+	teamAssignmentCache *teamAssignmentCache
+
 	geoIP fleet.GeoIP
 
 	*fleet.EnterpriseOverrides
@@ -111,25 +113,26 @@ func NewService(
 	}
 
 	svc := &Service{
-		ds:                ds,
-		task:              task,
-		carveStore:        carveStore,
-		installerStore:    installerStore,
-		resultStore:       resultStore,
-		liveQueryStore:    lq,
-		logger:            logger,
-		config:            config,
-		clock:             c,
-		osqueryLogWriter:  osqueryLogger,
-		mailService:       mailService,
-		ssoSessionStore:   sso,
-		failingPolicySet:  failingPolicySet,
-		authz:             authorizer,
-		jitterH:           make(map[time.Duration]*jitterHashTable),
-		jitterMu:          new(sync.Mutex),
-		geoIP:             geoIP,
-		enrollHostLimiter: enrollHostLimiter,
-		depStorage:        depStorage,
+		ds:                  ds,
+		task:                task,
+		carveStore:          carveStore,
+		installerStore:      installerStore,
+		resultStore:         resultStore,
+		liveQueryStore:      lq,
+		logger:              logger,
+		config:              config,
+		clock:               c,
+		osqueryLogWriter:    osqueryLogger,
+		mailService:         mailService,
+		ssoSessionStore:     sso,
+		failingPolicySet:    failingPolicySet,
+		authz:               authorizer,
+		jitterH:             make(map[time.Duration]*jitterHashTable),
+		jitterMu:            new(sync.Mutex),
+		teamAssignmentCache: new(teamAssignmentCache),
+		geoIP:               geoIP,
+		enrollHostLimiter:   enrollHostLimiter,
+		depStorage:          depStorage,
 		// TODO: remove mdmStorage and mdmPushService when
 		// we remove deprecated top-level service methods
 		// from the prototype.
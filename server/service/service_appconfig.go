@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/fleetdm/fleet/v4/server"
+	"github.com/fleetdm/fleet/v4/server/config"
 	authz_ctx "github.com/fleetdm/fleet/v4/server/contexts/authz"
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
 	"github.com/fleetdm/fleet/v4/server/contexts/license"
@@ -145,14 +146,17 @@ func (svc *Service) LoggingConfig(ctx context.Context) (*fleet.Logging, error) {
 
 	loggings := []struct {
 		plugin string
+		routes []config.LogRoute
 		target *fleet.LoggingPlugin
 	}{
 		{
 			plugin: conf.Osquery.StatusLogPlugin,
+			routes: conf.Osquery.StatusLogRoutes,
 			target: &logging.Status,
 		},
 		{
 			plugin: conf.Osquery.ResultLogPlugin,
+			routes: conf.Osquery.ResultLogRoutes,
 			target: &logging.Result,
 		},
 	}
@@ -160,6 +164,7 @@ func (svc *Service) LoggingConfig(ctx context.Context) (*fleet.Logging, error) {
 	if conf.Activity.EnableAuditLog {
 		loggings = append(loggings, struct {
 			plugin string
+			routes []config.LogRoute
 			target *fleet.LoggingPlugin
 		}{
 			plugin: conf.Activity.AuditLogPlugin,
@@ -168,66 +173,129 @@ func (svc *Service) LoggingConfig(ctx context.Context) (*fleet.Logging, error) {
 	}
 
 	for _, lp := range loggings {
-		switch lp.plugin {
-		case "", "filesystem":
-			*lp.target = fleet.LoggingPlugin{
-				Plugin: "filesystem",
-				Config: fleet.FilesystemConfig{
-					FilesystemConfig: conf.Filesystem,
-				},
+		var plugins []string
+		for _, p := range strings.Split(lp.plugin, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				plugins = append(plugins, p)
 			}
-		case "kinesis":
-			*lp.target = fleet.LoggingPlugin{
-				Plugin: "kinesis",
-				Config: fleet.KinesisConfig{
-					Region:       conf.Kinesis.Region,
-					StatusStream: conf.Kinesis.StatusStream,
-					ResultStream: conf.Kinesis.ResultStream,
-					AuditStream:  conf.Kinesis.AuditStream,
-				},
-			}
-		case "firehose":
-			*lp.target = fleet.LoggingPlugin{
-				Plugin: "firehose",
-				Config: fleet.FirehoseConfig{
-					Region:       conf.Firehose.Region,
-					StatusStream: conf.Firehose.StatusStream,
-					ResultStream: conf.Firehose.ResultStream,
-					AuditStream:  conf.Firehose.AuditStream,
-				},
-			}
-		case "lambda":
-			*lp.target = fleet.LoggingPlugin{
-				Plugin: "lambda",
-				Config: fleet.LambdaConfig{
-					Region:         conf.Lambda.Region,
-					StatusFunction: conf.Lambda.StatusFunction,
-					ResultFunction: conf.Lambda.ResultFunction,
-					AuditFunction:  conf.Lambda.AuditFunction,
-				},
-			}
-		case "pubsub":
-			*lp.target = fleet.LoggingPlugin{
-				Plugin: "pubsub",
-				Config: fleet.PubSubConfig{
-					PubSubConfig: conf.PubSub,
-				},
+		}
+		if len(plugins) <= 1 && len(lp.routes) == 0 {
+			plugin, err := loggingPluginConfig(ctx, conf, lp.plugin)
+			if err != nil {
+				return nil, err
 			}
-		case "stdout":
-			*lp.target = fleet.LoggingPlugin{Plugin: "stdout"}
-		case "kafkarest":
-			*lp.target = fleet.LoggingPlugin{
-				Plugin: "kafkarest",
-				Config: fleet.KafkaRESTConfig{
-					StatusTopic: conf.KafkaREST.StatusTopic,
-					ResultTopic: conf.KafkaREST.ResultTopic,
-					AuditTopic:  conf.KafkaREST.AuditTopic,
-					ProxyHost:   conf.KafkaREST.ProxyHost,
-				},
+			*lp.target = plugin
+			continue
+		}
+
+		composite := fleet.CompositeLoggingConfig{Routes: lp.routes}
+		for _, p := range plugins {
+			plugin, err := loggingPluginConfig(ctx, conf, p)
+			if err != nil {
+				return nil, err
 			}
-		default:
-			return nil, ctxerr.Errorf(ctx, "unrecognized logging plugin: %s", lp.plugin)
+			composite.Plugins = append(composite.Plugins, plugin)
+		}
+		*lp.target = fleet.LoggingPlugin{
+			Plugin: "composite",
+			Config: composite,
 		}
 	}
 	return logging, nil
 }
+
+// loggingPluginConfig returns the configuration of a single logging plugin,
+// without secrets.
+func loggingPluginConfig(ctx context.Context, conf config.FleetConfig, plugin string) (fleet.LoggingPlugin, error) {
+	switch plugin {
+	case "", "filesystem":
+		return fleet.LoggingPlugin{
+			Plugin: "filesystem",
+			Config: fleet.FilesystemConfig{
+				FilesystemConfig: conf.Filesystem,
+			},
+		}, nil
+	case "kinesis":
+		return fleet.LoggingPlugin{
+			Plugin: "kinesis",
+			Config: fleet.KinesisConfig{
+				Region:       conf.Kinesis.Region,
+				StatusStream: conf.Kinesis.StatusStream,
+				ResultStream: conf.Kinesis.ResultStream,
+				AuditStream:  conf.Kinesis.AuditStream,
+			},
+		}, nil
+	case "firehose":
+		return fleet.LoggingPlugin{
+			Plugin: "firehose",
+			Config: fleet.FirehoseConfig{
+				Region:       conf.Firehose.Region,
+				StatusStream: conf.Firehose.StatusStream,
+				ResultStream: conf.Firehose.ResultStream,
+				AuditStream:  conf.Firehose.AuditStream,
+			},
+		}, nil
+	case "lambda":
+		return fleet.LoggingPlugin{
+			Plugin: "lambda",
+			Config: fleet.LambdaConfig{
+				Region:         conf.Lambda.Region,
+				StatusFunction: conf.Lambda.StatusFunction,
+				ResultFunction: conf.Lambda.ResultFunction,
+				AuditFunction:  conf.Lambda.AuditFunction,
+			},
+		}, nil
+	case "pubsub":
+		return fleet.LoggingPlugin{
+			Plugin: "pubsub",
+			Config: fleet.PubSubConfig{
+				PubSubConfig: conf.PubSub,
+			},
+		}, nil
+	case "stdout":
+		return fleet.LoggingPlugin{Plugin: "stdout"}, nil
+	case "kafkarest":
+		return fleet.LoggingPlugin{
+			Plugin: "kafkarest",
+			Config: fleet.KafkaRESTConfig{
+				StatusTopic: conf.KafkaREST.StatusTopic,
+				ResultTopic: conf.KafkaREST.ResultTopic,
+				AuditTopic:  conf.KafkaREST.AuditTopic,
+				ProxyHost:   conf.KafkaREST.ProxyHost,
+			},
+		}, nil
+	case "http":
+		return fleet.LoggingPlugin{
+			Plugin: "http",
+			Config: fleet.HTTPLogConfig{
+				StatusURL: conf.HTTPLog.StatusURL,
+				ResultURL: conf.HTTPLog.ResultURL,
+				AuditURL:  conf.HTTPLog.AuditURL,
+			},
+		}, nil
+	case "splunk":
+		return fleet.LoggingPlugin{
+			Plugin: "splunk",
+			Config: fleet.SplunkConfig{
+				URL:         conf.Splunk.URL,
+				StatusIndex: conf.Splunk.StatusIndex,
+				ResultIndex: conf.Splunk.ResultIndex,
+				AuditIndex:  conf.Splunk.AuditIndex,
+				SourceType:  conf.Splunk.SourceType,
+				Source:      conf.Splunk.Source,
+			},
+		}, nil
+	case "elasticsearch":
+		return fleet.LoggingPlugin{
+			Plugin: "elasticsearch",
+			Config: fleet.ElasticsearchConfig{
+				URL:         conf.Elasticsearch.URL,
+				StatusIndex: conf.Elasticsearch.StatusIndex,
+				ResultIndex: conf.Elasticsearch.ResultIndex,
+				AuditIndex:  conf.Elasticsearch.AuditIndex,
+			},
+		}, nil
+	default:
+		return fleet.LoggingPlugin{}, ctxerr.Errorf(ctx, "unrecognized logging plugin: %s", plugin)
+	}
+}
@@ -192,6 +192,15 @@ func TestService_LoggingConfig(t *testing.T) {
 		},
 	}
 
+	splunkConfig := fleet.SplunkConfig{
+		URL:         testSplunkPluginConfig().Splunk.URL,
+		StatusIndex: testSplunkPluginConfig().Splunk.StatusIndex,
+		ResultIndex: testSplunkPluginConfig().Splunk.ResultIndex,
+		AuditIndex:  testSplunkPluginConfig().Splunk.AuditIndex,
+		SourceType:  testSplunkPluginConfig().Splunk.SourceType,
+		Source:      testSplunkPluginConfig().Splunk.Source,
+	}
+
 	type fields struct {
 		config config.FleetConfig
 	}
@@ -331,6 +340,61 @@ func TestService_LoggingConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "test splunk config",
+			fields: fields{config: testSplunkPluginConfig()},
+			args:   args{ctx: test.UserContext(context.Background(), test.UserAdmin)},
+			want: &fleet.Logging{
+				Debug: true,
+				Json:  false,
+				Result: fleet.LoggingPlugin{
+					Plugin: "splunk",
+					Config: splunkConfig,
+				},
+				Status: fleet.LoggingPlugin{
+					Plugin: "splunk",
+					Config: splunkConfig,
+				},
+				Audit: fleet.LoggingPlugin{
+					Plugin: "splunk",
+					Config: splunkConfig,
+				},
+			},
+		},
+		{
+			name:   "test composite config",
+			fields: fields{config: testCompositePluginConfig()},
+			args:   args{ctx: test.UserContext(context.Background(), test.UserAdmin)},
+			want: &fleet.Logging{
+				Debug: true,
+				Json:  false,
+				Result: fleet.LoggingPlugin{
+					Plugin: "composite",
+					Config: fleet.CompositeLoggingConfig{
+						Plugins: []fleet.LoggingPlugin{
+							{Plugin: "splunk", Config: splunkConfig},
+							{Plugin: "stdout"},
+						},
+						Routes: []config.LogRoute{
+							{Plugins: []string{"splunk"}, Queries: []string{"security_*"}},
+						},
+					},
+				},
+				Status: fleet.LoggingPlugin{
+					Plugin: "stdout",
+					Config: nil,
+				},
+				Audit: fleet.LoggingPlugin{
+					Plugin: "composite",
+					Config: fleet.CompositeLoggingConfig{
+						Plugins: []fleet.LoggingPlugin{
+							{Plugin: "splunk", Config: splunkConfig},
+							{Plugin: "stdout"},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:    "test unrecognized config",
 			fields:  fields{config: testUnrecognizedPluginConfig()},
@@ -70,23 +70,39 @@ func (svc Service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 		return
 	}
 
-	// Open the channel from which we will receive incoming query results
-	// (probably from the redis pubsub implementation)
-	readChan, cancelFunc, err := svc.GetCampaignReader(ctx, campaign)
-	if err != nil {
-		conn.WriteJSONError("error getting campaign reader: " + err.Error()) //nolint:errcheck
-		return
+	// When the results are persisted, a campaign can be reopened to get its
+	// results, including after it completed.
+	persistResults := svc.config.App.LiveQueryResultsRetention > 0
+	reopenCompleted := persistResults && campaign.Status == fleet.QueryComplete
+
+	var readChan <-chan interface{}
+	if !reopenCompleted {
+		// Open the channel from which we will receive incoming query results
+		// (probably from the redis pubsub implementation)
+		var cancelFunc context.CancelFunc
+		readChan, cancelFunc, err = svc.GetCampaignReader(ctx, campaign)
+		if err != nil {
+			conn.WriteJSONError("error getting campaign reader: " + err.Error()) //nolint:errcheck
+			return
+		}
+		defer cancelFunc()
 	}
-	defer cancelFunc()
-
-	// Setting the status to completed stops the query from being sent to
-	// targets. If this fails, there is a background job that will clean up
-	// this campaign.
-	defer svc.CompleteCampaign(ctx, campaign) //nolint:errcheck
 
 	status := campaignStatus{
 		Status: campaignStatusPending,
 	}
+
+	if !reopenCompleted {
+		// Setting the status to completed stops the query from being sent to
+		// targets. If this fails, there is a background job that will clean up
+		// this campaign. When the results are persisted, an unfinished campaign
+		// keeps running so that it can be reopened.
+		defer func() {
+			if !persistResults || status.Status == campaignStatusFinished {
+				svc.CompleteCampaign(ctx, campaign) //nolint:errcheck
+			}
+		}()
+	}
 	lastStatus := status
 	lastTotals := targetTotals{}
 
@@ -154,6 +170,43 @@ func (svc Service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 		return
 	}
 
+	// Write the results received before the campaign was (re)opened. The hosts
+	// that reported them are tracked to skip their results if they are also
+	// received from the read channel.
+	replayedHosts := make(map[uint]bool)
+	if persistResults {
+		opts := fleet.ListOptions{PerPage: 1000}
+		for {
+			results, meta, err := svc.ds.ListDistributedQueryCampaignResults(ctx, campaign.ID, opts)
+			if err != nil {
+				conn.WriteJSONError("error retrieving persisted results: " + err.Error()) //nolint:errcheck
+				return
+			}
+			for _, res := range results {
+				mapHostnameRows(res)
+				if err := conn.WriteJSONMessage("result", res); err != nil {
+					_ = svc.logger.Log("msg", "error writing persisted result", "err", err)
+					return
+				}
+				replayedHosts[res.Host.ID] = true
+				status.ActualResults++
+			}
+			if !meta.HasNextResults {
+				break
+			}
+			opts.Page++
+		}
+	}
+
+	if reopenCompleted {
+		// no more results will be received for a completed campaign
+		status.Status = campaignStatusFinished
+		if err := conn.WriteJSONMessage("status", status); err != nil {
+			_ = svc.logger.Log("msg", "error writing status", "err", err)
+		}
+		return
+	}
+
 	// Push status updates every 5 seconds at most
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -167,6 +220,9 @@ func (svc Service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 			// Receive a result and push it over the websocket
 			switch res := res.(type) {
 			case fleet.DistributedQueryResult:
+				if res.Host != nil && replayedHosts[res.Host.ID] {
+					continue
+				}
 				mapHostnameRows(&res)
 				err = conn.WriteJSONMessage("result", res)
 				if ctxerr.Cause(err) == sockjs.ErrSessionNotOpen {
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/contexts/license"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/go-kit/kit/log/level"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Preview team assignment rules
+////////////////////////////////////////////////////////////////////////////////
+
+type previewTeamAssignmentRulesRequest struct {
+	// Rules are the rules to preview, the configured rules are used if nil.
+	Rules *fleet.TeamAssignmentRules `json:"rules"`
+}
+
+type previewTeamAssignmentRulesResponse struct {
+	Moves []fleet.TeamAssignmentMove `json:"moves"`
+	Err   error                      `json:"error,omitempty"`
+}
+
+func (r previewTeamAssignmentRulesResponse) error() error { return r.Err }
+
+func previewTeamAssignmentRulesEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*previewTeamAssignmentRulesRequest)
+	moves, err := svc.PreviewTeamAssignmentRules(ctx, req.Rules)
+	if err != nil {
+		return previewTeamAssignmentRulesResponse{Err: err}, nil
+	}
+	return previewTeamAssignmentRulesResponse{Moves: moves}, nil
+}
+
+func (svc *Service) PreviewTeamAssignmentRules(ctx context.Context, rules *fleet.TeamAssignmentRules) ([]fleet.TeamAssignmentMove, error) {
+	// the rules are part of the app config and apply to all hosts
+	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionWrite); err != nil {
+		return nil, err
+	}
+	if !license.IsPremium(ctx) {
+		return nil, fleet.ErrMissingLicense
+	}
+
+	if rules == nil {
+		appConfig, err := svc.ds.AppConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rules = &appConfig.TeamAssignmentRules
+	} else if err := rules.Validate(); err != nil {
+		return nil, fleet.NewInvalidArgumentError("rules", err.Error())
+	}
+
+	compiled, err := svc.newTeamAssignmentRuleSet(ctx, *rules)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := svc.ds.ListHostsForTeamAssignment(ctx, nil, rules.LabelNames(), rules.UseEmails())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list hosts for team assignment")
+	}
+	return svc.teamAssignmentMoves(compiled, hosts), nil
+}
+
+// teamAssignmentCacheTTL is how long the compiled team assignment rules are
+// cached, so that teams and labels created or renamed after the rules were
+// configured are eventually picked up.
+const teamAssignmentCacheTTL = time.Minute
+
+// teamAssignmentRuleSet holds the team assignment rules compiled to match
+// hosts, along with the lookups they need.
+type teamAssignmentRuleSet struct {
+	rules    fleet.TeamAssignmentRules
+	compiled *fleet.CompiledTeamAssignmentRules
+	// teamIDs are the IDs of the teams by name.
+	teamIDs map[string]uint
+	// labelIDs are the IDs of the labels referenced by the rules.
+	labelIDs map[uint]bool
+}
+
+// teamAssignmentCache caches the rule set of the configured team assignment
+// rules, as they are evaluated for every host that reports new details.
+type teamAssignmentCache struct {
+	mu        sync.Mutex
+	ruleSet   *teamAssignmentRuleSet
+	expiresAt time.Time
+}
+
+func (svc *Service) newTeamAssignmentRuleSet(ctx context.Context, rules fleet.TeamAssignmentRules) (*teamAssignmentRuleSet, error) {
+	compiled, err := rules.Compile()
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "compile team assignment rules")
+	}
+
+	teams, err := svc.ds.TeamsSummary(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list teams")
+	}
+	teamIDs := make(map[string]uint, len(teams))
+	for _, t := range teams {
+		teamIDs[t.Name] = t.ID
+	}
+
+	labelIDs := make(map[uint]bool)
+	if labelNames := rules.LabelNames(); len(labelNames) > 0 {
+		ids, err := svc.ds.LabelIDsByName(ctx, labelNames)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "get team assignment rules label IDs")
+		}
+		for _, id := range ids {
+			labelIDs[id] = true
+		}
+	}
+
+	return &teamAssignmentRuleSet{
+		rules:    rules,
+		compiled: compiled,
+		teamIDs:  teamIDs,
+		labelIDs: labelIDs,
+	}, nil
+}
+
+// cachedTeamAssignmentRuleSet returns the rule set of the provided rules,
+// which are the configured ones, from the cache if it is still valid.
+func (svc *Service) cachedTeamAssignmentRuleSet(ctx context.Context, rules fleet.TeamAssignmentRules) (*teamAssignmentRuleSet, error) {
+	c := svc.teamAssignmentCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := svc.clock.Now()
+	if c.ruleSet != nil && now.Before(c.expiresAt) && reflect.DeepEqual(c.ruleSet.rules, rules) {
+		return c.ruleSet, nil
+	}
+	ruleSet, err := svc.newTeamAssignmentRuleSet(ctx, rules)
+	if err != nil {
+		return nil, err
+	}
+	c.ruleSet = ruleSet
+	c.expiresAt = now.Add(teamAssignmentCacheTTL)
+	return ruleSet, nil
+}
+
+// teamAssignmentMoves returns the transfers of the provided hosts by the
+// rules. The hosts that do not match any rule, or that are already in the team
+// of the rule they match, are not transferred.
+func (svc *Service) teamAssignmentMoves(ruleSet *teamAssignmentRuleSet, hosts []*fleet.TeamAssignmentHost) []fleet.TeamAssignmentMove {
+	moves := []fleet.TeamAssignmentMove{}
+	for _, h := range hosts {
+		i := ruleSet.compiled.Match(h)
+		if i < 0 {
+			continue
+		}
+		teamName := ruleSet.compiled.Team(i)
+		teamID, ok := ruleSet.teamIDs[teamName]
+		if !ok {
+			// the team was deleted after the rules were configured
+			level.Debug(svc.logger).Log("msg", "team assignment rule team not found", "team", teamName)
+			continue
+		}
+		if h.TeamID != nil && *h.TeamID == teamID {
+			continue
+		}
+		moves = append(moves, fleet.TeamAssignmentMove{
+			HostID:          h.ID,
+			HostDisplayName: h.DisplayName,
+			FromTeamID:      h.TeamID,
+			FromTeamName:    h.TeamName,
+			ToTeamID:        teamID,
+			ToTeamName:      teamName,
+			RuleIndex:       i,
+		})
+	}
+	return moves
+}
+
+// teamAssignmentAttributes are the attributes of a host, stored with the host
+// itself, that the team assignment rules match.
+type teamAssignmentAttributes struct {
+	hostname       string
+	platform       string
+	hardwareSerial string
+}
+
+func hostTeamAssignmentAttributes(h *fleet.Host) teamAssignmentAttributes {
+	return teamAssignmentAttributes{
+		hostname:       h.Hostname,
+		platform:       h.Platform,
+		hardwareSerial: h.HardwareSerial,
+	}
+}
+
+// maybeApplyTeamAssignmentRules applies the team assignment rules to a host
+// that reported query results, only if the attributes matched by the rules
+// may have changed: its hostname, platform or serial number changed, it
+// reported the membership of a label used by the rules, or it reported its
+// emails and the rules match them.
+func (svc *Service) maybeApplyTeamAssignmentRules(
+	ctx context.Context,
+	host *fleet.Host,
+	rules fleet.TeamAssignmentRules,
+	before teamAssignmentAttributes,
+	labelResults map[uint]*bool,
+	emailsReported bool,
+) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	changed := before != hostTeamAssignmentAttributes(host) || (emailsReported && rules.UseEmails())
+	if !changed && len(labelResults) > 0 {
+		ruleSet, err := svc.cachedTeamAssignmentRuleSet(ctx, rules)
+		if err != nil {
+			return err
+		}
+		for labelID := range labelResults {
+			if ruleSet.labelIDs[labelID] {
+				changed = true
+				break
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return svc.applyTeamAssignmentRules(ctx, host, rules)
+}
+
+// applyTeamAssignmentRules transfers the host to the team of the first rule
+// that matches it. The attributes of the host are taken from the provided
+// host, as it may not be saved yet.
+//
+// The rules take precedence over the team of the enroll secret used by the
+// host, and a host that was transferred manually is transferred back to the
+// team of the rule it matches the next time the rules are applied to it.
+func (svc *Service) applyTeamAssignmentRules(ctx context.Context, host *fleet.Host, rules fleet.TeamAssignmentRules) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ruleSet, err := svc.cachedTeamAssignmentRuleSet(ctx, rules)
+	if err != nil {
+		return err
+	}
+	hosts, err := svc.ds.ListHostsForTeamAssignment(ctx, []uint{host.ID}, rules.LabelNames(), rules.UseEmails())
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list host for team assignment")
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	h := hosts[0]
+	h.DisplayName = host.DisplayName()
+	h.Hostname = host.Hostname
+	h.Platform = host.Platform
+	h.HardwareSerial = host.HardwareSerial
+
+	for _, m := range svc.teamAssignmentMoves(ruleSet, hosts) {
+		if err := svc.ds.AddHostsToTeam(ctx, &m.ToTeamID, []uint{m.HostID}); err != nil {
+			return ctxerr.Wrap(ctx, err, "transfer host to team")
+		}
+		teamID := m.ToTeamID
+		host.TeamID = &teamID
+		if err := svc.ds.NewActivity(ctx, nil, fleet.ActivityTypeAssignedHostToTeamByRule{
+			HostID:          m.HostID,
+			HostDisplayName: m.HostDisplayName,
+			FromTeamID:      m.FromTeamID,
+			FromTeamName:    m.FromTeamName,
+			TeamID:          m.ToTeamID,
+			TeamName:        m.ToTeamName,
+			RuleIndex:       m.RuleIndex,
+		}); err != nil {
+			return ctxerr.Wrap(ctx, err, "create activity for team assignment")
+		}
+	}
+	return nil
+}
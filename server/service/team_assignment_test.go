@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewTeamAssignmentRules(t *testing.T) {
+	ds := new(mock.Store)
+	license := &fleet.LicenseInfo{Tier: fleet.TierPremium, Expiration: time.Now().Add(24 * time.Hour)}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{TeamAssignmentRules: fleet.TeamAssignmentRules{
+			{Team: "servers", HostnameRegex: "^web-"},
+		}}, nil
+	}
+	ds.TeamsSummaryFunc = func(ctx context.Context) ([]*fleet.TeamSummary, error) {
+		return []*fleet.TeamSummary{{ID: 1, Name: "servers"}, {ID: 2, Name: "laptops"}}, nil
+	}
+	ds.LabelIDsByNameFunc = func(ctx context.Context, labels []string) ([]uint, error) {
+		require.Equal(t, []string{"Laptops"}, labels)
+		return []uint{7}, nil
+	}
+	ds.ListHostsForTeamAssignmentFunc = func(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error) {
+		require.Nil(t, hostIDs)
+		hosts := []*fleet.TeamAssignmentHost{
+			{ID: 1, DisplayName: "web-1", Hostname: "web-1", Platform: "ubuntu"},
+			{ID: 2, DisplayName: "web-2", Hostname: "web-2", Platform: "ubuntu", TeamID: ptr.Uint(1), TeamName: ptr.String("servers")},
+			{ID: 3, DisplayName: "mac", Hostname: "mac", Platform: "darwin", TeamID: ptr.Uint(1), TeamName: ptr.String("servers")},
+		}
+		if len(labelNames) > 0 {
+			require.Equal(t, []string{"Laptops"}, labelNames)
+			hosts[2].LabelNames = []string{"Laptops"}
+		}
+		return hosts, nil
+	}
+
+	// only global admins can preview the rules
+	_, err := svc.PreviewTeamAssignmentRules(test.UserContext(ctx, test.UserMaintainer), nil)
+	checkAuthErr(t, true, err)
+
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	// the configured rules are used by default
+	moves, err := svc.PreviewTeamAssignmentRules(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []fleet.TeamAssignmentMove{
+		{HostID: 1, HostDisplayName: "web-1", ToTeamID: 1, ToTeamName: "servers"},
+	}, moves)
+
+	// the provided rules are used instead
+	moves, err = svc.PreviewTeamAssignmentRules(ctx, &fleet.TeamAssignmentRules{
+		{Team: "laptops", Label: "Laptops"},
+		{Team: "servers", Platform: "linux"},
+		{Team: "unknown", Platform: "darwin"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []fleet.TeamAssignmentMove{
+		{HostID: 1, HostDisplayName: "web-1", ToTeamID: 1, ToTeamName: "servers", RuleIndex: 1},
+		{HostID: 3, HostDisplayName: "mac", FromTeamID: ptr.Uint(1), FromTeamName: ptr.String("servers"), ToTeamID: 2, ToTeamName: "laptops"},
+	}, moves)
+
+	_, err = svc.PreviewTeamAssignmentRules(ctx, &fleet.TeamAssignmentRules{{Team: "laptops"}})
+	require.ErrorContains(t, err, "at least one of")
+}
+
+func TestEnrollAgentTeamAssignmentRules(t *testing.T) {
+	ds := new(mock.Store)
+	ds.VerifyEnrollSecretFunc = func(ctx context.Context, secret string) (*fleet.EnrollSecret, error) {
+		return &fleet.EnrollSecret{}, nil
+	}
+	ds.EnrollHostFunc = func(ctx context.Context, isMDMEnabled bool, osqueryHostId, hUUID, hSerial, nodeKey string, teamID *uint, cooldown time.Duration) (*fleet.Host, error) {
+		return &fleet.Host{ID: 1, OsqueryHostID: &osqueryHostId, NodeKey: &nodeKey}, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{TeamAssignmentRules: fleet.TeamAssignmentRules{
+			{Team: "servers", HostnameRegex: "^web-", Platform: "linux"},
+		}}, nil
+	}
+	ds.ListHostsForTeamAssignmentFunc = func(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error) {
+		require.Equal(t, []uint{1}, hostIDs)
+		// the host details are not saved yet
+		return []*fleet.TeamAssignmentHost{{ID: 1}}, nil
+	}
+	ds.TeamsSummaryFunc = func(ctx context.Context) ([]*fleet.TeamSummary, error) {
+		return []*fleet.TeamSummary{{ID: 5, Name: "servers"}}, nil
+	}
+	ds.AddHostsToTeamFunc = func(ctx context.Context, teamID *uint, hostIDs []uint) error {
+		require.Equal(t, uint(5), *teamID)
+		require.Equal(t, []uint{1}, hostIDs)
+		return nil
+	}
+	var act fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+		require.Nil(t, user)
+		act = activity
+		return nil
+	}
+	var gotHost *fleet.Host
+	ds.UpdateHostFunc = func(ctx context.Context, host *fleet.Host) error {
+		gotHost = host
+		return nil
+	}
+
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	details := map[string](map[string]string){
+		"system_info": {"hostname": "web-1"},
+		"os_version":  {"platform": "ubuntu"},
+	}
+	_, err := svc.EnrollAgent(ctx, "", "host123", details)
+	require.NoError(t, err)
+	require.True(t, ds.AddHostsToTeamFuncInvoked)
+	require.Equal(t, ptr.Uint(5), gotHost.TeamID)
+	require.Equal(t, fleet.ActivityTypeAssignedHostToTeamByRule{
+		HostID:          1,
+		HostDisplayName: "web-1",
+		TeamID:          5,
+		TeamName:        "servers",
+	}, act)
+}
+
+func TestMaybeApplyTeamAssignmentRules(t *testing.T) {
+	ds := new(mock.Store)
+	mockClock := clock.NewMockClock()
+	svc := &Service{ds: ds, clock: mockClock, logger: kitlog.NewNopLogger(), teamAssignmentCache: new(teamAssignmentCache)}
+	ctx := context.Background()
+
+	rules := fleet.TeamAssignmentRules{
+		{Team: "servers", HostnameRegex: "^web-"},
+		{Team: "laptops", Label: "Laptops"},
+	}
+	ds.TeamsSummaryFunc = func(ctx context.Context) ([]*fleet.TeamSummary, error) {
+		return []*fleet.TeamSummary{{ID: 1, Name: "servers"}, {ID: 2, Name: "laptops"}}, nil
+	}
+	ds.LabelIDsByNameFunc = func(ctx context.Context, labels []string) ([]uint, error) {
+		return []uint{7}, nil
+	}
+	var listed int
+	ds.ListHostsForTeamAssignmentFunc = func(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error) {
+		listed++
+		return []*fleet.TeamAssignmentHost{{ID: 1, TeamID: ptr.Uint(1)}}, nil
+	}
+	ds.AddHostsToTeamFunc = func(ctx context.Context, teamID *uint, hostIDs []uint) error {
+		return nil
+	}
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+		return nil
+	}
+
+	host := &fleet.Host{ID: 1, Hostname: "web-1", Platform: "ubuntu"}
+	before := hostTeamAssignmentAttributes(host)
+
+	// nothing matched by the rules was reported
+	require.NoError(t, svc.maybeApplyTeamAssignmentRules(ctx, host, rules, before, map[uint]*bool{3: ptr.Bool(true)}, true))
+	require.Zero(t, listed)
+
+	// the membership of a label used by the rules was reported
+	require.NoError(t, svc.maybeApplyTeamAssignmentRules(ctx, host, rules, before, map[uint]*bool{7: ptr.Bool(true)}, false))
+	require.Equal(t, 1, listed)
+	require.False(t, ds.AddHostsToTeamFuncInvoked)
+
+	// the hostname changed, the host is transferred
+	host.Hostname = "mac"
+	ds.ListHostsForTeamAssignmentFunc = func(ctx context.Context, hostIDs []uint, labelNames []string, withEmails bool) ([]*fleet.TeamAssignmentHost, error) {
+		listed++
+		return []*fleet.TeamAssignmentHost{{ID: 1, TeamID: ptr.Uint(1), LabelNames: []string{"Laptops"}}}, nil
+	}
+	require.NoError(t, svc.maybeApplyTeamAssignmentRules(ctx, host, rules, before, nil, false))
+	require.Equal(t, 2, listed)
+	require.True(t, ds.AddHostsToTeamFuncInvoked)
+	require.Equal(t, ptr.Uint(2), host.TeamID)
+
+	// the rule set is cached until it expires or the rules change
+	ds.TeamsSummaryFuncInvoked = false
+	require.NoError(t, svc.applyTeamAssignmentRules(ctx, host, rules))
+	require.False(t, ds.TeamsSummaryFuncInvoked)
+	require.NoError(t, svc.applyTeamAssignmentRules(ctx, host, rules[1:]))
+	require.True(t, ds.TeamsSummaryFuncInvoked)
+	ds.TeamsSummaryFuncInvoked = false
+	mockClock.AddTime(teamAssignmentCacheTTL)
+	require.NoError(t, svc.applyTeamAssignmentRules(ctx, host, rules[1:]))
+	require.True(t, ds.TeamsSummaryFuncInvoked)
+}
+
+func TestModifyAppConfigTeamAssignmentRules(t *testing.T) {
+	ds := new(mock.Store)
+	license := &fleet.LicenseInfo{Tier: fleet.TierPremium, Expiration: time.Now().Add(24 * time.Hour)}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	dsAppConfig := &fleet.AppConfig{
+		OrgInfo:             fleet.OrgInfo{OrgName: "Test"},
+		ServerSettings:      fleet.ServerSettings{ServerURL: "https://example.org"},
+		TeamAssignmentRules: fleet.TeamAssignmentRules{{Team: "servers", Platform: "linux", Label: "Servers"}},
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return dsAppConfig.Copy(), nil
+	}
+	ds.SaveAppConfigFunc = func(ctx context.Context, conf *fleet.AppConfig) error {
+		dsAppConfig = conf
+		return nil
+	}
+	ds.TeamByNameFunc = func(ctx context.Context, name string) (*fleet.Team, error) {
+		if name == "servers" {
+			return &fleet.Team{ID: 1, Name: name}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+	ds.LabelIDsByNameFunc = func(ctx context.Context, labels []string) ([]uint, error) {
+		var ids []uint
+		for _, l := range labels {
+			if l == "Servers" {
+				ids = append(ids, 1)
+			}
+		}
+		return ids, nil
+	}
+
+	cases := []struct {
+		desc    string
+		payload string
+		wantErr string
+		want    fleet.TeamAssignmentRules
+	}{
+		{
+			"not provided",
+			`{"org_info": {"org_name": "Test"}}`,
+			"",
+			fleet.TeamAssignmentRules{{Team: "servers", Platform: "linux", Label: "Servers"}},
+		},
+		{
+			"replaced",
+			`{"team_assignment_rules": [{"team": "servers", "hostname_regex": "^web-"}]}`,
+			"",
+			fleet.TeamAssignmentRules{{Team: "servers", HostnameRegex: "^web-"}},
+		},
+		{
+			"unknown team",
+			`{"team_assignment_rules": [{"team": "unknown", "platform": "linux"}]}`,
+			`rule 0: team "unknown" not found`,
+			nil,
+		},
+		{
+			"unknown label",
+			`{"team_assignment_rules": [{"team": "servers", "label": "unknown"}]}`,
+			"do not exist",
+			nil,
+		},
+		{
+			"invalid regex",
+			`{"team_assignment_rules": [{"team": "servers", "hostname_regex": "("}]}`,
+			"invalid hostname_regex",
+			nil,
+		},
+		{
+			"cleared",
+			`{"team_assignment_rules": []}`,
+			"",
+			fleet.TeamAssignmentRules{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := svc.ModifyAppConfig(ctx, []byte(c.payload), fleet.ApplySpecOptions{})
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.want, dsAppConfig.TeamAssignmentRules)
+		})
+	}
+
+	// the rules require a premium license
+	svc, ctx = newTestService(t, ds, nil, nil)
+	ctx = test.UserContext(ctx, test.UserAdmin)
+	_, err := svc.ModifyAppConfig(ctx, []byte(`{"team_assignment_rules": [{"team": "servers", "platform": "linux"}]}`), fleet.ApplySpecOptions{})
+	require.ErrorContains(t, err, ErrMissingLicense.Error())
+}
@@ -19,14 +19,17 @@ import (
 /////////////////////////////////////////////////////////////////////////////////
 
 type teamPolicyRequest struct {
-	TeamID      uint   `url:"team_id"`
-	QueryID     *uint  `json:"query_id"`
-	Query       string `json:"query"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Resolution  string `json:"resolution"`
-	Platform    string `json:"platform"`
-	Critical    bool   `json:"critical" premium:"true"`
+	TeamID      uint                            `url:"team_id"`
+	QueryID     *uint                           `json:"query_id"`
+	Query       string                          `json:"query"`
+	Name        string                          `json:"name"`
+	Description string                          `json:"description"`
+	Resolution  string                          `json:"resolution"`
+	Platform    string                          `json:"platform"`
+	Critical    bool                            `json:"critical" premium:"true"`
+	Severity    string                          `json:"severity"`
+	Tags        []string                        `json:"tags"`
+	Compliance  []fleet.PolicyComplianceControl `json:"compliance"`
 }
 
 type teamPolicyResponse struct {
@@ -46,6 +49,9 @@ func teamPolicyEndpoint(ctx context.Context, request interface{}, svc fleet.Serv
 		Resolution:  req.Resolution,
 		Platform:    req.Platform,
 		Critical:    req.Critical,
+		Severity:    req.Severity,
+		Tags:        req.Tags,
+		Compliance:  req.Compliance,
 	})
 	if err != nil {
 		return teamPolicyResponse{Err: err}, nil
@@ -97,7 +103,10 @@ func (svc Service) NewTeamPolicy(ctx context.Context, teamID uint, p fleet.Polic
 /////////////////////////////////////////////////////////////////////////////////
 
 type listTeamPoliciesRequest struct {
-	TeamID uint `url:"team_id"`
+	TeamID              uint   `url:"team_id"`
+	Severity            string `query:"severity,optional"`
+	Tag                 string `query:"tag,optional"`
+	ComplianceFramework string `query:"compliance_framework,optional"`
 }
 
 type listTeamPoliciesResponse struct {
@@ -110,14 +119,18 @@ func (r listTeamPoliciesResponse) error() error { return r.Err }
 
 func listTeamPoliciesEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
 	req := request.(*listTeamPoliciesRequest)
-	tmPols, inheritedPols, err := svc.ListTeamPolicies(ctx, req.TeamID)
+	tmPols, inheritedPols, err := svc.ListTeamPolicies(ctx, req.TeamID, fleet.PolicyListOptions{
+		Severity:            req.Severity,
+		Tag:                 req.Tag,
+		ComplianceFramework: req.ComplianceFramework,
+	})
 	if err != nil {
 		return listTeamPoliciesResponse{Err: err}, nil
 	}
 	return listTeamPoliciesResponse{Policies: tmPols, InheritedPolicies: inheritedPols}, nil
 }
 
-func (svc *Service) ListTeamPolicies(ctx context.Context, teamID uint) (teamPolicies, inheritedPolicies []*fleet.Policy, err error) {
+func (svc *Service) ListTeamPolicies(ctx context.Context, teamID uint, opts fleet.PolicyListOptions) (teamPolicies, inheritedPolicies []*fleet.Policy, err error) {
 	if err := svc.authz.Authorize(ctx, &fleet.Policy{
 		PolicyData: fleet.PolicyData{
 			TeamID: ptr.Uint(teamID),
@@ -126,11 +139,15 @@ func (svc *Service) ListTeamPolicies(ctx context.Context, teamID uint) (teamPoli
 		return nil, nil, err
 	}
 
+	if err := opts.Verify(); err != nil {
+		return nil, nil, fleet.NewInvalidArgumentError("severity", err.Error())
+	}
+
 	if _, err := svc.ds.Team(ctx, teamID); err != nil {
 		return nil, nil, ctxerr.Wrapf(ctx, err, "loading team %d", teamID)
 	}
 
-	return svc.ds.ListTeamPolicies(ctx, teamID)
+	return svc.ds.ListTeamPolicies(ctx, teamID, opts)
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -331,6 +348,15 @@ func (svc *Service) modifyPolicy(ctx context.Context, teamID *uint, id uint, p f
 	if p.Critical != nil {
 		policy.Critical = *p.Critical
 	}
+	if p.Severity != nil {
+		policy.Severity = *p.Severity
+	}
+	if p.Tags != nil {
+		policy.Tags = *p.Tags
+	}
+	if p.Compliance != nil {
+		policy.Compliance = *p.Compliance
+	}
 	logging.WithExtras(ctx, "name", policy.Name, "sql", policy.Query)
 
 	err = svc.ds.SavePolicy(ctx, policy)
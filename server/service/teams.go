@@ -17,6 +17,8 @@ import (
 
 type listTeamsRequest struct {
 	ListOptions fleet.ListOptions `url:"list_options"`
+	// Tree nests the child teams under their parent in the response.
+	Tree bool `query:"tree,optional"`
 }
 
 type listTeamsResponse struct {
@@ -32,6 +34,9 @@ func listTeamsEndpoint(ctx context.Context, request interface{}, svc fleet.Servi
 	if err != nil {
 		return listTeamsResponse{Err: err}, nil
 	}
+	if req.Tree {
+		teams = fleet.TeamTree(teams)
+	}
 
 	resp := listTeamsResponse{Teams: []fleet.Team{}}
 	for _, team := range teams {
@@ -415,3 +420,35 @@ func (svc *Service) ModifyTeamEnrollSecrets(ctx context.Context, teamID uint, se
 
 	return nil, fleet.ErrMissingLicense
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Preview host lifecycle rules for team
+////////////////////////////////////////////////////////////////////////////////
+
+type previewTeamHostLifecycleRequest struct {
+	TeamID uint `url:"id"`
+}
+
+type previewTeamHostLifecycleResponse struct {
+	*fleet.HostLifecycleReport
+	Err error `json:"error,omitempty"`
+}
+
+func (r previewTeamHostLifecycleResponse) error() error { return r.Err }
+
+func previewTeamHostLifecycleEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*previewTeamHostLifecycleRequest)
+	report, err := svc.PreviewTeamHostLifecycle(ctx, req.TeamID)
+	if err != nil {
+		return previewTeamHostLifecycleResponse{Err: err}, nil
+	}
+	return previewTeamHostLifecycleResponse{HostLifecycleReport: report}, nil
+}
+
+func (svc *Service) PreviewTeamHostLifecycle(ctx context.Context, teamID uint) (*fleet.HostLifecycleReport, error) {
+	// skipauth: No authorization check needed due to implementation returning
+	// only license error.
+	svc.authz.SkipAuthorization(ctx)
+
+	return nil, fleet.ErrMissingLicense
+}
@@ -178,6 +178,9 @@ func TestTeamAuth(t *testing.T) {
 
 			err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1"}}, fleet.ApplySpecOptions{})
 			checkAuthErr(t, tt.shouldFailTeamWrite, err)
+
+			_, err = svc.PreviewTeamHostLifecycle(ctx, 1)
+			checkAuthErr(t, tt.shouldFailRead, err)
 		})
 	}
 }
@@ -356,4 +359,156 @@ func TestApplyTeamSpecs(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Schedule overrides", func(t *testing.T) {
+		ds.TeamByNameFunc = func(ctx context.Context, name string) (*fleet.Team, error) {
+			return &fleet.Team{ID: 1, Name: name}, nil
+		}
+		ds.SaveTeamFunc = func(ctx context.Context, team *fleet.Team) (*fleet.Team, error) {
+			return team, nil
+		}
+		ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+			return nil
+		}
+		ds.EnsureGlobalPackFunc = func(ctx context.Context) (*fleet.Pack, error) {
+			return &fleet.Pack{ID: 7}, nil
+		}
+		ds.ListScheduledQueriesInPackFunc = func(ctx context.Context, packID uint) (fleet.ScheduledQueryList, error) {
+			require.Equal(t, uint(7), packID)
+			return fleet.ScheduledQueryList{
+				{ID: 1, Name: "q1"},
+				{ID: 2, Name: "q2"},
+			}, nil
+		}
+		var replaced []*fleet.TeamScheduledQueryOverride
+		ds.ReplaceTeamScheduledQueryOverridesFunc = func(ctx context.Context, teamID uint, overrides []*fleet.TeamScheduledQueryOverride) error {
+			require.Equal(t, uint(1), teamID)
+			replaced = overrides
+			return nil
+		}
+
+		// overrides are left unchanged if not provided
+		err := svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1"}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.False(t, ds.ReplaceTeamScheduledQueryOverridesFuncInvoked)
+
+		overrides := []fleet.TeamScheduledQueryOverride{
+			{Name: "q1", Disabled: true},
+			{Name: "q2", Interval: ptr.Uint(120), Shard: ptr.Uint(50), Platform: ptr.String("linux,darwin")},
+		}
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", ScheduleOverrides: &overrides}}, fleet.ApplySpecOptions{DryRun: true})
+		require.NoError(t, err)
+		require.False(t, ds.ReplaceTeamScheduledQueryOverridesFuncInvoked)
+
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", ScheduleOverrides: &overrides}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.True(t, ds.ReplaceTeamScheduledQueryOverridesFuncInvoked)
+		require.Equal(t, []*fleet.TeamScheduledQueryOverride{
+			{ScheduledQueryID: 1, Name: "q1", Disabled: true},
+			{ScheduledQueryID: 2, Name: "q2", Interval: ptr.Uint(120), Shard: ptr.Uint(50), Platform: ptr.String("linux,darwin")},
+		}, replaced)
+
+		// an empty list clears the overrides
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", ScheduleOverrides: &[]fleet.TeamScheduledQueryOverride{}}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.Empty(t, replaced)
+
+		for _, c := range []struct {
+			override fleet.TeamScheduledQueryOverride
+			errMsg   string
+		}{
+			{fleet.TeamScheduledQueryOverride{Name: "unknown"}, "is not a query of the global schedule"},
+			{fleet.TeamScheduledQueryOverride{Name: ""}, "name may not be empty"},
+			{fleet.TeamScheduledQueryOverride{Name: "q1", Interval: ptr.Uint(0)}, "interval must be greater than 0"},
+			{fleet.TeamScheduledQueryOverride{Name: "q1", Shard: ptr.Uint(101)}, "shard must be between 1 and 100"},
+			{fleet.TeamScheduledQueryOverride{Name: "q1", Platform: ptr.String("linux,chrome")}, "invalid platform"},
+		} {
+			err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", ScheduleOverrides: &[]fleet.TeamScheduledQueryOverride{c.override}}}, fleet.ApplySpecOptions{})
+			require.ErrorContains(t, err, c.errMsg)
+		}
+
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", ScheduleOverrides: &[]fleet.TeamScheduledQueryOverride{
+			{Name: "q1", Disabled: true}, {Name: "q1", Shard: ptr.Uint(1)},
+		}}}, fleet.ApplySpecOptions{})
+		require.ErrorContains(t, err, "duplicate override")
+	})
+
+	t.Run("Host lifecycle", func(t *testing.T) {
+		ds.TeamByNameFunc = func(ctx context.Context, name string) (*fleet.Team, error) {
+			if name == "unknown" {
+				return nil, sql.ErrNoRows
+			}
+			return &fleet.Team{ID: 1, Name: name}, nil
+		}
+		var saved *fleet.Team
+		ds.SaveTeamFunc = func(ctx context.Context, team *fleet.Team) (*fleet.Team, error) {
+			saved = team
+			return team, nil
+		}
+		ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+			return nil
+		}
+
+		settings := &fleet.HostLifecycleSettings{Rules: []fleet.HostLifecycleRule{
+			{OfflineDays: 14, MarkStale: true, TransferToTeam: "quarantine"},
+			{OfflineDays: 60, Delete: true},
+		}}
+		err := svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", HostLifecycle: settings}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.Equal(t, settings, saved.Config.HostLifecycle)
+
+		for _, c := range []struct {
+			rule   fleet.HostLifecycleRule
+			errMsg string
+		}{
+			{fleet.HostLifecycleRule{OfflineDays: 14, TransferToTeam: "unknown"}, `team "unknown" does not exist`},
+			{fleet.HostLifecycleRule{OfflineDays: 14, TransferToTeam: "team1"}, "hosts cannot be transferred to their own team"},
+			{fleet.HostLifecycleRule{OfflineDays: 14}, "has no action"},
+		} {
+			err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{
+				Name:          "team1",
+				HostLifecycle: &fleet.HostLifecycleSettings{Rules: []fleet.HostLifecycleRule{c.rule}},
+			}}, fleet.ApplySpecOptions{})
+			require.ErrorContains(t, err, c.errMsg)
+		}
+
+		// the team to transfer the hosts to can be created by the same specs
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{
+			{Name: "unknown"},
+			{Name: "team1", HostLifecycle: &fleet.HostLifecycleSettings{Rules: []fleet.HostLifecycleRule{{OfflineDays: 14, TransferToTeam: "unknown"}}}},
+		}, fleet.ApplySpecOptions{DryRun: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("Update channels", func(t *testing.T) {
+		ds.TeamByNameFunc = func(ctx context.Context, name string) (*fleet.Team, error) {
+			return &fleet.Team{ID: 1, Name: name, Config: fleet.TeamConfig{UpdateChannels: &fleet.UpdateChannels{Orbit: "edge"}}}, nil
+		}
+		var saved *fleet.Team
+		ds.SaveTeamFunc = func(ctx context.Context, team *fleet.Team) (*fleet.Team, error) {
+			saved = team
+			return team, nil
+		}
+		ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+			return nil
+		}
+
+		// the channels are left unmodified if the key is missing
+		err := svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1"}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.Equal(t, &fleet.UpdateChannels{Orbit: "edge"}, saved.Config.UpdateChannels)
+
+		channels := &fleet.UpdateChannels{Osqueryd: "5.8.1", Desktop: "stable"}
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", UpdateChannels: channels}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.Equal(t, channels, saved.Config.UpdateChannels)
+
+		// empty channels are cleared
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", UpdateChannels: &fleet.UpdateChannels{}}}, fleet.ApplySpecOptions{})
+		require.NoError(t, err)
+		require.Nil(t, saved.Config.UpdateChannels)
+
+		err = svc.ApplyTeamSpecs(ctx, []*fleet.TeamSpec{{Name: "team1", UpdateChannels: &fleet.UpdateChannels{Osqueryd: "../5.8.1"}}}, fleet.ApplySpecOptions{})
+		require.ErrorContains(t, err, "invalid update channel")
+	})
 }
@@ -123,7 +123,7 @@ func (ts *withServer) commonTearDownTest(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	globalPolicies, err := ts.ds.ListGlobalPolicies(ctx)
+	globalPolicies, err := ts.ds.ListGlobalPolicies(ctx, fleet.PolicyListOptions{})
 	require.NoError(t, err)
 	if len(globalPolicies) > 0 {
 		var globalPolicyIDs []uint
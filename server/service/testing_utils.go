@@ -385,6 +385,34 @@ func testStdoutPluginConfig() config.FleetConfig {
 	return c
 }
 
+func testSplunkPluginConfig() config.FleetConfig {
+	c := config.TestConfig()
+	c.Osquery.ResultLogPlugin = "splunk"
+	c.Osquery.StatusLogPlugin = "splunk"
+	c.Activity.AuditLogPlugin = "splunk"
+	c.Splunk = config.SplunkConfig{
+		URL:         "https://splunk.example.com:8088",
+		Token:       "secret",
+		StatusIndex: "status",
+		ResultIndex: "result",
+		AuditIndex:  "audit",
+		SourceType:  "_json",
+		Source:      "fleet",
+	}
+	return c
+}
+
+func testCompositePluginConfig() config.FleetConfig {
+	c := testSplunkPluginConfig()
+	c.Osquery.ResultLogPlugin = "splunk,stdout"
+	c.Osquery.ResultLogRoutes = []config.LogRoute{
+		{Plugins: []string{"splunk"}, Queries: []string{"security_*"}},
+	}
+	c.Osquery.StatusLogPlugin = "stdout"
+	c.Activity.AuditLogPlugin = "splunk, stdout"
+	return c
+}
+
 func testUnrecognizedPluginConfig() config.FleetConfig {
 	c := config.TestConfig()
 	c.Osquery = config.OsqueryConfig{
@@ -197,7 +197,7 @@ func hostListOptionsFromRequest(r *http.Request) (fleet.HostListOptions, error)
 
 	status := r.URL.Query().Get("status")
 	switch fleet.HostStatus(status) {
-	case fleet.StatusNew, fleet.StatusOnline, fleet.StatusOffline, fleet.StatusMIA, fleet.StatusMissing:
+	case fleet.StatusNew, fleet.StatusOnline, fleet.StatusOffline, fleet.StatusMIA, fleet.StatusMissing, fleet.StatusStale:
 		hopt.StatusFilter = fleet.HostStatus(status)
 	case "":
 		// No error when unset
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/authz"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/contexts/license"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List update rollouts
+////////////////////////////////////////////////////////////////////////////////
+
+type listUpdateRolloutsRequest struct{}
+
+type listUpdateRolloutsResponse struct {
+	Rollouts []*fleet.UpdateRollout `json:"rollouts"`
+	Err      error                  `json:"error,omitempty"`
+}
+
+func (r listUpdateRolloutsResponse) error() error { return r.Err }
+
+func listUpdateRolloutsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	rollouts, err := svc.ListUpdateRollouts(ctx)
+	if err != nil {
+		return listUpdateRolloutsResponse{Err: err}, nil
+	}
+	return listUpdateRolloutsResponse{Rollouts: rollouts}, nil
+}
+
+func (svc *Service) ListUpdateRollouts(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+	// the rollouts pin the updates of hosts of any team
+	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+	if !license.IsPremium(ctx) {
+		return nil, fleet.ErrMissingLicense
+	}
+
+	rollouts, err := svc.ds.ListUpdateRollouts(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list update rollouts")
+	}
+	now := time.Now()
+	for _, r := range rollouts {
+		if err := svc.countUpdateRolloutHosts(ctx, r, now); err != nil {
+			return nil, err
+		}
+	}
+	if rollouts == nil {
+		rollouts = []*fleet.UpdateRollout{}
+	}
+	return rollouts, nil
+}
+
+// countUpdateRolloutHosts sets the counts of the pinned and offline hosts of
+// the rollout at the provided time.
+func (svc *Service) countUpdateRolloutHosts(ctx context.Context, r *fleet.UpdateRollout, now time.Time) error {
+	offlineSince := now.Add(-time.Duration(r.OfflineMinutes) * time.Minute)
+	hosts, offline, err := svc.ds.CountUpdateRolloutHosts(ctx, r.ID, offlineSince)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "count update rollout hosts")
+	}
+	r.HostsCount, r.OfflineHostsCount = hosts, offline
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Get update rollout
+////////////////////////////////////////////////////////////////////////////////
+
+type getUpdateRolloutRequest struct {
+	ID uint `url:"id"`
+}
+
+type updateRolloutResponse struct {
+	Rollout *fleet.UpdateRollout `json:"rollout,omitempty"`
+	Err     error                `json:"error,omitempty"`
+}
+
+func (r updateRolloutResponse) error() error { return r.Err }
+
+func getUpdateRolloutEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*getUpdateRolloutRequest)
+	rollout, err := svc.GetUpdateRollout(ctx, req.ID)
+	if err != nil {
+		return updateRolloutResponse{Err: err}, nil
+	}
+	return updateRolloutResponse{Rollout: rollout}, nil
+}
+
+func (svc *Service) GetUpdateRollout(ctx context.Context, id uint) (*fleet.UpdateRollout, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+	if !license.IsPremium(ctx) {
+		return nil, fleet.ErrMissingLicense
+	}
+
+	rollout, err := svc.ds.UpdateRollout(ctx, id)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get update rollout")
+	}
+	if err := svc.countUpdateRolloutHosts(ctx, rollout, time.Now()); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create update rollout
+////////////////////////////////////////////////////////////////////////////////
+
+type createUpdateRolloutRequest struct {
+	fleet.UpdateRolloutPayload
+}
+
+func createUpdateRolloutEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*createUpdateRolloutRequest)
+	rollout, err := svc.NewUpdateRollout(ctx, req.UpdateRolloutPayload)
+	if err != nil {
+		return updateRolloutResponse{Err: err}, nil
+	}
+	return updateRolloutResponse{Rollout: rollout}, nil
+}
+
+func (svc *Service) NewUpdateRollout(ctx context.Context, p fleet.UpdateRolloutPayload) (*fleet.UpdateRollout, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionWrite); err != nil {
+		return nil, err
+	}
+	if !license.IsPremium(ctx) {
+		return nil, fleet.ErrMissingLicense
+	}
+
+	rollout := &fleet.UpdateRollout{
+		TeamID:                p.TeamID,
+		LabelID:               p.LabelID,
+		HaltOfflinePercentage: fleet.DefaultUpdateRolloutHaltOfflinePercentage,
+		OfflineMinutes:        fleet.DefaultUpdateRolloutOfflineMinutes,
+		Status:                fleet.UpdateRolloutStatusActive,
+	}
+	if p.Name != nil {
+		rollout.Name = *p.Name
+	}
+	if p.Target != nil {
+		rollout.Target = *p.Target
+	}
+	if p.Channel != nil {
+		rollout.Channel = *p.Channel
+	}
+	if p.Percentage != nil {
+		rollout.Percentage = *p.Percentage
+	}
+	if p.HaltOfflinePercentage != nil {
+		rollout.HaltOfflinePercentage = *p.HaltOfflinePercentage
+	}
+	if p.OfflineMinutes != nil {
+		rollout.OfflineMinutes = *p.OfflineMinutes
+	}
+	if p.Status != nil && *p.Status != fleet.UpdateRolloutStatusActive {
+		return nil, fleet.NewInvalidArgumentError("status", "a new rollout must be active")
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, fleet.NewInvalidArgumentError("rollout", err.Error())
+	}
+
+	if rollout.TeamID != nil {
+		if _, err := svc.ds.Team(ctx, *rollout.TeamID); err != nil {
+			if fleet.IsNotFound(err) {
+				return nil, fleet.NewInvalidArgumentError("team_id", "team does not exist")
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get team")
+		}
+	}
+	if rollout.LabelID != nil {
+		if _, err := svc.ds.Label(ctx, *rollout.LabelID); err != nil {
+			if fleet.IsNotFound(err) {
+				return nil, fleet.NewInvalidArgumentError("label_id", "label does not exist")
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get label")
+		}
+	}
+
+	rollout, err := svc.ds.NewUpdateRollout(ctx, rollout)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create update rollout")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeCreatedUpdateRollout{
+		RolloutID:   rollout.ID,
+		RolloutName: rollout.Name,
+		Target:      rollout.Target,
+		Channel:     rollout.Channel,
+		Percentage:  rollout.Percentage,
+	}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for new update rollout")
+	}
+	return rollout, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Modify update rollout
+////////////////////////////////////////////////////////////////////////////////
+
+type modifyUpdateRolloutRequest struct {
+	ID uint `json:"-" url:"id"`
+	fleet.UpdateRolloutPayload
+}
+
+func modifyUpdateRolloutEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*modifyUpdateRolloutRequest)
+	rollout, err := svc.ModifyUpdateRollout(ctx, req.ID, req.UpdateRolloutPayload)
+	if err != nil {
+		return updateRolloutResponse{Err: err}, nil
+	}
+	return updateRolloutResponse{Rollout: rollout}, nil
+}
+
+func (svc *Service) ModifyUpdateRollout(ctx context.Context, id uint, p fleet.UpdateRolloutPayload) (*fleet.UpdateRollout, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionWrite); err != nil {
+		return nil, err
+	}
+	if !license.IsPremium(ctx) {
+		return nil, fleet.ErrMissingLicense
+	}
+
+	if p.Target != nil || p.Channel != nil || p.TeamID != nil || p.LabelID != nil {
+		return nil, fleet.NewInvalidArgumentError("rollout", "the target, channel, team_id and label_id of a rollout cannot be modified, create a new rollout instead")
+	}
+
+	rollout, err := svc.ds.UpdateRollout(ctx, id)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get update rollout")
+	}
+	if p.Name != nil {
+		rollout.Name = *p.Name
+	}
+	if p.Percentage != nil {
+		rollout.Percentage = *p.Percentage
+	}
+	if p.HaltOfflinePercentage != nil {
+		rollout.HaltOfflinePercentage = *p.HaltOfflinePercentage
+	}
+	if p.OfflineMinutes != nil {
+		rollout.OfflineMinutes = *p.OfflineMinutes
+	}
+	if p.Status != nil && *p.Status != rollout.Status {
+		rollout.Status = *p.Status
+		switch rollout.Status {
+		case fleet.UpdateRolloutStatusHalted:
+			rollout.HaltedAt = ptr.Time(time.Now().UTC())
+			rollout.HaltReason = "halted by a user"
+		case fleet.UpdateRolloutStatusActive:
+			rollout.HaltedAt = nil
+			rollout.HaltReason = ""
+		}
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, fleet.NewInvalidArgumentError("rollout", err.Error())
+	}
+
+	if err := svc.ds.SaveUpdateRollout(ctx, rollout); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "save update rollout")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeEditedUpdateRollout{
+		RolloutID:   rollout.ID,
+		RolloutName: rollout.Name,
+		Target:      rollout.Target,
+		Channel:     rollout.Channel,
+		Percentage:  rollout.Percentage,
+		Status:      rollout.Status,
+	}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for modified update rollout")
+	}
+	if err := svc.countUpdateRolloutHosts(ctx, rollout, time.Now()); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete update rollout
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteUpdateRolloutRequest struct {
+	ID uint `url:"id"`
+}
+
+type deleteUpdateRolloutResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteUpdateRolloutResponse) error() error { return r.Err }
+
+func deleteUpdateRolloutEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*deleteUpdateRolloutRequest)
+	if err := svc.DeleteUpdateRollout(ctx, req.ID); err != nil {
+		return deleteUpdateRolloutResponse{Err: err}, nil
+	}
+	return deleteUpdateRolloutResponse{}, nil
+}
+
+func (svc *Service) DeleteUpdateRollout(ctx context.Context, id uint) error {
+	if err := svc.authz.Authorize(ctx, &fleet.AppConfig{}, fleet.ActionWrite); err != nil {
+		return err
+	}
+	if !license.IsPremium(ctx) {
+		return fleet.ErrMissingLicense
+	}
+
+	rollout, err := svc.ds.UpdateRollout(ctx, id)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get update rollout")
+	}
+	if err := svc.ds.DeleteUpdateRollout(ctx, id); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete update rollout")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeDeletedUpdateRollout{
+		RolloutID:   rollout.ID,
+		RolloutName: rollout.Name,
+		Target:      rollout.Target,
+		Channel:     rollout.Channel,
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for deleted update rollout")
+	}
+	return nil
+}
+
+// orbitUpdateChannels returns the update channels of the targets of the host:
+// the channels of its team, overridden by the channels that the rollouts pin
+// the host to. If record is true, the hosts that are pinned by a rollout for
+// the first time are recorded so that they stay pinned if the rollout is
+// narrowed or halted.
+func (svc *Service) orbitUpdateChannels(ctx context.Context, host *fleet.Host, record bool) (map[string]string, error) {
+	var channels map[string]string
+	if host.TeamID != nil {
+		teamChannels, err := svc.ds.TeamUpdateChannels(ctx, *host.TeamID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "get team update channels")
+		}
+		if teamChannels != nil && !teamChannels.IsEmpty() {
+			channels = teamChannels.ByTarget()
+		}
+	}
+
+	rolloutChannels, err := svc.updateRolloutChannels(ctx, host, record)
+	if err != nil {
+		return nil, err
+	}
+	for target, channel := range rolloutChannels {
+		if channels == nil {
+			channels = make(map[string]string)
+		}
+		channels[target] = channel
+	}
+	return channels, nil
+}
+
+// hostUpdateChannels returns the update channels that Fleet pins the targets
+// of the host to and the ones Orbit reported using, or nil if there are none.
+func (svc *Service) hostUpdateChannels(ctx context.Context, host *fleet.Host) (*fleet.HostUpdateChannels, error) {
+	reported, err := svc.ds.HostOrbitChannels(ctx, host.ID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get host orbit channels")
+	}
+	// the host is not pinned by this call, it is when Orbit fetches its
+	// config.
+	desired, err := svc.orbitUpdateChannels(ctx, host, false)
+	if err != nil {
+		return nil, err
+	}
+	if reported == nil && len(desired) == 0 {
+		return nil, nil
+	}
+
+	channels := &fleet.HostUpdateChannels{Desired: fleet.UpdateChannelsByTarget(desired)}
+	if reported != nil {
+		channels.Reported = *reported
+	}
+	return channels, nil
+}
+
+// updateRolloutChannels returns the update channels that the rollouts pin the
+// targets of the host to.
+func (svc *Service) updateRolloutChannels(ctx context.Context, host *fleet.Host, record bool) (map[string]string, error) {
+	rollouts, err := svc.ds.ListUpdateRollouts(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list update rollouts")
+	}
+	if len(rollouts) == 0 {
+		return nil, nil
+	}
+
+	rolloutHost := fleet.UpdateRolloutHost{
+		UUID:             host.UUID,
+		TeamID:           host.TeamID,
+		LabelIDs:         make(map[uint]bool),
+		PinnedRolloutIDs: make(map[uint]bool),
+	}
+	pinnedIDs, err := svc.ds.ListHostUpdateRolloutIDs(ctx, host.ID)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list host update rollouts")
+	}
+	for _, id := range pinnedIDs {
+		rolloutHost.PinnedRolloutIDs[id] = true
+	}
+
+	// the labels of the host are only needed if a label-scoped rollout would
+	// otherwise pin it.
+	for _, r := range rollouts {
+		if r.LabelID == nil || !r.InTeamScope(rolloutHost.TeamID) {
+			continue
+		}
+		if !rolloutHost.PinnedRolloutIDs[r.ID] && (r.Status != fleet.UpdateRolloutStatusActive || !r.InRing(host.UUID)) {
+			continue
+		}
+		labels, err := svc.ds.ListLabelsForHost(ctx, host.ID)
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "list labels for host")
+		}
+		for _, l := range labels {
+			rolloutHost.LabelIDs[l.ID] = true
+		}
+		break
+	}
+
+	channels, newIDs := fleet.UpdateRolloutChannels(rollouts, rolloutHost)
+	if record {
+		if err := svc.ds.AddHostUpdateRollouts(ctx, host.ID, newIDs, time.Now()); err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "add host update rollouts")
+		}
+	}
+	return channels, nil
+}
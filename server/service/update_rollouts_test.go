@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	hostctx "github.com/fleetdm/fleet/v4/server/contexts/host"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateRolloutsAuth(t *testing.T) {
+	ds := new(mock.Store)
+	license := &fleet.LicenseInfo{Tier: fleet.TierPremium, Expiration: time.Now().Add(24 * time.Hour)}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+
+	ds.UpdateRolloutFunc = func(ctx context.Context, id uint) (*fleet.UpdateRollout, error) {
+		return &fleet.UpdateRollout{ID: id, Name: "r", Target: "osqueryd", Channel: "5.8.2", OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusActive}, nil
+	}
+	ds.NewUpdateRolloutFunc = func(ctx context.Context, rollout *fleet.UpdateRollout) (*fleet.UpdateRollout, error) {
+		rollout.ID = 1
+		return rollout, nil
+	}
+	ds.SaveUpdateRolloutFunc = func(ctx context.Context, rollout *fleet.UpdateRollout) error { return nil }
+	ds.DeleteUpdateRolloutFunc = func(ctx context.Context, id uint) error { return nil }
+	ds.CountUpdateRolloutHostsFunc = func(ctx context.Context, rolloutID uint, offlineSince time.Time) (uint, uint, error) {
+		return 0, 0, nil
+	}
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error { return nil }
+
+	testCases := []struct {
+		name            string
+		user            *fleet.User
+		shouldFailWrite bool
+	}{
+		{"global admin", &fleet.User{GlobalRole: ptr.String(fleet.RoleAdmin)}, false},
+		{"global maintainer", &fleet.User{GlobalRole: ptr.String(fleet.RoleMaintainer)}, true},
+		{"team admin", &fleet.User{Teams: []fleet.UserTeam{{Team: fleet.Team{ID: 1}, Role: fleet.RoleAdmin}}}, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := test.UserContext(ctx, tt.user)
+
+			_, err := svc.NewUpdateRollout(ctx, fleet.UpdateRolloutPayload{
+				Name:    ptr.String("r"),
+				Target:  ptr.String("osqueryd"),
+				Channel: ptr.String("5.8.2"),
+			})
+			checkAuthErr(t, tt.shouldFailWrite, err)
+
+			_, err = svc.ModifyUpdateRollout(ctx, 1, fleet.UpdateRolloutPayload{Percentage: ptr.Uint(50)})
+			checkAuthErr(t, tt.shouldFailWrite, err)
+
+			err = svc.DeleteUpdateRollout(ctx, 1)
+			checkAuthErr(t, tt.shouldFailWrite, err)
+		})
+	}
+
+	// the rollouts require a premium license
+	svc, ctx = newTestService(t, ds, nil, nil, &TestServerOpts{SkipCreateTestUsers: true})
+	_, err := svc.ListUpdateRollouts(test.UserContext(ctx, test.UserAdmin))
+	require.ErrorIs(t, err, fleet.ErrMissingLicense)
+}
+
+func TestModifyUpdateRollout(t *testing.T) {
+	ds := new(mock.Store)
+	license := &fleet.LicenseInfo{Tier: fleet.TierPremium, Expiration: time.Now().Add(24 * time.Hour)}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	ds.UpdateRolloutFunc = func(ctx context.Context, id uint) (*fleet.UpdateRollout, error) {
+		return &fleet.UpdateRollout{
+			ID: id, Name: "r", Target: "osqueryd", Channel: "5.8.2", Percentage: 5,
+			HaltOfflinePercentage: 10, OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusHalted,
+			HaltedAt: ptr.Time(time.Now()), HaltReason: "2 of 10 pinned hosts did not check in for 60 minutes",
+		}, nil
+	}
+	var saved *fleet.UpdateRollout
+	ds.SaveUpdateRolloutFunc = func(ctx context.Context, rollout *fleet.UpdateRollout) error {
+		saved = rollout
+		return nil
+	}
+	ds.CountUpdateRolloutHostsFunc = func(ctx context.Context, rolloutID uint, offlineSince time.Time) (uint, uint, error) {
+		return 10, 1, nil
+	}
+	var activity fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, a fleet.ActivityDetails) error {
+		activity = a
+		return nil
+	}
+
+	// the channel cannot be modified
+	_, err := svc.ModifyUpdateRollout(ctx, 1, fleet.UpdateRolloutPayload{Channel: ptr.String("5.8.3")})
+	require.ErrorContains(t, err, "cannot be modified")
+	_, err = svc.ModifyUpdateRollout(ctx, 1, fleet.UpdateRolloutPayload{Percentage: ptr.Uint(200)})
+	require.ErrorContains(t, err, "percentage must be between 0 and 100")
+	require.Nil(t, saved)
+
+	// widening and resuming the rollout clears the halt
+	rollout, err := svc.ModifyUpdateRollout(ctx, 1, fleet.UpdateRolloutPayload{
+		Percentage: ptr.Uint(25),
+		Status:     (*fleet.UpdateRolloutStatus)(ptr.String(string(fleet.UpdateRolloutStatusActive))),
+	})
+	require.NoError(t, err)
+	require.Equal(t, saved, rollout)
+	require.Equal(t, uint(25), rollout.Percentage)
+	require.Equal(t, fleet.UpdateRolloutStatusActive, rollout.Status)
+	require.Nil(t, rollout.HaltedAt)
+	require.Empty(t, rollout.HaltReason)
+	require.Equal(t, uint(10), rollout.HostsCount)
+	require.Equal(t, uint(1), rollout.OfflineHostsCount)
+	require.Equal(t, fleet.ActivityTypeEditedUpdateRollout{
+		RolloutID:   1,
+		RolloutName: "r",
+		Target:      "osqueryd",
+		Channel:     "5.8.2",
+		Percentage:  25,
+		Status:      fleet.UpdateRolloutStatusActive,
+	}, activity)
+}
+
+func TestGetOrbitConfigUpdateChannels(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{}, nil
+	}
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return []*fleet.UpdateRollout{
+			{ID: 1, Target: "osqueryd", Channel: "5.8.2", Percentage: 100, Status: fleet.UpdateRolloutStatusActive},
+			{ID: 2, Target: "orbit", Channel: "1.8.0", LabelID: ptr.Uint(3), Percentage: 100, Status: fleet.UpdateRolloutStatusActive},
+			{ID: 3, Target: "desktop", Channel: "1.8.0", Percentage: 100, Status: fleet.UpdateRolloutStatusHalted},
+		}, nil
+	}
+	ds.ListLabelsForHostFunc = func(ctx context.Context, hid uint) ([]*fleet.Label, error) {
+		return []*fleet.Label{{ID: 3}}, nil
+	}
+	ds.ListHostUpdateRolloutIDsFunc = func(ctx context.Context, hostID uint) ([]uint, error) {
+		return []uint{2}, nil
+	}
+	var added []uint
+	ds.AddHostUpdateRolloutsFunc = func(ctx context.Context, hostID uint, rolloutIDs []uint, pinnedAt time.Time) error {
+		require.Equal(t, uint(1), hostID)
+		added = rolloutIDs
+		return nil
+	}
+
+	ctx = hostctx.NewContext(ctx, &fleet.Host{ID: 1, UUID: "uuid"})
+	cfg, err := svc.GetOrbitConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"osqueryd": "5.8.2", "orbit": "1.8.0"}, cfg.UpdateChannels)
+	require.Equal(t, []uint{1}, added)
+
+	// the channels of the team apply to the targets not pinned by a rollout
+	ds.TeamUpdateChannelsFunc = func(ctx context.Context, teamID uint) (*fleet.UpdateChannels, error) {
+		require.Equal(t, uint(2), teamID)
+		return &fleet.UpdateChannels{Osqueryd: "stable", Desktop: "edge"}, nil
+	}
+	ds.TeamAgentOptionsFunc = func(ctx context.Context, teamID uint) (*json.RawMessage, error) {
+		return nil, nil
+	}
+	ds.TeamMDMConfigFunc = func(ctx context.Context, teamID uint) (*fleet.TeamMDM, error) {
+		return nil, nil
+	}
+	teamHost := &fleet.Host{ID: 1, UUID: "uuid", TeamID: ptr.Uint(2)}
+	cfg, err = svc.GetOrbitConfig(hostctx.NewContext(ctx, teamHost))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"osqueryd": "5.8.2", "orbit": "1.8.0", "desktop": "edge"}, cfg.UpdateChannels)
+
+	// the host details show the desired channels without pinning the host
+	added = nil
+	ds.AddHostUpdateRolloutsFuncInvoked = false
+	ds.HostOrbitChannelsFunc = func(ctx context.Context, hostID uint) (*fleet.UpdateChannels, error) {
+		return &fleet.UpdateChannels{Orbit: "stable", Osqueryd: "stable", Desktop: "stable"}, nil
+	}
+	channels, err := (&Service{ds: ds}).hostUpdateChannels(ctx, teamHost)
+	require.NoError(t, err)
+	require.Equal(t, fleet.UpdateChannels{Orbit: "1.8.0", Osqueryd: "5.8.2", Desktop: "edge"}, channels.Desired)
+	require.Equal(t, fleet.UpdateChannels{Orbit: "stable", Osqueryd: "stable", Desktop: "stable"}, channels.Reported)
+	require.False(t, ds.AddHostUpdateRolloutsFuncInvoked)
+
+	// the labels of the host are not loaded if no label-scoped rollout would
+	// pin it
+	require.True(t, ds.ListLabelsForHostFuncInvoked)
+	ds.ListLabelsForHostFuncInvoked = false
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return []*fleet.UpdateRollout{
+			{ID: 1, Target: "osqueryd", Channel: "5.8.2", Percentage: 100, Status: fleet.UpdateRolloutStatusActive},
+			{ID: 2, Target: "orbit", Channel: "1.8.0", LabelID: ptr.Uint(3), TeamID: ptr.Uint(5), Percentage: 100, Status: fleet.UpdateRolloutStatusActive},
+			{ID: 4, Target: "desktop", Channel: "1.8.0", LabelID: ptr.Uint(3), Percentage: 100, Status: fleet.UpdateRolloutStatusHalted},
+		}, nil
+	}
+	cfg, err = svc.GetOrbitConfig(hostctx.NewContext(ctx, teamHost))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"osqueryd": "5.8.2", "desktop": "edge"}, cfg.UpdateChannels)
+	require.False(t, ds.ListLabelsForHostFuncInvoked)
+}
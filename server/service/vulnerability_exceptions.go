@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+
+	"github.com/fleetdm/fleet/v4/server/authz"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/contexts/license"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List vulnerability exceptions
+////////////////////////////////////////////////////////////////////////////////
+
+type listVulnerabilityExceptionsRequest struct {
+	fleet.VulnerabilityExceptionListOptions
+}
+
+type listVulnerabilityExceptionsResponse struct {
+	Exceptions []*fleet.VulnerabilityException `json:"exceptions"`
+	Err        error                           `json:"error,omitempty"`
+}
+
+func (r listVulnerabilityExceptionsResponse) error() error { return r.Err }
+
+func listVulnerabilityExceptionsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listVulnerabilityExceptionsRequest)
+	exceptions, err := svc.ListVulnerabilityExceptions(ctx, req.VulnerabilityExceptionListOptions)
+	if err != nil {
+		return listVulnerabilityExceptionsResponse{Err: err}, nil
+	}
+	return listVulnerabilityExceptionsResponse{Exceptions: exceptions}, nil
+}
+
+func (svc *Service) ListVulnerabilityExceptions(ctx context.Context, opts fleet.VulnerabilityExceptionListOptions) ([]*fleet.VulnerabilityException, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.VulnerabilityException{TeamID: opts.TeamID}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	exceptions, err := svc.ds.ListVulnerabilityExceptions(ctx, opts)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "list vulnerability exceptions")
+	}
+	if exceptions == nil {
+		exceptions = []*fleet.VulnerabilityException{}
+	}
+	return exceptions, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Get vulnerability exception
+////////////////////////////////////////////////////////////////////////////////
+
+type getVulnerabilityExceptionRequest struct {
+	ID uint `url:"id"`
+}
+
+type vulnerabilityExceptionResponse struct {
+	Exception *fleet.VulnerabilityException `json:"exception,omitempty"`
+	Err       error                         `json:"error,omitempty"`
+}
+
+func (r vulnerabilityExceptionResponse) error() error { return r.Err }
+
+func getVulnerabilityExceptionEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*getVulnerabilityExceptionRequest)
+	exception, err := svc.GetVulnerabilityException(ctx, req.ID)
+	if err != nil {
+		return vulnerabilityExceptionResponse{Err: err}, nil
+	}
+	return vulnerabilityExceptionResponse{Exception: exception}, nil
+}
+
+func (svc *Service) GetVulnerabilityException(ctx context.Context, id uint) (*fleet.VulnerabilityException, error) {
+	return svc.authorizedVulnerabilityException(ctx, id, fleet.ActionRead)
+}
+
+// authorizedVulnerabilityException returns the vulnerability exception after
+// authorizing the action on it, which depends on its team.
+func (svc *Service) authorizedVulnerabilityException(ctx context.Context, id uint, action string) (*fleet.VulnerabilityException, error) {
+	exception, err := svc.ds.VulnerabilityException(ctx, id)
+	if err != nil {
+		setAuthCheckedOnPreAuthErr(ctx)
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability exception")
+	}
+	if err := svc.authz.Authorize(ctx, exception, action); err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create vulnerability exception
+////////////////////////////////////////////////////////////////////////////////
+
+type createVulnerabilityExceptionRequest struct {
+	fleet.VulnerabilityExceptionPayload
+}
+
+func createVulnerabilityExceptionEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*createVulnerabilityExceptionRequest)
+	exception, err := svc.NewVulnerabilityException(ctx, req.VulnerabilityExceptionPayload)
+	if err != nil {
+		return vulnerabilityExceptionResponse{Err: err}, nil
+	}
+	return vulnerabilityExceptionResponse{Exception: exception}, nil
+}
+
+func (svc *Service) NewVulnerabilityException(ctx context.Context, p fleet.VulnerabilityExceptionPayload) (*fleet.VulnerabilityException, error) {
+	exception := &fleet.VulnerabilityException{
+		TeamID:     p.TeamID,
+		SoftwareID: p.SoftwareID,
+		HostID:     p.HostID,
+		ExpiresAt:  p.ExpiresAt,
+	}
+
+	if exception.HostID != nil {
+		// First ensure the user has access to list hosts, then the exception
+		// belongs to the team of the host.
+		if err := svc.authz.Authorize(ctx, &fleet.Host{}, fleet.ActionList); err != nil {
+			return nil, err
+		}
+		host, err := svc.ds.HostLite(ctx, *exception.HostID)
+		if err != nil {
+			if fleet.IsNotFound(err) {
+				return nil, fleet.NewInvalidArgumentError("host_id", "host does not exist")
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get host")
+		}
+		if exception.TeamID != nil && (host.TeamID == nil || *host.TeamID != *exception.TeamID) {
+			return nil, fleet.NewInvalidArgumentError("team_id", "the host does not belong to the team")
+		}
+		exception.TeamID = host.TeamID
+	}
+
+	if err := svc.authz.Authorize(ctx, exception, fleet.ActionWrite); err != nil {
+		return nil, err
+	}
+	if p.TeamID != nil && !license.IsPremium(ctx) {
+		return nil, fleet.ErrMissingLicense
+	}
+
+	if p.CVE != nil {
+		exception.CVE = *p.CVE
+	}
+	if p.Reason != nil {
+		exception.Reason = *p.Reason
+	}
+	if p.Justification != nil {
+		exception.Justification = *p.Justification
+	}
+	if p.Owner != nil {
+		exception.Owner = *p.Owner
+	}
+	if err := exception.Validate(); err != nil {
+		return nil, fleet.NewInvalidArgumentError("exception", err.Error())
+	}
+
+	if p.TeamID != nil && p.HostID == nil {
+		if _, err := svc.ds.Team(ctx, *p.TeamID); err != nil {
+			if fleet.IsNotFound(err) {
+				return nil, fleet.NewInvalidArgumentError("team_id", "team does not exist")
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get team")
+		}
+	}
+	if exception.SoftwareID != nil {
+		if _, err := svc.ds.SoftwareByID(ctx, *exception.SoftwareID, false); err != nil {
+			if fleet.IsNotFound(err) {
+				return nil, fleet.NewInvalidArgumentError("software_id", "software does not exist")
+			}
+			return nil, ctxerr.Wrap(ctx, err, "get software")
+		}
+	}
+	if user := authz.UserFromContext(ctx); user != nil {
+		exception.AuthorID = &user.ID
+	}
+
+	exception, err := svc.ds.NewVulnerabilityException(ctx, exception)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create vulnerability exception")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeCreatedVulnerabilityException{
+		ExceptionID: exception.ID,
+		CVE:         exception.CVE,
+		Reason:      exception.Reason,
+		TeamID:      exception.TeamID,
+		SoftwareID:  exception.SoftwareID,
+		HostID:      exception.HostID,
+		ExpiresAt:   exception.ExpiresAt,
+	}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for new vulnerability exception")
+	}
+	return exception, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Modify vulnerability exception
+////////////////////////////////////////////////////////////////////////////////
+
+type modifyVulnerabilityExceptionRequest struct {
+	ID uint `json:"-" url:"id"`
+	fleet.VulnerabilityExceptionPayload
+}
+
+func modifyVulnerabilityExceptionEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*modifyVulnerabilityExceptionRequest)
+	exception, err := svc.ModifyVulnerabilityException(ctx, req.ID, req.VulnerabilityExceptionPayload)
+	if err != nil {
+		return vulnerabilityExceptionResponse{Err: err}, nil
+	}
+	return vulnerabilityExceptionResponse{Exception: exception}, nil
+}
+
+func (svc *Service) ModifyVulnerabilityException(ctx context.Context, id uint, p fleet.VulnerabilityExceptionPayload) (*fleet.VulnerabilityException, error) {
+	exception, err := svc.authorizedVulnerabilityException(ctx, id, fleet.ActionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.CVE != nil || p.TeamID != nil || p.SoftwareID != nil || p.HostID != nil {
+		return nil, fleet.NewInvalidArgumentError("exception", "the cve, team_id, software_id and host_id of an exception cannot be modified, create a new exception instead")
+	}
+	if p.Reason != nil {
+		exception.Reason = *p.Reason
+	}
+	if p.Justification != nil {
+		exception.Justification = *p.Justification
+	}
+	if p.Owner != nil {
+		exception.Owner = *p.Owner
+	}
+	if p.ExpiresAt != nil {
+		exception.ExpiresAt = p.ExpiresAt
+	}
+	if err := exception.Validate(); err != nil {
+		return nil, fleet.NewInvalidArgumentError("exception", err.Error())
+	}
+
+	if err := svc.ds.SaveVulnerabilityException(ctx, exception); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "save vulnerability exception")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeEditedVulnerabilityException{
+		ExceptionID: exception.ID,
+		CVE:         exception.CVE,
+		Reason:      exception.Reason,
+		ExpiresAt:   exception.ExpiresAt,
+	}); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "create activity for modified vulnerability exception")
+	}
+	return exception, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete vulnerability exception
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteVulnerabilityExceptionRequest struct {
+	ID uint `url:"id"`
+}
+
+type deleteVulnerabilityExceptionResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteVulnerabilityExceptionResponse) error() error { return r.Err }
+
+func deleteVulnerabilityExceptionEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*deleteVulnerabilityExceptionRequest)
+	if err := svc.DeleteVulnerabilityException(ctx, req.ID); err != nil {
+		return deleteVulnerabilityExceptionResponse{Err: err}, nil
+	}
+	return deleteVulnerabilityExceptionResponse{}, nil
+}
+
+func (svc *Service) DeleteVulnerabilityException(ctx context.Context, id uint) error {
+	exception, err := svc.authorizedVulnerabilityException(ctx, id, fleet.ActionWrite)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ds.DeleteVulnerabilityException(ctx, id); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete vulnerability exception")
+	}
+	if err := svc.ds.NewActivity(ctx, authz.UserFromContext(ctx), fleet.ActivityTypeDeletedVulnerabilityException{
+		ExceptionID: exception.ID,
+		CVE:         exception.CVE,
+	}); err != nil {
+		return ctxerr.Wrap(ctx, err, "create activity for deleted vulnerability exception")
+	}
+	return nil
+}
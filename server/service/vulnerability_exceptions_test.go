@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityExceptionsAuth(t *testing.T) {
+	ds := new(mock.Store)
+	license := &fleet.LicenseInfo{Tier: fleet.TierPremium, Expiration: time.Now().Add(24 * time.Hour)}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+
+	ds.VulnerabilityExceptionFunc = func(ctx context.Context, id uint) (*fleet.VulnerabilityException, error) {
+		e := &fleet.VulnerabilityException{
+			ID: id, CVE: "CVE-2022-0001", Reason: fleet.VulnerabilityExceptionAcceptedRisk, Justification: "mitigated",
+		}
+		// exception 2 belongs to team 1
+		if id == 2 {
+			e.TeamID = ptr.Uint(1)
+		}
+		return e, nil
+	}
+	ds.ListVulnerabilityExceptionsFunc = func(ctx context.Context, opts fleet.VulnerabilityExceptionListOptions) ([]*fleet.VulnerabilityException, error) {
+		return nil, nil
+	}
+	ds.NewVulnerabilityExceptionFunc = func(ctx context.Context, exception *fleet.VulnerabilityException) (*fleet.VulnerabilityException, error) {
+		exception.ID = 1
+		return exception, nil
+	}
+	ds.SaveVulnerabilityExceptionFunc = func(ctx context.Context, exception *fleet.VulnerabilityException) error { return nil }
+	ds.DeleteVulnerabilityExceptionFunc = func(ctx context.Context, id uint) error { return nil }
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) { return &fleet.Team{ID: tid}, nil }
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error { return nil }
+
+	testCases := []struct {
+		name                string
+		user                *fleet.User
+		shouldFailGlobal    bool
+		shouldFailTeamRead  bool
+		shouldFailTeamWrite bool
+	}{
+		{"global admin", test.UserAdmin, false, false, false},
+		{"global maintainer", test.UserMaintainer, false, false, false},
+		{"global observer", test.UserObserver, true, false, true},
+		{"team admin", test.UserTeamAdminTeam1, true, false, false},
+		{"team observer", test.UserTeamObserverTeam1, true, false, true},
+		{"other team admin", test.UserTeamAdminTeam2, true, true, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := test.UserContext(ctx, tt.user)
+
+			payload := fleet.VulnerabilityExceptionPayload{
+				CVE:           ptr.String("CVE-2022-0001"),
+				Reason:        (*fleet.VulnerabilityExceptionReason)(ptr.String(string(fleet.VulnerabilityExceptionFalsePositive))),
+				Justification: ptr.String("wrong match"),
+			}
+			_, err := svc.NewVulnerabilityException(ctx, payload)
+			checkAuthErr(t, tt.shouldFailGlobal, err)
+			_, err = svc.ModifyVulnerabilityException(ctx, 1, fleet.VulnerabilityExceptionPayload{Owner: ptr.String("secops")})
+			checkAuthErr(t, tt.shouldFailGlobal, err)
+			err = svc.DeleteVulnerabilityException(ctx, 1)
+			checkAuthErr(t, tt.shouldFailGlobal, err)
+
+			payload.TeamID = ptr.Uint(1)
+			_, err = svc.NewVulnerabilityException(ctx, payload)
+			checkAuthErr(t, tt.shouldFailTeamWrite, err)
+			_, err = svc.ModifyVulnerabilityException(ctx, 2, fleet.VulnerabilityExceptionPayload{Owner: ptr.String("secops")})
+			checkAuthErr(t, tt.shouldFailTeamWrite, err)
+			err = svc.DeleteVulnerabilityException(ctx, 2)
+			checkAuthErr(t, tt.shouldFailTeamWrite, err)
+
+			_, err = svc.GetVulnerabilityException(ctx, 2)
+			checkAuthErr(t, tt.shouldFailTeamRead, err)
+			_, err = svc.ListVulnerabilityExceptions(ctx, fleet.VulnerabilityExceptionListOptions{TeamID: ptr.Uint(1)})
+			checkAuthErr(t, tt.shouldFailTeamRead, err)
+		})
+	}
+
+	// team exceptions require a premium license
+	svc, ctx = newTestService(t, ds, nil, nil, &TestServerOpts{SkipCreateTestUsers: true})
+	_, err := svc.NewVulnerabilityException(test.UserContext(ctx, test.UserAdmin), fleet.VulnerabilityExceptionPayload{
+		CVE:           ptr.String("CVE-2022-0001"),
+		TeamID:        ptr.Uint(1),
+		Reason:        (*fleet.VulnerabilityExceptionReason)(ptr.String(string(fleet.VulnerabilityExceptionFalsePositive))),
+		Justification: ptr.String("wrong match"),
+	})
+	require.ErrorIs(t, err, fleet.ErrMissingLicense)
+}
+
+func TestNewVulnerabilityException(t *testing.T) {
+	ds := new(mock.Store)
+	license := &fleet.LicenseInfo{Tier: fleet.TierPremium, Expiration: time.Now().Add(24 * time.Hour)}
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{License: license, SkipCreateTestUsers: true})
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	ds.HostLiteFunc = func(ctx context.Context, id uint) (*fleet.Host, error) {
+		if id != 42 {
+			return nil, &notFoundError{}
+		}
+		return &fleet.Host{ID: id, TeamID: ptr.Uint(3)}, nil
+	}
+	ds.SoftwareByIDFunc = func(ctx context.Context, id uint, includeCVEScores bool) (*fleet.Software, error) {
+		return &fleet.Software{ID: id}, nil
+	}
+	var created *fleet.VulnerabilityException
+	ds.NewVulnerabilityExceptionFunc = func(ctx context.Context, exception *fleet.VulnerabilityException) (*fleet.VulnerabilityException, error) {
+		exception.ID = 1
+		created = exception
+		return exception, nil
+	}
+	var activity fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, a fleet.ActivityDetails) error {
+		activity = a
+		return nil
+	}
+
+	reason := func(r fleet.VulnerabilityExceptionReason) *fleet.VulnerabilityExceptionReason { return &r }
+
+	// invalid payloads
+	_, err := svc.NewVulnerabilityException(ctx, fleet.VulnerabilityExceptionPayload{
+		CVE: ptr.String("not-a-cve"), Reason: reason(fleet.VulnerabilityExceptionFalsePositive), Justification: ptr.String("x"),
+	})
+	require.ErrorContains(t, err, "invalid CVE")
+	_, err = svc.NewVulnerabilityException(ctx, fleet.VulnerabilityExceptionPayload{
+		CVE: ptr.String("CVE-2022-0001"), Reason: reason("wontfix"), Justification: ptr.String("x"),
+	})
+	require.ErrorContains(t, err, "reason must be")
+	_, err = svc.NewVulnerabilityException(ctx, fleet.VulnerabilityExceptionPayload{
+		CVE: ptr.String("CVE-2022-0001"), Reason: reason(fleet.VulnerabilityExceptionFalsePositive),
+	})
+	require.ErrorContains(t, err, "justification is required")
+	_, err = svc.NewVulnerabilityException(ctx, fleet.VulnerabilityExceptionPayload{
+		CVE: ptr.String("CVE-2022-0001"), HostID: ptr.Uint(1), Reason: reason(fleet.VulnerabilityExceptionFalsePositive), Justification: ptr.String("x"),
+	})
+	require.ErrorContains(t, err, "host does not exist")
+	_, err = svc.NewVulnerabilityException(ctx, fleet.VulnerabilityExceptionPayload{
+		CVE: ptr.String("CVE-2022-0001"), HostID: ptr.Uint(42), TeamID: ptr.Uint(4), Reason: reason(fleet.VulnerabilityExceptionFalsePositive), Justification: ptr.String("x"),
+	})
+	require.ErrorContains(t, err, "the host does not belong to the team")
+	require.Nil(t, created)
+
+	// an exception on a host belongs to the team of the host
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UTC()
+	exception, err := svc.NewVulnerabilityException(ctx, fleet.VulnerabilityExceptionPayload{
+		CVE:           ptr.String("CVE-2022-0001"),
+		HostID:        ptr.Uint(42),
+		SoftwareID:    ptr.Uint(7),
+		Reason:        reason(fleet.VulnerabilityExceptionAcceptedRisk),
+		Justification: ptr.String("the extension is disabled"),
+		Owner:         ptr.String("secops"),
+		ExpiresAt:     &expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, created, exception)
+	require.Equal(t, uint(3), *exception.TeamID)
+	require.Equal(t, test.UserAdmin.ID, *exception.AuthorID)
+	require.Equal(t, "secops", exception.Owner)
+	require.Equal(t, fleet.ActivityTypeCreatedVulnerabilityException{
+		ExceptionID: 1,
+		CVE:         "CVE-2022-0001",
+		Reason:      fleet.VulnerabilityExceptionAcceptedRisk,
+		TeamID:      ptr.Uint(3),
+		SoftwareID:  ptr.Uint(7),
+		HostID:      ptr.Uint(42),
+		ExpiresAt:   &expiresAt,
+	}, activity)
+}
+
+func TestModifyVulnerabilityException(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil, &TestServerOpts{SkipCreateTestUsers: true})
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	ds.VulnerabilityExceptionFunc = func(ctx context.Context, id uint) (*fleet.VulnerabilityException, error) {
+		return &fleet.VulnerabilityException{
+			ID: id, CVE: "CVE-2022-0001", Reason: fleet.VulnerabilityExceptionAcceptedRisk, Justification: "mitigated",
+		}, nil
+	}
+	var saved *fleet.VulnerabilityException
+	ds.SaveVulnerabilityExceptionFunc = func(ctx context.Context, exception *fleet.VulnerabilityException) error {
+		saved = exception
+		return nil
+	}
+	var activity fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, a fleet.ActivityDetails) error {
+		activity = a
+		return nil
+	}
+
+	// the scope cannot be modified
+	_, err := svc.ModifyVulnerabilityException(ctx, 1, fleet.VulnerabilityExceptionPayload{HostID: ptr.Uint(1)})
+	require.ErrorContains(t, err, "cannot be modified")
+	_, err = svc.ModifyVulnerabilityException(ctx, 1, fleet.VulnerabilityExceptionPayload{Justification: ptr.String("")})
+	require.ErrorContains(t, err, "justification is required")
+	require.Nil(t, saved)
+
+	// extending the exception
+	expiresAt := time.Now().Add(90 * 24 * time.Hour).UTC()
+	exception, err := svc.ModifyVulnerabilityException(ctx, 1, fleet.VulnerabilityExceptionPayload{ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	require.Equal(t, saved, exception)
+	require.Equal(t, "mitigated", exception.Justification)
+	require.Equal(t, expiresAt, *exception.ExpiresAt)
+	require.Equal(t, fleet.ActivityTypeEditedVulnerabilityException{
+		ExceptionID: 1,
+		CVE:         "CVE-2022-0001",
+		Reason:      fleet.VulnerabilityExceptionAcceptedRisk,
+		ExpiresAt:   &expiresAt,
+	}, activity)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List host vulnerability remediations
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostVulnerabilityRemediationsRequest struct {
+	fleet.VulnerabilityRemediationListOptions
+}
+
+type listHostVulnerabilityRemediationsResponse struct {
+	Remediations []*fleet.HostVulnerabilityRemediation `json:"remediations"`
+	Meta         *fleet.PaginationMetadata             `json:"meta"`
+	Err          error                                 `json:"error,omitempty"`
+}
+
+func (r listHostVulnerabilityRemediationsResponse) error() error { return r.Err }
+
+func listHostVulnerabilityRemediationsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*listHostVulnerabilityRemediationsRequest)
+	remediations, meta, err := svc.ListHostVulnerabilityRemediations(ctx, req.VulnerabilityRemediationListOptions)
+	if err != nil {
+		return listHostVulnerabilityRemediationsResponse{Err: err}, nil
+	}
+	return listHostVulnerabilityRemediationsResponse{Remediations: remediations, Meta: meta}, nil
+}
+
+func (svc *Service) ListHostVulnerabilityRemediations(ctx context.Context, opts fleet.VulnerabilityRemediationListOptions) ([]*fleet.HostVulnerabilityRemediation, *fleet.PaginationMetadata, error) {
+	if opts.HostID != nil {
+		if err := svc.authz.Authorize(ctx, &fleet.Host{}, fleet.ActionList); err != nil {
+			return nil, nil, err
+		}
+		host, err := svc.ds.HostLite(ctx, *opts.HostID)
+		if err != nil {
+			return nil, nil, ctxerr.Wrap(ctx, err, "get host for vulnerability remediations")
+		}
+		// authorize again with the team of the host
+		if err := svc.authz.Authorize(ctx, host, fleet.ActionRead); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if err := svc.authz.Authorize(ctx, &fleet.AuthzSoftwareInventory{
+			TeamID: opts.TeamID,
+		}, fleet.ActionRead); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch opts.Status {
+	case "", fleet.VulnerabilityRemediationOpen, fleet.VulnerabilityRemediationResolved:
+	default:
+		return nil, nil, fleet.NewInvalidArgumentError("status", `status must be "open" or "resolved"`)
+	}
+	if opts.Severity != "" && !validCVESeverity(opts.Severity) {
+		return nil, nil, fleet.NewInvalidArgumentError("severity", "invalid severity: "+string(opts.Severity))
+	}
+
+	sla, err := svc.vulnerabilityRemediationSLA(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	remediations, meta, err := svc.ds.ListHostVulnerabilityRemediations(ctx, opts, sla, time.Now())
+	if err != nil {
+		return nil, nil, ctxerr.Wrap(ctx, err, "list host vulnerability remediations")
+	}
+	return remediations, meta, nil
+}
+
+func validCVESeverity(severity fleet.CVESeverity) bool {
+	for _, sev := range fleet.CVESeverities {
+		if sev == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func (svc *Service) vulnerabilityRemediationSLA(ctx context.Context) (fleet.VulnerabilityRemediationSLA, error) {
+	appConfig, err := svc.ds.AppConfig(ctx)
+	if err != nil {
+		return fleet.VulnerabilityRemediationSLA{}, ctxerr.Wrap(ctx, err, "get app config")
+	}
+	return appConfig.VulnerabilitySettings.RemediationSLA, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Vulnerability remediation stats
+////////////////////////////////////////////////////////////////////////////////
+
+type vulnerabilityRemediationStatsRequest struct {
+	TeamID *uint `query:"team_id,optional"`
+}
+
+type vulnerabilityRemediationStatsResponse struct {
+	TeamID *uint                                  `json:"team_id"`
+	Stats  []*fleet.VulnerabilityRemediationStats `json:"stats"`
+	Err    error                                  `json:"error,omitempty"`
+}
+
+func (r vulnerabilityRemediationStatsResponse) error() error { return r.Err }
+
+func vulnerabilityRemediationStatsEndpoint(ctx context.Context, request interface{}, svc fleet.Service) (errorer, error) {
+	req := request.(*vulnerabilityRemediationStatsRequest)
+	stats, err := svc.VulnerabilityRemediationStats(ctx, req.TeamID)
+	if err != nil {
+		return vulnerabilityRemediationStatsResponse{Err: err}, nil
+	}
+	return vulnerabilityRemediationStatsResponse{TeamID: req.TeamID, Stats: stats}, nil
+}
+
+func (svc *Service) VulnerabilityRemediationStats(ctx context.Context, teamID *uint) ([]*fleet.VulnerabilityRemediationStats, error) {
+	if err := svc.authz.Authorize(ctx, &fleet.AuthzSoftwareInventory{
+		TeamID: teamID,
+	}, fleet.ActionRead); err != nil {
+		return nil, err
+	}
+
+	sla, err := svc.vulnerabilityRemediationSLA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := svc.ds.VulnerabilityRemediationStats(ctx, teamID, sla, time.Now())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get vulnerability remediation stats")
+	}
+	return stats, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityRemediationsAuth(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{}, nil
+	}
+	ds.HostLiteFunc = func(ctx context.Context, id uint) (*fleet.Host, error) {
+		// host 2 belongs to team 1
+		if id == 2 {
+			return &fleet.Host{ID: id, TeamID: ptr.Uint(1)}, nil
+		}
+		return &fleet.Host{ID: id}, nil
+	}
+	ds.ListHostVulnerabilityRemediationsFunc = func(ctx context.Context, opts fleet.VulnerabilityRemediationListOptions, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, *fleet.PaginationMetadata, error) {
+		return []*fleet.HostVulnerabilityRemediation{}, nil, nil
+	}
+	ds.VulnerabilityRemediationStatsFunc = func(ctx context.Context, teamID *uint, sla fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.VulnerabilityRemediationStats, error) {
+		return nil, nil
+	}
+
+	testCases := []struct {
+		name             string
+		user             *fleet.User
+		shouldFailGlobal bool
+		shouldFailTeam   bool
+	}{
+		{"global admin", test.UserAdmin, false, false},
+		{"global maintainer", test.UserMaintainer, false, false},
+		{"global observer", test.UserObserver, false, false},
+		{"team admin", test.UserTeamAdminTeam1, true, false},
+		{"team observer", test.UserTeamObserverTeam1, true, false},
+		{"other team admin", test.UserTeamAdminTeam2, true, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := test.UserContext(ctx, tt.user)
+
+			_, _, err := svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{})
+			checkAuthErr(t, tt.shouldFailGlobal, err)
+			_, err = svc.VulnerabilityRemediationStats(ctx, nil)
+			checkAuthErr(t, tt.shouldFailGlobal, err)
+
+			_, _, err = svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{TeamID: ptr.Uint(1)})
+			checkAuthErr(t, tt.shouldFailTeam, err)
+			_, err = svc.VulnerabilityRemediationStats(ctx, ptr.Uint(1))
+			checkAuthErr(t, tt.shouldFailTeam, err)
+
+			// the remediations of a host are authorized with the team of the host
+			_, _, err = svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{HostID: ptr.Uint(1)})
+			checkAuthErr(t, tt.shouldFailGlobal, err)
+			_, _, err = svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{HostID: ptr.Uint(2)})
+			checkAuthErr(t, tt.shouldFailTeam, err)
+		})
+	}
+}
+
+func TestListHostVulnerabilityRemediations(t *testing.T) {
+	ds := new(mock.Store)
+	svc, ctx := newTestService(t, ds, nil, nil)
+	ctx = test.UserContext(ctx, test.UserAdmin)
+
+	sla := fleet.VulnerabilityRemediationSLA{CriticalDays: 7, HighDays: 30}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{VulnerabilitySettings: fleet.VulnerabilitySettings{RemediationSLA: sla}}, nil
+	}
+	ds.ListHostVulnerabilityRemediationsFunc = func(ctx context.Context, opts fleet.VulnerabilityRemediationListOptions, gotSLA fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.HostVulnerabilityRemediation, *fleet.PaginationMetadata, error) {
+		require.Equal(t, sla, gotSLA)
+		return []*fleet.HostVulnerabilityRemediation{{ID: 1, CVE: "CVE-2022-0001"}}, &fleet.PaginationMetadata{}, nil
+	}
+	ds.VulnerabilityRemediationStatsFunc = func(ctx context.Context, teamID *uint, gotSLA fleet.VulnerabilityRemediationSLA, now time.Time) ([]*fleet.VulnerabilityRemediationStats, error) {
+		require.Equal(t, sla, gotSLA)
+		return []*fleet.VulnerabilityRemediationStats{{Severity: fleet.CVESeverityCritical, SLADays: 7}}, nil
+	}
+
+	remediations, meta, err := svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{
+		Status:      fleet.VulnerabilityRemediationOpen,
+		Severity:    fleet.CVESeverityCritical,
+		SLABreached: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, remediations, 1)
+	require.NotNil(t, meta)
+	require.True(t, ds.ListHostVulnerabilityRemediationsFuncInvoked)
+
+	_, _, err = svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{Status: "fixed"})
+	require.ErrorContains(t, err, "status must be")
+	_, _, err = svc.ListHostVulnerabilityRemediations(ctx, fleet.VulnerabilityRemediationListOptions{Severity: "severe"})
+	require.ErrorContains(t, err, "invalid severity")
+
+	stats, err := svc.VulnerabilityRemediationStats(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+}
@@ -0,0 +1,65 @@
+// Package updaterollouts implements the automatic halt of the update rollouts
+// whose pinned hosts stop checking in.
+package updaterollouts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// HaltOffline halts the active update rollouts for which the pinned hosts
+// that are offline at the provided time exceed the halt threshold.
+func HaltOffline(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger, now time.Time) error {
+	rollouts, err := ds.ListUpdateRollouts(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list update rollouts")
+	}
+
+	for _, r := range rollouts {
+		if r.Status != fleet.UpdateRolloutStatusActive || r.HaltOfflinePercentage == 0 {
+			continue
+		}
+
+		offlineSince := now.Add(-time.Duration(r.OfflineMinutes) * time.Minute)
+		hosts, offline, err := ds.CountUpdateRolloutHosts(ctx, r.ID, offlineSince)
+		if err != nil {
+			return ctxerr.Wrapf(ctx, err, "count hosts of update rollout %d", r.ID)
+		}
+		if !r.ShouldHalt(hosts, offline) {
+			continue
+		}
+
+		level.Info(logger).Log(
+			"msg", "halting update rollout",
+			"rollout_id", r.ID,
+			"target", r.Target,
+			"channel", r.Channel,
+			"hosts", hosts,
+			"offline_hosts", offline,
+		)
+		r.Status = fleet.UpdateRolloutStatusHalted
+		haltedAt := now.UTC()
+		r.HaltedAt = &haltedAt
+		r.HaltReason = fmt.Sprintf("%d of %d pinned hosts did not check in for %d minutes", offline, hosts, r.OfflineMinutes)
+		if err := ds.SaveUpdateRollout(ctx, r); err != nil {
+			return ctxerr.Wrapf(ctx, err, "halt update rollout %d", r.ID)
+		}
+		if err := ds.NewActivity(ctx, nil, fleet.ActivityTypeHaltedUpdateRollout{
+			RolloutID:         r.ID,
+			RolloutName:       r.Name,
+			Target:            r.Target,
+			Channel:           r.Channel,
+			HostsCount:        hosts,
+			OfflineHostsCount: offline,
+		}); err != nil {
+			return ctxerr.Wrap(ctx, err, "create activity for halted update rollout")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package updaterollouts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHaltOffline(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	now := time.Date(2023, 3, 22, 0, 0, 0, 0, time.UTC)
+
+	// rollout 1 has 2 of 10 hosts offline with a 10% threshold, rollout 2 has
+	// 1 of 20 hosts offline, rollout 3 is already halted and rollout 4 has the
+	// automatic halt disabled.
+	rollouts := []*fleet.UpdateRollout{
+		{ID: 1, Name: "r1", Target: "osqueryd", Channel: "5.8.2", HaltOfflinePercentage: 10, OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusActive},
+		{ID: 2, Name: "r2", Target: "orbit", Channel: "1.8.0", HaltOfflinePercentage: 10, OfflineMinutes: 30, Status: fleet.UpdateRolloutStatusActive},
+		{ID: 3, Name: "r3", Target: "desktop", Channel: "1.8.0", HaltOfflinePercentage: 10, OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusHalted},
+		{ID: 4, Name: "r4", Target: "desktop", Channel: "1.9.0", HaltOfflinePercentage: 0, OfflineMinutes: 60, Status: fleet.UpdateRolloutStatusActive},
+	}
+	counts := map[uint][2]uint{1: {10, 2}, 2: {20, 1}, 3: {10, 10}, 4: {10, 10}}
+
+	ds.ListUpdateRolloutsFunc = func(ctx context.Context) ([]*fleet.UpdateRollout, error) {
+		return rollouts, nil
+	}
+	ds.CountUpdateRolloutHostsFunc = func(ctx context.Context, rolloutID uint, offlineSince time.Time) (uint, uint, error) {
+		for _, r := range rollouts {
+			if r.ID == rolloutID {
+				require.Equal(t, now.Add(-time.Duration(r.OfflineMinutes)*time.Minute), offlineSince)
+			}
+		}
+		return counts[rolloutID][0], counts[rolloutID][1], nil
+	}
+	var saved []*fleet.UpdateRollout
+	ds.SaveUpdateRolloutFunc = func(ctx context.Context, rollout *fleet.UpdateRollout) error {
+		saved = append(saved, rollout)
+		return nil
+	}
+	var activities []fleet.ActivityDetails
+	ds.NewActivityFunc = func(ctx context.Context, user *fleet.User, activity fleet.ActivityDetails) error {
+		require.Nil(t, user)
+		activities = append(activities, activity)
+		return nil
+	}
+
+	err := HaltOffline(ctx, ds, kitlog.NewNopLogger(), now)
+	require.NoError(t, err)
+	require.True(t, ds.CountUpdateRolloutHostsFuncInvoked)
+
+	require.Len(t, saved, 1)
+	require.Equal(t, uint(1), saved[0].ID)
+	require.Equal(t, fleet.UpdateRolloutStatusHalted, saved[0].Status)
+	require.Equal(t, now, *saved[0].HaltedAt)
+	require.Equal(t, "2 of 10 pinned hosts did not check in for 60 minutes", saved[0].HaltReason)
+
+	require.Equal(t, []fleet.ActivityDetails{fleet.ActivityTypeHaltedUpdateRollout{
+		RolloutID:         1,
+		RolloutName:       "r1",
+		Target:            "osqueryd",
+		Channel:           "5.8.2",
+		HostsCount:        10,
+		OfflineHostsCount: 2,
+	}}, activities)
+}
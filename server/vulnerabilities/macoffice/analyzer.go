@@ -78,25 +78,14 @@ func collectVulnerabilities(
 	return vulns
 }
 
-// getStoredVulnerabilities return all stored vulnerabilities for 'softwareID'
+// getStoredVulnerabilities return all stored vulnerabilities for 'softwareID', including the ones
+// suppressed by vulnerability exceptions
 func getStoredVulnerabilities(
 	ctx context.Context,
 	ds fleet.Datastore,
 	softwareID uint,
 ) ([]fleet.SoftwareVulnerability, error) {
-	storedSoftware, err := ds.SoftwareByID(ctx, softwareID, false)
-	if err != nil {
-		return nil, err
-	}
-
-	var result []fleet.SoftwareVulnerability
-	for _, v := range storedSoftware.Vulnerabilities {
-		result = append(result, fleet.SoftwareVulnerability{
-			SoftwareID: storedSoftware.ID,
-			CVE:        v.CVE,
-		})
-	}
-	return result, nil
+	return ds.ListSoftwareVulnerabilitiesBySoftwareID(ctx, softwareID)
 }
 
 func updateVulnsInDB(
@@ -78,7 +78,7 @@ func TestAnalyzer(t *testing.T) {
 	t.Run("getStoredVulnerabilities", func(t *testing.T) {
 		t.Run("on error", func(t *testing.T) {
 			ds := new(mock.Store)
-			ds.SoftwareByIDFunc = func(ctx context.Context, id uint, includeCVEScores bool) (*fleet.Software, error) {
+			ds.ListSoftwareVulnerabilitiesBySoftwareIDFunc = func(ctx context.Context, softwareID uint) ([]fleet.SoftwareVulnerability, error) {
 				return nil, errors.New("some error")
 			}
 
@@ -153,14 +153,42 @@ func cpeGeneralSearchQuery(software *fleet.Software) (string, []interface{}, err
 // and is optimized for lookups, see `GenerateCPEDB`. `translations` are used to aid in cpe matching. When searching for cpes, we first check if it matches
 // any translations, and then lookup in the cpe database based on the title, product and vendor.
 func CPEFromSoftware(db *sqlx.DB, software *fleet.Software, translations CPETranslations, reCache *regexpCache) (string, error) {
-	translation, match, err := translations.Translate(reCache, software)
+	res, err := resolveCPE(db, software, translations, reCache)
 	if err != nil {
-		return "", fmt.Errorf("translate software: %w", err)
+		return "", err
 	}
+	return res.CPE, nil
+}
+
+// resolveCPE is like CPEFromSoftware, but also explains how the CPE was
+// resolved.
+func resolveCPE(db *sqlx.DB, software *fleet.Software, translations CPETranslations, reCache *regexpCache) (*fleet.CPEResolution, error) {
+	item, err := translations.match(reCache, software)
+	if err != nil {
+		return nil, fmt.Errorf("translate software: %w", err)
+	}
+
+	if item != nil {
+		translation := item.Filter
+		res := &fleet.CPEResolution{
+			Method: fleet.CPEResolutionTranslation,
+			Filter: &fleet.CPETranslationRuleFilter{
+				Product:  translation.Product,
+				Vendor:   translation.Vendor,
+				TargetSW: translation.TargetSW,
+				Skip:     translation.Skip,
+			},
+		}
+		what := "a CPE translation from github.com/fleetdm/nvd"
+		if item.Rule != "" {
+			res.Method = fleet.CPEResolutionCustomTranslation
+			res.Rule = item.Rule
+			what = fmt.Sprintf("the custom CPE translation rule %q", item.Rule)
+		}
 
-	if match {
 		if translation.Skip {
-			return "", nil
+			res.Reason = fmt.Sprintf("The software matches %s that skips it, no NVD vulnerabilities are reported for it.", what)
+			return res, nil
 		}
 
 		ds := goqu.Dialect("sqlite").From(goqu.I("cpe_2").As("c")).
@@ -198,103 +226,138 @@ func CPEFromSoftware(db *sqlx.DB, software *fleet.Software, translations CPETran
 
 		var result IndexedCPEItem
 		err = db.Get(&result, stm, args...)
-		if err != nil {
-			return "", fmt.Errorf("getting CPE for: %s: %w", software.Name, err)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("getting CPE for: %s: %w", software.Name, err)
 		}
 
-		if result.ID != 0 {
-			return result.FmtStr(software), nil
-		}
-	} else {
-		stm, args, err := cpeGeneralSearchQuery(software)
-		if err != nil {
-			return "", fmt.Errorf("getting cpes for: %s: %w", software.Name, err)
+		if result.ID == 0 {
+			res.Reason = fmt.Sprintf("The software matches %s, but no entry of the CPE dictionary matches its filter.", what)
+			return res, nil
 		}
+		res.CPE = result.FmtStr(software)
+		res.Reason = fmt.Sprintf("The software matches %s, the CPE is the first entry of the CPE dictionary that matches its filter.", what)
+		return res, nil
+	}
 
-		var results []IndexedCPEItem
-		var match *IndexedCPEItem
+	res := &fleet.CPEResolution{
+		Method: fleet.CPEResolutionDictionarySearch,
+		Reason: "No CPE translation matches the software, and no entry of the CPE dictionary matches its name, vendor and bundle identifier.",
+	}
 
-		err = db.Select(&results, stm, args...)
-		if err == sql.ErrNoRows {
-			return "", nil
-		}
+	stm, args, err := cpeGeneralSearchQuery(software)
+	if err != nil {
+		return nil, fmt.Errorf("getting cpes for: %s: %w", software.Name, err)
+	}
 
-		if err != nil {
-			return "", fmt.Errorf("getting cpes for: %s: %w", software.Name, err)
-		}
+	var results []IndexedCPEItem
+	var match *IndexedCPEItem
 
-		for i, item := range results {
-			hasAllTerms := true
+	err = db.Select(&results, stm, args...)
+	if err == sql.ErrNoRows {
+		return res, nil
+	}
 
-			sName := strings.ToLower(software.Name)
-			for _, sN := range strings.Split(item.Product, "_") {
-				hasAllTerms = hasAllTerms && strings.Index(sName, sN) != -1
-			}
+	if err != nil {
+		return nil, fmt.Errorf("getting cpes for: %s: %w", software.Name, err)
+	}
 
-			sVendor := strings.ToLower(software.Vendor)
-			sBundle := strings.ToLower(software.BundleIdentifier)
-			for _, sV := range strings.Split(item.Vendor, "_") {
-				if sVendor != "" {
-					hasAllTerms = hasAllTerms && strings.Index(sVendor, sV) != -1
-				}
+	for i, item := range results {
+		hasAllTerms := true
 
-				if sBundle != "" {
-					hasAllTerms = hasAllTerms && strings.Index(sBundle, sV) != -1
-				}
+		sName := strings.ToLower(software.Name)
+		for _, sN := range strings.Split(item.Product, "_") {
+			hasAllTerms = hasAllTerms && strings.Index(sName, sN) != -1
+		}
+
+		sVendor := strings.ToLower(software.Vendor)
+		sBundle := strings.ToLower(software.BundleIdentifier)
+		for _, sV := range strings.Split(item.Vendor, "_") {
+			if sVendor != "" {
+				hasAllTerms = hasAllTerms && strings.Index(sVendor, sV) != -1
 			}
 
-			if hasAllTerms {
-				match = &results[i]
-				break
+			if sBundle != "" {
+				hasAllTerms = hasAllTerms && strings.Index(sBundle, sV) != -1
 			}
 		}
 
-		if match != nil {
-			if !match.Deprecated {
-				return match.FmtStr(software), nil
-			}
+		if hasAllTerms {
+			match = &results[i]
+			break
+		}
+	}
+
+	if match != nil {
+		if !match.Deprecated {
+			res.CPE = match.FmtStr(software)
+			res.Reason = fmt.Sprintf("No CPE translation matches the software, the CPE dictionary entry with product %q and vendor %q matches its name, vendor and bundle identifier.", match.Product, match.Vendor)
+			return res, nil
+		}
 
-			// try to find a non-deprecated cpe by looking up deprecated_by
-			for _, item := range results {
-				deprecatedItem := item
-				for {
-					var deprecation IndexedCPEItem
-
-					err = db.Get(
-						&deprecation,
-						`
-						SELECT
-							rowid,
-							product,
-							vendor,
-							deprecated
-						FROM
-							cpe_2
-						WHERE
-							cpe23 IN (
-								SELECT cpe23 FROM deprecated_by d WHERE d.cpe_id = ?
-							)
-					`,
-						deprecatedItem.ID,
-					)
-					if err == sql.ErrNoRows {
-						break
-					}
-					if err != nil {
-						return "", fmt.Errorf("getting deprecation: %w", err)
-					}
-					if deprecation.Deprecated {
-						deprecatedItem = deprecation
-						continue
-					}
-
-					return deprecation.FmtStr(software), nil
+		// try to find a non-deprecated cpe by looking up deprecated_by
+		for _, item := range results {
+			deprecatedItem := item
+			for {
+				var deprecation IndexedCPEItem
+
+				err = db.Get(
+					&deprecation,
+					`
+					SELECT
+						rowid,
+						product,
+						vendor,
+						deprecated
+					FROM
+						cpe_2
+					WHERE
+						cpe23 IN (
+							SELECT cpe23 FROM deprecated_by d WHERE d.cpe_id = ?
+						)
+				`,
+					deprecatedItem.ID,
+				)
+				if err == sql.ErrNoRows {
+					break
+				}
+				if err != nil {
+					return nil, fmt.Errorf("getting deprecation: %w", err)
 				}
+				if deprecation.Deprecated {
+					deprecatedItem = deprecation
+					continue
+				}
+
+				res.CPE = deprecation.FmtStr(software)
+				res.Reason = fmt.Sprintf("No CPE translation matches the software, the CPE dictionary entry with product %q and vendor %q matches its name, vendor and bundle identifier, but is deprecated by the entry with product %q and vendor %q.", match.Product, match.Vendor, deprecation.Product, deprecation.Vendor)
+				return res, nil
 			}
 		}
 	}
 
-	return "", nil
+	return res, nil
+}
+
+// ResolveCPE explains which CPE the software resolves to with the CPE
+// database and translations of vulnPath and the custom CPE translation rules,
+// without storing it.
+func ResolveCPE(vulnPath string, software *fleet.Software, rules []*fleet.CPETranslationRuleSpec) (*fleet.CPEResolution, error) {
+	dbPath := filepath.Join(vulnPath, cpeDBFilename)
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("cpe database: %w", err)
+	}
+	db, err := sqliteDB(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening the cpe db: %w", err)
+	}
+	defer db.Close()
+
+	upstream, err := loadCPETranslations(filepath.Join(vulnPath, cpeTranslationsFilename))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loading cpe translations: %w", err)
+	}
+
+	return resolveCPE(db, software, mergeCPETranslations(CustomCPETranslations(rules), upstream), newRegexpCache())
 }
 
 func TranslateSoftwareToCPE(
@@ -305,13 +368,6 @@ func TranslateSoftwareToCPE(
 ) error {
 	dbPath := filepath.Join(vulnPath, cpeDBFilename)
 
-	// Skip software from sources for which we will be using OVAL for vulnerability detection.
-	iterator, err := ds.AllSoftwareWithoutCPEIterator(ctx, oval.SupportedSoftwareSources)
-	if err != nil {
-		return ctxerr.Wrap(ctx, err, "all software iterator")
-	}
-	defer iterator.Close()
-
 	db, err := sqliteDB(dbPath)
 	if err != nil {
 		return ctxerr.Wrap(ctx, err, "opening the cpe db")
@@ -324,14 +380,37 @@ func TranslateSoftwareToCPE(
 		level.Error(logger).Log("msg", "failed to load cpe translations", "err", err)
 	}
 
+	rules, err := ds.GetCPETranslationRuleSpecs(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get cpe translation rules")
+	}
+	customTranslations := CustomCPETranslations(rules)
+
 	reCache := newRegexpCache()
 
+	// Software only gets translated once, so the CPEs of the software that
+	// matches the custom rules that changed since the last run are cleared to
+	// translate it again.
+	customTranslationsPath := filepath.Join(vulnPath, cpeCustomTranslationsFilename)
+	if err := clearCustomTranslatedCPEs(ctx, ds, customTranslationsPath, customTranslations, reCache); err != nil {
+		return err
+	}
+
+	// Skip software from sources for which we will be using OVAL for vulnerability detection.
+	iterator, err := ds.AllSoftwareWithoutCPEIterator(ctx, oval.SupportedSoftwareSources)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "all software iterator")
+	}
+	defer iterator.Close()
+
+	translations := mergeCPETranslations(customTranslations, cpeTranslations)
+
 	for iterator.Next() {
 		software, err := iterator.Value()
 		if err != nil {
 			return ctxerr.Wrap(ctx, err, "getting value from iterator")
 		}
-		cpe, err := CPEFromSoftware(db, software, cpeTranslations, reCache)
+		cpe, err := CPEFromSoftware(db, software, translations, reCache)
 		if err != nil {
 			level.Error(logger).Log("software->cpe", "error translating to CPE, skipping...", "err", err)
 			continue
@@ -345,5 +424,9 @@ func TranslateSoftwareToCPE(
 		}
 	}
 
+	if err := saveCustomCPETranslations(customTranslationsPath, rules); err != nil {
+		return ctxerr.Wrap(ctx, err, "save custom cpe translations")
+	}
+
 	return nil
 }
@@ -131,6 +131,36 @@ func TestCPETranslations(t *testing.T) {
 			},
 			Expected: "cpe:2.3:a:vendor:product-1:1.2.3:*:*:*:*:macos:*:*",
 		},
+		{
+			Name: "custom rule takes precedence",
+			Translations: mergeCPETranslations(
+				CustomCPETranslations([]*fleet.CPETranslationRuleSpec{
+					{
+						Name:     "custom",
+						Software: fleet.CPETranslationRuleSoftware{Name: []string{"X"}},
+						Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+					},
+				}),
+				CPETranslations{
+					{
+						Software: CPETranslationSoftware{
+							Name:   []string{"X"},
+							Source: []string{"apps"},
+						},
+						Filter: CPETranslation{
+							Product: []string{"product-1"},
+							Vendor:  []string{"vendor"},
+						},
+					},
+				},
+			),
+			Software: &fleet.Software{
+				Name:    "X",
+				Version: "1.2.3",
+				Source:  "apps",
+			},
+			Expected: "",
+		},
 	}
 
 	reCache := newRegexpCache()
@@ -273,6 +303,9 @@ func TestTranslateSoftwareToCPE(t *testing.T) {
 	ds.AllSoftwareWithoutCPEIteratorFunc = func(ctx context.Context, excludedPlatforms []string) (fleet.SoftwareIterator, error) {
 		return iterator, nil
 	}
+	ds.GetCPETranslationRuleSpecsFunc = func(ctx context.Context) ([]*fleet.CPETranslationRuleSpec, error) {
+		return nil, nil
+	}
 
 	items, err := cpedict.Decode(strings.NewReader(XmlCPETestDict))
 	require.NoError(t, err)
@@ -1,20 +1,29 @@
 package nvd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 
 	"github.com/fleetdm/fleet/v4/pkg/download"
 	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
 	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/oval"
 )
 
-const cpeTranslationsFilename = "cpe_translations.json"
+const (
+	cpeTranslationsFilename = "cpe_translations.json"
+	// cpeCustomTranslationsFilename holds the custom CPE translation rules
+	// used in the last run, to find the rules that changed since.
+	cpeCustomTranslationsFilename = "cpe_custom_translations.json"
+)
 
 func loadCPETranslations(path string) (CPETranslations, error) {
 	f, err := os.Open(path)
@@ -118,22 +127,160 @@ func (r *regexpCache) Get(pattern string) (*regexp.Regexp, error) {
 type CPETranslations []CPETranslationItem
 
 func (c CPETranslations) Translate(reCache *regexpCache, s *fleet.Software) (CPETranslation, bool, error) {
-	for _, item := range c {
+	item, err := c.match(reCache, s)
+	if err != nil || item == nil {
+		return CPETranslation{}, false, err
+	}
+	return item.Filter, true, nil
+}
+
+// match returns the first translation item that matches the software, nil if
+// none does.
+func (c CPETranslations) match(reCache *regexpCache, s *fleet.Software) (*CPETranslationItem, error) {
+	for i, item := range c {
 		match, err := item.Software.Matches(reCache, s)
 		if err != nil {
-			return CPETranslation{}, false, err
+			return nil, err
 		}
 		if match {
-			return item.Filter, true, nil
+			return &c[i], nil
 		}
 	}
 
-	return CPETranslation{}, false, nil
+	return nil, nil
 }
 
 type CPETranslationItem struct {
 	Software CPETranslationSoftware `json:"software"`
 	Filter   CPETranslation         `json:"filter"`
+
+	// Rule is the name of the custom CPE translation rule the item comes
+	// from, empty for the translations downloaded from github.com/fleetdm/nvd.
+	Rule string `json:"-"`
+}
+
+// CustomCPETranslations converts the custom CPE translation rules to
+// translation items.
+func CustomCPETranslations(rules []*fleet.CPETranslationRuleSpec) CPETranslations {
+	translations := make(CPETranslations, 0, len(rules))
+	for _, r := range rules {
+		translations = append(translations, CPETranslationItem{
+			Software: CPETranslationSoftware{
+				Name:             r.Software.Name,
+				BundleIdentifier: r.Software.BundleIdentifier,
+				Source:           r.Software.Source,
+			},
+			Filter: CPETranslation{
+				Product:  r.Filter.Product,
+				Vendor:   r.Filter.Vendor,
+				TargetSW: r.Filter.TargetSW,
+				Skip:     r.Filter.Skip,
+			},
+			Rule: r.Name,
+		})
+	}
+	return translations
+}
+
+// mergeCPETranslations returns the custom translations followed by the
+// upstream ones, so that custom rules take precedence.
+func mergeCPETranslations(custom, upstream CPETranslations) CPETranslations {
+	merged := make(CPETranslations, 0, len(custom)+len(upstream))
+	merged = append(merged, custom...)
+	return append(merged, upstream...)
+}
+
+func loadCustomCPETranslations(path string) (CPETranslations, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return CPETranslations{}, nil
+		}
+		return nil, err
+	}
+
+	var rules []*fleet.CPETranslationRuleSpec
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return CustomCPETranslations(rules), nil
+}
+
+func saveCustomCPETranslations(path string, rules []*fleet.CPETranslationRuleSpec) error {
+	b, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// changedCPETranslations returns the translations that are only in one of the
+// two lists.
+func changedCPETranslations(previous, current CPETranslations) CPETranslations {
+	contains := func(list CPETranslations, item CPETranslationItem) bool {
+		for _, it := range list {
+			if reflect.DeepEqual(it, item) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var changed CPETranslations
+	for _, item := range previous {
+		if !contains(current, item) {
+			changed = append(changed, item)
+		}
+	}
+	for _, item := range current {
+		if !contains(previous, item) {
+			changed = append(changed, item)
+		}
+	}
+	return changed
+}
+
+// clearCustomTranslatedCPEs clears the CPEs and NVD vulnerabilities of the
+// software that matches the custom translations that changed since the ones
+// saved at path.
+func clearCustomTranslatedCPEs(ctx context.Context, ds fleet.Datastore, path string, current CPETranslations, reCache *regexpCache) error {
+	previous, err := loadCustomCPETranslations(path)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "load custom cpe translations")
+	}
+	changed := changedCPETranslations(previous, current)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	iterator, err := ds.AllSoftwareWithCPEIterator(ctx, oval.SupportedSoftwareSources)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "software with cpe iterator")
+	}
+	defer iterator.Close()
+
+	var softwareIDs []uint
+	for iterator.Next() {
+		software, err := iterator.Value()
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "getting value from iterator")
+		}
+		item, err := changed.match(reCache, software)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "match changed cpe translations")
+		}
+		if item != nil {
+			softwareIDs = append(softwareIDs, software.ID)
+		}
+	}
+	if err := iterator.Err(); err != nil {
+		return ctxerr.Wrap(ctx, err, "iterate software with cpe")
+	}
+
+	if err := ds.DeleteSoftwareCPEs(ctx, softwareIDs); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete software cpes")
+	}
+	return nil
 }
 
 // CPETranslationSoftware represents software match criteria for cpe translations.
@@ -147,8 +294,7 @@ type CPETranslationSoftware struct {
 func (c CPETranslationSoftware) Matches(reCache *regexpCache, s *fleet.Software) (bool, error) {
 	matches := func(a, b string) (bool, error) {
 		// check if its a regular expression enclosed in '/'
-		if len(a) > 2 && a[0] == '/' && a[len(a)-1] == '/' {
-			pattern := a[1 : len(a)-1]
+		if pattern, ok := fleet.CPETranslationPattern(a); ok {
 			re, err := reCache.Get(pattern)
 			if err != nil {
 				return false, err
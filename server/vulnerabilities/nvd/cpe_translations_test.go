@@ -0,0 +1,172 @@
+package nvd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomCPETranslations(t *testing.T) {
+	rules := []*fleet.CPETranslationRuleSpec{
+		{
+			Name:     "acme-vpn",
+			Software: fleet.CPETranslationRuleSoftware{Name: []string{"/^Acme VPN/"}, Source: []string{"apps"}},
+			Filter:   fleet.CPETranslationRuleFilter{Product: []string{"vpn_client"}, Vendor: []string{"acme"}},
+		},
+		{
+			Name:     "acme-tools",
+			Software: fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"com.acme.tools"}},
+			Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+		},
+	}
+	upstream := CPETranslations{
+		{
+			Software: CPETranslationSoftware{Name: []string{"Acme VPN.app"}},
+			Filter:   CPETranslation{Product: []string{"vpn"}, Vendor: []string{"acme_corp"}},
+		},
+		{
+			Software: CPETranslationSoftware{Name: []string{"Other.app"}},
+			Filter:   CPETranslation{Product: []string{"other"}},
+		},
+	}
+	translations := mergeCPETranslations(CustomCPETranslations(rules), upstream)
+	require.Len(t, translations, 4)
+
+	reCache := newRegexpCache()
+	for _, tc := range []struct {
+		software *fleet.Software
+		rule     string
+		filter   CPETranslation
+		match    bool
+	}{
+		{
+			software: &fleet.Software{Name: "Acme VPN.app", Source: "apps"},
+			rule:     "acme-vpn",
+			filter:   CPETranslation{Product: []string{"vpn_client"}, Vendor: []string{"acme"}},
+			match:    true,
+		},
+		{
+			// the custom rule only matches apps
+			software: &fleet.Software{Name: "Acme VPN.app", Source: "programs"},
+			filter:   CPETranslation{Product: []string{"vpn"}, Vendor: []string{"acme_corp"}},
+			match:    true,
+		},
+		{
+			software: &fleet.Software{Name: "Tools.app", BundleIdentifier: "com.acme.tools", Source: "apps"},
+			rule:     "acme-tools",
+			filter:   CPETranslation{Skip: true},
+			match:    true,
+		},
+		{
+			software: &fleet.Software{Name: "Other.app", Source: "apps"},
+			filter:   CPETranslation{Product: []string{"other"}},
+			match:    true,
+		},
+		{
+			software: &fleet.Software{Name: "Unknown.app", Source: "apps"},
+		},
+	} {
+		item, err := translations.match(reCache, tc.software)
+		require.NoError(t, err)
+		if !tc.match {
+			require.Nil(t, item)
+			continue
+		}
+		require.NotNil(t, item)
+		require.Equal(t, tc.rule, item.Rule)
+		require.Equal(t, tc.filter, item.Filter)
+
+		filter, ok, err := translations.Translate(reCache, tc.software)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, tc.filter, filter)
+	}
+}
+
+func TestResolveCPESkip(t *testing.T) {
+	translations := CustomCPETranslations([]*fleet.CPETranslationRuleSpec{{
+		Name:     "acme-tools",
+		Software: fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"com.acme.tools"}},
+		Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+	}})
+
+	// skipping does not query the CPE database
+	res, err := resolveCPE(nil, &fleet.Software{Name: "Tools.app", BundleIdentifier: "com.acme.tools"}, translations, newRegexpCache())
+	require.NoError(t, err)
+	require.Empty(t, res.CPE)
+	require.Equal(t, fleet.CPEResolutionCustomTranslation, res.Method)
+	require.Equal(t, "acme-tools", res.Rule)
+	require.Equal(t, &fleet.CPETranslationRuleFilter{Skip: true}, res.Filter)
+	require.Contains(t, res.Reason, `custom CPE translation rule "acme-tools" that skips it`)
+
+	// the CPE database is required
+	_, err = ResolveCPE(t.TempDir(), &fleet.Software{Name: "Tools.app"}, nil)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestChangedCPETranslations(t *testing.T) {
+	a := CPETranslationItem{Software: CPETranslationSoftware{Name: []string{"A"}}, Filter: CPETranslation{Skip: true}, Rule: "a"}
+	b := CPETranslationItem{Software: CPETranslationSoftware{Name: []string{"B"}}, Filter: CPETranslation{Skip: true}, Rule: "b"}
+	b2 := CPETranslationItem{Software: CPETranslationSoftware{Name: []string{"B"}}, Filter: CPETranslation{Vendor: []string{"b"}}, Rule: "b"}
+	c := CPETranslationItem{Software: CPETranslationSoftware{Name: []string{"C"}}, Filter: CPETranslation{Skip: true}, Rule: "c"}
+
+	require.Empty(t, changedCPETranslations(CPETranslations{}, CPETranslations{}))
+	require.Empty(t, changedCPETranslations(CPETranslations{a, b}, CPETranslations{a, b}))
+	require.Equal(t, CPETranslations{a}, changedCPETranslations(CPETranslations{}, CPETranslations{a}))
+	require.Equal(t, CPETranslations{b, b2, c}, changedCPETranslations(CPETranslations{a, b}, CPETranslations{a, b2, c}))
+}
+
+func TestClearCustomTranslatedCPEs(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	path := filepath.Join(t.TempDir(), cpeCustomTranslationsFilename)
+
+	software := []*fleet.Software{
+		{ID: 1, Name: "Acme VPN.app", Source: "apps"},
+		{ID: 2, Name: "Tools.app", BundleIdentifier: "com.acme.tools", Source: "apps"},
+		{ID: 3, Name: "Other.app", Source: "apps"},
+	}
+	ds.AllSoftwareWithCPEIteratorFunc = func(ctx context.Context, excludedSources []string) (fleet.SoftwareIterator, error) {
+		return &fakeSoftwareIterator{softwares: software}, nil
+	}
+	var deleted []uint
+	ds.DeleteSoftwareCPEsFunc = func(ctx context.Context, softwareIDs []uint) error {
+		deleted = softwareIDs
+		return nil
+	}
+
+	vpn := &fleet.CPETranslationRuleSpec{
+		Name:     "acme-vpn",
+		Software: fleet.CPETranslationRuleSoftware{Name: []string{"/^Acme VPN/"}},
+		Filter:   fleet.CPETranslationRuleFilter{Product: []string{"vpn_client"}, Vendor: []string{"acme"}},
+	}
+	tools := &fleet.CPETranslationRuleSpec{
+		Name:     "acme-tools",
+		Software: fleet.CPETranslationRuleSoftware{BundleIdentifier: []string{"com.acme.tools"}},
+		Filter:   fleet.CPETranslationRuleFilter{Skip: true},
+	}
+	reCache := newRegexpCache()
+
+	// no rules, nothing changed
+	require.NoError(t, clearCustomTranslatedCPEs(ctx, ds, path, CustomCPETranslations(nil), reCache))
+	require.False(t, ds.AllSoftwareWithCPEIteratorFuncInvoked)
+
+	// new rules clear the CPEs of the matching software
+	require.NoError(t, clearCustomTranslatedCPEs(ctx, ds, path, CustomCPETranslations([]*fleet.CPETranslationRuleSpec{vpn, tools}), reCache))
+	require.Equal(t, []uint{1, 2}, deleted)
+	require.NoError(t, saveCustomCPETranslations(path, []*fleet.CPETranslationRuleSpec{vpn, tools}))
+
+	// unchanged rules
+	ds.AllSoftwareWithCPEIteratorFuncInvoked = false
+	require.NoError(t, clearCustomTranslatedCPEs(ctx, ds, path, CustomCPETranslations([]*fleet.CPETranslationRuleSpec{vpn, tools}), reCache))
+	require.False(t, ds.AllSoftwareWithCPEIteratorFuncInvoked)
+
+	// a deleted rule clears the CPEs of the software it matched
+	require.NoError(t, clearCustomTranslatedCPEs(ctx, ds, path, CustomCPETranslations([]*fleet.CPETranslationRuleSpec{vpn}), reCache))
+	require.Equal(t, []uint{2}, deleted)
+}
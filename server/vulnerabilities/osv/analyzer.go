@@ -0,0 +1,185 @@
+package osv
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/utils"
+)
+
+const vulnBatchSize = 500
+
+// scanDump evaluates all the entries contained in the OSV dump located at 'path' against the
+// installed packages of the 'eco' ecosystem ('installed' is keyed by normalized package name),
+// returning all vulnerabilities found.
+func scanDump(path string, eco Ecosystem, installed map[string][]fleet.Software) ([]fleet.SoftwareVulnerability, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening osv dump: %w", err)
+	}
+	defer zr.Close()
+
+	var vulns []fleet.SoftwareVulnerability
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+
+		entry, err := readEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		vulns = append(vulns, evalEntry(entry, eco, installed)...)
+	}
+	return vulns, nil
+}
+
+func readEntry(f *zip.File) (Entry, error) {
+	var entry Entry
+
+	r, err := f.Open()
+	if err != nil {
+		return entry, fmt.Errorf("opening %s: %w", f.Name, err)
+	}
+	defer r.Close()
+
+	if err := json.NewDecoder(r).Decode(&entry); err != nil {
+		return entry, fmt.Errorf("decoding %s: %w", f.Name, err)
+	}
+	return entry, nil
+}
+
+// evalEntry returns the vulnerabilities of 'entry' affecting any of the 'installed' packages.
+func evalEntry(entry Entry, eco Ecosystem, installed map[string][]fleet.Software) []fleet.SoftwareVulnerability {
+	if entry.Withdrawn != "" {
+		return nil
+	}
+	cves := entry.CVEs()
+	if len(cves) == 0 {
+		return nil
+	}
+
+	var vulns []fleet.SoftwareVulnerability
+	for _, affected := range entry.Affected {
+		if affected.Package.Ecosystem != eco.Name {
+			continue
+		}
+		for _, software := range installed[eco.NormalizeName(affected.Package.Name)] {
+			if !affected.Affects(software.Version, eco) {
+				continue
+			}
+			for _, cve := range cves {
+				vulns = append(vulns, fleet.SoftwareVulnerability{
+					SoftwareID: software.ID,
+					CVE:        cve,
+				})
+			}
+		}
+	}
+	return vulns
+}
+
+// Analyze uses the OSV dumps contained in 'vulnPath' for detecting vulnerabilities on the software
+// of the supported ecosystems, inserting any new vulnerabilities and deleting anything patched.
+// Ecosystems without a dump are skipped and their stored vulnerabilities are kept.
+func Analyze(
+	ctx context.Context,
+	ds fleet.Datastore,
+	vulnPath string,
+	collectVulns bool,
+) ([]fleet.SoftwareVulnerability, error) {
+	installed, err := installedEcosystems(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzed := make(map[uint]bool)
+	toInsertSet := make(map[string]fleet.SoftwareVulnerability)
+	for _, eco := range EcosystemsBySource {
+		pkgs, ok := installed[eco.Name]
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(vulnPath, DumpFilename(eco.Name))
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		found, err := scanDump(path, eco, pkgs)
+		if err != nil {
+			return nil, fmt.Errorf("scanning %s: %w", eco.Name, err)
+		}
+		for _, v := range found {
+			toInsertSet[v.Key()] = v
+		}
+		for _, software := range pkgs {
+			for _, s := range software {
+				analyzed[s.ID] = true
+			}
+		}
+	}
+
+	// Nothing was analyzed, so there is nothing to update
+	if len(analyzed) == 0 {
+		return nil, nil
+	}
+
+	stored, err := ds.ListSoftwareVulnerabilitiesBySource(ctx, fleet.OSVSource)
+	if err != nil {
+		return nil, err
+	}
+	var existing []fleet.SoftwareVulnerability
+	for _, v := range stored {
+		if analyzed[v.SoftwareID] {
+			existing = append(existing, v)
+		}
+	}
+
+	detected := make([]fleet.SoftwareVulnerability, 0, len(toInsertSet))
+	for _, v := range toInsertSet {
+		detected = append(detected, v)
+	}
+	toInsert, toDelete := utils.VulnsDelta(detected, existing)
+
+	err = utils.BatchProcess(sliceToSet(toDelete), func(v []fleet.SoftwareVulnerability) error {
+		return ds.DeleteSoftwareVulnerabilities(ctx, v)
+	}, vulnBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var inserted []fleet.SoftwareVulnerability
+	err = utils.BatchProcess(sliceToSet(toInsert), func(v []fleet.SoftwareVulnerability) error {
+		n, err := ds.InsertSoftwareVulnerabilities(ctx, v, fleet.OSVSource)
+		if err != nil {
+			return err
+		}
+
+		if collectVulns && n > 0 {
+			inserted = append(inserted, v...)
+		}
+		return nil
+	}, vulnBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return inserted, nil
+}
+
+func sliceToSet(vulns []fleet.SoftwareVulnerability) map[string]fleet.SoftwareVulnerability {
+	r := make(map[string]fleet.SoftwareVulnerability, len(vulns))
+	for _, v := range vulns {
+		r[v.Key()] = v
+	}
+	return r
+}
@@ -0,0 +1,163 @@
+package osv
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSoftwareIterator struct {
+	index     int
+	softwares []*fleet.Software
+}
+
+func (f *fakeSoftwareIterator) Next() bool {
+	return f.index < len(f.softwares)
+}
+
+func (f *fakeSoftwareIterator) Value() (*fleet.Software, error) {
+	s := f.softwares[f.index]
+	f.index++
+	return s, nil
+}
+
+func (f *fakeSoftwareIterator) Err() error   { return nil }
+func (f *fakeSoftwareIterator) Close() error { return nil }
+
+func writeDump(t *testing.T, path string, entries []Entry) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		w, err := zw.Create(e.ID + ".json")
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(e))
+	}
+	require.NoError(t, zw.Close())
+}
+
+func TestAnalyze(t *testing.T) {
+	ctx := context.Background()
+
+	software := []*fleet.Software{
+		{ID: 1, Name: "Twisted", Version: "22.2.0", Source: "python_packages"},
+		{ID: 2, Name: "requests", Version: "2.31.0", Source: "python_packages"},
+		{ID: 3, Name: "lodash", Version: "4.17.15", Source: "npm_packages"},
+	}
+
+	pypiEntries := []Entry{
+		{
+			ID:      "PYSEC-2022-301",
+			Aliases: []string{"CVE-2022-39348", "GHSA-vg46-2rrj-3647"},
+			Affected: []Affected{{
+				Package: Package{Ecosystem: "PyPI", Name: "twisted"},
+				Ranges:  []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0.9.4"}, {Fixed: "22.10.0rc1"}}}},
+			}},
+		},
+		{
+			ID:      "PYSEC-2023-74",
+			Aliases: []string{"CVE-2023-32681"},
+			Affected: []Affected{{
+				Package: Package{Ecosystem: "PyPI", Name: "requests"},
+				Ranges:  []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "2.3.0"}, {Fixed: "2.31.0"}}}},
+			}},
+		},
+		{
+			// Withdrawn entries are ignored
+			ID:        "PYSEC-2022-1",
+			Aliases:   []string{"CVE-2022-1111"},
+			Withdrawn: "2022-10-10T00:00:00Z",
+			Affected: []Affected{{
+				Package: Package{Ecosystem: "PyPI", Name: "twisted"},
+				Ranges:  []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0"}}}},
+			}},
+		},
+		{
+			// Entries without CVEs are ignored
+			ID: "GHSA-xxxx-yyyy-zzzz",
+			Affected: []Affected{{
+				Package: Package{Ecosystem: "PyPI", Name: "twisted"},
+				Ranges:  []Range{{Type: "ECOSYSTEM", Events: []Event{{Introduced: "0"}}}},
+			}},
+		},
+	}
+
+	newStore := func(stored []fleet.SoftwareVulnerability) *mock.Store {
+		ds := new(mock.Store)
+		ds.ListSoftwareBySourceIterFunc = func(ctx context.Context, sources []string) (fleet.SoftwareIterator, error) {
+			require.ElementsMatch(t, SupportedSoftwareSources(), sources)
+			return &fakeSoftwareIterator{softwares: software}, nil
+		}
+		ds.ListSoftwareVulnerabilitiesBySourceFunc = func(ctx context.Context, source fleet.VulnerabilitySource) ([]fleet.SoftwareVulnerability, error) {
+			require.Equal(t, fleet.OSVSource, source)
+			return stored, nil
+		}
+		ds.DeleteSoftwareVulnerabilitiesFunc = func(ctx context.Context, vulns []fleet.SoftwareVulnerability) error {
+			return nil
+		}
+		ds.InsertSoftwareVulnerabilitiesFunc = func(ctx context.Context, vulns []fleet.SoftwareVulnerability, source fleet.VulnerabilitySource) (int64, error) {
+			require.Equal(t, fleet.OSVSource, source)
+			return int64(len(vulns)), nil
+		}
+		return ds
+	}
+
+	t.Run("no dumps", func(t *testing.T) {
+		ds := newStore(nil)
+		vulns, err := Analyze(ctx, ds, t.TempDir(), true)
+		require.NoError(t, err)
+		require.Empty(t, vulns)
+		require.False(t, ds.ListSoftwareVulnerabilitiesBySourceFuncInvoked)
+		require.False(t, ds.InsertSoftwareVulnerabilitiesFuncInvoked)
+	})
+
+	t.Run("detects, inserts and deletes vulnerabilities", func(t *testing.T) {
+		vulnPath := t.TempDir()
+		writeDump(t, filepath.Join(vulnPath, DumpFilename("PyPI")), pypiEntries)
+
+		ds := newStore([]fleet.SoftwareVulnerability{
+			// patched
+			{SoftwareID: 2, CVE: "CVE-2023-32681"},
+			// no npm dump, so npm vulnerabilities are kept
+			{SoftwareID: 3, CVE: "CVE-2020-8203"},
+		})
+		var deleted []fleet.SoftwareVulnerability
+		ds.DeleteSoftwareVulnerabilitiesFunc = func(ctx context.Context, vulns []fleet.SoftwareVulnerability) error {
+			deleted = append(deleted, vulns...)
+			return nil
+		}
+
+		vulns, err := Analyze(ctx, ds, vulnPath, true)
+		require.NoError(t, err)
+		require.Equal(t, []fleet.SoftwareVulnerability{{SoftwareID: 1, CVE: "CVE-2022-39348"}}, vulns)
+		require.Equal(t, []fleet.SoftwareVulnerability{{SoftwareID: 2, CVE: "CVE-2023-32681"}}, deleted)
+	})
+
+	t.Run("does not collect vulnerabilities", func(t *testing.T) {
+		vulnPath := t.TempDir()
+		writeDump(t, filepath.Join(vulnPath, DumpFilename("PyPI")), pypiEntries)
+
+		ds := newStore(nil)
+		vulns, err := Analyze(ctx, ds, vulnPath, false)
+		require.NoError(t, err)
+		require.Empty(t, vulns)
+		require.True(t, ds.InsertSoftwareVulnerabilitiesFuncInvoked)
+	})
+
+	t.Run("invalid dump", func(t *testing.T) {
+		vulnPath := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(vulnPath, DumpFilename("PyPI")), []byte("not a zip"), 0o644))
+
+		_, err := Analyze(ctx, newStore(nil), vulnPath, false)
+		require.ErrorContains(t, err, "opening osv dump")
+	})
+}
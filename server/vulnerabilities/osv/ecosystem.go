@@ -0,0 +1,259 @@
+package osv
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// Ecosystem is an OSV ecosystem along with its naming and versioning rules.
+// see https://ossf.github.io/osv-schema/#affectedpackage-field
+type Ecosystem struct {
+	// Name is the name of the ecosystem as used in OSV entries.
+	Name string
+	// normalize is used for normalizing package names, so that the names of installed software
+	// can be compared with the names used in OSV entries.
+	normalize func(string) string
+	// compare compares two versions, returns -1, 0 or 1.
+	compare func(a, b string) (int, error)
+}
+
+// NormalizeName normalizes the package name 'name' according to the ecosystem rules.
+func (e Ecosystem) NormalizeName(name string) string {
+	if e.normalize == nil {
+		return name
+	}
+	return e.normalize(name)
+}
+
+// EcosystemsBySource maps a software source to its OSV ecosystem. osquery does not include tables
+// for gems, Go modules or crates, software with those sources must be reported using an osquery
+// extension.
+var EcosystemsBySource = map[string]Ecosystem{
+	"python_packages": {Name: "PyPI", normalize: normalizePyPIName, compare: comparePEP440},
+	"npm_packages":    {Name: "npm", compare: compareSemver},
+	"gem_packages":    {Name: "RubyGems", compare: compareRubyGems},
+	"go_packages":     {Name: "Go", compare: compareSemver},
+	"cargo_packages":  {Name: "crates.io", compare: compareSemver},
+}
+
+// SupportedSoftwareSources are the software sources for which we are using OSV for vulnerability
+// detection.
+func SupportedSoftwareSources() []string {
+	r := make([]string, 0, len(EcosystemsBySource))
+	for source := range EcosystemsBySource {
+		r = append(r, source)
+	}
+	return r
+}
+
+// -----------------
+// Semver (npm, Go, crates.io)
+// -----------------
+
+func compareSemver(a, b string) (int, error) {
+	va, err := semver.NewVersion(a)
+	if err != nil {
+		return 0, fmt.Errorf("parsing version %q: %w", a, err)
+	}
+	vb, err := semver.NewVersion(b)
+	if err != nil {
+		return 0, fmt.Errorf("parsing version %q: %w", b, err)
+	}
+	return va.Compare(vb), nil
+}
+
+// -----------------
+// PyPI
+// -----------------
+
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName normalizes a python package name as described in
+// https://peps.python.org/pep-0503/#normalized-names
+func normalizePyPIName(name string) string {
+	return strings.ToLower(pypiNameSeparators.ReplaceAllString(name, "-"))
+}
+
+// pep440Pattern is based on the regular expression included in
+// https://peps.python.org/pep-0440/#appendix-b-parsing-version-strings-with-regular-expressions
+var pep440Pattern = regexp.MustCompile(`(?i)^\s*v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(?P<pre_l>a|b|c|rc|alpha|beta|pre|preview)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?:-(?P<post_n1>[0-9]+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?)?` +
+	`(?:[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+[a-z0-9]+(?:[-_.][a-z0-9]+)*)?\s*$`)
+
+// pep440Version holds the comparable parts of a PEP 440 version, the local version label is
+// ignored.
+type pep440Version struct {
+	epoch   int
+	release []int
+	// pre is (phase, number), phase is -1 for dev only releases (which sort before
+	// pre-releases) and math.MaxInt32 for final releases.
+	pre  [2]int
+	post int
+	dev  int
+}
+
+func parsePEP440(v string) (*pep440Version, error) {
+	m := pep440Pattern.FindStringSubmatch(v)
+	if m == nil {
+		return nil, fmt.Errorf("invalid PEP 440 version %q", v)
+	}
+	group := func(name string) string {
+		return m[pep440Pattern.SubexpIndex(name)]
+	}
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	r := pep440Version{
+		epoch: atoi(group("epoch")),
+		post:  -1,
+		dev:   math.MaxInt32,
+	}
+	for _, p := range strings.Split(group("release"), ".") {
+		r.release = append(r.release, atoi(p))
+	}
+	// Trailing zeros are not significant, 1.0 == 1.0.0
+	for len(r.release) > 1 && r.release[len(r.release)-1] == 0 {
+		r.release = r.release[:len(r.release)-1]
+	}
+
+	switch strings.ToLower(group("pre_l")) {
+	case "a", "alpha":
+		r.pre = [2]int{0, atoi(group("pre_n"))}
+	case "b", "beta":
+		r.pre = [2]int{1, atoi(group("pre_n"))}
+	case "c", "rc", "pre", "preview":
+		r.pre = [2]int{2, atoi(group("pre_n"))}
+	default:
+		r.pre = [2]int{math.MaxInt32, 0}
+	}
+
+	if n := group("post_n1"); n != "" {
+		r.post = atoi(n)
+	} else if group("post_l") != "" {
+		r.post = atoi(group("post_n2"))
+	}
+
+	if group("dev_l") != "" {
+		r.dev = atoi(group("dev_n"))
+		if group("pre_l") == "" && r.post == -1 {
+			r.pre = [2]int{-1, 0}
+		}
+	}
+
+	return &r, nil
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePEP440 compares two python package versions following
+// https://peps.python.org/pep-0440/#summary-of-permitted-suffixes-and-relative-ordering
+func comparePEP440(a, b string) (int, error) {
+	va, err := parsePEP440(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parsePEP440(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if c := cmpInt(va.epoch, vb.epoch); c != 0 {
+		return c, nil
+	}
+	for i := 0; i < len(va.release) || i < len(vb.release); i++ {
+		var ra, rb int
+		if i < len(va.release) {
+			ra = va.release[i]
+		}
+		if i < len(vb.release) {
+			rb = vb.release[i]
+		}
+		if c := cmpInt(ra, rb); c != 0 {
+			return c, nil
+		}
+	}
+	for i := range va.pre {
+		if c := cmpInt(va.pre[i], vb.pre[i]); c != 0 {
+			return c, nil
+		}
+	}
+	if c := cmpInt(va.post, vb.post); c != 0 {
+		return c, nil
+	}
+	return cmpInt(va.dev, vb.dev), nil
+}
+
+// -----------------
+// RubyGems
+// -----------------
+
+var gemSegmentPattern = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+// compareRubyGems compares two gem versions following the same rules as Gem::Version: versions are
+// split into numeric and alphabetic segments, alphabetic segments denote a pre-release (so they
+// sort before numeric segments) and missing segments are treated as zeros.
+func compareRubyGems(a, b string) (int, error) {
+	segments := func(v string) ([]string, error) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil, fmt.Errorf("invalid gem version %q", v)
+		}
+		return gemSegmentPattern.FindAllString(strings.ReplaceAll(v, "-", ".pre."), -1), nil
+	}
+	sa, err := segments(a)
+	if err != nil {
+		return 0, err
+	}
+	sb, err := segments(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(sa) || i < len(sb); i++ {
+		ga, gb := "0", "0"
+		if i < len(sa) {
+			ga = sa[i]
+		}
+		if i < len(sb) {
+			gb = sb[i]
+		}
+		if ga == gb {
+			continue
+		}
+
+		na, errA := strconv.Atoi(ga)
+		nb, errB := strconv.Atoi(gb)
+		switch {
+		case errA != nil && errB == nil:
+			return -1, nil
+		case errA == nil && errB != nil:
+			return 1, nil
+		case errA == nil && errB == nil:
+			if c := cmpInt(na, nb); c != 0 {
+				return c, nil
+			}
+		default:
+			return cmpInt(strings.Compare(ga, gb), 0), nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,91 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	pypi := EcosystemsBySource["python_packages"]
+	require.Equal(t, "zope-interface", pypi.NormalizeName("zope.interface"))
+	require.Equal(t, "typing-extensions", pypi.NormalizeName("Typing__Extensions"))
+	require.Equal(t, "django", pypi.NormalizeName("Django"))
+
+	npm := EcosystemsBySource["npm_packages"]
+	require.Equal(t, "@babel/core", npm.NormalizeName("@babel/core"))
+}
+
+func TestComparePEP440(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.0.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1.0a1", "1.0", -1},
+		{"1.0a1", "1.0b1", -1},
+		{"1.0rc1", "1.0b2", 1},
+		{"1.0.dev1", "1.0a1", -1},
+		{"1.0.post1", "1.0", 1},
+		{"1.0-1", "1.0.post1", 0},
+		{"1.0.post1.dev1", "1.0.post1", -1},
+		{"1.0+local.1", "1.0", 0},
+		{"1!0.1", "2.0", 1},
+		{"v2.0", "2.0", 0},
+		{"22.2.0", "22.10.0", -1},
+	}
+	for _, c := range cases {
+		r, err := comparePEP440(c.a, c.b)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, r, "%s vs %s", c.a, c.b)
+	}
+
+	_, err := comparePEP440("not-a-version", "1.0")
+	require.Error(t, err)
+}
+
+func TestCompareRubyGems(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.0.0", 0},
+		{"1.0.1", "1.0", 1},
+		{"1.0.0.pre1", "1.0.0", -1},
+		{"1.0.0.rc1", "1.0.0.beta2", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"7.0.4.3", "7.0.4.2", 1},
+		{"2.10", "2.9", 1},
+	}
+	for _, c := range cases {
+		r, err := compareRubyGems(c.a, c.b)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, r, "%s vs %s", c.a, c.b)
+	}
+
+	_, err := compareRubyGems("", "1.0")
+	require.Error(t, err)
+}
+
+func TestCompareSemver(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3-alpha", "1.2.3", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"0.0.0-20210101000000-abcdef123456", "0.0.1", -1},
+	}
+	for _, c := range cases {
+		r, err := compareSemver(c.a, c.b)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, r, "%s vs %s", c.a, c.b)
+	}
+
+	_, err := compareSemver("foo", "1.0.0")
+	require.Error(t, err)
+}
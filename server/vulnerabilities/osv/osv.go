@@ -0,0 +1,155 @@
+package osv
+
+import (
+	"sort"
+	"strings"
+)
+
+// Event is a version event of an affected range, only one of the fields is set.
+// see https://ossf.github.io/osv-schema/#affectedrangesevents-fields
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// version returns the version the event refers to.
+func (e Event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	}
+	return e.Limit
+}
+
+// Range is a range of affected versions.
+// see https://ossf.github.io/osv-schema/#affectedranges-field
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Package identifies the affected package.
+// see https://ossf.github.io/osv-schema/#affectedpackage-field
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Affected describes the affected versions of a package.
+// see https://ossf.github.io/osv-schema/#affected-fields
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+// Entry is a vulnerability entry in the OSV format, only the fields used for detecting
+// vulnerabilities are included.
+// see https://ossf.github.io/osv-schema
+type Entry struct {
+	ID        string     `json:"id"`
+	Aliases   []string   `json:"aliases"`
+	Withdrawn string     `json:"withdrawn,omitempty"`
+	Affected  []Affected `json:"affected"`
+}
+
+// CVEs returns the CVE identifiers of the entry, either its id or any of its aliases. Other
+// identifiers (GHSA, PYSEC, etc.) are excluded because we only want to report entries for which
+// we might have a NVD link.
+func (e Entry) CVEs() []string {
+	var r []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{e.ID}, e.Aliases...) {
+		if strings.HasPrefix(strings.ToUpper(id), "CVE-") && !seen[id] {
+			seen[id] = true
+			r = append(r, id)
+		}
+	}
+	return r
+}
+
+// Affects returns whether 'version' is affected using the version semantics of 'eco'. A version
+// is affected if it is explicitly enumerated or if it falls into any of the SEMVER or ECOSYSTEM
+// ranges, GIT ranges are ignored since we don't know the commit of installed packages.
+func (a Affected) Affects(version string, eco Ecosystem) bool {
+	for _, v := range a.Versions {
+		if v == version {
+			return true
+		}
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if affected, err := r.affects(version, eco.compare); err == nil && affected {
+			return true
+		}
+	}
+	return false
+}
+
+// affects evaluates the range events as described in
+// https://ossf.github.io/osv-schema/#evaluation: events are sorted by version and the version is
+// affected if the closest event at or below it is an 'introduced' event.
+func (r Range) affects(version string, cmp func(string, string) (int, error)) (bool, error) {
+	events := make([]Event, len(r.Events))
+	copy(events, r.Events)
+
+	var sortErr error
+	sort.SliceStable(events, func(i, j int) bool {
+		vi, vj := events[i].version(), events[j].version()
+		if vi == "0" || vj == "0" {
+			return vi == "0" && vj != "0"
+		}
+		c, err := cmp(vi, vj)
+		if err != nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return false, sortErr
+	}
+
+	var affected bool
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" {
+				affected = true
+				continue
+			}
+			c, err := cmp(version, e.Introduced)
+			if err != nil {
+				return false, err
+			}
+			if c >= 0 {
+				affected = true
+			}
+		case e.Fixed != "" || e.Limit != "":
+			c, err := cmp(version, e.version())
+			if err != nil {
+				return false, err
+			}
+			if c >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			c, err := cmp(version, e.LastAffected)
+			if err != nil {
+				return false, err
+			}
+			if c > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected, nil
+}
@@ -0,0 +1,104 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryCVEs(t *testing.T) {
+	require.Empty(t, Entry{ID: "GHSA-xxxx-yyyy-zzzz"}.CVEs())
+	require.Equal(t, []string{"CVE-2022-39348"}, Entry{
+		ID:      "PYSEC-2022-301",
+		Aliases: []string{"GHSA-vg46-2rrj-3647", "CVE-2022-39348", "CVE-2022-39348"},
+	}.CVEs())
+	require.Equal(t, []string{"CVE-2021-1234", "CVE-2021-5678"}, Entry{
+		ID:      "CVE-2021-1234",
+		Aliases: []string{"CVE-2021-5678"},
+	}.CVEs())
+}
+
+func TestAffects(t *testing.T) {
+	npm := EcosystemsBySource["npm_packages"]
+
+	t.Run("enumerated versions", func(t *testing.T) {
+		a := Affected{Versions: []string{"1.0.0", "1.0.1"}}
+		require.True(t, a.Affects("1.0.1", npm))
+		require.False(t, a.Affects("1.0.2", npm))
+	})
+
+	t.Run("ranges", func(t *testing.T) {
+		a := Affected{
+			Ranges: []Range{
+				{
+					Type: "SEMVER",
+					Events: []Event{
+						{Introduced: "0"},
+						{Fixed: "1.2.3"},
+						{Introduced: "2.0.0"},
+						{Fixed: "2.1.0"},
+					},
+				},
+			},
+		}
+		cases := []struct {
+			version  string
+			affected bool
+		}{
+			{"0.0.1", true},
+			{"1.2.2", true},
+			{"1.2.3", false},
+			{"1.9.9", false},
+			{"2.0.0", true},
+			{"2.0.5-beta.1", true},
+			{"2.1.0", false},
+			{"3.0.0", false},
+		}
+		for _, c := range cases {
+			require.Equal(t, c.affected, a.Affects(c.version, npm), c.version)
+		}
+	})
+
+	t.Run("events are evaluated in version order", func(t *testing.T) {
+		a := Affected{
+			Ranges: []Range{
+				{
+					Type: "ECOSYSTEM",
+					Events: []Event{
+						{Fixed: "2.1.0"},
+						{Introduced: "2.0.0"},
+					},
+				},
+			},
+		}
+		require.False(t, a.Affects("1.0.0", npm))
+		require.True(t, a.Affects("2.0.1", npm))
+		require.False(t, a.Affects("2.1.0", npm))
+	})
+
+	t.Run("last affected and limit", func(t *testing.T) {
+		lastAffected := Affected{
+			Ranges: []Range{{Type: "SEMVER", Events: []Event{{Introduced: "1.0.0"}, {LastAffected: "1.5.0"}}}},
+		}
+		require.True(t, lastAffected.Affects("1.5.0", npm))
+		require.False(t, lastAffected.Affects("1.5.1", npm))
+
+		limit := Affected{
+			Ranges: []Range{{Type: "SEMVER", Events: []Event{{Introduced: "0"}, {Limit: "1.5.0"}}}},
+		}
+		require.True(t, limit.Affects("1.4.9", npm))
+		require.False(t, limit.Affects("1.5.0", npm))
+	})
+
+	t.Run("git ranges and invalid versions are ignored", func(t *testing.T) {
+		git := Affected{
+			Ranges: []Range{{Type: "GIT", Events: []Event{{Introduced: "0"}, {Fixed: "abcdef"}}}},
+		}
+		require.False(t, git.Affects("1.0.0", npm))
+
+		a := Affected{
+			Ranges: []Range{{Type: "SEMVER", Events: []Event{{Introduced: "0"}, {Fixed: "1.2.3"}}}},
+		}
+		require.False(t, a.Affects("not a version", npm))
+	})
+}
@@ -0,0 +1,101 @@
+package osv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/pkg/download"
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+const (
+	// dumpsBaseURL is where the OSV project publishes the dumps of all the vulnerabilities of
+	// each ecosystem, see https://google.github.io/osv.dev/data/#data-dumps
+	dumpsBaseURL = "https://osv-vulnerabilities.storage.googleapis.com"
+	// dumpMaxAge is how long a downloaded dump is used before downloading it again.
+	dumpMaxAge = 24 * time.Hour
+)
+
+// DumpFilename returns the name of the file containing the OSV dump for 'ecosystem', dumps are
+// zip files containing one JSON file per vulnerability. Dumps can be placed in the vulnerabilities
+// path manually when the data sync is disabled.
+func DumpFilename(ecosystem string) string {
+	return fmt.Sprintf("osv-%s.zip", ecosystem)
+}
+
+// installedEcosystems returns the ecosystems of all the software in the datastore, grouped by
+// ecosystem and normalized package name.
+func installedEcosystems(ctx context.Context, ds fleet.Datastore) (map[string]map[string][]fleet.Software, error) {
+	iter, err := ds.ListSoftwareBySourceIter(ctx, SupportedSoftwareSources())
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "listing software")
+	}
+	defer iter.Close()
+
+	r := make(map[string]map[string][]fleet.Software)
+	for iter.Next() {
+		software, err := iter.Value()
+		if err != nil {
+			return nil, ctxerr.Wrap(ctx, err, "getting software from iterator")
+		}
+
+		eco, ok := EcosystemsBySource[software.Source]
+		if !ok {
+			continue
+		}
+		if r[eco.Name] == nil {
+			r[eco.Name] = make(map[string][]fleet.Software)
+		}
+		name := eco.NormalizeName(software.Name)
+		r[eco.Name][name] = append(r[eco.Name][name], *software)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "iterating software")
+	}
+
+	return r, nil
+}
+
+// Refresh downloads the OSV dumps for the ecosystems of the software in the datastore into
+// 'vulnPath'. Dumps downloaded within the last day are kept. Returns the ecosystems of the newly
+// downloaded dumps.
+func Refresh(ctx context.Context, ds fleet.Datastore, vulnPath string) ([]string, error) {
+	installed, err := installedEcosystems(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	ecosystems := make([]string, 0, len(installed))
+	for eco := range installed {
+		ecosystems = append(ecosystems, eco)
+	}
+
+	return sync(fleethttp.NewClient(), dumpsBaseURL, vulnPath, ecosystems, time.Now())
+}
+
+func sync(client *http.Client, baseURL string, vulnPath string, ecosystems []string, now time.Time) ([]string, error) {
+	var downloaded []string
+	for _, eco := range ecosystems {
+		dst := filepath.Join(vulnPath, DumpFilename(eco))
+		if stat, err := os.Stat(dst); err == nil && now.Sub(stat.ModTime()) < dumpMaxAge {
+			continue
+		}
+
+		u, err := url.Parse(fmt.Sprintf("%s/%s/all.zip", baseURL, url.PathEscape(eco)))
+		if err != nil {
+			return nil, err
+		}
+		if err := download.Download(client, u, dst); err != nil {
+			return nil, fmt.Errorf("osv sync %s: %w", eco, err)
+		}
+		downloaded = append(downloaded, eco)
+	}
+	return downloaded, nil
+}
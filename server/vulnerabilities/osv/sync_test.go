@@ -0,0 +1,48 @@
+package osv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSync(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		_, _ = w.Write([]byte("zip contents"))
+	}))
+	defer srv.Close()
+
+	vulnPath := t.TempDir()
+	now := time.Now()
+
+	// a fresh dump is kept
+	fresh := filepath.Join(vulnPath, DumpFilename("npm"))
+	require.NoError(t, os.WriteFile(fresh, []byte("fresh"), 0o644))
+
+	// an old dump is downloaded again
+	old := filepath.Join(vulnPath, DumpFilename("PyPI"))
+	require.NoError(t, os.WriteFile(old, []byte("old"), 0o644))
+	require.NoError(t, os.Chtimes(old, now.Add(-48*time.Hour), now.Add(-48*time.Hour)))
+
+	downloaded, err := sync(srv.Client(), srv.URL, vulnPath, []string{"npm", "PyPI", "crates.io"}, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PyPI", "crates.io"}, downloaded)
+	require.Equal(t, []string{"/PyPI/all.zip", "/crates.io/all.zip"}, requested)
+
+	contents, err := os.ReadFile(fresh)
+	require.NoError(t, err)
+	require.Equal(t, "fresh", string(contents))
+
+	contents, err = os.ReadFile(old)
+	require.NoError(t, err)
+	require.Equal(t, "zip contents", string(contents))
+
+	require.FileExists(t, filepath.Join(vulnPath, "osv-crates.io.zip"))
+}
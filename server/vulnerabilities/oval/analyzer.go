@@ -28,14 +28,10 @@ func Analyze(
 ) ([]fleet.SoftwareVulnerability, error) {
 	platform := NewPlatform(ver.Platform, ver.Name)
 
-	source := fleet.UbuntuOVALSource
-	if platform.IsRedHat() {
-		source = fleet.RHELOVALSource
-	}
-
 	if !platform.IsSupported() {
 		return nil, nil
 	}
+	source := vulnSource(platform)
 
 	defs, err := loadDef(platform, vulnPath)
 	if err != nil {
@@ -121,6 +117,23 @@ func Analyze(
 	return inserted, nil
 }
 
+// vulnSource returns the source used when storing the vulnerabilities found for 'platform'.
+func vulnSource(platform Platform) fleet.VulnerabilitySource {
+	switch {
+	case platform.IsRedHat():
+		return fleet.RHELOVALSource
+	case platform.IsDebian():
+		return fleet.DebianOVALSource
+	case platform.IsSUSE():
+		return fleet.SUSEOVALSource
+	case platform.IsAmazonLinux2023():
+		return fleet.AmazonLinuxALASSource
+	case platform.IsAlpine():
+		return fleet.AlpineSecDBSource
+	}
+	return fleet.UbuntuOVALSource
+}
+
 // loadDef returns the latest oval Definition for the given platform.
 func loadDef(platform Platform, vulnPath string) (oval_parsed.Result, error) {
 	if !platform.IsSupported() {
@@ -137,21 +150,34 @@ func loadDef(platform Platform, vulnPath string) (oval_parsed.Result, error) {
 		return nil, err
 	}
 
-	if platform.IsUbuntu() {
-		result := oval_parsed.UbuntuResult{}
-		if err := json.Unmarshal(payload, &result); err != nil {
-			return nil, err
-		}
-		return result, nil
+	var result oval_parsed.Result
+	switch {
+	case platform.IsUbuntu():
+		r := oval_parsed.UbuntuResult{}
+		err = json.Unmarshal(payload, &r)
+		result = r
+	case platform.IsRedHat(), platform.IsSUSE():
+		r := oval_parsed.RhelResult{}
+		err = json.Unmarshal(payload, &r)
+		result = r
+	case platform.IsDebian():
+		r := oval_parsed.DebianResult{}
+		err = json.Unmarshal(payload, &r)
+		result = r
+	case platform.IsAmazonLinux2023():
+		r := oval_parsed.AmazonLinuxResult{}
+		err = json.Unmarshal(payload, &r)
+		result = r
+	case platform.IsAlpine():
+		r := oval_parsed.AlpineResult{}
+		err = json.Unmarshal(payload, &r)
+		result = r
+	default:
+		return nil, fmt.Errorf("don't know how to parse file %q for %q platform", latest, platform)
 	}
-
-	if platform.IsRedHat() {
-		result := oval_parsed.RhelResult{}
-		if err := json.Unmarshal(payload, &result); err != nil {
-			return nil, err
-		}
-		return result, nil
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("don't know how to parse file %q for %q platform", latest, platform)
+	return result, nil
 }
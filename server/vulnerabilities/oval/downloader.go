@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -41,6 +43,61 @@ func getOvalSources(getter func(string) (io.ReadCloser, error)) (OvalSources, er
 	return sources, nil
 }
 
+// debianCodenames maps a Debian major version to its codename, used for locating the Debian OVAL
+// definitions.
+var debianCodenames = map[int]string{
+	9:  "stretch",
+	10: "buster",
+	11: "bullseye",
+	12: "bookworm",
+}
+
+// amazonLinux2023MirrorList is the mirror list of the Amazon Linux 2023 core repository, whose
+// 'updateinfo.xml' metadata contains the ALAS security advisories.
+const amazonLinux2023MirrorList = "https://cdn.amazonlinux.com/al2023/core/mirrors/latest/x86_64/mirror.list"
+
+// defaultOvalSource returns where to find the definitions for platforms that are published
+// upstream in a well-known location, used when 'platform' is not included in the 'oval sources'
+// file.
+func defaultOvalSource(platform Platform) (string, bool) {
+	if platform.IsAmazonLinux2023() {
+		// the advisories of all the Amazon Linux 2023 releases are published in the same
+		// repository
+		return amazonLinux2023MirrorList, true
+	}
+
+	parts := strings.SplitN(string(platform), "_", 2)
+	if len(parts) != 2 || len(parts[1]) < 2 {
+		return "", false
+	}
+	major, err := strconv.Atoi(parts[1][:2])
+	if err != nil {
+		return "", false
+	}
+	var minor int
+	if len(parts[1]) > 2 {
+		if minor, err = strconv.Atoi(parts[1][2:]); err != nil {
+			return "", false
+		}
+	}
+
+	switch {
+	case platform.IsDebian():
+		codename, ok := debianCodenames[major]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("https://www.debian.org/security/oval/oval-definitions-%s.xml.bz2", codename), true
+	case strings.HasPrefix(string(platform), "sles"):
+		return fmt.Sprintf("https://ftp.suse.com/pub/projects/security/oval/suse.linux.enterprise.server.%d.xml.gz", major), true
+	case strings.HasPrefix(string(platform), "opensuse-leap"):
+		return fmt.Sprintf("https://ftp.suse.com/pub/projects/security/oval/opensuse.leap.%d.%d.xml.gz", major, minor), true
+	case platform.IsAlpine():
+		return fmt.Sprintf("https://secdb.alpinelinux.org/v%d.%d/main.json", major, minor), true
+	}
+	return "", false
+}
+
 // downloadDefinitions downloads the OVAL definitions for a given 'platform-major os version'.
 // Returns the filepath to the downloaded oval definitions.
 func downloadDefinitions(
@@ -49,6 +106,9 @@ func downloadDefinitions(
 	downloader func(string, string) error,
 ) (string, error) {
 	url, ok := sources[platform]
+	if !ok {
+		url, ok = defaultOvalSource(platform)
+	}
 	if !ok {
 		return "", fmt.Errorf("could not find platform %s on oval sources", platform)
 	}
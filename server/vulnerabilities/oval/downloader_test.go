@@ -34,3 +34,41 @@ func TestOvalDownloadDefinitionsPlatformNotFound(t *testing.T) {
 	_, err := downloadDefinitions(ovalSources, "rhel-8", dw)
 	require.ErrorContains(t, err, "could not find platform")
 }
+
+func TestOvalDownloadDefinitionsDefaultSources(t *testing.T) {
+	cases := []struct {
+		platform Platform
+		expected string
+	}{
+		{"debian_11", "https://www.debian.org/security/oval/oval-definitions-bullseye.xml.bz2"},
+		{"sles_15", "https://ftp.suse.com/pub/projects/security/oval/suse.linux.enterprise.server.15.xml.gz"},
+		{"opensuse-leap_1504", "https://ftp.suse.com/pub/projects/security/oval/opensuse.leap.15.4.xml.gz"},
+		{"alpine_0317", "https://secdb.alpinelinux.org/v3.17/main.json"},
+		{"amzn_2023", "https://cdn.amazonlinux.com/al2023/core/mirrors/latest/x86_64/mirror.list"},
+	}
+
+	for _, c := range cases {
+		var downloaded string
+		dw := func(a string, b string) error {
+			downloaded = a
+			return nil
+		}
+		_, err := downloadDefinitions(OvalSources{}, c.platform, dw)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, downloaded)
+	}
+
+	// oval sources take precedence
+	var downloaded string
+	dw := func(a string, b string) error {
+		downloaded = a
+		return nil
+	}
+	_, err := downloadDefinitions(OvalSources{"debian_11": "https://example.com/debian.xml.bz2"}, "debian_11", dw)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/debian.xml.bz2", downloaded)
+
+	// no default source
+	_, err = downloadDefinitions(OvalSources{}, "debian_08", dw)
+	require.ErrorContains(t, err, "could not find platform")
+}
@@ -0,0 +1,26 @@
+package oval_input
+
+// AlasReferenceXML is a reference (usually a CVE) included in an Amazon Linux security advisory.
+type AlasReferenceXML struct {
+	Id   string `xml:"id,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// AlasPackageXML is a package included in an Amazon Linux security advisory, the attributes
+// describe the first package version containing the fix.
+type AlasPackageXML struct {
+	Name    string `xml:"name,attr"`
+	Epoch   string `xml:"epoch,attr"`
+	Version string `xml:"version,attr"`
+	Release string `xml:"release,attr"`
+	Arch    string `xml:"arch,attr"`
+}
+
+// AlasUpdateXML is an Amazon Linux security advisory (ALAS) as found in the 'updateinfo.xml'
+// repository metadata file, see https://alas.aws.amazon.com.
+type AlasUpdateXML struct {
+	Id         string             `xml:"id"`
+	Type       string             `xml:"type,attr"`
+	References []AlasReferenceXML `xml:"references>reference"`
+	Packages   []AlasPackageXML   `xml:"pkglist>collection>package"`
+}
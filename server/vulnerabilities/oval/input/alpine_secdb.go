@@ -0,0 +1,18 @@
+package oval_input
+
+// AlpineSecDBPackageJSON contains the security fixes for a given package, 'SecFixes' maps a
+// package version to the list of vulnerabilities fixed in that version.
+type AlpineSecDBPackageJSON struct {
+	Name     string              `json:"name"`
+	SecFixes map[string][]string `json:"secfixes"`
+}
+
+// AlpineSecDBJSON is the Alpine security database for a given release branch and repository, see
+// https://secdb.alpinelinux.org.
+type AlpineSecDBJSON struct {
+	DistroVersion string `json:"distroversion"`
+	RepoName      string `json:"reponame"`
+	Packages      []struct {
+		Pkg AlpineSecDBPackageJSON `json:"pkg"`
+	} `json:"packages"`
+}
@@ -0,0 +1,12 @@
+package oval_input
+
+// DebianResultXML groups together the different tokens produced from parsing an OVAL file targeting
+// Debian distros.
+type DebianResultXML struct {
+	Definitions     []DefinitionXML
+	DpkgInfoTests   []DpkgInfoTestXML
+	DpkgInfoStates  []DpkgInfoStateXML
+	DpkgInfoObjects []PackageInfoTestObjectXML
+	PlatformTests   []PlatformCheckTestXML
+	Variables       map[string]ConstantVariableXML
+}
@@ -0,0 +1,12 @@
+package oval_input
+
+// PlatformCheckTestXML is used for tests that make assertions against the installed OS rather than
+// against an installed package, for example the 'textfilecontent54_test' used for checking the
+// Debian release (see
+// https://oval.mitre.org/language/version5.10.1/ovaldefinition/documentation/independent-definitions-schema.html#textfilecontent54_test)
+// or the 'uname_test' used for checking the host architecture (see
+// https://oval.mitre.org/language/version5.10.1/ovaldefinition/documentation/unix-definitions-schema.html#uname_test).
+type PlatformCheckTestXML struct {
+	Id      string `xml:"id,attr"`
+	Comment string `xml:"comment,attr"`
+}
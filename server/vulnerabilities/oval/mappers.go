@@ -3,11 +3,13 @@ package oval
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
 	oval_input "github.com/fleetdm/fleet/v4/server/vulnerabilities/oval/input"
 	oval_parsed "github.com/fleetdm/fleet/v4/server/vulnerabilities/oval/parsed"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/utils"
 )
 
 // extractId discards the Namespace part of an OVAL id attr, returning only the last numeric portion.
@@ -296,3 +298,93 @@ func mapDpkgInfoState(sta oval_input.DpkgInfoStateXML) (*oval_parsed.ObjectState
 	r := oval_parsed.NewObjectStateEvrString(sta.Evr.Op, sta.Evr.Value)
 	return &r, nil
 }
+
+// -----------------
+// Debian
+// -----------------
+
+// mapPlatformCheckTest returns the id of a PlatformCheckTestXML, will error out if the test id can
+// not be parsed.
+func mapPlatformCheckTest(i oval_input.PlatformCheckTestXML) (int, error) {
+	return extractId(i.Id)
+}
+
+// -----------------
+// Amazon Linux
+// -----------------
+
+// mapAlasUpdate maps an AlasUpdateXML into a PackageFix for each one of the included packages,
+// keyed by package name. Only security advisories referencing at least one CVE are mapped. The
+// epoch is not included in the fixed version because we don't store the epoch of installed
+// packages (see https://github.com/fleetdm/fleet/issues/6236).
+func mapAlasUpdate(u oval_input.AlasUpdateXML) map[string]oval_parsed.PackageFix {
+	if u.Type != "security" {
+		return nil
+	}
+
+	var vulns []string
+	for _, ref := range u.References {
+		if ref.Type == "cve" && ref.Id != "" {
+			vulns = append(vulns, ref.Id)
+		}
+	}
+	if len(vulns) == 0 {
+		return nil
+	}
+
+	r := make(map[string]oval_parsed.PackageFix)
+	for _, pkg := range u.Packages {
+		if pkg.Name == "" || pkg.Version == "" {
+			continue
+		}
+		fixed := pkg.Version
+		if pkg.Release != "" {
+			fixed = fmt.Sprintf("%s-%s", pkg.Version, pkg.Release)
+		}
+		// The same package is listed once per architecture
+		r[pkg.Name] = oval_parsed.PackageFix{
+			FixedVersion:    fixed,
+			Vulnerabilities: vulns,
+		}
+	}
+	return r
+}
+
+// -----------------
+// Alpine
+// -----------------
+
+// mapAlpineSecFixes maps the 'secfixes' of an Alpine package into a list of PackageFix. Fixes
+// listed under the '0' version are skipped, those are used by Alpine for flagging vulnerabilities
+// that never affected the package. Some entries contain more than just the CVE id (for example
+// 'CVE-2019-1234 GHSA-xxxx'), only the first token is kept.
+func mapAlpineSecFixes(pkg oval_input.AlpineSecDBPackageJSON) []oval_parsed.PackageFix {
+	var r []oval_parsed.PackageFix
+	for ver, entries := range pkg.SecFixes {
+		if ver == "0" {
+			continue
+		}
+
+		var vulns []string
+		for _, e := range entries {
+			fields := strings.Fields(e)
+			if len(fields) == 0 {
+				continue
+			}
+			vulns = append(vulns, fields[0])
+		}
+		if len(vulns) == 0 {
+			continue
+		}
+
+		r = append(r, oval_parsed.PackageFix{
+			FixedVersion:    ver,
+			Vulnerabilities: vulns,
+		})
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return utils.Rpmvercmp(r[i].FixedVersion, r[j].FixedVersion) == -1
+	})
+	return r
+}
@@ -504,4 +504,64 @@ func TestOvalMapper(t *testing.T) {
 			require.Equal(t, *output.Version, oval_parsed.NewObjectStateSimpleValue("int", "equals", "123"))
 		})
 	})
+
+	t.Run("#mapAlasUpdate", func(t *testing.T) {
+		t.Run("skips non security advisories", func(t *testing.T) {
+			input := oval_input.AlasUpdateXML{
+				Type:       "bugfix",
+				References: []oval_input.AlasReferenceXML{{Id: "CVE-2022-1234", Type: "cve"}},
+				Packages:   []oval_input.AlasPackageXML{{Name: "curl", Version: "7.87.0", Release: "1"}},
+			}
+			require.Empty(t, mapAlasUpdate(input))
+		})
+
+		t.Run("skips advisories without CVEs", func(t *testing.T) {
+			input := oval_input.AlasUpdateXML{
+				Type:       "security",
+				References: []oval_input.AlasReferenceXML{{Id: "RHBZ-1234", Type: "bugzilla"}},
+				Packages:   []oval_input.AlasPackageXML{{Name: "curl", Version: "7.87.0", Release: "1"}},
+			}
+			require.Empty(t, mapAlasUpdate(input))
+		})
+
+		t.Run("maps each package without the epoch", func(t *testing.T) {
+			input := oval_input.AlasUpdateXML{
+				Type: "security",
+				References: []oval_input.AlasReferenceXML{
+					{Id: "CVE-2022-1234", Type: "cve"},
+					{Id: "CVE-2022-5678", Type: "cve"},
+				},
+				Packages: []oval_input.AlasPackageXML{
+					{Name: "curl", Epoch: "1", Version: "7.87.0", Release: "2.amzn2023"},
+					{Name: "libcurl", Version: "7.87.0"},
+				},
+			}
+			require.Equal(t, map[string]oval_parsed.PackageFix{
+				"curl": {
+					FixedVersion:    "7.87.0-2.amzn2023",
+					Vulnerabilities: []string{"CVE-2022-1234", "CVE-2022-5678"},
+				},
+				"libcurl": {
+					FixedVersion:    "7.87.0",
+					Vulnerabilities: []string{"CVE-2022-1234", "CVE-2022-5678"},
+				},
+			}, mapAlasUpdate(input))
+		})
+	})
+
+	t.Run("#mapAlpineSecFixes", func(t *testing.T) {
+		input := oval_input.AlpineSecDBPackageJSON{
+			Name: "curl",
+			SecFixes: map[string][]string{
+				"7.88.0-r0":  {"CVE-2023-23914", "CVE-2023-23915"},
+				"7.79.1-r0":  {"CVE-2021-22945"},
+				"7.80.0-r10": {},
+				"0":          {"CVE-2021-22897"},
+			},
+		}
+		require.Equal(t, []oval_parsed.PackageFix{
+			{FixedVersion: "7.79.1-r0", Vulnerabilities: []string{"CVE-2021-22945"}},
+			{FixedVersion: "7.88.0-r0", Vulnerabilities: []string{"CVE-2023-23914", "CVE-2023-23915"}},
+		}, mapAlpineSecFixes(input))
+	})
 }
@@ -15,7 +15,7 @@ type Platform string
 const OvalFilePrefix = "fleet_oval"
 
 // SupportedSoftwareSources are the software sources for which we are using OVAL for vulnerability detection.
-var SupportedSoftwareSources = []string{"deb_packages", "rpm_packages"}
+var SupportedSoftwareSources = []string{"deb_packages", "rpm_packages", "apk_packages"}
 
 // getMajorMinorVer returns the major and minor version of an 'os_version'.
 // ex: 'Ubuntu 20.4.0' => '(20, 04)'
@@ -45,10 +45,12 @@ func getMajorMinorVer(osVersion string) (string, string) {
 }
 
 func format(platform string, major string, minor string) string {
-	if platform == "ubuntu" {
+	switch platform {
+	case "ubuntu", "opensuse-leap", "alpine":
+		// These platforms publish a different set of definitions per minor release
 		return fmt.Sprintf("%s_%s%s", platform, major, minor)
 	}
-	// RHEL based platforms only use the major version for their OVAL definitions
+	// RHEL based platforms (and Debian, SLES) only use the major version for their OVAL definitions
 	return fmt.Sprintf("%s_%s", platform, major)
 }
 
@@ -57,6 +59,7 @@ func format(platform string, major string, minor string) string {
 // Examples:
 // ('ubuntu', 'Ubuntu 20.4.0') => 'ubuntu_2004'.
 // ('rhel', 'CentOS Linux 7.9.2009') => 'rhel_07'.
+// ('alpine', 'Alpine Linux 3.17.2') => 'alpine_0317'.
 func NewPlatform(hostPlatform, hostOsVersion string) Platform {
 	nPlatform := strings.Trim(strings.ToLower(hostPlatform), " ")
 	hostOsVersion = oval_parsed.ReplaceFedoraOSVersion(hostOsVersion)
@@ -86,6 +89,15 @@ func (op Platform) IsSupported() bool {
 		"rhel_08",
 		"rhel_09",
 		"amzn_02",
+		"amzn_2023",
+		"debian_09",
+		"debian_10",
+		"debian_11",
+		"debian_12",
+		"sles_12",
+		"sles_15",
+		"opensuse-leap_15",
+		"alpine_03",
 	}
 	for _, p := range supported {
 		if strings.HasPrefix(string(op), p) {
@@ -102,5 +114,28 @@ func (op Platform) IsUbuntu() bool {
 
 // IsRedHat checks whether the current Platform targets Redhat based systems.
 func (op Platform) IsRedHat() bool {
-	return strings.HasPrefix(string(op), "rhel") || strings.HasPrefix(string(op), "amzn")
+	return strings.HasPrefix(string(op), "rhel") || strings.HasPrefix(string(op), "amzn_02")
+}
+
+// IsDebian checks whether the current Platform targets Debian.
+func (op Platform) IsDebian() bool {
+	return strings.HasPrefix(string(op), "debian")
+}
+
+// IsSUSE checks whether the current Platform targets SUSE Linux Enterprise Server or openSUSE.
+func (op Platform) IsSUSE() bool {
+	return strings.HasPrefix(string(op), "sles") || strings.HasPrefix(string(op), "opensuse")
+}
+
+// IsAmazonLinux2023 checks whether the current Platform targets Amazon Linux 2023. Amazon Linux
+// 2023 does not publish OVAL definitions, vulnerabilities are instead detected using the ALAS
+// security advisories contained in the 'updateinfo.xml' repository metadata.
+func (op Platform) IsAmazonLinux2023() bool {
+	return strings.HasPrefix(string(op), "amzn_2023")
+}
+
+// IsAlpine checks whether the current Platform targets Alpine Linux. Alpine Linux does not publish
+// OVAL definitions, vulnerabilities are instead detected using the Alpine security database (secdb).
+func (op Platform) IsAlpine() bool {
+	return strings.HasPrefix(string(op), "alpine")
 }
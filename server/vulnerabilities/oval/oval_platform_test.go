@@ -53,6 +53,11 @@ func TestOvalPlatform(t *testing.T) {
 			{"rhel", "Fedora Linux 35.0.0", "rhel_09"},
 			{"rhel", "Fedora Linux 36.0.0", "rhel_09"},
 			{"ubuntu", "Ubuntu 20.04.2 LTS", "ubuntu_2004"},
+			{"debian", "Debian GNU/Linux 11.0.0", "debian_11"},
+			{"amzn", "Amazon Linux 2023.0.20230315", "amzn_2023"},
+			{"sles", "SLES 15.4.0", "sles_15"},
+			{"opensuse-leap", "openSUSE Leap 15.4.0", "opensuse-leap_1504"},
+			{"alpine", "Alpine Linux 3.17.2", "alpine_0317"},
 		}
 
 		for _, c := range cases {
@@ -60,6 +65,46 @@ func TestOvalPlatform(t *testing.T) {
 		}
 	})
 
+	t.Run("IsSupported", func(t *testing.T) {
+		cases := []struct {
+			platform  string
+			osVersion string
+			supported bool
+		}{
+			{"ubuntu", "Ubuntu 20.4.0", true},
+			{"rhel", "CentOS Linux 7.9.2009", true},
+			{"amzn", "Amazon Linux 2.0.0", true},
+			{"amzn", "Amazon Linux 2023.0.20230315", true},
+			{"debian", "Debian GNU/Linux 9.0.0", true},
+			{"debian", "Debian GNU/Linux 12.0.0", true},
+			{"debian", "Debian GNU/Linux 8.0.0", false},
+			{"sles", "SLES 15.4.0", true},
+			{"sles", "SLES 11.4.0", false},
+			{"opensuse-leap", "openSUSE Leap 15.4.0", true},
+			{"alpine", "Alpine Linux 3.17.2", true},
+			{"arch", "Arch Linux 1.0.0", false},
+		}
+		for _, c := range cases {
+			require.Equal(t, c.supported, NewPlatform(c.platform, c.osVersion).IsSupported(), c)
+		}
+	})
+
+	t.Run("platform families", func(t *testing.T) {
+		amzn2 := NewPlatform("amzn", "Amazon Linux 2.0.0")
+		require.True(t, amzn2.IsRedHat())
+		require.False(t, amzn2.IsAmazonLinux2023())
+
+		amzn2023 := NewPlatform("amzn", "Amazon Linux 2023.0.20230315")
+		require.False(t, amzn2023.IsRedHat())
+		require.True(t, amzn2023.IsAmazonLinux2023())
+
+		require.True(t, NewPlatform("debian", "Debian GNU/Linux 11.0.0").IsDebian())
+		require.False(t, NewPlatform("debian", "Debian GNU/Linux 11.0.0").IsUbuntu())
+		require.True(t, NewPlatform("sles", "SLES 15.4.0").IsSUSE())
+		require.True(t, NewPlatform("opensuse-leap", "openSUSE Leap 15.4.0").IsSUSE())
+		require.True(t, NewPlatform("alpine", "Alpine Linux 3.17.2").IsAlpine())
+	})
+
 	t.Run("ToFilename", func(t *testing.T) {
 		cases := []struct {
 			date     time.Time
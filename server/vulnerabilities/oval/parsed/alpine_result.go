@@ -0,0 +1,32 @@
+package oval_parsed
+
+import (
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/utils"
+)
+
+type AlpineResult struct {
+	// Fixes maps a package name to the security fixes published for it.
+	Fixes map[string][]PackageFix
+}
+
+// NewAlpineResult is the result of parsing the Alpine security database (secdb) for an Alpine
+// release.
+func NewAlpineResult() *AlpineResult {
+	return &AlpineResult{
+		Fixes: make(map[string][]PackageFix),
+	}
+}
+
+// AddFix adds a security fix for the package 'name' to the given result.
+func (r *AlpineResult) AddFix(name string, fix PackageFix) {
+	r.Fixes[name] = append(r.Fixes[name], fix)
+}
+
+// Eval only considers software reported with the 'apk_packages' source, ingested from the
+// apk_packages table of the fleetd osquery extension.
+func (r AlpineResult) Eval(ver fleet.OSVersion, software []fleet.Software) ([]fleet.SoftwareVulnerability, error) {
+	return evalPackageFixes(r.Fixes, "apk_packages", software, func(s fleet.Software) string {
+		return s.Version
+	}, utils.Apkvercmp), nil
+}
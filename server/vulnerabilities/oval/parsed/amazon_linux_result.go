@@ -0,0 +1,28 @@
+package oval_parsed
+
+import (
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/vulnerabilities/utils"
+)
+
+type AmazonLinuxResult struct {
+	// Fixes maps a package name to the security fixes published for it.
+	Fixes map[string][]PackageFix
+}
+
+// NewAmazonLinuxResult is the result of parsing the security advisories (ALAS) for an Amazon Linux
+// release.
+func NewAmazonLinuxResult() *AmazonLinuxResult {
+	return &AmazonLinuxResult{
+		Fixes: make(map[string][]PackageFix),
+	}
+}
+
+// AddFix adds a security fix for the package 'name' to the given result.
+func (r *AmazonLinuxResult) AddFix(name string, fix PackageFix) {
+	r.Fixes[name] = append(r.Fixes[name], fix)
+}
+
+func (r AmazonLinuxResult) Eval(ver fleet.OSVersion, software []fleet.Software) ([]fleet.SoftwareVulnerability, error) {
+	return evalPackageFixes(r.Fixes, "rpm_packages", software, rpmEvr, utils.Rpmvercmp), nil
+}
@@ -0,0 +1,69 @@
+package oval_parsed
+
+import (
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+type DebianResult struct {
+	Definitions   []Definition
+	PackageTests  map[int]*DpkgInfoTest
+	PlatformTests []int
+}
+
+// NewDebianResult is the result of parsing an OVAL file that targets a Debian release.
+// Used to evaluate whether a Debian host is vulnerable based on one or more package tests.
+func NewDebianResult() *DebianResult {
+	return &DebianResult{
+		PackageTests: make(map[int]*DpkgInfoTest),
+	}
+}
+
+// AddDefinition add a definition to the given result.
+func (r *DebianResult) AddDefinition(def Definition) {
+	r.Definitions = append(r.Definitions, def)
+}
+
+// AddPackageTest adds a package test to the given result.
+func (r *DebianResult) AddPackageTest(id int, tst *DpkgInfoTest) {
+	r.PackageTests[id] = tst
+}
+
+func (r DebianResult) Eval(ver fleet.OSVersion, software []fleet.Software) ([]fleet.SoftwareVulnerability, error) {
+	// Test Id => Matching software
+	pkgTstResults := make(map[int][]fleet.Software)
+	for i, t := range r.PackageTests {
+		r, err := t.Eval(software)
+		if err != nil {
+			return nil, err
+		}
+		pkgTstResults[i] = r
+	}
+
+	// Debian publishes one OVAL file per release, so the tests checking the installed release
+	// always hold. Fixed versions are the same across architectures, so the architecture tests
+	// are considered to hold as well.
+	OSTstResults := make(map[int]bool, len(r.PlatformTests))
+	for _, id := range r.PlatformTests {
+		OSTstResults[id] = true
+	}
+
+	vuln := make([]fleet.SoftwareVulnerability, 0)
+	for _, d := range r.Definitions {
+		if !d.Eval(OSTstResults, pkgTstResults) {
+			continue
+		}
+
+		for _, tId := range d.CollectTestIds() {
+			for _, software := range pkgTstResults[tId] {
+				for _, v := range d.CveVulnerabilities() {
+					vuln = append(vuln, fleet.SoftwareVulnerability{
+						SoftwareID: software.ID,
+						CVE:        v,
+					})
+				}
+			}
+		}
+	}
+
+	return vuln, nil
+}
@@ -0,0 +1,67 @@
+package oval_parsed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+)
+
+// PackageFix represents a security fix included in a given package version, any installed package
+// with a lower version is susceptible to all of the listed vulnerabilities. Used by platforms that
+// publish security advisories instead of OVAL definitions.
+type PackageFix struct {
+	FixedVersion    string
+	Vulnerabilities []string
+}
+
+// evalPackageFixes evaluates 'fixes' (package name => fixes) against the installed 'software',
+// only software coming from 'source' is considered. 'installedVer' is used for extracting the
+// version of an installed package in the same format as the fixed version, and 'cmp' for comparing
+// versions using the rules of the platform's package manager.
+func evalPackageFixes(
+	fixes map[string][]PackageFix,
+	source string,
+	software []fleet.Software,
+	installedVer func(fleet.Software) string,
+	cmp func(a, b string) int,
+) []fleet.SoftwareVulnerability {
+	seen := make(map[string]bool)
+	vulns := make([]fleet.SoftwareVulnerability, 0)
+
+	for _, s := range software {
+		if s.Source != source {
+			continue
+		}
+		ver := installedVer(s)
+		for _, fix := range fixes[s.Name] {
+			if cmp(ver, fix.FixedVersion) != -1 {
+				continue
+			}
+			for _, v := range fix.Vulnerabilities {
+				if !strings.HasPrefix(strings.ToLower(v), "cve") {
+					continue
+				}
+				vuln := fleet.SoftwareVulnerability{
+					SoftwareID: s.ID,
+					CVE:        v,
+				}
+				if seen[vuln.Key()] {
+					continue
+				}
+				seen[vuln.Key()] = true
+				vulns = append(vulns, vuln)
+			}
+		}
+	}
+
+	return vulns
+}
+
+// rpmEvr returns the version of an installed rpm package as a 'version-release' string.
+func rpmEvr(s fleet.Software) string {
+	if s.Release != "" {
+		return fmt.Sprintf("%s-%s", s.Version, s.Release)
+	}
+	return s.Version
+}
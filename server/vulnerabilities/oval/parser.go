@@ -23,8 +23,16 @@ func parseDefinitions(platform Platform, inputFile string, outputFile string) er
 	switch {
 	case platform.IsUbuntu():
 		payload, err = processUbuntuDef(r)
-	case platform.IsRedHat():
+	case platform.IsRedHat(), platform.IsSUSE():
 		payload, err = processRhelDef(r)
+	case platform.IsDebian():
+		payload, err = processDebianDef(r)
+	case platform.IsAmazonLinux2023():
+		payload, err = processAmazonLinuxDef(r)
+	case platform.IsAlpine():
+		payload, err = processAlpineDef(r)
+	default:
+		err = fmt.Errorf("don't know how to parse definitions for %q platform", platform)
 	}
 	if err != nil {
 		return fmt.Errorf("oval parser: %w", err)
@@ -388,3 +396,233 @@ func mapToUbuntuResult(xmlResult *oval_input.UbuntuResultXML) (*oval_parsed.Ubun
 	}
 	return r, nil
 }
+
+// -----------------
+// Debian
+// -----------------
+
+func processDebianDef(r io.Reader) ([]byte, error) {
+	xmlResult, err := parseDebianXML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := mapToDebianResult(xmlResult)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func parseDebianXML(reader io.Reader) (*oval_input.DebianResultXML, error) {
+	r := &oval_input.DebianResultXML{
+		Variables: make(map[string]oval_input.ConstantVariableXML),
+	}
+	d := xml.NewDecoder(reader)
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return r, nil
+			}
+			return nil, fmt.Errorf("decoding token: %v", err)
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "definition" {
+				def := oval_input.DefinitionXML{}
+				if err = d.DecodeElement(&def, &t); err != nil {
+					return nil, err
+				}
+				r.Definitions = append(r.Definitions, def)
+			}
+			if t.Name.Local == "dpkginfo_test" {
+				tst := oval_input.DpkgInfoTestXML{}
+				if err = d.DecodeElement(&tst, &t); err != nil {
+					return nil, err
+				}
+				r.DpkgInfoTests = append(r.DpkgInfoTests, tst)
+			}
+			if t.Name.Local == "textfilecontent54_test" || t.Name.Local == "uname_test" {
+				tst := oval_input.PlatformCheckTestXML{}
+				if err = d.DecodeElement(&tst, &t); err != nil {
+					return nil, err
+				}
+				r.PlatformTests = append(r.PlatformTests, tst)
+			}
+			if t.Name.Local == "dpkginfo_state" {
+				sta := oval_input.DpkgInfoStateXML{}
+				if err = d.DecodeElement(&sta, &t); err != nil {
+					return nil, err
+				}
+				r.DpkgInfoStates = append(r.DpkgInfoStates, sta)
+			}
+			if t.Name.Local == "dpkginfo_object" {
+				obj := oval_input.PackageInfoTestObjectXML{}
+				if err = d.DecodeElement(&obj, &t); err != nil {
+					return nil, err
+				}
+				r.DpkgInfoObjects = append(r.DpkgInfoObjects, obj)
+			}
+			if t.Name.Local == "constant_variable" {
+				cVar := oval_input.ConstantVariableXML{}
+				if err = d.DecodeElement(&cVar, &t); err != nil {
+					return nil, err
+				}
+				r.Variables[cVar.Id] = cVar
+			}
+		}
+	}
+}
+
+func mapToDebianResult(xmlResult *oval_input.DebianResultXML) (*oval_parsed.DebianResult, error) {
+	r := oval_parsed.NewDebianResult()
+
+	staToTst := make(map[string][]int)
+	objToTst := make(map[string][]int)
+
+	for _, d := range xmlResult.Definitions {
+		if len(d.Vulnerabilities) > 0 {
+			def, err := mapDefinition(d)
+			if err != nil {
+				return nil, err
+			}
+			r.AddDefinition(*def)
+		}
+	}
+
+	for _, t := range xmlResult.PlatformTests {
+		id, err := mapPlatformCheckTest(t)
+		if err != nil {
+			return nil, err
+		}
+		r.PlatformTests = append(r.PlatformTests, id)
+	}
+
+	for _, t := range xmlResult.DpkgInfoTests {
+		id, tst, err := mapDpkgInfoTest(t)
+		if err != nil {
+			return nil, err
+		}
+
+		objToTst[t.Object.Id] = append(objToTst[t.Object.Id], id)
+		for _, sta := range t.States {
+			staToTst[sta.Id] = append(staToTst[sta.Id], id)
+		}
+		r.AddPackageTest(id, tst)
+	}
+
+	for _, o := range xmlResult.DpkgInfoObjects {
+		obj, err := mapPackageInfoTestObject(o, xmlResult.Variables)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tId := range objToTst[o.Id] {
+			t, ok := r.PackageTests[tId]
+			if ok {
+				t.Objects = obj
+			} else {
+				return nil, fmt.Errorf("test not found: %d", tId)
+			}
+		}
+	}
+
+	for _, s := range xmlResult.DpkgInfoStates {
+		sta, err := mapDpkgInfoState(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, tId := range staToTst[s.Id] {
+			t, ok := r.PackageTests[tId]
+			if ok {
+				t.States = append(t.States, *sta)
+			} else {
+				return nil, fmt.Errorf("test not found: %d", tId)
+			}
+		}
+	}
+	return r, nil
+}
+
+// -----------------
+// Amazon Linux
+// -----------------
+
+func processAmazonLinuxDef(r io.Reader) ([]byte, error) {
+	updates, err := parseAlasXML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := oval_parsed.NewAmazonLinuxResult()
+	for _, u := range updates {
+		for name, fix := range mapAlasUpdate(u) {
+			result.AddFix(name, fix)
+		}
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// parseAlasXML parses the security advisories contained in an 'updateinfo.xml' file.
+func parseAlasXML(reader io.Reader) ([]oval_input.AlasUpdateXML, error) {
+	var r []oval_input.AlasUpdateXML
+	d := xml.NewDecoder(reader)
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return r, nil
+			}
+			return nil, fmt.Errorf("decoding token: %v", err)
+		}
+
+		if t, ok := t.(xml.StartElement); ok && t.Name.Local == "update" {
+			u := oval_input.AlasUpdateXML{}
+			if err = d.DecodeElement(&u, &t); err != nil {
+				return nil, err
+			}
+			r = append(r, u)
+		}
+	}
+}
+
+// -----------------
+// Alpine
+// -----------------
+
+func processAlpineDef(r io.Reader) ([]byte, error) {
+	secDB := oval_input.AlpineSecDBJSON{}
+	if err := json.NewDecoder(r).Decode(&secDB); err != nil {
+		return nil, fmt.Errorf("decoding secdb: %w", err)
+	}
+
+	result := oval_parsed.NewAlpineResult()
+	for _, p := range secDB.Packages {
+		for _, fix := range mapAlpineSecFixes(p.Pkg) {
+			result.AddFix(p.Pkg.Name, fix)
+		}
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
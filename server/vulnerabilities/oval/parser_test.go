@@ -1,6 +1,7 @@
 package oval
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -680,3 +681,317 @@ func TestOvalParser(t *testing.T) {
 		}
 	})
 }
+
+func TestDebianOvalParser(t *testing.T) {
+	debianOvalXML := `
+<oval_definitions
+    xmlns="http://oval.mitre.org/XMLSchema/oval-definitions-5"
+    xmlns:ind-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#independent"
+    xmlns:linux-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#linux"
+    xmlns:oval="http://oval.mitre.org/XMLSchema/oval-common-5"
+    xmlns:unix-def="http://oval.mitre.org/XMLSchema/oval-definitions-5#unix">
+  <definitions>
+    <definition class="vulnerability" id="oval:org.debian:def:20230286" version="1">
+      <metadata>
+        <title>CVE-2023-0286</title>
+        <affected family="unix">
+          <platform>Debian GNU/Linux 11</platform>
+          <product>openssl</product>
+        </affected>
+        <reference ref_id="CVE-2023-0286" ref_url="https://security-tracker.debian.org/tracker/CVE-2023-0286" source="CVE"/>
+        <description>There is a type confusion vulnerability relating to X.400 address processing</description>
+      </metadata>
+      <criteria comment="Release section" operator="AND">
+        <criterion comment="Debian GNU/Linux 11 is installed" test_ref="oval:org.debian.oval:tst:1"/>
+        <criteria comment="Architecture section" operator="OR">
+          <criteria comment="Architecture independent section" operator="AND">
+            <criterion comment="all architecture" test_ref="oval:org.debian.oval:tst:2"/>
+            <criterion comment="openssl DPKG is earlier than 1.1.1n-0+deb11u4" test_ref="oval:org.debian.oval:tst:3"/>
+          </criteria>
+        </criteria>
+      </criteria>
+    </definition>
+    <definition class="vulnerability" id="oval:org.debian:def:20221271" version="1">
+      <metadata>
+        <title>CVE-2022-1271</title>
+        <affected family="unix">
+          <platform>Debian GNU/Linux 11</platform>
+          <product>gzip</product>
+        </affected>
+        <reference ref_id="CVE-2022-1271" ref_url="https://security-tracker.debian.org/tracker/CVE-2022-1271" source="CVE"/>
+        <description>An arbitrary file write vulnerability was found in GNU gzip's zgrep utility.</description>
+      </metadata>
+      <criteria comment="Release section" operator="AND">
+        <criterion comment="Debian GNU/Linux 11 is installed" test_ref="oval:org.debian.oval:tst:1"/>
+        <criteria comment="Architecture section" operator="OR">
+          <criteria comment="Architecture independent section" operator="AND">
+            <criterion comment="all architecture" test_ref="oval:org.debian.oval:tst:2"/>
+            <criterion comment="gzip DPKG is earlier than 1.10-4+deb11u1" test_ref="oval:org.debian.oval:tst:4"/>
+          </criteria>
+        </criteria>
+      </criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <ind-def:textfilecontent54_test check="all" check_existence="at_least_one_exists" comment="Debian GNU/Linux 11 is installed" id="oval:org.debian.oval:tst:1" version="1">
+      <ind-def:object object_ref="oval:org.debian.oval:obj:1"/>
+      <ind-def:state state_ref="oval:org.debian.oval:ste:1"/>
+    </ind-def:textfilecontent54_test>
+    <unix-def:uname_test check="all" check_existence="all_exist" comment="Installed architecture is all" id="oval:org.debian.oval:tst:2" version="1">
+      <unix-def:object object_ref="oval:org.debian.oval:obj:2"/>
+    </unix-def:uname_test>
+    <linux-def:dpkginfo_test check="all" check_existence="at_least_one_exists" comment="openssl is earlier than 1.1.1n-0+deb11u4" id="oval:org.debian.oval:tst:3" version="1">
+      <linux-def:object object_ref="oval:org.debian.oval:obj:3"/>
+      <linux-def:state state_ref="oval:org.debian.oval:ste:3"/>
+    </linux-def:dpkginfo_test>
+    <linux-def:dpkginfo_test check="all" check_existence="at_least_one_exists" comment="gzip is earlier than 1.10-4+deb11u1" id="oval:org.debian.oval:tst:4" version="1">
+      <linux-def:object object_ref="oval:org.debian.oval:obj:4"/>
+      <linux-def:state state_ref="oval:org.debian.oval:ste:4"/>
+    </linux-def:dpkginfo_test>
+  </tests>
+  <objects>
+    <ind-def:textfilecontent54_object id="oval:org.debian.oval:obj:1" version="1">
+      <ind-def:path>/etc</ind-def:path>
+      <ind-def:filename>debian_version</ind-def:filename>
+      <ind-def:pattern operation="pattern match">(\d+)\.\d</ind-def:pattern>
+      <ind-def:instance datatype="int">1</ind-def:instance>
+    </ind-def:textfilecontent54_object>
+    <unix-def:uname_object id="oval:org.debian.oval:obj:2" version="1"/>
+    <linux-def:dpkginfo_object id="oval:org.debian.oval:obj:3" version="1">
+      <linux-def:name>openssl</linux-def:name>
+    </linux-def:dpkginfo_object>
+    <linux-def:dpkginfo_object id="oval:org.debian.oval:obj:4" version="1">
+      <linux-def:name>gzip</linux-def:name>
+    </linux-def:dpkginfo_object>
+  </objects>
+  <states>
+    <ind-def:textfilecontent54_state id="oval:org.debian.oval:ste:1" version="1">
+      <ind-def:subexpression operation="equals">11</ind-def:subexpression>
+    </ind-def:textfilecontent54_state>
+    <linux-def:dpkginfo_state id="oval:org.debian.oval:ste:3" version="1">
+      <linux-def:evr datatype="debian_evr_string" operation="less than">0:1.1.1n-0+deb11u4</linux-def:evr>
+    </linux-def:dpkginfo_state>
+    <linux-def:dpkginfo_state id="oval:org.debian.oval:ste:4" version="1">
+      <linux-def:evr datatype="debian_evr_string" operation="less than">0:1.10-4+deb11u1</linux-def:evr>
+    </linux-def:dpkginfo_state>
+  </states>
+</oval_definitions>`
+
+	t.Run("#parseDebianXML", func(t *testing.T) {
+		result, err := parseDebianXML(strings.NewReader(debianOvalXML))
+		require.NoError(t, err)
+
+		require.Len(t, result.Definitions, 2)
+		require.Equal(t, "CVE-2023-0286", result.Definitions[0].Vulnerabilities[0].Id)
+		require.Len(t, result.DpkgInfoTests, 2)
+		require.Len(t, result.DpkgInfoStates, 2)
+		require.Len(t, result.DpkgInfoObjects, 2)
+		require.Equal(t, []oval_input.PlatformCheckTestXML{
+			{Id: "oval:org.debian.oval:tst:1", Comment: "Debian GNU/Linux 11 is installed"},
+			{Id: "oval:org.debian.oval:tst:2", Comment: "Installed architecture is all"},
+		}, result.PlatformTests)
+	})
+
+	t.Run("#mapToDebianResult", func(t *testing.T) {
+		xmlResult, err := parseDebianXML(strings.NewReader(debianOvalXML))
+		require.NoError(t, err)
+
+		result, err := mapToDebianResult(xmlResult)
+		require.NoError(t, err)
+
+		require.Len(t, result.Definitions, 2)
+		require.ElementsMatch(t, []int{1, 2}, result.PlatformTests)
+		require.Len(t, result.PackageTests, 2)
+		require.Equal(t, []string{"openssl"}, result.PackageTests[3].Objects)
+		require.Equal(t, []oval_parsed.ObjectStateEvrString{
+			oval_parsed.NewObjectStateEvrString("less than", "0:1.1.1n-0+deb11u4"),
+		}, result.PackageTests[3].States)
+		require.Equal(t, []string{"gzip"}, result.PackageTests[4].Objects)
+	})
+
+	t.Run("Debian definitions are evaluated against installed packages", func(t *testing.T) {
+		xmlResult, err := parseDebianXML(strings.NewReader(debianOvalXML))
+		require.NoError(t, err)
+
+		result, err := mapToDebianResult(xmlResult)
+		require.NoError(t, err)
+
+		software := []fleet.Software{
+			{ID: 1, Name: "openssl", Version: "1.1.1n-0+deb11u3", Source: "deb_packages"},
+			{ID: 2, Name: "gzip", Version: "1.10-4+deb11u1", Source: "deb_packages"},
+			{ID: 3, Name: "bash", Version: "5.1-2+deb11u1", Source: "deb_packages"},
+		}
+		vulns, err := result.Eval(fleet.OSVersion{Platform: "debian", Name: "Debian GNU/Linux 11.0.0"}, software)
+		require.NoError(t, err)
+		require.Equal(t, []fleet.SoftwareVulnerability{{SoftwareID: 1, CVE: "CVE-2023-0286"}}, vulns)
+	})
+}
+
+func TestAmazonLinuxParser(t *testing.T) {
+	updateInfoXML := `<?xml version="1.0" ?>
+<updates>
+  <update author="linux-security@amazon.com" from="linux-security@amazon.com" status="final" type="security" version="1.4">
+    <id>ALAS2023-2023-001</id>
+    <title>Amazon Linux 2023 - ALAS2023-2023-001: Medium priority package update for curl</title>
+    <issued date="2023-02-22 01:59:00" />
+    <updated date="2023-02-22 01:59:00" />
+    <severity>medium</severity>
+    <description>Package updates are available for Amazon Linux 2023 that fix the following vulnerabilities.</description>
+    <references>
+      <reference href="https://access.redhat.com/security/cve/CVE-2022-43551" id="CVE-2022-43551" title="" type="cve" />
+      <reference href="https://access.redhat.com/security/cve/CVE-2022-43552" id="CVE-2022-43552" title="" type="cve" />
+    </references>
+    <pkglist>
+      <collection short="amazon-linux-2023---2023.0">
+        <name>amazon-linux-2023---2023.0</name>
+        <package arch="x86_64" epoch="0" name="curl" release="2.amzn2023.0.2" version="7.87.0">
+          <filename>Packages/curl-7.87.0-2.amzn2023.0.2.x86_64.rpm</filename>
+        </package>
+        <package arch="aarch64" epoch="0" name="curl" release="2.amzn2023.0.2" version="7.87.0">
+          <filename>Packages/curl-7.87.0-2.amzn2023.0.2.aarch64.rpm</filename>
+        </package>
+        <package arch="x86_64" epoch="0" name="libcurl" release="2.amzn2023.0.2" version="7.87.0">
+          <filename>Packages/libcurl-7.87.0-2.amzn2023.0.2.x86_64.rpm</filename>
+        </package>
+      </collection>
+    </pkglist>
+  </update>
+  <update author="linux-security@amazon.com" from="linux-security@amazon.com" status="final" type="bugfix" version="1.4">
+    <id>ALAS2023-2023-002</id>
+    <references />
+    <pkglist>
+      <collection short="amazon-linux-2023---2023.0">
+        <package arch="x86_64" epoch="0" name="vim-minimal" release="1.amzn2023" version="9.0.1367">
+          <filename>Packages/vim-minimal-9.0.1367-1.amzn2023.x86_64.rpm</filename>
+        </package>
+      </collection>
+    </pkglist>
+  </update>
+</updates>`
+
+	t.Run("#parseAlasXML", func(t *testing.T) {
+		updates, err := parseAlasXML(strings.NewReader(updateInfoXML))
+		require.NoError(t, err)
+		require.Len(t, updates, 2)
+		require.Equal(t, "ALAS2023-2023-001", updates[0].Id)
+		require.Equal(t, "security", updates[0].Type)
+		require.Len(t, updates[0].References, 2)
+		require.Len(t, updates[0].Packages, 3)
+		require.Equal(t, oval_input.AlasPackageXML{
+			Name:    "curl",
+			Epoch:   "0",
+			Version: "7.87.0",
+			Release: "2.amzn2023.0.2",
+			Arch:    "x86_64",
+		}, updates[0].Packages[0])
+	})
+
+	t.Run("Amazon Linux advisories are evaluated against installed packages", func(t *testing.T) {
+		payload, err := processAmazonLinuxDef(strings.NewReader(updateInfoXML))
+		require.NoError(t, err)
+
+		result := oval_parsed.AmazonLinuxResult{}
+		require.NoError(t, json.Unmarshal(payload, &result))
+		require.Len(t, result.Fixes, 2)
+		require.NotContains(t, result.Fixes, "vim-minimal")
+
+		software := []fleet.Software{
+			{ID: 1, Name: "curl", Version: "7.85.0", Release: "1.amzn2023.0.1", Source: "rpm_packages"},
+			{ID: 2, Name: "libcurl", Version: "7.87.0", Release: "2.amzn2023.0.2", Source: "rpm_packages"},
+			{ID: 3, Name: "curl", Version: "7.85.0", Source: "python_packages"},
+		}
+		vulns, err := result.Eval(fleet.OSVersion{Platform: "amzn", Name: "Amazon Linux 2023.0.20230315"}, software)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []fleet.SoftwareVulnerability{
+			{SoftwareID: 1, CVE: "CVE-2022-43551"},
+			{SoftwareID: 1, CVE: "CVE-2022-43552"},
+		}, vulns)
+	})
+}
+
+func TestAlpineSecDBParser(t *testing.T) {
+	secDBJSON := `{
+  "apkurl": "{{urlprefix}}/{{distroversion}}/{{reponame}}/{{arch}}/{{pkg.name}}-{{pkg.ver}}.apk",
+  "archs": ["aarch64", "x86_64"],
+  "reponame": "main",
+  "urlprefix": "https://dl-cdn.alpinelinux.org/alpine",
+  "distroversion": "v3.17",
+  "packages": [
+    {
+      "pkg": {
+        "name": "openssl",
+        "secfixes": {
+          "3.0.8-r0": ["CVE-2022-4203", "CVE-2023-0286"],
+          "3.0.7-r2": ["CVE-2022-3996"],
+          "0": ["CVE-2022-1292"]
+        }
+      }
+    },
+    {
+      "pkg": {
+        "name": "busybox",
+        "secfixes": {
+          "1.35.0-r17": ["CVE-2022-30065 ALPINE-13661"]
+        }
+      }
+    }
+  ]
+}`
+
+	t.Run("#processAlpineDef", func(t *testing.T) {
+		payload, err := processAlpineDef(strings.NewReader(secDBJSON))
+		require.NoError(t, err)
+
+		result := oval_parsed.AlpineResult{}
+		require.NoError(t, json.Unmarshal(payload, &result))
+		require.Equal(t, map[string][]oval_parsed.PackageFix{
+			"openssl": {
+				{FixedVersion: "3.0.7-r2", Vulnerabilities: []string{"CVE-2022-3996"}},
+				{FixedVersion: "3.0.8-r0", Vulnerabilities: []string{"CVE-2022-4203", "CVE-2023-0286"}},
+			},
+			"busybox": {
+				{FixedVersion: "1.35.0-r17", Vulnerabilities: []string{"CVE-2022-30065"}},
+			},
+		}, result.Fixes)
+	})
+
+	t.Run("Alpine secdb is evaluated against installed packages", func(t *testing.T) {
+		payload, err := processAlpineDef(strings.NewReader(secDBJSON))
+		require.NoError(t, err)
+
+		result := oval_parsed.AlpineResult{}
+		require.NoError(t, json.Unmarshal(payload, &result))
+
+		software := []fleet.Software{
+			{ID: 1, Name: "openssl", Version: "3.0.7-r3", Source: "apk_packages"},
+			{ID: 2, Name: "busybox", Version: "1.35.0-r29", Source: "apk_packages"},
+		}
+		vulns, err := result.Eval(fleet.OSVersion{Platform: "alpine", Name: "Alpine Linux 3.17.2"}, software)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []fleet.SoftwareVulnerability{
+			{SoftwareID: 1, CVE: "CVE-2022-4203"},
+			{SoftwareID: 1, CVE: "CVE-2023-0286"},
+		}, vulns)
+
+		// versions are compared using the apk rules, pre-releases are older than the fixed release
+		software = []fleet.Software{
+			{ID: 1, Name: "openssl", Version: "3.0.8_rc1-r0", Source: "apk_packages"},
+			{ID: 2, Name: "busybox", Version: "1.35.0_alpha1-r20", Source: "apk_packages"},
+			{ID: 3, Name: "openssl", Version: "3.0.8_p1-r0", Source: "apk_packages"},
+			{ID: 4, Name: "busybox", Version: "1.35.0-r16", Source: "apk_packages"},
+		}
+		vulns, err = result.Eval(fleet.OSVersion{Platform: "alpine", Name: "Alpine Linux 3.17.2"}, software)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []fleet.SoftwareVulnerability{
+			{SoftwareID: 1, CVE: "CVE-2022-4203"},
+			{SoftwareID: 1, CVE: "CVE-2023-0286"},
+			{SoftwareID: 2, CVE: "CVE-2022-30065"},
+			{SoftwareID: 4, CVE: "CVE-2022-30065"},
+		}, vulns)
+	})
+
+	t.Run("invalid secdb", func(t *testing.T) {
+		_, err := processAlpineDef(strings.NewReader("<xml/>"))
+		require.ErrorContains(t, err, "decoding secdb")
+	})
+}
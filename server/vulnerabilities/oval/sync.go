@@ -2,6 +2,8 @@ package oval
 
 import (
 	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +16,8 @@ import (
 	"github.com/fleetdm/fleet/v4/pkg/download"
 	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
 	"github.com/fleetdm/fleet/v4/server/fleet"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
 
 	"github.com/google/go-github/v37/github"
 )
@@ -42,10 +46,84 @@ func downloadDecompressed(client *http.Client) func(string, string) error {
 		if err != nil {
 			return err
 		}
+		// The Amazon Linux 2023 advisories are located through the mirror list of its repository
+		if strings.HasSuffix(parsedUrl.Path, "/mirror.list") {
+			if parsedUrl, err = resolveUpdateInfoURL(client, parsedUrl); err != nil {
+				return err
+			}
+		}
+		// Some definitions (like the Alpine secdb) are published uncompressed
+		if strings.HasSuffix(parsedUrl.Path, ".json") || strings.HasSuffix(parsedUrl.Path, ".xml") {
+			return download.Download(client, parsedUrl, dstPath)
+		}
 		return download.DownloadAndExtract(client, parsedUrl, dstPath)
 	}
 }
 
+// resolveUpdateInfoURL returns the URL of the 'updateinfo.xml' metadata, which contains the
+// security advisories, of the RPM repository listed first in the mirror list.
+func resolveUpdateInfoURL(client *http.Client, mirrorList *url.URL) (*url.URL, error) {
+	list, err := getContents(client, mirrorList)
+	if err != nil {
+		return nil, fmt.Errorf("get mirror list: %w", err)
+	}
+	var mirror string
+	for _, line := range strings.Split(string(list), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			mirror = line
+			break
+		}
+	}
+	if mirror == "" {
+		return nil, errors.New("empty mirror list")
+	}
+	if !strings.HasSuffix(mirror, "/") {
+		mirror += "/"
+	}
+	baseURL, err := mirrorList.Parse(mirror)
+	if err != nil {
+		return nil, fmt.Errorf("parse mirror: %w", err)
+	}
+
+	repomdURL, err := baseURL.Parse("repodata/repomd.xml")
+	if err != nil {
+		return nil, err
+	}
+	contents, err := getContents(client, repomdURL)
+	if err != nil {
+		return nil, fmt.Errorf("get repomd.xml: %w", err)
+	}
+	var repomd struct {
+		Data []struct {
+			Type     string `xml:"type,attr"`
+			Location struct {
+				Href string `xml:"href,attr"`
+			} `xml:"location"`
+		} `xml:"data"`
+	}
+	if err := xml.Unmarshal(contents, &repomd); err != nil {
+		return nil, fmt.Errorf("parse repomd.xml: %w", err)
+	}
+	for _, d := range repomd.Data {
+		if d.Type == "updateinfo" && d.Location.Href != "" {
+			return baseURL.Parse(d.Location.Href)
+		}
+	}
+	return nil, errors.New("updateinfo not found in repomd.xml")
+}
+
+func getContents(client *http.Client, u *url.URL) ([]byte, error) {
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
 func whatToDownload(osVers *fleet.OSVersions, existing map[string]bool, date time.Time) []Platform {
 	var r []Platform
 	for _, os := range osVers.OSVersions {
@@ -85,11 +163,12 @@ func removeOldDefs(date time.Time, path string) (map[string]bool, error) {
 }
 
 // Sync syncs the oval definitions for one or more platforms.
-// If 'platforms' is nil, then all supported platforms will be synched.
-func Sync(dstDir string, platforms []Platform) error {
+// If 'platforms' is nil, then all supported platforms will be synched. A platform whose
+// definitions cannot be synched is logged and skipped, the synched platforms are returned.
+func Sync(dstDir string, platforms []Platform, logger kitlog.Logger) ([]Platform, error) {
 	sources, err := getOvalSources(ghNvdFileGetter())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if platforms == nil {
@@ -100,25 +179,27 @@ func Sync(dstDir string, platforms []Platform) error {
 
 	client := fleethttp.NewClient()
 	dwn := downloadDecompressed(client)
+	var synched []Platform
 	for _, platform := range platforms {
-		defFile, err := downloadDefinitions(sources, platform, dwn)
-		if err != nil {
-			return err
-		}
-
-		dstFile := strings.Replace(filepath.Base(defFile), ".xml", ".json", 1)
-		dstPath := filepath.Join(dstDir, dstFile)
-		err = parseDefinitions(platform, defFile, dstPath)
-		if err != nil {
-			return err
+		if err := syncPlatform(dstDir, sources, platform, dwn); err != nil {
+			level.Error(logger).Log("msg", "sync oval definitions", "platform", platform, "err", err)
+			continue
 		}
+		synched = append(synched, platform)
+	}
+	return synched, nil
+}
 
-		err = os.Remove(defFile)
-		if err != nil {
-			return err
-		}
+func syncPlatform(dstDir string, sources OvalSources, platform Platform, dwn func(string, string) error) error {
+	defFile, err := downloadDefinitions(sources, platform, dwn)
+	if err != nil {
+		return err
 	}
-	return nil
+	defer os.Remove(defFile)
+
+	dstFile := strings.Replace(filepath.Base(defFile), ".xml", ".json", 1)
+	dstPath := filepath.Join(dstDir, dstFile)
+	return parseDefinitions(platform, defFile, dstPath)
 }
 
 // Refresh checks all local OVAL artifacts contained in 'vulnPath' deleting the old and downloading
@@ -128,6 +209,7 @@ func Refresh(
 	ctx context.Context,
 	versions *fleet.OSVersions,
 	vulnPath string,
+	logger kitlog.Logger,
 ) ([]Platform, error) {
 	now := time.Now()
 
@@ -137,12 +219,8 @@ func Refresh(
 	}
 
 	toDownload := whatToDownload(versions, existing, now)
-	if len(toDownload) > 0 {
-		err = Sync(vulnPath, toDownload)
-		if err != nil {
-			return nil, err
-		}
+	if len(toDownload) == 0 {
+		return nil, nil
 	}
-
-	return toDownload, nil
+	return Sync(vulnPath, toDownload, logger)
 }
@@ -1,6 +1,9 @@
 package oval
 
 import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -93,3 +96,38 @@ func TestSync(t *testing.T) {
 		require.NotContains(t, r, NewPlatform("rhle", "CentOS Linux 8.3.2011"))
 	})
 }
+
+func TestDownloadAmazonLinux2023Advisories(t *testing.T) {
+	const updateInfo = `<?xml version="1.0" encoding="UTF-8"?><updates></updates>`
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mirrors/latest/x86_64/mirror.list":
+			_, _ = w.Write([]byte("\n" + srvURL + "/guids/abc/x86_64\n"))
+		case "/guids/abc/x86_64/repodata/repomd.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<repomd xmlns="http://linux.duke.edu/metadata/repo">
+  <data type="primary"><location href="repodata/primary.xml.gz"/></data>
+  <data type="updateinfo"><location href="repodata/updateinfo.xml.gz"/></data>
+</repomd>`))
+		case "/guids/abc/x86_64/repodata/updateinfo.xml.gz":
+			gw := gzip.NewWriter(w)
+			_, _ = gw.Write([]byte(updateInfo))
+			_ = gw.Close()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	dst := filepath.Join(t.TempDir(), "updateinfo.xml")
+	err := downloadDecompressed(srv.Client())(srv.URL+"/mirrors/latest/x86_64/mirror.list", dst)
+	require.NoError(t, err)
+	contents, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, updateInfo, string(contents))
+
+	err = downloadDecompressed(srv.Client())(srv.URL+"/missing/mirror.list", dst)
+	require.ErrorContains(t, err, "get mirror list")
+}
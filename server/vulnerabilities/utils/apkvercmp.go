@@ -0,0 +1,176 @@
+package utils
+
+import "strings"
+
+// apkSuffixes lists the apk version suffixes from the lowest to the highest, no suffix (at index
+// apkNoSuffix) sits between the pre-release and the post-release suffixes, so that e.g.
+// '1.0_rc1' < '1.0' < '1.0_p1'.
+var apkSuffixes = []string{"alpha", "beta", "pre", "rc", "", "cvs", "svn", "git", "hg", "p"}
+
+const apkNoSuffix = 4
+
+type apkSuffix struct {
+	rank   int
+	number string
+}
+
+type apkVersion struct {
+	numbers  []string
+	letter   byte
+	suffixes []apkSuffix
+	hash     string
+	revision string
+}
+
+// parseApkVersion parses an apk version, made of dot separated numbers, an optional letter, zero
+// or more '_<suffix><number>' suffixes, an optional '~<hash>' and an optional '-r<revision>',
+// e.g. '1.2.3a_rc1_p2~abc-r4'.
+func parseApkVersion(s string) (apkVersion, bool) {
+	var v apkVersion
+
+	if i := strings.LastIndex(s, "-r"); i >= 0 {
+		if !isDigits(s[i+2:]) {
+			return v, false
+		}
+		v.revision = s[i+2:]
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '~'); i >= 0 {
+		v.hash = s[i+1:]
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, "_")
+	version := parts[0]
+	if n := len(version); n > 0 && version[n-1] >= 'a' && version[n-1] <= 'z' {
+		v.letter = version[n-1]
+		version = version[:n-1]
+	}
+	for _, n := range strings.Split(version, ".") {
+		if !isDigits(n) {
+			return v, false
+		}
+		v.numbers = append(v.numbers, n)
+	}
+
+	for _, p := range parts[1:] {
+		name := strings.TrimRight(p, "0123456789")
+		rank := -1
+		for i, suffix := range apkSuffixes {
+			if suffix != "" && suffix == name {
+				rank = i
+				break
+			}
+		}
+		if rank == -1 {
+			return v, false
+		}
+		v.suffixes = append(v.suffixes, apkSuffix{rank: rank, number: p[len(name):]})
+	}
+
+	return v, true
+}
+
+// Apkvercmp compares two apk package versions (e.g. '1.2.3_rc1-r0') following the rules of
+// apk-tools:
+//   - The dot separated numbers are compared in order, numbers after the first one with a
+//     leading zero are compared as decimal fractions, a version with more numbers is newer.
+//   - The letters are compared, a version without letter is older.
+//   - The suffixes are compared in order, the pre-release suffixes (_alpha, _beta, _pre and _rc)
+//     are older and the post-release suffixes (_cvs, _svn, _git, _hg and _p) newer than no
+//     suffix. Equal suffixes are compared by their number.
+//   - The hashes are compared as strings, then the '-r' package revisions as numbers.
+//
+// Versions that cannot be parsed are compared using Rpmvercmp.
+//
+// Returns:
+//
+//	-1 if a < b
+//	0 if a == b
+//	1 if a > b
+func Apkvercmp(a, b string) int {
+	va, okA := parseApkVersion(a)
+	vb, okB := parseApkVersion(b)
+	if !okA || !okB {
+		return Rpmvercmp(a, b)
+	}
+
+	for i := 0; i < len(va.numbers) && i < len(vb.numbers); i++ {
+		var r int
+		if i > 0 && (strings.HasPrefix(va.numbers[i], "0") || strings.HasPrefix(vb.numbers[i], "0")) {
+			r = compareFractions(va.numbers[i], vb.numbers[i])
+		} else {
+			r = compareNumbers(va.numbers[i], vb.numbers[i])
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	if r := compareInts(len(va.numbers), len(vb.numbers)); r != 0 {
+		return r
+	}
+
+	if r := compareInts(int(va.letter), int(vb.letter)); r != 0 {
+		return r
+	}
+
+	for i := 0; i < len(va.suffixes) || i < len(vb.suffixes); i++ {
+		sa, sb := apkSuffix{rank: apkNoSuffix}, apkSuffix{rank: apkNoSuffix}
+		if i < len(va.suffixes) {
+			sa = va.suffixes[i]
+		}
+		if i < len(vb.suffixes) {
+			sb = vb.suffixes[i]
+		}
+		if r := compareInts(sa.rank, sb.rank); r != 0 {
+			return r
+		}
+		if r := compareNumbers(sa.number, sb.number); r != 0 {
+			return r
+		}
+	}
+
+	if r := strings.Compare(va.hash, vb.hash); r != 0 {
+		return r
+	}
+
+	return compareNumbers(va.revision, vb.revision)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareNumbers compares two strings of digits by their numeric value, an empty string is
+// treated as 0.
+func compareNumbers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if r := compareInts(len(a), len(b)); r != 0 {
+		return r
+	}
+	return strings.Compare(a, b)
+}
+
+// compareFractions compares two strings of digits as the decimal part of a number, e.g. '05' <
+// '1' as 0.05 < 0.1.
+func compareFractions(a, b string) int {
+	return strings.Compare(strings.TrimRight(a, "0"), strings.TrimRight(b, "0"))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApkvercmp(t *testing.T) {
+	const (
+		LESS    = -1
+		EQUAL   = 0
+		GREATER = 1
+	)
+
+	cases := []struct {
+		a        string
+		expected int
+		b        string
+	}{
+		{"1.2.3-r0", EQUAL, "1.2.3-r0"},
+		{"1.2.3", EQUAL, "1.2.3-r0"},
+		{"1.2.3-r0", LESS, "1.2.4-r0"},
+		{"1.2.10-r0", GREATER, "1.2.9-r0"},
+		{"1.2-r0", LESS, "1.2.0-r0"},
+		{"2.0-r0", GREATER, "1.99.99-r9"},
+
+		// package revisions
+		{"1.2.3-r1", GREATER, "1.2.3-r0"},
+		{"1.2.3-r10", GREATER, "1.2.3-r9"},
+		{"1.2.3-r9", LESS, "1.2.4-r0"},
+
+		// pre-release suffixes are older than the release
+		{"1.2.3_rc1-r0", LESS, "1.2.3-r0"},
+		{"1.2.3_rc1-r5", LESS, "1.2.3-r0"},
+		{"1.2.3_rc2-r0", GREATER, "1.2.3_rc1-r0"},
+		{"1.2.3_rc10-r0", GREATER, "1.2.3_rc9-r0"},
+		{"1.2.3_alpha1-r0", LESS, "1.2.3-r0"},
+		{"1.2.3_alpha-r0", LESS, "1.2.3_alpha1-r0"},
+		{"1.2.3_beta1-r0", LESS, "1.2.3-r0"},
+		{"1.2.3_pre1-r0", LESS, "1.2.3-r0"},
+		{"1.2.3_alpha2-r0", LESS, "1.2.3_beta1-r0"},
+		{"1.2.3_beta2-r0", LESS, "1.2.3_pre1-r0"},
+		{"1.2.3_pre2-r0", LESS, "1.2.3_rc1-r0"},
+		{"1.2.3_rc1-r0", GREATER, "1.2.2-r9"},
+
+		// post-release suffixes are newer than the release
+		{"1.2.3_p1-r0", GREATER, "1.2.3-r0"},
+		{"1.2.3_p1-r0", GREATER, "1.2.3-r9"},
+		{"1.2.3_p2-r0", GREATER, "1.2.3_p1-r0"},
+		{"1.2.3_p1-r0", LESS, "1.2.4-r0"},
+		{"3.0.8_p1-r0", GREATER, "3.0.8-r0"},
+		{"1.2.3_git20230101-r0", GREATER, "1.2.3-r0"},
+		{"1.2.3_rc1_p1-r0", LESS, "1.2.3-r0"},
+		{"1.2.3_rc1_p1-r0", GREATER, "1.2.3_rc1-r0"},
+
+		// letters
+		{"1.2.3a-r0", GREATER, "1.2.3-r0"},
+		{"1.2.3b-r0", GREATER, "1.2.3a-r0"},
+		{"1.2.3a-r0", LESS, "1.2.4-r0"},
+
+		// numbers with a leading zero are compared as fractions
+		{"1.05-r0", LESS, "1.1-r0"},
+		{"1.010-r0", EQUAL, "1.01-r0"},
+		{"01.2-r0", EQUAL, "1.2-r0"},
+
+		// invalid versions fall back to rpmvercmp
+		{"1.2.3-foo", LESS, "1.2.4-foo"},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.expected, Apkvercmp(c.a, c.b), "%s <=> %s", c.a, c.b)
+		require.Equal(t, -c.expected, Apkvercmp(c.b, c.a), "%s <=> %s", c.b, c.a)
+	}
+}
@@ -119,6 +119,7 @@ func TestTriggerFailingPoliciesWebhookBasic(t *testing.T) {
         "team_id": null,
         "resolution": "policy1 resolution",
         "platform": "darwin",
+        "severity": "",
         "created_at": "0001-01-01T00:00:00Z",
         "updated_at": "0001-01-01T00:00:00Z",
         "passing_host_count": 0,
@@ -303,6 +304,7 @@ func TestTriggerFailingPoliciesWebhookTeam(t *testing.T) {
         "team_id": 1,
         "resolution": "policy1 resolution",
         "platform": "darwin",
+        "severity": "",
         "created_at": "0001-01-01T00:00:00Z",
         "updated_at": "0001-01-01T00:00:00Z",
         "passing_host_count": 0,
@@ -43,9 +43,11 @@ func TriggerVulnerabilitiesWebhook(
 	}
 
 	for cve, sIDs := range groups {
-		hosts, err := ds.HostsBySoftwareIDs(ctx, sIDs)
+		// the hosts on which the CVE is suppressed by a vulnerability exception
+		// are excluded.
+		hosts, err := ds.VulnerableHostsBySoftwareIDs(ctx, sIDs, cve)
 		if err != nil {
-			return ctxerr.Wrap(ctx, err, "get hosts by software ids")
+			return ctxerr.Wrap(ctx, err, "get vulnerable hosts by software ids")
 		}
 
 		for len(hosts) > 0 {
@@ -178,7 +178,7 @@ func TestTriggerVulnerabilitiesWebhook(t *testing.T) {
 				}))
 				defer srv.Close()
 
-				ds.HostsBySoftwareIDsFunc = func(ctx context.Context, softwareIDs []uint) ([]*fleet.HostShort, error) {
+				ds.VulnerableHostsBySoftwareIDsFunc = func(ctx context.Context, softwareIDs []uint, cve string) ([]*fleet.HostShort, error) {
 					return c.hosts, nil
 				}
 
@@ -194,8 +194,8 @@ func TestTriggerVulnerabilitiesWebhook(t *testing.T) {
 				err := TriggerVulnerabilitiesWebhook(ctx, ds, logger, args, &mapper)
 				require.NoError(t, err)
 
-				assert.True(t, ds.HostsBySoftwareIDsFuncInvoked)
-				ds.HostsBySoftwareIDsFuncInvoked = false
+				assert.True(t, ds.VulnerableHostsBySoftwareIDsFuncInvoked)
+				ds.VulnerableHostsBySoftwareIDsFuncInvoked = false
 
 				want := strings.Split(c.want, "\n")
 				assert.ElementsMatch(t, want, requests)
@@ -0,0 +1,132 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+type slaBreachHostPayloadPart struct {
+	ID              uint      `json:"id"`
+	Hostname        string    `json:"hostname"`
+	DisplayName     string    `json:"display_name"`
+	URL             string    `json:"url"`
+	FirstDetectedAt time.Time `json:"first_detected_at"`
+	SLADueAt        time.Time `json:"sla_due_at"`
+}
+
+// SLABreachPayload is the payload sent to the vulnerabilities webhook for the
+// hosts on which a CVE is still detected past its remediation SLA.
+type SLABreachPayload struct {
+	CVE       string                      `json:"cve"`
+	Link      string                      `json:"details_link"`
+	Severity  fleet.CVESeverity           `json:"severity"`
+	CVSSScore *float64                    `json:"cvss_score"`
+	SLADays   int                         `json:"sla_days"`
+	Hosts     []*slaBreachHostPayloadPart `json:"hosts_affected"`
+}
+
+// TriggerVulnerabilitySLABreachesWebhook sends the remediation SLA breaches
+// that were not notified yet to the vulnerabilities webhook, one request per
+// CVE (and batch of hosts), and records them as notified.
+func TriggerVulnerabilitySLABreachesWebhook(
+	ctx context.Context,
+	ds fleet.Datastore,
+	logger kitlog.Logger,
+	appConfig *fleet.AppConfig,
+	now time.Time,
+) error {
+	vulnConfig := appConfig.WebhookSettings.VulnerabilitiesWebhook
+	if !vulnConfig.Enable {
+		return nil
+	}
+
+	serverURL, err := url.Parse(appConfig.ServerSettings.ServerURL)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "invalid server url")
+	}
+
+	sla := appConfig.VulnerabilitySettings.RemediationSLA
+	breaches, err := ds.ListUnnotifiedVulnerabilitySLABreaches(ctx, sla, now)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list unnotified vulnerability sla breaches")
+	}
+	level.Debug(logger).Log("slaBreaches", len(breaches))
+
+	batchSize := vulnConfig.HostBatchSize
+	for len(breaches) > 0 {
+		// breaches are ordered by CVE, take those of the next CVE
+		n := 1
+		for n < len(breaches) && breaches[n].CVE == breaches[0].CVE {
+			n++
+		}
+		cveBreaches := breaches[:n]
+		breaches = breaches[n:]
+
+		for len(cveBreaches) > 0 {
+			limit := len(cveBreaches)
+			if batchSize > 0 && len(cveBreaches) > batchSize {
+				limit = batchSize
+			}
+			batch := cveBreaches[:limit]
+			cveBreaches = cveBreaches[limit:]
+
+			payload := map[string]interface{}{
+				"timestamp":  now,
+				"sla_breach": slaBreachPayload(serverURL, sla, batch),
+			}
+			if err := server.PostJSONWithTimeout(ctx, vulnConfig.DestinationURL, &payload); err != nil {
+				return ctxerr.Wrapf(ctx, err, "posting to %s", vulnConfig.DestinationURL)
+			}
+
+			ids := make([]uint, 0, len(batch))
+			for _, b := range batch {
+				ids = append(ids, b.ID)
+			}
+			if err := ds.MarkVulnerabilitySLABreachesNotified(ctx, ids, now); err != nil {
+				return ctxerr.Wrap(ctx, err, "mark vulnerability sla breaches notified")
+			}
+		}
+	}
+
+	return nil
+}
+
+func slaBreachPayload(hostBaseURL *url.URL, sla fleet.VulnerabilityRemediationSLA, breaches []*fleet.HostVulnerabilityRemediation) SLABreachPayload {
+	first := breaches[0]
+	hosts := make([]*slaBreachHostPayloadPart, 0, len(breaches))
+	for _, b := range breaches {
+		hostURL := *hostBaseURL
+		hostURL.Path = path.Join(hostURL.Path, "hosts", strconv.Itoa(int(b.HostID)))
+
+		part := &slaBreachHostPayloadPart{
+			ID:              b.HostID,
+			Hostname:        b.HostHostname,
+			DisplayName:     b.HostDisplayName,
+			URL:             hostURL.String(),
+			FirstDetectedAt: b.FirstDetectedAt,
+		}
+		if b.SLADueAt != nil {
+			part.SLADueAt = *b.SLADueAt
+		}
+		hosts = append(hosts, part)
+	}
+
+	return SLABreachPayload{
+		CVE:       first.CVE,
+		Link:      fmt.Sprintf("https://nvd.nist.gov/vuln/detail/%s", first.CVE),
+		Severity:  first.Severity,
+		CVSSScore: first.CVSSScore,
+		SLADays:   sla.Days(first.Severity),
+		Hosts:     hosts,
+	}
+}
@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerVulnerabilitySLABreachesWebhook(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+	logger := kitlog.NewNopLogger()
+
+	var mu sync.Mutex
+	var requests []map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(b, &payload))
+		mu.Lock()
+		requests = append(requests, payload)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sla := fleet.VulnerabilityRemediationSLA{CriticalDays: 7}
+	appCfg := &fleet.AppConfig{
+		WebhookSettings: fleet.WebhookSettings{
+			VulnerabilitiesWebhook: fleet.VulnerabilitiesWebhookSettings{
+				Enable:         true,
+				DestinationURL: srv.URL,
+				HostBatchSize:  2,
+			},
+		},
+		ServerSettings: fleet.ServerSettings{
+			ServerURL: "https://fleet.example.com",
+		},
+		VulnerabilitySettings: fleet.VulnerabilitySettings{
+			RemediationSLA: sla,
+		},
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	detected := now.Add(-10 * 24 * time.Hour)
+	due := detected.Add(7 * 24 * time.Hour)
+	breach := func(id, hostID uint, cve string) *fleet.HostVulnerabilityRemediation {
+		return &fleet.HostVulnerabilityRemediation{
+			ID:              id,
+			HostID:          hostID,
+			HostHostname:    fmt.Sprintf("h%d", hostID),
+			HostDisplayName: fmt.Sprintf("d%d", hostID),
+			CVE:             cve,
+			CVSSScore:       ptr.Float64(9.8),
+			Severity:        fleet.CVESeverityCritical,
+			FirstDetectedAt: detected,
+			SLADueAt:        &due,
+			SLABreached:     true,
+		}
+	}
+
+	ds.ListUnnotifiedVulnerabilitySLABreachesFunc = func(ctx context.Context, gotSLA fleet.VulnerabilityRemediationSLA, gotNow time.Time) ([]*fleet.HostVulnerabilityRemediation, error) {
+		require.Equal(t, sla, gotSLA)
+		require.Equal(t, now, gotNow)
+		return []*fleet.HostVulnerabilityRemediation{
+			breach(1, 1, "CVE-2022-0001"),
+			breach(2, 2, "CVE-2022-0001"),
+			breach(3, 3, "CVE-2022-0001"),
+			breach(4, 1, "CVE-2022-0002"),
+		}, nil
+	}
+	var notified [][]uint
+	ds.MarkVulnerabilitySLABreachesNotifiedFunc = func(ctx context.Context, ids []uint, gotNow time.Time) error {
+		notified = append(notified, ids)
+		return nil
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		appCfg := *appCfg
+		appCfg.WebhookSettings.VulnerabilitiesWebhook.Enable = false
+		err := TriggerVulnerabilitySLABreachesWebhook(ctx, ds, logger, &appCfg, now)
+		require.NoError(t, err)
+		require.False(t, ds.ListUnnotifiedVulnerabilitySLABreachesFuncInvoked)
+	})
+
+	t.Run("trigger requests", func(t *testing.T) {
+		err := TriggerVulnerabilitySLABreachesWebhook(ctx, ds, logger, appCfg, now)
+		require.NoError(t, err)
+
+		// one request per CVE and batch of 2 hosts
+		require.Len(t, requests, 3)
+		require.Equal(t, [][]uint{{1, 2}, {3}, {4}}, notified)
+
+		var got SLABreachPayload
+		require.NoError(t, json.Unmarshal(requests[0]["sla_breach"], &got))
+		require.Equal(t, "CVE-2022-0001", got.CVE)
+		require.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2022-0001", got.Link)
+		require.Equal(t, fleet.CVESeverityCritical, got.Severity)
+		require.Equal(t, 9.8, *got.CVSSScore)
+		require.Equal(t, 7, got.SLADays)
+		require.Len(t, got.Hosts, 2)
+		require.Equal(t, uint(1), got.Hosts[0].ID)
+		require.Equal(t, "h1", got.Hosts[0].Hostname)
+		require.Equal(t, "d1", got.Hosts[0].DisplayName)
+		require.Equal(t, "https://fleet.example.com/hosts/1", got.Hosts[0].URL)
+		require.True(t, detected.Equal(got.Hosts[0].FirstDetectedAt))
+		require.True(t, due.Equal(got.Hosts[0].SLADueAt))
+
+		require.NoError(t, json.Unmarshal(requests[2]["sla_breach"], &got))
+		require.Equal(t, "CVE-2022-0002", got.CVE)
+		require.Len(t, got.Hosts, 1)
+	})
+}
@@ -0,0 +1,288 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fleetdm/fleet/v4/pkg/fleethttp"
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// activityWebhookName is the name of the job as registered in the worker.
+const activityWebhookName = "activity_webhook"
+
+const (
+	// ActivityWebhookSignatureHeader is the header that holds the HMAC-SHA256
+	// signature of the request body, computed with the webhook's secret.
+	ActivityWebhookSignatureHeader = "X-Fleet-Signature"
+	// ActivityWebhookEventHeader is the header that holds the activity type.
+	ActivityWebhookEventHeader = "X-Fleet-Event"
+	// ActivityWebhookDeliveryHeader is the header that holds the ID of the
+	// delivery, which is the same for all attempts of a given delivery.
+	ActivityWebhookDeliveryHeader = "X-Fleet-Delivery"
+)
+
+// activityWebhookRetryDelays are the delays to wait before retrying a failed
+// delivery, indexed by the number of retries already made.
+var activityWebhookRetryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+}
+
+// ActivityWebhook is the job processor for the delivery of activities to the
+// activities webhooks.
+type ActivityWebhook struct {
+	Datastore fleet.Datastore
+	Log       kitlog.Logger
+	// Client is the HTTP client used to send the requests, a default client is
+	// used if nil.
+	Client *http.Client
+}
+
+// Name returns the name of the job.
+func (a *ActivityWebhook) Name() string {
+	return activityWebhookName
+}
+
+// RetryDelay implements RetryDelayer, it returns an increasing delay to wait
+// before retrying a failed delivery.
+func (a *ActivityWebhook) RetryDelay(retries int) time.Duration {
+	if retries >= len(activityWebhookRetryDelays) {
+		return activityWebhookRetryDelays[len(activityWebhookRetryDelays)-1]
+	}
+	return activityWebhookRetryDelays[retries]
+}
+
+// activityWebhookArgs are the arguments for the activity webhook job.
+type activityWebhookArgs struct {
+	DeliveryID uint           `json:"delivery_id"`
+	Activity   fleet.Activity `json:"activity"`
+}
+
+// Run executes the activity webhook job.
+func (a *ActivityWebhook) Run(ctx context.Context, argsJSON json.RawMessage) error {
+	delivery, err := a.run(ctx, argsJSON)
+	if delivery != nil {
+		if uerr := a.Datastore.UpdateActivityWebhookDelivery(ctx, delivery); uerr != nil {
+			if err != nil {
+				level.Error(a.Log).Log("msg", "update activity webhook delivery", "delivery_id", delivery.ID, "err", uerr)
+				return err
+			}
+			return ctxerr.Wrap(ctx, uerr, "update activity webhook delivery")
+		}
+	}
+	return err
+}
+
+// RunWithUpdate implements UpdatingJob, it executes the activity webhook job
+// and returns a function that records the attempt in the delivery in the same
+// transaction as the update of the job.
+func (a *ActivityWebhook) RunWithUpdate(ctx context.Context, argsJSON json.RawMessage) (JobUpdateFunc, error) {
+	delivery, err := a.run(ctx, argsJSON)
+	if delivery == nil {
+		return nil, err
+	}
+	return func(ctx context.Context, job *fleet.Job) error {
+		return a.Datastore.UpdateActivityWebhookDeliveryAndJob(ctx, delivery, job)
+	}, err
+}
+
+// run executes the activity webhook job, it returns the delivery to update, if
+// any, and the error of the delivery attempt.
+func (a *ActivityWebhook) run(ctx context.Context, argsJSON json.RawMessage) (*fleet.ActivityWebhookDelivery, error) {
+	var args activityWebhookArgs
+	if err := json.Unmarshal(argsJSON, &args); err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "unmarshal args")
+	}
+
+	delivery, err := a.Datastore.ActivityWebhookDelivery(ctx, args.DeliveryID)
+	if err != nil {
+		if fleet.IsNotFound(err) {
+			// the activity (and its deliveries) has been deleted, nothing to do.
+			return nil, nil
+		}
+		return nil, ctxerr.Wrap(ctx, err, "get activity webhook delivery")
+	}
+	if delivery.Status != fleet.ActivityWebhookDeliveryPending {
+		return nil, nil
+	}
+
+	ac, err := a.Datastore.AppConfig(ctx)
+	if err != nil {
+		return nil, ctxerr.Wrap(ctx, err, "get app config")
+	}
+	var webhook *fleet.ActivityWebhookSettings
+	for _, w := range ac.WebhookSettings.ActivitiesWebhooks {
+		if w.DestinationURL == delivery.DestinationURL {
+			webhook = w
+			break
+		}
+	}
+	if webhook == nil {
+		// this delivery was queued when the webhook was configured, but it has
+		// since been removed, so mark the delivery as failed and the job as
+		// processed.
+		delivery.Status = fleet.ActivityWebhookDeliveryFailed
+		delivery.Error = ptr.String("webhook no longer configured")
+		return delivery, nil
+	}
+
+	statusCode, sendErr := a.send(ctx, webhook, delivery, &args.Activity)
+
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+	if statusCode > 0 {
+		delivery.ResponseStatus = ptr.Int(statusCode)
+	}
+	delivery.Error = nil
+	switch {
+	case sendErr == nil:
+		delivery.Status = fleet.ActivityWebhookDeliverySuccess
+	case delivery.Attempts > maxRetries:
+		// the worker will not retry this job anymore
+		delivery.Status = fleet.ActivityWebhookDeliveryFailed
+		delivery.Error = ptr.String(sendErr.Error())
+	default:
+		delivery.Error = ptr.String(sendErr.Error())
+	}
+
+	if sendErr != nil {
+		return delivery, ctxerr.Wrapf(ctx, sendErr, "deliver activity %d to webhook", args.Activity.ID)
+	}
+	level.Debug(a.Log).Log(
+		"msg", "delivered activity to webhook",
+		"activity_id", args.Activity.ID,
+		"delivery_id", delivery.ID,
+	)
+	return delivery, nil
+}
+
+// send posts the activity to the webhook, it returns the HTTP status code of
+// the response if a response was received.
+func (a *ActivityWebhook) send(ctx context.Context, webhook *fleet.ActivityWebhookSettings, delivery *fleet.ActivityWebhookDelivery, activity *fleet.Activity) (int, error) {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "marshal activity")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.DestinationURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, ctxerr.Wrap(ctx, err, "create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(ActivityWebhookEventHeader, activity.Type)
+	req.Header.Set(ActivityWebhookDeliveryHeader, fmt.Sprint(delivery.ID))
+	if webhook.Secret != "" {
+		req.Header.Set(ActivityWebhookSignatureHeader, "sha256="+SignActivityWebhookPayload(webhook.Secret, body))
+	}
+
+	client := a.Client
+	if client == nil {
+		client = fleethttp.NewClient(fleethttp.WithTimeout(30 * time.Second))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to POST to %s: %w", webhook.DestinationURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("error posting to %s: %d. %s", webhook.DestinationURL, resp.StatusCode, string(respBody))
+	}
+	return resp.StatusCode, nil
+}
+
+// SignActivityWebhookPayload returns the hex-encoded HMAC-SHA256 signature of
+// the payload using the provided secret. This is the value sent in the
+// signature header (prefixed with "sha256=") so that receivers can verify the
+// authenticity of the payload.
+func SignActivityWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) //nolint:errcheck // never returns an error
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ActivitiesWebhooksBatchCount is the number of activities processed in each
+// batch by QueueActivityWebhookJobs.
+var ActivitiesWebhooksBatchCount uint = 500
+
+// QueueActivityWebhookJobs creates a delivery and queues an activity webhook
+// job for each activity that has not been processed yet and each configured
+// activities webhook that subscribes to that activity's type. Activities are
+// marked as processed even if no webhook is configured, so that only new
+// activities are sent to webhooks added later on.
+func QueueActivityWebhookJobs(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger) error {
+	ac, err := ds.AppConfig(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get app config")
+	}
+	webhooks := ac.WebhookSettings.ActivitiesWebhooks
+
+	for {
+		activities, _, err := ds.ListActivities(ctx, fleet.ListActivitiesOptions{
+			ListOptions: fleet.ListOptions{
+				OrderKey:       "id",
+				OrderDirection: fleet.OrderAscending,
+				PerPage:        ActivitiesWebhooksBatchCount,
+			},
+			WebhooksProcessed: ptr.Bool(false),
+		})
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "list activities")
+		}
+		if len(activities) == 0 {
+			return nil
+		}
+
+		var queued int
+		ids := make([]uint, 0, len(activities))
+		for _, act := range activities {
+			for _, w := range webhooks {
+				if !w.MatchesActivityType(act.Type) {
+					continue
+				}
+				delivery, err := ds.NewActivityWebhookDelivery(ctx, &fleet.ActivityWebhookDelivery{
+					ActivityID:     act.ID,
+					ActivityType:   act.Type,
+					DestinationURL: w.DestinationURL,
+				})
+				if err != nil {
+					return ctxerr.Wrap(ctx, err, "create activity webhook delivery")
+				}
+				if _, err := QueueJob(ctx, ds, activityWebhookName, activityWebhookArgs{
+					DeliveryID: delivery.ID,
+					Activity:   *act,
+				}); err != nil {
+					return ctxerr.Wrap(ctx, err, "queueing job")
+				}
+				queued++
+			}
+			ids = append(ids, act.ID)
+		}
+
+		if err := ds.MarkActivitiesAsWebhooksProcessed(ctx, ids); err != nil {
+			return ctxerr.Wrap(ctx, err, "mark activities as webhooks processed")
+		}
+		level.Debug(logger).Log("msg", "queued activity webhook jobs", "activities", len(ids), "jobs", queued)
+
+		if len(activities) < int(ActivitiesWebhooksBatchCount) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityWebhookRun(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+
+	var (
+		gotBody    []byte
+		gotHeaders http.Header
+		respStatus int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		gotBody = b
+		gotHeaders = r.Header
+		w.WriteHeader(respStatus)
+	}))
+	defer srv.Close()
+
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{WebhookSettings: fleet.WebhookSettings{
+			ActivitiesWebhooks: []*fleet.ActivityWebhookSettings{
+				{DestinationURL: srv.URL, Secret: "s3cr3t"},
+			},
+		}}, nil
+	}
+	delivery := &fleet.ActivityWebhookDelivery{
+		ID:             10,
+		ActivityID:     1,
+		ActivityType:   "created_pack",
+		DestinationURL: srv.URL,
+		Status:         fleet.ActivityWebhookDeliveryPending,
+	}
+	ds.ActivityWebhookDeliveryFunc = func(ctx context.Context, id uint) (*fleet.ActivityWebhookDelivery, error) {
+		require.Equal(t, delivery.ID, id)
+		d := *delivery
+		return &d, nil
+	}
+	ds.UpdateActivityWebhookDeliveryFunc = func(ctx context.Context, d *fleet.ActivityWebhookDelivery) error {
+		delivery = d
+		return nil
+	}
+
+	job := &ActivityWebhook{Datastore: ds, Log: kitlog.NewNopLogger()}
+	args := json.RawMessage(`{"delivery_id":10,"activity":{"id":1,"type":"created_pack","details":{"pack_id":2}}}`)
+
+	// failed delivery is retried
+	respStatus = http.StatusInternalServerError
+	err := job.Run(ctx, args)
+	require.Error(t, err)
+	require.Equal(t, fleet.ActivityWebhookDeliveryPending, delivery.Status)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, ptr.Int(http.StatusInternalServerError), delivery.ResponseStatus)
+	require.NotNil(t, delivery.Error)
+
+	// successful delivery
+	respStatus = http.StatusOK
+	err = job.Run(ctx, args)
+	require.NoError(t, err)
+	require.Equal(t, fleet.ActivityWebhookDeliverySuccess, delivery.Status)
+	require.Equal(t, 2, delivery.Attempts)
+	require.Equal(t, ptr.Int(http.StatusOK), delivery.ResponseStatus)
+	require.Nil(t, delivery.Error)
+
+	var act fleet.Activity
+	require.NoError(t, json.Unmarshal(gotBody, &act))
+	require.Equal(t, uint(1), act.ID)
+	require.Equal(t, "created_pack", act.Type)
+	require.Equal(t, "created_pack", gotHeaders.Get(ActivityWebhookEventHeader))
+	require.Equal(t, "10", gotHeaders.Get(ActivityWebhookDeliveryHeader))
+	require.Equal(t, "sha256="+SignActivityWebhookPayload("s3cr3t", gotBody), gotHeaders.Get(ActivityWebhookSignatureHeader))
+
+	// a delivery that is not pending anymore is not sent again
+	gotBody = nil
+	err = job.Run(ctx, args)
+	require.NoError(t, err)
+	require.Nil(t, gotBody)
+
+	// the last attempt marks the delivery as failed
+	respStatus = http.StatusBadGateway
+	delivery.Status = fleet.ActivityWebhookDeliveryPending
+	delivery.Attempts = maxRetries
+	err = job.Run(ctx, args)
+	require.Error(t, err)
+	require.Equal(t, fleet.ActivityWebhookDeliveryFailed, delivery.Status)
+	require.Equal(t, maxRetries+1, delivery.Attempts)
+
+	// the webhook has been removed from the config
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{}, nil
+	}
+	gotBody = nil
+	delivery.Status = fleet.ActivityWebhookDeliveryPending
+	err = job.Run(ctx, args)
+	require.NoError(t, err)
+	require.Nil(t, gotBody)
+	require.Equal(t, fleet.ActivityWebhookDeliveryFailed, delivery.Status)
+}
+
+func TestActivityWebhookRunWithUpdate(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{WebhookSettings: fleet.WebhookSettings{
+			ActivitiesWebhooks: []*fleet.ActivityWebhookSettings{{DestinationURL: srv.URL}},
+		}}, nil
+	}
+	status := fleet.ActivityWebhookDeliveryPending
+	ds.ActivityWebhookDeliveryFunc = func(ctx context.Context, id uint) (*fleet.ActivityWebhookDelivery, error) {
+		return &fleet.ActivityWebhookDelivery{ID: id, DestinationURL: srv.URL, Status: status}, nil
+	}
+	var (
+		gotDelivery *fleet.ActivityWebhookDelivery
+		gotJob      *fleet.Job
+	)
+	ds.UpdateActivityWebhookDeliveryAndJobFunc = func(ctx context.Context, d *fleet.ActivityWebhookDelivery, job *fleet.Job) error {
+		gotDelivery, gotJob = d, job
+		return nil
+	}
+
+	job := &ActivityWebhook{Datastore: ds, Log: kitlog.NewNopLogger()}
+	args := json.RawMessage(`{"delivery_id":10,"activity":{"id":1,"type":"created_pack"}}`)
+
+	// the attempt is recorded with the update of the job
+	update, err := job.RunWithUpdate(ctx, args)
+	require.Error(t, err)
+	require.NotNil(t, update)
+	require.False(t, ds.UpdateActivityWebhookDeliveryAndJobFuncInvoked)
+	fleetJob := &fleet.Job{ID: 1, Retries: 1}
+	require.NoError(t, update(ctx, fleetJob))
+	require.Equal(t, uint(10), gotDelivery.ID)
+	require.Equal(t, 1, gotDelivery.Attempts)
+	require.Equal(t, ptr.Int(http.StatusInternalServerError), gotDelivery.ResponseStatus)
+	require.Same(t, fleetJob, gotJob)
+	require.False(t, ds.UpdateActivityWebhookDeliveryFuncInvoked)
+
+	// nothing to record for a delivery that is not pending anymore
+	status = fleet.ActivityWebhookDeliverySuccess
+	update, err = job.RunWithUpdate(ctx, args)
+	require.NoError(t, err)
+	require.Nil(t, update)
+}
+
+func TestActivityWebhookRetryDelay(t *testing.T) {
+	job := &ActivityWebhook{}
+	prev := job.RetryDelay(0)
+	require.NotZero(t, prev)
+	for i := 1; i < maxRetries+2; i++ {
+		d := job.RetryDelay(i)
+		require.GreaterOrEqual(t, d, prev)
+		prev = d
+	}
+}
+
+func TestSignActivityWebhookPayload(t *testing.T) {
+	// value computed with: echo -n '{"id":1}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t,
+		"03def589620c813f198fd03d7967e292b163ef0435ebf43071ce0e9519763cb7",
+		SignActivityWebhookPayload("secret", []byte(`{"id":1}`)),
+	)
+}
+
+func TestQueueActivityWebhookJobs(t *testing.T) {
+	ctx := context.Background()
+	ds := new(mock.Store)
+
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{WebhookSettings: fleet.WebhookSettings{
+			ActivitiesWebhooks: []*fleet.ActivityWebhookSettings{
+				{DestinationURL: "https://a.example.com"},
+				{DestinationURL: "https://b.example.com", ActivityTypes: []string{"deleted_pack"}},
+			},
+		}}, nil
+	}
+	activities := []*fleet.Activity{
+		{ID: 1, Type: "created_pack"},
+		{ID: 2, Type: "deleted_pack"},
+	}
+	ds.ListActivitiesFunc = func(ctx context.Context, opt fleet.ListActivitiesOptions) ([]*fleet.Activity, *fleet.PaginationMetadata, error) {
+		require.NotNil(t, opt.WebhooksProcessed)
+		require.False(t, *opt.WebhooksProcessed)
+		res := activities
+		activities = nil
+		return res, nil, nil
+	}
+	var deliveries []*fleet.ActivityWebhookDelivery
+	ds.NewActivityWebhookDeliveryFunc = func(ctx context.Context, d *fleet.ActivityWebhookDelivery) (*fleet.ActivityWebhookDelivery, error) {
+		d.ID = uint(len(deliveries) + 1)
+		deliveries = append(deliveries, d)
+		return d, nil
+	}
+	var jobs []*fleet.Job
+	ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+		jobs = append(jobs, job)
+		return job, nil
+	}
+	var processed []uint
+	ds.MarkActivitiesAsWebhooksProcessedFunc = func(ctx context.Context, ids []uint) error {
+		processed = append(processed, ids...)
+		return nil
+	}
+
+	err := QueueActivityWebhookJobs(ctx, ds, kitlog.NewNopLogger())
+	require.NoError(t, err)
+
+	require.Equal(t, []uint{1, 2}, processed)
+	require.Len(t, deliveries, 3)
+	require.Equal(t, "https://a.example.com", deliveries[0].DestinationURL)
+	require.Equal(t, uint(1), deliveries[0].ActivityID)
+	require.Equal(t, "https://a.example.com", deliveries[1].DestinationURL)
+	require.Equal(t, uint(2), deliveries[1].ActivityID)
+	require.Equal(t, "https://b.example.com", deliveries[2].DestinationURL)
+	require.Equal(t, uint(2), deliveries[2].ActivityID)
+
+	require.Len(t, jobs, 3)
+	for i, j := range jobs {
+		require.Equal(t, activityWebhookName, j.Name)
+		var args activityWebhookArgs
+		require.NoError(t, json.Unmarshal(*j.Args, &args))
+		require.Equal(t, deliveries[i].ID, args.DeliveryID)
+		require.Equal(t, deliveries[i].ActivityID, args.Activity.ID)
+	}
+}
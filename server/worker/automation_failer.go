@@ -60,6 +60,46 @@ func (f *TestAutomationFailer) CreateZendeskTicket(ctx context.Context, ticket *
 	return f.ZendeskClient.CreateZendeskTicket(ctx, ticket)
 }
 
+// ReopenJiraIssue implements the JiraClient and introduces a forced failure if
+// required, otherwise it returns the result of calling
+// f.JiraClient.ReopenJiraIssue with the provided arguments.
+func (f *TestAutomationFailer) ReopenJiraIssue(ctx context.Context, issueKey, comment string) error {
+	if err := f.forceErr(comment); err != nil {
+		return err
+	}
+	return f.JiraClient.ReopenJiraIssue(ctx, issueKey, comment)
+}
+
+// ResolveJiraIssue implements the JiraClient and introduces a forced failure if
+// required, otherwise it returns the result of calling
+// f.JiraClient.ResolveJiraIssue with the provided arguments.
+func (f *TestAutomationFailer) ResolveJiraIssue(ctx context.Context, issueKey, comment string) error {
+	if err := f.forceErr(comment); err != nil {
+		return err
+	}
+	return f.JiraClient.ResolveJiraIssue(ctx, issueKey, comment)
+}
+
+// ReopenZendeskTicket implements the ZendeskClient and introduces a forced
+// failure if required, otherwise it returns the result of calling
+// f.ZendeskClient.ReopenZendeskTicket with the provided arguments.
+func (f *TestAutomationFailer) ReopenZendeskTicket(ctx context.Context, ticketID int64, comment string) error {
+	if err := f.forceErr(comment); err != nil {
+		return err
+	}
+	return f.ZendeskClient.ReopenZendeskTicket(ctx, ticketID, comment)
+}
+
+// ResolveZendeskTicket implements the ZendeskClient and introduces a forced
+// failure if required, otherwise it returns the result of calling
+// f.ZendeskClient.ResolveZendeskTicket with the provided arguments.
+func (f *TestAutomationFailer) ResolveZendeskTicket(ctx context.Context, ticketID int64, comment string) error {
+	if err := f.forceErr(comment); err != nil {
+		return err
+	}
+	return f.ZendeskClient.ResolveZendeskTicket(ctx, ticketID, comment)
+}
+
 func (f *TestAutomationFailer) JiraConfigMatches(opts *externalsvc.JiraOptions) bool {
 	return f.JiraClient.JiraConfigMatches(opts)
 }
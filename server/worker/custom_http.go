@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// customHTTPName is the name of the job as registered in the worker.
+const customHTTPName = "custom_http"
+
+// CustomHTTPClient defines the method required for the client that sends
+// requests for custom HTTP integrations.
+type CustomHTTPClient interface {
+	SendCustomHTTPRequest(ctx context.Context, body []byte) error
+	CustomHTTPConfigMatches(opts *externalsvc.CustomHTTPOptions) bool
+}
+
+// CustomHTTP is the job processor for custom HTTP integrations. The body of
+// the requests is rendered using the body template of the integration, with
+// the same arguments as the ones available to the Jira and Zendesk templates
+// for failing policies.
+type CustomHTTP struct {
+	FleetURL      string
+	Datastore     fleet.Datastore
+	Log           kitlog.Logger
+	NewClientFunc func(*externalsvc.CustomHTTPOptions) (CustomHTTPClient, error)
+
+	// mu protects concurrent access to clientsCache, so that the job processor
+	// can potentially be run concurrently.
+	mu sync.Mutex
+	// map of integration type + team ID to client (empty team ID for global),
+	// e.g. "failingPolicy:123", "failingPolicy:", etc.
+	clientsCache map[string]CustomHTTPClient
+}
+
+// returns nil, nil if there is no integration enabled for that message,
+// otherwise it returns the client and the integration's configuration.
+func (c *CustomHTTP) getClient(ctx context.Context, args customHTTPArgs) (CustomHTTPClient, *fleet.CustomHTTPIntegration, error) {
+	var teamID uint
+	var useTeamCfg bool
+
+	key := intgTypeFailingPolicy + ":"
+	if args.FailingPolicy.TeamID != nil {
+		teamID = *args.FailingPolicy.TeamID
+		useTeamCfg = true
+		key += fmt.Sprint(teamID)
+	}
+
+	ac, err := c.Datastore.AppConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// load the config that would be used to create the client first - it is
+	// needed to check if an existing client is configured the same or if its
+	// configuration has changed since it was created.
+	intgs := ac.Integrations
+	if useTeamCfg {
+		tm, err := fleet.TeamWithInheritedConfig(ctx, c.Datastore, teamID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		intgs, err = tm.Config.Integrations.MatchWithIntegrations(ac.Integrations)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var intg *fleet.CustomHTTPIntegration
+	for _, ch := range intgs.CustomHTTP {
+		if ch.EnableFailingPolicies {
+			intg = ch
+			break
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.clientsCache == nil {
+		c.clientsCache = make(map[string]CustomHTTPClient)
+	}
+	if intg == nil {
+		// no integration configured, clear any existing one
+		delete(c.clientsCache, key)
+		return nil, nil, nil
+	}
+
+	// check if the existing one can be reused
+	opts := &externalsvc.CustomHTTPOptions{
+		URL:     intg.URL,
+		Method:  intg.Method,
+		Headers: intg.Headers,
+	}
+	if cli := c.clientsCache[key]; cli != nil && cli.CustomHTTPConfigMatches(opts) {
+		return cli, intg, nil
+	}
+
+	// otherwise create a new one
+	cli, err := c.NewClientFunc(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.clientsCache[key] = cli
+	return cli, intg, nil
+}
+
+// Name returns the name of the job.
+func (c *CustomHTTP) Name() string {
+	return customHTTPName
+}
+
+// customHTTPArgs are the arguments for the custom HTTP integration job.
+type customHTTPArgs struct {
+	FailingPolicy *failingPolicyArgs `json:"failing_policy,omitempty"`
+}
+
+// Run executes the custom HTTP job.
+func (c *CustomHTTP) Run(ctx context.Context, argsJSON json.RawMessage) error {
+	var args customHTTPArgs
+	if err := json.Unmarshal(argsJSON, &args); err != nil {
+		return ctxerr.Wrap(ctx, err, "unmarshal args")
+	}
+	if args.FailingPolicy == nil {
+		return ctxerr.New(ctx, "missing failing policy args")
+	}
+
+	cli, intg, err := c.getClient(ctx, args)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get custom HTTP client")
+	}
+	if cli == nil {
+		// this request was queued when an integration was enabled, but since
+		// then it has been disabled, so return success to mark the message
+		// as processed.
+		return nil
+	}
+
+	tpl, err := externalsvc.ParseCustomHTTPBodyTemplate(intg.BodyTemplate)
+	if err != nil {
+		return ctxerr.Wrapf(ctx, err, "parse body template of custom HTTP integration %s", intg.Name)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, newFailingPoliciesTplArgs(c.FleetURL, args.FailingPolicy)); err != nil {
+		return ctxerr.Wrapf(ctx, err, "execute body template of custom HTTP integration %s", intg.Name)
+	}
+
+	if err := cli.SendCustomHTTPRequest(ctx, buf.Bytes()); err != nil {
+		return ctxerr.Wrap(ctx, err, "send custom HTTP request")
+	}
+
+	attrs := []interface{}{
+		"msg", "sent custom http request for failing policy",
+		"integration", intg.Name,
+		"policy_id", args.FailingPolicy.PolicyID,
+		"policy_name", args.FailingPolicy.PolicyName,
+	}
+	if args.FailingPolicy.TeamID != nil {
+		attrs = append(attrs, "team_id", *args.FailingPolicy.TeamID)
+	}
+	level.Debug(c.Log).Log(attrs...)
+	return nil
+}
+
+// QueueCustomHTTPFailingPolicyJob queues a custom HTTP job for a failing
+// policy to process asynchronously via the worker.
+func QueueCustomHTTPFailingPolicyJob(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger,
+	policy *fleet.Policy, hosts []fleet.PolicySetHost,
+) error {
+	attrs := []interface{}{
+		"enabled", "true",
+		"failing_policy", policy.ID,
+		"hosts_count", len(hosts),
+	}
+	if policy.TeamID != nil {
+		attrs = append(attrs, "team_id", *policy.TeamID)
+	}
+	if len(hosts) == 0 {
+		attrs = append(attrs, "msg", "skipping, no host")
+		level.Debug(logger).Log(attrs...)
+		return nil
+	}
+
+	level.Info(logger).Log(attrs...)
+
+	args := &failingPolicyArgs{
+		PolicyID:       policy.ID,
+		PolicyName:     policy.Name,
+		PolicyCritical: policy.Critical,
+		TeamID:         policy.TeamID,
+		Hosts:          hosts,
+	}
+	job, err := QueueJob(ctx, ds, customHTTPName, customHTTPArgs{FailingPolicy: args})
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "queueing job")
+	}
+	level.Debug(logger).Log("job_id", job.ID)
+	return nil
+}
@@ -0,0 +1,161 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomHTTPRun(t *testing.T) {
+	type request struct {
+		Method  string
+		Path    string
+		Headers http.Header
+		Body    string
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, request{Method: r.Method, Path: r.URL.Path, Headers: r.Header, Body: string(b)})
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	const bodyTpl = `{"short_description": {{ json (printf "%s failed on %d host(s)" .PolicyName (len .Hosts)) }}, ` +
+		`"critical": {{ .PolicyCritical }}, "hosts": [{{ range $i, $h := .Hosts }}{{ if $i }},{{ end }}{{ json $h.DisplayName }}{{ end }}], ` +
+		`"url": "{{ .FleetURL }}/hosts/manage/?{{ if .TeamID }}team_id={{ .TeamID }}&{{ end }}policy_id={{ .PolicyID }}"}`
+
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			CustomHTTP: []*fleet.CustomHTTPIntegration{
+				{
+					Name:                  "servicenow",
+					URL:                   srv.URL + "/api/now/table/incident",
+					Method:                http.MethodPost,
+					Headers:               map[string]string{"Authorization": "Basic abc"},
+					BodyTemplate:          bodyTpl,
+					EnableFailingPolicies: true,
+				},
+				{
+					Name:         "tracker",
+					URL:          srv.URL + "/tracker",
+					Method:       http.MethodPut,
+					BodyTemplate: `{{ .PolicyName }}`,
+				},
+				{
+					Name:         "broken",
+					URL:          srv.URL + "/broken",
+					BodyTemplate: `{{ .NoSuchField }}`,
+				},
+			},
+		}}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		switch tid {
+		case 1:
+			return &fleet.Team{ID: tid, Config: fleet.TeamConfig{Integrations: fleet.TeamIntegrations{
+				CustomHTTP: []*fleet.TeamCustomHTTPIntegration{{Name: "tracker", EnableFailingPolicies: true}},
+			}}}, nil
+		case 2:
+			return &fleet.Team{ID: tid, Config: fleet.TeamConfig{Integrations: fleet.TeamIntegrations{
+				CustomHTTP: []*fleet.TeamCustomHTTPIntegration{{Name: "tracker", EnableFailingPolicies: false}},
+			}}}, nil
+		case 3:
+			return &fleet.Team{ID: tid, Config: fleet.TeamConfig{Integrations: fleet.TeamIntegrations{
+				CustomHTTP: []*fleet.TeamCustomHTTPIntegration{{Name: "broken", EnableFailingPolicies: true}},
+			}}}, nil
+		}
+		return nil, errors.New("unexpected team id")
+	}
+
+	job := &CustomHTTP{
+		FleetURL:  "https://fleet.example.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.CustomHTTPOptions) (CustomHTTPClient, error) {
+			return externalsvc.NewCustomHTTPClient(opts)
+		},
+	}
+	ctx := context.Background()
+
+	// global policy uses the globally-enabled integration
+	err := job.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "disk \"encryption\"", "policy_critical": true,
+		"hosts": [{"id": 1, "hostname": "h1", "displayname": "host-1"}, {"id": 2, "hostname": "h2", "displayname": "host-2"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, http.MethodPost, requests[0].Method)
+	require.Equal(t, "/api/now/table/incident", requests[0].Path)
+	require.Equal(t, "Basic abc", requests[0].Headers.Get("Authorization"))
+	require.JSONEq(t, `{
+		"short_description": "disk \"encryption\" failed on 2 host(s)",
+		"critical": true,
+		"hosts": ["host-1", "host-2"],
+		"url": "https://fleet.example.com/hosts/manage/?policy_id=1"
+	}`, requests[0].Body)
+
+	// team policy uses the team's integration
+	err = job.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 2, "policy_name": "p2", "team_id": 1, "hosts": []}}`))
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	require.Equal(t, http.MethodPut, requests[1].Method)
+	require.Equal(t, "/tracker", requests[1].Path)
+	require.Equal(t, "p2", requests[1].Body)
+
+	// integration disabled for the team, no request
+	err = job.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 3, "policy_name": "p3", "team_id": 2, "hosts": []}}`))
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+
+	// template fails to execute
+	err = job.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 4, "policy_name": "p4", "team_id": 3, "hosts": []}}`))
+	require.ErrorContains(t, err, "execute body template of custom HTTP integration broken")
+	require.Len(t, requests, 2)
+}
+
+func TestCustomHTTPQueueFailingPolicyJob(t *testing.T) {
+	ds := new(mock.Store)
+	ctx := context.Background()
+	logger := kitlog.NewNopLogger()
+
+	t.Run("success", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			require.Equal(t, customHTTPName, job.Name)
+			require.Contains(t, string(*job.Args), `"team_id":2`)
+			return job, nil
+		}
+		err := QueueCustomHTTPFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1", TeamID: ptr.Uint(2)}}, []fleet.PolicySetHost{{ID: 1, Hostname: "h1"}})
+		require.NoError(t, err)
+		require.True(t, ds.NewJobFuncInvoked)
+		ds.NewJobFuncInvoked = false
+	})
+
+	t.Run("no host", func(t *testing.T) {
+		err := QueueCustomHTTPFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1"}}, nil)
+		require.NoError(t, err)
+		require.False(t, ds.NewJobFuncInvoked)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			return nil, io.EOF
+		}
+		err := QueueCustomHTTPFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1"}}, []fleet.PolicySetHost{{ID: 1, Hostname: "h1"}})
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
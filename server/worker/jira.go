@@ -26,6 +26,8 @@ var jiraTemplates = struct {
 	VulnDescription          *template.Template
 	FailingPolicySummary     *template.Template
 	FailingPolicyDescription *template.Template
+	FailingPolicyComment     *template.Template
+	ResolvedPolicyComment    *template.Template
 }{
 	VulnSummary: template.Must(template.New("").Parse(
 		`Vulnerability {{ .CVE }} detected on {{ len .Hosts }} host(s)`,
@@ -86,6 +88,26 @@ View hosts that failed {{ .PolicyName }} on the [*Hosts*|{{ .FleetURL }}/hosts/m
 ----
 
 This issue was created automatically by your Fleet Jira integration.
+`)),
+
+	FailingPolicyComment: template.Must(template.New("").Parse(
+		`{{ .PolicyName }} policy now also fails on {{ len .Hosts }} more host(s):
+{{ $end := len .Hosts }}{{ if gt $end 50 }}{{ $end = 50 }}{{ end }}
+{{ range slice .Hosts 0 $end }}
+* [{{ .DisplayName }}|{{ $.FleetURL }}/hosts/{{ .ID }}]
+{{ end }}
+
+----
+
+This comment was added automatically by your Fleet Jira integration.
+`)),
+
+	ResolvedPolicyComment: template.Must(template.New("").Parse(
+		`{{ if .PolicyName }}All hosts now pass the {{ .PolicyName }} policy.{{ else }}The policy has been deleted.{{ end }}
+
+----
+
+This issue was resolved automatically by your Fleet Jira integration.
 `)),
 }
 
@@ -107,6 +129,8 @@ type jiraVulnTplArgs struct {
 // to Jira.
 type JiraClient interface {
 	CreateJiraIssue(ctx context.Context, issue *jira.Issue) (*jira.Issue, error)
+	ReopenJiraIssue(ctx context.Context, issueKey, comment string) error
+	ResolveJiraIssue(ctx context.Context, issueKey, comment string) error
 	JiraConfigMatches(opts *externalsvc.JiraOptions) bool
 }
 
@@ -121,7 +145,8 @@ type Jira struct {
 	// can potentially be run concurrently.
 	mu sync.Mutex
 	// map of integration type + team ID to Jira client (empty team ID for
-	// global), e.g. "vuln:123", "failingPolicy:", etc.
+	// global), e.g. "vuln:123", "failingPolicy:", etc. For resolved
+	// policies, the integration key is used instead of the team ID.
 	clientsCache map[string]JiraClient
 }
 
@@ -130,8 +155,14 @@ func (j *Jira) Name() string {
 	return jiraName
 }
 
-// returns nil, nil if there is no integration enabled for that message.
-func (j *Jira) getClient(ctx context.Context, args jiraArgs) (JiraClient, error) {
+// jiraIntegrationKey returns the key identifying the Jira integration
+// configured with those options, as stored with the tickets it created.
+func jiraIntegrationKey(opts *externalsvc.JiraOptions) string {
+	return opts.BaseURL + "\n" + opts.ProjectKey
+}
+
+// returns nil, nil, nil if there is no integration enabled for that message.
+func (j *Jira) getClient(ctx context.Context, args jiraArgs) (JiraClient, *externalsvc.JiraOptions, error) {
 	var teamID uint
 	var useTeamCfg bool
 
@@ -142,10 +173,13 @@ func (j *Jira) getClient(ctx context.Context, args jiraArgs) (JiraClient, error)
 		useTeamCfg = true
 		key += fmt.Sprint(teamID)
 	}
+	if intgType == intgTypeResolvedPolicy {
+		key += args.ResolvedPolicy.IntegrationKey
+	}
 
 	ac, err := j.Datastore.AppConfig(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// load the config that would be used to create the client first - it is
@@ -153,14 +187,14 @@ func (j *Jira) getClient(ctx context.Context, args jiraArgs) (JiraClient, error)
 	// configuration has changed since it was created.
 	var opts *externalsvc.JiraOptions
 	if useTeamCfg {
-		tm, err := j.Datastore.Team(ctx, teamID)
+		tm, err := fleet.TeamWithInheritedConfig(ctx, j.Datastore, teamID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		intgs, err := tm.Config.Integrations.MatchWithIntegrations(ac.Integrations)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		for _, intg := range intgs.Jira {
 			if intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies {
@@ -175,14 +209,18 @@ func (j *Jira) getClient(ctx context.Context, args jiraArgs) (JiraClient, error)
 		}
 	} else {
 		for _, intg := range ac.Integrations.Jira {
+			intgOpts := &externalsvc.JiraOptions{
+				BaseURL:           intg.URL,
+				BasicAuthUsername: intg.Username,
+				BasicAuthPassword: intg.APIToken,
+				ProjectKey:        intg.ProjectKey,
+			}
+			// tickets are resolved with the integration that created them, as
+			// long as it exists, even if its automations are now disabled.
 			if (intgType == intgTypeVuln && intg.EnableSoftwareVulnerabilities) ||
-				(intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies) {
-				opts = &externalsvc.JiraOptions{
-					BaseURL:           intg.URL,
-					BasicAuthUsername: intg.Username,
-					BasicAuthPassword: intg.APIToken,
-					ProjectKey:        intg.ProjectKey,
-				}
+				(intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies) ||
+				(intgType == intgTypeResolvedPolicy && jiraIntegrationKey(intgOpts) == args.ResolvedPolicy.IntegrationKey) {
+				opts = intgOpts
 				break
 			}
 		}
@@ -197,37 +235,42 @@ func (j *Jira) getClient(ctx context.Context, args jiraArgs) (JiraClient, error)
 	if opts == nil {
 		// no integration configured, clear any existing one
 		delete(j.clientsCache, key)
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// check if the existing one can be reused
 	if cli := j.clientsCache[key]; cli != nil && cli.JiraConfigMatches(opts) {
-		return cli, nil
+		return cli, opts, nil
 	}
 
 	// otherwise create a new one
 	cli, err := j.NewClientFunc(opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	j.clientsCache[key] = cli
-	return cli, nil
+	return cli, opts, nil
 }
 
 // jiraArgs are the arguments for the Jira integration job.
 type jiraArgs struct {
 	// CVE is deprecated but kept for backwards compatibility (there may be jobs
 	// enqueued in that format to process).
-	CVE           string             `json:"cve,omitempty"`
-	Vulnerability *vulnArgs          `json:"vulnerability,omitempty"`
-	FailingPolicy *failingPolicyArgs `json:"failing_policy,omitempty"`
+	CVE            string              `json:"cve,omitempty"`
+	Vulnerability  *vulnArgs           `json:"vulnerability,omitempty"`
+	FailingPolicy  *failingPolicyArgs  `json:"failing_policy,omitempty"`
+	ResolvedPolicy *resolvedPolicyArgs `json:"resolved_policy,omitempty"`
 }
 
 func (a *jiraArgs) integrationType() string {
-	if a.FailingPolicy == nil {
+	switch {
+	case a.FailingPolicy != nil:
+		return intgTypeFailingPolicy
+	case a.ResolvedPolicy != nil:
+		return intgTypeResolvedPolicy
+	default:
 		return intgTypeVuln
 	}
-	return intgTypeFailingPolicy
 }
 
 // Run executes the jira job.
@@ -237,7 +280,7 @@ func (j *Jira) Run(ctx context.Context, argsJSON json.RawMessage) error {
 		return ctxerr.Wrap(ctx, err, "unmarshal args")
 	}
 
-	cli, err := j.getClient(ctx, args)
+	cli, opts, err := j.getClient(ctx, args)
 	if err != nil {
 		return ctxerr.Wrap(ctx, err, "get Jira client")
 	}
@@ -252,7 +295,9 @@ func (j *Jira) Run(ctx context.Context, argsJSON json.RawMessage) error {
 	case intgTypeVuln:
 		return j.runVuln(ctx, cli, args)
 	case intgTypeFailingPolicy:
-		return j.runFailingPolicy(ctx, cli, args)
+		return j.runFailingPolicy(ctx, cli, jiraIntegrationKey(opts), args)
+	case intgTypeResolvedPolicy:
+		return j.runResolvedPolicy(ctx, cli, args)
 	default:
 		return ctxerr.Errorf(ctx, "unknown integration type: %v", intgType)
 	}
@@ -271,6 +316,15 @@ func (j *Jira) runVuln(ctx context.Context, cli JiraClient, args jiraArgs) error
 	if err != nil {
 		return ctxerr.Wrap(ctx, err, "find hosts by cve")
 	}
+	if len(hosts) == 0 {
+		// the CVE is suppressed by vulnerability exceptions on all the hosts
+		// that have it, or it was fixed since the job was queued.
+		level.Debug(j.Log).Log(
+			"msg", "skipping jira issue for cve without vulnerable hosts",
+			"cve", vargs.CVE,
+		)
+		return nil
+	}
 
 	tplArgs := &jiraVulnTplArgs{
 		NVDURL:           nvdCVEURL,
@@ -296,13 +350,59 @@ func (j *Jira) runVuln(ctx context.Context, cli JiraClient, args jiraArgs) error
 	return nil
 }
 
-func (j *Jira) runFailingPolicy(ctx context.Context, cli JiraClient, args jiraArgs) error {
-	tplArgs := newFailingPoliciesTplArgs(j.FleetURL, args.FailingPolicy)
+func (j *Jira) runFailingPolicy(ctx context.Context, cli JiraClient, intgKey string, args jiraArgs) error {
+	fp := args.FailingPolicy
+	tracked, err := j.Datastore.ListPolicyAutomationTickets(ctx, fp.PolicyID, jiraName, intgKey)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list policy automation tickets")
+	}
+
+	issueKey, newHosts := policyTicketToUpdate(tracked, fp.Hosts)
+	if issueKey != "" {
+		if len(newHosts) == 0 {
+			level.Debug(j.Log).Log(
+				"msg", "failing policy hosts already tracked by jira issue",
+				"policy_id", fp.PolicyID,
+				"issue_key", issueKey,
+			)
+			return nil
+		}
+
+		newArgs := *fp
+		newArgs.Hosts = newHosts
+		var buf bytes.Buffer
+		if err := jiraTemplates.FailingPolicyComment.Execute(&buf, newFailingPoliciesTplArgs(j.FleetURL, &newArgs)); err != nil {
+			return ctxerr.Wrap(ctx, err, "execute comment template")
+		}
+
+		err := cli.ReopenJiraIssue(ctx, issueKey, buf.String())
+		if err == nil {
+			recordPolicyTickets(ctx, j.Datastore, j.Log, fp, jiraName, intgKey, issueKey, newHosts)
+			level.Debug(j.Log).Log(
+				"msg", "updated jira issue for failing policy",
+				"policy_id", fp.PolicyID,
+				"issue_key", issueKey,
+				"new_hosts", len(newHosts),
+			)
+			return nil
+		}
+
+		// the issue may have been deleted or moved, create a new one instead.
+		level.Info(j.Log).Log(
+			"msg", "failed to update jira issue for failing policy, creating a new one",
+			"policy_id", fp.PolicyID,
+			"issue_key", issueKey,
+			"err", err,
+		)
+	}
+
+	tplArgs := newFailingPoliciesTplArgs(j.FleetURL, fp)
 
 	createdIssue, err := j.createTemplatedIssue(ctx, cli, jiraTemplates.FailingPolicySummary, jiraTemplates.FailingPolicyDescription, tplArgs)
 	if err != nil {
 		return err
 	}
+	recordPolicyTickets(ctx, j.Datastore, j.Log, fp, jiraName, intgKey, createdIssue.Key, fp.Hosts)
 
 	attrs := []interface{}{
 		"msg", "created jira issue for failing policy",
@@ -318,6 +418,35 @@ func (j *Jira) runFailingPolicy(ctx context.Context, cli JiraClient, args jiraAr
 	return nil
 }
 
+func (j *Jira) runResolvedPolicy(ctx context.Context, cli JiraClient, args jiraArgs) error {
+	rp := args.ResolvedPolicy
+
+	// a host may have started failing the policy again since the job was
+	// queued, in which case the issue is still relevant.
+	count, err := j.Datastore.CountPolicyAutomationTicketHosts(ctx, jiraName, rp.IntegrationKey, rp.TicketID)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "count policy automation ticket hosts")
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := jiraTemplates.ResolvedPolicyComment.Execute(&buf, rp); err != nil {
+		return ctxerr.Wrap(ctx, err, "execute comment template")
+	}
+	if err := cli.ResolveJiraIssue(ctx, rp.TicketID, buf.String()); err != nil {
+		return ctxerr.Wrap(ctx, err, "resolve issue")
+	}
+
+	level.Debug(j.Log).Log(
+		"msg", "resolved jira issue for failing policy",
+		"policy_id", rp.PolicyID,
+		"issue_key", rp.TicketID,
+	)
+	return nil
+}
+
 func (j *Jira) createTemplatedIssue(ctx context.Context, cli JiraClient, summaryTpl, descTpl *template.Template, args interface{}) (*jira.Issue, error) {
 	var buf bytes.Buffer
 	if err := summaryTpl.Execute(&buf, args); err != nil {
@@ -38,6 +38,12 @@ func TestJiraRun(t *testing.T) {
 			},
 		}}, nil
 	}
+	ds.ListPolicyAutomationTicketsFunc = func(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+		return nil, nil
+	}
+	ds.UpsertPolicyAutomationTicketsFunc = func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+		return nil
+	}
 	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
 		if tid != 123 {
 			return nil, errors.New("unexpected team id")
@@ -194,6 +200,44 @@ func TestJiraRun(t *testing.T) {
 	}
 }
 
+func TestJiraRunSuppressedVuln(t *testing.T) {
+	ds := new(mock.Store)
+	// the CVE is suppressed by vulnerability exceptions on all hosts
+	ds.HostsByCVEFunc = func(ctx context.Context, cve string) ([]*fleet.HostShort, error) {
+		return nil, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			Jira: []*fleet.JiraIntegration{
+				{EnableSoftwareVulnerabilities: true},
+			},
+		}}, nil
+	}
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client, err := externalsvc.NewJiraClient(&externalsvc.JiraOptions{BaseURL: srv.URL})
+	require.NoError(t, err)
+
+	jira := &Jira{
+		FleetURL:  "https://fleetdm.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.JiraOptions) (JiraClient, error) {
+			return client, nil
+		},
+	}
+	err = jira.Run(license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: fleet.TierFree}), json.RawMessage(`{"vulnerability":{"cve":"CVE-1234-5678"}}`))
+	require.NoError(t, err)
+	require.True(t, ds.HostsByCVEFuncInvoked)
+	require.Zero(t, requests)
+}
+
 func TestJiraQueueVulnJobs(t *testing.T) {
 	ds := new(mock.Store)
 	ctx := context.Background()
@@ -313,11 +357,30 @@ func TestJiraQueueFailingPolicyJob(t *testing.T) {
 type mockJiraClient struct {
 	opts   externalsvc.JiraOptions
 	issues []jira.Issue
+
+	// reopened and resolved are the keys and comments of the issues reopened
+	// and resolved, as "key: comment".
+	reopened  []string
+	resolved  []string
+	reopenErr error
 }
 
 func (c *mockJiraClient) CreateJiraIssue(ctx context.Context, issue *jira.Issue) (*jira.Issue, error) {
 	c.issues = append(c.issues, *issue)
-	return &jira.Issue{}, nil
+	return &jira.Issue{Key: fmt.Sprintf("ED-%d", len(c.issues))}, nil
+}
+
+func (c *mockJiraClient) ReopenJiraIssue(ctx context.Context, issueKey, comment string) error {
+	if c.reopenErr != nil {
+		return c.reopenErr
+	}
+	c.reopened = append(c.reopened, issueKey+": "+comment)
+	return nil
+}
+
+func (c *mockJiraClient) ResolveJiraIssue(ctx context.Context, issueKey, comment string) error {
+	c.resolved = append(c.resolved, issueKey+": "+comment)
+	return nil
 }
 
 func (c *mockJiraClient) JiraConfigMatches(opts *externalsvc.JiraOptions) bool {
@@ -342,6 +405,13 @@ func TestJiraRunClientUpdate(t *testing.T) {
 		}}, nil
 	}
 
+	ds.ListPolicyAutomationTicketsFunc = func(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+		return nil, nil
+	}
+	ds.UpsertPolicyAutomationTicketsFunc = func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+		return nil
+	}
+
 	teamCfg := &fleet.Team{
 		ID: 123,
 		Config: fleet.TeamConfig{
@@ -430,3 +500,101 @@ func TestJiraRunClientUpdate(t *testing.T) {
 	require.Len(t, clients[2].issues, 1)
 	require.NotContains(t, clients[2].issues[0].Fields.Description, "Critical")
 }
+
+func TestJiraRunFailingPolicyTickets(t *testing.T) {
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			Jira: []*fleet.JiraIntegration{
+				{URL: "https://jira.example.com", ProjectKey: "ED", EnableFailingPolicies: true},
+			},
+		}}, nil
+	}
+
+	const intgKey = "https://jira.example.com\nED"
+	var tracked []*fleet.PolicyAutomationTicket
+	ds.ListPolicyAutomationTicketsFunc = func(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+		require.Equal(t, uint(1), policyID)
+		require.Equal(t, "jira", integrationType)
+		require.Equal(t, intgKey, integrationKey)
+		return tracked, nil
+	}
+	ds.UpsertPolicyAutomationTicketsFunc = func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+		for _, t := range tickets {
+			t.ID = uint(len(tracked) + 1)
+			tracked = append(tracked, t)
+		}
+		return nil
+	}
+	ds.CountPolicyAutomationTicketHostsFunc = func(ctx context.Context, integrationType, integrationKey, ticketID string) (int, error) {
+		var count int
+		for _, t := range tracked {
+			if t.IntegrationType == integrationType && t.IntegrationKey == integrationKey && t.TicketID == ticketID {
+				count++
+			}
+		}
+		return count, nil
+	}
+
+	client := &mockJiraClient{}
+	jiraJob := &Jira{
+		FleetURL:  "http://example.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.JiraOptions) (JiraClient, error) {
+			client.opts = *opts
+			return client, nil
+		},
+	}
+	ctx := license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: fleet.TierFree})
+
+	// first failure creates the issue and tracks the hosts
+	err := jiraJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 1, "displayname": "h1"}, {"id": 2, "displayname": "h2"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.issues, 1)
+	require.Len(t, tracked, 2)
+	require.Equal(t, "ED-1", tracked[0].TicketID)
+	require.Equal(t, "ED-1", tracked[1].TicketID)
+
+	// repeated failure of the same hosts does nothing
+	err = jiraJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 2, "displayname": "h2"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.issues, 1)
+	require.Empty(t, client.reopened)
+
+	// failure of a new host comments on the existing issue
+	err = jiraJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 2, "displayname": "h2"}, {"id": 3, "displayname": "h3"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.issues, 1)
+	require.Len(t, client.reopened, 1)
+	require.Contains(t, client.reopened[0], "ED-1: test-policy policy now also fails on 1 more host(s)")
+	require.Contains(t, client.reopened[0], "[h3|http://example.com/hosts/3]")
+	require.NotContains(t, client.reopened[0], "h2")
+	require.Len(t, tracked, 3)
+	require.Equal(t, uint(3), tracked[2].HostID)
+	require.Equal(t, "ED-1", tracked[2].TicketID)
+
+	// if the issue cannot be updated, a new one is created
+	client.reopenErr = errors.New("issue does not exist")
+	err = jiraJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 4, "displayname": "h4"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.issues, 2)
+	require.Len(t, tracked, 4)
+	require.Equal(t, "ED-2", tracked[3].TicketID)
+
+	// the issue is not resolved while hosts are still tracked
+	err = jiraJob.Run(ctx, json.RawMessage(`{"resolved_policy":{"policy_id": 1, "policy_name": "test-policy", "integration_key": "https://jira.example.com\nED", "ticket_id": "ED-1"}}`))
+	require.NoError(t, err)
+	require.Empty(t, client.resolved)
+
+	tracked = tracked[3:]
+	err = jiraJob.Run(ctx, json.RawMessage(`{"resolved_policy":{"policy_id": 1, "policy_name": "test-policy", "integration_key": "https://jira.example.com\nED", "ticket_id": "ED-1"}}`))
+	require.NoError(t, err)
+	require.Len(t, client.resolved, 1)
+	require.Contains(t, client.resolved[0], "ED-1: All hosts now pass the test-policy policy.")
+
+	// resolving with an integration that no longer exists is a no-op
+	err = jiraJob.Run(ctx, json.RawMessage(`{"resolved_policy":{"policy_id": 1, "policy_name": "test-policy", "integration_key": "https://other.example.com\nED", "ticket_id": "ED-9"}}`))
+	require.NoError(t, err)
+	require.Len(t, client.resolved, 1)
+}
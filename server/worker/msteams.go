@@ -0,0 +1,356 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// microsoftTeamsName is the name of the job as registered in the worker.
+const microsoftTeamsName = "microsoft_teams"
+
+var microsoftTeamsTemplates = struct {
+	VulnTitle          *template.Template
+	VulnText           *template.Template
+	FailingPolicyTitle *template.Template
+	FailingPolicyText  *template.Template
+}{
+	VulnTitle: template.Must(template.New("").Parse(
+		`Vulnerability {{ .CVE }} detected on {{ len .Hosts }} host(s)`,
+	)),
+
+	// Microsoft Teams message cards support a subset of markdown. See
+	// https://learn.microsoft.com/en-us/microsoftteams/platform/task-modules-and-cards/cards/cards-format
+	// for some reference. Paragraphs must be separated by an empty line.
+	VulnText: template.Must(template.New("").Funcs(template.FuncMap{
+		// CISAKnownExploit is *bool, so any condition check on it in the template
+		// will test if nil or not, and not its actual boolean value. Hence, "deref".
+		"deref": func(b *bool) bool { return *b },
+	}).Parse(
+		`See vulnerability (CVE) details in National Vulnerability Database (NVD) here: [{{ .CVE }}]({{ .NVDURL }}{{ .CVE }}).
+{{ if .IsPremium }}{{ if .EPSSProbability }}
+Probability of exploit (reported by [FIRST.org/epss](https://www.first.org/epss/)): {{ .EPSSProbability }}
+{{ end }}{{ if .CVSSScore }}
+CVSS score (reported by [NVD](https://nvd.nist.gov/)): {{ .CVSSScore }}
+{{ end }}{{ if .CISAKnownExploit }}
+Known exploits (reported by [CISA](https://www.cisa.gov/known-exploited-vulnerabilities-catalog)): {{ if deref .CISAKnownExploit }}Yes{{ else }}No{{ end }}
+{{ end }}{{ end }}
+Affected hosts:
+{{ $end := len .Hosts }}{{ if gt $end 50 }}{{ $end = 50 }}{{ end }}{{ range slice .Hosts 0 $end }}
+- [{{ .DisplayName }}]({{ $.FleetURL }}/hosts/{{ .ID }}){{ end }}
+
+View the affected software and more affected hosts on the [Software]({{ .FleetURL }}/software/manage) page in Fleet (search for "{{ .CVE }}").`,
+	)),
+
+	FailingPolicyTitle: template.Must(template.New("").Parse(
+		`{{ .PolicyName }} policy failed on {{ len .Hosts }} host(s)`,
+	)),
+
+	FailingPolicyText: template.Must(template.New("").Parse(
+		`{{ if .PolicyCritical }}This policy is marked as **Critical** in Fleet.
+
+{{ end }}Hosts:
+{{ $end := len .Hosts }}{{ if gt $end 50 }}{{ $end = 50 }}{{ end }}{{ range slice .Hosts 0 $end }}
+- [{{ .DisplayName }}]({{ $.FleetURL }}/hosts/{{ .ID }}){{ end }}
+
+View hosts that failed {{ .PolicyName }} on the [**Hosts**]({{ .FleetURL }}/hosts/manage/?order_key=hostname&order_direction=asc&{{ if .TeamID }}team_id={{ .TeamID }}&{{ end }}policy_id={{ .PolicyID }}&policy_response=failing) page in Fleet.`,
+	)),
+}
+
+// MicrosoftTeamsClient defines the method required for the client that sends
+// messages to Microsoft Teams.
+type MicrosoftTeamsClient interface {
+	PostMicrosoftTeamsMessage(ctx context.Context, msg *externalsvc.MicrosoftTeamsMessage) error
+	MicrosoftTeamsConfigMatches(opts *externalsvc.MicrosoftTeamsOptions) bool
+}
+
+// MicrosoftTeams is the job processor for Microsoft Teams integrations.
+type MicrosoftTeams struct {
+	FleetURL      string
+	Datastore     fleet.Datastore
+	Log           kitlog.Logger
+	NewClientFunc func(*externalsvc.MicrosoftTeamsOptions) (MicrosoftTeamsClient, error)
+
+	// mu protects concurrent access to clientsCache, so that the job processor
+	// can potentially be run concurrently.
+	mu sync.Mutex
+	// map of integration type + team ID to Microsoft Teams client (empty team
+	// ID for global), e.g. "vuln:123", "failingPolicy:", etc.
+	clientsCache map[string]MicrosoftTeamsClient
+}
+
+// returns nil, nil if there is no integration enabled for that message.
+func (m *MicrosoftTeams) getClient(ctx context.Context, args microsoftTeamsArgs) (MicrosoftTeamsClient, error) {
+	var teamID uint
+	var useTeamCfg bool
+
+	intgType := args.integrationType()
+	key := intgType + ":"
+	if intgType == intgTypeFailingPolicy && args.FailingPolicy.TeamID != nil {
+		teamID = *args.FailingPolicy.TeamID
+		useTeamCfg = true
+		key += fmt.Sprint(teamID)
+	}
+
+	ac, err := m.Datastore.AppConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the config that would be used to create the client first - it is
+	// needed to check if an existing client is configured the same or if its
+	// configuration has changed since it was created.
+	var opts *externalsvc.MicrosoftTeamsOptions
+	if useTeamCfg {
+		tm, err := fleet.TeamWithInheritedConfig(ctx, m.Datastore, teamID)
+		if err != nil {
+			return nil, err
+		}
+
+		intgs, err := tm.Config.Integrations.MatchWithIntegrations(ac.Integrations)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, intg := range intgs.MicrosoftTeams {
+			if intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies {
+				opts = &externalsvc.MicrosoftTeamsOptions{URL: intg.URL}
+				break
+			}
+		}
+	} else {
+		for _, intg := range ac.Integrations.MicrosoftTeams {
+			if (intgType == intgTypeVuln && intg.EnableSoftwareVulnerabilities) ||
+				(intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies) {
+				opts = &externalsvc.MicrosoftTeamsOptions{URL: intg.URL}
+				break
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.clientsCache == nil {
+		m.clientsCache = make(map[string]MicrosoftTeamsClient)
+	}
+	if opts == nil {
+		// no integration configured, clear any existing one
+		delete(m.clientsCache, key)
+		return nil, nil
+	}
+
+	// check if the existing one can be reused
+	if cli := m.clientsCache[key]; cli != nil && cli.MicrosoftTeamsConfigMatches(opts) {
+		return cli, nil
+	}
+
+	// otherwise create a new one
+	cli, err := m.NewClientFunc(opts)
+	if err != nil {
+		return nil, err
+	}
+	m.clientsCache[key] = cli
+	return cli, nil
+}
+
+// Name returns the name of the job.
+func (m *MicrosoftTeams) Name() string {
+	return microsoftTeamsName
+}
+
+// microsoftTeamsArgs are the arguments for the Microsoft Teams integration
+// job.
+type microsoftTeamsArgs struct {
+	Vulnerability *vulnArgs          `json:"vulnerability,omitempty"`
+	FailingPolicy *failingPolicyArgs `json:"failing_policy,omitempty"`
+}
+
+func (a *microsoftTeamsArgs) integrationType() string {
+	if a.FailingPolicy == nil {
+		return intgTypeVuln
+	}
+	return intgTypeFailingPolicy
+}
+
+// Run executes the microsoft teams job.
+func (m *MicrosoftTeams) Run(ctx context.Context, argsJSON json.RawMessage) error {
+	var args microsoftTeamsArgs
+	if err := json.Unmarshal(argsJSON, &args); err != nil {
+		return ctxerr.Wrap(ctx, err, "unmarshal args")
+	}
+
+	cli, err := m.getClient(ctx, args)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get Microsoft Teams client")
+	}
+	if cli == nil {
+		// this message was queued when an integration was enabled, but since
+		// then it has been disabled, so return success to mark the message
+		// as processed.
+		return nil
+	}
+
+	switch intgType := args.integrationType(); intgType {
+	case intgTypeVuln:
+		return m.runVuln(ctx, cli, args)
+	case intgTypeFailingPolicy:
+		return m.runFailingPolicy(ctx, cli, args)
+	default:
+		return ctxerr.Errorf(ctx, "unknown integration type: %v", intgType)
+	}
+}
+
+func (m *MicrosoftTeams) runVuln(ctx context.Context, cli MicrosoftTeamsClient, args microsoftTeamsArgs) error {
+	if args.Vulnerability == nil {
+		return ctxerr.New(ctx, "missing vulnerability args")
+	}
+	hosts, err := m.Datastore.HostsByCVE(ctx, args.Vulnerability.CVE)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "find hosts by cve")
+	}
+	if len(hosts) == 0 {
+		// the CVE is suppressed by vulnerability exceptions on all the hosts
+		// that have it, or it was fixed since the job was queued.
+		level.Debug(m.Log).Log(
+			"msg", "skipping microsoft teams message for cve without vulnerable hosts",
+			"cve", args.Vulnerability.CVE,
+		)
+		return nil
+	}
+
+	tplArgs := newVulnTplArgs(ctx, m.FleetURL, args.Vulnerability, hosts)
+	if err := m.postTemplatedMessage(ctx, cli, microsoftTeamsTemplates.VulnTitle, microsoftTeamsTemplates.VulnText, tplArgs); err != nil {
+		return err
+	}
+	level.Debug(m.Log).Log(
+		"msg", "posted microsoft teams message for cve",
+		"cve", args.Vulnerability.CVE,
+	)
+	return nil
+}
+
+func (m *MicrosoftTeams) runFailingPolicy(ctx context.Context, cli MicrosoftTeamsClient, args microsoftTeamsArgs) error {
+	tplArgs := newFailingPoliciesTplArgs(m.FleetURL, args.FailingPolicy)
+	if err := m.postTemplatedMessage(ctx, cli, microsoftTeamsTemplates.FailingPolicyTitle, microsoftTeamsTemplates.FailingPolicyText, tplArgs); err != nil {
+		return err
+	}
+
+	attrs := []interface{}{
+		"msg", "posted microsoft teams message for failing policy",
+		"policy_id", args.FailingPolicy.PolicyID,
+		"policy_name", args.FailingPolicy.PolicyName,
+	}
+	if args.FailingPolicy.TeamID != nil {
+		attrs = append(attrs, "team_id", *args.FailingPolicy.TeamID)
+	}
+	level.Debug(m.Log).Log(attrs...)
+	return nil
+}
+
+func (m *MicrosoftTeams) postTemplatedMessage(ctx context.Context, cli MicrosoftTeamsClient, titleTpl, textTpl *template.Template, args interface{}) error {
+	var buf bytes.Buffer
+	if err := titleTpl.Execute(&buf, args); err != nil {
+		return ctxerr.Wrap(ctx, err, "execute title template")
+	}
+	title := buf.String()
+
+	buf.Reset() // reuse buffer
+	if err := textTpl.Execute(&buf, args); err != nil {
+		return ctxerr.Wrap(ctx, err, "execute text template")
+	}
+	text := buf.String()
+
+	if err := cli.PostMicrosoftTeamsMessage(ctx, &externalsvc.MicrosoftTeamsMessage{Title: title, Text: text}); err != nil {
+		return ctxerr.Wrap(ctx, err, "post message")
+	}
+	return nil
+}
+
+// QueueMicrosoftTeamsVulnJobs queues the Microsoft Teams vulnerability jobs to
+// process asynchronously via the worker.
+func QueueMicrosoftTeamsVulnJobs(
+	ctx context.Context,
+	ds fleet.Datastore,
+	logger kitlog.Logger,
+	recentVulns []fleet.SoftwareVulnerability,
+	cveMeta map[string]fleet.CVEMeta,
+) error {
+	level.Info(logger).Log("enabled", "true", "recentVulns", len(recentVulns))
+
+	// for troubleshooting, log in debug level the CVEs that we will process
+	// (cannot be done in the loop below as we want to add the debug log
+	// _before_ we start processing them).
+	cves := make([]string, 0, len(recentVulns))
+	for _, vuln := range recentVulns {
+		cves = append(cves, vuln.GetCVE())
+	}
+	sort.Strings(cves)
+	level.Debug(logger).Log("recent_cves", fmt.Sprintf("%v", cves))
+
+	uniqCVEs := make(map[string]bool)
+	for _, v := range recentVulns {
+		uniqCVEs[v.GetCVE()] = true
+	}
+
+	for cve := range uniqCVEs {
+		args := vulnArgs{CVE: cve}
+		if meta, ok := cveMeta[cve]; ok {
+			args.EPSSProbability = meta.EPSSProbability
+			args.CVSSScore = meta.CVSSScore
+			args.CISAKnownExploit = meta.CISAKnownExploit
+		}
+		job, err := QueueJob(ctx, ds, microsoftTeamsName, microsoftTeamsArgs{Vulnerability: &args})
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "queueing job")
+		}
+		level.Debug(logger).Log("job_id", job.ID)
+	}
+	return nil
+}
+
+// QueueMicrosoftTeamsFailingPolicyJob queues a Microsoft Teams job for a
+// failing policy to process asynchronously via the worker.
+func QueueMicrosoftTeamsFailingPolicyJob(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger,
+	policy *fleet.Policy, hosts []fleet.PolicySetHost,
+) error {
+	attrs := []interface{}{
+		"enabled", "true",
+		"failing_policy", policy.ID,
+		"hosts_count", len(hosts),
+	}
+	if policy.TeamID != nil {
+		attrs = append(attrs, "team_id", *policy.TeamID)
+	}
+	if len(hosts) == 0 {
+		attrs = append(attrs, "msg", "skipping, no host")
+		level.Debug(logger).Log(attrs...)
+		return nil
+	}
+
+	level.Info(logger).Log(attrs...)
+
+	args := &failingPolicyArgs{
+		PolicyID:       policy.ID,
+		PolicyName:     policy.Name,
+		PolicyCritical: policy.Critical,
+		TeamID:         policy.TeamID,
+		Hosts:          hosts,
+	}
+	job, err := QueueJob(ctx, ds, microsoftTeamsName, microsoftTeamsArgs{FailingPolicy: args})
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "queueing job")
+	}
+	level.Debug(logger).Log("job_id", job.ID)
+	return nil
+}
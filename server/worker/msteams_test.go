@@ -0,0 +1,228 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/license"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMicrosoftTeamsClient struct {
+	opts     externalsvc.MicrosoftTeamsOptions
+	messages []externalsvc.MicrosoftTeamsMessage
+}
+
+func (c *mockMicrosoftTeamsClient) PostMicrosoftTeamsMessage(ctx context.Context, msg *externalsvc.MicrosoftTeamsMessage) error {
+	c.messages = append(c.messages, *msg)
+	return nil
+}
+
+func (c *mockMicrosoftTeamsClient) MicrosoftTeamsConfigMatches(opts *externalsvc.MicrosoftTeamsOptions) bool {
+	return c.opts == *opts
+}
+
+func TestMicrosoftTeamsRun(t *testing.T) {
+	ds := new(mock.Store)
+	ds.HostsByCVEFunc = func(ctx context.Context, cve string) ([]*fleet.HostShort, error) {
+		return []*fleet.HostShort{
+			{
+				ID:          1,
+				Hostname:    "test",
+				DisplayName: "test",
+			},
+		}, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			MicrosoftTeams: []*fleet.MicrosoftTeamsIntegration{
+				{URL: "https://teams.example.com/webhook/global/token", EnableSoftwareVulnerabilities: true, EnableFailingPolicies: true},
+				{URL: "https://teams.example.com/webhook/team/token", EnableSoftwareVulnerabilities: false, EnableFailingPolicies: false},
+			},
+		}}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		if tid != 123 {
+			return nil, errors.New("unexpected team id")
+		}
+		return &fleet.Team{
+			ID: 123,
+			Config: fleet.TeamConfig{
+				Integrations: fleet.TeamIntegrations{
+					MicrosoftTeams: []*fleet.TeamMicrosoftTeamsIntegration{
+						{URL: "https://teams.example.com/webhook/team/token", EnableFailingPolicies: true},
+					},
+				},
+			},
+		}, nil
+	}
+
+	cases := []struct {
+		desc                 string
+		licenseTier          string
+		payload              string
+		expectedURL          string
+		expectedTitle        string
+		expectedContains     []string
+		expectedNotContained string
+	}{
+		{
+			"vuln free",
+			fleet.TierFree,
+			`{"vulnerability":{"cve":"CVE-1234-5678","epss_probability":3.4,"cvss_score":50,"cisa_known_exploit":true}}`,
+			"https://teams.example.com/webhook/global/token",
+			"Vulnerability CVE-1234-5678 detected on 1 host(s)",
+			[]string{
+				"[CVE-1234-5678](https://nvd.nist.gov/vuln/detail/CVE-1234-5678)",
+				"- [test](https://fleetdm.com/hosts/1)",
+			},
+			"Probability of exploit",
+		},
+		{
+			"vuln premium",
+			fleet.TierPremium,
+			`{"vulnerability":{"cve":"CVE-1234-5678","epss_probability":3.4,"cvss_score":50,"cisa_known_exploit":false}}`,
+			"https://teams.example.com/webhook/global/token",
+			"Vulnerability CVE-1234-5678 detected on 1 host(s)",
+			[]string{
+				"Probability of exploit (reported by [FIRST.org/epss](https://www.first.org/epss/)): 3.4",
+				"CVSS score (reported by [NVD](https://nvd.nist.gov/)): 50",
+				"Known exploits (reported by [CISA](https://www.cisa.gov/known-exploited-vulnerabilities-catalog)): No",
+			},
+			"",
+		},
+		{
+			"failing global policy",
+			fleet.TierFree,
+			`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 123, "hostname": "host-123", "displayname": "host-123"}]}}`,
+			"https://teams.example.com/webhook/global/token",
+			"test-policy policy failed on 1 host(s)",
+			[]string{
+				"- [host-123](https://fleetdm.com/hosts/123)",
+				"&policy_id=1&policy_response=failing) page in Fleet.",
+			},
+			"**Critical**",
+		},
+		{
+			"failing critical team policy",
+			fleet.TierPremium,
+			`{"failing_policy":{"policy_id": 2, "policy_name": "test-policy-2", "policy_critical": true, "team_id": 123, "hosts": [{"id": 1, "hostname": "host-1"}, {"id": 2, "hostname": "host-2"}]}}`,
+			"https://teams.example.com/webhook/team/token",
+			"test-policy-2 policy failed on 2 host(s)",
+			[]string{
+				"This policy is marked as **Critical** in Fleet.",
+				"&team_id=123&policy_id=2&policy_response=failing) page in Fleet.",
+			},
+			"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var client *mockMicrosoftTeamsClient
+			teams := &MicrosoftTeams{
+				FleetURL:  "https://fleetdm.com",
+				Datastore: ds,
+				Log:       kitlog.NewNopLogger(),
+				NewClientFunc: func(opts *externalsvc.MicrosoftTeamsOptions) (MicrosoftTeamsClient, error) {
+					client = &mockMicrosoftTeamsClient{opts: *opts}
+					return client, nil
+				},
+			}
+
+			err := teams.Run(license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: c.licenseTier}), json.RawMessage(c.payload))
+			require.NoError(t, err)
+			require.NotNil(t, client)
+			require.Equal(t, c.expectedURL, client.opts.URL)
+			require.Len(t, client.messages, 1)
+			require.Equal(t, c.expectedTitle, client.messages[0].Title)
+			for _, s := range c.expectedContains {
+				require.Contains(t, client.messages[0].Text, s)
+			}
+			if c.expectedNotContained != "" {
+				require.NotContains(t, client.messages[0].Text, c.expectedNotContained)
+			}
+		})
+	}
+}
+
+func TestMicrosoftTeamsRunDisabled(t *testing.T) {
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			MicrosoftTeams: []*fleet.MicrosoftTeamsIntegration{
+				{URL: "https://teams.example.com/webhook/global/token", EnableSoftwareVulnerabilities: false, EnableFailingPolicies: false},
+			},
+		}}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		return &fleet.Team{ID: tid}, nil
+	}
+
+	teams := &MicrosoftTeams{
+		FleetURL:  "https://fleetdm.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.MicrosoftTeamsOptions) (MicrosoftTeamsClient, error) {
+			return nil, errors.New("client should not be created")
+		},
+	}
+
+	ctx := license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: fleet.TierPremium})
+	err := teams.Run(ctx, json.RawMessage(`{"vulnerability":{"cve":"CVE-1234-5678"}}`))
+	require.NoError(t, err)
+	err = teams.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 2, "policy_name": "test-policy-2", "team_id": 123, "hosts": []}}`))
+	require.NoError(t, err)
+	require.False(t, ds.HostsByCVEFuncInvoked)
+}
+
+func TestMicrosoftTeamsQueueJobs(t *testing.T) {
+	ds := new(mock.Store)
+	ctx := context.Background()
+	logger := kitlog.NewNopLogger()
+
+	t.Run("vulnerabilities", func(t *testing.T) {
+		var count int
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			require.Equal(t, microsoftTeamsName, job.Name)
+			count++
+			return job, nil
+		}
+		vulns := []fleet.SoftwareVulnerability{
+			{CVE: "CVE-1234-5678", SoftwareID: 1},
+			{CVE: "CVE-1234-5678", SoftwareID: 2},
+			{CVE: "CVE-1234-9999", SoftwareID: 2},
+		}
+		err := QueueMicrosoftTeamsVulnJobs(ctx, ds, logger, vulns, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+	})
+
+	t.Run("failing policy", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			require.Equal(t, microsoftTeamsName, job.Name)
+			require.Contains(t, string(*job.Args), `"team_id":2`)
+			return job, nil
+		}
+		err := QueueMicrosoftTeamsFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1", TeamID: ptr.Uint(2)}}, []fleet.PolicySetHost{{ID: 1, Hostname: "h1"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			return nil, io.EOF
+		}
+		err := QueueMicrosoftTeamsFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1"}}, []fleet.PolicySetHost{{ID: 1, Hostname: "h1"}})
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
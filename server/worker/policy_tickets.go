@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// policyTicketToUpdate returns the ID of the ticket tracking hosts failing
+// the policy that should be updated with new failing hosts (empty if there is
+// none), and the hosts that are not tracked by a ticket yet. If hosts are
+// tracked by multiple tickets, the most recent one is returned.
+func policyTicketToUpdate(tracked []*fleet.PolicyAutomationTicket, hosts []fleet.PolicySetHost) (string, []fleet.PolicySetHost) {
+	var ticketID string
+	var latestID uint
+	trackedHosts := make(map[uint]bool, len(tracked))
+	for _, t := range tracked {
+		trackedHosts[t.HostID] = true
+		if t.ID >= latestID {
+			latestID = t.ID
+			ticketID = t.TicketID
+		}
+	}
+
+	var untracked []fleet.PolicySetHost
+	for _, h := range hosts {
+		if !trackedHosts[h.ID] {
+			untracked = append(untracked, h)
+		}
+	}
+	return ticketID, untracked
+}
+
+// recordPolicyTickets records that the hosts are tracked by the ticket for
+// the failing policy. The ticket has already been created or updated at this
+// point, so a failure is logged instead of failing the job, which would
+// create duplicate tickets when retried.
+func recordPolicyTickets(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger,
+	args *failingPolicyArgs, intgType, intgKey, ticketID string, hosts []fleet.PolicySetHost,
+) {
+	tickets := make([]*fleet.PolicyAutomationTicket, 0, len(hosts))
+	for _, h := range hosts {
+		tickets = append(tickets, &fleet.PolicyAutomationTicket{
+			PolicyID:        args.PolicyID,
+			HostID:          h.ID,
+			IntegrationType: intgType,
+			IntegrationKey:  intgKey,
+			TicketID:        ticketID,
+		})
+	}
+	if err := ds.UpsertPolicyAutomationTickets(ctx, tickets); err != nil {
+		level.Error(logger).Log(
+			"msg", "failed to record failing policy ticket",
+			"policy_id", args.PolicyID,
+			"integration", intgType,
+			"ticket_id", ticketID,
+			"err", err,
+		)
+	}
+}
+
+// QueueResolvedPolicyTicketJobs stops tracking the hosts of failing policy
+// tickets that now pass the policy (or that have been deleted), and queues a
+// job to resolve the tickets that no longer track any failing host.
+func QueueResolvedPolicyTicketJobs(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger) error {
+	resolved, err := ds.ListResolvedPolicyAutomationTickets(ctx)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list resolved policy automation tickets")
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+
+	type ticketKey struct {
+		intgType, intgKey, ticketID string
+	}
+	ids := make([]uint, 0, len(resolved))
+	tickets := make(map[ticketKey]*fleet.PolicyAutomationTicket)
+	for _, t := range resolved {
+		ids = append(ids, t.ID)
+		tickets[ticketKey{t.IntegrationType, t.IntegrationKey, t.TicketID}] = t
+	}
+	if err := ds.DeletePolicyAutomationTickets(ctx, ids); err != nil {
+		return ctxerr.Wrap(ctx, err, "delete resolved policy automation tickets")
+	}
+
+	keys := make([]ticketKey, 0, len(tickets))
+	for k := range tickets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return tickets[keys[i]].ID < tickets[keys[j]].ID
+	})
+
+	for _, k := range keys {
+		count, err := ds.CountPolicyAutomationTicketHosts(ctx, k.intgType, k.intgKey, k.ticketID)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "count policy automation ticket hosts")
+		}
+		if count > 0 {
+			// some hosts still fail the policy
+			continue
+		}
+
+		t := tickets[k]
+		args := &resolvedPolicyArgs{
+			PolicyID:       t.PolicyID,
+			IntegrationKey: t.IntegrationKey,
+			TicketID:       t.TicketID,
+		}
+		// the policy may have been deleted, in which case the ticket is
+		// resolved without its name.
+		if p, err := ds.Policy(ctx, t.PolicyID); err == nil {
+			args.PolicyName = p.Name
+		} else if !fleet.IsNotFound(err) {
+			return ctxerr.Wrap(ctx, err, "get policy")
+		}
+
+		var jobArgs interface{}
+		switch t.IntegrationType {
+		case jiraName:
+			jobArgs = jiraArgs{ResolvedPolicy: args}
+		case zendeskName:
+			jobArgs = zendeskArgs{ResolvedPolicy: args}
+		default:
+			level.Info(logger).Log("msg", "unknown policy ticket integration type", "type", t.IntegrationType)
+			continue
+		}
+
+		job, err := QueueJob(ctx, ds, t.IntegrationType, jobArgs)
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "queueing job")
+		}
+		level.Debug(logger).Log(
+			"msg", "queued resolution of failing policy ticket",
+			"policy_id", t.PolicyID,
+			"integration", t.IntegrationType,
+			"ticket_id", t.TicketID,
+			"job_id", job.ID,
+		)
+	}
+	return nil
+}
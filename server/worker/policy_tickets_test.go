@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+type notFoundErr struct{}
+
+func (notFoundErr) Error() string    { return "not found" }
+func (notFoundErr) IsNotFound() bool { return true }
+
+func TestPolicyTicketToUpdate(t *testing.T) {
+	hosts := []fleet.PolicySetHost{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	ticketID, untracked := policyTicketToUpdate(nil, hosts)
+	require.Empty(t, ticketID)
+	require.Equal(t, hosts, untracked)
+
+	ticketID, untracked = policyTicketToUpdate([]*fleet.PolicyAutomationTicket{
+		{ID: 1, HostID: 1, TicketID: "A"},
+		{ID: 3, HostID: 2, TicketID: "B"},
+		{ID: 2, HostID: 4, TicketID: "A"},
+	}, hosts)
+	require.Equal(t, "B", ticketID)
+	require.Equal(t, []fleet.PolicySetHost{{ID: 3}}, untracked)
+}
+
+func TestQueueResolvedPolicyTicketJobs(t *testing.T) {
+	ds := new(mock.Store)
+	ctx := context.Background()
+	logger := kitlog.NewNopLogger()
+
+	var resolved []*fleet.PolicyAutomationTicket
+	ds.ListResolvedPolicyAutomationTicketsFunc = func(ctx context.Context) ([]*fleet.PolicyAutomationTicket, error) {
+		return resolved, nil
+	}
+	var deleted []uint
+	ds.DeletePolicyAutomationTicketsFunc = func(ctx context.Context, ids []uint) error {
+		deleted = append(deleted, ids...)
+		return nil
+	}
+	// the Jira issue ED-2 still tracks other hosts
+	ds.CountPolicyAutomationTicketHostsFunc = func(ctx context.Context, integrationType, integrationKey, ticketID string) (int, error) {
+		if integrationType == "jira" && ticketID == "ED-2" {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	ds.PolicyFunc = func(ctx context.Context, id uint) (*fleet.Policy, error) {
+		if id == 2 {
+			return nil, notFoundErr{}
+		}
+		return &fleet.Policy{PolicyData: fleet.PolicyData{ID: id, Name: "p1"}}, nil
+	}
+	var jobs []*fleet.Job
+	ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+		jobs = append(jobs, job)
+		return job, nil
+	}
+
+	// nothing to resolve
+	err := QueueResolvedPolicyTicketJobs(ctx, ds, logger)
+	require.NoError(t, err)
+	require.False(t, ds.DeletePolicyAutomationTicketsFuncInvoked)
+	require.Empty(t, jobs)
+
+	resolved = []*fleet.PolicyAutomationTicket{
+		{ID: 1, PolicyID: 1, HostID: 1, IntegrationType: "jira", IntegrationKey: "j", TicketID: "ED-1"},
+		{ID: 2, PolicyID: 1, HostID: 2, IntegrationType: "jira", IntegrationKey: "j", TicketID: "ED-1"},
+		{ID: 3, PolicyID: 1, HostID: 3, IntegrationType: "jira", IntegrationKey: "j", TicketID: "ED-2"},
+		{ID: 4, PolicyID: 2, HostID: 1, IntegrationType: "zendesk", IntegrationKey: "z", TicketID: "10"},
+	}
+	err = QueueResolvedPolicyTicketJobs(ctx, ds, logger)
+	require.NoError(t, err)
+	require.Equal(t, []uint{1, 2, 3, 4}, deleted)
+	require.Len(t, jobs, 2)
+
+	require.Equal(t, jiraName, jobs[0].Name)
+	var jargs jiraArgs
+	require.NoError(t, json.Unmarshal(*jobs[0].Args, &jargs))
+	require.Equal(t, &resolvedPolicyArgs{PolicyID: 1, PolicyName: "p1", IntegrationKey: "j", TicketID: "ED-1"}, jargs.ResolvedPolicy)
+
+	require.Equal(t, zendeskName, jobs[1].Name)
+	var zargs zendeskArgs
+	require.NoError(t, json.Unmarshal(*jobs[1].Args, &zargs))
+	require.Equal(t, &resolvedPolicyArgs{PolicyID: 2, IntegrationKey: "z", TicketID: "10"}, zargs.ResolvedPolicy)
+}
@@ -0,0 +1,347 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// slackName is the name of the job as registered in the worker.
+const slackName = "slack"
+
+// slackEscaper escapes the control characters of Slack's mrkdwn format, see
+// https://api.slack.com/reference/surfaces/formatting#escaping.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+var slackTemplates = struct {
+	VulnMessage          *template.Template
+	FailingPolicyMessage *template.Template
+}{
+	// Slack uses its own mrkdwn format for formatting. See
+	// https://api.slack.com/reference/surfaces/formatting for some reference.
+	VulnMessage: template.Must(template.New("").Funcs(template.FuncMap{
+		// CISAKnownExploit is *bool, so any condition check on it in the template
+		// will test if nil or not, and not its actual boolean value. Hence, "deref".
+		"deref":  func(b *bool) bool { return *b },
+		"escape": slackEscaper.Replace,
+	}).Parse(
+		`*Vulnerability {{ .CVE }} detected on {{ len .Hosts }} host(s)*
+
+See vulnerability (CVE) details in National Vulnerability Database (NVD) here: <{{ .NVDURL }}{{ .CVE }}|{{ .CVE }}>.
+{{ if .IsPremium }}{{ if .EPSSProbability }}
+Probability of exploit (reported by <https://www.first.org/epss/|FIRST.org/epss>): {{ .EPSSProbability }}{{ end }}{{ if .CVSSScore }}
+CVSS score (reported by <https://nvd.nist.gov/|NVD>): {{ .CVSSScore }}{{ end }}{{ if .CISAKnownExploit }}
+Known exploits (reported by <https://www.cisa.gov/known-exploited-vulnerabilities-catalog|CISA>): {{ if deref .CISAKnownExploit }}Yes{{ else }}No{{ end }}{{ end }}
+{{ end }}
+Affected hosts:
+{{ $end := len .Hosts }}{{ if gt $end 50 }}{{ $end = 50 }}{{ end }}{{ range slice .Hosts 0 $end }}
+• <{{ $.FleetURL }}/hosts/{{ .ID }}|{{ escape .DisplayName }}>{{ end }}
+
+View the affected software and more affected hosts on the <{{ .FleetURL }}/software/manage|Software> page in Fleet (search for "{{ .CVE }}").`,
+	)),
+
+	FailingPolicyMessage: template.Must(template.New("").Funcs(template.FuncMap{
+		"escape": slackEscaper.Replace,
+	}).Parse(
+		`*{{ escape .PolicyName }} policy failed on {{ len .Hosts }} host(s)*
+{{ if .PolicyCritical }}
+This policy is marked as *Critical* in Fleet.
+{{ end }}
+Hosts:
+{{ $end := len .Hosts }}{{ if gt $end 50 }}{{ $end = 50 }}{{ end }}{{ range slice .Hosts 0 $end }}
+• <{{ $.FleetURL }}/hosts/{{ .ID }}|{{ escape .DisplayName }}>{{ end }}
+
+View hosts that failed {{ escape .PolicyName }} on the <{{ .FleetURL }}/hosts/manage/?order_key=hostname&order_direction=asc&{{ if .TeamID }}team_id={{ .TeamID }}&{{ end }}policy_id={{ .PolicyID }}&policy_response=failing|Hosts> page in Fleet.`,
+	)),
+}
+
+// SlackClient defines the method required for the client that sends messages
+// to Slack.
+type SlackClient interface {
+	PostSlackMessage(ctx context.Context, msg *externalsvc.SlackMessage) error
+	SlackConfigMatches(opts *externalsvc.SlackOptions) bool
+}
+
+// Slack is the job processor for Slack integrations.
+type Slack struct {
+	FleetURL      string
+	Datastore     fleet.Datastore
+	Log           kitlog.Logger
+	NewClientFunc func(*externalsvc.SlackOptions) (SlackClient, error)
+
+	// mu protects concurrent access to clientsCache, so that the job processor
+	// can potentially be run concurrently.
+	mu sync.Mutex
+	// map of integration type + team ID to Slack client (empty team ID for
+	// global), e.g. "vuln:123", "failingPolicy:", etc.
+	clientsCache map[string]SlackClient
+}
+
+// returns nil, nil if there is no integration enabled for that message.
+func (s *Slack) getClient(ctx context.Context, args slackArgs) (SlackClient, error) {
+	var teamID uint
+	var useTeamCfg bool
+
+	intgType := args.integrationType()
+	key := intgType + ":"
+	if intgType == intgTypeFailingPolicy && args.FailingPolicy.TeamID != nil {
+		teamID = *args.FailingPolicy.TeamID
+		useTeamCfg = true
+		key += fmt.Sprint(teamID)
+	}
+
+	ac, err := s.Datastore.AppConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the config that would be used to create the client first - it is
+	// needed to check if an existing client is configured the same or if its
+	// configuration has changed since it was created.
+	var opts *externalsvc.SlackOptions
+	if useTeamCfg {
+		tm, err := fleet.TeamWithInheritedConfig(ctx, s.Datastore, teamID)
+		if err != nil {
+			return nil, err
+		}
+
+		intgs, err := tm.Config.Integrations.MatchWithIntegrations(ac.Integrations)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, intg := range intgs.Slack {
+			if intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies {
+				opts = &externalsvc.SlackOptions{URL: intg.URL}
+				break
+			}
+		}
+	} else {
+		for _, intg := range ac.Integrations.Slack {
+			if (intgType == intgTypeVuln && intg.EnableSoftwareVulnerabilities) ||
+				(intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies) {
+				opts = &externalsvc.SlackOptions{URL: intg.URL}
+				break
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.clientsCache == nil {
+		s.clientsCache = make(map[string]SlackClient)
+	}
+	if opts == nil {
+		// no integration configured, clear any existing one
+		delete(s.clientsCache, key)
+		return nil, nil
+	}
+
+	// check if the existing one can be reused
+	if cli := s.clientsCache[key]; cli != nil && cli.SlackConfigMatches(opts) {
+		return cli, nil
+	}
+
+	// otherwise create a new one
+	cli, err := s.NewClientFunc(opts)
+	if err != nil {
+		return nil, err
+	}
+	s.clientsCache[key] = cli
+	return cli, nil
+}
+
+// Name returns the name of the job.
+func (s *Slack) Name() string {
+	return slackName
+}
+
+// slackArgs are the arguments for the Slack integration job.
+type slackArgs struct {
+	Vulnerability *vulnArgs          `json:"vulnerability,omitempty"`
+	FailingPolicy *failingPolicyArgs `json:"failing_policy,omitempty"`
+}
+
+func (a *slackArgs) integrationType() string {
+	if a.FailingPolicy == nil {
+		return intgTypeVuln
+	}
+	return intgTypeFailingPolicy
+}
+
+// Run executes the slack job.
+func (s *Slack) Run(ctx context.Context, argsJSON json.RawMessage) error {
+	var args slackArgs
+	if err := json.Unmarshal(argsJSON, &args); err != nil {
+		return ctxerr.Wrap(ctx, err, "unmarshal args")
+	}
+
+	cli, err := s.getClient(ctx, args)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "get Slack client")
+	}
+	if cli == nil {
+		// this message was queued when an integration was enabled, but since
+		// then it has been disabled, so return success to mark the message
+		// as processed.
+		return nil
+	}
+
+	switch intgType := args.integrationType(); intgType {
+	case intgTypeVuln:
+		return s.runVuln(ctx, cli, args)
+	case intgTypeFailingPolicy:
+		return s.runFailingPolicy(ctx, cli, args)
+	default:
+		return ctxerr.Errorf(ctx, "unknown integration type: %v", intgType)
+	}
+}
+
+func (s *Slack) runVuln(ctx context.Context, cli SlackClient, args slackArgs) error {
+	if args.Vulnerability == nil {
+		return ctxerr.New(ctx, "missing vulnerability args")
+	}
+	hosts, err := s.Datastore.HostsByCVE(ctx, args.Vulnerability.CVE)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "find hosts by cve")
+	}
+	if len(hosts) == 0 {
+		// the CVE is suppressed by vulnerability exceptions on all the hosts
+		// that have it, or it was fixed since the job was queued.
+		level.Debug(s.Log).Log(
+			"msg", "skipping slack message for cve without vulnerable hosts",
+			"cve", args.Vulnerability.CVE,
+		)
+		return nil
+	}
+
+	tplArgs := newVulnTplArgs(ctx, s.FleetURL, args.Vulnerability, hosts)
+	if err := s.postTemplatedMessage(ctx, cli, slackTemplates.VulnMessage, tplArgs); err != nil {
+		return err
+	}
+	level.Debug(s.Log).Log(
+		"msg", "posted slack message for cve",
+		"cve", args.Vulnerability.CVE,
+	)
+	return nil
+}
+
+func (s *Slack) runFailingPolicy(ctx context.Context, cli SlackClient, args slackArgs) error {
+	tplArgs := newFailingPoliciesTplArgs(s.FleetURL, args.FailingPolicy)
+	if err := s.postTemplatedMessage(ctx, cli, slackTemplates.FailingPolicyMessage, tplArgs); err != nil {
+		return err
+	}
+
+	attrs := []interface{}{
+		"msg", "posted slack message for failing policy",
+		"policy_id", args.FailingPolicy.PolicyID,
+		"policy_name", args.FailingPolicy.PolicyName,
+	}
+	if args.FailingPolicy.TeamID != nil {
+		attrs = append(attrs, "team_id", *args.FailingPolicy.TeamID)
+	}
+	level.Debug(s.Log).Log(attrs...)
+	return nil
+}
+
+func (s *Slack) postTemplatedMessage(ctx context.Context, cli SlackClient, tpl *template.Template, args interface{}) error {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, args); err != nil {
+		return ctxerr.Wrap(ctx, err, "execute message template")
+	}
+
+	if err := cli.PostSlackMessage(ctx, &externalsvc.SlackMessage{Text: buf.String()}); err != nil {
+		return ctxerr.Wrap(ctx, err, "post message")
+	}
+	return nil
+}
+
+// QueueSlackVulnJobs queues the Slack vulnerability jobs to process
+// asynchronously via the worker.
+func QueueSlackVulnJobs(
+	ctx context.Context,
+	ds fleet.Datastore,
+	logger kitlog.Logger,
+	recentVulns []fleet.SoftwareVulnerability,
+	cveMeta map[string]fleet.CVEMeta,
+) error {
+	level.Info(logger).Log("enabled", "true", "recentVulns", len(recentVulns))
+
+	// for troubleshooting, log in debug level the CVEs that we will process
+	// (cannot be done in the loop below as we want to add the debug log
+	// _before_ we start processing them).
+	cves := make([]string, 0, len(recentVulns))
+	for _, vuln := range recentVulns {
+		cves = append(cves, vuln.GetCVE())
+	}
+	sort.Strings(cves)
+	level.Debug(logger).Log("recent_cves", fmt.Sprintf("%v", cves))
+
+	uniqCVEs := make(map[string]bool)
+	for _, v := range recentVulns {
+		uniqCVEs[v.GetCVE()] = true
+	}
+
+	for cve := range uniqCVEs {
+		args := vulnArgs{CVE: cve}
+		if meta, ok := cveMeta[cve]; ok {
+			args.EPSSProbability = meta.EPSSProbability
+			args.CVSSScore = meta.CVSSScore
+			args.CISAKnownExploit = meta.CISAKnownExploit
+		}
+		job, err := QueueJob(ctx, ds, slackName, slackArgs{Vulnerability: &args})
+		if err != nil {
+			return ctxerr.Wrap(ctx, err, "queueing job")
+		}
+		level.Debug(logger).Log("job_id", job.ID)
+	}
+	return nil
+}
+
+// QueueSlackFailingPolicyJob queues a Slack job for a failing policy to
+// process asynchronously via the worker.
+func QueueSlackFailingPolicyJob(ctx context.Context, ds fleet.Datastore, logger kitlog.Logger,
+	policy *fleet.Policy, hosts []fleet.PolicySetHost,
+) error {
+	attrs := []interface{}{
+		"enabled", "true",
+		"failing_policy", policy.ID,
+		"hosts_count", len(hosts),
+	}
+	if policy.TeamID != nil {
+		attrs = append(attrs, "team_id", *policy.TeamID)
+	}
+	if len(hosts) == 0 {
+		attrs = append(attrs, "msg", "skipping, no host")
+		level.Debug(logger).Log(attrs...)
+		return nil
+	}
+
+	level.Info(logger).Log(attrs...)
+
+	args := &failingPolicyArgs{
+		PolicyID:       policy.ID,
+		PolicyName:     policy.Name,
+		PolicyCritical: policy.Critical,
+		TeamID:         policy.TeamID,
+		Hosts:          hosts,
+	}
+	job, err := QueueJob(ctx, ds, slackName, slackArgs{FailingPolicy: args})
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "queueing job")
+	}
+	level.Debug(logger).Log("job_id", job.ID)
+	return nil
+}
@@ -0,0 +1,289 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fleetdm/fleet/v4/server/contexts/license"
+	"github.com/fleetdm/fleet/v4/server/fleet"
+	"github.com/fleetdm/fleet/v4/server/mock"
+	"github.com/fleetdm/fleet/v4/server/ptr"
+	"github.com/fleetdm/fleet/v4/server/service/externalsvc"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSlackClient struct {
+	opts     externalsvc.SlackOptions
+	messages []externalsvc.SlackMessage
+}
+
+func (c *mockSlackClient) PostSlackMessage(ctx context.Context, msg *externalsvc.SlackMessage) error {
+	c.messages = append(c.messages, *msg)
+	return nil
+}
+
+func (c *mockSlackClient) SlackConfigMatches(opts *externalsvc.SlackOptions) bool {
+	return c.opts == *opts
+}
+
+func TestSlackRun(t *testing.T) {
+	ds := new(mock.Store)
+	ds.HostsByCVEFunc = func(ctx context.Context, cve string) ([]*fleet.HostShort, error) {
+		return []*fleet.HostShort{
+			{
+				ID:          1,
+				Hostname:    "test<1>",
+				DisplayName: "test<1>",
+			},
+		}, nil
+	}
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			Slack: []*fleet.SlackIntegration{
+				{URL: "https://slack.example.com/services/global/token", EnableSoftwareVulnerabilities: true, EnableFailingPolicies: true},
+				{URL: "https://slack.example.com/services/team/token", EnableSoftwareVulnerabilities: false, EnableFailingPolicies: false},
+			},
+		}}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		if tid != 123 {
+			return nil, errors.New("unexpected team id")
+		}
+		return &fleet.Team{
+			ID: 123,
+			Config: fleet.TeamConfig{
+				Integrations: fleet.TeamIntegrations{
+					Slack: []*fleet.TeamSlackIntegration{
+						{URL: "https://slack.example.com/services/team/token", EnableFailingPolicies: true},
+					},
+				},
+			},
+		}, nil
+	}
+
+	cases := []struct {
+		desc                 string
+		licenseTier          string
+		payload              string
+		expectedURL          string
+		expectedContains     []string
+		expectedNotContained string
+	}{
+		{
+			"vuln free",
+			fleet.TierFree,
+			`{"vulnerability":{"cve":"CVE-1234-5678","epss_probability":3.4,"cvss_score":50,"cisa_known_exploit":true}}`,
+			"https://slack.example.com/services/global/token",
+			[]string{
+				"*Vulnerability CVE-1234-5678 detected on 1 host(s)*",
+				"<https://nvd.nist.gov/vuln/detail/CVE-1234-5678|CVE-1234-5678>",
+				"<https://fleetdm.com/hosts/1|test&lt;1&gt;>",
+			},
+			"Probability of exploit",
+		},
+		{
+			"vuln premium",
+			fleet.TierPremium,
+			`{"vulnerability":{"cve":"CVE-1234-5678","epss_probability":3.4,"cvss_score":50,"cisa_known_exploit":true}}`,
+			"https://slack.example.com/services/global/token",
+			[]string{
+				"*Vulnerability CVE-1234-5678 detected on 1 host(s)*",
+				"Probability of exploit (reported by <https://www.first.org/epss/|FIRST.org/epss>): 3.4",
+				"CVSS score (reported by <https://nvd.nist.gov/|NVD>): 50",
+				"Known exploits (reported by <https://www.cisa.gov/known-exploited-vulnerabilities-catalog|CISA>): Yes",
+			},
+			"",
+		},
+		{
+			"failing global policy",
+			fleet.TierFree,
+			`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 123, "hostname": "host-123", "displayname": "host-123"}]}}`,
+			"https://slack.example.com/services/global/token",
+			[]string{
+				"*test-policy policy failed on 1 host(s)*",
+				"<https://fleetdm.com/hosts/123|host-123>",
+				"&policy_id=1&policy_response=failing|Hosts>",
+			},
+			"team_id=",
+		},
+		{
+			"failing critical team policy",
+			fleet.TierPremium,
+			`{"failing_policy":{"policy_id": 2, "policy_name": "test-policy-2", "policy_critical": true, "team_id": 123, "hosts": [{"id": 1, "hostname": "host-1"}, {"id": 2, "hostname": "host-2"}]}}`,
+			"https://slack.example.com/services/team/token",
+			[]string{
+				"*test-policy-2 policy failed on 2 host(s)*",
+				"This policy is marked as *Critical* in Fleet.",
+				"&team_id=123&policy_id=2&policy_response=failing|Hosts>",
+			},
+			"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var client *mockSlackClient
+			slack := &Slack{
+				FleetURL:  "https://fleetdm.com",
+				Datastore: ds,
+				Log:       kitlog.NewNopLogger(),
+				NewClientFunc: func(opts *externalsvc.SlackOptions) (SlackClient, error) {
+					client = &mockSlackClient{opts: *opts}
+					return client, nil
+				},
+			}
+
+			err := slack.Run(license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: c.licenseTier}), json.RawMessage(c.payload))
+			require.NoError(t, err)
+			require.NotNil(t, client)
+			require.Equal(t, c.expectedURL, client.opts.URL)
+			require.Len(t, client.messages, 1)
+			for _, s := range c.expectedContains {
+				require.Contains(t, client.messages[0].Text, s)
+			}
+			if c.expectedNotContained != "" {
+				require.NotContains(t, client.messages[0].Text, c.expectedNotContained)
+			}
+		})
+	}
+}
+
+func TestSlackRunDisabled(t *testing.T) {
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			Slack: []*fleet.SlackIntegration{
+				{URL: "https://slack.example.com/services/global/token", EnableSoftwareVulnerabilities: false, EnableFailingPolicies: false},
+			},
+		}}, nil
+	}
+	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
+		return &fleet.Team{ID: tid}, nil
+	}
+
+	slack := &Slack{
+		FleetURL:  "https://fleetdm.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.SlackOptions) (SlackClient, error) {
+			return nil, errors.New("client should not be created")
+		},
+	}
+
+	ctx := license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: fleet.TierPremium})
+	err := slack.Run(ctx, json.RawMessage(`{"vulnerability":{"cve":"CVE-1234-5678"}}`))
+	require.NoError(t, err)
+	err = slack.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": []}}`))
+	require.NoError(t, err)
+	err = slack.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 2, "policy_name": "test-policy-2", "team_id": 123, "hosts": []}}`))
+	require.NoError(t, err)
+	require.False(t, ds.HostsByCVEFuncInvoked)
+}
+
+func TestSlackRunClientUpdate(t *testing.T) {
+	ds := new(mock.Store)
+	globalURL := "https://slack.example.com/1"
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			Slack: []*fleet.SlackIntegration{
+				{URL: globalURL, EnableFailingPolicies: true},
+			},
+		}}, nil
+	}
+
+	var clients []*mockSlackClient
+	slack := &Slack{
+		FleetURL:  "https://fleetdm.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.SlackOptions) (SlackClient, error) {
+			c := &mockSlackClient{opts: *opts}
+			clients = append(clients, c)
+			return c, nil
+		},
+	}
+
+	ctx := license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: fleet.TierFree})
+	payload := json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": []}}`)
+
+	// first run creates the client, second one reuses it
+	require.NoError(t, slack.Run(ctx, payload))
+	require.NoError(t, slack.Run(ctx, payload))
+	require.Len(t, clients, 1)
+	require.Len(t, clients[0].messages, 2)
+
+	// the URL changes, a new client is created
+	globalURL = "https://slack.example.com/2"
+	require.NoError(t, slack.Run(ctx, payload))
+	require.Len(t, clients, 2)
+	require.Equal(t, globalURL, clients[1].opts.URL)
+	require.Len(t, clients[1].messages, 1)
+}
+
+func TestSlackQueueVulnJobs(t *testing.T) {
+	ds := new(mock.Store)
+	ctx := context.Background()
+	logger := kitlog.NewNopLogger()
+
+	t.Run("same vulnerability on multiple software only queue one job", func(t *testing.T) {
+		var count int
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			require.Equal(t, slackName, job.Name)
+			count++
+			return job, nil
+		}
+		vulns := []fleet.SoftwareVulnerability{
+			{CVE: "CVE-1234-5678", SoftwareID: 1},
+			{CVE: "CVE-1234-5678", SoftwareID: 2},
+			{CVE: "CVE-1234-5678", SoftwareID: 3},
+		}
+		meta := map[string]fleet.CVEMeta{"CVE-1234-5678": {CVE: "CVE-1234-5678", CVSSScore: ptr.Float64(5)}}
+
+		err := QueueSlackVulnJobs(ctx, ds, logger, vulns, meta)
+		require.NoError(t, err)
+		require.True(t, ds.NewJobFuncInvoked)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			return nil, io.EOF
+		}
+		err := QueueSlackVulnJobs(ctx, ds, logger, []fleet.SoftwareVulnerability{{CVE: "CVE-1234-5678"}}, nil)
+		require.Error(t, err)
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestSlackQueueFailingPolicyJob(t *testing.T) {
+	ds := new(mock.Store)
+	ctx := context.Background()
+	logger := kitlog.NewNopLogger()
+
+	t.Run("success team", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			require.Equal(t, slackName, job.Name)
+			require.Contains(t, string(*job.Args), `"team_id":2`)
+			return job, nil
+		}
+		err := QueueSlackFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1", TeamID: ptr.Uint(2)}}, []fleet.PolicySetHost{{ID: 1, Hostname: "h1"}})
+		require.NoError(t, err)
+		require.True(t, ds.NewJobFuncInvoked)
+		ds.NewJobFuncInvoked = false
+	})
+
+	t.Run("no host", func(t *testing.T) {
+		ds.NewJobFunc = func(ctx context.Context, job *fleet.Job) (*fleet.Job, error) {
+			return job, nil
+		}
+		err := QueueSlackFailingPolicyJob(ctx, ds, logger,
+			&fleet.Policy{PolicyData: fleet.PolicyData{ID: 1, Name: "p1"}}, []fleet.PolicySetHost{})
+		require.NoError(t, err)
+		require.False(t, ds.NewJobFuncInvoked)
+	})
+}
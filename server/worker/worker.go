@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/fleetdm/fleet/v4/server/contexts/ctxerr"
+	"github.com/fleetdm/fleet/v4/server/contexts/license"
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -21,8 +23,9 @@ const (
 const (
 	// types of integrations - jobs like Jira and Zendesk support different
 	// integrations, this identifies the integration type of a message.
-	intgTypeVuln          = "vuln"
-	intgTypeFailingPolicy = "failingPolicy"
+	intgTypeVuln           = "vuln"
+	intgTypeFailingPolicy  = "failingPolicy"
+	intgTypeResolvedPolicy = "resolvedPolicy"
 )
 
 // Job defines an interface for jobs that can be run by the Worker
@@ -34,6 +37,28 @@ type Job interface {
 	Run(ctx context.Context, argsJSON json.RawMessage) error
 }
 
+// RetryDelayer is an optional interface that can be implemented by a Job to
+// delay the retries of its failed executions. If a Job does not implement it,
+// failed executions are retried on the next run of the worker.
+type RetryDelayer interface {
+	// RetryDelay returns the delay to wait before the next attempt, given the
+	// number of retries already made.
+	RetryDelay(retries int) time.Duration
+}
+
+// JobUpdateFunc updates the job after an execution, see UpdatingJob.
+type JobUpdateFunc func(ctx context.Context, job *fleet.Job) error
+
+// UpdatingJob is an optional interface that can be implemented by a Job that
+// records the result of each execution in its own tables. The worker calls
+// RunWithUpdate instead of Run, and updates the job with the returned function
+// instead of Datastore.UpdateJob, so that the job and the job's records are
+// updated in a single transaction. If the returned function is nil, the job
+// is updated with Datastore.UpdateJob.
+type UpdatingJob interface {
+	RunWithUpdate(ctx context.Context, argsJSON json.RawMessage) (JobUpdateFunc, error)
+}
+
 // failingPolicyArgs are the args common to all integrations that can process
 // failing policies.
 type failingPolicyArgs struct {
@@ -44,6 +69,17 @@ type failingPolicyArgs struct {
 	TeamID         *uint                 `json:"team_id,omitempty"`
 }
 
+// resolvedPolicyArgs are the args common to all integrations that can resolve
+// the tickets of failing policies, once all hosts of a ticket pass the policy.
+type resolvedPolicyArgs struct {
+	PolicyID   uint   `json:"policy_id"`
+	PolicyName string `json:"policy_name"`
+	// IntegrationKey identifies the integration that created the ticket, see
+	// fleet.PolicyAutomationTicket.
+	IntegrationKey string `json:"integration_key"`
+	TicketID       string `json:"ticket_id"`
+}
+
 // vulnArgs are the args common to all integrations that can process
 // vulnerabilities.
 type vulnArgs struct {
@@ -129,11 +165,15 @@ func (w *Worker) ProcessJobs(ctx context.Context) error {
 
 			level.Debug(log).Log("msg", "processing job")
 
-			if err := w.processJob(ctx, job); err != nil {
+			update, err := w.processJob(ctx, job)
+			if err != nil {
 				level.Error(log).Log("msg", "process job", "err", err)
 				job.Error = err.Error()
 				if job.Retries < maxRetries {
 					level.Debug(log).Log("msg", "will retry job")
+					if d, ok := w.registry[job.Name].(RetryDelayer); ok {
+						job.RetryDelay = d.RetryDelay(job.Retries)
+					}
 					job.Retries += 1
 				} else {
 					job.State = fleet.JobStateFailure
@@ -146,7 +186,13 @@ func (w *Worker) ProcessJobs(ctx context.Context) error {
 			// When we update the job, the updated_at timestamp gets updated and the job gets "pushed" to the back
 			// of queue. GetQueuedJobs fetches jobs by updated_at, so it will not return the same job until the queue
 			// has been processed once.
-			if _, err := w.ds.UpdateJob(ctx, job.ID, job); err != nil {
+			if update == nil {
+				update = func(ctx context.Context, job *fleet.Job) error {
+					_, err := w.ds.UpdateJob(ctx, job.ID, job)
+					return err
+				}
+			}
+			if err := update(ctx, job); err != nil {
 				level.Error(log).Log("update job", "err", err)
 			}
 		}
@@ -155,10 +201,10 @@ func (w *Worker) ProcessJobs(ctx context.Context) error {
 	return nil
 }
 
-func (w *Worker) processJob(ctx context.Context, job *fleet.Job) error {
+func (w *Worker) processJob(ctx context.Context, job *fleet.Job) (JobUpdateFunc, error) {
 	j, ok := w.registry[job.Name]
 	if !ok {
-		return ctxerr.Errorf(ctx, "unknown job: %s", job.Name)
+		return nil, ctxerr.Errorf(ctx, "unknown job: %s", job.Name)
 	}
 
 	var args json.RawMessage
@@ -166,7 +212,10 @@ func (w *Worker) processJob(ctx context.Context, job *fleet.Job) error {
 		args = *job.Args
 	}
 
-	return j.Run(ctx, args)
+	if uj, ok := j.(UpdatingJob); ok {
+		return uj.RunWithUpdate(ctx, args)
+	}
+	return nil, j.Run(ctx, args)
 }
 
 type failingPoliciesTplArgs struct {
@@ -188,3 +237,32 @@ func newFailingPoliciesTplArgs(fleetURL string, args *failingPolicyArgs) *failin
 		Hosts:          args.Hosts,
 	}
 }
+
+// vulnTplArgs are the template arguments common to the integrations that only
+// send a notification (e.g. Slack, Microsoft Teams) for vulnerabilities.
+type vulnTplArgs struct {
+	NVDURL   string
+	FleetURL string
+	CVE      string
+	Hosts    []*fleet.HostShort
+
+	IsPremium bool
+
+	// the following fields are only included in the message for premium licenses.
+	EPSSProbability  *float64
+	CVSSScore        *float64
+	CISAKnownExploit *bool
+}
+
+func newVulnTplArgs(ctx context.Context, fleetURL string, args *vulnArgs, hosts []*fleet.HostShort) *vulnTplArgs {
+	return &vulnTplArgs{
+		NVDURL:           nvdCVEURL,
+		FleetURL:         fleetURL,
+		CVE:              args.CVE,
+		Hosts:            hosts,
+		IsPremium:        license.IsPremium(ctx),
+		EPSSProbability:  args.EPSSProbability,
+		CVSSScore:        args.CVSSScore,
+		CISAKnownExploit: args.CISAKnownExploit,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/fleetdm/fleet/v4/server/fleet"
 	"github.com/fleetdm/fleet/v4/server/mock"
@@ -234,3 +235,111 @@ func TestWorkerMiddleJobFails(t *testing.T) {
 	require.Equal(t, 2, jobs[1].Retries)
 	require.Equal(t, 4, jobCallCount)
 }
+
+type testDelayedJob struct {
+	testJob
+	delay func(retries int) time.Duration
+}
+
+func (t testDelayedJob) RetryDelay(retries int) time.Duration {
+	return t.delay(retries)
+}
+
+func TestWorkerRetryDelay(t *testing.T) {
+	ds := new(mock.Store)
+
+	argsJSON := json.RawMessage(`{}`)
+	jobs := []*fleet.Job{
+		{ID: 1, Name: "delayed", Args: &argsJSON, State: fleet.JobStateQueued},
+		{ID: 2, Name: "test", Args: &argsJSON, State: fleet.JobStateQueued},
+	}
+	ds.GetQueuedJobsFunc = func(ctx context.Context, maxNumJobs int) ([]*fleet.Job, error) {
+		return jobs, nil
+	}
+	ds.UpdateJobFunc = func(ctx context.Context, id uint, job *fleet.Job) (*fleet.Job, error) {
+		return job, nil
+	}
+
+	w := NewWorker(ds, kitlog.NewNopLogger())
+	failFn := func(ctx context.Context, argsJSON json.RawMessage) error {
+		return errors.New("unknown error")
+	}
+	var gotRetries []int
+	w.Register(
+		testDelayedJob{
+			testJob: testJob{name: "delayed", run: failFn},
+			delay: func(retries int) time.Duration {
+				gotRetries = append(gotRetries, retries)
+				return time.Hour
+			},
+		},
+		testJob{name: "test", run: failFn},
+	)
+
+	err := w.ProcessJobs(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, []int{0}, gotRetries)
+	require.Equal(t, 1, jobs[0].Retries)
+	require.Equal(t, time.Hour, jobs[0].RetryDelay)
+	// jobs that do not implement RetryDelayer are not delayed
+	require.Equal(t, 1, jobs[1].Retries)
+	require.Zero(t, jobs[1].RetryDelay)
+}
+
+type testUpdatingJob struct {
+	testJob
+	update JobUpdateFunc
+}
+
+func (t testUpdatingJob) RunWithUpdate(ctx context.Context, argsJSON json.RawMessage) (JobUpdateFunc, error) {
+	return t.update, t.run(ctx, argsJSON)
+}
+
+func TestWorkerUpdatingJob(t *testing.T) {
+	ds := new(mock.Store)
+
+	argsJSON := json.RawMessage(`{}`)
+	jobs := []*fleet.Job{
+		{ID: 1, Name: "updating", Args: &argsJSON, State: fleet.JobStateQueued},
+		{ID: 2, Name: "not_updating", Args: &argsJSON, State: fleet.JobStateQueued},
+	}
+	ds.GetQueuedJobsFunc = func(ctx context.Context, maxNumJobs int) ([]*fleet.Job, error) {
+		return jobs, nil
+	}
+	var updated []uint
+	ds.UpdateJobFunc = func(ctx context.Context, id uint, job *fleet.Job) (*fleet.Job, error) {
+		updated = append(updated, id)
+		return job, nil
+	}
+
+	var jobUpdated []*fleet.Job
+	w := NewWorker(ds, kitlog.NewNopLogger())
+	w.Register(
+		testUpdatingJob{
+			testJob: testJob{name: "updating", run: func(ctx context.Context, argsJSON json.RawMessage) error {
+				return errors.New("unknown error")
+			}},
+			update: func(ctx context.Context, job *fleet.Job) error {
+				jobUpdated = append(jobUpdated, job)
+				return nil
+			},
+		},
+		testUpdatingJob{
+			testJob: testJob{name: "not_updating", run: func(ctx context.Context, argsJSON json.RawMessage) error {
+				return nil
+			}},
+		},
+	)
+
+	err := w.ProcessJobs(context.Background())
+	require.NoError(t, err)
+
+	// the job's update function is used instead of UpdateJob
+	require.Len(t, jobUpdated, 1)
+	require.Equal(t, uint(1), jobUpdated[0].ID)
+	require.Equal(t, 1, jobUpdated[0].Retries)
+	require.Equal(t, fleet.JobStateQueued, jobUpdated[0].State)
+	// without an update function, the job is updated with UpdateJob
+	require.Equal(t, []uint{2}, updated)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 	"text/template"
 
@@ -26,6 +27,8 @@ var zendeskTemplates = struct {
 	VulnDescription          *template.Template
 	FailingPolicySummary     *template.Template
 	FailingPolicyDescription *template.Template
+	FailingPolicyComment     *template.Template
+	ResolvedPolicyComment    *template.Template
 }{
 	VulnSummary: template.Must(template.New("").Parse(
 		`Vulnerability {{ .CVE }} detected on {{ len .Hosts }} host(s)`,
@@ -87,6 +90,26 @@ View hosts that failed {{ .PolicyName }} on the [**Hosts**]({{ .FleetURL }}/host
 ----
 
 This issue was created automatically by your Fleet Zendesk integration.
+`)),
+
+	FailingPolicyComment: template.Must(template.New("").Parse(
+		`{{ .PolicyName }} policy now also fails on {{ len .Hosts }} more host(s):
+{{ $end := len .Hosts }}{{ if gt $end 50 }}{{ $end = 50 }}{{ end }}
+{{ range slice .Hosts 0 $end }}
+* [{{ .DisplayName }}]({{ $.FleetURL }}/hosts/{{ .ID }})
+{{ end }}
+
+----
+
+This comment was added automatically by your Fleet Zendesk integration.
+`)),
+
+	ResolvedPolicyComment: template.Must(template.New("").Parse(
+		`{{ if .PolicyName }}All hosts now pass the {{ .PolicyName }} policy.{{ else }}The policy has been deleted.{{ end }}
+
+----
+
+This ticket was solved automatically by your Fleet Zendesk integration.
 `)),
 }
 
@@ -108,6 +131,8 @@ type zendeskVulnTplArgs struct {
 // to Zendesk.
 type ZendeskClient interface {
 	CreateZendeskTicket(ctx context.Context, ticket *zendesk.Ticket) (*zendesk.Ticket, error)
+	ReopenZendeskTicket(ctx context.Context, ticketID int64, comment string) error
+	ResolveZendeskTicket(ctx context.Context, ticketID int64, comment string) error
 	ZendeskConfigMatches(opts *externalsvc.ZendeskOptions) bool
 }
 
@@ -122,12 +147,19 @@ type Zendesk struct {
 	// can potentially be run concurrently.
 	mu sync.Mutex
 	// map of integration type + team ID to Zendesk client (empty team ID for
-	// global), e.g. "vuln:123", "failingPolicy:", etc.
+	// global), e.g. "vuln:123", "failingPolicy:", etc. For resolved
+	// policies, the integration key is used instead of the team ID.
 	clientsCache map[string]ZendeskClient
 }
 
-// returns nil, nil if there is no integration enabled for that message.
-func (z *Zendesk) getClient(ctx context.Context, args zendeskArgs) (ZendeskClient, error) {
+// zendeskIntegrationKey returns the key identifying the Zendesk integration
+// configured with those options, as stored with the tickets it created.
+func zendeskIntegrationKey(opts *externalsvc.ZendeskOptions) string {
+	return opts.URL + "\n" + strconv.FormatInt(opts.GroupID, 10)
+}
+
+// returns nil, nil, nil if there is no integration enabled for that message.
+func (z *Zendesk) getClient(ctx context.Context, args zendeskArgs) (ZendeskClient, *externalsvc.ZendeskOptions, error) {
 	var teamID uint
 	var useTeamCfg bool
 
@@ -138,10 +170,13 @@ func (z *Zendesk) getClient(ctx context.Context, args zendeskArgs) (ZendeskClien
 		useTeamCfg = true
 		key += fmt.Sprint(teamID)
 	}
+	if intgType == intgTypeResolvedPolicy {
+		key += args.ResolvedPolicy.IntegrationKey
+	}
 
 	ac, err := z.Datastore.AppConfig(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// load the config that would be used to create the client first - it is
@@ -149,14 +184,14 @@ func (z *Zendesk) getClient(ctx context.Context, args zendeskArgs) (ZendeskClien
 	// configuration has changed since it was created.
 	var opts *externalsvc.ZendeskOptions
 	if useTeamCfg {
-		tm, err := z.Datastore.Team(ctx, teamID)
+		tm, err := fleet.TeamWithInheritedConfig(ctx, z.Datastore, teamID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		intgs, err := tm.Config.Integrations.MatchWithIntegrations(ac.Integrations)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		for _, intg := range intgs.Zendesk {
@@ -172,14 +207,18 @@ func (z *Zendesk) getClient(ctx context.Context, args zendeskArgs) (ZendeskClien
 		}
 	} else {
 		for _, intg := range ac.Integrations.Zendesk {
+			intgOpts := &externalsvc.ZendeskOptions{
+				URL:      intg.URL,
+				Email:    intg.Email,
+				APIToken: intg.APIToken,
+				GroupID:  intg.GroupID,
+			}
+			// tickets are resolved with the integration that created them, as
+			// long as it exists, even if its automations are now disabled.
 			if (intgType == intgTypeVuln && intg.EnableSoftwareVulnerabilities) ||
-				(intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies) {
-				opts = &externalsvc.ZendeskOptions{
-					URL:      intg.URL,
-					Email:    intg.Email,
-					APIToken: intg.APIToken,
-					GroupID:  intg.GroupID,
-				}
+				(intgType == intgTypeFailingPolicy && intg.EnableFailingPolicies) ||
+				(intgType == intgTypeResolvedPolicy && zendeskIntegrationKey(intgOpts) == args.ResolvedPolicy.IntegrationKey) {
+				opts = intgOpts
 				break
 			}
 		}
@@ -194,21 +233,21 @@ func (z *Zendesk) getClient(ctx context.Context, args zendeskArgs) (ZendeskClien
 	if opts == nil {
 		// no integration configured, clear any existing one
 		delete(z.clientsCache, key)
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// check if the existing one can be reused
 	if cli := z.clientsCache[key]; cli != nil && cli.ZendeskConfigMatches(opts) {
-		return cli, nil
+		return cli, opts, nil
 	}
 
 	// otherwise create a new one
 	cli, err := z.NewClientFunc(opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	z.clientsCache[key] = cli
-	return cli, nil
+	return cli, opts, nil
 }
 
 // Name returns the name of the job.
@@ -220,16 +259,21 @@ func (z *Zendesk) Name() string {
 type zendeskArgs struct {
 	// CVE is deprecated but kept for backwards compatibility (there may be jobs
 	// enqueued in that format to process).
-	CVE           string             `json:"cve,omitempty"`
-	Vulnerability *vulnArgs          `json:"vulnerability,omitempty"`
-	FailingPolicy *failingPolicyArgs `json:"failing_policy,omitempty"`
+	CVE            string              `json:"cve,omitempty"`
+	Vulnerability  *vulnArgs           `json:"vulnerability,omitempty"`
+	FailingPolicy  *failingPolicyArgs  `json:"failing_policy,omitempty"`
+	ResolvedPolicy *resolvedPolicyArgs `json:"resolved_policy,omitempty"`
 }
 
 func (a *zendeskArgs) integrationType() string {
-	if a.FailingPolicy == nil {
+	switch {
+	case a.FailingPolicy != nil:
+		return intgTypeFailingPolicy
+	case a.ResolvedPolicy != nil:
+		return intgTypeResolvedPolicy
+	default:
 		return intgTypeVuln
 	}
-	return intgTypeFailingPolicy
 }
 
 // Run executes the zendesk job.
@@ -239,7 +283,7 @@ func (z *Zendesk) Run(ctx context.Context, argsJSON json.RawMessage) error {
 		return ctxerr.Wrap(ctx, err, "unmarshal args")
 	}
 
-	cli, err := z.getClient(ctx, args)
+	cli, opts, err := z.getClient(ctx, args)
 	if err != nil {
 		return ctxerr.Wrap(ctx, err, "get Zendesk client")
 	}
@@ -254,7 +298,9 @@ func (z *Zendesk) Run(ctx context.Context, argsJSON json.RawMessage) error {
 	case intgTypeVuln:
 		return z.runVuln(ctx, cli, args)
 	case intgTypeFailingPolicy:
-		return z.runFailingPolicy(ctx, cli, args)
+		return z.runFailingPolicy(ctx, cli, zendeskIntegrationKey(opts), args)
+	case intgTypeResolvedPolicy:
+		return z.runResolvedPolicy(ctx, cli, args)
 	default:
 		return ctxerr.Errorf(ctx, "unknown integration type: %v", intgType)
 	}
@@ -273,6 +319,15 @@ func (z *Zendesk) runVuln(ctx context.Context, cli ZendeskClient, args zendeskAr
 	if err != nil {
 		return ctxerr.Wrap(ctx, err, "find hosts by cve")
 	}
+	if len(hosts) == 0 {
+		// the CVE is suppressed by vulnerability exceptions on all the hosts
+		// that have it, or it was fixed since the job was queued.
+		level.Debug(z.Log).Log(
+			"msg", "skipping zendesk ticket for cve without vulnerable hosts",
+			"cve", vargs.CVE,
+		)
+		return nil
+	}
 
 	tplArgs := &zendeskVulnTplArgs{
 		NVDURL:           nvdCVEURL,
@@ -297,13 +352,62 @@ func (z *Zendesk) runVuln(ctx context.Context, cli ZendeskClient, args zendeskAr
 	return nil
 }
 
-func (z *Zendesk) runFailingPolicy(ctx context.Context, cli ZendeskClient, args zendeskArgs) error {
-	tplArgs := newFailingPoliciesTplArgs(z.FleetURL, args.FailingPolicy)
+func (z *Zendesk) runFailingPolicy(ctx context.Context, cli ZendeskClient, intgKey string, args zendeskArgs) error {
+	fp := args.FailingPolicy
+	tracked, err := z.Datastore.ListPolicyAutomationTickets(ctx, fp.PolicyID, zendeskName, intgKey)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "list policy automation tickets")
+	}
+
+	rawTicketID, newHosts := policyTicketToUpdate(tracked, fp.Hosts)
+	if rawTicketID != "" {
+		if len(newHosts) == 0 {
+			level.Debug(z.Log).Log(
+				"msg", "failing policy hosts already tracked by zendesk ticket",
+				"policy_id", fp.PolicyID,
+				"ticket_id", rawTicketID,
+			)
+			return nil
+		}
+
+		newArgs := *fp
+		newArgs.Hosts = newHosts
+		var buf bytes.Buffer
+		if err := zendeskTemplates.FailingPolicyComment.Execute(&buf, newFailingPoliciesTplArgs(z.FleetURL, &newArgs)); err != nil {
+			return ctxerr.Wrap(ctx, err, "execute comment template")
+		}
+
+		ticketID, err := strconv.ParseInt(rawTicketID, 10, 64)
+		if err == nil {
+			err = cli.ReopenZendeskTicket(ctx, ticketID, buf.String())
+		}
+		if err == nil {
+			recordPolicyTickets(ctx, z.Datastore, z.Log, fp, zendeskName, intgKey, rawTicketID, newHosts)
+			level.Debug(z.Log).Log(
+				"msg", "updated zendesk ticket for failing policy",
+				"policy_id", fp.PolicyID,
+				"ticket_id", rawTicketID,
+				"new_hosts", len(newHosts),
+			)
+			return nil
+		}
+
+		// the ticket may have been deleted or closed, create a new one instead.
+		level.Info(z.Log).Log(
+			"msg", "failed to update zendesk ticket for failing policy, creating a new one",
+			"policy_id", fp.PolicyID,
+			"ticket_id", rawTicketID,
+			"err", err,
+		)
+	}
+
+	tplArgs := newFailingPoliciesTplArgs(z.FleetURL, fp)
 
 	createdTicket, err := z.createTemplatedTicket(ctx, cli, zendeskTemplates.FailingPolicySummary, zendeskTemplates.FailingPolicyDescription, tplArgs)
 	if err != nil {
 		return err
 	}
+	recordPolicyTickets(ctx, z.Datastore, z.Log, fp, zendeskName, intgKey, strconv.FormatInt(createdTicket.ID, 10), fp.Hosts)
 
 	attrs := []interface{}{
 		"msg", "created zendesk ticket for failing policy",
@@ -318,6 +422,40 @@ func (z *Zendesk) runFailingPolicy(ctx context.Context, cli ZendeskClient, args
 	return nil
 }
 
+func (z *Zendesk) runResolvedPolicy(ctx context.Context, cli ZendeskClient, args zendeskArgs) error {
+	rp := args.ResolvedPolicy
+
+	// a host may have started failing the policy again since the job was
+	// queued, in which case the ticket is still relevant.
+	count, err := z.Datastore.CountPolicyAutomationTicketHosts(ctx, zendeskName, rp.IntegrationKey, rp.TicketID)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "count policy automation ticket hosts")
+	}
+	if count > 0 {
+		return nil
+	}
+
+	ticketID, err := strconv.ParseInt(rp.TicketID, 10, 64)
+	if err != nil {
+		return ctxerr.Wrap(ctx, err, "parse ticket id")
+	}
+
+	var buf bytes.Buffer
+	if err := zendeskTemplates.ResolvedPolicyComment.Execute(&buf, rp); err != nil {
+		return ctxerr.Wrap(ctx, err, "execute comment template")
+	}
+	if err := cli.ResolveZendeskTicket(ctx, ticketID, buf.String()); err != nil {
+		return ctxerr.Wrap(ctx, err, "resolve ticket")
+	}
+
+	level.Debug(z.Log).Log(
+		"msg", "resolved zendesk ticket for failing policy",
+		"policy_id", rp.PolicyID,
+		"ticket_id", rp.TicketID,
+	)
+	return nil
+}
+
 func (z *Zendesk) createTemplatedTicket(ctx context.Context, cli ZendeskClient, summaryTpl, descTpl *template.Template, args interface{}) (*zendesk.Ticket, error) {
 	var buf bytes.Buffer
 	if err := summaryTpl.Execute(&buf, args); err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -37,6 +38,12 @@ func TestZendeskRun(t *testing.T) {
 			},
 		}}, nil
 	}
+	ds.ListPolicyAutomationTicketsFunc = func(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+		return nil, nil
+	}
+	ds.UpsertPolicyAutomationTicketsFunc = func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+		return nil
+	}
 	ds.TeamFunc = func(ctx context.Context, tid uint) (*fleet.Team, error) {
 		if tid != 123 {
 			return nil, errors.New("unexpected team id")
@@ -298,11 +305,30 @@ func TestZendeskQueueFailingPolicyJob(t *testing.T) {
 type mockZendeskClient struct {
 	opts    externalsvc.ZendeskOptions
 	tickets []zendesk.Ticket
+
+	// reopened and resolved are the IDs and comments of the tickets reopened
+	// and resolved, as "id: comment".
+	reopened  []string
+	resolved  []string
+	reopenErr error
 }
 
 func (c *mockZendeskClient) CreateZendeskTicket(ctx context.Context, ticket *zendesk.Ticket) (*zendesk.Ticket, error) {
 	c.tickets = append(c.tickets, *ticket)
-	return &zendesk.Ticket{}, nil
+	return &zendesk.Ticket{ID: int64(len(c.tickets))}, nil
+}
+
+func (c *mockZendeskClient) ReopenZendeskTicket(ctx context.Context, ticketID int64, comment string) error {
+	if c.reopenErr != nil {
+		return c.reopenErr
+	}
+	c.reopened = append(c.reopened, fmt.Sprintf("%d: %s", ticketID, comment))
+	return nil
+}
+
+func (c *mockZendeskClient) ResolveZendeskTicket(ctx context.Context, ticketID int64, comment string) error {
+	c.resolved = append(c.resolved, fmt.Sprintf("%d: %s", ticketID, comment))
+	return nil
 }
 
 func (c *mockZendeskClient) ZendeskConfigMatches(opts *externalsvc.ZendeskOptions) bool {
@@ -327,6 +353,13 @@ func TestZendeskRunClientUpdate(t *testing.T) {
 		}}, nil
 	}
 
+	ds.ListPolicyAutomationTicketsFunc = func(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+		return nil, nil
+	}
+	ds.UpsertPolicyAutomationTicketsFunc = func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+		return nil
+	}
+
 	teamCfg := &fleet.Team{
 		ID: 123,
 		Config: fleet.TeamConfig{
@@ -417,3 +450,95 @@ func TestZendeskRunClientUpdate(t *testing.T) {
 	require.Len(t, clients[2].tickets, 1)
 	require.NotContains(t, clients[2].tickets[0].Comment.Body, "Critical")
 }
+
+func TestZendeskRunFailingPolicyTickets(t *testing.T) {
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func(ctx context.Context) (*fleet.AppConfig, error) {
+		return &fleet.AppConfig{Integrations: fleet.Integrations{
+			Zendesk: []*fleet.ZendeskIntegration{
+				{URL: "https://zendesk.example.com", GroupID: 12, EnableFailingPolicies: true},
+			},
+		}}, nil
+	}
+
+	const intgKey = "https://zendesk.example.com\n12"
+	var tracked []*fleet.PolicyAutomationTicket
+	ds.ListPolicyAutomationTicketsFunc = func(ctx context.Context, policyID uint, integrationType, integrationKey string) ([]*fleet.PolicyAutomationTicket, error) {
+		require.Equal(t, uint(1), policyID)
+		require.Equal(t, "zendesk", integrationType)
+		require.Equal(t, intgKey, integrationKey)
+		return tracked, nil
+	}
+	ds.UpsertPolicyAutomationTicketsFunc = func(ctx context.Context, tickets []*fleet.PolicyAutomationTicket) error {
+		for _, t := range tickets {
+			t.ID = uint(len(tracked) + 1)
+			tracked = append(tracked, t)
+		}
+		return nil
+	}
+	ds.CountPolicyAutomationTicketHostsFunc = func(ctx context.Context, integrationType, integrationKey, ticketID string) (int, error) {
+		var count int
+		for _, t := range tracked {
+			if t.IntegrationType == integrationType && t.IntegrationKey == integrationKey && t.TicketID == ticketID {
+				count++
+			}
+		}
+		return count, nil
+	}
+
+	client := &mockZendeskClient{}
+	zendeskJob := &Zendesk{
+		FleetURL:  "http://example.com",
+		Datastore: ds,
+		Log:       kitlog.NewNopLogger(),
+		NewClientFunc: func(opts *externalsvc.ZendeskOptions) (ZendeskClient, error) {
+			client.opts = *opts
+			return client, nil
+		},
+	}
+	ctx := license.NewContext(context.Background(), &fleet.LicenseInfo{Tier: fleet.TierFree})
+
+	// first failure creates the ticket and tracks the hosts
+	err := zendeskJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 1, "displayname": "h1"}, {"id": 2, "displayname": "h2"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.tickets, 1)
+	require.Len(t, tracked, 2)
+	require.Equal(t, "1", tracked[0].TicketID)
+	require.Equal(t, "1", tracked[1].TicketID)
+
+	// repeated failure of the same hosts does nothing
+	err = zendeskJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 1, "displayname": "h1"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.tickets, 1)
+	require.Empty(t, client.reopened)
+
+	// failure of a new host comments on the existing ticket
+	err = zendeskJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 1, "displayname": "h1"}, {"id": 3, "displayname": "h3"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.tickets, 1)
+	require.Len(t, client.reopened, 1)
+	require.Contains(t, client.reopened[0], "1: test-policy policy now also fails on 1 more host(s)")
+	require.Contains(t, client.reopened[0], "[h3](http://example.com/hosts/3)")
+	require.NotContains(t, client.reopened[0], "h1")
+	require.Len(t, tracked, 3)
+	require.Equal(t, "1", tracked[2].TicketID)
+
+	// if the ticket cannot be updated, a new one is created
+	client.reopenErr = errors.New("ticket is closed")
+	err = zendeskJob.Run(ctx, json.RawMessage(`{"failing_policy":{"policy_id": 1, "policy_name": "test-policy", "hosts": [{"id": 4, "displayname": "h4"}]}}`))
+	require.NoError(t, err)
+	require.Len(t, client.tickets, 2)
+	require.Len(t, tracked, 4)
+	require.Equal(t, "2", tracked[3].TicketID)
+
+	// the ticket is not resolved while hosts are still tracked
+	err = zendeskJob.Run(ctx, json.RawMessage(`{"resolved_policy":{"policy_id": 1, "policy_name": "test-policy", "integration_key": "https://zendesk.example.com\n12", "ticket_id": "1"}}`))
+	require.NoError(t, err)
+	require.Empty(t, client.resolved)
+
+	tracked = tracked[3:]
+	err = zendeskJob.Run(ctx, json.RawMessage(`{"resolved_policy":{"policy_id": 1, "policy_name": "", "integration_key": "https://zendesk.example.com\n12", "ticket_id": "1"}}`))
+	require.NoError(t, err)
+	require.Len(t, client.resolved, 1)
+	require.Contains(t, client.resolved[0], "1: The policy has been deleted.")
+}